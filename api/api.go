@@ -0,0 +1,110 @@
+// Package api runs the mcpgen generation from Go, to build a custom mcpgen
+// binary registering plugins, like gqlgen's api package. Plugins extend the
+// generation without patching mcpgen: they implement Plugin and any of the
+// SpecMutator, ModelMutator, TemplateDataMutator and PostGenerator hooks.
+//
+// Example:
+//
+//	// cmd/mcpgen/main.go of the company tooling
+//	func main() {
+//	    if err := api.Generate("mcpgen.yaml", api.AddPlugin(audit.New())); err != nil {
+//	        fmt.Fprintln(os.Stderr, err)
+//	        os.Exit(1)
+//	    }
+//	}
+package api
+
+import (
+	"fmt"
+	"os"
+
+	"go.probo.inc/mcpgen/internal/codegen"
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+type (
+	// Plugin extends the generation pipeline. See codegen.Plugin.
+	Plugin = codegen.Plugin
+	// SpecMutator changes the spec before its schemas are loaded.
+	SpecMutator = codegen.SpecMutator
+	// TemplateDataMutator changes the data of a code template, such as
+	// server.gotpl, before it is executed.
+	TemplateDataMutator = codegen.TemplateDataMutator
+	// PostGenerator runs once the generated files are written.
+	PostGenerator = codegen.PostGenerator
+	// ModelMutator changes the model definitions before they are rendered.
+	ModelMutator = codegen.ModelMutator
+
+	// ModelBuild holds the model definitions ModelMutator plugins change.
+	ModelBuild = codegen.ModelBuild
+	// Model is a generated struct type.
+	Model = codegen.Model
+	// Field is a field of a Model.
+	Field = codegen.Field
+
+	// Config is the mcpgen.yaml configuration.
+	Config = config.Config
+	// Spec is the MCP spec the code is generated from.
+	Spec = config.MCPSpec
+	// Tool is a tool of the spec.
+	Tool = config.Tool
+	// Resource is a resource of the spec.
+	Resource = config.Resource
+	// Prompt is a prompt of the spec.
+	Prompt = config.Prompt
+	// Schema is a JSON schema of the spec.
+	Schema = config.Schema
+)
+
+// Option configures Generate.
+type Option func(*options)
+
+type options struct {
+	plugins []Plugin
+	only    []string
+}
+
+// AddPlugin registers a plugin, whose hooks run after the ones of the
+// plugins added before.
+func AddPlugin(p Plugin) Option {
+	return func(o *options) {
+		o.plugins = append(o.plugins, p)
+	}
+}
+
+// Only limits the generation to stages, such as models or server, or to
+// primitive kinds, such as tools, like mcpgen generate --only.
+func Only(stages ...string) Option {
+	return func(o *options) {
+		o.only = append(o.only, stages...)
+	}
+}
+
+// Generate generates the code of the configuration file at configFile, like
+// mcpgen generate, running the hooks of the plugins. Warnings are printed
+// to stderr.
+func Generate(configFile string, opts ...Option) error {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	cfg, spec, err := config.Load(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	gen := codegen.New(cfg, spec)
+	for _, p := range o.plugins {
+		gen.AddPlugin(p)
+	}
+
+	if err := gen.Generate(o.only...); err != nil {
+		return fmt.Errorf("code generation failed: %w", err)
+	}
+
+	for _, warning := range gen.Warnings() {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type auditPlugin struct {
+	generated bool
+}
+
+func (p *auditPlugin) Name() string { return "audit" }
+
+func (p *auditPlugin) MutateSpec(spec *Spec) error {
+	spec.Components.Schemas["AuditEntry"] = &Schema{
+		Type:       "object",
+		Properties: map[string]*Schema{"actor": {Type: "string"}},
+	}
+	return nil
+}
+
+func (p *auditPlugin) PostGenerate(cfg *Config, spec *Spec) error {
+	p.generated = true
+	return nil
+}
+
+func TestGenerate(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":      "module example.com/app\n\ngo 1.21\n",
+		"mcpgen.yaml": "spec: mcp.yaml\noutput: generated\nexec:\n  package: server\nresolver:\n  package: server\nmodel:\n  package: server\n  filename: models.go\n",
+		"mcp.yaml":    "info:\n  title: app\n  version: 1.0.0\ncomponents:\n  schemas:\n    Task:\n      type: object\n      properties:\n        title:\n          type: string\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	t.Chdir(dir)
+
+	plugin := &auditPlugin{}
+	require.NoError(t, Generate("mcpgen.yaml", AddPlugin(plugin), Only("models")))
+	assert.True(t, plugin.generated)
+
+	content, err := os.ReadFile(filepath.Join(dir, "generated", "models.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "type AuditEntry struct")
+	assert.Contains(t, string(content), "type Task struct")
+}
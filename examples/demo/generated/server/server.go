@@ -4,7 +4,10 @@ package server
 
 import (
 	"context"
+	"errors"
+
 	"demo/generated/types"
+
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	mcputil "go.probo.inc/mcpgen/mcp"
 )
@@ -25,26 +28,83 @@ type ResolverInterface interface {
 
 // New creates a new MCP server instance with all handlers registered.
 // Returns a fully configured *mcp.Server ready to be used with any transport.
+// The OnInitialize hook of resolver, when it implements mcputil.InitializeHook,
+// is called as each session initializes.
 func New(resolver ResolverInterface, opts ...mcputil.Option) *mcp.Server {
 	o := mcputil.ApplyOptions(opts)
 
 	server := mcp.NewServer(
 		&mcp.Implementation{
 			Name:    "demo-server",
-			Version: "1.0.0",
+			Version: serverVersion,
 		},
-		nil,
+		mcputil.LifecycleOptions(resolver),
 	)
 
+	// Answer the calls to tools whose resolver returns mcputil.ErrNotImplemented
+	server.AddReceivingMiddleware(mcputil.NotImplementedMiddleware(o.NotImplementedFunc))
+
+	// Report the errors classified with mcputil.InvalidInput, NotFound and
+	// the other kinds uniformly
+	server.AddReceivingMiddleware(mcputil.ErrorMiddleware())
+
+	if transcript := o.Transcript(); transcript != nil {
+		// Record the messages of each session as the client sees them
+		server.AddReceivingMiddleware(transcript.Middleware(mcputil.TranscriptReceived))
+		server.AddSendingMiddleware(transcript.Middleware(mcputil.TranscriptSent))
+	}
+
+	// Give each request an ID, read with mcputil.RequestID
+	server.AddReceivingMiddleware(mcputil.RequestIDMiddleware(o.IDGenerator))
+
 	registerToolHandlers(server, resolver, &o)
-	registerResourceHandlers(server, resolver)
+	registerResourceHandlers(server, resolver, &o)
 	registerPromptHandlers(server, resolver)
 
 	return server
 }
 
+// serverVersion and serverSpecHash are the version and spec hash of ServerInfo,
+// which mcpgen release sets when linking the server, as with
+// go build -ldflags "-X <package>.serverVersion=1.2.0".
+var (
+	serverVersion  = "1.0.0"
+	serverSpecHash = "ce2fb8fdf924ee8ba1599d2f5e51d8e377b4c91e26ec93bfc3a4a8e14aecd325"
+)
+
+// ServerInfo returns the build and specification metadata of this server.
+// Use ServerInfo().MetricsHandler() to expose it as a Prometheus build info metric.
+func ServerInfo() mcputil.BuildInfo {
+	return mcputil.BuildInfo{
+		Name:          "demo-server",
+		Version:       serverVersion,
+		SpecHash:      serverSpecHash,
+		MCPGenVersion: "dev",
+	}
+}
+
+// RunConfig selects the transports served by Run.
+type RunConfig = mcputil.RunConfig
+
+// Run creates the MCP server and serves it on the transports selected by cfg
+// (stdio, HTTP or both) until ctx is cancelled or an interrupt signal is received.
+// It then calls the OnShutdown hook of resolver, when it implements
+// mcputil.ShutdownHook. With cfg.DevFixtures, the unimplemented handlers serve
+// the devFixture of the spec. With cfg.TranscriptDir, the transcript of each
+// session is written to that directory.
+func Run(ctx context.Context, resolver ResolverInterface, cfg RunConfig, opts ...mcputil.Option) error {
+	if cfg.DevFixtures {
+		opts = append(opts, mcputil.WithDevFixtures(true))
+	}
+	if cfg.TranscriptDir != "" {
+		opts = append(opts, mcputil.WithTranscriptDir(cfg.TranscriptDir))
+	}
+	err := mcputil.Run(ctx, New(resolver, opts...), cfg)
+	return errors.Join(err, mcputil.Shutdown(ctx, resolver, cfg.ShutdownTimeout))
+}
+
 func registerToolHandlers(server *mcp.Server, resolver ResolverInterface, opts *mcputil.Options) {
-	mcp.AddTool(
+	mcputil.AddTool(
 		server,
 		&mcp.Tool{
 			Name:         "calculate",
@@ -55,16 +115,10 @@ func registerToolHandlers(server *mcp.Server, resolver ResolverInterface, opts *
 				IdempotentHint: true,
 			},
 		},
-		func(ctx context.Context, req *mcp.CallToolRequest, input *types.CalculateInput) (result *mcp.CallToolResult, output types.CalculateOutput, err error) {
-			defer func() {
-				if r := recover(); r != nil {
-					err = opts.RecoverFunc(ctx, r)
-				}
-			}()
-			return resolver.CalculateTool(ctx, req, input)
-		},
+		resolver.CalculateTool,
+		opts,
 	)
-	mcp.AddTool(
+	mcputil.AddTool(
 		server,
 		&mcp.Tool{
 			Name:        "calculate2",
@@ -74,16 +128,10 @@ func registerToolHandlers(server *mcp.Server, resolver ResolverInterface, opts *
 				IdempotentHint: true,
 			},
 		},
-		func(ctx context.Context, req *mcp.CallToolRequest, input *types.Calculate2Input) (result *mcp.CallToolResult, output map[string]any, err error) {
-			defer func() {
-				if r := recover(); r != nil {
-					err = opts.RecoverFunc(ctx, r)
-				}
-			}()
-			return resolver.Calculate2Tool(ctx, req, input)
-		},
+		resolver.Calculate2Tool,
+		opts,
 	)
-	mcp.AddTool(
+	mcputil.AddTool(
 		server,
 		&mcp.Tool{
 			Name:         "create_task",
@@ -91,16 +139,10 @@ func registerToolHandlers(server *mcp.Server, resolver ResolverInterface, opts *
 			InputSchema:  types.CreateTaskToolInputSchema,
 			OutputSchema: types.CreateTaskToolOutputSchema,
 		},
-		func(ctx context.Context, req *mcp.CallToolRequest, input *types.CreateTaskInput) (result *mcp.CallToolResult, output types.CreateTaskOutput, err error) {
-			defer func() {
-				if r := recover(); r != nil {
-					err = opts.RecoverFunc(ctx, r)
-				}
-			}()
-			return resolver.CreateTaskTool(ctx, req, input)
-		},
+		resolver.CreateTaskTool,
+		opts,
 	)
-	mcp.AddTool(
+	mcputil.AddTool(
 		server,
 		&mcp.Tool{
 			Name:        "search",
@@ -111,16 +153,10 @@ func registerToolHandlers(server *mcp.Server, resolver ResolverInterface, opts *
 				IdempotentHint: true,
 			},
 		},
-		func(ctx context.Context, req *mcp.CallToolRequest, input *types.SearchInput) (result *mcp.CallToolResult, output map[string]any, err error) {
-			defer func() {
-				if r := recover(); r != nil {
-					err = opts.RecoverFunc(ctx, r)
-				}
-			}()
-			return resolver.SearchTool(ctx, req, input)
-		},
+		resolver.SearchTool,
+		opts,
 	)
-	mcp.AddTool(
+	mcputil.AddTool(
 		server,
 		&mcp.Tool{
 			Name:        "get_history",
@@ -131,14 +167,8 @@ func registerToolHandlers(server *mcp.Server, resolver ResolverInterface, opts *
 				IdempotentHint: true,
 			},
 		},
-		func(ctx context.Context, req *mcp.CallToolRequest, input *types.GetHistoryInput) (result *mcp.CallToolResult, output map[string]any, err error) {
-			defer func() {
-				if r := recover(); r != nil {
-					err = opts.RecoverFunc(ctx, r)
-				}
-			}()
-			return resolver.GetHistoryTool(ctx, req, input)
-		},
+		resolver.GetHistoryTool,
+		opts,
 	)
 }
 
@@ -146,7 +176,7 @@ func boolPtr(b bool) *bool {
 	return &b
 }
 
-func registerResourceHandlers(server *mcp.Server, resolver ResolverInterface) {
+func registerResourceHandlers(server *mcp.Server, resolver ResolverInterface, opts *mcputil.Options) {
 	server.AddResource(
 		&mcp.Resource{
 			URI:         "docs://readme",
@@ -4,68 +4,22 @@ package types
 
 import (
 	"encoding/json"
-	"fmt"
-	"go.probo.inc/mcpgen/mcp"
 	"time"
+
+	"go.probo.inc/mcpgen/mcp"
 )
 
 // Tool input schemas
 var (
-	Calculate2ToolInputSchema  = mcp.MustUnmarshalSchema(`{"type":"object","required":["title","priority"],"properties":{"completed":{"type":"boolean","description":"Whether task is completed"},"deadline":{"type":"string","description":"Task deadline","format":"date-time"},"priority":{"type":"string","description":"Task priority level","enum":["low","medium","high","urgent"]},"tags":{"type":"array","description":"Task tags","items":{"type":"string"}},"title":{"type":"string","description":"Task title"}}}`)
-	CalculateToolInputSchema   = mcp.MustUnmarshalSchema(`{"type":"object","required":["operation","a","b"],"properties":{"a":{"type":"number","description":"First operand"},"b":{"type":"number","description":"Second operand"},"operation":{"type":"string","description":"The arithmetic operation to perform","enum":["add","subtract","multiply","divide"]}}}`)
-	CalculateToolOutputSchema  = mcp.MustUnmarshalSchema(`{"type":"object","properties":{"operation":{"type":"string","description":"The operation that was performed"},"value":{"type":"number","description":"The result value"}}}`)
-	CreateTaskToolInputSchema  = mcp.MustUnmarshalSchema(`{"type":"object","required":["title","priority"],"properties":{"completed":{"type":"boolean","description":"Whether task is completed"},"deadline":{"type":"string","description":"Task deadline","format":"date-time"},"priority":{"type":"string","description":"Task priority level","enum":["low","medium","high","urgent"]},"tags":{"type":"array","description":"Task tags","items":{"type":"string"}},"title":{"type":"string","description":"Task title"}}}`)
-	CreateTaskToolOutputSchema = mcp.MustUnmarshalSchema(`{"type":"object","properties":{"createdAt":{"type":"string","description":"Creation timestamp","format":"date-time"},"id":{"type":"string","description":"Task ID"},"priority":{"type":"string","description":"Priority level","enum":["low","medium","high","urgent"]},"status":{"type":"string","description":"Task status","enum":["pending","in_progress","completed","cancelled"]},"title":{"type":"string","description":"Task title"}}}`)
-	GetHistoryToolInputSchema  = mcp.MustUnmarshalSchema(`{"type":"object","properties":{"limit":{"type":"integer","description":"Maximum number of history entries","default":10}}}`)
-	SearchToolInputSchema      = mcp.MustUnmarshalSchema(`{"type":"object","required":["query"],"properties":{"filter":{"type":"string","description":"Filter results","enum":["all","active","completed"]},"limit":{"type":"integer","description":"Maximum number of results","default":10},"query":{"type":"string","description":"Search query"}}}`)
-)
-
-// Task priority level
-type Calculate2InputPriority string
-
-const (
-	Calculate2InputPriorityLow    Calculate2InputPriority = "low"
-	Calculate2InputPriorityMedium Calculate2InputPriority = "medium"
-	Calculate2InputPriorityHigh   Calculate2InputPriority = "high"
-	Calculate2InputPriorityUrgent Calculate2InputPriority = "urgent"
+	Calculate2ToolInputSchema  = mcp.MustUnmarshalSchema(`{"properties":{"completed":{"description":"Whether task is completed","type":"boolean"},"deadline":{"description":"Task deadline","format":"date-time","type":"string"},"priority":{"description":"Task priority level","enum":["low","medium","high","urgent"],"type":"string"},"tags":{"description":"Task tags","items":{"type":"string"},"type":"array"},"title":{"description":"Task title","type":"string"}},"required":["title","priority"],"type":"object"}`)
+	CalculateToolInputSchema   = mcp.MustUnmarshalSchema(`{"properties":{"a":{"description":"First operand","type":"number"},"b":{"description":"Second operand","type":"number"},"operation":{"description":"The arithmetic operation to perform","enum":["add","subtract","multiply","divide"],"type":"string"}},"required":["operation","a","b"],"type":"object"}`)
+	CalculateToolOutputSchema  = mcp.MustUnmarshalSchema(`{"properties":{"operation":{"description":"The operation that was performed","type":"string"},"value":{"description":"The result value","type":"number"}},"type":"object"}`)
+	CreateTaskToolInputSchema  = mcp.MustUnmarshalSchema(`{"properties":{"completed":{"description":"Whether task is completed","type":"boolean"},"deadline":{"description":"Task deadline","format":"date-time","type":"string"},"priority":{"description":"Task priority level","enum":["low","medium","high","urgent"],"type":"string"},"tags":{"description":"Task tags","items":{"type":"string"},"type":"array"},"title":{"description":"Task title","type":"string"}},"required":["title","priority"],"type":"object"}`)
+	CreateTaskToolOutputSchema = mcp.MustUnmarshalSchema(`{"properties":{"createdAt":{"description":"Creation timestamp","format":"date-time","type":"string"},"id":{"description":"Task ID","type":"string"},"priority":{"description":"Priority level","enum":["low","medium","high","urgent"],"type":"string"},"status":{"description":"Task status","enum":["pending","in_progress","completed","cancelled"],"type":"string"},"title":{"description":"Task title","type":"string"}},"type":"object"}`)
+	GetHistoryToolInputSchema  = mcp.MustUnmarshalSchema(`{"properties":{"limit":{"default":10,"description":"Maximum number of history entries","type":"integer"}},"type":"object"}`)
+	SearchToolInputSchema      = mcp.MustUnmarshalSchema(`{"properties":{"filter":{"description":"Filter results","enum":["all","active","completed"],"type":"string"},"limit":{"default":10,"description":"Maximum number of results","type":"integer"},"query":{"description":"Search query","type":"string"}},"required":["query"],"type":"object"}`)
 )
 
-// IsValid returns true if the Calculate2InputPriority value is valid
-func (e Calculate2InputPriority) IsValid() bool {
-	switch e {
-	case Calculate2InputPriorityLow:
-		return true
-	case Calculate2InputPriorityMedium:
-		return true
-	case Calculate2InputPriorityHigh:
-		return true
-	case Calculate2InputPriorityUrgent:
-		return true
-	}
-	return false
-}
-
-// UnmarshalJSON implements json.Unmarshaler
-func (e *Calculate2InputPriority) UnmarshalJSON(data []byte) error {
-	var s string
-	if err := json.Unmarshal(data, &s); err != nil {
-		return err
-	}
-	*e = Calculate2InputPriority(s)
-	if !e.IsValid() {
-		return fmt.Errorf("invalid Calculate2InputPriority value: %q", s)
-	}
-	return nil
-}
-
-// MarshalJSON implements json.Marshaler
-func (e Calculate2InputPriority) MarshalJSON() ([]byte, error) {
-	if !e.IsValid() {
-		return nil, fmt.Errorf("invalid Calculate2InputPriority value: %q", string(e))
-	}
-	return json.Marshal(string(e))
-}
-
 // The arithmetic operation to perform
 type CalculateInputOperation string
 
@@ -91,161 +45,79 @@ func (e CalculateInputOperation) IsValid() bool {
 	return false
 }
 
-// UnmarshalJSON implements json.Unmarshaler
-func (e *CalculateInputOperation) UnmarshalJSON(data []byte) error {
-	var s string
-	if err := json.Unmarshal(data, &s); err != nil {
-		return err
-	}
-	*e = CalculateInputOperation(s)
-	if !e.IsValid() {
-		return fmt.Errorf("invalid CalculateInputOperation value: %q", s)
-	}
-	return nil
-}
-
-// MarshalJSON implements json.Marshaler
-func (e CalculateInputOperation) MarshalJSON() ([]byte, error) {
-	if !e.IsValid() {
-		return nil, fmt.Errorf("invalid CalculateInputOperation value: %q", string(e))
-	}
-	return json.Marshal(string(e))
-}
-
-// Task priority level
-type CreateTaskInputPriority string
-
-const (
-	CreateTaskInputPriorityLow    CreateTaskInputPriority = "low"
-	CreateTaskInputPriorityMedium CreateTaskInputPriority = "medium"
-	CreateTaskInputPriorityHigh   CreateTaskInputPriority = "high"
-	CreateTaskInputPriorityUrgent CreateTaskInputPriority = "urgent"
-)
-
-// IsValid returns true if the CreateTaskInputPriority value is valid
-func (e CreateTaskInputPriority) IsValid() bool {
-	switch e {
-	case CreateTaskInputPriorityLow:
-		return true
-	case CreateTaskInputPriorityMedium:
-		return true
-	case CreateTaskInputPriorityHigh:
-		return true
-	case CreateTaskInputPriorityUrgent:
-		return true
+// AllCalculateInputOperationValues returns the values of CalculateInputOperation, in the order of the schema
+func AllCalculateInputOperationValues() []CalculateInputOperation {
+	return []CalculateInputOperation{
+		CalculateInputOperationAdd,
+		CalculateInputOperationSubtract,
+		CalculateInputOperationMultiply,
+		CalculateInputOperationDivide,
 	}
-	return false
 }
 
-// UnmarshalJSON implements json.Unmarshaler
-func (e *CreateTaskInputPriority) UnmarshalJSON(data []byte) error {
-	var s string
-	if err := json.Unmarshal(data, &s); err != nil {
-		return err
-	}
-	*e = CreateTaskInputPriority(s)
-	if !e.IsValid() {
-		return fmt.Errorf("invalid CreateTaskInputPriority value: %q", s)
+// Values returns the values of CalculateInputOperation as strings, in the order of the schema
+func (CalculateInputOperation) Values() []string {
+	return []string{
+		"add",
+		"subtract",
+		"multiply",
+		"divide",
 	}
-	return nil
 }
 
-// MarshalJSON implements json.Marshaler
-func (e CreateTaskInputPriority) MarshalJSON() ([]byte, error) {
-	if !e.IsValid() {
-		return nil, fmt.Errorf("invalid CreateTaskInputPriority value: %q", string(e))
-	}
-	return json.Marshal(string(e))
+// CalculateInputOperationNames maps the values of CalculateInputOperation to the names of their constants
+var CalculateInputOperationNames = map[CalculateInputOperation]string{
+	CalculateInputOperationAdd:      "Add",
+	CalculateInputOperationSubtract: "Subtract",
+	CalculateInputOperationMultiply: "Multiply",
+	CalculateInputOperationDivide:   "Divide",
 }
 
-// Priority level
-type CreateTaskOutputPriority string
-
-const (
-	CreateTaskOutputPriorityLow    CreateTaskOutputPriority = "low"
-	CreateTaskOutputPriorityMedium CreateTaskOutputPriority = "medium"
-	CreateTaskOutputPriorityHigh   CreateTaskOutputPriority = "high"
-	CreateTaskOutputPriorityUrgent CreateTaskOutputPriority = "urgent"
-)
-
-// IsValid returns true if the CreateTaskOutputPriority value is valid
-func (e CreateTaskOutputPriority) IsValid() bool {
-	switch e {
-	case CreateTaskOutputPriorityLow:
-		return true
-	case CreateTaskOutputPriorityMedium:
-		return true
-	case CreateTaskOutputPriorityHigh:
-		return true
-	case CreateTaskOutputPriorityUrgent:
-		return true
-	}
-	return false
+// CalculateInputOperationByName maps the names of the constants of CalculateInputOperation to their values
+var CalculateInputOperationByName = map[string]CalculateInputOperation{
+	"Add":      CalculateInputOperationAdd,
+	"Subtract": CalculateInputOperationSubtract,
+	"Multiply": CalculateInputOperationMultiply,
+	"Divide":   CalculateInputOperationDivide,
 }
 
-// UnmarshalJSON implements json.Unmarshaler
-func (e *CreateTaskOutputPriority) UnmarshalJSON(data []byte) error {
-	var s string
-	if err := json.Unmarshal(data, &s); err != nil {
-		return err
+// ParseCalculateInputOperation converts a value of CalculateInputOperation, or the name of its constant, to a CalculateInputOperation
+func ParseCalculateInputOperation(s string) (CalculateInputOperation, error) {
+	if e := CalculateInputOperation(s); e.IsValid() {
+		return e, nil
 	}
-	*e = CreateTaskOutputPriority(s)
-	if !e.IsValid() {
-		return fmt.Errorf("invalid CreateTaskOutputPriority value: %q", s)
+	if e, ok := CalculateInputOperationByName[s]; ok {
+		return e, nil
 	}
-	return nil
+	return "", mcp.NewError(mcp.MessageInvalidEnumValue, "CalculateInputOperation", s)
 }
 
-// MarshalJSON implements json.Marshaler
-func (e CreateTaskOutputPriority) MarshalJSON() ([]byte, error) {
+// MustCalculateInputOperation converts v to a CalculateInputOperation, and panics if v is not one of its values
+func MustCalculateInputOperation(v string) CalculateInputOperation {
+	e := CalculateInputOperation(v)
 	if !e.IsValid() {
-		return nil, fmt.Errorf("invalid CreateTaskOutputPriority value: %q", string(e))
-	}
-	return json.Marshal(string(e))
-}
-
-// Task status
-type CreateTaskOutputStatus string
-
-const (
-	CreateTaskOutputStatusPending    CreateTaskOutputStatus = "pending"
-	CreateTaskOutputStatusInProgress CreateTaskOutputStatus = "in_progress"
-	CreateTaskOutputStatusCompleted  CreateTaskOutputStatus = "completed"
-	CreateTaskOutputStatusCancelled  CreateTaskOutputStatus = "cancelled"
-)
-
-// IsValid returns true if the CreateTaskOutputStatus value is valid
-func (e CreateTaskOutputStatus) IsValid() bool {
-	switch e {
-	case CreateTaskOutputStatusPending:
-		return true
-	case CreateTaskOutputStatusInProgress:
-		return true
-	case CreateTaskOutputStatusCompleted:
-		return true
-	case CreateTaskOutputStatusCancelled:
-		return true
+		panic(mcp.NewError(mcp.MessageInvalidEnumValue, "CalculateInputOperation", v))
 	}
-	return false
+	return e
 }
 
 // UnmarshalJSON implements json.Unmarshaler
-func (e *CreateTaskOutputStatus) UnmarshalJSON(data []byte) error {
+func (e *CalculateInputOperation) UnmarshalJSON(data []byte) error {
 	var s string
 	if err := json.Unmarshal(data, &s); err != nil {
 		return err
 	}
-	*e = CreateTaskOutputStatus(s)
+	*e = CalculateInputOperation(s)
 	if !e.IsValid() {
-		return fmt.Errorf("invalid CreateTaskOutputStatus value: %q", s)
+		return mcp.NewError(mcp.MessageInvalidEnumValue, "CalculateInputOperation", s)
 	}
 	return nil
 }
 
 // MarshalJSON implements json.Marshaler
-func (e CreateTaskOutputStatus) MarshalJSON() ([]byte, error) {
+func (e CalculateInputOperation) MarshalJSON() ([]byte, error) {
 	if !e.IsValid() {
-		return nil, fmt.Errorf("invalid CreateTaskOutputStatus value: %q", string(e))
+		return nil, mcp.NewError(mcp.MessageInvalidEnumValue, "CalculateInputOperation", string(e))
 	}
 	return json.Marshal(string(e))
 }
@@ -272,115 +144,75 @@ func (e SearchInputFilter) IsValid() bool {
 	return false
 }
 
-// UnmarshalJSON implements json.Unmarshaler
-func (e *SearchInputFilter) UnmarshalJSON(data []byte) error {
-	var s string
-	if err := json.Unmarshal(data, &s); err != nil {
-		return err
-	}
-	*e = SearchInputFilter(s)
-	if !e.IsValid() {
-		return fmt.Errorf("invalid SearchInputFilter value: %q", s)
+// AllSearchInputFilterValues returns the values of SearchInputFilter, in the order of the schema
+func AllSearchInputFilterValues() []SearchInputFilter {
+	return []SearchInputFilter{
+		SearchInputFilterAll,
+		SearchInputFilterActive,
+		SearchInputFilterCompleted,
 	}
-	return nil
 }
 
-// MarshalJSON implements json.Marshaler
-func (e SearchInputFilter) MarshalJSON() ([]byte, error) {
-	if !e.IsValid() {
-		return nil, fmt.Errorf("invalid SearchInputFilter value: %q", string(e))
+// Values returns the values of SearchInputFilter as strings, in the order of the schema
+func (SearchInputFilter) Values() []string {
+	return []string{
+		"all",
+		"active",
+		"completed",
 	}
-	return json.Marshal(string(e))
 }
 
-// Priority level
-type TaskDetailsContentPriority string
-
-const (
-	TaskDetailsContentPriorityLow    TaskDetailsContentPriority = "low"
-	TaskDetailsContentPriorityMedium TaskDetailsContentPriority = "medium"
-	TaskDetailsContentPriorityHigh   TaskDetailsContentPriority = "high"
-	TaskDetailsContentPriorityUrgent TaskDetailsContentPriority = "urgent"
-)
+// SearchInputFilterNames maps the values of SearchInputFilter to the names of their constants
+var SearchInputFilterNames = map[SearchInputFilter]string{
+	SearchInputFilterAll:       "All",
+	SearchInputFilterActive:    "Active",
+	SearchInputFilterCompleted: "Completed",
+}
 
-// IsValid returns true if the TaskDetailsContentPriority value is valid
-func (e TaskDetailsContentPriority) IsValid() bool {
-	switch e {
-	case TaskDetailsContentPriorityLow:
-		return true
-	case TaskDetailsContentPriorityMedium:
-		return true
-	case TaskDetailsContentPriorityHigh:
-		return true
-	case TaskDetailsContentPriorityUrgent:
-		return true
-	}
-	return false
+// SearchInputFilterByName maps the names of the constants of SearchInputFilter to their values
+var SearchInputFilterByName = map[string]SearchInputFilter{
+	"All":       SearchInputFilterAll,
+	"Active":    SearchInputFilterActive,
+	"Completed": SearchInputFilterCompleted,
 }
 
-// UnmarshalJSON implements json.Unmarshaler
-func (e *TaskDetailsContentPriority) UnmarshalJSON(data []byte) error {
-	var s string
-	if err := json.Unmarshal(data, &s); err != nil {
-		return err
+// ParseSearchInputFilter converts a value of SearchInputFilter, or the name of its constant, to a SearchInputFilter
+func ParseSearchInputFilter(s string) (SearchInputFilter, error) {
+	if e := SearchInputFilter(s); e.IsValid() {
+		return e, nil
 	}
-	*e = TaskDetailsContentPriority(s)
-	if !e.IsValid() {
-		return fmt.Errorf("invalid TaskDetailsContentPriority value: %q", s)
+	if e, ok := SearchInputFilterByName[s]; ok {
+		return e, nil
 	}
-	return nil
+	return "", mcp.NewError(mcp.MessageInvalidEnumValue, "SearchInputFilter", s)
 }
 
-// MarshalJSON implements json.Marshaler
-func (e TaskDetailsContentPriority) MarshalJSON() ([]byte, error) {
+// MustSearchInputFilter converts v to a SearchInputFilter, and panics if v is not one of its values
+func MustSearchInputFilter(v string) SearchInputFilter {
+	e := SearchInputFilter(v)
 	if !e.IsValid() {
-		return nil, fmt.Errorf("invalid TaskDetailsContentPriority value: %q", string(e))
+		panic(mcp.NewError(mcp.MessageInvalidEnumValue, "SearchInputFilter", v))
 	}
-	return json.Marshal(string(e))
-}
-
-// Task status
-type TaskDetailsContentStatus string
-
-const (
-	TaskDetailsContentStatusPending    TaskDetailsContentStatus = "pending"
-	TaskDetailsContentStatusInProgress TaskDetailsContentStatus = "in_progress"
-	TaskDetailsContentStatusCompleted  TaskDetailsContentStatus = "completed"
-	TaskDetailsContentStatusCancelled  TaskDetailsContentStatus = "cancelled"
-)
-
-// IsValid returns true if the TaskDetailsContentStatus value is valid
-func (e TaskDetailsContentStatus) IsValid() bool {
-	switch e {
-	case TaskDetailsContentStatusPending:
-		return true
-	case TaskDetailsContentStatusInProgress:
-		return true
-	case TaskDetailsContentStatusCompleted:
-		return true
-	case TaskDetailsContentStatusCancelled:
-		return true
-	}
-	return false
+	return e
 }
 
 // UnmarshalJSON implements json.Unmarshaler
-func (e *TaskDetailsContentStatus) UnmarshalJSON(data []byte) error {
+func (e *SearchInputFilter) UnmarshalJSON(data []byte) error {
 	var s string
 	if err := json.Unmarshal(data, &s); err != nil {
 		return err
 	}
-	*e = TaskDetailsContentStatus(s)
+	*e = SearchInputFilter(s)
 	if !e.IsValid() {
-		return fmt.Errorf("invalid TaskDetailsContentStatus value: %q", s)
+		return mcp.NewError(mcp.MessageInvalidEnumValue, "SearchInputFilter", s)
 	}
 	return nil
 }
 
 // MarshalJSON implements json.Marshaler
-func (e TaskDetailsContentStatus) MarshalJSON() ([]byte, error) {
+func (e SearchInputFilter) MarshalJSON() ([]byte, error) {
 	if !e.IsValid() {
-		return nil, fmt.Errorf("invalid TaskDetailsContentStatus value: %q", string(e))
+		return nil, mcp.NewError(mcp.MessageInvalidEnumValue, "SearchInputFilter", string(e))
 	}
 	return json.Marshal(string(e))
 }
@@ -410,6 +242,62 @@ func (e TaskDetailsPriority) IsValid() bool {
 	return false
 }
 
+// AllTaskDetailsPriorityValues returns the values of TaskDetailsPriority, in the order of the schema
+func AllTaskDetailsPriorityValues() []TaskDetailsPriority {
+	return []TaskDetailsPriority{
+		TaskDetailsPriorityLow,
+		TaskDetailsPriorityMedium,
+		TaskDetailsPriorityHigh,
+		TaskDetailsPriorityUrgent,
+	}
+}
+
+// Values returns the values of TaskDetailsPriority as strings, in the order of the schema
+func (TaskDetailsPriority) Values() []string {
+	return []string{
+		"low",
+		"medium",
+		"high",
+		"urgent",
+	}
+}
+
+// TaskDetailsPriorityNames maps the values of TaskDetailsPriority to the names of their constants
+var TaskDetailsPriorityNames = map[TaskDetailsPriority]string{
+	TaskDetailsPriorityLow:    "Low",
+	TaskDetailsPriorityMedium: "Medium",
+	TaskDetailsPriorityHigh:   "High",
+	TaskDetailsPriorityUrgent: "Urgent",
+}
+
+// TaskDetailsPriorityByName maps the names of the constants of TaskDetailsPriority to their values
+var TaskDetailsPriorityByName = map[string]TaskDetailsPriority{
+	"Low":    TaskDetailsPriorityLow,
+	"Medium": TaskDetailsPriorityMedium,
+	"High":   TaskDetailsPriorityHigh,
+	"Urgent": TaskDetailsPriorityUrgent,
+}
+
+// ParseTaskDetailsPriority converts a value of TaskDetailsPriority, or the name of its constant, to a TaskDetailsPriority
+func ParseTaskDetailsPriority(s string) (TaskDetailsPriority, error) {
+	if e := TaskDetailsPriority(s); e.IsValid() {
+		return e, nil
+	}
+	if e, ok := TaskDetailsPriorityByName[s]; ok {
+		return e, nil
+	}
+	return "", mcp.NewError(mcp.MessageInvalidEnumValue, "TaskDetailsPriority", s)
+}
+
+// MustTaskDetailsPriority converts v to a TaskDetailsPriority, and panics if v is not one of its values
+func MustTaskDetailsPriority(v string) TaskDetailsPriority {
+	e := TaskDetailsPriority(v)
+	if !e.IsValid() {
+		panic(mcp.NewError(mcp.MessageInvalidEnumValue, "TaskDetailsPriority", v))
+	}
+	return e
+}
+
 // UnmarshalJSON implements json.Unmarshaler
 func (e *TaskDetailsPriority) UnmarshalJSON(data []byte) error {
 	var s string
@@ -418,7 +306,7 @@ func (e *TaskDetailsPriority) UnmarshalJSON(data []byte) error {
 	}
 	*e = TaskDetailsPriority(s)
 	if !e.IsValid() {
-		return fmt.Errorf("invalid TaskDetailsPriority value: %q", s)
+		return mcp.NewError(mcp.MessageInvalidEnumValue, "TaskDetailsPriority", s)
 	}
 	return nil
 }
@@ -426,7 +314,7 @@ func (e *TaskDetailsPriority) UnmarshalJSON(data []byte) error {
 // MarshalJSON implements json.Marshaler
 func (e TaskDetailsPriority) MarshalJSON() ([]byte, error) {
 	if !e.IsValid() {
-		return nil, fmt.Errorf("invalid TaskDetailsPriority value: %q", string(e))
+		return nil, mcp.NewError(mcp.MessageInvalidEnumValue, "TaskDetailsPriority", string(e))
 	}
 	return json.Marshal(string(e))
 }
@@ -456,6 +344,62 @@ func (e TaskDetailsStatus) IsValid() bool {
 	return false
 }
 
+// AllTaskDetailsStatusValues returns the values of TaskDetailsStatus, in the order of the schema
+func AllTaskDetailsStatusValues() []TaskDetailsStatus {
+	return []TaskDetailsStatus{
+		TaskDetailsStatusPending,
+		TaskDetailsStatusInProgress,
+		TaskDetailsStatusCompleted,
+		TaskDetailsStatusCancelled,
+	}
+}
+
+// Values returns the values of TaskDetailsStatus as strings, in the order of the schema
+func (TaskDetailsStatus) Values() []string {
+	return []string{
+		"pending",
+		"in_progress",
+		"completed",
+		"cancelled",
+	}
+}
+
+// TaskDetailsStatusNames maps the values of TaskDetailsStatus to the names of their constants
+var TaskDetailsStatusNames = map[TaskDetailsStatus]string{
+	TaskDetailsStatusPending:    "Pending",
+	TaskDetailsStatusInProgress: "InProgress",
+	TaskDetailsStatusCompleted:  "Completed",
+	TaskDetailsStatusCancelled:  "Cancelled",
+}
+
+// TaskDetailsStatusByName maps the names of the constants of TaskDetailsStatus to their values
+var TaskDetailsStatusByName = map[string]TaskDetailsStatus{
+	"Pending":    TaskDetailsStatusPending,
+	"InProgress": TaskDetailsStatusInProgress,
+	"Completed":  TaskDetailsStatusCompleted,
+	"Cancelled":  TaskDetailsStatusCancelled,
+}
+
+// ParseTaskDetailsStatus converts a value of TaskDetailsStatus, or the name of its constant, to a TaskDetailsStatus
+func ParseTaskDetailsStatus(s string) (TaskDetailsStatus, error) {
+	if e := TaskDetailsStatus(s); e.IsValid() {
+		return e, nil
+	}
+	if e, ok := TaskDetailsStatusByName[s]; ok {
+		return e, nil
+	}
+	return "", mcp.NewError(mcp.MessageInvalidEnumValue, "TaskDetailsStatus", s)
+}
+
+// MustTaskDetailsStatus converts v to a TaskDetailsStatus, and panics if v is not one of its values
+func MustTaskDetailsStatus(v string) TaskDetailsStatus {
+	e := TaskDetailsStatus(v)
+	if !e.IsValid() {
+		panic(mcp.NewError(mcp.MessageInvalidEnumValue, "TaskDetailsStatus", v))
+	}
+	return e
+}
+
 // UnmarshalJSON implements json.Unmarshaler
 func (e *TaskDetailsStatus) UnmarshalJSON(data []byte) error {
 	var s string
@@ -464,7 +408,7 @@ func (e *TaskDetailsStatus) UnmarshalJSON(data []byte) error {
 	}
 	*e = TaskDetailsStatus(s)
 	if !e.IsValid() {
-		return fmt.Errorf("invalid TaskDetailsStatus value: %q", s)
+		return mcp.NewError(mcp.MessageInvalidEnumValue, "TaskDetailsStatus", s)
 	}
 	return nil
 }
@@ -472,7 +416,7 @@ func (e *TaskDetailsStatus) UnmarshalJSON(data []byte) error {
 // MarshalJSON implements json.Marshaler
 func (e TaskDetailsStatus) MarshalJSON() ([]byte, error) {
 	if !e.IsValid() {
-		return nil, fmt.Errorf("invalid TaskDetailsStatus value: %q", string(e))
+		return nil, mcp.NewError(mcp.MessageInvalidEnumValue, "TaskDetailsStatus", string(e))
 	}
 	return json.Marshal(string(e))
 }
@@ -502,6 +446,62 @@ func (e TaskInputPriority) IsValid() bool {
 	return false
 }
 
+// AllTaskInputPriorityValues returns the values of TaskInputPriority, in the order of the schema
+func AllTaskInputPriorityValues() []TaskInputPriority {
+	return []TaskInputPriority{
+		TaskInputPriorityLow,
+		TaskInputPriorityMedium,
+		TaskInputPriorityHigh,
+		TaskInputPriorityUrgent,
+	}
+}
+
+// Values returns the values of TaskInputPriority as strings, in the order of the schema
+func (TaskInputPriority) Values() []string {
+	return []string{
+		"low",
+		"medium",
+		"high",
+		"urgent",
+	}
+}
+
+// TaskInputPriorityNames maps the values of TaskInputPriority to the names of their constants
+var TaskInputPriorityNames = map[TaskInputPriority]string{
+	TaskInputPriorityLow:    "Low",
+	TaskInputPriorityMedium: "Medium",
+	TaskInputPriorityHigh:   "High",
+	TaskInputPriorityUrgent: "Urgent",
+}
+
+// TaskInputPriorityByName maps the names of the constants of TaskInputPriority to their values
+var TaskInputPriorityByName = map[string]TaskInputPriority{
+	"Low":    TaskInputPriorityLow,
+	"Medium": TaskInputPriorityMedium,
+	"High":   TaskInputPriorityHigh,
+	"Urgent": TaskInputPriorityUrgent,
+}
+
+// ParseTaskInputPriority converts a value of TaskInputPriority, or the name of its constant, to a TaskInputPriority
+func ParseTaskInputPriority(s string) (TaskInputPriority, error) {
+	if e := TaskInputPriority(s); e.IsValid() {
+		return e, nil
+	}
+	if e, ok := TaskInputPriorityByName[s]; ok {
+		return e, nil
+	}
+	return "", mcp.NewError(mcp.MessageInvalidEnumValue, "TaskInputPriority", s)
+}
+
+// MustTaskInputPriority converts v to a TaskInputPriority, and panics if v is not one of its values
+func MustTaskInputPriority(v string) TaskInputPriority {
+	e := TaskInputPriority(v)
+	if !e.IsValid() {
+		panic(mcp.NewError(mcp.MessageInvalidEnumValue, "TaskInputPriority", v))
+	}
+	return e
+}
+
 // UnmarshalJSON implements json.Unmarshaler
 func (e *TaskInputPriority) UnmarshalJSON(data []byte) error {
 	var s string
@@ -510,7 +510,7 @@ func (e *TaskInputPriority) UnmarshalJSON(data []byte) error {
 	}
 	*e = TaskInputPriority(s)
 	if !e.IsValid() {
-		return fmt.Errorf("invalid TaskInputPriority value: %q", s)
+		return mcp.NewError(mcp.MessageInvalidEnumValue, "TaskInputPriority", s)
 	}
 	return nil
 }
@@ -518,7 +518,7 @@ func (e *TaskInputPriority) UnmarshalJSON(data []byte) error {
 // MarshalJSON implements json.Marshaler
 func (e TaskInputPriority) MarshalJSON() ([]byte, error) {
 	if !e.IsValid() {
-		return nil, fmt.Errorf("invalid TaskInputPriority value: %q", string(e))
+		return nil, mcp.NewError(mcp.MessageInvalidEnumValue, "TaskInputPriority", string(e))
 	}
 	return json.Marshal(string(e))
 }
@@ -530,7 +530,7 @@ type Calculate2Input struct {
 	// Task deadline
 	Deadline *time.Time `json:"deadline,omitempty"`
 	// Task priority level
-	Priority Calculate2InputPriority `json:"priority"`
+	Priority TaskInputPriority `json:"priority"`
 	// Task tags
 	Tags []string `json:"tags,omitempty"`
 	// Task title
@@ -562,7 +562,7 @@ type CreateTaskInput struct {
 	// Task deadline
 	Deadline *time.Time `json:"deadline,omitempty"`
 	// Task priority level
-	Priority CreateTaskInputPriority `json:"priority"`
+	Priority TaskInputPriority `json:"priority"`
 	// Task tags
 	Tags []string `json:"tags,omitempty"`
 	// Task title
@@ -576,9 +576,9 @@ type CreateTaskOutput struct {
 	// Task ID
 	ID *string `json:"id,omitempty"`
 	// Priority level
-	Priority *CreateTaskOutputPriority `json:"priority,omitempty"`
+	Priority *TaskDetailsPriority `json:"priority,omitempty"`
 	// Task status
-	Status *CreateTaskOutputStatus `json:"status,omitempty"`
+	Status *TaskDetailsStatus `json:"status,omitempty"`
 	// Task title
 	Title *string `json:"title,omitempty"`
 }
@@ -589,6 +589,14 @@ type GetHistoryInput struct {
 	Limit *int `json:"limit,omitempty"`
 }
 
+// ApplyDefaults sets the optional fields left unset to their schema default
+func (v *GetHistoryInput) ApplyDefaults() {
+	if v.Limit == nil {
+		value := int(10)
+		v.Limit = &value
+	}
+}
+
 // LastResultContent represents the schema
 type LastResultContent struct {
 	// The operation that was performed
@@ -621,6 +629,14 @@ type SearchInput struct {
 	Query string `json:"query"`
 }
 
+// ApplyDefaults sets the optional fields left unset to their schema default
+func (v *SearchInput) ApplyDefaults() {
+	if v.Limit == nil {
+		value := int(10)
+		v.Limit = &value
+	}
+}
+
 // TaskDetails represents the schema
 type TaskDetails struct {
 	// Creation timestamp
@@ -642,9 +658,9 @@ type TaskDetailsContent struct {
 	// Task ID
 	ID *string `json:"id,omitempty"`
 	// Priority level
-	Priority *TaskDetailsContentPriority `json:"priority,omitempty"`
+	Priority *TaskDetailsPriority `json:"priority,omitempty"`
 	// Task status
-	Status *TaskDetailsContentStatus `json:"status,omitempty"`
+	Status *TaskDetailsStatus `json:"status,omitempty"`
 	// Task title
 	Title *string `json:"title,omitempty"`
 }
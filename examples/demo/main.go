@@ -2,38 +2,28 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 
 	mcp_v1 "demo/generated"
 	"demo/generated/server"
-	"github.com/modelcontextprotocol/go-sdk/mcp"
+	mcputil "go.probo.inc/mcpgen/mcp"
 )
 
 func main() {
-	// Create resolver and get fully configured MCP server
-	resolver := mcp_v1.NewResolver()
-	mcpServer := server.New(resolver)
+	// Select transports from MCP_TRANSPORT (stdio, http, both) and
+	// MCP_HTTP_ADDR, then let command line flags override them:
+	//
+	//	demo-server -http :8080               # stdio and HTTP
+	//	demo-server -stdio=false -http :8080  # HTTP only
+	cfg := mcputil.RunConfigFromEnv()
+	cfg.RegisterFlags(flag.CommandLine)
+	flag.Parse()
 
-	// Example 1: Use with stdio transport
-	transport := &mcp.StdioTransport{}
-	ctx := context.Background()
-	log.Println("Starting MCP server with stdio...")
-	if err := mcpServer.Run(ctx, transport); err != nil {
+	// Create resolver and serve until interrupted
+	resolver := mcp_v1.NewResolver()
+	log.Println("Starting MCP server...")
+	if err := server.Run(context.Background(), resolver, cfg); err != nil {
 		log.Fatalf("Server failed: %v", err)
 	}
-
-	// Example 2: Use with HTTP handler (commented out)
-	// getServer := func(r *http.Request) *mcp.Server { return mcpServer }
-	// eventStore := mcp.NewMemoryEventStore(nil)
-	// handler := mcp.NewStreamableHTTPHandler(
-	//     getServer,
-	//     &mcp.StreamableHTTPOptions{
-	//         Stateless:      false,
-	//         SessionTimeout: 30 * time.Minute,
-	//         EventStore:     eventStore,
-	//         Logger:         nil,
-	//     },
-	// )
-	// http.Handle("/mcp", handler)
-	// http.ListenAndServe(":8080", nil)
 }
@@ -0,0 +1,564 @@
+// Package gen produces random values conforming to JSON schemas, such as
+// the input and output schemas of generated tools, for fuzzers, simulators
+// and placeholder handlers. Values are reproducible: two generators created
+// with the same seed produce the same values for the same schemas.
+//
+// Example:
+//
+//	g := gen.New(42)
+//	input, err := gen.Into[types.CalculateInput](g, types.CalculateToolInputSchema)
+package gen
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+const (
+	// DefaultMaxDepth is the nesting depth past which optional properties
+	// are omitted and arrays get their minimum number of items.
+	DefaultMaxDepth = 5
+	// DefaultMaxItems is the number of items arrays and maps get at most
+	// past their minimum.
+	DefaultMaxItems = 3
+	// DefaultAttempts is the number of values generated for a schema before
+	// giving up when none of them is valid.
+	DefaultAttempts = 20
+)
+
+// Generator produces random values conforming to JSON schemas. It is not
+// safe for concurrent use.
+type Generator struct {
+	rand     *rand.Rand
+	maxDepth int
+	maxItems int
+	attempts int
+}
+
+// Option configures a Generator.
+type Option func(*Generator)
+
+// WithMaxDepth sets the nesting depth past which optional properties are
+// omitted. Defaults to DefaultMaxDepth.
+func WithMaxDepth(depth int) Option {
+	return func(g *Generator) {
+		g.maxDepth = depth
+	}
+}
+
+// WithMaxItems sets the number of items arrays get at most past their
+// minItems. Defaults to DefaultMaxItems.
+func WithMaxItems(items int) Option {
+	return func(g *Generator) {
+		g.maxItems = items
+	}
+}
+
+// WithAttempts sets the number of values generated for a schema before
+// giving up when none of them is valid. Defaults to DefaultAttempts.
+func WithAttempts(attempts int) Option {
+	return func(g *Generator) {
+		g.attempts = attempts
+	}
+}
+
+// New returns a generator whose values are determined by seed.
+func New(seed uint64, opts ...Option) *Generator {
+	g := &Generator{
+		rand:     rand.New(rand.NewPCG(seed, seed)),
+		maxDepth: DefaultMaxDepth,
+		maxItems: DefaultMaxItems,
+		attempts: DefaultAttempts,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// Value returns a random value conforming to s, made of the types
+// encoding/json decodes to: map[string]any, []any, string, float64, bool and
+// nil. Keywords that are not generated from, such as not or oneOf branches
+// that overlap, are honored by validating each value against s and
+// generating another one when it is invalid.
+func (g *Generator) Value(s *jsonschema.Schema) (any, error) {
+	resolved, err := s.Resolve(nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve schema: %w", err)
+	}
+
+	var lastErr error
+	for range max(g.attempts, 1) {
+		value, err := (&walker{g: g, root: s}).value(s, 0)
+		if err != nil {
+			return nil, err
+		}
+		if lastErr = resolved.Validate(value); lastErr == nil {
+			return value, nil
+		}
+	}
+	return nil, fmt.Errorf("no valid value generated in %d attempts: %w", max(g.attempts, 1), lastErr)
+}
+
+// JSON returns the JSON encoding of a random value conforming to s.
+func (g *Generator) JSON(s *jsonschema.Schema) (json.RawMessage, error) {
+	value, err := g.Value(s)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(value)
+}
+
+// Into returns a random value of T conforming to s, T being the Go type
+// generated for s.
+func Into[T any](g *Generator, s *jsonschema.Schema) (T, error) {
+	var value T
+	data, err := g.JSON(s)
+	if err != nil {
+		return value, err
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return value, fmt.Errorf("cannot decode generated value into %T: %w", value, err)
+	}
+	return value, nil
+}
+
+// walker generates one value, resolving references against its root schema.
+type walker struct {
+	g    *Generator
+	root *jsonschema.Schema
+}
+
+func (w *walker) value(s *jsonschema.Schema, depth int) (any, error) {
+	if s == nil {
+		return w.any(), nil
+	}
+
+	if s.Ref != "" {
+		target, err := w.resolveRef(s.Ref)
+		if err != nil {
+			return nil, err
+		}
+		return w.value(target, depth)
+	}
+
+	if s.Const != nil {
+		return *s.Const, nil
+	}
+	if len(s.Enum) > 0 {
+		return s.Enum[w.g.rand.IntN(len(s.Enum))], nil
+	}
+
+	if len(s.AllOf) > 0 {
+		return w.allOf(s, depth)
+	}
+	if len(s.OneOf) > 0 {
+		return w.value(s.OneOf[w.g.rand.IntN(len(s.OneOf))], depth)
+	}
+	if len(s.AnyOf) > 0 {
+		return w.value(s.AnyOf[w.g.rand.IntN(len(s.AnyOf))], depth)
+	}
+
+	switch w.typeOf(s) {
+	case "null":
+		return nil, nil
+	case "boolean":
+		return w.g.rand.IntN(2) == 1, nil
+	case "integer":
+		return w.number(s, true)
+	case "number":
+		return w.number(s, false)
+	case "string":
+		return w.string(s)
+	case "array":
+		return w.array(s, depth)
+	case "object":
+		return w.object(s, depth)
+	default:
+		return w.any(), nil
+	}
+}
+
+// resolveRef returns the schema a reference within the root schema points
+// to: the root itself or one of its definitions.
+func (w *walker) resolveRef(ref string) (*jsonschema.Schema, error) {
+	if ref == "#" {
+		return w.root, nil
+	}
+	for prefix, defs := range map[string]map[string]*jsonschema.Schema{
+		"#/$defs/":       w.root.Defs,
+		"#/definitions/": w.root.Definitions,
+	} {
+		name, ok := strings.CutPrefix(ref, prefix)
+		if !ok {
+			continue
+		}
+		name = strings.ReplaceAll(strings.ReplaceAll(name, "~1", "/"), "~0", "~")
+		if target, ok := defs[name]; ok {
+			return target, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot resolve reference %q", ref)
+}
+
+// typeOf returns the type of the values to generate for s, picked among its
+// types, or inferred from its keywords when it has none.
+func (w *walker) typeOf(s *jsonschema.Schema) string {
+	if s.Type != "" {
+		return s.Type
+	}
+	if len(s.Types) > 0 {
+		// Prefer the non-null types
+		types := make([]string, 0, len(s.Types))
+		for _, t := range s.Types {
+			if t != "null" {
+				types = append(types, t)
+			}
+		}
+		if len(types) == 0 {
+			return "null"
+		}
+		return types[w.g.rand.IntN(len(types))]
+	}
+
+	switch {
+	case s.Properties != nil || s.Required != nil || s.AdditionalProperties != nil || s.MinProperties != nil:
+		return "object"
+	case s.Items != nil || s.PrefixItems != nil || s.Contains != nil || s.MinItems != nil:
+		return "array"
+	case s.Pattern != "" || s.Format != "" || s.MinLength != nil || s.MaxLength != nil:
+		return "string"
+	case s.Minimum != nil || s.Maximum != nil || s.ExclusiveMinimum != nil || s.ExclusiveMaximum != nil || s.MultipleOf != nil:
+		return "number"
+	}
+	return ""
+}
+
+// any returns a value of any type, for schemas accepting everything.
+func (w *walker) any() any {
+	switch w.g.rand.IntN(3) {
+	case 0:
+		return w.letters(1 + w.g.rand.IntN(8))
+	case 1:
+		return float64(w.g.rand.IntN(100))
+	default:
+		return w.g.rand.IntN(2) == 1
+	}
+}
+
+// allOf merges the objects generated for the subschemas of s and for s
+// itself; when they are not all objects, the last value wins.
+func (w *walker) allOf(s *jsonschema.Schema, depth int) (any, error) {
+	own := *s
+	own.AllOf = nil
+	subs := slices.Clone(s.AllOf)
+	if w.typeOf(&own) != "" || own.Ref != "" || own.Const != nil || len(own.Enum) > 0 || len(own.OneOf) > 0 || len(own.AnyOf) > 0 {
+		subs = append(subs, &own)
+	}
+
+	var merged any
+	for _, sub := range subs {
+		value, err := w.value(sub, depth)
+		if err != nil {
+			return nil, err
+		}
+		object, ok := value.(map[string]any)
+		into, isObject := merged.(map[string]any)
+		if !ok || !isObject {
+			merged = value
+			continue
+		}
+		for name, v := range object {
+			if _, exists := into[name]; !exists {
+				into[name] = v
+			}
+		}
+	}
+	return merged, nil
+}
+
+func (w *walker) number(s *jsonschema.Schema, integer bool) (any, error) {
+	lo, hi := math.Inf(-1), math.Inf(1)
+	if s.Minimum != nil {
+		lo = *s.Minimum
+	}
+	if s.ExclusiveMinimum != nil {
+		lo = math.Max(lo, math.Nextafter(*s.ExclusiveMinimum, math.Inf(1)))
+		if integer {
+			lo = math.Max(lo, math.Floor(*s.ExclusiveMinimum)+1)
+		}
+	}
+	if s.Maximum != nil {
+		hi = *s.Maximum
+	}
+	if s.ExclusiveMaximum != nil {
+		hi = math.Min(hi, math.Nextafter(*s.ExclusiveMaximum, math.Inf(-1)))
+		if integer {
+			hi = math.Min(hi, math.Ceil(*s.ExclusiveMaximum)-1)
+		}
+	}
+
+	// Keep values small when a bound is missing
+	switch {
+	case math.IsInf(lo, -1) && math.IsInf(hi, 1):
+		lo, hi = 0, 100
+	case math.IsInf(lo, -1):
+		lo = hi - 100
+	case math.IsInf(hi, 1):
+		hi = lo + 100
+	}
+
+	step := 0.0
+	if s.MultipleOf != nil && *s.MultipleOf > 0 {
+		step = *s.MultipleOf
+	} else if integer {
+		step = 1
+	}
+
+	if step == 0 {
+		if lo > hi {
+			return nil, fmt.Errorf("empty range [%v, %v]", lo, hi)
+		}
+		return lo + w.g.rand.Float64()*(hi-lo), nil
+	}
+
+	first, last := math.Ceil(lo/step), math.Floor(hi/step)
+	if first > last {
+		return nil, fmt.Errorf("no multiple of %v in [%v, %v]", step, lo, hi)
+	}
+	n := first + float64(w.g.rand.Int64N(int64(last-first)+1))
+	return n * step, nil
+}
+
+func (w *walker) string(s *jsonschema.Schema) (any, error) {
+	if s.Pattern != "" {
+		value, err := w.matching(s.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("cannot generate a string matching %q: %w", s.Pattern, err)
+		}
+		return value, nil
+	}
+	if value, ok := w.format(s.Format); ok {
+		return value, nil
+	}
+
+	minLength, maxLength := 0, 0
+	if s.MinLength != nil {
+		minLength = *s.MinLength
+	}
+	if s.MaxLength != nil {
+		maxLength = *s.MaxLength
+	} else {
+		maxLength = max(minLength, 1) + 10
+	}
+	if minLength > maxLength {
+		return nil, fmt.Errorf("minLength %d greater than maxLength %d", minLength, maxLength)
+	}
+	if minLength == 0 && maxLength > 0 {
+		minLength = 1
+	}
+	return w.letters(minLength + w.g.rand.IntN(maxLength-minLength+1)), nil
+}
+
+// format returns a string in a well-known format.
+func (w *walker) format(format string) (string, bool) {
+	r := w.g.rand
+	date := time.Date(2000+r.IntN(30), time.Month(1+r.IntN(12)), 1+r.IntN(28), r.IntN(24), r.IntN(60), r.IntN(60), 0, time.UTC)
+
+	switch format {
+	case "date-time":
+		return date.Format(time.RFC3339), true
+	case "date":
+		return date.Format(time.DateOnly), true
+	case "time":
+		return date.Format("15:04:05Z07:00"), true
+	case "duration":
+		return "PT" + strconv.Itoa(1+r.IntN(59)) + "M", true
+	case "email", "idn-email":
+		return w.letters(6) + "@example.com", true
+	case "hostname", "idn-hostname":
+		return w.letters(6) + ".example.com", true
+	case "uri", "iri", "uri-reference", "iri-reference":
+		return "https://example.com/" + w.letters(6), true
+	case "uuid":
+		b := make([]byte, 16)
+		for i := range b {
+			b[i] = byte(r.IntN(256))
+		}
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), true
+	case "ipv4":
+		return fmt.Sprintf("%d.%d.%d.%d", 1+r.IntN(254), r.IntN(256), r.IntN(256), 1+r.IntN(254)), true
+	case "ipv6":
+		return fmt.Sprintf("2001:db8::%x:%x", r.IntN(0x10000), r.IntN(0x10000)), true
+	}
+	return "", false
+}
+
+func (w *walker) letters(n int) string {
+	const alphabet = "abcdefghijklmnopqrstuvwxyz"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphabet[w.g.rand.IntN(len(alphabet))]
+	}
+	return string(b)
+}
+
+func (w *walker) array(s *jsonschema.Schema, depth int) (any, error) {
+	minItems, maxItems := 0, 0
+	if s.MinItems != nil {
+		minItems = *s.MinItems
+	}
+	minContains := 0
+	if s.Contains != nil {
+		minContains = 1
+		if s.MinContains != nil {
+			minContains = *s.MinContains
+		}
+	}
+	minItems = max(minItems, len(s.PrefixItems), minContains)
+
+	maxItems = minItems
+	if depth < w.g.maxDepth {
+		maxItems += w.g.maxItems
+	}
+	if s.MaxItems != nil && *s.MaxItems < maxItems {
+		maxItems = *s.MaxItems
+	}
+	if minItems > maxItems {
+		return nil, fmt.Errorf("minItems %d greater than maxItems %d", minItems, maxItems)
+	}
+	count := minItems + w.g.rand.IntN(maxItems-minItems+1)
+
+	items := make([]any, 0, count)
+	seen := map[string]bool{}
+	for i := 0; len(items) < count; i++ {
+		if i > count*w.g.attempts {
+			// Settle for fewer items when the item schema has few values
+			if len(items) < minItems {
+				return nil, fmt.Errorf("cannot generate %d unique items", minItems)
+			}
+			break
+		}
+
+		position := len(items)
+		item := s.Items
+		switch {
+		case position < len(s.PrefixItems):
+			item = s.PrefixItems[position]
+		case position < len(s.PrefixItems)+minContains:
+			item = s.Contains
+		}
+
+		value, err := w.value(item, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if s.UniqueItems {
+			key, _ := json.Marshal(value)
+			if seen[string(key)] {
+				continue
+			}
+			seen[string(key)] = true
+		}
+		items = append(items, value)
+	}
+	return items, nil
+}
+
+func (w *walker) object(s *jsonschema.Schema, depth int) (any, error) {
+	object := map[string]any{}
+
+	// Keep the draws reproducible despite the random map order
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	required := map[string]bool{}
+	for _, name := range s.Required {
+		required[name] = true
+	}
+	if s.Then != nil {
+		for _, name := range s.Then.Required {
+			required[name] = true
+		}
+	}
+
+	for _, name := range names {
+		if !required[name] && (depth >= w.g.maxDepth || w.g.rand.IntN(2) == 0) {
+			continue
+		}
+		value, err := w.value(s.Properties[name], depth+1)
+		if err != nil {
+			return nil, err
+		}
+		object[name] = value
+	}
+
+	// Required properties the schema does not declare
+	for _, name := range s.Required {
+		if _, ok := object[name]; ok {
+			continue
+		}
+		value, err := w.value(w.extraProperty(s), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		object[name] = value
+	}
+
+	if s.Then != nil {
+		then, err := w.value(s.Then, depth)
+		if err != nil {
+			return nil, err
+		}
+		if then, ok := then.(map[string]any); ok {
+			for name, value := range then {
+				if _, exists := object[name]; !exists {
+					object[name] = value
+				}
+			}
+		}
+	}
+
+	if s.MinProperties != nil {
+		for i := 1; len(object) < *s.MinProperties; i++ {
+			name := "property" + strconv.Itoa(i)
+			if _, exists := object[name]; exists {
+				continue
+			}
+			property := w.extraProperty(s)
+			if s.Properties[name] != nil {
+				property = s.Properties[name]
+			}
+			value, err := w.value(property, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			object[name] = value
+		}
+	}
+
+	return object, nil
+}
+
+// extraProperty returns the schema of the properties of s not declared in
+// its properties.
+func (w *walker) extraProperty(s *jsonschema.Schema) *jsonschema.Schema {
+	if s.AdditionalProperties != nil {
+		return s.AdditionalProperties
+	}
+	return &jsonschema.Schema{Type: "string"}
+}
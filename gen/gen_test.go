@@ -0,0 +1,139 @@
+package gen
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustSchema(t *testing.T, schemaJSON string) *jsonschema.Schema {
+	t.Helper()
+	var s jsonschema.Schema
+	require.NoError(t, json.Unmarshal([]byte(schemaJSON), &s))
+	return &s
+}
+
+func TestValue(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema string
+	}{
+		{"enum", `{"type":"string","enum":["low","medium","high"]}`},
+		{"const", `{"const":{"kind":"fixed"}}`},
+		{"bounded integer", `{"type":"integer","minimum":3,"exclusiveMaximum":7}`},
+		{"multiple of", `{"type":"number","minimum":0.5,"maximum":10,"multipleOf":0.25}`},
+		{"negative number", `{"type":"number","maximum":-20}`},
+		{"string length", `{"type":"string","minLength":4,"maxLength":6}`},
+		{"pattern", `{"type":"string","pattern":"^[A-Z]{3}-\\d{2,4}$"}`},
+		{"date-time", `{"type":"string","format":"date-time"}`},
+		{"nullable", `{"type":["string","null"],"maxLength":2}`},
+		{"unique items", `{"type":"array","items":{"type":"integer","minimum":0,"maximum":3},"minItems":3,"uniqueItems":true}`},
+		{"tuple", `{"type":"array","prefixItems":[{"type":"string"},{"type":"boolean"}],"items":false}`},
+		{"contains", `{"type":"array","items":{"type":"integer"},"contains":{"const":42},"minContains":2}`},
+		{"object", `{
+			"type":"object",
+			"properties":{
+				"id":{"type":"string","format":"uuid"},
+				"tags":{"type":"array","items":{"type":"string"}},
+				"owner":{"type":"object","properties":{"email":{"type":"string","format":"email"}},"required":["email"]}
+			},
+			"required":["id","owner"],
+			"additionalProperties":false
+		}`},
+		{"map", `{"type":"object","additionalProperties":{"type":"integer"},"minProperties":2}`},
+		{"recursive", `{
+			"$defs":{"node":{"type":"object","properties":{"value":{"type":"integer"},"children":{"type":"array","items":{"$ref":"#/$defs/node"}}},"required":["value"]}},
+			"$ref":"#/$defs/node"
+		}`},
+		{"all of", `{"allOf":[{"type":"object","properties":{"a":{"type":"string"}},"required":["a"]},{"properties":{"b":{"type":"integer"}},"required":["b"]}]}`},
+		{"one of", `{"oneOf":[{"type":"string","maxLength":3},{"type":"integer","minimum":10}]}`},
+		{"if then else", `{
+			"type":"object",
+			"properties":{"kind":{"enum":["card","transfer"]}},
+			"required":["kind"],
+			"if":{"properties":{"kind":{"const":"card"}}},
+			"then":{"properties":{"number":{"type":"string","pattern":"^\\d{16}$"}},"required":["number"]},
+			"else":{"properties":{"iban":{"type":"string"}}}
+		}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := mustSchema(t, tt.schema)
+			resolved, err := s.Resolve(nil)
+			require.NoError(t, err)
+
+			for seed := range uint64(50) {
+				value, err := New(seed).Value(s)
+				require.NoError(t, err, "seed %d", seed)
+				assert.NoError(t, resolved.Validate(value), "seed %d: %#v", seed, value)
+			}
+		})
+	}
+}
+
+func TestValueReproducible(t *testing.T) {
+	s := mustSchema(t, `{
+		"type":"object",
+		"properties":{
+			"name":{"type":"string"},
+			"count":{"type":"integer"},
+			"ratio":{"type":"number"},
+			"tags":{"type":"array","items":{"type":"string"}}
+		}
+	}`)
+
+	first, err := New(7).JSON(s)
+	require.NoError(t, err)
+	second, err := New(7).JSON(s)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(first), string(second))
+
+	// A generator keeps drawing new values
+	g := New(7)
+	_, err = g.JSON(s)
+	require.NoError(t, err)
+	third, err := g.JSON(s)
+	require.NoError(t, err)
+	assert.NotEqual(t, string(first), string(third))
+}
+
+func TestValueErrors(t *testing.T) {
+	_, err := New(1).Value(mustSchema(t, `{"type":"integer","minimum":5,"maximum":4}`))
+	assert.ErrorContains(t, err, "no multiple of 1 in [5, 4]")
+
+	_, err = New(1).Value(mustSchema(t, `{"type":"array","items":{"type":"boolean"},"minItems":3,"uniqueItems":true}`))
+	assert.ErrorContains(t, err, "cannot generate 3 unique items")
+
+	_, err = New(1, WithAttempts(3)).Value(mustSchema(t, `{"type":"string","not":{"type":"string"}}`))
+	assert.ErrorContains(t, err, "no valid value generated in 3 attempts")
+}
+
+func TestInto(t *testing.T) {
+	type task struct {
+		Title    string     `json:"title"`
+		Priority *string    `json:"priority,omitempty"`
+		Deadline *time.Time `json:"deadline,omitempty"`
+	}
+
+	s := mustSchema(t, `{
+		"type":"object",
+		"properties":{
+			"title":{"type":"string","minLength":1},
+			"priority":{"type":"string","enum":["low","high"]},
+			"deadline":{"type":"string","format":"date-time"}
+		},
+		"required":["title","priority","deadline"]
+	}`)
+
+	value, err := Into[task](New(3), s)
+	require.NoError(t, err)
+	assert.NotEmpty(t, value.Title)
+	require.NotNil(t, value.Priority)
+	assert.Contains(t, []string{"low", "high"}, *value.Priority)
+	assert.NotNil(t, value.Deadline)
+}
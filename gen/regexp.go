@@ -0,0 +1,106 @@
+package gen
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+)
+
+// maxRepeat is the number of repetitions past the minimum generated for the
+// unbounded repetitions of a pattern, such as a* or a{2,}.
+const maxRepeat = 5
+
+// matching returns a random string matching pattern. Patterns are not
+// anchored in JSON Schema, so the whole string matching the pattern is
+// enough: anchors are ignored.
+func (w *walker) matching(pattern string) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	if err := w.writeMatch(&b, re.Simplify()); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+func (w *walker) writeMatch(b *strings.Builder, re *syntax.Regexp) error {
+	r := w.g.rand
+
+	switch re.Op {
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return nil
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+		return nil
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteByte(w.letters(1)[0])
+		return nil
+	case syntax.OpCharClass:
+		// Rune holds the inclusive ranges of the class, as pairs. Prefer
+		// printable ASCII when the class allows it.
+		var printable []rune
+		for i := 0; i < len(re.Rune); i += 2 {
+			if lo, hi := max(re.Rune[i], ' '), min(re.Rune[i+1], '~'); lo <= hi {
+				printable = append(printable, lo, hi)
+			}
+		}
+		ranges := re.Rune
+		if len(printable) > 0 {
+			ranges = printable
+		}
+
+		total := 0
+		for i := 0; i < len(ranges); i += 2 {
+			total += int(ranges[i+1]-ranges[i]) + 1
+		}
+		if total == 0 {
+			return fmt.Errorf("empty character class")
+		}
+		n := r.IntN(total)
+		for i := 0; i < len(ranges); i += 2 {
+			size := int(ranges[i+1]-ranges[i]) + 1
+			if n < size {
+				b.WriteRune(ranges[i] + rune(n))
+				break
+			}
+			n -= size
+		}
+		return nil
+	case syntax.OpCapture:
+		return w.writeMatch(b, re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if err := w.writeMatch(b, sub); err != nil {
+				return err
+			}
+		}
+		return nil
+	case syntax.OpAlternate:
+		return w.writeMatch(b, re.Sub[r.IntN(len(re.Sub))])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		lo, hi := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			lo, hi = 0, -1
+		case syntax.OpPlus:
+			lo, hi = 1, -1
+		case syntax.OpQuest:
+			lo, hi = 0, 1
+		}
+		if hi < 0 {
+			hi = lo + maxRepeat
+		}
+		for range lo + r.IntN(hi-lo+1) {
+			if err := w.writeMatch(b, re.Sub[0]); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported pattern operator %s", re.Op)
+	}
+}
@@ -0,0 +1,34 @@
+package gen
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatching(t *testing.T) {
+	patterns := []string{
+		`^[a-z0-9._%+-]+@[a-z0-9.-]+\.[a-z]{2,}$`,
+		`^\+?[1-9]\d{1,14}$`,
+		`^(draft|published|archived)$`,
+		`^[A-F0-9]{8}(-[A-F0-9]{4}){3}-[A-F0-9]{12}$`,
+		`v\d+\.\d+(\.\d+)?`,
+		`^[^\s/]+/[^\s/]+$`,
+		`^\p{Lu}\w*$`,
+		`(?i)^abc$`,
+	}
+
+	for _, pattern := range patterns {
+		t.Run(pattern, func(t *testing.T) {
+			re := regexp.MustCompile(pattern)
+			w := &walker{g: New(11)}
+			for range 50 {
+				value, err := w.matching(pattern)
+				require.NoError(t, err)
+				assert.Regexp(t, re, value)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package codegen
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"go.probo.inc/mcpgen/internal/schema"
+)
+
+// flattenAllOf returns an object schema merging the properties and required
+// lists of an allOf composition with those of s, such as a component
+// extending a base component, so that it generates a single struct. It
+// returns nil when a part of the composition is not an object.
+//
+// When several parts declare the same property, the first declaration with
+// a type wins.
+func (g *TypeGenerator) flattenAllOf(s *schema.Schema) *schema.Schema {
+	if len(s.AllOf) == 0 {
+		return nil
+	}
+
+	flat := *s
+	flat.AllOf = nil
+	flat.Type = "object"
+	flat.Types = nil
+	flat.Properties = map[string]*schema.Schema{}
+	flat.Required = nil
+	// The merged properties keep the spec order of the parts recording it
+	flat.Extra = maps.Clone(s.Extra)
+	delete(flat.Extra, schema.PropertyOrderKey)
+
+	if !g.mergeObject(&flat, s, map[string]bool{}) {
+		return nil
+	}
+	return &flat
+}
+
+// mergeObject adds the properties and required properties of part, its
+// allOf parts included, to flat. It returns false when part is not an
+// object. Components already merged are in seen, to stop at cycles.
+func (g *TypeGenerator) mergeObject(flat *schema.Schema, part *schema.Schema, seen map[string]bool) bool {
+	if part == nil {
+		return true
+	}
+
+	if part.Ref != "" {
+		name, ok := strings.CutPrefix(part.Ref, "#/components/schemas/")
+		if !ok || g.customMappings[name] != nil {
+			return false
+		}
+		if seen[name] {
+			return true
+		}
+		seen[name] = true
+		return g.mergeObject(flat, g.schemas[name], seen)
+	}
+
+	if t := schema.GetType(part); t != "" && t != "object" {
+		return false
+	}
+	if len(part.Enum) > 0 || part.Const != nil || len(part.OneOf) > 0 || len(part.AnyOf) > 0 {
+		return false
+	}
+
+	for _, sub := range part.AllOf {
+		if !g.mergeObject(flat, sub, seen) {
+			return false
+		}
+	}
+
+	_, ordered := part.Extra[schema.PropertyOrderKey]
+	for _, name := range schema.PropertyNames(part) {
+		prop := part.Properties[name]
+		if existing, ok := flat.Properties[name]; ok && isTyped(existing) {
+			continue
+		}
+		flat.Properties[name] = prop
+		if ordered {
+			if flat.Extra == nil {
+				flat.Extra = map[string]any{}
+			}
+			order, _ := flat.Extra[schema.PropertyOrderKey].([]any)
+			if !slices.Contains(order, any(name)) {
+				flat.Extra[schema.PropertyOrderKey] = append(order, name)
+			}
+		}
+	}
+
+	for _, name := range part.Required {
+		if !slices.Contains(flat.Required, name) {
+			flat.Required = append(flat.Required, name)
+		}
+	}
+
+	return true
+}
+
+// isTyped reports whether a property schema says what its values are,
+// rather than only documenting or constraining them.
+func isTyped(s *schema.Schema) bool {
+	return s != nil && (schema.GetType(s) != "" || s.Ref != "" || len(s.Properties) > 0 || len(s.Enum) > 0 || s.Const != nil ||
+		len(s.AllOf) > 0 || len(s.AnyOf) > 0 || len(s.OneOf) > 0)
+}
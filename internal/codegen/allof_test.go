@@ -0,0 +1,99 @@
+package codegen
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+func TestFlattenAllOf(t *testing.T) {
+	schemas := map[string]string{
+		"Pet": `{
+			"type": "object",
+			"properties": {"id": {"type": "string"}, "name": {"type": "string", "description": "Pet name"}},
+			"required": ["id", "name"]
+		}`,
+		"Dog": `{
+			"description": "A dog.",
+			"allOf": [
+				{"$ref": "#/components/schemas/Pet"},
+				{
+					"type": "object",
+					"properties": {"breed": {"type": "string"}, "name": {"description": "Name of the dog"}},
+					"required": ["breed", "id"]
+				}
+			]
+		}`,
+		"Owner": `{
+			"type": "object",
+			"properties": {
+				"dog": {"description": "The dog of the owner", "allOf": [{"$ref": "#/components/schemas/Dog"}]},
+				"cat": {"allOf": [{"$ref": "#/components/schemas/Pet"}, {"properties": {"indoor": {"type": "boolean"}}}]},
+				"tag": {"allOf": [{"type": "string"}, {"maxLength": 8}]}
+			}
+		}`,
+	}
+
+	gen := NewTypeGenerator()
+	for name, data := range schemas {
+		var s config.Schema
+		require.NoError(t, json.Unmarshal([]byte(data), &s))
+		gen.AddSchema(name, &s)
+	}
+	code, err := gen.Generate("test")
+	require.NoError(t, err)
+	codeStr := string(code)
+
+	t.Run("component extending a base", func(t *testing.T) {
+		assert.Contains(t, codeStr, "// A dog.\ntype Dog struct {")
+		assert.Regexp(t, "type Dog struct {\n\tBreed +string `json:\"breed\"`\n\tID +string `json:\"id\"`\n\t// Pet name\n\tName string `json:\"name\"`\n}", codeStr)
+	})
+
+	t.Run("single reference", func(t *testing.T) {
+		assert.Contains(t, codeStr, "\t// The dog of the owner\n\tDog *Dog ")
+	})
+
+	t.Run("inline composition", func(t *testing.T) {
+		assert.Contains(t, codeStr, "Cat *OwnerCat ")
+		assert.Regexp(t, "type OwnerCat struct {\n\tID +string +`json:\"id\"`\n\tIndoor \\*bool +`json:\"indoor,omitempty\"`", codeStr)
+	})
+
+	t.Run("composition of non-objects", func(t *testing.T) {
+		assert.Contains(t, codeStr, "Tag *any ")
+	})
+}
+
+func TestFlattenAllOfPropertyOrder(t *testing.T) {
+	schemas := map[string]string{
+		"Pet": `{
+			"type": "object",
+			"properties": {"name": {"type": "string"}, "id": {"type": "string"}},
+			"go.probo.inc/mcpgen/property-order": ["name", "id"]
+		}`,
+		"Dog": `{
+			"allOf": [
+				{"$ref": "#/components/schemas/Pet"},
+				{
+					"type": "object",
+					"properties": {"breed": {"type": "string"}, "age": {"type": "integer"}},
+					"go.probo.inc/mcpgen/property-order": ["breed", "age"]
+				}
+			]
+		}`,
+	}
+
+	gen := NewTypeGenerator()
+	for name, data := range schemas {
+		var s config.Schema
+		require.NoError(t, json.Unmarshal([]byte(data), &s))
+		gen.AddSchema(name, &s)
+	}
+	code, err := gen.Generate("test")
+	require.NoError(t, err)
+
+	assert.Regexp(t, "type Pet struct {\n\tName \\*string .*\n\tID +\\*string ", string(code))
+	assert.Regexp(t, "type Dog struct {\n\tName +\\*string .*\n\tID +\\*string .*\n\tBreed +\\*string .*\n\tAge +\\*int ", string(code))
+}
@@ -0,0 +1,125 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// autobinder finds the exported types of the packages listed in the
+// autobind configuration, to use them for the component schemas of the same
+// name instead of generating new types.
+type autobinder struct {
+	packages []string
+	// dir is the directory go list runs in, to find the packages of the
+	// module of the generated code.
+	dir string
+	// types caches the exported type names of each package.
+	types map[string]map[string]bool
+}
+
+func newAutobinder(packages []string, dir string) *autobinder {
+	return &autobinder{
+		packages: packages,
+		dir:      dir,
+		types:    make(map[string]map[string]bool),
+	}
+}
+
+// bind returns the qualified Go type, such as
+// github.com/myorg/app/models.Task, of the first package declaring typeName,
+// or an empty string when none does.
+func (b *autobinder) bind(typeName string) (string, error) {
+	for _, pkg := range b.packages {
+		types, err := b.exportedTypes(pkg)
+		if err != nil {
+			return "", err
+		}
+		if types[typeName] {
+			return pkg + "." + typeName, nil
+		}
+	}
+	return "", nil
+}
+
+func (b *autobinder) exportedTypes(pkg string) (map[string]bool, error) {
+	if types, ok := b.types[pkg]; ok {
+		return types, nil
+	}
+
+	dir, err := b.packageDir(pkg)
+	if err != nil {
+		return nil, err
+	}
+	types, err := parseExportedTypes(dir)
+	if err != nil {
+		return nil, fmt.Errorf("autobind %s: %w", pkg, err)
+	}
+	b.types[pkg] = types
+	return types, nil
+}
+
+// packageDir returns the directory of the source files of pkg, as reported
+// by go list, so that packages of the module, of its dependencies and of the
+// vendor directory are all found.
+func (b *autobinder) packageDir(pkg string) (string, error) {
+	cmd := exec.Command("go", "list", "-find", "-f", "{{.Dir}}", pkg)
+	cmd.Dir = b.dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("autobind %s: cannot find package: %s", pkg, msg)
+		}
+		return "", fmt.Errorf("autobind %s: cannot find package: %w", pkg, err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// parseExportedTypes returns the exported types declared in the Go files of
+// dir. Tests and generated files are skipped, so that a package listed for
+// autobind can also hold the generated models.
+func parseExportedTypes(dir string) (map[string]bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	types := make(map[string]bool)
+	fset := token.NewFileSet()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments|parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		if ast.IsGenerated(file) {
+			continue
+		}
+
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				// Generic types cannot be bound without type arguments
+				if ts, ok := spec.(*ast.TypeSpec); ok && ts.Name.IsExported() && ts.TypeParams == nil {
+					types[ts.Name.Name] = true
+				}
+			}
+		}
+	}
+	return types, nil
+}
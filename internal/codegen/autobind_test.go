@@ -0,0 +1,94 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+}
+
+func TestParseExportedTypes(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"task.go":      "package models\n\ntype Task struct{}\n\ntype (\n\tStatus string\n\tnote struct{}\n)\n\ntype Page[T any] struct{ Items []T }\n",
+		"task_test.go": "package models\n\ntype Fixture struct{}\n",
+		"models.go":    "// Code generated by mcpgen. DO NOT EDIT.\n\npackage models\n\ntype User struct{}\n",
+	})
+
+	types, err := parseExportedTypes(dir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"Task": true, "Status": true}, types)
+}
+
+func TestGenerateAutobind(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"go.mod":                 "module example.com/app\n\ngo 1.21\n",
+		"models/task.go":         "package models\n\ntype Task struct{}\n",
+		"domain/task.go":         "package domain\n\ntype Task struct{}\n\ntype Label string\n",
+		"generated/user.go":      "package generated\n\ntype User struct{}\n",
+		"generated/generated.go": "// Code generated by mcpgen. DO NOT EDIT.\n\npackage generated\n\ntype Note struct{}\n",
+	})
+
+	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Components: config.Components{
+			Schemas: map[string]*config.Schema{
+				"Task":  {Type: "object", Properties: map[string]*config.Schema{"title": {Type: "string"}}},
+				"Label": {Type: "string"},
+				"User":  {Type: "object", Properties: map[string]*config.Schema{"name": {Type: "string"}}},
+				"Note":  {Type: "object", Properties: map[string]*config.Schema{"body": {Type: "string"}}},
+				"Board": {
+					Type: "object",
+					Properties: map[string]*config.Schema{
+						"task":  {Ref: "#/components/schemas/Task"},
+						"label": {Ref: "#/components/schemas/Label"},
+						"owner": {Ref: "#/components/schemas/User"},
+						"note":  {Ref: "#/components/schemas/Note"},
+					},
+				},
+			},
+		},
+	}
+
+	outputDir := filepath.Join(root, "generated")
+	cfg := &config.Config{
+		Output: outputDir,
+		Model: config.ModelConfig{
+			Package:  "generated",
+			Filename: "models.go",
+		},
+		Models: config.ModelsConfig{Models: map[string]config.TypeMapping{
+			"Label": {Model: "example.com/app/models.Label"},
+		}},
+		Autobind: []string{"example.com/app/models", "example.com/app/domain", "example.com/app/generated"},
+	}
+	require.NoError(t, New(cfg, spec).Generate(StageModels))
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "models.go"))
+	require.NoError(t, err, "Failed to read models.go")
+	models := string(content)
+	assert.Regexp(t, `Task +\*models\.Task`, models, "the first package declaring the type wins")
+	assert.Regexp(t, `Label +\*models\.Label`, models, "the models configuration wins")
+	assert.Regexp(t, `Owner +\*User `, models, "types of the models package are used unqualified")
+	assert.Contains(t, models, "type Note struct", "generated types are not bound")
+	assert.NotContains(t, models, "type Task struct")
+	assert.NotContains(t, models, "type User struct")
+	assert.NotContains(t, models, "example.com/app/generated")
+
+	cfg.Autobind = []string{"example.com/app/missing"}
+	err = New(cfg, spec).Generate(StageModels)
+	assert.ErrorContains(t, err, "autobind example.com/app/missing: cannot find package")
+}
@@ -0,0 +1,172 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+
+	"go.probo.inc/mcpgen/internal/schema"
+)
+
+// canonicalJSON encodes v as compact JSON with object keys sorted, except for
+// the properties of schemas recording their spec order, and numbers in a
+// single form, so the schemas embedded in generated code only change
+// when their content does. Integers are written without fraction or exponent
+// and other numbers with the shortest representation that round-trips.
+//
+// Backticks are escaped so the result can be embedded in a raw string literal.
+func canonicalJSON(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := writeCanonical(&buf, doc); err != nil {
+		return nil, err
+	}
+
+	return bytes.ReplaceAll(buf.Bytes(), []byte("`"), []byte(`\u0060`)), nil
+}
+
+func writeCanonical(buf *bytes.Buffer, v any) error {
+	switch v := v.(type) {
+	case map[string]any:
+		// Schemas recording the spec order of their properties write them
+		// in that order, without the annotation
+		order, ordered := v[schema.PropertyOrderKey].([]any)
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			if !ordered || k != schema.PropertyOrderKey {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, k)
+			buf.WriteByte(':')
+			var err error
+			if properties, ok := v[k].(map[string]any); ok && ordered && k == "properties" {
+				err = writeCanonicalObject(buf, properties, orderedKeys(properties, order))
+			} else {
+				err = writeCanonical(buf, v[k])
+			}
+			if err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case []any:
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case json.Number:
+		n, err := canonicalNumber(v)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(n)
+	case string:
+		writeCanonicalString(buf, v)
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case nil:
+		buf.WriteString("null")
+	default:
+		return fmt.Errorf("unexpected JSON value of type %T", v)
+	}
+
+	return nil
+}
+
+// writeCanonicalObject writes the members of m in the order of keys.
+func writeCanonicalObject(buf *bytes.Buffer, m map[string]any, keys []string) error {
+	buf.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		writeCanonicalString(buf, k)
+		buf.WriteByte(':')
+		if err := writeCanonical(buf, m[k]); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// orderedKeys returns the keys of m listed in order, followed by the others
+// sorted.
+func orderedKeys(m map[string]any, order []any) []string {
+	keys := make([]string, 0, len(m))
+	listed := make(map[string]bool, len(m))
+	for _, k := range order {
+		k, ok := k.(string)
+		if _, exists := m[k]; ok && exists && !listed[k] {
+			keys = append(keys, k)
+			listed[k] = true
+		}
+	}
+	rest := make([]string, 0, len(m)-len(keys))
+	for k := range m {
+		if !listed[k] {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
+
+func writeCanonicalString(buf *bytes.Buffer, s string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	// Encoding a string cannot fail
+	_ = enc.Encode(s)
+	// Drop the newline added by Encode
+	buf.Truncate(buf.Len() - 1)
+}
+
+// canonicalNumber formats integral numbers, including 1.0 and 1e2, as
+// integers and other numbers as encoding/json formats a float64.
+func canonicalNumber(n json.Number) (string, error) {
+	if i, err := n.Int64(); err == nil {
+		return strconv.FormatInt(i, 10), nil
+	}
+
+	f, err := n.Float64()
+	if err != nil {
+		return "", fmt.Errorf("invalid number %s: %w", n, err)
+	}
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return strconv.FormatInt(int64(f), 10), nil
+	}
+
+	data, err := json.Marshal(f)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
@@ -0,0 +1,67 @@
+package codegen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.probo.inc/mcpgen/internal/config"
+	"go.probo.inc/mcpgen/internal/schema"
+)
+
+func TestCanonicalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input any
+		want  string
+	}{
+		{
+			name:  "sorted keys",
+			input: map[string]any{"b": 1, "a": map[string]any{"d": true, "c": nil}},
+			want:  `{"a":{"c":null,"d":true},"b":1}`,
+		},
+		{
+			name:  "integral numbers",
+			input: []any{1.0, 1e2, -3, 0.5, 1e21},
+			want:  `[1,100,-3,0.5,1e+21]`,
+		},
+		{
+			name:  "strings",
+			input: "<a href=`x`>",
+			want:  "\"<a href=\\u0060x\\u0060>\"",
+		},
+		{
+			name: "schema",
+			input: &config.Schema{
+				Type:     "object",
+				Required: []string{"name"},
+				Properties: map[string]*config.Schema{
+					"name": {Type: "string", Description: "Name"},
+				},
+			},
+			want: `{"properties":{"name":{"description":"Name","type":"string"}},"required":["name"],"type":"object"}`,
+		},
+		{
+			name: "schema recording its property order",
+			input: &config.Schema{
+				Type: "object",
+				Properties: map[string]*config.Schema{
+					"name": {Type: "string"},
+					"age":  {Type: "integer"},
+					"bio":  {Type: "string"},
+				},
+				Extra: map[string]any{schema.PropertyOrderKey: []any{"name", "age"}},
+			},
+			want: `{"properties":{"name":{"type":"string"},"age":{"type":"integer"},"bio":{"type":"string"}},"type":"object"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := canonicalJSON(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package codegen
+
+import (
+	"strconv"
+	"strings"
+
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+// compositeFunc returns the name of the generated function returning the
+// handler of a composite tool.
+func compositeFunc(tool config.Tool) string {
+	name := toHandlerName(tool.Name)
+	return strings.ToLower(name[:1]) + name[1:] + "CompositeTool"
+}
+
+// compositeData returns the template data of the steps and output mapping
+// of a composite tool, whose step inputs use the types of the models
+// prefixed with typePrefix.
+func (g *Generator) compositeData(tool config.Tool, typePrefix string) map[string]interface{} {
+	tools := make(map[string]config.Tool, len(g.spec.Tools))
+	for _, t := range g.spec.Tools {
+		tools[t.Name] = t
+	}
+
+	steps := make([]map[string]interface{}, 0, len(tool.Steps))
+	for i, step := range tool.Steps {
+		called := tools[step.Tool]
+		stepData := map[string]interface{}{
+			"Index":       i + 1,
+			"Name":        step.StepID(),
+			"ID":          strconv.Quote(step.StepID()),
+			"Tool":        step.Tool,
+			"HandlerName": toHandlerName(step.Tool),
+			"Receiver":    handlerReceiver(called.Group),
+			"NoInput":     called.TakesNoInput(),
+		}
+		if !called.TakesNoInput() {
+			stepData["InputType"] = "map[string]any"
+			if called.InputSchema != nil {
+				stepData["InputType"] = typePrefix + toPascalCase(called.Name) + "Input"
+				stepData["HasInputType"] = true
+			}
+			stepData["Input"] = mappingLiteral(step.Input, "$input")
+		}
+		steps = append(steps, stepData)
+	}
+
+	last := tool.Steps[len(tool.Steps)-1]
+	return map[string]interface{}{
+		"Func":   compositeFunc(tool),
+		"Steps":  steps,
+		"Output": mappingLiteral(tool.Output, "$steps."+last.StepID()),
+	}
+}
+
+// mappingLiteral returns the Go string literal of the JSON encoding of a
+// data mapping, or of fallback when it is not set.
+func mappingLiteral(mapping any, fallback string) string {
+	if mapping == nil {
+		mapping = fallback
+	}
+	return fixtureLiteral(mapping)
+}
@@ -0,0 +1,72 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+func TestGenerateCompositeTools(t *testing.T) {
+	object := &config.Schema{
+		Type:       "object",
+		Properties: map[string]*config.Schema{"id": {Type: "string"}, "name": {Type: "string"}},
+	}
+	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Tools: []config.Tool{
+			{Name: "create_user", InputSchema: object, OutputSchema: object},
+			{Name: "ping", NoInput: true},
+			{
+				Name:         "onboard",
+				InputSchema:  object,
+				OutputSchema: object,
+				Steps: []config.ToolStep{
+					{Tool: "create_user", Input: map[string]any{"name": "$input.name"}},
+					{Tool: "ping"},
+					{ID: "welcome", Tool: "create_user"},
+				},
+			},
+		},
+	}
+	require.NoError(t, spec.Validate())
+
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Output:   outputDir,
+		Exec:     config.ExecConfig{Package: "test", Filename: "server.go"},
+		Model:    config.ModelConfig{Package: "test", Filename: "models.go"},
+		Resolver: config.ResolverConfig{Package: "test", Filename: "resolver.go", Type: "Resolver"},
+	}
+	require.NoError(t, New(cfg, spec).Generate(StageModels, StageServer, StageResolver))
+
+	server, err := os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(server), "OnboardTool(", "composite tools have no resolver handler")
+	assert.Contains(t, string(server), "\t\tonboardCompositeTool(resolver),\n")
+	assert.Contains(t, string(server), `func onboardCompositeTool(resolver ResolverInterface) mcp.ToolHandlerFor[*OnboardInput, OnboardOutput] {`)
+	assert.Contains(t, string(server), `
+		// Step create_user: create_user
+		step1Input, err := mcputil.StepInput[CreateUserInput](call, "create_user", "{\"name\":\"$input.name\"}")
+		if err != nil {
+			return nil, output, err
+		}
+		step1Result, step1Output, err := resolver.CreateUserTool(ctx, req, &step1Input)
+		if err := call.Done("create_user", step1Result, step1Output, err); err != nil {
+			return nil, output, err
+		}
+
+		// Step ping: ping
+		step2Result, step2Output, err := resolver.PingTool(ctx, req)
+`)
+	assert.Contains(t, string(server), `step3Input, err := mcputil.StepInput[CreateUserInput](call, "welcome", "\"$input\"")`, "steps take the input of the composite tool by default")
+	assert.Contains(t, string(server), `output, err = mcputil.CompositeOutput[OnboardOutput](call, "\"$steps.welcome\"")`, "composite tools return the output of their last step by default")
+
+	resolver, err := os.ReadFile(filepath.Join(outputDir, "schema.resolvers.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(resolver), "CreateUserTool(")
+	assert.NotContains(t, string(resolver), "OnboardTool(")
+}
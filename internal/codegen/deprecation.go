@@ -0,0 +1,94 @@
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+// deprecationWarnings lists the deprecated tools, which are still served,
+// and the deprecated component schemas and properties still referenced by
+// tools, resources or components that are not deprecated themselves.
+func (g *Generator) deprecationWarnings() []string {
+	var warnings []string
+
+	for _, tool := range g.spec.Tools {
+		switch {
+		case tool.Deprecated && tool.ReplacedBy != "":
+			warnings = append(warnings, fmt.Sprintf("tools.%s is deprecated in favor of %s and still served", tool.Name, tool.ReplacedBy))
+		case tool.Deprecated:
+			warnings = append(warnings, fmt.Sprintf("tools.%s is deprecated and still served", tool.Name))
+		}
+	}
+
+	references := map[string][]string{}
+	var walk func(path string, s *config.Schema)
+	walk = func(path string, s *config.Schema) {
+		if s == nil {
+			return
+		}
+		if name, ok := strings.CutPrefix(s.Ref, "#/components/schemas/"); ok {
+			if component := g.spec.Components.Schemas[name]; component != nil && component.Deprecated {
+				references[name] = append(references[name], path)
+			}
+		}
+
+		for _, name := range sortedSchemaNames(s.Properties) {
+			prop := s.Properties[name]
+			if prop != nil && prop.Deprecated {
+				warnings = append(warnings, fmt.Sprintf("%s.properties.%s is deprecated and still declared", path, name))
+				continue
+			}
+			walk(path+".properties."+name, prop)
+		}
+		walk(path+".items", s.Items)
+		walk(path+".additionalProperties", s.AdditionalProperties)
+		for i, sub := range s.PrefixItems {
+			walk(fmt.Sprintf("%s.prefixItems[%d]", path, i), sub)
+		}
+		for i, sub := range s.AllOf {
+			walk(fmt.Sprintf("%s.allOf[%d]", path, i), sub)
+		}
+		for i, sub := range s.AnyOf {
+			walk(fmt.Sprintf("%s.anyOf[%d]", path, i), sub)
+		}
+		for i, sub := range s.OneOf {
+			walk(fmt.Sprintf("%s.oneOf[%d]", path, i), sub)
+		}
+	}
+
+	for _, tool := range g.spec.Tools {
+		if tool.Deprecated {
+			continue
+		}
+		walk("tools."+tool.Name+".inputSchema", tool.InputSchema)
+		walk("tools."+tool.Name+".outputSchema", tool.OutputSchema)
+	}
+	for _, resource := range g.spec.Resources {
+		walk("resources."+resource.Name+".schema", resource.Schema)
+	}
+	for _, name := range sortedSchemaNames(g.spec.Components.Schemas) {
+		if s := g.spec.Components.Schemas[name]; !s.Deprecated {
+			walk("components.schemas."+name, s)
+		}
+	}
+
+	for _, name := range sortedSchemaNames(g.spec.Components.Schemas) {
+		if paths := references[name]; len(paths) > 0 {
+			warnings = append(warnings, fmt.Sprintf("components.schemas.%s is deprecated and still referenced by %s", name, strings.Join(paths, ", ")))
+		}
+	}
+
+	return warnings
+}
+
+func sortedSchemaNames(schemas map[string]*config.Schema) []string {
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
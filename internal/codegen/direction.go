@@ -0,0 +1,133 @@
+package codegen
+
+import (
+	"strings"
+
+	"go.probo.inc/mcpgen/internal/schema"
+)
+
+// direction tells whether a type describes data sent by the client (input),
+// returned by the server (output), or both. Input types leave out readOnly
+// properties and output types writeOnly ones, as OpenAPI does.
+type direction int
+
+const (
+	anyDirection direction = iota
+	inputDirection
+	outputDirection
+)
+
+// suffix is appended to the name of the components split by direction.
+func (d direction) suffix() string {
+	switch d {
+	case inputDirection:
+		return "Input"
+	case outputDirection:
+		return "Output"
+	default:
+		return ""
+	}
+}
+
+// AddInputSchema adds a schema describing data sent by clients, such as a
+// tool input: its readOnly properties get no field.
+func (g *TypeGenerator) AddInputSchema(name string, s *schema.Schema) {
+	g.schemas[name] = s
+	g.directions[name] = inputDirection
+}
+
+// AddOutputSchema adds a schema describing data returned to clients, such as
+// a tool output: its writeOnly properties get no field.
+func (g *TypeGenerator) AddOutputSchema(name string, s *schema.Schema) {
+	g.schemas[name] = s
+	g.directions[name] = outputDirection
+}
+
+// skipsProperty reports whether a property gets no field in the type being
+// generated, being readOnly in an input type or writeOnly in an output type.
+func (g *TypeGenerator) skipsProperty(s *schema.Schema) bool {
+	if s == nil {
+		return false
+	}
+	return g.direction == inputDirection && s.ReadOnly || g.direction == outputDirection && s.WriteOnly
+}
+
+// componentTypeName returns the Go type of a component referenced from the
+// type being generated: the Input or Output variant of a split component,
+// or else the type named after the component.
+func (g *TypeGenerator) componentTypeName(name string) string {
+	typeName := toGoTypeName(name)
+	if g.direction != anyDirection && g.isSplitComponent(name) {
+		return typeName + g.direction.suffix()
+	}
+	return typeName
+}
+
+// enumTypeName returns the name of the enum or const type of s, named after
+// hint. An enum nested in a split component is named after the component
+// rather than its variant, and is generated once for both variants and for
+// the tool types copying the component.
+func (g *TypeGenerator) enumTypeName(s *schema.Schema, hint string) string {
+	if name, ok := g.enumNames[s]; ok {
+		return name
+	}
+	name := toGoTypeName(hint)
+	if rest, ok := strings.CutPrefix(name, g.variant); ok && g.variant != "" {
+		name = g.variantOf + rest
+	}
+	g.enumNames[s] = name
+	return name
+}
+
+// isSplitComponent reports whether a component generates an Input and an
+// Output variant instead of a single type, see isSplit.
+func (g *TypeGenerator) isSplitComponent(name string) bool {
+	if split, ok := g.splitComponents[name]; ok {
+		return split
+	}
+	split := g.isSplit(&schema.Schema{Ref: "#/components/schemas/" + name}, map[string]bool{})
+	g.splitComponents[name] = split
+	return split
+}
+
+// isSplit reports whether s declares readOnly or writeOnly properties, in
+// its own properties or in the schemas of its properties, items, allOf
+// parts, oneOf variants and referenced components. Components already
+// walked are in seen, to stop at cycles.
+func (g *TypeGenerator) isSplit(s *schema.Schema, seen map[string]bool) bool {
+	if s == nil {
+		return false
+	}
+
+	if s.Ref != "" {
+		name, ok := strings.CutPrefix(s.Ref, "#/components/schemas/")
+		if !ok || seen[name] || g.customMappings[name] != nil {
+			return false
+		}
+		if split, ok := g.splitComponents[name]; ok {
+			return split
+		}
+		seen[name] = true
+		return g.isSplit(g.schemas[name], seen)
+	}
+
+	for _, prop := range s.Properties {
+		if prop != nil && (prop.ReadOnly || prop.WriteOnly) {
+			return true
+		}
+		if g.isSplit(prop, seen) {
+			return true
+		}
+	}
+
+	subschemas := []*schema.Schema{s.Items, s.AdditionalProperties}
+	subschemas = append(subschemas, s.PrefixItems...)
+	subschemas = append(subschemas, s.AllOf...)
+	subschemas = append(subschemas, s.OneOf...)
+	for _, sub := range subschemas {
+		if g.isSplit(sub, seen) {
+			return true
+		}
+	}
+	return false
+}
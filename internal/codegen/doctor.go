@@ -0,0 +1,197 @@
+package codegen
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+// Requirements of the generated code on the module it is generated into.
+// They follow the go.mod of mcpgen.
+const (
+	requiredGoVersion         = "1.25.3"
+	requiredSDKVersion        = "v1.1.0"
+	requiredJSONSchemaVersion = "v0.3.0"
+)
+
+const (
+	mcpgenModule     = "go.probo.inc/mcpgen"
+	sdkModule        = "github.com/modelcontextprotocol/go-sdk"
+	jsonSchemaModule = "github.com/google/jsonschema-go"
+)
+
+// Diagnostic is the outcome of a doctor check. Fix suggests how to solve
+// the problem when the check failed.
+type Diagnostic struct {
+	Check   string
+	OK      bool
+	Message string
+	Fix     string
+}
+
+func (d Diagnostic) String() string {
+	if d.OK {
+		return fmt.Sprintf("✓ %s: %s", d.Check, d.Message)
+	}
+	if d.Fix == "" {
+		return fmt.Sprintf("✗ %s: %s", d.Check, d.Message)
+	}
+	return fmt.Sprintf("✗ %s: %s\n  fix: %s", d.Check, d.Message, d.Fix)
+}
+
+// Doctor checks that the module the code is generated into can build it:
+// its go directive and its requirements on the MCP SDK, jsonschema-go and
+// the mcpgen runtime, and that the package paths of the configuration match
+// the import paths computed from go.mod. Every check is reported, failed
+// or not.
+func (g *Generator) Doctor() []Diagnostic {
+	absOutput, err := filepath.Abs(g.config.Output)
+	if err != nil {
+		return []Diagnostic{{Check: "go.mod", Message: err.Error()}}
+	}
+
+	modulePath, moduleRoot, err := findClosestGoMod(absOutput)
+	if err != nil {
+		return []Diagnostic{{
+			Check:   "go.mod",
+			Message: err.Error(),
+			Fix:     fmt.Sprintf("run go mod init <module> in %s or one of its parents", g.config.Output),
+		}}
+	}
+
+	goModPath := filepath.Join(moduleRoot, "go.mod")
+	data, err := os.ReadFile(goModPath)
+	if err != nil {
+		return []Diagnostic{{Check: "go.mod", Message: err.Error()}}
+	}
+	mod, err := modfile.Parse(goModPath, data, nil)
+	if err != nil {
+		return []Diagnostic{{Check: "go.mod", Message: err.Error()}}
+	}
+
+	diagnostics := []Diagnostic{
+		{Check: "go.mod", OK: true, Message: fmt.Sprintf("module %s in %s", modulePath, moduleRoot)},
+		checkGoVersion(mod),
+		checkRequirement(mod, sdkModule, requiredSDKVersion),
+		checkRequirement(mod, jsonSchemaModule, requiredJSONSchemaVersion),
+	}
+	if modulePath != mcpgenModule {
+		diagnostics = append(diagnostics, checkRequirement(mod, mcpgenModule, mcpgenVersion()))
+	}
+
+	packages := []struct {
+		key      string
+		pkg      string
+		filename string
+	}{
+		{"model.package", g.config.Model.Package, g.config.Model.Filename},
+		{"resolver.package", g.config.Resolver.Package, g.config.Resolver.Filename},
+	}
+	for _, p := range packages {
+		diagnostics = append(diagnostics, checkImportPath(p.key, p.pkg, modulePath, moduleRoot, absOutput, p.filename))
+	}
+
+	return diagnostics
+}
+
+// mcpgenVersion returns the version of mcpgen the generated code expects
+// of the runtime, or "" for development builds.
+func mcpgenVersion() string {
+	version := Version
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	if !semver.IsValid(version) {
+		return ""
+	}
+	return version
+}
+
+func checkGoVersion(mod *modfile.File) Diagnostic {
+	d := Diagnostic{Check: "go version"}
+	fix := fmt.Sprintf("go mod edit -go=%s", requiredGoVersion)
+
+	if mod.Go == nil {
+		d.Message = "go.mod has no go directive"
+		d.Fix = fix
+		return d
+	}
+	if semver.Compare("v"+mod.Go.Version, "v"+requiredGoVersion) < 0 {
+		d.Message = fmt.Sprintf("go %s is older than go %s", mod.Go.Version, requiredGoVersion)
+		d.Fix = fix
+		return d
+	}
+
+	d.OK = true
+	d.Message = "go " + mod.Go.Version
+	return d
+}
+
+// checkRequirement checks that mod requires path at version or later. A
+// requirement replaced by a local directory is accepted at any version, as
+// is any version when version is empty.
+func checkRequirement(mod *modfile.File, path, version string) Diagnostic {
+	d := Diagnostic{Check: path}
+	target := version
+	if target == "" {
+		target = "latest"
+	}
+	fix := fmt.Sprintf("go get %s@%s", path, target)
+
+	var required *modfile.Require
+	for _, r := range mod.Require {
+		if r.Mod.Path == path {
+			required = r
+		}
+	}
+	if required == nil {
+		d.Message = "not required by go.mod"
+		d.Fix = fix
+		return d
+	}
+
+	for _, r := range mod.Replace {
+		if r.Old.Path == path && r.New.Version == "" {
+			d.OK = true
+			d.Message = fmt.Sprintf("%s, replaced by %s", required.Mod.Version, r.New.Path)
+			return d
+		}
+	}
+
+	if version != "" && semver.Compare(required.Mod.Version, version) < 0 {
+		d.Message = fmt.Sprintf("%s is older than %s", required.Mod.Version, version)
+		d.Fix = fix
+		return d
+	}
+
+	d.OK = true
+	d.Message = required.Mod.Version
+	return d
+}
+
+// checkImportPath checks that a package configured with its import path, as
+// opposed to its name, lies where go.mod says it does.
+func checkImportPath(key, pkg, modulePath, moduleRoot, absOutput, filename string) Diagnostic {
+	d := Diagnostic{Check: key}
+
+	relPath, err := filepath.Rel(moduleRoot, filepath.Join(absOutput, filepath.Dir(filename)))
+	if err != nil {
+		d.Message = err.Error()
+		return d
+	}
+	computed := filepath.ToSlash(filepath.Join(modulePath, relPath))
+
+	if !strings.Contains(pkg, "/") || pkg == computed {
+		d.OK = true
+		d.Message = "imported as " + computed
+		return d
+	}
+
+	d.Message = fmt.Sprintf("%s does not match the import path %s computed from go.mod", pkg, computed)
+	d.Fix = fmt.Sprintf("set %s to %s", key, computed)
+	return d
+}
@@ -0,0 +1,129 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.probo.inc/mcpgen/internal/config"
+	"golang.org/x/mod/modfile"
+)
+
+func TestDoctorRequirementsFollowGoMod(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "go.mod"))
+	require.NoError(t, err)
+	mod, err := modfile.Parse("go.mod", data, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, requiredGoVersion, mod.Go.Version)
+	versions := map[string]string{}
+	for _, r := range mod.Require {
+		versions[r.Mod.Path] = r.Mod.Version
+	}
+	assert.Equal(t, requiredSDKVersion, versions[sdkModule])
+	assert.Equal(t, requiredJSONSchemaVersion, versions[jsonSchemaModule])
+}
+
+func TestDoctor(t *testing.T) {
+	const goodMod = `module example.com/tasks
+
+go 1.25.3
+
+require (
+	github.com/google/jsonschema-go v0.3.0
+	github.com/modelcontextprotocol/go-sdk v1.1.0
+	go.probo.inc/mcpgen v0.4.0
+)
+`
+
+	tests := []struct {
+		name         string
+		goMod        string
+		version      string
+		modelPackage string
+		failed       map[string]string
+	}{
+		{
+			name:         "up to date",
+			goMod:        goodMod,
+			version:      "v0.4.0",
+			modelPackage: "example.com/tasks/generated/types",
+			failed:       map[string]string{},
+		},
+		{
+			name: "outdated",
+			goMod: `module example.com/tasks
+
+go 1.22
+
+require (
+	github.com/modelcontextprotocol/go-sdk v1.0.0
+	go.probo.inc/mcpgen v0.3.1
+)
+`,
+			version:      "0.4.0",
+			modelPackage: "types",
+			failed: map[string]string{
+				"go version":          "go mod edit -go=1.25.3",
+				sdkModule:             "go get github.com/modelcontextprotocol/go-sdk@v1.1.0",
+				jsonSchemaModule:      "go get github.com/google/jsonschema-go@v0.3.0",
+				"go.probo.inc/mcpgen": "go get go.probo.inc/mcpgen@v0.4.0",
+			},
+		},
+		{
+			name: "replaced runtime",
+			goMod: goodMod + `
+replace go.probo.inc/mcpgen => ../mcpgen
+`,
+			version:      "v0.5.0",
+			modelPackage: "types",
+			failed:       map[string]string{},
+		},
+		{
+			name:         "package path mismatch",
+			goMod:        goodMod,
+			version:      "dev",
+			modelPackage: "example.com/tasks/types",
+			failed: map[string]string{
+				"model.package": "set model.package to example.com/tasks/generated/types",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(tt.goMod), 0644))
+
+			previous := Version
+			Version = tt.version
+			t.Cleanup(func() { Version = previous })
+
+			cfg := &config.Config{
+				Output:   filepath.Join(dir, "generated"),
+				Model:    config.ModelConfig{Package: tt.modelPackage, Filename: "types/types.go"},
+				Resolver: config.ResolverConfig{Package: "generated", Filename: "resolver.go"},
+			}
+
+			failed := map[string]string{}
+			for _, d := range New(cfg, &config.MCPSpec{}).Doctor() {
+				if !d.OK {
+					failed[d.Check] = d.Fix
+				}
+			}
+			assert.Equal(t, tt.failed, failed)
+		})
+	}
+}
+
+func TestDoctorWithoutGoMod(t *testing.T) {
+	cfg := &config.Config{Output: filepath.Join(t.TempDir(), "generated")}
+
+	diagnostics := New(cfg, &config.MCPSpec{}).Doctor()
+	require.Len(t, diagnostics, 1)
+	assert.False(t, diagnostics[0].OK)
+	assert.Equal(t, "go.mod", diagnostics[0].Check)
+	assert.Contains(t, diagnostics[0].Fix, "go mod init")
+}
@@ -0,0 +1,234 @@
+package codegen
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+// runGeneratedServerTest generates the server of spec with the exec and
+// model settings of cfg, along with its models and fake, and runs the test
+// file returned by source next to them. source is given the import path of
+// the output directory, holding the server, types and servertest packages.
+// The code is generated within this module, in a directory go test ./...
+// skips, so it builds against the runtime of this tree.
+func runGeneratedServerTest(t *testing.T, spec *config.MCPSpec, cfg config.Config, source func(pkg string) string) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("builds a generated server")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go is not installed")
+	}
+
+	dir, err := os.MkdirTemp(".", "_e2e")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	cfg.Output = dir
+	cfg.Exec.Package = "server"
+	cfg.Exec.Filename = "server/server.go"
+	cfg.Exec.Fake = config.FakeConfig{Filename: "servertest/fake.go"}
+	cfg.Model.Package = "types"
+	cfg.Model.Filename = "types/models.go"
+	cfg.Resolver = config.ResolverConfig{Package: "generated", Filename: "resolver.go", Type: "Resolver"}
+	require.NoError(t, New(&cfg, spec).Generate())
+
+	testDir := filepath.Join(dir, "e2e")
+	require.NoError(t, os.MkdirAll(testDir, 0o755))
+	src := source("go.probo.inc/mcpgen/internal/codegen/" + filepath.Base(dir))
+	require.NoError(t, os.WriteFile(filepath.Join(testDir, "e2e_test.go"), []byte(src), 0o644))
+
+	out, err := exec.Command(goBin, "test", "-count=1", "./"+filepath.ToSlash(testDir)).CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+func TestGeneratedServerCoercesBeforeValidating(t *testing.T) {
+	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "calc", Version: "1.0.0"},
+		Tools: []config.Tool{{
+			Name: "calculate",
+			InputSchema: &config.Schema{
+				Type:       "object",
+				Properties: map[string]*config.Schema{"a": {Type: "number"}},
+				Required:   []string{"a"},
+			},
+		}},
+	}
+	require.NoError(t, spec.Validate())
+
+	runGeneratedServerTest(t, spec, config.Config{Exec: config.ExecConfig{LenientCoercion: true, ValidateInput: true}}, func(pkg string) string {
+		return `package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"` + pkg + `/servertest"
+	"` + pkg + `/types"
+)
+
+func TestCoercion(t *testing.T) {
+	fake := &servertest.Fake{
+		CalculateTool: func(ctx context.Context, req *mcp.CallToolRequest, input *types.CalculateInput) (*mcp.CallToolResult, map[string]any, error) {
+			if input.A != 1 {
+				t.Errorf("a = %v, want 1", input.A)
+			}
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, nil, nil
+		},
+	}
+	session, err := fake.Connect(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "calculate", Arguments: map[string]any{"a": "1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsError {
+		t.Fatalf("call failed: %s", result.Content[0].(*mcp.TextContent).Text)
+	}
+
+	result, err = session.CallTool(context.Background(), &mcp.CallToolParams{Name: "calculate", Arguments: map[string]any{"a": "one"}})
+	if err == nil && !result.IsError {
+		t.Fatal("the input is validated")
+	}
+}
+`
+	})
+}
+
+func TestGeneratedServerSanitizesErrorResults(t *testing.T) {
+	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "search", Version: "1.0.0"},
+		Tools: []config.Tool{{
+			Name: "search",
+			InputSchema: &config.Schema{
+				Type:       "object",
+				Properties: map[string]*config.Schema{"query": {Type: "string"}},
+			},
+		}},
+	}
+	require.NoError(t, spec.Validate())
+
+	runGeneratedServerTest(t, spec, config.Config{Exec: config.ExecConfig{SanitizeResults: true}}, func(pkg string) string {
+		return `package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	mcputil "go.probo.inc/mcpgen/mcp"
+	"` + pkg + `/servertest"
+	"` + pkg + `/types"
+)
+
+func TestSanitize(t *testing.T) {
+	fake := &servertest.Fake{
+		SearchTool: func(ctx context.Context, req *mcp.CallToolRequest, input *types.SearchInput) (*mcp.CallToolResult, map[string]any, error) {
+			return nil, nil, mcputil.InvalidInput("invalid query: %s", *input.Query)
+		},
+	}
+	session, err := fake.Connect(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "search", Arguments: map[string]any{"query": "a\x1b[2Jb"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError {
+		t.Fatal("the call succeeded")
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; text != "invalid query: a[2Jb" {
+		t.Errorf("text = %q, want the message without its control character", text)
+	}
+}
+`
+	})
+}
+
+func TestGeneratedServerOmitsUnsetOmittableFields(t *testing.T) {
+	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "tasks", Version: "1.0.0"},
+		Tools: []config.Tool{{
+			Name: "get_task",
+			InputSchema: &config.Schema{
+				Type: "object",
+				Properties: map[string]*config.Schema{
+					"id":    {Type: "string"},
+					"limit": {Type: "integer", Default: json.RawMessage("10")},
+				},
+				Required: []string{"id"},
+			},
+			OutputSchema: &config.Schema{
+				Type: "object",
+				Properties: map[string]*config.Schema{
+					"id":     {Type: "string"},
+					"status": {Type: "string"},
+				},
+				Required: []string{"id"},
+			},
+		}},
+	}
+	require.NoError(t, spec.Validate())
+
+	cfg := config.Config{
+		Exec:  config.ExecConfig{ValidateOutput: true},
+		Model: config.ModelConfig{OptionalStyle: config.OptionalStyleOmittable},
+	}
+	runGeneratedServerTest(t, spec, cfg, func(pkg string) string {
+		return `package e2e
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"` + pkg + `/servertest"
+	"` + pkg + `/types"
+)
+
+func TestOmittable(t *testing.T) {
+	fake := &servertest.Fake{
+		GetTaskTool: func(ctx context.Context, req *mcp.CallToolRequest, input *types.GetTaskInput) (*mcp.CallToolResult, types.GetTaskOutput, error) {
+			input.ApplyDefaults()
+			if limit, _ := input.Limit.Value(); limit != 10 {
+				t.Errorf("limit = %d, want the default", limit)
+			}
+			return nil, types.GetTaskOutput{ID: input.ID}, nil
+		},
+	}
+	session, err := fake.Connect(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "get_task", Arguments: map[string]any{"id": "1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsError {
+		t.Fatalf("call failed: %s", result.Content[0].(*mcp.TextContent).Text)
+	}
+	output := result.StructuredContent.(map[string]any)
+	if _, ok := output["status"]; ok {
+		t.Errorf("output = %v, want the unset status left out", output)
+	}
+}
+`
+	})
+}
@@ -0,0 +1,72 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+)
+
+// generateFake writes a package holding an in-memory fake of the generated
+// server, with a handler field per tool, resource and prompt, so that the
+// clients of the server can be tested without running it.
+func (g *Generator) generateFake() error {
+	tmpl, err := g.parseTemplate("fake.gotpl")
+	if err != nil {
+		return fmt.Errorf("failed to parse fake template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := g.execute(tmpl, &buf, g.buildFakeTemplateData()); err != nil {
+		return fmt.Errorf("failed to execute fake template: %w", err)
+	}
+
+	fakePath := filepath.Join(g.config.Output, g.config.Exec.Fake.Filename)
+
+	formatted, err := g.formatSource("fake", fakePath, buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format fake code: %w\n%s", err, buf.String())
+	}
+
+	if err := g.writeFile(fakePath, g.withHeader(formatted)); err != nil {
+		return fmt.Errorf("failed to write fake file: %w", err)
+	}
+
+	g.logf("Generated fake: %s\n", fakePath)
+	return nil
+}
+
+// buildFakeTemplateData returns the server template data seen from the fake
+// package, which imports the server as "server" and the models, when they
+// live elsewhere, under their package name.
+func (g *Generator) buildFakeTemplateData() map[string]interface{} {
+	data := g.buildServerTemplateData()
+
+	pkg := g.config.Exec.Fake.Package
+	if pkg == "" {
+		pkg = filepath.Base(filepath.Dir(filepath.Join(g.config.Output, g.config.Exec.Fake.Filename)))
+	}
+	data["Package"] = pkg
+
+	imports := []map[string]string{{
+		"Path":  g.computeImportPath(g.config.Exec.Package, g.config.Exec.Filename),
+		"Alias": "server",
+	}}
+
+	if g.config.Model.Package == g.config.Exec.Package {
+		// The models are declared by the server package
+		for _, key := range []string{"Tools", "Prompts"} {
+			for _, item := range data[key].([]map[string]interface{}) {
+				for _, field := range []string{"InputType", "OutputType", "ArgsType"} {
+					if typeName, ok := item[field].(string); ok {
+						item[field] = "server." + typeName
+					}
+				}
+			}
+		}
+	} else if serverImports, ok := data["Imports"].([]map[string]string); ok {
+		imports = append(imports, serverImports...)
+	}
+	data["Imports"] = imports
+
+	return data
+}
@@ -2,29 +2,76 @@ package codegen
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"go/format"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"text/template"
+	"time"
 
 	"go.probo.inc/mcpgen/internal/config"
 	"go.probo.inc/mcpgen/internal/schema"
+	mcputil "go.probo.inc/mcpgen/mcp"
 	"golang.org/x/mod/modfile"
 )
 
 //go:embed templates/*.gotpl
 var templates embed.FS
 
+// Version is the mcpgen version recorded in generated code. The mcpgen
+// command sets it from its own build version.
+var Version = "dev"
+
 type Generator struct {
 	config       *config.Config
 	spec         *config.MCPSpec
 	schemaLoader *schema.Loader
 	typeGen      *TypeGenerator
+
+	// In dry-run mode files are compared with the ones on disk instead of
+	// being written, and the outcome is recorded in plan.
+	dryRun bool
+	plan   *Plan
+
+	confirmRename RenameFunc
+
+	// handlerKinds limits incremental resolver updates to the handlers of
+	// these primitive kinds, when set.
+	handlerKinds []string
+
+	// componentSchemas holds the resolved JSON schema of every component,
+	// by name, for the schema registry.
+	componentSchemas map[string]string
+	// sharedRefs records the component schemas referred to by the schema
+	// resolveAllRefs resolves, keeping the references, when set.
+	sharedRefs map[string]bool
+	// resolving is the chain of the local references resolveAllRefs is
+	// resolving, to report the cycles.
+	resolving []string
+	// schemas caches the resolved schemas of the spec.
+	schemas *schemaCache
+
+	// group is the group of the resolver file being generated, empty for
+	// the handlers without a group.
+	group string
+
+	// binder finds the types of the autobind packages, created on first use.
+	binder *autobinder
+
+	plugins     []Plugin
+	specMutated bool
+
+	trace *Trace
 }
 
 func New(cfg *config.Config, spec *config.MCPSpec) *Generator {
@@ -42,34 +89,228 @@ func New(cfg *config.Config, spec *config.MCPSpec) *Generator {
 		customMapping := parseTypeMapping(typeMapping.Model)
 		typeGen.AddCustomMapping(schemaName, customMapping)
 	}
+	for format, goType := range cfg.Formats {
+		typeGen.AddFormatMapping(format, parseTypeMapping(goType))
+	}
+	typeGen.SetOptionalStyle(cfg.Model.OptionalStyle)
+	typeGen.SetEnumStringer(cfg.Model.EnumStringer)
 
 	return &Generator{
 		config:       cfg,
 		spec:         spec,
 		schemaLoader: schema.NewLoader("."),
 		typeGen:      typeGen,
+		schemas:      newSchemaCache(),
+	}
+}
+
+// Generation stages, which can be selected with Generate(only...).
+const (
+	StageModels   = "models"
+	StageServer   = "server"
+	StageOpenAPI  = "openapi"
+	StageFake     = "fake"
+	StageResolver = "resolver"
+)
+
+// Stages lists the generation stages in the order they run.
+var Stages = []string{StageModels, StageServer, StageOpenAPI, StageFake, StageResolver}
+
+// Primitive kinds, which can also be selected with Generate(only...): they run
+// the stages generating code for that kind of primitive, and incremental
+// resolver updates then only add and orphan handlers of the selected kinds.
+const (
+	KindTools     = "tools"
+	KindResources = "resources"
+	KindPrompts   = "prompts"
+)
+
+// kindStages lists the stages generating code for each primitive kind.
+var kindStages = map[string][]string{
+	KindTools:     {StageModels, StageServer, StageOpenAPI, StageFake, StageResolver},
+	KindResources: {StageModels, StageServer, StageFake, StageResolver},
+	KindPrompts:   {StageModels, StageServer, StageFake, StageResolver},
+}
+
+// kindHandlerSuffix is the suffix of the resolver handlers of each kind.
+var kindHandlerSuffix = map[string]string{
+	KindTools:     "Tool",
+	KindResources: "Resource",
+	KindPrompts:   "Prompt",
+}
+
+type stage struct {
+	name string
+	run  func() error
+}
+
+func (g *Generator) stages() []stage {
+	return []stage{
+		{
+			name: StageModels,
+			run: func() error {
+				if err := g.generateModels(); err != nil {
+					return fmt.Errorf("failed to generate models: %w", err)
+				}
+				return nil
+			},
+		},
+		{
+			name: StageServer,
+			run: func() error {
+				if err := g.generateServer(); err != nil {
+					return fmt.Errorf("failed to generate server: %w", err)
+				}
+				if err := g.generateNames(); err != nil {
+					return fmt.Errorf("failed to generate names: %w", err)
+				}
+				return nil
+			},
+		},
+		{
+			name: StageOpenAPI,
+			run: func() error {
+				if g.config.Exec.OpenAPI.Filename == "" {
+					return nil
+				}
+				if err := g.generateOpenAPI(); err != nil {
+					return fmt.Errorf("failed to generate OpenAPI document: %w", err)
+				}
+				return nil
+			},
+		},
+		{
+			name: StageFake,
+			run: func() error {
+				if g.config.Exec.Fake.Filename == "" {
+					return nil
+				}
+				if err := g.generateFake(); err != nil {
+					return fmt.Errorf("failed to generate fake: %w", err)
+				}
+				return nil
+			},
+		},
+		{
+			name: StageResolver,
+			run: func() error {
+				if err := g.generateResolverStruct(); err != nil {
+					return fmt.Errorf("failed to generate resolver struct: %w", err)
+				}
+				if err := g.generateResolverImplementations(); err != nil {
+					return fmt.Errorf("failed to generate resolver implementations: %w", err)
+				}
+				return nil
+			},
+		},
 	}
 }
 
-func (g *Generator) Generate() error {
+// Generate writes the generated files. When stage names or primitive kinds
+// are given, only those stages, or the stages of those kinds, run; schemas
+// are always loaded since every stage needs them.
+func (g *Generator) Generate(only ...string) error {
+	selected := make(map[string]bool, len(only))
+	g.handlerKinds = nil
+	for _, name := range only {
+		switch {
+		case contains(Stages, name):
+			selected[name] = true
+		case kindStages[name] != nil:
+			for _, stage := range kindStages[name] {
+				selected[stage] = true
+			}
+			g.handlerKinds = append(g.handlerKinds, name)
+		default:
+			return fmt.Errorf("unknown generation stage %q (valid stages: %s, %s, %s, %s)", name, strings.Join(Stages, ", "), KindTools, KindResources, KindPrompts)
+		}
+	}
+	// A stage selected by name reconciles every handler
+	if contains(only, StageResolver) {
+		g.handlerKinds = nil
+	}
+
+	if err := g.mutateSpec(); err != nil {
+		return err
+	}
+
+	endLoad := g.trace.Start("load schemas")
+	err := g.loadSchemas()
+	endLoad()
+	if err != nil {
+		return fmt.Errorf("failed to load schemas: %w", err)
+	}
+
+	for _, st := range g.stages() {
+		if len(selected) > 0 && !selected[st.name] {
+			continue
+		}
+		endStage := g.trace.Start("stage " + st.name)
+		err := st.run()
+		endStage()
+		if err != nil {
+			return err
+		}
+	}
+
+	if g.dryRun {
+		return nil
+	}
+	return g.postGenerate()
+}
+
+// Warnings returns the non-fatal problems found in the spec and the schema
+// files loaded so far, such as draft-07 constructs that could not be converted,
+// and the deprecated tools and schemas still in use.
+func (g *Generator) Warnings() []string {
+	warnings := append([]string{}, g.spec.Warnings...)
+	warnings = append(warnings, g.schemaLoader.Warnings()...)
+	warnings = append(warnings, g.templateWarnings()...)
+	return append(warnings, g.deprecationWarnings()...)
+}
+
+// Validate loads and resolves every schema referenced by the spec and builds
+// the models in memory, reporting the first problem found. No files are written.
+func (g *Generator) Validate() error {
+	if err := g.mutateSpec(); err != nil {
+		return err
+	}
+
 	if err := g.loadSchemas(); err != nil {
 		return fmt.Errorf("failed to load schemas: %w", err)
 	}
 
-	if err := g.generateModels(); err != nil {
+	if err := g.checkRefs(); err != nil {
+		return fmt.Errorf("failed to resolve schema references: %w", err)
+	}
+
+	if _, err := g.typeGen.Generate(g.config.Model.Package); err != nil {
 		return fmt.Errorf("failed to generate models: %w", err)
 	}
 
-	if err := g.generateServer(); err != nil {
-		return fmt.Errorf("failed to generate server: %w", err)
+	return nil
+}
+
+// checkRefs resolves the local references of component and resource schemas,
+// which loadSchemas only registers without following.
+func (g *Generator) checkRefs() error {
+	// Sort schema names for deterministic output
+	schemaNames := make([]string, 0, len(g.spec.Components.Schemas))
+	for name := range g.spec.Components.Schemas {
+		schemaNames = append(schemaNames, name)
 	}
+	sort.Strings(schemaNames)
 
-	if err := g.generateResolverStruct(); err != nil {
-		return fmt.Errorf("failed to generate resolver struct: %w", err)
+	for _, name := range schemaNames {
+		if _, err := g.resolveRefs(g.spec.Components.Schemas[name]); err != nil {
+			return fmt.Errorf("components.schemas.%s: %w", name, err)
+		}
 	}
 
-	if err := g.generateResolverImplementations(); err != nil {
-		return fmt.Errorf("failed to generate resolver implementations: %w", err)
+	for _, resource := range g.spec.Resources {
+		if _, err := g.resolveRefs(resource.Schema); err != nil {
+			return fmt.Errorf("resource %s: %w", resource.Name, err)
+		}
 	}
 
 	return nil
@@ -93,23 +334,43 @@ func (g *Generator) loadSchemas() error {
 			if goType := extractGoTypeAnnotation(s); goType != "" {
 				customMapping := parseTypeMapping(goType)
 				g.typeGen.AddCustomMapping(name, customMapping)
+			} else if mapping := g.typeGen.formatMapping(s); mapping != nil {
+				g.typeGen.AddCustomMapping(name, mapping)
+			} else if err := g.autobind(name); err != nil {
+				return err
 			}
 			g.typeGen.AddSchema(name, s)
 		} else {
 			if goType := extractGoTypeAnnotation(schema); goType != "" {
 				customMapping := parseTypeMapping(goType)
 				g.typeGen.AddCustomMapping(name, customMapping)
+			} else if mapping := g.typeGen.formatMapping(schema); mapping != nil {
+				// A component mapped by its format is a name for the mapped type
+				g.typeGen.AddCustomMapping(name, mapping)
+			} else if err := g.autobind(name); err != nil {
+				return err
 			}
 			g.typeGen.AddSchema(name, schema)
 		}
 	}
 
+	if g.config.Exec.SchemaRegistry {
+		if err := g.loadComponentSchemas(schemaNames); err != nil {
+			return err
+		}
+	}
+
 	for _, tool := range g.spec.Tools {
-		if tool.InputSchema != nil {
+		if tool.InputSchema != nil && !tool.TakesNoInput() {
 			typeName := toPascalCase(tool.Name) + "Input"
 			handlerName := toHandlerName(tool.Name)
 			schemaVarName := handlerName + "ToolInputSchema"
 
+			addSchema := g.typeGen.AddInputSchema
+			if g.config.Model.StrictInputs {
+				addSchema = g.typeGen.AddStrictSchema
+			}
+
 			var resolvedSchema *config.Schema
 			if config.IsSchemaRef(tool.InputSchema) {
 				if len(tool.InputSchema.Ref) > 0 && tool.InputSchema.Ref[0] == '#' {
@@ -118,29 +379,30 @@ func (g *Generator) loadSchemas() error {
 						return fmt.Errorf("failed to resolve input schema ref for tool %s: %w", tool.Name, err)
 					}
 					resolvedSchema = resolved
-					g.typeGen.AddSchema(typeName, resolvedSchema)
+					addSchema(typeName, resolvedSchema)
 				} else {
 					s, err := g.schemaLoader.Load(tool.InputSchema.Ref)
 					if err != nil {
 						return fmt.Errorf("failed to load input schema for tool %s: %w", tool.Name, err)
 					}
 					resolvedSchema = s
-					g.typeGen.AddSchema(typeName, s)
+					addSchema(typeName, s)
 				}
 			} else {
 				resolvedSchema = tool.InputSchema
-				g.typeGen.AddSchema(typeName, tool.InputSchema)
+				addSchema(typeName, tool.InputSchema)
 			}
 
-			if resolvedSchema != nil {
-				fullyResolvedSchema, err := g.resolveAllRefs(resolvedSchema)
+			if resolvedSchema != nil && g.config.Exec.MinimalRuntime {
+				if err := g.addSharedSchemaVar(schemaVarName, sharedRoot(tool.InputSchema, resolvedSchema), g.config.Model.StrictInputs); err != nil {
+					return fmt.Errorf("failed to share schema for tool %s: %w", tool.Name, err)
+				}
+			} else if resolvedSchema != nil {
+				schemaJSON, err := g.resolvedJSON(resolvedSchema, g.config.Model.StrictInputs)
 				if err != nil {
 					return fmt.Errorf("failed to fully resolve schema for tool %s: %w", tool.Name, err)
 				}
-				schemaJSON, err := json.Marshal(fullyResolvedSchema)
-				if err == nil {
-					g.typeGen.AddSchemaVar(schemaVarName, string(schemaJSON))
-				}
+				g.typeGen.AddSchemaVar(schemaVarName, string(schemaJSON))
 			}
 		}
 
@@ -158,29 +420,30 @@ func (g *Generator) loadSchemas() error {
 						return fmt.Errorf("failed to resolve output schema ref for tool %s: %w", tool.Name, err)
 					}
 					resolvedSchema = resolved
-					g.typeGen.AddSchema(typeName, resolvedSchema)
+					g.typeGen.AddOutputSchema(typeName, resolvedSchema)
 				} else {
 					s, err := g.schemaLoader.Load(tool.OutputSchema.Ref)
 					if err != nil {
 						return fmt.Errorf("failed to load output schema for tool %s: %w", tool.Name, err)
 					}
 					resolvedSchema = s
-					g.typeGen.AddSchema(typeName, s)
+					g.typeGen.AddOutputSchema(typeName, s)
 				}
 			} else {
 				resolvedSchema = tool.OutputSchema
-				g.typeGen.AddSchema(typeName, tool.OutputSchema)
+				g.typeGen.AddOutputSchema(typeName, tool.OutputSchema)
 			}
 
-			if resolvedSchema != nil {
-				fullyResolvedSchema, err := g.resolveAllRefs(resolvedSchema)
+			if resolvedSchema != nil && g.config.Exec.MinimalRuntime {
+				if err := g.addSharedSchemaVar(schemaVarName, sharedRoot(tool.OutputSchema, resolvedSchema), false); err != nil {
+					return fmt.Errorf("failed to share schema for tool %s: %w", tool.Name, err)
+				}
+			} else if resolvedSchema != nil {
+				schemaJSON, err := g.resolvedJSON(resolvedSchema, false)
 				if err != nil {
 					return fmt.Errorf("failed to fully resolve schema for tool %s: %w", tool.Name, err)
 				}
-				schemaJSON, err := json.Marshal(fullyResolvedSchema)
-				if err == nil {
-					g.typeGen.AddSchemaVar(schemaVarName, string(schemaJSON))
-				}
+				g.typeGen.AddSchemaVar(schemaVarName, string(schemaJSON))
 			}
 		}
 	}
@@ -194,16 +457,16 @@ func (g *Generator) loadSchemas() error {
 					if err != nil {
 						return fmt.Errorf("failed to resolve schema ref for resource %s: %w", resource.Name, err)
 					}
-					g.typeGen.AddSchema(typeName, resolvedSchema)
+					g.typeGen.AddOutputSchema(typeName, resolvedSchema)
 					continue
 				}
 				s, err := g.schemaLoader.Load(resource.Schema.Ref)
 				if err != nil {
 					return fmt.Errorf("failed to load schema for resource %s: %w", resource.Name, err)
 				}
-				g.typeGen.AddSchema(typeName, s)
+				g.typeGen.AddOutputSchema(typeName, s)
 			} else {
-				g.typeGen.AddSchema(typeName, resource.Schema)
+				g.typeGen.AddOutputSchema(typeName, resource.Schema)
 			}
 		}
 	}
@@ -237,18 +500,90 @@ func (g *Generator) loadSchemas() error {
 	return nil
 }
 
+// autobind maps a component schema to the type of the same name of the first
+// autobind package declaring one, unless the models configuration already
+// maps it.
+func (g *Generator) autobind(name string) error {
+	if len(g.config.Autobind) == 0 || g.typeGen.customMappings[name] != nil {
+		return nil
+	}
+
+	if g.binder == nil {
+		dir := "."
+		if absOutput, err := filepath.Abs(g.config.Output); err == nil {
+			if _, moduleRoot, err := findClosestGoMod(absOutput); err == nil {
+				dir = moduleRoot
+			}
+		}
+		g.binder = newAutobinder(g.config.Autobind, dir)
+	}
+
+	typeName := toGoTypeName(name)
+	goType, err := g.binder.bind(typeName)
+	if err != nil || goType == "" {
+		return err
+	}
+
+	// Types of the models package itself are used unqualified
+	if strings.TrimSuffix(goType, "."+typeName) == g.computeModelImportPath() {
+		g.typeGen.AddCustomMapping(name, &CustomTypeMapping{GoType: typeName})
+		return nil
+	}
+	g.typeGen.AddCustomMapping(name, parseTypeMapping(goType))
+	return nil
+}
+
+// loadComponentSchemas resolves the references of the component schemas,
+// loading the external ones, for the schema registry.
+func (g *Generator) loadComponentSchemas(names []string) error {
+	g.componentSchemas = make(map[string]string, len(names))
+	for _, name := range names {
+		if g.config.Exec.MinimalRuntime {
+			if err := g.addSharedComponent(name, false); err != nil {
+				return err
+			}
+			g.componentSchemas[name] = ""
+			continue
+		}
+
+		s := g.spec.Components.Schemas[name]
+		if config.IsSchemaRef(s) && !strings.HasPrefix(s.Ref, "#") {
+			loaded, err := g.schemaLoader.Load(s.Ref)
+			if err != nil {
+				return fmt.Errorf("failed to load schema %s: %w", name, err)
+			}
+			s = loaded
+		}
+
+		schemaJSON, err := g.resolvedJSON(s, false)
+		if err != nil {
+			return fmt.Errorf("failed to fully resolve schema %s: %w", name, err)
+		}
+		g.componentSchemas[name] = string(schemaJSON)
+	}
+	return nil
+}
+
+// resolveRefs returns s with its references resolved, timed in the trace.
+// The schemas of the references are shared with the other schemas referring
+// to them: clone the result with CloneSchemas before modifying it.
+func (g *Generator) resolveRefs(s *config.Schema) (*config.Schema, error) {
+	defer g.trace.Start("resolve refs")()
+	return g.resolveAllRefs(s)
+}
+
 func (g *Generator) resolveAllRefs(s *config.Schema) (*config.Schema, error) {
 	if s == nil {
 		return nil, nil
 	}
 
 	if config.IsSchemaRef(s) {
+		if name, ok := componentRef(s); ok && g.sharedRefs != nil {
+			g.sharedRefs[name] = true
+			return &config.Schema{Ref: s.Ref}, nil
+		}
 		if len(s.Ref) > 0 && s.Ref[0] == '#' {
-			resolved, err := g.spec.ResolveSchemaRef(s.Ref)
-			if err != nil {
-				return nil, err
-			}
-			return g.resolveAllRefs(resolved)
+			return g.resolveRef(s.Ref)
 		}
 		return s, nil
 	}
@@ -260,6 +595,7 @@ func (g *Generator) resolveAllRefs(s *config.Schema) (*config.Schema, error) {
 		Description:      s.Description,
 		Default:          s.Default,
 		Enum:             s.Enum,
+		Const:            s.Const,
 		Title:            s.Title,
 		Required:         s.Required,
 		ReadOnly:         s.ReadOnly,
@@ -281,13 +617,12 @@ func (g *Generator) resolveAllRefs(s *config.Schema) (*config.Schema, error) {
 
 	if len(s.Properties) > 0 {
 		result.Properties = make(map[string]*config.Schema)
-		// Sort property names for deterministic output
-		propNames := make([]string, 0, len(s.Properties))
-		for key := range s.Properties {
-			propNames = append(propNames, key)
+		// Keep the spec order of the properties for the schema JSON, see
+		// canonicalJSON
+		if order, ok := s.Extra[schema.PropertyOrderKey]; ok {
+			result.Extra = map[string]any{schema.PropertyOrderKey: order}
 		}
-		sort.Strings(propNames)
-		for _, key := range propNames {
+		for _, key := range schema.PropertyNames(s) {
 			propSchema := s.Properties[key]
 			resolvedProp, err := g.resolveAllRefs(propSchema)
 			if err != nil {
@@ -305,6 +640,35 @@ func (g *Generator) resolveAllRefs(s *config.Schema) (*config.Schema, error) {
 		result.Items = resolvedItems
 	}
 
+	if len(s.PrefixItems) > 0 {
+		result.PrefixItems = make([]*config.Schema, len(s.PrefixItems))
+		for i, schema := range s.PrefixItems {
+			resolvedSchema, err := g.resolveAllRefs(schema)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve prefixItems[%d]: %w", i, err)
+			}
+			result.PrefixItems[i] = resolvedSchema
+		}
+	}
+
+	if s.Contains != nil {
+		resolvedContains, err := g.resolveAllRefs(s.Contains)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve contains: %w", err)
+		}
+		result.Contains = resolvedContains
+		result.MinContains = s.MinContains
+		result.MaxContains = s.MaxContains
+	}
+
+	if s.UnevaluatedItems != nil {
+		resolvedUnevaluated, err := g.resolveAllRefs(s.UnevaluatedItems)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve unevaluatedItems: %w", err)
+		}
+		result.UnevaluatedItems = resolvedUnevaluated
+	}
+
 	if len(s.AnyOf) > 0 {
 		result.AnyOf = make([]*config.Schema, len(s.AnyOf))
 		for i, schema := range s.AnyOf {
@@ -346,6 +710,30 @@ func (g *Generator) resolveAllRefs(s *config.Schema) (*config.Schema, error) {
 		result.Not = resolvedNot
 	}
 
+	if s.If != nil {
+		resolvedIf, err := g.resolveAllRefs(s.If)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve if: %w", err)
+		}
+		result.If = resolvedIf
+	}
+
+	if s.Then != nil {
+		resolvedThen, err := g.resolveAllRefs(s.Then)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve then: %w", err)
+		}
+		result.Then = resolvedThen
+	}
+
+	if s.Else != nil {
+		resolvedElse, err := g.resolveAllRefs(s.Else)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve else: %w", err)
+		}
+		result.Else = resolvedElse
+	}
+
 	if s.AdditionalProperties != nil {
 		resolvedAdditional, err := g.resolveAllRefs(s.AdditionalProperties)
 		if err != nil {
@@ -354,6 +742,14 @@ func (g *Generator) resolveAllRefs(s *config.Schema) (*config.Schema, error) {
 		result.AdditionalProperties = resolvedAdditional
 	}
 
+	if s.UnevaluatedProperties != nil {
+		resolvedUnevaluated, err := g.resolveAllRefs(s.UnevaluatedProperties)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve unevaluatedProperties: %w", err)
+		}
+		result.UnevaluatedProperties = resolvedUnevaluated
+	}
+
 	if len(s.PatternProperties) > 0 {
 		result.PatternProperties = make(map[string]*config.Schema)
 		// Sort pattern names for deterministic output
@@ -375,6 +771,60 @@ func (g *Generator) resolveAllRefs(s *config.Schema) (*config.Schema, error) {
 	return result, nil
 }
 
+// closeObjects sets additionalProperties to false on the objects of a resolved
+// schema that declare properties and leave additional properties unspecified.
+// Objects combined with allOf or if/then/else are left open, since each
+// branch only declares part of the properties.
+func closeObjects(s *config.Schema) {
+	if s == nil || len(s.AllOf) > 0 || s.If != nil {
+		return
+	}
+
+	if len(s.Properties) > 0 && s.AdditionalProperties == nil && s.UnevaluatedProperties == nil && len(s.PatternProperties) == 0 {
+		s.AdditionalProperties = &config.Schema{Not: &config.Schema{}}
+	}
+
+	for _, prop := range s.Properties {
+		closeObjects(prop)
+	}
+	closeObjects(s.Items)
+	for _, item := range s.PrefixItems {
+		closeObjects(item)
+	}
+	for _, sub := range s.AnyOf {
+		closeObjects(sub)
+	}
+	for _, sub := range s.OneOf {
+		closeObjects(sub)
+	}
+}
+
+// goDuration returns the Go expression of a duration from the config, such
+// as 2 * time.Second, or "" when it is not set or invalid.
+func goDuration(s string) string {
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return ""
+	}
+
+	units := []struct {
+		unit time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+		{time.Microsecond, "time.Microsecond"},
+	}
+	for _, u := range units {
+		if d%u.unit == 0 {
+			return fmt.Sprintf("%d * %s", d/u.unit, u.name)
+		}
+	}
+	return fmt.Sprintf("%d * time.Nanosecond", d)
+}
+
 func toPascalCase(s string) string {
 	parts := strings.FieldsFunc(s, func(r rune) bool {
 		return r == '_' || r == '-' || r == ' '
@@ -388,32 +838,106 @@ func toPascalCase(s string) string {
 }
 
 func (g *Generator) generateModels() error {
-	code, err := g.typeGen.Generate(g.config.Model.Package)
-	if err != nil {
-		return err
-	}
-
 	modelsFile := "models.go"
 	if g.config.Model.Filename != "" {
 		modelsFile = g.config.Model.Filename
 	}
 	modelsPath := filepath.Join(g.config.Output, modelsFile)
+	modelsDir := filepath.Dir(modelsPath)
 
-	dir := filepath.Dir(modelsPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+	files, err := g.modelFiles(filepath.Base(modelsPath))
+	if err != nil {
+		return err
+	}
+	g.typeGen.formatted = loadModelsCache(modelsDir)
+	sources, err := g.typeGen.GenerateFiles(g.config.Model.Package, files)
+	if err != nil {
+		return err
 	}
 
-	if err := os.WriteFile(modelsPath, code, 0644); err != nil {
-		return fmt.Errorf("failed to write models file: %w", err)
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	paths := make([]string, len(names))
+	codes := make([][]byte, len(names))
+	var mutated []int
+	for i, name := range names {
+		code, err := g.mutateModels(sources[name])
+		if err != nil {
+			return err
+		}
+
+		paths[i] = modelsPath
+		if name != "" {
+			paths[i] = filepath.Join(modelsDir, name)
+		}
+		codes[i] = code
+		if !bytes.Equal(code, sources[name]) {
+			mutated = append(mutated, i)
+		}
 	}
 
-	fmt.Printf("Generated models: %s\n", modelsPath)
+	// Plugins changing the models need not maintain their imports; the
+	// models come formatted with their imports otherwise
+	err = parallel(g.trace, len(mutated), func(j int) error {
+		i := mutated[j]
+		code, err := g.formatSource("models", paths[i], codes[i])
+		if err != nil {
+			return fmt.Errorf("failed to format models: %w", err)
+		}
+		codes[i] = code
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	written := make(map[string]bool, len(names))
+	manifestFiles := make(map[string][]byte, len(names))
+	manifestDecls := make(map[string][]modelDecl, len(names))
+	isMutated := make(map[int]bool, len(mutated))
+	for _, i := range mutated {
+		isMutated[i] = true
+	}
+	for i, path := range paths {
+		code := g.withHeader(codes[i])
+		written[path] = true
+		if !isMutated[i] {
+			manifestFiles[filepath.Base(path)] = code
+			manifestDecls[filepath.Base(path)] = g.typeGen.decls[names[i]]
+		}
+
+		// The files of unchanged schemas are left untouched
+		if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, code) && !g.dryRun {
+			continue
+		}
+		if err := g.writeFile(path, code); err != nil {
+			return fmt.Errorf("failed to write models file: %w", err)
+		}
+	}
+	if !g.dryRun {
+		if err := writeModelsManifest(modelsDir, manifestFiles, manifestDecls); err != nil {
+			return fmt.Errorf("failed to write models manifest: %w", err)
+		}
+	}
+
+	if err := g.removeStaleModelFiles(modelsDir, written); err != nil {
+		return err
+	}
+
+	if len(names) > 1 {
+		g.logf("Generated models: %s and %d schema files\n", modelsPath, len(names)-1)
+	} else {
+		g.logf("Generated models: %s\n", modelsPath)
+	}
 	return nil
 }
 
 func (g *Generator) generateServer() error {
-	tmpl, err := template.ParseFS(templates, "templates/server.gotpl")
+	tmpl, err := g.parseTemplate("server.gotpl")
 	if err != nil {
 		return fmt.Errorf("failed to parse server template: %w", err)
 	}
@@ -421,31 +945,26 @@ func (g *Generator) generateServer() error {
 	data := g.buildServerTemplateData()
 
 	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
+	if err := g.execute(tmpl, &buf, data); err != nil {
 		return fmt.Errorf("failed to execute server template: %w", err)
 	}
 
-	formatted, err := format.Source(buf.Bytes())
-	if err != nil {
-		return fmt.Errorf("failed to format server code: %w\n%s", err, buf.String())
-	}
-
 	serverFile := "server.go"
 	if g.config.Exec.Filename != "" {
 		serverFile = g.config.Exec.Filename
 	}
 	serverPath := filepath.Join(g.config.Output, serverFile)
 
-	dir := filepath.Dir(serverPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+	formatted, err := g.formatSource("server", serverPath, buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format server code: %w\n%s", err, buf.String())
 	}
 
-	if err := os.WriteFile(serverPath, formatted, 0644); err != nil {
+	if err := g.writeFile(serverPath, g.withHeader(formatted)); err != nil {
 		return fmt.Errorf("failed to write server file: %w", err)
 	}
 
-	fmt.Printf("Generated server: %s\n", serverPath)
+	g.logf("Generated server: %s\n", serverPath)
 	return nil
 }
 
@@ -456,11 +975,12 @@ func (g *Generator) generateResolverStruct() error {
 
 	// Only generate if file doesn't exist
 	if _, err := os.Stat(resolverFile); err == nil {
-		fmt.Printf("Resolver struct already exists, skipping: %s\n", resolverFile)
+		g.keepFile(resolverFile)
+		g.logf("Resolver struct already exists, skipping: %s\n", resolverFile)
 		return nil
 	}
 
-	tmpl, err := template.ParseFS(templates, "templates/resolver_struct.gotpl")
+	tmpl, err := g.parseTemplate("resolver_struct.gotpl")
 	if err != nil {
 		return fmt.Errorf("failed to parse resolver_struct template: %w", err)
 	}
@@ -471,26 +991,41 @@ func (g *Generator) generateResolverStruct() error {
 	}
 
 	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
+	if err := g.execute(tmpl, &buf, data); err != nil {
 		return fmt.Errorf("failed to execute resolver_struct template: %w", err)
 	}
 
-	formatted, err := format.Source(buf.Bytes())
+	formatted, err := g.formatSource("resolver struct", resolverFile, buf.Bytes())
 	if err != nil {
 		return fmt.Errorf("failed to format resolver struct code: %w\n%s", err, buf.String())
 	}
 
-	if err := os.WriteFile(resolverFile, formatted, 0644); err != nil {
+	if err := g.writeFile(resolverFile, g.withHeader(formatted)); err != nil {
 		return fmt.Errorf("failed to write resolver struct file: %w", err)
 	}
 
-	fmt.Printf("Generated resolver struct: %s\n", resolverFile)
+	g.logf("Generated resolver struct: %s\n", resolverFile)
 	return nil
 }
 
-// generateResolverImplementations creates/updates schema.resolvers.go with tool/prompt/resource implementations
+// generateResolverImplementations creates/updates schema.resolvers.go with
+// tool/prompt/resource implementations, and <group>.resolvers.go with the
+// sub-resolver of each group of the spec
 func (g *Generator) generateResolverImplementations() error {
-	resolverFile := filepath.Join(g.config.Output, "schema.resolvers.go")
+	defer func() { g.group = "" }()
+
+	for _, group := range append([]string{""}, g.groups()...) {
+		g.group = group
+		if err := g.generateGroupResolver(g.resolverFile(group)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// generateGroupResolver creates/updates the resolver file of the handlers
+// of the current group.
+func (g *Generator) generateGroupResolver(resolverFile string) error {
 
 	fileExists := false
 	if _, err := os.Stat(resolverFile); err == nil {
@@ -499,7 +1034,11 @@ func (g *Generator) generateResolverImplementations() error {
 
 	// If file doesn't exist, generate from template (initial generation)
 	if !fileExists {
-		return g.generateResolverFromTemplate(resolverFile)
+		if err := g.generateResolverFromTemplate(resolverFile); err != nil {
+			return err
+		}
+		g.planHandlers(resolverFile, g.getRequiredHandlerNames(), nil, nil, nil)
+		return nil
 	}
 
 	// File exists and preserve is enabled - do incremental update (gqlgen-style)
@@ -512,7 +1051,7 @@ func (g *Generator) generateResolverImplementations() error {
 }
 
 func (g *Generator) generateResolverFromTemplate(resolverFile string) error {
-	tmpl, err := template.ParseFS(templates, "templates/resolver.gotpl")
+	tmpl, err := g.parseTemplate("resolver.gotpl")
 	if err != nil {
 		return fmt.Errorf("failed to parse resolver template: %w", err)
 	}
@@ -520,20 +1059,20 @@ func (g *Generator) generateResolverFromTemplate(resolverFile string) error {
 	data := g.buildResolverTemplateData()
 
 	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
+	if err := g.execute(tmpl, &buf, data); err != nil {
 		return fmt.Errorf("failed to execute resolver template: %w", err)
 	}
 
-	formatted, err := format.Source(buf.Bytes())
+	formatted, err := g.formatSource("resolver", resolverFile, buf.Bytes())
 	if err != nil {
 		return fmt.Errorf("failed to format resolver code: %w\n%s", err, buf.String())
 	}
 
-	if err := os.WriteFile(resolverFile, formatted, 0644); err != nil {
+	if err := g.writeFile(resolverFile, g.withHeader(formatted)); err != nil {
 		return fmt.Errorf("failed to write resolver file: %w", err)
 	}
 
-	fmt.Printf("Generated resolver implementations: %s\n", resolverFile)
+	g.logf("Generated resolver implementations: %s\n", resolverFile)
 	return nil
 }
 
@@ -543,13 +1082,34 @@ func (g *Generator) updateResolverIncremental(resolverFile string) error {
 		return fmt.Errorf("failed to parse existing resolver: %w", err)
 	}
 
-	existingHandlers, err := parser.ExtractHandlers(g.config.Resolver.Type)
+	existingHandlers, err := parser.ExtractHandlers(g.resolverType(g.group))
 	if err != nil {
 		return fmt.Errorf("failed to extract handlers: %w", err)
 	}
 
 	requiredHandlers := g.getRequiredHandlerNames()
 
+	// Identify orphaned handlers (exist in file but not in spec, excluding already orphaned ones)
+	// First, get the list of handlers that were already in the orphaned section
+	previouslyOrphanedHandlers := extractOrphanedHandlerNames(resolverFile)
+
+	// When only some primitive kinds are selected, handlers of the other
+	// kinds are left as they are: neither added nor orphaned
+	if len(g.handlerKinds) > 0 {
+		var kept []string
+		for _, name := range requiredHandlers {
+			if g.isSelectedHandler(name) {
+				kept = append(kept, name)
+			}
+		}
+		for name := range existingHandlers {
+			if !g.isSelectedHandler(name) && !contains(previouslyOrphanedHandlers, name) {
+				kept = append(kept, name)
+			}
+		}
+		requiredHandlers = kept
+	}
+
 	// Identify which handlers are new
 	newHandlers := []string{}
 	for _, required := range requiredHandlers {
@@ -558,10 +1118,6 @@ func (g *Generator) updateResolverIncremental(resolverFile string) error {
 		}
 	}
 
-	// Identify orphaned handlers (exist in file but not in spec, excluding already orphaned ones)
-	// First, get the list of handlers that were already in the orphaned section
-	previouslyOrphanedHandlers := extractOrphanedHandlerNames(resolverFile)
-
 	// Identify which handlers are orphaned (not in required list and not already in orphaned section)
 	currentlyOrphanedHandlers := []string{}
 	for handlerName := range existingHandlers {
@@ -586,13 +1142,26 @@ func (g *Generator) updateResolverIncremental(resolverFile string) error {
 		}
 	}
 
+	// Newly orphaned handlers confirmed as renamed keep their body under the
+	// new handler name instead of getting a stub
+	sort.Strings(currentlyOrphanedHandlers)
+	renames := findRenames(currentlyOrphanedHandlers, newHandlers, g.confirmRename)
+	for _, rename := range renames {
+		newHandlers = remove(newHandlers, rename.New)
+		currentlyOrphanedHandlers = remove(currentlyOrphanedHandlers, rename.Old)
+	}
+
 	// Mark handlers as orphaned for formatting
 	IdentifyOrphanedHandlers(existingHandlers, requiredHandlers)
+	for _, rename := range renames {
+		existingHandlers[rename.Old].IsOrphaned = false
+	}
 	orphanedHandlers := FormatOrphanedHandlers(existingHandlers)
 
 	// If nothing changed, skip update
-	if len(newHandlers) == 0 && len(currentlyOrphanedHandlers) == 0 && len(orphanedHandlersRemoved) == 0 {
-		fmt.Printf("Resolver is up to date, skipping: %s\n", resolverFile)
+	if len(newHandlers) == 0 && len(currentlyOrphanedHandlers) == 0 && len(orphanedHandlersRemoved) == 0 && len(renames) == 0 {
+		g.keepFile(resolverFile)
+		g.logf("Resolver is up to date, skipping: %s\n", resolverFile)
 		return nil
 	}
 
@@ -614,6 +1183,11 @@ func (g *Generator) updateResolverIncremental(resolverFile string) error {
 		contentStr = contentStr[:idx]
 	}
 
+	contentStr, err = g.applyRenames(contentStr, existingHandlers, renames)
+	if err != nil {
+		return fmt.Errorf("failed to rename handlers: %w", err)
+	}
+
 	// Build final content: existing code + new handlers + orphaned section
 	var buf bytes.Buffer
 	buf.WriteString(contentStr)
@@ -627,15 +1201,26 @@ func (g *Generator) updateResolverIncremental(resolverFile string) error {
 		buf.WriteString(orphanedHandlers)
 	}
 
+	// The new stubs may use packages the existing file does not import
+	source, err := addResolverImports(buf.Bytes(), newHandlers)
+	if err != nil {
+		return fmt.Errorf("failed to add resolver imports: %w", err)
+	}
+
 	// Format the final code
-	formatted, err := format.Source(buf.Bytes())
+	formatted, err := g.formatSource("resolver", resolverFile, source)
 	if err != nil {
-		return fmt.Errorf("failed to format resolver code: %w\n%s", err, buf.String())
+		return fmt.Errorf("failed to format resolver code: %w\n%s", err, source)
 	}
 
-	if err := os.WriteFile(resolverFile, formatted, 0644); err != nil {
+	if err := g.writeFile(resolverFile, formatted); err != nil {
 		return fmt.Errorf("failed to write resolver file: %w", err)
 	}
+	renamed := make([]string, 0, len(renames))
+	for _, rename := range renames {
+		renamed = append(renamed, rename.String())
+	}
+	g.planHandlers(resolverFile, newHandlers, currentlyOrphanedHandlers, orphanedHandlersRemoved, renamed)
 
 	// Build status message
 	var updates []string
@@ -648,12 +1233,95 @@ func (g *Generator) updateResolverIncremental(resolverFile string) error {
 	if len(orphanedHandlersRemoved) > 0 {
 		updates = append(updates, fmt.Sprintf("restored %d from orphaned", len(orphanedHandlersRemoved)))
 	}
+	if len(renames) > 0 {
+		updates = append(updates, fmt.Sprintf("renamed %d", len(renames)))
+	}
 
-	fmt.Printf("Updated resolver: %s: %s\n", strings.Join(updates, ", "), resolverFile)
+	g.logf("Updated resolver: %s: %s\n", strings.Join(updates, ", "), resolverFile)
 
 	return nil
 }
 
+// isSelectedHandler reports whether a resolver handler is of one of the
+// primitive kinds selected for generation.
+func (g *Generator) isSelectedHandler(name string) bool {
+	for _, kind := range g.handlerKinds {
+		if strings.HasSuffix(name, kindHandlerSuffix[kind]) {
+			return true
+		}
+	}
+	return false
+}
+
+// addResolverImports adds to the resolver source the imports used by the
+// stubs of handlerNames that it is missing: the runtime package for tool and
+// resource stubs, which return mcputil.ErrNotImplemented, and fmt for the
+// resource and prompt stubs.
+func addResolverImports(src []byte, handlerNames []string) ([]byte, error) {
+	type importSpec struct{ name, path string }
+	fmtImport := importSpec{path: "fmt"}
+	mcputilImport := importSpec{name: "mcputil", path: "go.probo.inc/mcpgen/mcp"}
+
+	var needed []importSpec
+	for _, name := range handlerNames {
+		specs := []importSpec{fmtImport}
+		switch {
+		case strings.HasSuffix(name, kindHandlerSuffix[KindTools]):
+			specs = []importSpec{mcputilImport}
+		case strings.HasSuffix(name, kindHandlerSuffix[KindResources]):
+			specs = []importSpec{fmtImport, mcputilImport}
+		}
+		for _, spec := range specs {
+			if !slices.Contains(needed, spec) {
+				needed = append(needed, spec)
+			}
+		}
+	}
+	if len(needed) == 0 {
+		return src, nil
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ImportsOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, spec := range needed {
+		imported := false
+		for _, imp := range file.Imports {
+			if path, _ := strconv.Unquote(imp.Path.Value); path == spec.path {
+				imported = true
+				break
+			}
+		}
+		if !imported {
+			missing = append(missing, strings.TrimSpace(spec.name+" "+strconv.Quote(spec.path)))
+		}
+	}
+	if len(missing) == 0 {
+		return src, nil
+	}
+
+	// Insert in the first parenthesized import declaration, or in a new one
+	// after the package clause
+	offset := fset.Position(file.Name.End()).Offset
+	insert := "\n\nimport (\n\t" + strings.Join(missing, "\n\t") + "\n)"
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT && gen.Rparen.IsValid() {
+			offset = fset.Position(gen.Rparen).Offset
+			insert = "\t" + strings.Join(missing, "\n\t") + "\n"
+			break
+		}
+	}
+
+	out := make([]byte, 0, len(src)+len(insert))
+	out = append(out, src[:offset]...)
+	out = append(out, insert...)
+	return append(out, src[offset:]...), nil
+}
+
 func countOrphanedHandlers(orphanedCode string) int {
 	return strings.Count(orphanedCode, "// Orphaned:")
 }
@@ -695,13 +1363,23 @@ func contains(slice []string, item string) bool {
 	return false
 }
 
+func remove(slice []string, item string) []string {
+	result := slice[:0]
+	for _, s := range slice {
+		if s != item {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
 func (g *Generator) generateNewHandlersCode(handlerNames []string) (string, error) {
 	if len(handlerNames) == 0 {
 		return "", nil
 	}
 
 	// Parse the resolver template to extract individual handler templates
-	tmpl, err := template.ParseFS(templates, "templates/resolver.gotpl")
+	tmpl, err := g.parseTemplate("resolver.gotpl")
 	if err != nil {
 		return "", fmt.Errorf("failed to parse resolver template: %w", err)
 	}
@@ -761,13 +1439,17 @@ func (g *Generator) generateNewHandlersCode(handlerNames []string) (string, erro
 	handlersOnlyTmpl, err := template.New("handlers").Parse(`
 {{- range .Tools }}
 
-{{- if .HasInputType }}
+{{- if .NoInput }}
+func (r *{{ $.ResolverType }}) {{ .HandlerName }}Tool(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, {{ if .HasOutputType }}{{ .OutputType }}{{ else }}map[string]any{{ end }}, error) {
+	return nil, {{ if .HasOutputType }}{{ .OutputType }}{}{{ else }}nil{{ end }}, mcputil.ErrNotImplemented
+}
+{{- else if .HasInputType }}
 func (r *{{ $.ResolverType }}) {{ .HandlerName }}Tool(ctx context.Context, req *mcp.CallToolRequest, input *{{ .InputType }}) (*mcp.CallToolResult, {{ if .HasOutputType }}{{ .OutputType }}{{ else }}map[string]any{{ end }}, error) {
-	return nil, {{ if .HasOutputType }}{{ .OutputType }}{}{{ else }}nil{{ end }}, fmt.Errorf("{{ .Name }} not implemented")
+	return nil, {{ if .HasOutputType }}{{ .OutputType }}{}{{ else }}nil{{ end }}, mcputil.ErrNotImplemented
 }
 {{- else }}
 func (r *{{ $.ResolverType }}) {{ .HandlerName }}Tool(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, {{ if .HasOutputType }}{{ .OutputType }}{{ else }}map[string]any{{ end }}, error) {
-	return nil, {{ if .HasOutputType }}{{ .OutputType }}{}{{ else }}nil{{ end }}, fmt.Errorf("{{ .Name }} not implemented")
+	return nil, {{ if .HasOutputType }}{{ .OutputType }}{}{{ else }}nil{{ end }}, mcputil.ErrNotImplemented
 }
 {{- end }}
 {{- end }}
@@ -775,7 +1457,7 @@ func (r *{{ $.ResolverType }}) {{ .HandlerName }}Tool(ctx context.Context, req *
 {{- range .Resources }}
 
 func (r *{{ $.ResolverType }}) {{ .HandlerName }}Resource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
-	return nil, fmt.Errorf("{{ .Name }} not implemented")
+	return nil, fmt.Errorf("{{ .Name }}: %w", mcputil.ErrNotImplemented)
 }
 {{- end }}
 
@@ -805,24 +1487,72 @@ func (r *{{ $.ResolverType }}) {{ .HandlerName }}Prompt(ctx context.Context, req
 	return buf.String(), nil
 }
 
+// getRequiredHandlerNames returns the resolver handlers of the current group.
 func (g *Generator) getRequiredHandlerNames() []string {
 	var names []string
 
 	for _, tool := range g.spec.Tools {
+		if tool.IsComposite() || tool.Group != g.group {
+			continue
+		}
 		names = append(names, toHandlerName(tool.Name)+"Tool")
 	}
 
 	for _, resource := range g.spec.Resources {
-		names = append(names, toHandlerName(resource.Name)+"Resource")
+		if resource.Group == g.group {
+			names = append(names, toHandlerName(resource.Name)+"Resource")
+		}
 	}
 
 	for _, prompt := range g.spec.Prompts {
-		names = append(names, toHandlerName(prompt.Name)+"Prompt")
+		if prompt.Group == g.group {
+			names = append(names, toHandlerName(prompt.Name)+"Prompt")
+		}
 	}
 
 	return names
 }
 
+// fixtureLiteral returns the Go string literal of the JSON encoding of a
+// devFixture, or an empty string when there is none.
+func fixtureLiteral(fixture any) string {
+	if fixture == nil {
+		return ""
+	}
+	data, err := canonicalJSON(fixture)
+	if err != nil {
+		return ""
+	}
+	return strconv.Quote(string(data))
+}
+
+// componentSchemaData returns the names and resolved JSON schemas of the
+// components, sorted by name. With exec.minimal_runtime, the schemas are the
+// shared variables of the models package, prefixed with typePrefix.
+func (g *Generator) componentSchemaData(typePrefix string) []map[string]string {
+	names := make([]string, 0, len(g.componentSchemas))
+	for name := range g.componentSchemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	schemas := make([]map[string]string, 0, len(names))
+	for _, name := range names {
+		if g.config.Exec.MinimalRuntime {
+			schemas = append(schemas, map[string]string{
+				"Name":      name,
+				"SchemaVar": typePrefix + sharedSchemaVar(name, false),
+			})
+			continue
+		}
+		schemas = append(schemas, map[string]string{
+			"Name":   name,
+			"Schema": g.componentSchemas[name],
+		})
+	}
+	return schemas
+}
+
 func (g *Generator) buildServerTemplateData() map[string]interface{} {
 	// Compute type prefix if model package is different from exec package
 	modelPackage := g.config.Model.Package
@@ -839,80 +1569,54 @@ func (g *Generator) buildServerTemplateData() map[string]interface{} {
 		modelImportPath = g.computeModelImportPath()
 	}
 
-	tools := make([]map[string]interface{}, 0, len(g.spec.Tools))
+	// Resolve the schemas of the tools concurrently, the longest part of
+	// the server data of large specs
+	tools := make([]map[string]interface{}, len(g.spec.Tools))
+	_ = parallel(g.trace, len(g.spec.Tools), func(i int) error {
+		tools[i] = g.serverToolData(g.spec.Tools[i], typePrefix)
+		return nil
+	})
+
 	hasTypedTools := false
+	hasReplacedTools := false
+	hasToolFixtures := false
+	var toolCapabilities, resourceCapabilities, promptCapabilities bool
 	for _, tool := range g.spec.Tools {
-		toolData := map[string]interface{}{
-			"Name":        tool.Name,
-			"Description": tool.Description,
-			"HandlerName": toHandlerName(tool.Name),
-		}
-
-		// Add hints if present
-		if tool.Hints != nil {
-			toolData["Readonly"] = tool.Hints.Readonly
-			toolData["Destructive"] = tool.Hints.Destructive
-			toolData["Idempotent"] = tool.Hints.Idempotent
-			toolData["OpenWorld"] = tool.Hints.OpenWorld
-		}
-
-		// Add input type information and schema code
-		if tool.InputSchema != nil {
-			inputTypeName := typePrefix + toPascalCase(tool.Name) + "Input"
-			toolData["InputType"] = inputTypeName
-			toolData["HasInputType"] = true
-
-			// Add schema variable name with proper prefix
-			schemaVarName := typePrefix + toHandlerName(tool.Name) + "ToolInputSchema"
-			toolData["InputSchemaVar"] = schemaVarName
-
-			resolvedSchema := tool.InputSchema
-			if config.IsSchemaRef(tool.InputSchema) && len(tool.InputSchema.Ref) > 0 && tool.InputSchema.Ref[0] == '#' {
-				resolved, err := g.spec.ResolveSchemaRef(tool.InputSchema.Ref)
-				if err == nil {
-					resolvedSchema = resolved
-				}
-			}
-
-			schemaCode := g.generateSchemaCode(resolvedSchema)
-			toolData["InputSchemaCode"] = schemaCode
-
-			hasTypedTools = true
+		toolCapabilities = toolCapabilities || len(tool.RequiresClientCapability) > 0
+		hasTypedTools = hasTypedTools || tool.InputSchema != nil && !tool.TakesNoInput()
+		if g.config.Exec.ToolDocs {
+			// The docs of a tool are hidden from the clients the tool is
+			// hidden from
+			resourceCapabilities = resourceCapabilities || len(tool.RequiresClientCapability) > 0
 		}
-
-		// Add output type information and schema code
-		if tool.OutputSchema != nil {
-			outputTypeName := typePrefix + toPascalCase(tool.Name) + "Output"
-			toolData["OutputType"] = outputTypeName
-			toolData["HasOutputType"] = true
-
-			// Add schema variable name with proper prefix
-			schemaVarName := typePrefix + toHandlerName(tool.Name) + "ToolOutputSchema"
-			toolData["OutputSchemaVar"] = schemaVarName
-
-			resolvedSchema := tool.OutputSchema
-			if config.IsSchemaRef(tool.OutputSchema) && len(tool.OutputSchema.Ref) > 0 && tool.OutputSchema.Ref[0] == '#' {
-				resolved, err := g.spec.ResolveSchemaRef(tool.OutputSchema.Ref)
-				if err == nil {
-					resolvedSchema = resolved
-				}
-			}
-
-			schemaCode := g.generateSchemaCode(resolvedSchema)
-			toolData["OutputSchemaCode"] = schemaCode
-		}
-
-		tools = append(tools, toolData)
+		hasReplacedTools = hasReplacedTools || tool.ReplacedBy != ""
+		hasToolFixtures = hasToolFixtures || tool.DevFixture != nil
 	}
 
 	resources := make([]map[string]interface{}, 0, len(g.spec.Resources))
 	for _, resource := range g.spec.Resources {
 		resData := map[string]interface{}{
-			"Name":        resource.Name,
-			"Description": resource.Description,
-			"HandlerName": toHandlerName(resource.Name),
-			"MimeType":    resource.MimeType,
-			"Readonly":    resource.Readonly,
+			"Name":               resource.Name,
+			"Description":        quoteText(resource.Description),
+			"HandlerName":        toHandlerName(resource.Name),
+			"Group":              resource.Group,
+			"Receiver":           handlerReceiver(resource.Group),
+			"MimeType":           resource.MimeType,
+			"Encoding":           resource.Encoding,
+			"Readonly":           resource.Readonly,
+			"ClientCapabilities": quoteList(resource.RequiresClientCapability),
+			"Extensions":         resource.Extensions,
+			"DevFixture":         fixtureLiteral(resource.DevFixture),
+		}
+		resourceCapabilities = resourceCapabilities || len(resource.RequiresClientCapability) > 0
+		if resource.Title != "" {
+			resData["Title"] = quoteText(resource.Title)
+		}
+		if resource.Encoding != "" {
+			resData["ContentType"] = "any"
+			if resource.Schema != nil {
+				resData["ContentType"] = typePrefix + toPascalCase(resource.Name) + "Content"
+			}
 		}
 
 		if resource.URI != "" {
@@ -932,16 +1636,24 @@ func (g *Generator) buildServerTemplateData() map[string]interface{} {
 		for _, arg := range prompt.Arguments {
 			args = append(args, map[string]interface{}{
 				"Name":        arg.Name,
-				"Description": arg.Description,
+				"Description": quoteText(arg.Description),
 				"Required":    arg.Required,
 			})
 		}
 
 		promptData := map[string]interface{}{
-			"Name":        prompt.Name,
-			"Description": prompt.Description,
-			"HandlerName": toHandlerName(prompt.Name),
-			"Arguments":   args,
+			"Name":               prompt.Name,
+			"Description":        quoteText(prompt.Description),
+			"HandlerName":        toHandlerName(prompt.Name),
+			"Group":              prompt.Group,
+			"Receiver":           handlerReceiver(prompt.Group),
+			"Arguments":          args,
+			"ClientCapabilities": quoteList(prompt.RequiresClientCapability),
+			"Extensions":         prompt.Extensions,
+		}
+		promptCapabilities = promptCapabilities || len(prompt.RequiresClientCapability) > 0
+		if prompt.Title != "" {
+			promptData["Title"] = quoteText(prompt.Title)
 		}
 
 		// Add args type if there are arguments
@@ -954,17 +1666,67 @@ func (g *Generator) buildServerTemplateData() map[string]interface{} {
 		prompts = append(prompts, promptData)
 	}
 
+	experiments := make([]map[string]interface{}, 0, len(g.spec.Experiments))
+	for _, experiment := range g.spec.Experiments {
+		experimentData := map[string]interface{}{
+			"Name":      experiment.Name,
+			"FieldName": toPascalCase(experiment.Name),
+		}
+		if experiment.Description != "" {
+			experimentData["Comment"] = strings.TrimSuffix(formatComment(experiment.Description, "\t"), "\n")
+		}
+		experiments = append(experiments, experimentData)
+	}
+
+	env := make([]map[string]interface{}, 0, len(g.spec.Env))
+	for _, v := range g.spec.Env {
+		envData := map[string]interface{}{
+			"Name":     strconv.Quote(v.Name),
+			"Required": v.Required,
+		}
+		if v.Description != "" {
+			envData["Description"] = quoteText(v.Description)
+		}
+		if v.Default != "" {
+			envData["Default"] = strconv.Quote(v.Default)
+		}
+		env = append(env, envData)
+	}
+
 	data := map[string]interface{}{
-		"Package":       g.config.Exec.Package,
-		"ServerName":    g.spec.Info.Title,
-		"ServerVersion": g.spec.Info.Version,
-		"ResolverType":  g.config.Resolver.Type,
-		"Tools":         tools,
-		"Resources":     resources,
-		"Prompts":       prompts,
-		"HasResources":  len(resources) > 0,
-		"HasPrompts":    len(prompts) > 0,
-		"HasTypedTools": hasTypedTools,
+		"Package":              g.config.Exec.Package,
+		"ServerName":           g.spec.Info.Title,
+		"ServerVersion":        g.spec.Info.Version,
+		"ResolverType":         g.config.Resolver.Type,
+		"Tools":                tools,
+		"Resources":            resources,
+		"Prompts":              prompts,
+		"HasResources":         len(resources) > 0,
+		"HasPrompts":           len(prompts) > 0,
+		"HasTypedTools":        hasTypedTools,
+		"HasReplacedTools":     hasReplacedTools,
+		"HasToolFixtures":      hasToolFixtures,
+		"LenientCoercion":      g.config.Exec.LenientCoercion,
+		"ValidateInput":        g.config.Exec.ValidateInput,
+		"ValidateOutput":       g.config.Exec.ValidateOutput,
+		"SanitizeResults":      g.config.Exec.SanitizeResults,
+		"SlowCallThreshold":    goDuration(g.config.Exec.SlowCallThreshold),
+		"SwappableResolver":    g.config.Exec.SwappableResolver,
+		"SchemaRegistry":       g.config.Exec.SchemaRegistry,
+		"LazyTools":            g.config.Exec.LazyTools,
+		"ToolDocs":             g.config.Exec.ToolDocs,
+		"Admin":                g.config.Exec.Admin,
+		"ComponentSchemas":     g.componentSchemaData(typePrefix),
+		"ToolCapabilities":     toolCapabilities,
+		"ResourceCapabilities": resourceCapabilities,
+		"PromptCapabilities":   promptCapabilities,
+		"HasCapabilities":      toolCapabilities || resourceCapabilities || promptCapabilities,
+		"Experiments":          experiments,
+		"HasExperiments":       len(experiments) > 0,
+		"Env":                  env,
+		"Groups":               g.groupsData(),
+		"SpecHash":             g.specHash(),
+		"MCPGenVersion":        Version,
 	}
 
 	// Add imports if packages are different from exec package
@@ -983,6 +1745,92 @@ func (g *Generator) buildServerTemplateData() map[string]interface{} {
 	return data
 }
 
+// serverToolData returns the data of tool for the server template, with its
+// resolved schemas.
+func (g *Generator) serverToolData(tool config.Tool, typePrefix string) map[string]interface{} {
+	toolData := map[string]interface{}{
+		"Name":               tool.Name,
+		"Description":        quoteText(tool.Description),
+		"HandlerName":        toHandlerName(tool.Name),
+		"Group":              tool.Group,
+		"Receiver":           handlerReceiver(tool.Group),
+		"ClientCapabilities": quoteList(tool.RequiresClientCapability),
+		"Experiment":         tool.Experiment,
+		"Extensions":         tool.Extensions,
+		"Deprecated":         tool.Deprecated,
+		"ReplacedBy":         tool.ReplacedBy,
+		"DevFixture":         fixtureLiteral(tool.DevFixture),
+	}
+	if tool.Title != "" {
+		toolData["Title"] = quoteText(tool.Title)
+	}
+
+	// Add hints if present
+	if tool.Hints != nil {
+		toolData["Readonly"] = tool.Hints.Readonly
+		toolData["Destructive"] = tool.Hints.Destructive
+		toolData["Idempotent"] = tool.Hints.Idempotent
+		toolData["OpenWorld"] = tool.Hints.OpenWorld
+	}
+
+	// Add input type information and schema code
+	toolData["NoInput"] = tool.TakesNoInput()
+	if tool.InputSchema != nil && !tool.TakesNoInput() {
+		inputTypeName := typePrefix + toPascalCase(tool.Name) + "Input"
+		toolData["InputType"] = inputTypeName
+		toolData["HasInputType"] = true
+
+		// Add schema variable name with proper prefix
+		schemaVarName := typePrefix + toHandlerName(tool.Name) + "ToolInputSchema"
+		toolData["InputSchemaVar"] = schemaVarName
+
+		resolvedSchema := tool.InputSchema
+		if config.IsSchemaRef(tool.InputSchema) && len(tool.InputSchema.Ref) > 0 && tool.InputSchema.Ref[0] == '#' {
+			resolved, err := g.spec.ResolveSchemaRef(tool.InputSchema.Ref)
+			if err == nil {
+				resolvedSchema = resolved
+			}
+		}
+
+		if !g.config.Exec.MinimalRuntime {
+			toolData["InputSchemaCode"] = g.generateSchemaCode(resolvedSchema)
+		}
+	}
+
+	// Add output type information and schema code
+	if tool.OutputSchema != nil {
+		outputTypeName := typePrefix + toPascalCase(tool.Name) + "Output"
+		toolData["OutputType"] = outputTypeName
+		toolData["HasOutputType"] = true
+
+		// Add schema variable name with proper prefix
+		schemaVarName := typePrefix + toHandlerName(tool.Name) + "ToolOutputSchema"
+		toolData["OutputSchemaVar"] = schemaVarName
+
+		resolvedSchema := tool.OutputSchema
+		if config.IsSchemaRef(tool.OutputSchema) && len(tool.OutputSchema.Ref) > 0 && tool.OutputSchema.Ref[0] == '#' {
+			resolved, err := g.spec.ResolveSchemaRef(tool.OutputSchema.Ref)
+			if err == nil {
+				resolvedSchema = resolved
+			}
+		}
+
+		if !g.config.Exec.MinimalRuntime {
+			toolData["OutputSchemaCode"] = g.generateSchemaCode(resolvedSchema)
+		}
+	}
+
+	if tool.IsComposite() {
+		toolData["Composite"] = g.compositeData(tool, typePrefix)
+	}
+
+	if g.config.Exec.ToolDocs {
+		toolData["Doc"] = quoteText(g.toolDoc(tool))
+	}
+
+	return toolData
+}
+
 func (g *Generator) buildResolverTemplateData() map[string]interface{} {
 	// Resolver template data is similar to server template data, but uses resolver package
 	modelPackage := g.config.Model.Package
@@ -1002,10 +1850,16 @@ func (g *Generator) buildResolverTemplateData() map[string]interface{} {
 	tools := make([]map[string]interface{}, 0, len(g.spec.Tools))
 	hasTypedTools := false
 	for _, tool := range g.spec.Tools {
+		if tool.IsComposite() || tool.Group != g.group {
+			// The server generates the handlers of the composite tools
+			continue
+		}
 		toolData := map[string]interface{}{
 			"Name":        tool.Name,
+			"Title":       tool.Title,
 			"Description": tool.Description,
 			"HandlerName": toHandlerName(tool.Name),
+			"Extensions":  tool.Extensions,
 		}
 
 		// Add hints if present
@@ -1017,7 +1871,8 @@ func (g *Generator) buildResolverTemplateData() map[string]interface{} {
 		}
 
 		// Add input type information
-		if tool.InputSchema != nil {
+		toolData["NoInput"] = tool.TakesNoInput()
+		if tool.InputSchema != nil && !tool.TakesNoInput() {
 			inputTypeName := typePrefix + toPascalCase(tool.Name) + "Input"
 			toolData["InputType"] = inputTypeName
 			toolData["HasInputType"] = true
@@ -1036,12 +1891,17 @@ func (g *Generator) buildResolverTemplateData() map[string]interface{} {
 
 	resources := make([]map[string]interface{}, 0, len(g.spec.Resources))
 	for _, resource := range g.spec.Resources {
+		if resource.Group != g.group {
+			continue
+		}
 		resData := map[string]interface{}{
 			"Name":        resource.Name,
+			"Title":       resource.Title,
 			"Description": resource.Description,
 			"HandlerName": toHandlerName(resource.Name),
 			"MimeType":    resource.MimeType,
 			"Readonly":    resource.Readonly,
+			"Extensions":  resource.Extensions,
 		}
 
 		if resource.URI != "" {
@@ -1057,6 +1917,9 @@ func (g *Generator) buildResolverTemplateData() map[string]interface{} {
 
 	prompts := make([]map[string]interface{}, 0, len(g.spec.Prompts))
 	for _, prompt := range g.spec.Prompts {
+		if prompt.Group != g.group {
+			continue
+		}
 		args := make([]map[string]interface{}, 0, len(prompt.Arguments))
 		for _, arg := range prompt.Arguments {
 			args = append(args, map[string]interface{}{
@@ -1068,9 +1931,11 @@ func (g *Generator) buildResolverTemplateData() map[string]interface{} {
 
 		promptData := map[string]interface{}{
 			"Name":        prompt.Name,
+			"Title":       prompt.Title,
 			"Description": prompt.Description,
 			"HandlerName": toHandlerName(prompt.Name),
 			"Arguments":   args,
+			"Extensions":  prompt.Extensions,
 		}
 
 		// Add args type if there are arguments
@@ -1087,7 +1952,7 @@ func (g *Generator) buildResolverTemplateData() map[string]interface{} {
 		"Package":       g.config.Resolver.Package,
 		"ServerName":    g.spec.Info.Title,
 		"ServerVersion": g.spec.Info.Version,
-		"ResolverType":  g.config.Resolver.Type,
+		"ResolverType":  g.resolverType(g.group),
 		"Tools":         tools,
 		"Resources":     resources,
 		"Prompts":       prompts,
@@ -1097,12 +1962,33 @@ func (g *Generator) buildResolverTemplateData() map[string]interface{} {
 	}
 
 	// Add model package import if different from resolver package
+	var imports []map[string]string
 	if modelPackage != resolverPackage && modelImportPath != "" {
-		var imports []map[string]string
 		imports = append(imports, map[string]string{
 			"Path":  modelImportPath,
 			"Alias": "",
 		})
+	}
+
+	// The sub-resolver of a group implements an interface of the server
+	if g.group != "" {
+		serverPrefix := ""
+		if g.config.Exec.Package != resolverPackage {
+			parts := strings.Split(g.config.Exec.Package, "/")
+			serverPrefix = parts[len(parts)-1] + "."
+			imports = append(imports, map[string]string{
+				"Path":  g.computeImportPath(g.config.Exec.Package, g.config.Exec.Filename),
+				"Alias": "",
+			})
+		}
+		data["Group"] = map[string]string{
+			"Name":      g.group,
+			"Accessor":  groupAccessor(g.group),
+			"Interface": serverPrefix + groupInterface(g.group),
+			"RootType":  g.config.Resolver.Type,
+		}
+	}
+	if len(imports) > 0 {
 		data["Imports"] = imports
 	}
 
@@ -1215,8 +2101,20 @@ func toHandlerName(name string) string {
 	return strings.Join(parts, "")
 }
 
+// specHash returns the SHA-256 of the JSON encoding of the spec, which is
+// stable across formatting and key order changes in the source file.
+func (g *Generator) specHash() string {
+	specJSON, err := json.Marshal(g.spec)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(specJSON)
+	return hex.EncodeToString(sum[:])
+}
+
 func (g *Generator) generateSchemaCode(s *config.Schema) string {
-	schemaJSON, err := json.Marshal(s)
+	schemaJSON, err := canonicalJSON(s)
 	if err != nil {
 		return "nil"
 	}
@@ -1243,6 +2141,22 @@ func extractURIParams(uriTemplate string) []map[string]interface{} {
 	return params
 }
 
+// quoteText returns the Go literal of a title or description, cleaned with
+// mcputil.SanitizeText: text imported from other specs can hold quotes,
+// control characters or invalid UTF-8.
+func quoteText(text string) string {
+	return strconv.Quote(mcputil.SanitizeText(text))
+}
+
+// quoteList returns the Go literals of values, separated by commas.
+func quoteList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = strconv.Quote(value)
+	}
+	return strings.Join(quoted, ", ")
+}
+
 func extractGoTypeAnnotation(s *config.Schema) string {
 	if s == nil || s.Extra == nil {
 		return ""
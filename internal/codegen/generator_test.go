@@ -647,6 +647,12 @@ func TestFullGenerateWorkflow(t *testing.T) {
 	if !containsString(serverStr, "mcp.NewServer") {
 		t.Error("server.go should use MCP SDK")
 	}
+	if !containsString(serverStr, "func Run(ctx context.Context, resolver ResolverInterface, cfg RunConfig") {
+		t.Error("server.go should contain Run function")
+	}
+	if !containsString(serverStr, "func ServerInfo() mcputil.BuildInfo") {
+		t.Error("server.go should contain ServerInfo function")
+	}
 
 	resolverContent, err := os.ReadFile(filepath.Join(outputDir, "resolver.go"))
 	require.NoError(t, err, "Failed to read resolver.go")
@@ -665,8 +671,8 @@ func TestFullGenerateWorkflow(t *testing.T) {
 	}
 }
 
-func TestGenerateWithDifferentPackages(t *testing.T) {
-	specPath := filepath.Join("testdata", "custom_types.yaml")
+func TestGenerateServerWithLenientCoercion(t *testing.T) {
+	specPath := filepath.Join("testdata", "config_based_types.yaml")
 	spec, err := config.LoadMCPSpec(specPath)
 	require.NoError(t, err, "Failed to load spec")
 
@@ -675,85 +681,97 @@ func TestGenerateWithDifferentPackages(t *testing.T) {
 		Spec:   specPath,
 		Output: outputDir,
 		Exec: config.ExecConfig{
-			Package:  "server",
-			Filename: "server.go",
+			Package:         "test",
+			Filename:        "server.go",
+			LenientCoercion: true,
 		},
 		Model: config.ModelConfig{
-			Package:  "types",
-			Filename: "types/models.go",
+			Package:  "test",
+			Filename: "models.go",
 		},
 		Resolver: config.ResolverConfig{
-			Package:  "mcp_v1",
+			Package:  "test",
 			Filename: "resolver.go",
 			Type:     "Resolver",
-			Preserve: false,
-		},
-		Models: config.ModelsConfig{
-			Models: map[string]config.TypeMapping{},
 		},
 	}
 
 	gen := New(cfg, spec)
+	require.NoError(t, gen.Generate())
 
-	// Load schemas
-	if err := gen.loadSchemas(); err != nil {
-		t.Fatalf("loadSchemas() error = %v", err)
-	}
+	serverContent, err := os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
 
-	data := gen.buildServerTemplateData()
+	serverStr := string(serverContent)
+	assert.Contains(t, serverStr, `"github.com/google/jsonschema-go/jsonschema"`)
+	assert.Contains(t, serverStr, "server.AddReceivingMiddleware(mcputil.CoercionMiddleware(toolInputSchemas))")
+	assert.Contains(t, serverStr, `"create_event": CreateEventToolInputSchema,`)
 
-	// When model package differs from exec package, should have imports
-	imports, ok := data["Imports"]
-	assert.True(t, ok, "Should have Imports when packages differ")
+	cfg.Exec.LenientCoercion = false
+	require.NoError(t, New(cfg, spec).generateServer())
 
-	if imports != nil {
-		importList, ok := imports.([]map[string]string)
-		require.True(t, ok, "Imports should be []map[string]string")
-		assert.NotEmpty(t, importList, "Imports list should not be empty when packages differ")
-	}
+	serverContent, err = os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	assert.NotContains(t, string(serverContent), "CoercionMiddleware")
+	assert.NotContains(t, string(serverContent), "jsonschema")
 }
 
-func TestCountOrphanedHandlers(t *testing.T) {
-	tests := []struct {
-		name   string
-		code   string
-		want   int
-	}{
-		{
-			name: "no orphaned handlers",
-			code: "func (r *Resolver) Handler() {}",
-			want: 0,
+func TestGenerateServerWithInputValidation(t *testing.T) {
+	specPath := filepath.Join("testdata", "config_based_types.yaml")
+	spec, err := config.LoadMCPSpec(specPath)
+	require.NoError(t, err, "Failed to load spec")
+
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Spec:   specPath,
+		Output: outputDir,
+		Exec: config.ExecConfig{
+			Package:       "test",
+			Filename:      "server.go",
+			ValidateInput: true,
 		},
-		{
-			name: "one orphaned handler",
-			code: "// Orphaned: OldHandler\nfunc (r *Resolver) OldHandler() {}",
-			want: 1,
+		Model: config.ModelConfig{
+			Package:  "test",
+			Filename: "models.go",
 		},
-		{
-			name: "multiple orphaned handlers",
-			code: "// Orphaned: Handler1\nfunc (r *Resolver) Handler1() {}\n// Orphaned: Handler2\nfunc (r *Resolver) Handler2() {}",
-			want: 2,
+		Resolver: config.ResolverConfig{
+			Package:  "test",
+			Filename: "resolver.go",
+			Type:     "Resolver",
 		},
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got := countOrphanedHandlers(tt.code)
-			if got != tt.want {
-				t.Errorf("countOrphanedHandlers() = %d, want %d", got, tt.want)
-			}
-		})
-	}
+	require.NoError(t, New(cfg, spec).Generate())
+
+	serverContent, err := os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+
+	serverStr := string(serverContent)
+	assert.Contains(t, serverStr, "server.AddReceivingMiddleware(mcputil.ValidationMiddleware(toolInputSchemas, o.ReportValidationFailure))")
+	assert.Contains(t, serverStr, `"create_event": CreateEventToolInputSchema,`)
+	assert.NotContains(t, serverStr, "CoercionMiddleware")
+
+	cfg.Exec.LenientCoercion = true
+	require.NoError(t, New(cfg, spec).generateServer())
+
+	serverContent, err = os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	assert.Regexp(t, `(?s)mcputil\.ValidationMiddleware.*mcputil\.CoercionMiddleware`, string(serverContent), "coercion wraps validation, so arguments are coerced before they are validated")
 }
 
-func TestGenerateNewHandlersCode(t *testing.T) {
+func TestGenerateServerWithOutputValidation(t *testing.T) {
 	specPath := filepath.Join("testdata", "config_based_types.yaml")
 	spec, err := config.LoadMCPSpec(specPath)
 	require.NoError(t, err, "Failed to load spec")
 
+	outputDir := t.TempDir()
 	cfg := &config.Config{
 		Spec:   specPath,
-		Output: t.TempDir(),
+		Output: outputDir,
+		Exec: config.ExecConfig{
+			Package:  "test",
+			Filename: "server.go",
+		},
 		Model: config.ModelConfig{
 			Package:  "test",
 			Filename: "models.go",
@@ -762,626 +780,1964 @@ func TestGenerateNewHandlersCode(t *testing.T) {
 			Package:  "test",
 			Filename: "resolver.go",
 			Type:     "Resolver",
-			Preserve: false,
-		},
-		Models: config.ModelsConfig{
-			Models: map[string]config.TypeMapping{},
 		},
 	}
 
-	gen := New(cfg, spec)
-	if err := gen.loadSchemas(); err != nil {
-		t.Fatalf("Failed to load schemas: %v", err)
-	}
+	require.NoError(t, New(cfg, spec).generateServer())
 
-	tests := []struct {
-		name         string
-		handlerNames []string
-		wantContains []string
-		isEmpty      bool
-	}{
-		{
-			name:         "empty handler list",
-			handlerNames: []string{},
-			isEmpty:      true,
+	serverContent, err := os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	assert.NotContains(t, string(serverContent), "WithOutputValidation")
+
+	cfg.Exec.ValidateOutput = true
+	require.NoError(t, New(cfg, spec).generateServer())
+
+	serverContent, err = os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	assert.Regexp(t, `(?s)opts = append\(\[\]mcputil\.Option\{mcputil\.WithOutputValidation\(true\)\}, opts\.\.\.\).*mcputil\.ApplyOptions\(opts\)`, string(serverContent), "output validation is enabled before the options are applied")
+}
+
+func TestGenerateServerWithLazyTools(t *testing.T) {
+	specPath := filepath.Join("testdata", "config_based_types.yaml")
+	spec, err := config.LoadMCPSpec(specPath)
+	require.NoError(t, err, "Failed to load spec")
+
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Spec:   specPath,
+		Output: outputDir,
+		Exec: config.ExecConfig{
+			Package:  "test",
+			Filename: "server.go",
 		},
-		{
-			name:         "single handler",
-			handlerNames: []string{"CreateEventTool"},
-			wantContains: []string{"CreateEventTool", "func (r *Resolver) CreateEventTool"},
-			isEmpty:      false,
+		Model: config.ModelConfig{
+			Package:  "test",
+			Filename: "models.go",
+		},
+		Resolver: config.ResolverConfig{
+			Package:  "test",
+			Filename: "resolver.go",
+			Type:     "Resolver",
 		},
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			code, err := gen.generateNewHandlersCode(tt.handlerNames)
-			if err != nil {
-				t.Errorf("generateNewHandlersCode() error = %v", err)
-				return
-			}
+	require.NoError(t, New(cfg, spec).generateServer())
 
-			if tt.isEmpty {
-				if code != "" {
-					t.Errorf("Expected empty code, got: %q", code)
-				}
-				return
-			}
+	serverContent, err := os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	assert.NotContains(t, string(serverContent), "WithLazyTools")
+	assert.NotContains(t, string(serverContent), "func Prewarm")
 
-			for _, want := range tt.wantContains {
-				if !containsString(code, want) {
-					t.Errorf("Generated code should contain %q\nGot: %s", want, code)
-				}
-			}
-		})
-	}
+	cfg.Exec.LazyTools = true
+	require.NoError(t, New(cfg, spec).generateServer())
+
+	serverContent, err = os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	assert.Regexp(t, `(?s)opts = append\(\[\]mcputil\.Option\{mcputil\.WithLazyTools\(true\)\}, opts\.\.\.\).*mcputil\.ApplyOptions\(opts\)`, string(serverContent), "lazy tools are enabled before the options are applied")
+	assert.Regexp(t, `(?s)func Prewarm\(\) error \{\n\treturn mcputil\.Prewarm\(\n\t\t\w+ToolInputSchema,\n`, string(serverContent))
 }
 
-func TestResolveAllRefs(t *testing.T) {
-	spec := &config.MCPSpec{
-		Components: config.Components{
-			Schemas: map[string]*config.Schema{
-				"SimpleType": {
-					Type:   "string",
-					Format: "uuid",
-				},
-				"ComplexType": {
-					Type: "object",
-					Properties: map[string]*config.Schema{
-						"id": {
-							Ref: "#/components/schemas/SimpleType",
-						},
-						"name": {
-							Type: "string",
-						},
-					},
-				},
-				"WithExtensions": {
-					Type:   "string",
-					Format: "date-time",
-					Extra: map[string]interface{}{
-						"go.probo.inc/mcpgen/type":      "time.Time",
-						"x-custom-field": "value",
-					},
-				},
-				"NestedRef": {
-					Type: "object",
-					Properties: map[string]*config.Schema{
-						"complex": {
-							Ref: "#/components/schemas/ComplexType",
-						},
-					},
-				},
-			},
+func TestGenerateServerWithSlowCallThreshold(t *testing.T) {
+	specPath := filepath.Join("testdata", "config_based_types.yaml")
+	spec, err := config.LoadMCPSpec(specPath)
+	require.NoError(t, err, "Failed to load spec")
+
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Spec:   specPath,
+		Output: outputDir,
+		Exec: config.ExecConfig{
+			Package:           "test",
+			Filename:          "server.go",
+			SlowCallThreshold: "1500ms",
+		},
+		Model: config.ModelConfig{
+			Package:  "test",
+			Filename: "models.go",
+		},
+		Resolver: config.ResolverConfig{
+			Package:  "test",
+			Filename: "resolver.go",
+			Type:     "Resolver",
 		},
 	}
+	require.NoError(t, New(cfg, spec).generateServer())
 
-	cfg := &config.Config{}
-	gen := New(cfg, spec)
+	serverContent, err := os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	serverStr := string(serverContent)
+	assert.Contains(t, serverStr, `"time"`)
+	assert.Contains(t, serverStr, "server.AddReceivingMiddleware(mcputil.SlowCallMiddleware(1500*time.Millisecond, o.Logger))")
 
-	tests := []struct {
-		name           string
-		schema         *config.Schema
-		wantType       string
-		wantNoRef      bool
-		wantNoExtras   bool
-		checkProperty  string
-		propertyType   string
-	}{
-		{
-			name:         "nil schema",
-			schema:       nil,
-			wantType:     "",
-			wantNoRef:    true,
+	cfg.Exec.SlowCallThreshold = ""
+	require.NoError(t, New(cfg, spec).generateServer())
+
+	serverContent, err = os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	assert.NotContains(t, string(serverContent), "SlowCallMiddleware")
+}
+
+func TestGenerateServerWithSwappableResolver(t *testing.T) {
+	specPath := filepath.Join("testdata", "config_based_types.yaml")
+	spec, err := config.LoadMCPSpec(specPath)
+	require.NoError(t, err, "Failed to load spec")
+
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Spec:   specPath,
+		Output: outputDir,
+		Exec: config.ExecConfig{
+			Package:           "test",
+			Filename:          "server.go",
+			SwappableResolver: true,
 		},
-		{
-			name: "simple ref resolution",
-			schema: &config.Schema{
-				Ref: "#/components/schemas/SimpleType",
-			},
-			wantType:  "string",
-			wantNoRef: true,
+		Model: config.ModelConfig{
+			Package:  "test",
+			Filename: "models.go",
 		},
-		{
-			name: "nested ref in properties",
-			schema: &config.Schema{
-				Ref: "#/components/schemas/ComplexType",
-			},
-			wantType:      "object",
-			wantNoRef:     true,
-			checkProperty: "id",
-			propertyType:  "string",
+		Resolver: config.ResolverConfig{
+			Package:  "test",
+			Filename: "resolver.go",
+			Type:     "Resolver",
 		},
-		{
-			name: "deep nested refs",
-			schema: &config.Schema{
-				Ref: "#/components/schemas/NestedRef",
-			},
-			wantType:      "object",
-			wantNoRef:     true,
-			checkProperty: "complex",
-			propertyType:  "object",
+	}
+	require.NoError(t, New(cfg, spec).generateServer())
+
+	serverContent, err := os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	serverStr := string(serverContent)
+	assert.Contains(t, serverStr, `"sync/atomic"`)
+	assert.Contains(t, serverStr, "resolver atomic.Pointer[ResolverInterface]")
+	assert.Contains(t, serverStr, "func (s *SwappableResolver) SetResolver(resolver ResolverInterface) {")
+	assert.Contains(t, serverStr, "return s.Resolver().CreateEventTool(ctx, req, input)")
+	assert.Contains(t, serverStr, "if hook, ok := s.Resolver().(mcputil.InitializeHook); ok {")
+	assert.Contains(t, serverStr, "func (s *SwappableResolver) OnShutdown(ctx context.Context) error {")
+	assert.Contains(t, serverStr, "mcputil.LifecycleOptions(resolver),")
+	assert.Contains(t, serverStr, "return errors.Join(err, mcputil.Shutdown(ctx, resolver, cfg.ShutdownTimeout))")
+
+	cfg.Exec.SwappableResolver = false
+	require.NoError(t, New(cfg, spec).generateServer())
+
+	serverContent, err = os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	assert.NotContains(t, string(serverContent), "SwappableResolver")
+	assert.NotContains(t, string(serverContent), "sync/atomic")
+}
+
+func TestGenerateServerWithSchemaRegistry(t *testing.T) {
+	specPath := filepath.Join("testdata", "config_based_types.yaml")
+	spec, err := config.LoadMCPSpec(specPath)
+	require.NoError(t, err, "Failed to load spec")
+
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Spec:   specPath,
+		Output: outputDir,
+		Exec: config.ExecConfig{
+			Package:        "test",
+			Filename:       "server.go",
+			SchemaRegistry: true,
 		},
-		{
-			name: "ref in array items",
-			schema: &config.Schema{
-				Type: "array",
-				Items: &config.Schema{
-					Ref: "#/components/schemas/SimpleType",
-				},
+		Model: config.ModelConfig{
+			Package:  "test",
+			Filename: "models.go",
+		},
+		Resolver: config.ResolverConfig{
+			Package:  "test",
+			Filename: "resolver.go",
+			Type:     "Resolver",
+		},
+	}
+	gen := New(cfg, spec)
+	require.NoError(t, gen.loadSchemas())
+	require.NoError(t, gen.generateServer())
+
+	serverContent, err := os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	serverStr := string(serverContent)
+	assert.Contains(t, serverStr, `"github.com/google/jsonschema-go/jsonschema"`)
+	assert.Contains(t, serverStr, "var Schemas = mcputil.NewSchemaRegistry(map[string]*jsonschema.Schema{")
+	assert.Regexp(t, `"UUID": +mcputil\.MustUnmarshalSchema\(`+"`"+`\{"format":"uuid","type":"string"\}`+"`"+`\),`, serverStr)
+	assert.Regexp(t, `"Event": +mcputil\.MustUnmarshalSchema\(.*"id":\{"format":"uuid","type":"string"\}`, serverStr, "references are resolved")
+
+	cfg.Exec.SchemaRegistry = false
+	require.NoError(t, New(cfg, spec).generateServer())
+
+	serverContent, err = os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	assert.NotContains(t, string(serverContent), "Schemas")
+}
+
+func TestGenerateFake(t *testing.T) {
+	specPath := filepath.Join("testdata", "config_based_types.yaml")
+	spec, err := config.LoadMCPSpec(specPath)
+	require.NoError(t, err, "Failed to load spec")
+
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Spec:   specPath,
+		Output: outputDir,
+		Exec: config.ExecConfig{
+			Package:  "test",
+			Filename: "server.go",
+			Fake: config.FakeConfig{
+				Filename: "testtest/fake.go",
 			},
-			wantType:  "array",
-			wantNoRef: true,
 		},
-		{
-			name: "ref in anyOf",
-			schema: &config.Schema{
-				AnyOf: []*config.Schema{
-					{
-						Ref: "#/components/schemas/SimpleType",
+		Model: config.ModelConfig{
+			Package:  "test",
+			Filename: "models.go",
+		},
+		Resolver: config.ResolverConfig{
+			Package:  "test",
+			Filename: "resolver.go",
+			Type:     "Resolver",
+		},
+	}
+	require.NoError(t, New(cfg, spec).generateFake())
+
+	fakeContent, err := os.ReadFile(filepath.Join(outputDir, "testtest", "fake.go"))
+	require.NoError(t, err, "Failed to read fake.go")
+	fakeStr := string(fakeContent)
+	assert.Contains(t, fakeStr, "package testtest")
+	assert.Contains(t, fakeStr, `server "test"`)
+	assert.Contains(t, fakeStr, "CreateEventTool func(ctx context.Context, req *mcp.CallToolRequest, input *server.CreateEventInput) (*mcp.CallToolResult, map[string]any, error)")
+	assert.Contains(t, fakeStr, "var _ server.ResolverInterface = fakeResolver{}")
+	assert.Contains(t, fakeStr, `r.f.record("tools/call", "create_event", input)`)
+	assert.NotContains(t, fakeStr, `"fmt"`)
+
+	cfg.Exec.Fake.Package = "fakes"
+	require.NoError(t, New(cfg, spec).generateFake())
+
+	fakeContent, err = os.ReadFile(filepath.Join(outputDir, "testtest", "fake.go"))
+	require.NoError(t, err, "Failed to read fake.go")
+	assert.Contains(t, string(fakeContent), "package fakes")
+}
+
+func TestGoDuration(t *testing.T) {
+	assert.Equal(t, "2 * time.Second", goDuration("2s"))
+	assert.Equal(t, "90 * time.Second", goDuration("1m30s"))
+	assert.Equal(t, "1500 * time.Millisecond", goDuration("1.5s"))
+	assert.Equal(t, "", goDuration(""))
+	assert.Equal(t, "", goDuration("-1s"))
+}
+
+func TestGenerateOnly(t *testing.T) {
+	specPath := filepath.Join("testdata", "config_based_types.yaml")
+	spec, err := config.LoadMCPSpec(specPath)
+	require.NoError(t, err, "Failed to load spec")
+
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Spec:   specPath,
+		Output: outputDir,
+		Exec: config.ExecConfig{
+			Package:  "test",
+			Filename: "server.go",
+		},
+		Model: config.ModelConfig{
+			Package:  "test",
+			Filename: "models.go",
+		},
+		Resolver: config.ResolverConfig{
+			Package:  "test",
+			Filename: "resolver.go",
+			Type:     "Resolver",
+		},
+	}
+
+	require.NoError(t, New(cfg, spec).Generate(StageModels))
+
+	entries, err := os.ReadDir(outputDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, ModelsManifest, entries[0].Name())
+	assert.Equal(t, "models.go", entries[1].Name())
+
+	require.NoError(t, New(cfg, spec).Generate(StageServer, StageResolver))
+
+	for _, filename := range []string{"server.go", "resolver.go", "schema.resolvers.go"} {
+		assert.FileExists(t, filepath.Join(outputDir, filename))
+	}
+
+	err = New(cfg, spec).Generate("handlers")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown generation stage "handlers"`)
+}
+
+func TestGenerateOnlyKind(t *testing.T) {
+	specPath := filepath.Join("testdata", "config_based_types.yaml")
+	spec, err := config.LoadMCPSpec(specPath)
+	require.NoError(t, err, "Failed to load spec")
+
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Spec:   specPath,
+		Output: outputDir,
+		Exec: config.ExecConfig{
+			Package:  "test",
+			Filename: "server.go",
+		},
+		Model: config.ModelConfig{
+			Package:  "test",
+			Filename: "models.go",
+		},
+		Resolver: config.ResolverConfig{
+			Package:  "test",
+			Filename: "resolver.go",
+			Type:     "Resolver",
+			Preserve: true,
+		},
+	}
+	require.NoError(t, New(cfg, spec).Generate())
+
+	// Tool changes are left out of a prompts-only generation
+	spec.Tools[0].Name = "schedule_event"
+	spec.Prompts = append(spec.Prompts, config.Prompt{Name: "summarize"})
+
+	plan, err := New(cfg, spec).Plan(KindPrompts)
+	require.NoError(t, err)
+	resolvers := plan.file(filepath.Join(outputDir, "schema.resolvers.go"))
+	require.NotNil(t, resolvers)
+	assert.Equal(t, []string{"SummarizePrompt"}, resolvers.AddedHandlers)
+	assert.Empty(t, resolvers.OrphanedHandlers)
+
+	plan, err = New(cfg, spec).Plan(KindTools)
+	require.NoError(t, err)
+	resolvers = plan.file(filepath.Join(outputDir, "schema.resolvers.go"))
+	require.NotNil(t, resolvers)
+	assert.Equal(t, []string{"ScheduleEventTool"}, resolvers.AddedHandlers)
+	assert.Equal(t, []string{"CreateEventTool"}, resolvers.OrphanedHandlers)
+
+	plan, err = New(cfg, spec).Plan(StageResolver)
+	require.NoError(t, err)
+	resolvers = plan.file(filepath.Join(outputDir, "schema.resolvers.go"))
+	require.NotNil(t, resolvers)
+	assert.ElementsMatch(t, []string{"ScheduleEventTool", "SummarizePrompt"}, resolvers.AddedHandlers)
+}
+
+func TestCheck(t *testing.T) {
+	specPath := filepath.Join("testdata", "config_based_types.yaml")
+	spec, err := config.LoadMCPSpec(specPath)
+	require.NoError(t, err, "Failed to load spec")
+
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Spec:   specPath,
+		Output: outputDir,
+		Exec: config.ExecConfig{
+			Package:  "test",
+			Filename: "server.go",
+		},
+		Model: config.ModelConfig{
+			Package:  "test",
+			Filename: "models.go",
+		},
+		Resolver: config.ResolverConfig{
+			Package:  "test",
+			Filename: "resolver.go",
+			Type:     "Resolver",
+			Preserve: true,
+		},
+	}
+
+	stale, err := New(cfg, spec).Check()
+	require.NoError(t, err)
+	assert.Len(t, stale, 5, "all files should be reported before the first generation")
+
+	entries, err := os.ReadDir(outputDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "Check should not write files")
+
+	require.NoError(t, New(cfg, spec).Generate())
+
+	stale, err = New(cfg, spec).Check()
+	require.NoError(t, err)
+	assert.Empty(t, stale)
+
+	modelsPath := filepath.Join(outputDir, "models.go")
+	require.NoError(t, os.WriteFile(modelsPath, []byte("package test\n"), 0644))
+
+	stale, err = New(cfg, spec).Check()
+	require.NoError(t, err)
+	assert.Equal(t, []string{modelsPath}, stale)
+
+	content, err := os.ReadFile(modelsPath)
+	require.NoError(t, err)
+	assert.Equal(t, "package test\n", string(content), "Check should not modify files")
+}
+
+func TestValidate(t *testing.T) {
+	newConfig := func(outputDir string) *config.Config {
+		return &config.Config{
+			Output: outputDir,
+			Model: config.ModelConfig{
+				Package:  "test",
+				Filename: "models.go",
+			},
+			Resolver: config.ResolverConfig{
+				Package: "test",
+				Type:    "Resolver",
+			},
+		}
+	}
+
+	t.Run("valid spec writes no files", func(t *testing.T) {
+		spec, err := config.LoadMCPSpec(filepath.Join("testdata", "config_based_types.yaml"))
+		require.NoError(t, err, "Failed to load spec")
+
+		outputDir := t.TempDir()
+		require.NoError(t, New(newConfig(outputDir), spec).Validate())
+
+		entries, err := os.ReadDir(outputDir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("missing component ref", func(t *testing.T) {
+		spec := &config.MCPSpec{
+			Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+			Components: config.Components{
+				Schemas: map[string]*config.Schema{
+					"Task": {
+						Type: "object",
+						Properties: map[string]*config.Schema{
+							"owner": {Ref: "#/components/schemas/Missing"},
+						},
 					},
-					{
-						Type: "null",
+				},
+			},
+		}
+
+		err := New(newConfig(t.TempDir()), spec).Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "components.schemas.Task")
+		assert.Contains(t, err.Error(), "schema not found: Missing")
+	})
+
+	t.Run("circular ref", func(t *testing.T) {
+		spec := &config.MCPSpec{
+			Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+			Components: config.Components{
+				Schemas: map[string]*config.Schema{
+					"Task": {
+						Type: "object",
+						Properties: map[string]*config.Schema{
+							"parent": {Ref: "#/components/schemas/Task"},
+						},
 					},
 				},
 			},
-			wantNoRef: true,
-		},
-		{
-			name: "removes x-* extensions",
-			schema: &config.Schema{
-				Ref: "#/components/schemas/WithExtensions",
+		}
+
+		err := New(newConfig(t.TempDir()), spec).Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "components.schemas.Task")
+		assert.Contains(t, err.Error(), "circular schema reference: Task → Task")
+	})
+
+	t.Run("missing tool ref", func(t *testing.T) {
+		spec := &config.MCPSpec{
+			Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+			Tools: []config.Tool{
+				{
+					Name:        "create_task",
+					InputSchema: &config.Schema{Ref: "#/components/schemas/Missing"},
+				},
 			},
-			wantType:     "string",
-			wantNoRef:    true,
-			wantNoExtras: true,
-		},
-		{
-			name: "no ref to resolve",
-			schema: &config.Schema{
-				Type: "string",
+		}
+
+		err := New(newConfig(t.TempDir()), spec).Validate()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "create_task")
+	})
+}
+
+func TestSpecHash(t *testing.T) {
+	newSpec := func(version string) *config.MCPSpec {
+		return &config.MCPSpec{
+			Info: config.ServerInfo{Title: "test", Version: version},
+			Tools: []config.Tool{
+				{Name: "ping", InputSchema: &config.Schema{Type: "object"}},
 			},
-			wantType:  "string",
-			wantNoRef: true,
+		}
+	}
+
+	cfg := &config.Config{}
+	hash := New(cfg, newSpec("1.0.0")).specHash()
+
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, New(cfg, newSpec("1.0.0")).specHash())
+	assert.NotEqual(t, hash, New(cfg, newSpec("1.0.1")).specHash())
+
+	data := New(cfg, newSpec("1.0.0")).buildServerTemplateData()
+	assert.Equal(t, hash, data["SpecHash"])
+	assert.Equal(t, Version, data["MCPGenVersion"])
+}
+
+func TestGenerateWithDifferentPackages(t *testing.T) {
+	specPath := filepath.Join("testdata", "custom_types.yaml")
+	spec, err := config.LoadMCPSpec(specPath)
+	require.NoError(t, err, "Failed to load spec")
+
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Spec:   specPath,
+		Output: outputDir,
+		Exec: config.ExecConfig{
+			Package:  "server",
+			Filename: "server.go",
+		},
+		Model: config.ModelConfig{
+			Package:  "types",
+			Filename: "types/models.go",
+		},
+		Resolver: config.ResolverConfig{
+			Package:  "mcp_v1",
+			Filename: "resolver.go",
+			Type:     "Resolver",
+			Preserve: false,
+		},
+		Models: config.ModelsConfig{
+			Models: map[string]config.TypeMapping{},
+		},
+	}
+
+	gen := New(cfg, spec)
+
+	// Load schemas
+	if err := gen.loadSchemas(); err != nil {
+		t.Fatalf("loadSchemas() error = %v", err)
+	}
+
+	data := gen.buildServerTemplateData()
+
+	// When model package differs from exec package, should have imports
+	imports, ok := data["Imports"]
+	assert.True(t, ok, "Should have Imports when packages differ")
+
+	if imports != nil {
+		importList, ok := imports.([]map[string]string)
+		require.True(t, ok, "Imports should be []map[string]string")
+		assert.NotEmpty(t, importList, "Imports list should not be empty when packages differ")
+	}
+}
+
+func TestTemplateDataExtensions(t *testing.T) {
+	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "billing", Version: "1.0.0"},
+		Tools: []config.Tool{
+			{
+				Name:        "refund",
+				InputSchema: &config.Schema{Type: "object"},
+				Extensions:  config.Extensions{"x-owner": "payments"},
+			},
+		},
+		Resources: []config.Resource{
+			{Name: "invoices", URI: "billing://invoices", Extensions: config.Extensions{"x-cache-ttl": 300}},
+		},
+		Prompts: []config.Prompt{
+			{Name: "dispute"},
+		},
+	}
+
+	cfg := &config.Config{
+		Model:    config.ModelConfig{Package: "test"},
+		Resolver: config.ResolverConfig{Package: "test", Type: "Resolver"},
+	}
+
+	for _, data := range []map[string]interface{}{
+		New(cfg, spec).buildServerTemplateData(),
+		New(cfg, spec).buildResolverTemplateData(),
+	} {
+		tools := data["Tools"].([]map[string]interface{})
+		assert.Equal(t, config.Extensions{"x-owner": "payments"}, tools[0]["Extensions"])
+		resources := data["Resources"].([]map[string]interface{})
+		assert.Equal(t, config.Extensions{"x-cache-ttl": 300}, resources[0]["Extensions"])
+		prompts := data["Prompts"].([]map[string]interface{})
+		assert.Nil(t, prompts[0]["Extensions"])
+	}
+}
+
+func TestCountOrphanedHandlers(t *testing.T) {
+	tests := []struct {
+		name   string
+		code   string
+		want   int
+	}{
+		{
+			name: "no orphaned handlers",
+			code: "func (r *Resolver) Handler() {}",
+			want: 0,
+		},
+		{
+			name: "one orphaned handler",
+			code: "// Orphaned: OldHandler\nfunc (r *Resolver) OldHandler() {}",
+			want: 1,
+		},
+		{
+			name: "multiple orphaned handlers",
+			code: "// Orphaned: Handler1\nfunc (r *Resolver) Handler1() {}\n// Orphaned: Handler2\nfunc (r *Resolver) Handler2() {}",
+			want: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := countOrphanedHandlers(tt.code)
+			if got != tt.want {
+				t.Errorf("countOrphanedHandlers() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateNewHandlersCode(t *testing.T) {
+	specPath := filepath.Join("testdata", "config_based_types.yaml")
+	spec, err := config.LoadMCPSpec(specPath)
+	require.NoError(t, err, "Failed to load spec")
+
+	cfg := &config.Config{
+		Spec:   specPath,
+		Output: t.TempDir(),
+		Model: config.ModelConfig{
+			Package:  "test",
+			Filename: "models.go",
+		},
+		Resolver: config.ResolverConfig{
+			Package:  "test",
+			Filename: "resolver.go",
+			Type:     "Resolver",
+			Preserve: false,
+		},
+		Models: config.ModelsConfig{
+			Models: map[string]config.TypeMapping{},
+		},
+	}
+
+	gen := New(cfg, spec)
+	if err := gen.loadSchemas(); err != nil {
+		t.Fatalf("Failed to load schemas: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		handlerNames []string
+		wantContains []string
+		isEmpty      bool
+	}{
+		{
+			name:         "empty handler list",
+			handlerNames: []string{},
+			isEmpty:      true,
+		},
+		{
+			name:         "single handler",
+			handlerNames: []string{"CreateEventTool"},
+			wantContains: []string{"CreateEventTool", "func (r *Resolver) CreateEventTool"},
+			isEmpty:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := gen.generateNewHandlersCode(tt.handlerNames)
+			if err != nil {
+				t.Errorf("generateNewHandlersCode() error = %v", err)
+				return
+			}
+
+			if tt.isEmpty {
+				if code != "" {
+					t.Errorf("Expected empty code, got: %q", code)
+				}
+				return
+			}
+
+			for _, want := range tt.wantContains {
+				if !containsString(code, want) {
+					t.Errorf("Generated code should contain %q\nGot: %s", want, code)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveAllRefs(t *testing.T) {
+	spec := &config.MCPSpec{
+		Components: config.Components{
+			Schemas: map[string]*config.Schema{
+				"SimpleType": {
+					Type:   "string",
+					Format: "uuid",
+				},
+				"ComplexType": {
+					Type: "object",
+					Properties: map[string]*config.Schema{
+						"id": {
+							Ref: "#/components/schemas/SimpleType",
+						},
+						"name": {
+							Type: "string",
+						},
+					},
+				},
+				"WithExtensions": {
+					Type:   "string",
+					Format: "date-time",
+					Extra: map[string]interface{}{
+						"go.probo.inc/mcpgen/type":      "time.Time",
+						"x-custom-field": "value",
+					},
+				},
+				"NestedRef": {
+					Type: "object",
+					Properties: map[string]*config.Schema{
+						"complex": {
+							Ref: "#/components/schemas/ComplexType",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	cfg := &config.Config{}
+	gen := New(cfg, spec)
+
+	tests := []struct {
+		name           string
+		schema         *config.Schema
+		wantType       string
+		wantNoRef      bool
+		wantNoExtras   bool
+		checkProperty  string
+		propertyType   string
+	}{
+		{
+			name:         "nil schema",
+			schema:       nil,
+			wantType:     "",
+			wantNoRef:    true,
+		},
+		{
+			name: "simple ref resolution",
+			schema: &config.Schema{
+				Ref: "#/components/schemas/SimpleType",
+			},
+			wantType:  "string",
+			wantNoRef: true,
+		},
+		{
+			name: "nested ref in properties",
+			schema: &config.Schema{
+				Ref: "#/components/schemas/ComplexType",
+			},
+			wantType:      "object",
+			wantNoRef:     true,
+			checkProperty: "id",
+			propertyType:  "string",
+		},
+		{
+			name: "deep nested refs",
+			schema: &config.Schema{
+				Ref: "#/components/schemas/NestedRef",
+			},
+			wantType:      "object",
+			wantNoRef:     true,
+			checkProperty: "complex",
+			propertyType:  "object",
+		},
+		{
+			name: "ref in array items",
+			schema: &config.Schema{
+				Type: "array",
+				Items: &config.Schema{
+					Ref: "#/components/schemas/SimpleType",
+				},
+			},
+			wantType:  "array",
+			wantNoRef: true,
+		},
+		{
+			name: "ref in anyOf",
+			schema: &config.Schema{
+				AnyOf: []*config.Schema{
+					{
+						Ref: "#/components/schemas/SimpleType",
+					},
+					{
+						Type: "null",
+					},
+				},
+			},
+			wantNoRef: true,
+		},
+		{
+			name: "removes x-* extensions",
+			schema: &config.Schema{
+				Ref: "#/components/schemas/WithExtensions",
+			},
+			wantType:     "string",
+			wantNoRef:    true,
+			wantNoExtras: true,
+		},
+		{
+			name: "no ref to resolve",
+			schema: &config.Schema{
+				Type: "string",
+			},
+			wantType:  "string",
+			wantNoRef: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := gen.resolveAllRefs(tt.schema)
+			if err != nil {
+				t.Fatalf("resolveAllRefs() error = %v", err)
+			}
+
+			if tt.schema == nil {
+				if got != nil {
+					t.Errorf("resolveAllRefs(nil) should return nil")
+				}
+				return
+			}
+
+			if tt.wantType != "" && got.Type != tt.wantType {
+				t.Errorf("Type = %q, want %q", got.Type, tt.wantType)
+			}
+
+			if tt.wantNoRef && got.Ref != "" {
+				t.Errorf("Ref should be empty, got %q", got.Ref)
+			}
+
+			if tt.wantNoExtras && got.Extra != nil && len(got.Extra) > 0 {
+				t.Errorf("Extra fields should be removed, got %v", got.Extra)
+			}
+
+			if tt.checkProperty != "" {
+				if got.Properties == nil {
+					t.Error("Properties should not be nil")
+					return
+				}
+				prop, ok := got.Properties[tt.checkProperty]
+				if !ok {
+					t.Errorf("Property %q should exist", tt.checkProperty)
+					return
+				}
+				if prop.Type != tt.propertyType {
+					t.Errorf("Property %q type = %q, want %q", tt.checkProperty, prop.Type, tt.propertyType)
+				}
+				if prop.Ref != "" {
+					t.Errorf("Property %q should have ref resolved, got %q", tt.checkProperty, prop.Ref)
+				}
+			}
+
+			if tt.schema.Type == "array" && got.Items != nil {
+				if got.Items.Ref != "" {
+					t.Errorf("Array items should have ref resolved, got %q", got.Items.Ref)
+				}
+			}
+
+			if len(tt.schema.AnyOf) > 0 {
+				for i, schema := range got.AnyOf {
+					if schema.Ref != "" {
+						t.Errorf("anyOf[%d] should have ref resolved, got %q", i, schema.Ref)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestResolveAllRefsInAllOf(t *testing.T) {
+	spec := &config.MCPSpec{
+		Components: config.Components{
+			Schemas: map[string]*config.Schema{
+				"Base": {
+					Type: "object",
+					Properties: map[string]*config.Schema{
+						"id": {Type: "string"},
+					},
+				},
+				"Extended": {
+					Type: "object",
+					Properties: map[string]*config.Schema{
+						"name": {Type: "string"},
+					},
+				},
+			},
+		},
+	}
+
+	cfg := &config.Config{}
+	gen := New(cfg, spec)
+
+	schema := &config.Schema{
+		AllOf: []*config.Schema{
+			{Ref: "#/components/schemas/Base"},
+			{Ref: "#/components/schemas/Extended"},
+		},
+	}
+
+	got, err := gen.resolveAllRefs(schema)
+	if err != nil {
+		t.Fatalf("resolveAllRefs() error = %v", err)
+	}
+
+	if len(got.AllOf) != 2 {
+		t.Errorf("AllOf length = %d, want 2", len(got.AllOf))
+	}
+
+	for i, s := range got.AllOf {
+		if s.Ref != "" {
+			t.Errorf("allOf[%d] should have ref resolved, got %q", i, s.Ref)
+		}
+		if s.Type != "object" {
+			t.Errorf("allOf[%d] type = %q, want object", i, s.Type)
+		}
+	}
+}
+
+func TestResolveAllRefsInOneOf(t *testing.T) {
+	spec := &config.MCPSpec{
+		Components: config.Components{
+			Schemas: map[string]*config.Schema{
+				"Option1": {Type: "string"},
+				"Option2": {Type: "number"},
+			},
+		},
+	}
+
+	cfg := &config.Config{}
+	gen := New(cfg, spec)
+
+	schema := &config.Schema{
+		OneOf: []*config.Schema{
+			{Ref: "#/components/schemas/Option1"},
+			{Ref: "#/components/schemas/Option2"},
+		},
+	}
+
+	got, err := gen.resolveAllRefs(schema)
+	if err != nil {
+		t.Fatalf("resolveAllRefs() error = %v", err)
+	}
+
+	if len(got.OneOf) != 2 {
+		t.Errorf("OneOf length = %d, want 2", len(got.OneOf))
+	}
+
+	for i, s := range got.OneOf {
+		if s.Ref != "" {
+			t.Errorf("oneOf[%d] should have ref resolved, got %q", i, s.Ref)
+		}
+	}
+}
+
+func TestResolveAllRefsInAdditionalProperties(t *testing.T) {
+	spec := &config.MCPSpec{
+		Components: config.Components{
+			Schemas: map[string]*config.Schema{
+				"Value": {Type: "string"},
+			},
+		},
+	}
+
+	cfg := &config.Config{}
+	gen := New(cfg, spec)
+
+	schema := &config.Schema{
+		Type: "object",
+		AdditionalProperties: &config.Schema{
+			Ref: "#/components/schemas/Value",
+		},
+	}
+
+	got, err := gen.resolveAllRefs(schema)
+	if err != nil {
+		t.Fatalf("resolveAllRefs() error = %v", err)
+	}
+
+	if got.AdditionalProperties == nil {
+		t.Fatal("AdditionalProperties should not be nil")
+	}
+
+	if got.AdditionalProperties.Ref != "" {
+		t.Errorf("AdditionalProperties should have ref resolved, got %q", got.AdditionalProperties.Ref)
+	}
+
+	if got.AdditionalProperties.Type != "string" {
+		t.Errorf("AdditionalProperties type = %q, want string", got.AdditionalProperties.Type)
+	}
+}
+
+func TestResolveAllRefsInPatternProperties(t *testing.T) {
+	spec := &config.MCPSpec{
+		Components: config.Components{
+			Schemas: map[string]*config.Schema{
+				"Pattern": {Type: "number"},
+			},
+		},
+	}
+
+	cfg := &config.Config{}
+	gen := New(cfg, spec)
+
+	schema := &config.Schema{
+		Type: "object",
+		PatternProperties: map[string]*config.Schema{
+			"^[a-z]+$": {
+				Ref: "#/components/schemas/Pattern",
+			},
+		},
+	}
+
+	got, err := gen.resolveAllRefs(schema)
+	if err != nil {
+		t.Fatalf("resolveAllRefs() error = %v", err)
+	}
+
+	if got.PatternProperties == nil {
+		t.Fatal("PatternProperties should not be nil")
+	}
+
+	pattern, ok := got.PatternProperties["^[a-z]+$"]
+	if !ok {
+		t.Fatal("Pattern should exist")
+	}
+
+	if pattern.Ref != "" {
+		t.Errorf("Pattern should have ref resolved, got %q", pattern.Ref)
+	}
+
+	if pattern.Type != "number" {
+		t.Errorf("Pattern type = %q, want number", pattern.Type)
+	}
+}
+
+func TestResolveAllRefsError(t *testing.T) {
+	spec := &config.MCPSpec{
+		Components: config.Components{
+			Schemas: map[string]*config.Schema{},
+		},
+	}
+
+	cfg := &config.Config{}
+	gen := New(cfg, spec)
+
+	schema := &config.Schema{
+		Ref: "#/components/schemas/NonExistent",
+	}
+
+	_, err := gen.resolveAllRefs(schema)
+	if err == nil {
+		t.Error("resolveAllRefs() should return error for non-existent ref")
+	}
+}
+
+func TestResolveAllRefsCycle(t *testing.T) {
+	spec := &config.MCPSpec{
+		Components: config.Components{
+			Schemas: map[string]*config.Schema{
+				"Task": {
+					Type: "object",
+					Properties: map[string]*config.Schema{
+						"project": {Ref: "#/components/schemas/Project"},
+					},
+				},
+				"Project": {
+					Type: "object",
+					Properties: map[string]*config.Schema{
+						"tasks": {Type: "array", Items: &config.Schema{Ref: "#/components/schemas/Task"}},
+					},
+				},
+				"Node": {
+					Type: "object",
+					Properties: map[string]*config.Schema{
+						"next": {Ref: "#/components/schemas/Node"},
+					},
+				},
+			},
+		},
+	}
+	gen := New(&config.Config{}, spec)
+
+	_, err := gen.resolveAllRefs(&config.Schema{Ref: "#/components/schemas/Task"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "circular schema reference: Task → Project → Task")
+
+	_, err = gen.resolveAllRefs(&config.Schema{Ref: "#/components/schemas/Node"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "circular schema reference: Node → Node")
+	assert.Empty(t, gen.resolving, "the chain is unwound on errors")
+}
+
+func TestResolveAllRefsRemovesExtensions(t *testing.T) {
+	spec := &config.MCPSpec{
+		Components: config.Components{
+			Schemas: map[string]*config.Schema{
+				"TypeWithExtensions": {
+					Type:   "object",
+					Extra: map[string]interface{}{
+						"go.probo.inc/mcpgen/type": "custom.Type",
+						"x-custom":  "value",
+					},
+					Properties: map[string]*config.Schema{
+						"field": {
+							Type: "string",
+							Extra: map[string]interface{}{
+								"x-validation": "required",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	cfg := &config.Config{}
+	gen := New(cfg, spec)
+
+	schema := &config.Schema{
+		Ref: "#/components/schemas/TypeWithExtensions",
+	}
+
+	got, err := gen.resolveAllRefs(schema)
+	if err != nil {
+		t.Fatalf("resolveAllRefs() error = %v", err)
+	}
+
+	if got.Extra != nil && len(got.Extra) > 0 {
+		t.Errorf("Extra should be removed from resolved schema, got %v", got.Extra)
+	}
+
+	if got.Properties == nil {
+		t.Fatal("Properties should not be nil")
+	}
+
+	field := got.Properties["field"]
+	if field.Extra != nil && len(field.Extra) > 0 {
+		t.Errorf("Extra should be removed from property, got %v", field.Extra)
+	}
+}
+
+func TestUpdateResolverIncremental(t *testing.T) {
+	specPath := filepath.Join("testdata", "config_based_types.yaml")
+	spec, err := config.LoadMCPSpec(specPath)
+	require.NoError(t, err, "Failed to load spec")
+
+	tests := []struct {
+		name             string
+		existingResolver string
+		wantContains     []string
+		wantNotContains  []string
+		expectUpdate     bool
+	}{
+		{
+			name: "add new handler",
+			existingResolver: `package test
+
+import (
+	"context"
+	"fmt"
+	mcp "github.com/mark3labs/mcp-go/mcp"
+)
+
+type Resolver struct{}
+
+type toolResolver struct {
+	*Resolver
+}
+
+type promptResolver struct {
+	*Resolver
+}
+
+type resourceResolver struct {
+	*Resolver
+}
+
+func (r *toolResolver) OldHandler(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, map[string]any, error) {
+	return nil, nil, nil
+}
+`,
+			wantContains: []string{
+				"func (r *Resolver) CreateEventTool",
+				"mcputil.ErrNotImplemented",
+				`mcputil "go.probo.inc/mcpgen/mcp"`,
+				"Orphaned: OldHandler",
+			},
+			expectUpdate: true,
+		},
+		{
+			name: "no changes needed",
+			existingResolver: `package test
+
+import (
+	"context"
+	"fmt"
+	mcp "github.com/mark3labs/mcp-go/mcp"
+)
+
+type Resolver struct{}
+
+type toolResolver struct {
+	*Resolver
+}
+
+type promptResolver struct {
+	*Resolver
+}
+
+type resourceResolver struct {
+	*Resolver
+}
+
+func (r *Resolver) CreateEventTool(ctx context.Context, req *mcp.CallToolRequest, input *Event) (*mcp.CallToolResult, map[string]any, error) {
+	return nil, nil, fmt.Errorf("create-event not implemented")
+}
+`,
+			wantContains: []string{
+				"func (r *Resolver) CreateEventTool",
+			},
+			wantNotContains: []string{
+				"Orphaned Handlers",
+			},
+			expectUpdate: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			resolverFile := filepath.Join(tmpDir, "schema.resolvers.go")
+
+			if err := os.WriteFile(resolverFile, []byte(tt.existingResolver), 0644); err != nil {
+				t.Fatalf("Failed to write resolver file: %v", err)
+			}
+
+			cfg := &config.Config{
+				Spec:   specPath,
+				Output: tmpDir,
+				Model: config.ModelConfig{
+					Package:  "test",
+					Filename: "models.go",
+				},
+				Resolver: config.ResolverConfig{
+					Package:  "test",
+					Filename: "schema.resolvers.go",
+					Type:     "Resolver",
+					Preserve: true,
+				},
+				Models: config.ModelsConfig{
+					Models: map[string]config.TypeMapping{},
+				},
+			}
+
+			gen := New(cfg, spec)
+			if err := gen.loadSchemas(); err != nil {
+				t.Fatalf("Failed to load schemas: %v", err)
+			}
+
+			err := gen.updateResolverIncremental(resolverFile)
+			if err != nil {
+				t.Fatalf("updateResolverIncremental() error = %v", err)
+			}
+
+			content, err := os.ReadFile(resolverFile)
+			if err != nil {
+				t.Fatalf("Failed to read updated resolver: %v", err)
+			}
+
+			contentStr := string(content)
+
+			for _, want := range tt.wantContains {
+				if !containsString(contentStr, want) {
+					t.Errorf("Updated resolver should contain %q\nGot: %s", want, contentStr)
+				}
+			}
+
+			for _, wantNot := range tt.wantNotContains {
+				if containsString(contentStr, wantNot) {
+					t.Errorf("Updated resolver should NOT contain %q", wantNot)
+				}
+			}
+		})
+	}
+}
+
+
+func TestGenerateStrictInputs(t *testing.T) {
+	specPath := filepath.Join("testdata", "config_based_types.yaml")
+	spec, err := config.LoadMCPSpec(specPath)
+	require.NoError(t, err, "Failed to load spec")
+
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Spec:   specPath,
+		Output: outputDir,
+		Exec: config.ExecConfig{
+			Package:  "test",
+			Filename: "server.go",
+		},
+		Model: config.ModelConfig{
+			Package:      "test",
+			Filename:     "models.go",
+			StrictInputs: true,
+		},
+		Resolver: config.ResolverConfig{
+			Package:  "test",
+			Filename: "resolver.go",
+			Type:     "Resolver",
+		},
+	}
+
+	require.NoError(t, New(cfg, spec).Generate(StageModels))
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "models.go"))
+	require.NoError(t, err, "Failed to read models.go")
+	models := string(content)
+
+	assert.Contains(t, models, "func (v *CreateEventInput) UnmarshalJSON(data []byte) error")
+	assert.Contains(t, models, `mcp.NewError(mcp.MessageUnknownField, field, "CreateEventInput")`)
+	assert.Contains(t, models, `"additionalProperties":false`)
+
+	// Only tool inputs are strict
+	assert.NotContains(t, models, "func (v *User) UnmarshalJSON")
+}
+
+func TestGenerateToolsWithoutInput(t *testing.T) {
+	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Tools: []config.Tool{
+			{Name: "ping", NoInput: true},
+			{Name: "status", InputSchema: &config.Schema{}},
+		},
+	}
+	require.NoError(t, spec.Validate())
+
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Output: outputDir,
+		Exec: config.ExecConfig{
+			Package:  "test",
+			Filename: "server.go",
+			Fake: config.FakeConfig{
+				Filename: "testtest/fake.go",
+			},
+		},
+		Model: config.ModelConfig{
+			Package:  "test",
+			Filename: "models.go",
+		},
+		Resolver: config.ResolverConfig{
+			Package:  "test",
+			Filename: "resolver.go",
+			Type:     "Resolver",
+		},
+	}
+	require.NoError(t, New(cfg, spec).Generate(StageModels, StageServer, StageResolver, StageFake))
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "models.go"))
+	require.NoError(t, err, "Failed to read models.go")
+	assert.NotContains(t, string(content), "PingInput")
+	assert.NotContains(t, string(content), "StatusToolInputSchema")
+
+	content, err = os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	server := string(content)
+	assert.Contains(t, server, "PingTool(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, map[string]any, error)")
+	assert.Contains(t, server, "StatusTool(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, map[string]any, error)")
+	assert.Contains(t, server, "mcputil.AddToolWithoutInput(")
+	assert.NotContains(t, server, "InputSchema:")
+
+	content, err = os.ReadFile(filepath.Join(outputDir, "schema.resolvers.go"))
+	require.NoError(t, err, "Failed to read schema.resolvers.go")
+	assert.Contains(t, string(content), "func (r *Resolver) PingTool(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, map[string]any, error) {")
+
+	content, err = os.ReadFile(filepath.Join(outputDir, "testtest", "fake.go"))
+	require.NoError(t, err, "Failed to read fake.go")
+	assert.Contains(t, string(content), `r.f.record("tools/call", "ping", nil)`)
+	assert.Contains(t, string(content), "return r.f.PingTool(ctx, req)")
+}
+
+func TestGenerateTitles(t *testing.T) {
+	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Tools: []config.Tool{
+			{Name: "list_projects", Title: "List projects", Icon: "https://example.com/projects.svg", NoInput: true},
+			{Name: "ping", NoInput: true},
+		},
+		Resources: []config.Resource{
+			{Name: "readme", Title: "Project README", URI: "docs://readme"},
+		},
+		Prompts: []config.Prompt{
+			{Name: "review", Title: "Review code"},
+		},
+	}
+
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Output: outputDir,
+		Exec: config.ExecConfig{
+			Package:  "test",
+			Filename: "server.go",
+		},
+		Model: config.ModelConfig{
+			Package:  "test",
+			Filename: "models.go",
+		},
+		Resolver: config.ResolverConfig{
+			Package:  "test",
+			Filename: "resolver.go",
+			Type:     "Resolver",
+		},
+	}
+	require.NoError(t, New(cfg, spec).Generate(StageServer))
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	server := string(content)
+	assert.Regexp(t, "Name: +\"list_projects\",\n\t+Title: +\"List projects\",", server)
+	assert.Regexp(t, "Name: +\"readme\",\n\t+Title: +\"Project README\",", server)
+	assert.Regexp(t, "Name: +\"review\",\n\t+Title: +\"Review code\",", server)
+	assert.NotRegexp(t, "Name: +\"ping\",\n\t+Title:", server)
+}
+
+func TestGenerateCapabilityRequirements(t *testing.T) {
+	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Tools: []config.Tool{
+			{Name: "summarize", NoInput: true, RequiresClientCapability: []string{"sampling"}},
+			{Name: "ping", NoInput: true},
+		},
+		Resources: []config.Resource{
+			{Name: "draft", URITemplate: "drafts://{id}", RequiresClientCapability: []string{"experimental.drafts"}},
+		},
+	}
+
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Output: outputDir,
+		Exec: config.ExecConfig{
+			Package:  "test",
+			Filename: "server.go",
+		},
+		Model: config.ModelConfig{
+			Package:  "test",
+			Filename: "models.go",
+		},
+		Resolver: config.ResolverConfig{
+			Package:  "test",
+			Filename: "resolver.go",
+			Type:     "Resolver",
 		},
 	}
+	require.NoError(t, New(cfg, spec).Generate(StageServer))
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			got, err := gen.resolveAllRefs(tt.schema)
-			if err != nil {
-				t.Fatalf("resolveAllRefs() error = %v", err)
-			}
-
-			if tt.schema == nil {
-				if got != nil {
-					t.Errorf("resolveAllRefs(nil) should return nil")
-				}
-				return
-			}
-
-			if tt.wantType != "" && got.Type != tt.wantType {
-				t.Errorf("Type = %q, want %q", got.Type, tt.wantType)
-			}
-
-			if tt.wantNoRef && got.Ref != "" {
-				t.Errorf("Ref should be empty, got %q", got.Ref)
-			}
-
-			if tt.wantNoExtras && got.Extra != nil && len(got.Extra) > 0 {
-				t.Errorf("Extra fields should be removed, got %v", got.Extra)
-			}
-
-			if tt.checkProperty != "" {
-				if got.Properties == nil {
-					t.Error("Properties should not be nil")
-					return
-				}
-				prop, ok := got.Properties[tt.checkProperty]
-				if !ok {
-					t.Errorf("Property %q should exist", tt.checkProperty)
-					return
-				}
-				if prop.Type != tt.propertyType {
-					t.Errorf("Property %q type = %q, want %q", tt.checkProperty, prop.Type, tt.propertyType)
-				}
-				if prop.Ref != "" {
-					t.Errorf("Property %q should have ref resolved, got %q", tt.checkProperty, prop.Ref)
-				}
-			}
+	content, err := os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	server := string(content)
+	assert.Contains(t, server, "server.AddReceivingMiddleware(mcputil.CapabilityMiddleware(capabilityRequirements))")
+	assert.Contains(t, server, "\tTools: map[string][]string{\n\t\t\"summarize\": {\"sampling\"},\n\t},")
+	assert.Contains(t, server, "\tResources: map[string][]string{\n\t\t\"drafts://{id}\": {\"experimental.drafts\"},\n\t},")
+	assert.NotContains(t, server, "Prompts: map[string][]string")
 
-			if tt.schema.Type == "array" && got.Items != nil {
-				if got.Items.Ref != "" {
-					t.Errorf("Array items should have ref resolved, got %q", got.Items.Ref)
-				}
-			}
+	spec.Tools[0].RequiresClientCapability = nil
+	spec.Resources[0].RequiresClientCapability = nil
+	require.NoError(t, New(cfg, spec).Generate(StageServer))
 
-			if len(tt.schema.AnyOf) > 0 {
-				for i, schema := range got.AnyOf {
-					if schema.Ref != "" {
-						t.Errorf("anyOf[%d] should have ref resolved, got %q", i, schema.Ref)
-					}
-				}
-			}
-		})
-	}
+	content, err = os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	assert.NotContains(t, string(content), "capabilityRequirements")
 }
 
-func TestResolveAllRefsInAllOf(t *testing.T) {
+func TestGenerateExperiments(t *testing.T) {
 	spec := &config.MCPSpec{
-		Components: config.Components{
-			Schemas: map[string]*config.Schema{
-				"Base": {
-					Type: "object",
-					Properties: map[string]*config.Schema{
-						"id": {Type: "string"},
-					},
-				},
-				"Extended": {
-					Type: "object",
-					Properties: map[string]*config.Schema{
-						"name": {Type: "string"},
-					},
-				},
-			},
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Tools: []config.Tool{
+			{Name: "export", NoInput: true, Experiment: "bulk_export"},
+			{Name: "ping", NoInput: true},
+		},
+		Experiments: []config.Experiment{
+			{Name: "bulk_export", Description: "Export many records at once."},
+			{Name: "drafts"},
 		},
 	}
 
-	cfg := &config.Config{}
-	gen := New(cfg, spec)
-
-	schema := &config.Schema{
-		AllOf: []*config.Schema{
-			{Ref: "#/components/schemas/Base"},
-			{Ref: "#/components/schemas/Extended"},
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Output: outputDir,
+		Exec: config.ExecConfig{
+			Package:  "test",
+			Filename: "server.go",
+		},
+		Model: config.ModelConfig{
+			Package:  "test",
+			Filename: "models.go",
+		},
+		Resolver: config.ResolverConfig{
+			Package:  "test",
+			Filename: "resolver.go",
+			Type:     "Resolver",
 		},
 	}
+	require.NoError(t, New(cfg, spec).Generate(StageServer))
 
-	got, err := gen.resolveAllRefs(schema)
-	if err != nil {
-		t.Fatalf("resolveAllRefs() error = %v", err)
-	}
+	content, err := os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	server := string(content)
+	assert.Contains(t, server, "type Flags struct {\n\t// Export many records at once.\n\tBulkExport bool\n\tDrafts     bool\n}")
+	assert.Regexp(t, `"bulk_export": +flags.BulkExport,`, server)
+	assert.Contains(t, server, "\tif opts.ExperimentEnabled(\"bulk_export\") {\n\t\tmcputil.AddToolWithoutInput(")
+	assert.Regexp(t, `\n\tmcputil\.AddToolWithoutInput\(\n\t\tserver,\n\t\t&mcp\.Tool\{\n\t\t\tName: +"ping"`, server, "ungated tools are always registered")
 
-	if len(got.AllOf) != 2 {
-		t.Errorf("AllOf length = %d, want 2", len(got.AllOf))
-	}
+	spec.Experiments = nil
+	spec.Tools[0].Experiment = ""
+	require.NoError(t, New(cfg, spec).Generate(StageServer))
 
-	for i, s := range got.AllOf {
-		if s.Ref != "" {
-			t.Errorf("allOf[%d] should have ref resolved, got %q", i, s.Ref)
-		}
-		if s.Type != "object" {
-			t.Errorf("allOf[%d] type = %q, want object", i, s.Type)
-		}
-	}
+	content, err = os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	assert.NotContains(t, string(content), "Flags")
 }
 
-func TestResolveAllRefsInOneOf(t *testing.T) {
+func TestGenerateSanitizedText(t *testing.T) {
 	spec := &config.MCPSpec{
-		Components: config.Components{
-			Schemas: map[string]*config.Schema{
-				"Option1": {Type: "string"},
-				"Option2": {Type: "number"},
-			},
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Tools: []config.Tool{
+			{Name: "search", Title: "Search \"all\"", Description: "Search the index.\nUse \\ to escape\x1b[1m, caf\xe9.", NoInput: true},
+		},
+		Prompts: []config.Prompt{
+			{Name: "review", Arguments: []config.PromptArgument{{Name: "code", Description: "The \"code\"\x00"}}},
 		},
 	}
 
-	cfg := &config.Config{}
-	gen := New(cfg, spec)
-
-	schema := &config.Schema{
-		OneOf: []*config.Schema{
-			{Ref: "#/components/schemas/Option1"},
-			{Ref: "#/components/schemas/Option2"},
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Output: outputDir,
+		Exec: config.ExecConfig{
+			Package:         "test",
+			Filename:        "server.go",
+			SanitizeResults: true,
+		},
+		Model: config.ModelConfig{
+			Package:  "test",
+			Filename: "models.go",
+		},
+		Resolver: config.ResolverConfig{
+			Package:  "test",
+			Filename: "resolver.go",
+			Type:     "Resolver",
 		},
 	}
+	require.NoError(t, New(cfg, spec).Generate(StageServer))
 
-	got, err := gen.resolveAllRefs(schema)
-	if err != nil {
-		t.Fatalf("resolveAllRefs() error = %v", err)
-	}
+	content, err := os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	server := string(content)
+	assert.Regexp(t, `Title: +"Search \\"all\\"",`, server)
+	assert.Contains(t, server, `Description: "Search the index.\nUse \\ to escape[1m, caf�.",`)
+	assert.Regexp(t, `Description: +"The \\"code\\"",`, server)
+	assert.Regexp(t, `(?s)mcputil\.ErrorMiddleware\(\)\).*server\.AddReceivingMiddleware\(mcputil\.SanitizeMiddleware\(\)\)`, server, "sanitizing wraps the error results")
+}
 
-	if len(got.OneOf) != 2 {
-		t.Errorf("OneOf length = %d, want 2", len(got.OneOf))
+func TestGenerateDeprecatedTool(t *testing.T) {
+	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Tools: []config.Tool{
+			{Name: "legacy_search", NoInput: true, Deprecated: true},
+			{Name: "ping", NoInput: true},
+		},
 	}
 
-	for i, s := range got.OneOf {
-		if s.Ref != "" {
-			t.Errorf("oneOf[%d] should have ref resolved, got %q", i, s.Ref)
-		}
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Output: outputDir,
+		Exec: config.ExecConfig{
+			Package:  "test",
+			Filename: "server.go",
+		},
+		Model: config.ModelConfig{
+			Package:  "test",
+			Filename: "models.go",
+		},
+		Resolver: config.ResolverConfig{
+			Package:  "test",
+			Filename: "resolver.go",
+			Type:     "Resolver",
+		},
 	}
+	require.NoError(t, New(cfg, spec).Generate(StageServer))
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	server := string(content)
+	assert.Contains(t, server, "\t// Deprecated: the legacy_search tool is deprecated in the spec.\n\tLegacySearchTool(")
+	assert.Regexp(t, `Name: +"legacy_search",\n(\t+.*\n)*?\t+Meta: +mcp\.Meta\{"deprecated": true\},`, server)
+	assert.NotRegexp(t, `Name: +"ping",\n(\t+.*\n)*?\t+Meta:`, server, "other tools carry no metadata")
 }
 
-func TestResolveAllRefsInAdditionalProperties(t *testing.T) {
+func TestDeprecationWarnings(t *testing.T) {
 	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
 		Components: config.Components{
 			Schemas: map[string]*config.Schema{
-				"Value": {Type: "string"},
+				"Task": {Type: "object", Deprecated: true},
+				"Old":  {Type: "object", Deprecated: true},
+				"Search": {
+					Type: "object",
+					Properties: map[string]*config.Schema{
+						"q":    {Type: "string", Deprecated: true},
+						"task": {Ref: "#/components/schemas/Task"},
+					},
+				},
 			},
 		},
-	}
-
-	cfg := &config.Config{}
-	gen := New(cfg, spec)
-
-	schema := &config.Schema{
-		Type: "object",
-		AdditionalProperties: &config.Schema{
-			Ref: "#/components/schemas/Value",
+		Tools: []config.Tool{
+			{Name: "search", InputSchema: &config.Schema{Ref: "#/components/schemas/Search"}},
+			{Name: "get_task", NoInput: true, OutputSchema: &config.Schema{Ref: "#/components/schemas/Task"}},
+			{Name: "legacy", Deprecated: true, InputSchema: &config.Schema{Ref: "#/components/schemas/Old"}},
 		},
 	}
 
-	got, err := gen.resolveAllRefs(schema)
-	if err != nil {
-		t.Fatalf("resolveAllRefs() error = %v", err)
-	}
+	assert.Equal(t, []string{
+		"tools.legacy is deprecated and still served",
+		"components.schemas.Search.properties.q is deprecated and still declared",
+		"components.schemas.Task is deprecated and still referenced by tools.get_task.outputSchema, components.schemas.Search.properties.task",
+	}, New(&config.Config{}, spec).deprecationWarnings())
+}
 
-	if got.AdditionalProperties == nil {
-		t.Fatal("AdditionalProperties should not be nil")
+func TestGenerateResourceEncoding(t *testing.T) {
+	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Resources: []config.Resource{
+			{
+				Name:     "file",
+				URI:      "files://current",
+				Encoding: "cbor",
+				Schema:   &config.Schema{Type: "object", Properties: map[string]*config.Schema{"data": {Type: "string"}}},
+			},
+			{Name: "thumbnail", URITemplate: "thumbnails://{id}", MimeType: "application/msgpack", Encoding: "msgpack"},
+			{Name: "readme", URI: "docs://readme"},
+		},
 	}
 
-	if got.AdditionalProperties.Ref != "" {
-		t.Errorf("AdditionalProperties should have ref resolved, got %q", got.AdditionalProperties.Ref)
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Output: outputDir,
+		Exec: config.ExecConfig{
+			Package:  "test",
+			Filename: "server.go",
+		},
+		Model: config.ModelConfig{
+			Package:  "test",
+			Filename: "models.go",
+		},
+		Resolver: config.ResolverConfig{
+			Package:  "test",
+			Filename: "resolver.go",
+			Type:     "Resolver",
+		},
 	}
+	require.NoError(t, New(cfg, spec).Generate(StageServer))
 
-	if got.AdditionalProperties.Type != "string" {
-		t.Errorf("AdditionalProperties type = %q, want string", got.AdditionalProperties.Type)
-	}
+	content, err := os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	server := string(content)
+	assert.Regexp(t, `MIMEType: +mcputil\.SerializerMIMEType\("cbor"\),`, server)
+	assert.Regexp(t, `MIMEType: +"application/msgpack",`, server)
+	assert.Contains(t, server, "func EncodeFileResource(uri string, v FileContent) (*mcp.ReadResourceResult, error) {\n\tcontents, err := mcputil.EncodeResource(uri, \"cbor\", v)")
+	assert.Contains(t, server, "func EncodeThumbnailResource(uri string, v any) (*mcp.ReadResourceResult, error) {")
+	assert.NotContains(t, server, "EncodeReadmeResource")
 }
 
-func TestResolveAllRefsInPatternProperties(t *testing.T) {
+func TestGenerateReplacedTool(t *testing.T) {
 	spec := &config.MCPSpec{
-		Components: config.Components{
-			Schemas: map[string]*config.Schema{
-				"Pattern": {Type: "number"},
-			},
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Tools: []config.Tool{
+			{Name: "search", NoInput: true, Deprecated: true, ReplacedBy: "search_v2"},
+			{Name: "search_v2", NoInput: true},
 		},
 	}
 
-	cfg := &config.Config{}
-	gen := New(cfg, spec)
-
-	schema := &config.Schema{
-		Type: "object",
-		PatternProperties: map[string]*config.Schema{
-			"^[a-z]+$": {
-				Ref: "#/components/schemas/Pattern",
-			},
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Output: outputDir,
+		Exec: config.ExecConfig{
+			Package:  "test",
+			Filename: "server.go",
+		},
+		Model: config.ModelConfig{
+			Package:  "test",
+			Filename: "models.go",
+		},
+		Resolver: config.ResolverConfig{
+			Package:  "test",
+			Filename: "resolver.go",
+			Type:     "Resolver",
 		},
 	}
+	g := New(cfg, spec)
+	require.NoError(t, g.Generate(StageServer))
+	assert.Equal(t, []string{"tools.search is deprecated in favor of search_v2 and still served"}, g.deprecationWarnings())
 
-	got, err := gen.resolveAllRefs(schema)
-	if err != nil {
-		t.Fatalf("resolveAllRefs() error = %v", err)
-	}
+	content, err := os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	server := string(content)
+	assert.Contains(t, server, "\t// Deprecated: the search tool is deprecated in the spec, use search_v2 instead.\n\tSearchTool(")
+	assert.Regexp(t, `Meta: +mcp\.Meta\{"deprecated": true, "replacedBy": "search_v2"\},`, server)
+	assert.Contains(t, server, "server.AddReceivingMiddleware(mcputil.DeprecationMiddleware(ToolReplacements(), o.DeprecatedCalls))")
+	assert.Contains(t, server, "func ToolReplacements() map[string]string {\n\treturn map[string]string{\n\t\t\"search\": \"search_v2\",\n\t}\n}")
+
+	spec.Tools[0].ReplacedBy = ""
+	require.NoError(t, New(cfg, spec).Generate(StageServer))
+	content, err = os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	assert.NotContains(t, string(content), "ToolReplacements")
+}
 
-	if got.PatternProperties == nil {
-		t.Fatal("PatternProperties should not be nil")
+func TestGenerateDevFixtures(t *testing.T) {
+	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Tools: []config.Tool{
+			{Name: "get_task", NoInput: true, DevFixture: map[string]any{"title": "Write docs", "id": "t1"}},
+			{Name: "delete_task", NoInput: true},
+		},
+		Resources: []config.Resource{
+			{Name: "task", URITemplate: "tasks://{id}", DevFixture: map[string]any{"id": "t1"}},
+			{Name: "readme", URI: "docs://readme", MimeType: "text/markdown", DevFixture: "# Tasks"},
+			{Name: "changelog", URI: "docs://changelog"},
+		},
 	}
 
-	pattern, ok := got.PatternProperties["^[a-z]+$"]
-	if !ok {
-		t.Fatal("Pattern should exist")
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Output: outputDir,
+		Exec: config.ExecConfig{
+			Package:  "test",
+			Filename: "server.go",
+		},
+		Model: config.ModelConfig{
+			Package:  "test",
+			Filename: "models.go",
+		},
+		Resolver: config.ResolverConfig{
+			Package:  "test",
+			Filename: "resolver.go",
+			Type:     "Resolver",
+		},
 	}
+	require.NoError(t, New(cfg, spec).Generate(StageServer, StageResolver))
 
-	if pattern.Ref != "" {
-		t.Errorf("Pattern should have ref resolved, got %q", pattern.Ref)
-	}
+	content, err := os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	server := string(content)
+	assert.Contains(t, server, "\tif o.DevFixtures {\n\t\t// Answer the calls to unimplemented tools with their devFixture\n\t\to.NotImplementedFunc = mcputil.FixtureNotImplementedFunc(toolFixtures, o.NotImplementedFunc)\n\t}")
+	assert.Contains(t, server, "var toolFixtures = map[string]string{\n\t\"get_task\": \"{\\\"id\\\":\\\"t1\\\",\\\"title\\\":\\\"Write docs\\\"}\",\n}")
+	assert.Contains(t, server, "mcputil.FixtureResourceHandler(resolver.TaskResource, \"{\\\"id\\\":\\\"t1\\\"}\", \"\", opts),")
+	assert.Contains(t, server, "mcputil.FixtureResourceHandler(resolver.ReadmeResource, \"\\\"# Tasks\\\"\", \"text/markdown\", opts),")
+	assert.Contains(t, server, "\t\tresolver.ChangelogResource,\n")
+	assert.Contains(t, server, "if cfg.DevFixtures {\n\t\topts = append(opts, mcputil.WithDevFixtures(true))\n\t}")
+
+	content, err = os.ReadFile(filepath.Join(outputDir, "schema.resolvers.go"))
+	require.NoError(t, err, "Failed to read schema.resolvers.go")
+	assert.Contains(t, string(content), "return nil, fmt.Errorf(\"task: %w\", mcputil.ErrNotImplemented)")
 
-	if pattern.Type != "number" {
-		t.Errorf("Pattern type = %q, want number", pattern.Type)
-	}
+	spec.Tools[0].DevFixture = nil
+	require.NoError(t, New(cfg, spec).Generate(StageServer))
+	content, err = os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	assert.NotContains(t, string(content), "toolFixtures")
 }
 
-func TestResolveAllRefsError(t *testing.T) {
-	spec := &config.MCPSpec{
-		Components: config.Components{
-			Schemas: map[string]*config.Schema{},
-		},
-	}
+func TestGenerateOpenAPINullable(t *testing.T) {
+	dir := t.TempDir()
+	specPath := filepath.Join(dir, "mcp.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(`info:
+  title: test
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+      properties:
+        name:
+          type: string
+    Task:
+      type: object
+      required: [title, status, owner]
+      properties:
+        title:
+          type: string
+          nullable: true
+        status:
+          type: string
+          enum: [open, done]
+          nullable: true
+        owner:
+          $ref: '#/components/schemas/User'
+          nullable: true
+        note:
+          type: string
+          nullable: true
+          go.probo.inc/mcpgen/omittable: true
+tools:
+  - name: get_task
+    noInput: true
+    outputSchema:
+      $ref: '#/components/schemas/Task'
+`), 0644))
 
-	cfg := &config.Config{}
-	gen := New(cfg, spec)
+	spec, err := config.LoadMCPSpec(specPath)
+	require.NoError(t, err, "Failed to load spec")
 
-	schema := &config.Schema{
-		Ref: "#/components/schemas/NonExistent",
+	cfg := &config.Config{
+		Spec:   specPath,
+		Output: filepath.Join(dir, "generated"),
+		Model: config.ModelConfig{
+			Package:  "test",
+			Filename: "models.go",
+		},
 	}
+	require.NoError(t, New(cfg, spec).Generate(StageModels))
 
-	_, err := gen.resolveAllRefs(schema)
-	if err == nil {
-		t.Error("resolveAllRefs() should return error for non-existent ref")
-	}
+	content, err := os.ReadFile(filepath.Join(dir, "generated", "models.go"))
+	require.NoError(t, err, "Failed to read models.go")
+	models := string(content)
+	assert.Regexp(t, `Title +\*string +`+"`json:\"title\"`", models)
+	assert.Regexp(t, `Status +\*TaskStatus +`+"`json:\"status\"`", models)
+	assert.Regexp(t, `Owner +\*User +`+"`json:\"owner\"`", models)
+	assert.Regexp(t, `Note +mcp\.Omittable\[\*string\]`, models)
+	assert.Contains(t, models, "TaskStatusOpen TaskStatus = \"open\"")
 }
 
-func TestResolveAllRefsRemovesExtensions(t *testing.T) {
+func TestGenerateFormatMappings(t *testing.T) {
 	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
 		Components: config.Components{
 			Schemas: map[string]*config.Schema{
-				"TypeWithExtensions": {
-					Type:   "object",
-					Extra: map[string]interface{}{
-						"go.probo.inc/mcpgen/type": "custom.Type",
-						"x-custom":  "value",
-					},
+				"TaskID": {Type: "string", Format: "uuid"},
+				"Task": {
+					Type:     "object",
+					Required: []string{"id", "due"},
 					Properties: map[string]*config.Schema{
-						"field": {
-							Type: "string",
-							Extra: map[string]interface{}{
-								"x-validation": "required",
-							},
-						},
+						"id":      {Ref: "#/components/schemas/TaskID"},
+						"due":     {Type: "string", Format: "date"},
+						"created": {Type: "string", Format: "date-time"},
+						"owner":   {Type: "string", Format: "uuid"},
+						"kind":    {Type: "string", Format: "uuid", Enum: []any{"a"}},
 					},
 				},
 			},
 		},
+		Tools: []config.Tool{
+			{Name: "get_task", NoInput: true, OutputSchema: &config.Schema{Ref: "#/components/schemas/Task"}},
+		},
 	}
 
-	cfg := &config.Config{}
-	gen := New(cfg, spec)
-
-	schema := &config.Schema{
-		Ref: "#/components/schemas/TypeWithExtensions",
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Output: outputDir,
+		Model: config.ModelConfig{
+			Package:  "test",
+			Filename: "models.go",
+		},
+		Formats: map[string]string{
+			"uuid":      "github.com/google/uuid.UUID",
+			"date":      "cloud.google.com/go/civil.Date",
+			"date-time": "github.com/example/clock.Instant",
+		},
 	}
+	require.NoError(t, New(cfg, spec).Generate(StageModels))
 
-	got, err := gen.resolveAllRefs(schema)
-	if err != nil {
-		t.Fatalf("resolveAllRefs() error = %v", err)
-	}
+	content, err := os.ReadFile(filepath.Join(outputDir, "models.go"))
+	require.NoError(t, err, "Failed to read models.go")
+	models := string(content)
+	assert.Contains(t, models, "\t\"cloud.google.com/go/civil\"\n")
+	assert.Contains(t, models, "\t\"github.com/google/uuid\"\n")
+	assert.Regexp(t, `ID +uuid\.UUID +`+"`json:\"id\"`", models)
+	assert.Regexp(t, `Due +civil\.Date +`+"`json:\"due\"`", models)
+	assert.Regexp(t, `Owner +\*uuid\.UUID +`+"`json:\"owner,omitempty\"`", models)
+	assert.Regexp(t, `Created +\*clock\.Instant +`, models, "date-time can be mapped too")
+	assert.Regexp(t, `Kind +\*TaskKind +`, models, "enums keep their type")
+	assert.NotContains(t, models, "type TaskID", "components mapped by their format generate no type")
+}
 
-	if got.Extra != nil && len(got.Extra) > 0 {
-		t.Errorf("Extra should be removed from resolved schema, got %v", got.Extra)
+func TestGenerateAdmin(t *testing.T) {
+	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Experiments: []config.Experiment{
+			{Name: "bulk_export"},
+		},
+		Tools: []config.Tool{
+			{Name: "search", Title: "Search", NoInput: true, Deprecated: true, ReplacedBy: "find"},
+			{Name: "find", NoInput: true},
+			{Name: "export", NoInput: true, Experiment: "bulk_export"},
+		},
 	}
 
-	if got.Properties == nil {
-		t.Fatal("Properties should not be nil")
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Output:   outputDir,
+		Exec:     config.ExecConfig{Package: "test", Filename: "server.go", Admin: true},
+		Model:    config.ModelConfig{Package: "test", Filename: "models.go"},
+		Resolver: config.ResolverConfig{Package: "test", Filename: "resolver.go", Type: "Resolver"},
 	}
+	require.NoError(t, New(cfg, spec).Generate(StageServer))
 
-	field := got.Properties["field"]
-	if field.Extra != nil && len(field.Extra) > 0 {
-		t.Errorf("Extra should be removed from property, got %v", field.Extra)
+	content, err := os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	server := string(content)
+	assert.Contains(t, server, `
+func AdminTools() []mcputil.ToolInfo {
+	return []mcputil.ToolInfo{
+		{Name: "search", Title: "Search", Deprecated: true, ReplacedBy: "find"},
+		{Name: "find"},
+		{Name: "export", Experiment: "bulk_export"},
 	}
 }
+`)
+	assert.Contains(t, server, "\t\tcfg.Admin.Tools = AdminTools()\n")
+	assert.Contains(t, server, "\t\t\tcfg.Admin.Switch = mcputil.NewToolSwitch()\n")
 
-func TestUpdateResolverIncremental(t *testing.T) {
-	specPath := filepath.Join("testdata", "config_based_types.yaml")
-	spec, err := config.LoadMCPSpec(specPath)
-	require.NoError(t, err, "Failed to load spec")
-
-	tests := []struct {
-		name             string
-		existingResolver string
-		wantContains     []string
-		wantNotContains  []string
-		expectUpdate     bool
-	}{
-		{
-			name: "add new handler",
-			existingResolver: `package test
-
-import (
-	"context"
-	"fmt"
-	mcp "github.com/mark3labs/mcp-go/mcp"
-)
-
-type Resolver struct{}
-
-type toolResolver struct {
-	*Resolver
-}
-
-type promptResolver struct {
-	*Resolver
-}
-
-type resourceResolver struct {
-	*Resolver
-}
-
-func (r *toolResolver) OldHandler(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, map[string]any, error) {
-	return nil, nil, nil
-}
-`,
-			wantContains: []string{
-				"func (r *Resolver) CreateEventTool",
-				"Orphaned: OldHandler",
-			},
-			expectUpdate: true,
-		},
-		{
-			name: "no changes needed",
-			existingResolver: `package test
-
-import (
-	"context"
-	"fmt"
-	mcp "github.com/mark3labs/mcp-go/mcp"
-)
-
-type Resolver struct{}
-
-type toolResolver struct {
-	*Resolver
-}
-
-type promptResolver struct {
-	*Resolver
-}
-
-type resourceResolver struct {
-	*Resolver
+	cfg.Exec.Admin = false
+	require.NoError(t, New(cfg, spec).Generate(StageServer))
+	content, err = os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	assert.NotContains(t, string(content), "AdminTools")
 }
 
-func (r *Resolver) CreateEventTool(ctx context.Context, req *mcp.CallToolRequest, input *Event) (*mcp.CallToolResult, map[string]any, error) {
-	return nil, nil, fmt.Errorf("create-event not implemented")
-}
-`,
-			wantContains: []string{
-				"func (r *Resolver) CreateEventTool",
-			},
-			wantNotContains: []string{
-				"Orphaned Handlers",
-			},
-			expectUpdate: false,
+func TestGenerateEnv(t *testing.T) {
+	spec := &config.MCPSpec{
+		Info:  config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Tools: []config.Tool{{Name: "ping", NoInput: true}},
+		Env: []config.EnvVar{
+			{Name: "TASKS_TOKEN", Description: "Token of the tasks API", Required: true},
+			{Name: "TASKS_URL", Default: "https://tasks.example.com"},
 		},
 	}
+	require.NoError(t, spec.Validate())
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			tmpDir := t.TempDir()
-			resolverFile := filepath.Join(tmpDir, "schema.resolvers.go")
-
-			if err := os.WriteFile(resolverFile, []byte(tt.existingResolver), 0644); err != nil {
-				t.Fatalf("Failed to write resolver file: %v", err)
-			}
-
-			cfg := &config.Config{
-				Spec:   specPath,
-				Output: tmpDir,
-				Model: config.ModelConfig{
-					Package:  "test",
-					Filename: "models.go",
-				},
-				Resolver: config.ResolverConfig{
-					Package:  "test",
-					Filename: "schema.resolvers.go",
-					Type:     "Resolver",
-					Preserve: true,
-				},
-				Models: config.ModelsConfig{
-					Models: map[string]config.TypeMapping{},
-				},
-			}
-
-			gen := New(cfg, spec)
-			if err := gen.loadSchemas(); err != nil {
-				t.Fatalf("Failed to load schemas: %v", err)
-			}
-
-			err := gen.updateResolverIncremental(resolverFile)
-			if err != nil {
-				t.Fatalf("updateResolverIncremental() error = %v", err)
-			}
-
-			content, err := os.ReadFile(resolverFile)
-			if err != nil {
-				t.Fatalf("Failed to read updated resolver: %v", err)
-			}
-
-			contentStr := string(content)
-
-			for _, want := range tt.wantContains {
-				if !containsString(contentStr, want) {
-					t.Errorf("Updated resolver should contain %q\nGot: %s", want, contentStr)
-				}
-			}
-
-			for _, wantNot := range tt.wantNotContains {
-				if containsString(contentStr, wantNot) {
-					t.Errorf("Updated resolver should NOT contain %q", wantNot)
-				}
-			}
-		})
-	}
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Output:   outputDir,
+		Exec:     config.ExecConfig{Package: "test", Filename: "server.go"},
+		Model:    config.ModelConfig{Package: "test", Filename: "models.go"},
+		Resolver: config.ResolverConfig{Package: "test", Filename: "resolver.go", Type: "Resolver"},
+	}
+	require.NoError(t, New(cfg, spec).Generate(StageServer))
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `func Env() []mcputil.EnvVar {
+	return []mcputil.EnvVar{
+		{Name: "TASKS_TOKEN", Description: "Token of the tasks API", Required: true},
+		{Name: "TASKS_URL", Default: "https://tasks.example.com"},
+	}
+}`)
+	assert.Contains(t, string(content), "\tcfg.Env = append(Env(), cfg.Env...)\n")
 }
-
@@ -0,0 +1,86 @@
+package codegen
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// rootResolverFile is the resolver file of the handlers without a group.
+const rootResolverFile = "schema.resolvers.go"
+
+// groups returns the groups of the tools, resources and prompts of the
+// spec, sorted by name.
+func (g *Generator) groups() []string {
+	seen := make(map[string]bool)
+	for _, tool := range g.spec.Tools {
+		seen[tool.Group] = true
+	}
+	for _, resource := range g.spec.Resources {
+		seen[resource.Group] = true
+	}
+	for _, prompt := range g.spec.Prompts {
+		seen[prompt.Group] = true
+	}
+	delete(seen, "")
+
+	groups := make([]string, 0, len(seen))
+	for group := range seen {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// groupAccessor returns the name of the ResolverInterface method returning
+// the sub-resolver of group.
+func groupAccessor(group string) string {
+	return toPascalCase(group)
+}
+
+// groupInterface returns the name of the interface of the sub-resolver of
+// group, declared by the server.
+func groupInterface(group string) string {
+	return toPascalCase(group) + "Resolver"
+}
+
+// resolverFile returns the resolver file of the handlers of group.
+func (g *Generator) resolverFile(group string) string {
+	if group == "" {
+		return filepath.Join(g.config.Output, rootResolverFile)
+	}
+	return filepath.Join(g.config.Output, group+".resolvers.go")
+}
+
+// resolverType returns the receiver type of the handlers of group: the
+// resolver type of the config, or the sub-resolver embedding it.
+func (g *Generator) resolverType(group string) string {
+	if group == "" {
+		return g.config.Resolver.Type
+	}
+	name := groupInterface(group)
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+// handlerReceiver returns the expression of the generated server calling
+// the handlers of group on its resolver.
+func handlerReceiver(group string) string {
+	if group == "" {
+		return "resolver"
+	}
+	return "resolver." + groupAccessor(group) + "()"
+}
+
+// groupsData returns the template data of the groups of the spec.
+func (g *Generator) groupsData() []map[string]string {
+	groups := g.groups()
+	data := make([]map[string]string, 0, len(groups))
+	for _, group := range groups {
+		data = append(data, map[string]string{
+			"Name":      group,
+			"Accessor":  groupAccessor(group),
+			"Interface": groupInterface(group),
+		})
+	}
+	return data
+}
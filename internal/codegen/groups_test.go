@@ -0,0 +1,91 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+func TestGenerateResolverGroups(t *testing.T) {
+	object := &config.Schema{Type: "object", Properties: map[string]*config.Schema{"id": {Type: "string"}}}
+	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Tools: []config.Tool{
+			{Name: "ping", NoInput: true},
+			{Name: "create_invoice", InputSchema: object, OutputSchema: object, Group: "billing"},
+		},
+		Resources: []config.Resource{
+			{Name: "invoice", URITemplate: "invoice://{id}", Group: "billing"},
+		},
+		Prompts: []config.Prompt{
+			{Name: "summarize"},
+		},
+	}
+	require.NoError(t, spec.Validate())
+
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Output:   outputDir,
+		Exec:     config.ExecConfig{Package: "test", Filename: "server.go", SwappableResolver: true},
+		Model:    config.ModelConfig{Package: "test", Filename: "models.go"},
+		Resolver: config.ResolverConfig{Package: "test", Filename: "resolver.go", Type: "Resolver", Preserve: true},
+	}
+	require.NoError(t, New(cfg, spec).Generate(StageModels, StageServer, StageResolver))
+
+	server, err := os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(server), `
+	SummarizePrompt(ctx context.Context, req *mcp.GetPromptRequest, args map[string]string) (*mcp.GetPromptResult, error)
+	// Billing returns the resolver of the handlers of the billing group.
+	Billing() BillingResolver
+}
+`)
+	assert.Contains(t, string(server), `
+type BillingResolver interface {
+	CreateInvoiceTool(ctx context.Context, req *mcp.CallToolRequest, input *CreateInvoiceInput) (*mcp.CallToolResult, CreateInvoiceOutput, error)
+	InvoiceResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error)
+}
+`)
+	assert.Contains(t, string(server), "\t\tresolver.Billing().CreateInvoiceTool,\n")
+	assert.Contains(t, string(server), "\t\tresolver.Billing().InvoiceResource,\n")
+	assert.Contains(t, string(server), "return r.s.Resolver().Billing().CreateInvoiceTool(ctx, req, input)", "the swappable resolver forwards each call to the current resolver")
+
+	root, err := os.ReadFile(filepath.Join(outputDir, "schema.resolvers.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(root), "func (r *Resolver) PingTool(")
+	assert.Contains(t, string(root), "func (r *Resolver) SummarizePrompt(")
+	assert.NotContains(t, string(root), "CreateInvoiceTool")
+
+	billing, err := os.ReadFile(filepath.Join(outputDir, "billing.resolvers.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(billing), `
+// Billing returns the resolver of the billing group.
+func (r *Resolver) Billing() BillingResolver {
+	return &billingResolver{r}
+}
+
+// billingResolver implements the handlers of the billing group.
+type billingResolver struct{ *Resolver }
+`)
+	assert.Contains(t, string(billing), "func (r *billingResolver) CreateInvoiceTool(")
+	assert.Contains(t, string(billing), "func (r *billingResolver) InvoiceResource(")
+
+	t.Run("incremental updates keep the handlers of the groups", func(t *testing.T) {
+		implemented := []byte(string(billing) + "\n// helper is kept\nfunc helper() {}\n")
+		require.NoError(t, os.WriteFile(filepath.Join(outputDir, "billing.resolvers.go"), implemented, 0o644))
+
+		spec.Tools = append(spec.Tools, config.Tool{Name: "refund", InputSchema: object, Group: "billing"})
+		require.NoError(t, New(cfg, spec).Generate(StageResolver))
+
+		billing, err := os.ReadFile(filepath.Join(outputDir, "billing.resolvers.go"))
+		require.NoError(t, err)
+		assert.Contains(t, string(billing), "func (r *billingResolver) RefundTool(")
+		assert.Contains(t, string(billing), "func helper() {}")
+		assert.Contains(t, string(billing), "func (r *Resolver) Billing() BillingResolver {")
+		assert.NotContains(t, string(billing), "Orphaned")
+	})
+}
@@ -0,0 +1,36 @@
+package codegen
+
+import (
+	"bytes"
+	"strings"
+)
+
+// generatedComment starts the generated code comment of the files mcpgen
+// writes, followed by the version of mcpgen when header.version is set.
+const generatedComment = "// Code generated by mcpgen"
+
+// withHeader returns the Go source of a generated file headed by the
+// comment and build constraint of the header config, with the version of
+// mcpgen in its generated code comment when enabled.
+func (g *Generator) withHeader(src []byte) []byte {
+	header := g.config.Header
+	if header.Version {
+		src = bytes.Replace(src, []byte(generatedComment), []byte(generatedComment+" "+Version), 1)
+	}
+
+	var buf bytes.Buffer
+	if comment := strings.TrimRight(header.Comment, "\n"); comment != "" {
+		for _, line := range strings.Split(comment, "\n") {
+			buf.WriteString(strings.TrimRight("// "+line, " ") + "\n")
+		}
+		buf.WriteString("\n")
+	}
+	if header.BuildTags != "" {
+		buf.WriteString("//go:build " + header.BuildTags + "\n\n")
+	}
+	if buf.Len() == 0 {
+		return src
+	}
+	buf.Write(src)
+	return buf.Bytes()
+}
@@ -0,0 +1,76 @@
+package codegen
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+func TestGenerateHeader(t *testing.T) {
+	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Tools: []config.Tool{
+			{Name: "ping", NoInput: true},
+		},
+		Components: config.Components{
+			Schemas: map[string]*config.Schema{
+				"Task": {Type: "object", Properties: map[string]*config.Schema{"name": {Type: "string"}}},
+			},
+		},
+	}
+
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Output:   outputDir,
+		Exec:     config.ExecConfig{Package: "test", Filename: "server.go", Fake: config.FakeConfig{Filename: "servertest/fake.go"}},
+		Model:    config.ModelConfig{Package: "test", Filename: "models.go", Layout: config.ModelLayoutPerSchema},
+		Resolver: config.ResolverConfig{Package: "test", Filename: "resolver.go", Type: "Resolver", Preserve: true},
+		Header: config.HeaderConfig{
+			Comment:   "Copyright (c) 2026 Acme Inc.\n\nLicensed under the Apache License, Version 2.0.\n",
+			BuildTags: "linux && !tinygo",
+			Version:   true,
+		},
+	}
+	require.NoError(t, New(cfg, spec).Generate(StageModels, StageServer, StageResolver, StageFake))
+
+	const header = `// Copyright (c) 2026 Acme Inc.
+//
+// Licensed under the Apache License, Version 2.0.
+
+//go:build linux && !tinygo
+
+`
+	for _, name := range []string{"models.go", "task.go", "server.go", "resolver.go", "schema.resolvers.go", "servertest/fake.go"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(outputDir, name)
+			content, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(string(content), header), "the file starts with the header:\n%s", content)
+
+			file, err := parser.ParseFile(token.NewFileSet(), path, content, parser.ParseComments)
+			require.NoError(t, err)
+			require.NotEmpty(t, file.Comments)
+			assert.NotEqual(t, file.Doc, file.Comments[0], "the header is not the package doc")
+		})
+	}
+
+	models, err := os.ReadFile(filepath.Join(outputDir, "models.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(models), header+"// Code generated by mcpgen "+Version+". DO NOT EDIT.\n")
+	task, err := os.ReadFile(filepath.Join(outputDir, "task.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(task), header+"// Code generated by mcpgen "+Version+" from the Task schema. DO NOT EDIT.\n")
+
+	t.Run("schema files are recognized behind the header", func(t *testing.T) {
+		cfg.Model.Layout = config.ModelLayoutSingle
+		require.NoError(t, New(cfg, spec).Generate(StageModels))
+		assert.NoFileExists(t, filepath.Join(outputDir, "task.go"))
+	})
+}
@@ -0,0 +1,106 @@
+package codegen
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"go.probo.inc/mcpgen/internal/config"
+	"go.probo.inc/mcpgen/internal/importer"
+)
+
+// modelFiles returns the files of the component schemas with the
+// per-schema model layout, or its per-type alias, by schema name, such as
+// task.go for Task. It returns nil with the single layout. mainFile is the
+// file of the other models, which no schema file may replace.
+func (g *Generator) modelFiles(mainFile string) (map[string]string, error) {
+	if layout := g.config.Model.Layout; layout != config.ModelLayoutPerSchema && layout != config.ModelLayoutPerType {
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(g.spec.Components.Schemas))
+	for name := range g.spec.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	files := make(map[string]string, len(names))
+	owners := map[string]string{mainFile: ""}
+	for _, name := range names {
+		file := importer.SnakeCase(name) + ".go"
+		if strings.HasSuffix(file, "_test.go") {
+			file = strings.TrimSuffix(file, ".go") + "_schema.go"
+		}
+		if owner, ok := owners[file]; ok {
+			if owner == "" {
+				return nil, fmt.Errorf("model.layout: schema %s would be written to %s, the models file", name, file)
+			}
+			return nil, fmt.Errorf("model.layout: schemas %s and %s would both be written to %s", owner, name, file)
+		}
+		owners[file] = name
+		files[name] = file
+	}
+	return files, nil
+}
+
+// schemaFileHeader starts the generated code comment of the model files of
+// a schema.
+const schemaFileHeader = generatedComment + " from the "
+
+// removeStaleModelFiles removes the model files of schemas from dir that
+// this generation did not write, left by removed schemas or by the
+// per-schema layout when switching back to a single file.
+func (g *Generator) removeStaleModelFiles(dir string, written map[string]bool) error {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() || filepath.Ext(path) != ".go" || written[path] {
+			continue
+		}
+		stale, err := isSchemaModelFile(path)
+		if err != nil {
+			return err
+		}
+		if stale {
+			if err := g.removeFile(path); err != nil {
+				return fmt.Errorf("failed to remove stale models file: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// isSchemaModelFile reports whether the file at path holds the models of a
+// schema, generated with the per-schema layout.
+func isSchemaModelFile(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	// The generated code comment follows the header comment and build
+	// constraint of the config, and carries the version of mcpgen when
+	// header.version is set
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, generatedComment) {
+			return strings.Contains(line, " from the ") && strings.HasSuffix(line, " schema. DO NOT EDIT."), nil
+		}
+		if line != "" && !strings.HasPrefix(line, "//") {
+			return false, nil
+		}
+	}
+	return false, scanner.Err()
+}
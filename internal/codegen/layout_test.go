@@ -0,0 +1,119 @@
+package codegen
+
+import (
+	"go/format"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+func TestGenerateModelsPerSchema(t *testing.T) {
+	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Components: config.Components{
+			Schemas: map[string]*config.Schema{
+				"Task": {
+					Type: "object",
+					Properties: map[string]*config.Schema{
+						"status":  {Type: "string", Enum: []any{"todo", "done"}},
+						"due":     {Type: "string", Format: "date-time"},
+						"project": {Ref: "#/components/schemas/Project"},
+					},
+				},
+				"ProjectOwner": {Type: "object", Properties: map[string]*config.Schema{"name": {Type: "string"}}},
+				"Project":      {Type: "object", Properties: map[string]*config.Schema{"name": {Type: "string"}}},
+			},
+		},
+	}
+
+	outputDir := t.TempDir()
+	generate := func(t *testing.T, layout string) {
+		t.Helper()
+		gen := New(&config.Config{
+			Output: outputDir,
+			Model:  config.ModelConfig{Package: "models", Filename: "models/models.go", Layout: layout},
+		}, spec)
+		require.NoError(t, gen.Generate(StageModels))
+	}
+	read := func(t *testing.T, name string) string {
+		t.Helper()
+		content, err := os.ReadFile(filepath.Join(outputDir, "models", name))
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	t.Run("per-schema", func(t *testing.T) {
+		generate(t, config.ModelLayoutPerSchema)
+
+		task := read(t, "task.go")
+		assert.Contains(t, task, "// Code generated by mcpgen from the Task schema. DO NOT EDIT.\n\npackage models\n")
+		assert.Contains(t, task, "type Task struct")
+		assert.Contains(t, task, "type TaskStatus string", "enums go with their schema")
+		assert.Contains(t, task, `"time"`)
+		assert.NotContains(t, task, "type Project struct")
+
+		assert.Contains(t, read(t, "project.go"), "type Project struct")
+		assert.NotContains(t, read(t, "project.go"), `"time"`)
+		assert.Contains(t, read(t, "project_owner.go"), "type ProjectOwner struct")
+		assert.NotContains(t, read(t, "project.go"), "type ProjectOwner struct", "types go to the longest matching schema")
+
+		models := read(t, "models.go")
+		assert.Contains(t, models, "// Code generated by mcpgen. DO NOT EDIT.")
+		assert.NotContains(t, models, "type Task struct")
+
+		// With or without imports, the files are gofmt-clean
+		for _, name := range []string{"task.go", "project.go", "project_owner.go", "models.go"} {
+			formatted, err := format.Source([]byte(read(t, name)))
+			require.NoError(t, err, name)
+			assert.Equal(t, string(formatted), read(t, name), name)
+		}
+	})
+
+	t.Run("stale schema files are removed", func(t *testing.T) {
+		handwritten := filepath.Join(outputDir, "models", "helpers.go")
+		require.NoError(t, os.WriteFile(handwritten, []byte("package models\n"), 0o644))
+
+		generate(t, config.ModelLayoutSingle)
+
+		assert.NoFileExists(t, filepath.Join(outputDir, "models", "task.go"))
+		assert.NoFileExists(t, filepath.Join(outputDir, "models", "project.go"))
+		assert.FileExists(t, handwritten)
+		assert.Contains(t, read(t, "models.go"), "type Task struct")
+	})
+
+	t.Run("per-type", func(t *testing.T) {
+		generate(t, config.ModelLayoutPerType)
+
+		assert.Contains(t, read(t, "task.go"), "type Task struct")
+		assert.Contains(t, read(t, "project.go"), "type Project struct")
+		assert.NotContains(t, read(t, "models.go"), "type Task struct")
+	})
+
+	t.Run("plan", func(t *testing.T) {
+		generate(t, config.ModelLayoutPerSchema)
+
+		gen := New(&config.Config{
+			Output: outputDir,
+			Model:  config.ModelConfig{Package: "models", Filename: "models/models.go"},
+		}, spec)
+		plan, err := gen.Plan(StageModels)
+		require.NoError(t, err)
+
+		planned := plan.file(filepath.Join(outputDir, "models", "task.go"))
+		require.NotNil(t, planned)
+		assert.Equal(t, FileDelete, planned.Action)
+		assert.FileExists(t, filepath.Join(outputDir, "models", "task.go"))
+	})
+
+	t.Run("collision", func(t *testing.T) {
+		gen := New(&config.Config{
+			Output: t.TempDir(),
+			Model:  config.ModelConfig{Package: "models", Filename: "task.go", Layout: config.ModelLayoutPerSchema},
+		}, spec)
+		assert.ErrorContains(t, gen.Generate(StageModels), "model.layout: schema Task would be written to task.go, the models file")
+	})
+}
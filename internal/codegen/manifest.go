@@ -0,0 +1,163 @@
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+// homebrewArch is the Homebrew block of the architectures of the formula.
+var homebrewArch = map[string]string{
+	"amd64": "on_intel",
+	"arm64": "on_arm",
+}
+
+// scoopArch is the Scoop architecture of the architectures of the manifest.
+var scoopArch = map[string]string{
+	"386":   "32bit",
+	"amd64": "64bit",
+	"arm64": "arm64",
+}
+
+// writeHomebrewFormula writes the Homebrew formula path installing the
+// binary of the darwin and linux artifacts, downloaded from opts.DownloadURL.
+// The notes, such as the environment variables of the server, are its
+// caveats.
+func writeHomebrewFormula(path string, opts ReleaseOptions, description string, notes []string, artifacts []Artifact) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Homebrew formula of %s %s, generated by mcpgen release.\n", opts.Name, opts.Version)
+	fmt.Fprintf(&b, "class %s < Formula\n", homebrewClass(opts.Name))
+	if description != "" {
+		fmt.Fprintf(&b, "  desc %s\n", rubyString(description))
+	}
+	if opts.Homepage != "" {
+		fmt.Fprintf(&b, "  homepage %s\n", rubyString(opts.Homepage))
+	}
+	fmt.Fprintf(&b, "  version %s\n", rubyString(opts.Version))
+
+	found := false
+	for _, system := range []struct{ goos, block string }{{"darwin", "on_macos"}, {"linux", "on_linux"}} {
+		var blocks strings.Builder
+		for _, artifact := range artifacts {
+			arch, ok := homebrewArch[artifact.Arch]
+			if artifact.OS != system.goos || !ok {
+				continue
+			}
+			fmt.Fprintf(&blocks, "    %s do\n", arch)
+			fmt.Fprintf(&blocks, "      url %s\n", rubyString(downloadURL(opts.DownloadURL, artifact)))
+			fmt.Fprintf(&blocks, "      sha256 %s\n", rubyString(artifact.SHA256))
+			fmt.Fprintf(&blocks, "    end\n")
+		}
+		if blocks.Len() == 0 {
+			continue
+		}
+		found = true
+		fmt.Fprintf(&b, "\n  %s do\n%s  end\n", system.block, blocks.String())
+	}
+	if !found {
+		return fmt.Errorf("no darwin or linux target with an amd64 or arm64 architecture for the Homebrew formula")
+	}
+
+	fmt.Fprintf(&b, "\n  def install\n    bin.install %s\n  end\n", rubyString(opts.Name))
+	if len(notes) > 0 {
+		// A quoted heredoc is not interpolated
+		b.WriteString("\n  def caveats\n    <<~'EOS'\n")
+		for _, note := range notes {
+			fmt.Fprintf(&b, "      %s\n", note)
+		}
+		b.WriteString("    EOS\n  end\n")
+	}
+	fmt.Fprintf(&b, "\n  test do\n    assert_predicate bin/%s, :executable?\n  end\nend\n", rubyString(opts.Name))
+
+	return os.WriteFile(path, []byte(b.String()), 0o644)
+}
+
+// writeScoopManifest writes the Scoop manifest path installing the binary
+// of the windows artifacts, downloaded from opts.DownloadURL, which shows
+// the notes after installing it.
+func writeScoopManifest(path string, opts ReleaseOptions, description string, notes []string, artifacts []Artifact) error {
+	type download struct {
+		URL  string `json:"url"`
+		Hash string `json:"hash"`
+	}
+	manifest := struct {
+		Version      string              `json:"version"`
+		Description  string              `json:"description,omitempty"`
+		Homepage     string              `json:"homepage,omitempty"`
+		Architecture map[string]download `json:"architecture"`
+		Bin          string              `json:"bin"`
+		Notes        []string            `json:"notes,omitempty"`
+	}{
+		Version:      opts.Version,
+		Description:  description,
+		Homepage:     opts.Homepage,
+		Architecture: make(map[string]download),
+		Bin:          opts.Name + ".exe",
+		Notes:        notes,
+	}
+	for _, artifact := range artifacts {
+		if arch, ok := scoopArch[artifact.Arch]; ok && artifact.OS == "windows" {
+			manifest.Architecture[arch] = download{URL: downloadURL(opts.DownloadURL, artifact), Hash: artifact.SHA256}
+		}
+	}
+	if len(manifest.Architecture) == 0 {
+		return fmt.Errorf("no windows target with a 386, amd64 or arm64 architecture for the Scoop manifest")
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// envNotes returns the notes of the packages of the server listing the
+// environment variables it reads, or nil when it reads none.
+func envNotes(name string, vars []config.EnvVar) []string {
+	if len(vars) == 0 {
+		return nil
+	}
+	notes := []string{name + " reads these environment variables:"}
+	for _, v := range vars {
+		note := "  " + v.Name
+		switch {
+		case v.Required:
+			note += " (required)"
+		case v.Default != "":
+			note += " (default: " + v.Default + ")"
+		}
+		if description := strings.Join(strings.Fields(v.Description), " "); description != "" {
+			note += ": " + description
+		}
+		notes = append(notes, note)
+	}
+	return notes
+}
+
+// downloadURL returns the URL of the archive of artifact published under
+// base.
+func downloadURL(base string, artifact Artifact) string {
+	return strings.TrimSuffix(base, "/") + "/" + filepath.Base(artifact.Path)
+}
+
+// homebrewClass returns the class of the formula name, as Homebrew derives
+// it: tasks-server is TasksServer.
+func homebrewClass(name string) string {
+	return toPascalCase(strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '-'
+	}, name))
+}
+
+// rubyString returns s as a double-quoted Ruby string literal.
+func rubyString(s string) string {
+	return strings.ReplaceAll(strconv.Quote(s), "#{", `\#{`)
+}
@@ -0,0 +1,126 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+var manifestArtifacts = []Artifact{
+	{OS: "darwin", Arch: "arm64", Path: "dist/tasks_1.2.0_darwin_arm64.tar.gz", SHA256: "aa"},
+	{OS: "linux", Arch: "amd64", Path: "dist/tasks_1.2.0_linux_amd64.tar.gz", SHA256: "bb"},
+	{OS: "linux", Arch: "riscv64", Path: "dist/tasks_1.2.0_linux_riscv64.tar.gz", SHA256: "cc"},
+	{OS: "windows", Arch: "amd64", Path: "dist/tasks_1.2.0_windows_amd64.zip", SHA256: "dd"},
+}
+
+func TestWriteHomebrewFormula(t *testing.T) {
+	opts := ReleaseOptions{
+		Name:        "tasks-server",
+		Version:     "1.2.0",
+		DownloadURL: "https://github.com/acme/tasks/releases/download/v1.2.0/",
+		Homepage:    "https://github.com/acme/tasks",
+	}
+	path := filepath.Join(t.TempDir(), "tasks-server.rb")
+	require.NoError(t, writeHomebrewFormula(path, opts, `Manage "tasks" from #{anywhere}`, nil, manifestArtifacts))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `# Homebrew formula of tasks-server 1.2.0, generated by mcpgen release.
+class TasksServer < Formula
+  desc "Manage \"tasks\" from \#{anywhere}"
+  homepage "https://github.com/acme/tasks"
+  version "1.2.0"
+
+  on_macos do
+    on_arm do
+      url "https://github.com/acme/tasks/releases/download/v1.2.0/tasks_1.2.0_darwin_arm64.tar.gz"
+      sha256 "aa"
+    end
+  end
+
+  on_linux do
+    on_intel do
+      url "https://github.com/acme/tasks/releases/download/v1.2.0/tasks_1.2.0_linux_amd64.tar.gz"
+      sha256 "bb"
+    end
+  end
+
+  def install
+    bin.install "tasks-server"
+  end
+
+  test do
+    assert_predicate bin/"tasks-server", :executable?
+  end
+end
+`, string(data))
+
+	err = writeHomebrewFormula(path, opts, "", nil, manifestArtifacts[3:])
+	assert.EqualError(t, err, "no darwin or linux target with an amd64 or arm64 architecture for the Homebrew formula")
+}
+
+func TestWriteScoopManifest(t *testing.T) {
+	opts := ReleaseOptions{
+		Name:        "tasks",
+		Version:     "1.2.0",
+		DownloadURL: "https://github.com/acme/tasks/releases/download/v1.2.0",
+	}
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	require.NoError(t, writeScoopManifest(path, opts, "Manage tasks", nil, manifestArtifacts))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"version": "1.2.0",
+		"description": "Manage tasks",
+		"architecture": {
+			"64bit": {
+				"url": "https://github.com/acme/tasks/releases/download/v1.2.0/tasks_1.2.0_windows_amd64.zip",
+				"hash": "dd"
+			}
+		},
+		"bin": "tasks.exe"
+	}`, string(data))
+
+	err = writeScoopManifest(path, opts, "", nil, manifestArtifacts[:3])
+	assert.EqualError(t, err, "no windows target with a 386, amd64 or arm64 architecture for the Scoop manifest")
+}
+
+func TestEnvNotes(t *testing.T) {
+	assert.Nil(t, envNotes("tasks", nil))
+
+	notes := envNotes("tasks", []config.EnvVar{
+		{Name: "TASKS_TOKEN", Description: "Token of the\ntasks API", Required: true},
+		{Name: "TASKS_URL", Default: "https://tasks.example.com"},
+	})
+	assert.Equal(t, []string{
+		"tasks reads these environment variables:",
+		"  TASKS_TOKEN (required): Token of the tasks API",
+		"  TASKS_URL (default: https://tasks.example.com)",
+	}, notes)
+
+	path := filepath.Join(t.TempDir(), "tasks.rb")
+	opts := ReleaseOptions{Name: "tasks", Version: "1.2.0", DownloadURL: "https://example.com"}
+	require.NoError(t, writeHomebrewFormula(path, opts, "", notes, manifestArtifacts))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `  def caveats
+    <<~'EOS'
+      tasks reads these environment variables:
+        TASKS_TOKEN (required): Token of the tasks API
+        TASKS_URL (default: https://tasks.example.com)
+    EOS
+  end
+`)
+
+	path = filepath.Join(t.TempDir(), "tasks.json")
+	require.NoError(t, writeScoopManifest(path, opts, "", notes, manifestArtifacts))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"notes": [
+        "tasks reads these environment variables:",`)
+}
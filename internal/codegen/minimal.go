@@ -0,0 +1,198 @@
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+// componentRefPrefix prefixes the references to the component schemas.
+const componentRefPrefix = "#/components/schemas/"
+
+// componentRef returns the component schema s refers to.
+func componentRef(s *config.Schema) (string, bool) {
+	if !config.IsSchemaRef(s) {
+		return "", false
+	}
+	name, ok := strings.CutPrefix(s.Ref, componentRefPrefix)
+	return name, ok && !strings.Contains(name, "/")
+}
+
+// sharedSchemaVar returns the variable of the component schema name shared
+// by the tool schemas with exec.minimal_runtime, closed for strict inputs.
+func sharedSchemaVar(name string, closed bool) string {
+	if closed {
+		return toPascalCase(name) + "ClosedComponentSchema"
+	}
+	return toPascalCase(name) + "ComponentSchema"
+}
+
+// addSharedSchemaVar adds the schema variable varName of s for
+// exec.minimal_runtime: the references of s to component schemas are kept,
+// the components getting a variable of their own shared by every schema
+// using them, and titles and descriptions are dropped. A reference to a
+// component is the variable of the component. With closed, the
+// objects are closed as with model.strict_inputs; s is then inlined when
+// some of its references are left open, such as in allOf.
+func (g *Generator) addSharedSchemaVar(varName string, s *config.Schema, closed bool) error {
+	g.sharedRefs = make(map[string]bool)
+	shared, err := g.resolveAllRefs(s)
+	refs := g.sharedRefs
+	g.sharedRefs = nil
+	if err != nil {
+		return err
+	}
+
+	if closed {
+		if hasOpenRef(shared, true) {
+			if shared, err = g.resolveAllRefs(s); err != nil {
+				return err
+			}
+			shared = shared.CloneSchemas()
+			refs = nil
+		}
+		closeObjects(shared)
+	}
+	dropDocs(shared)
+
+	if name, ok := componentRef(shared); ok {
+		g.typeGen.AddSchemaAlias(varName, sharedSchemaVar(name, closed))
+		return g.addSharedComponent(name, closed)
+	}
+
+	schemaJSON, err := canonicalJSON(shared)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(refs))
+	for name := range refs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	vars := make(map[string]string, len(names))
+	for _, name := range names {
+		vars[name] = sharedSchemaVar(name, closed)
+	}
+	g.typeGen.AddSharedSchemaVar(varName, string(schemaJSON), vars)
+
+	for _, name := range names {
+		if err := g.addSharedComponent(name, closed); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addSharedComponent adds the variable of the component schema name, unless
+// another schema added it already.
+func (g *Generator) addSharedComponent(name string, closed bool) error {
+	varName := sharedSchemaVar(name, closed)
+	if g.typeGen.HasSchemaVar(varName) {
+		return nil
+	}
+
+	s, ok := g.spec.Components.Schemas[name]
+	if !ok {
+		return fmt.Errorf("unknown schema %s", name)
+	}
+	if config.IsSchemaRef(s) && !strings.HasPrefix(s.Ref, "#") {
+		loaded, err := g.schemaLoader.Load(s.Ref)
+		if err != nil {
+			return fmt.Errorf("failed to load schema %s: %w", name, err)
+		}
+		s = loaded
+	}
+
+	if err := g.addSharedSchemaVar(varName, s, closed); err != nil {
+		return fmt.Errorf("failed to share schema %s: %w", name, err)
+	}
+	return nil
+}
+
+// hasOpenRef reports whether closeObjects leaves a component reference of s
+// open, such as one in allOf. closing reports whether closeObjects closes s.
+func hasOpenRef(s *config.Schema, closing bool) bool {
+	if s == nil {
+		return false
+	}
+	if _, ok := componentRef(s); ok {
+		return !closing
+	}
+	closing = closing && len(s.AllOf) == 0 && s.If == nil
+
+	closed := []*config.Schema{s.Items}
+	for _, prop := range s.Properties {
+		closed = append(closed, prop)
+	}
+	closed = append(closed, s.PrefixItems...)
+	closed = append(closed, s.AnyOf...)
+	closed = append(closed, s.OneOf...)
+	for _, sub := range closed {
+		if hasOpenRef(sub, closing) {
+			return true
+		}
+	}
+
+	open := []*config.Schema{
+		s.Contains, s.UnevaluatedItems, s.Not, s.If, s.Then, s.Else,
+		s.AdditionalProperties, s.UnevaluatedProperties,
+	}
+	for _, sub := range s.PatternProperties {
+		open = append(open, sub)
+	}
+	open = append(open, s.AllOf...)
+	for _, sub := range open {
+		if hasOpenRef(sub, false) {
+			return true
+		}
+	}
+	return false
+}
+
+// dropDocs removes the titles and descriptions of a resolved schema.
+func dropDocs(s *config.Schema) {
+	if s == nil {
+		return
+	}
+	s.Title = ""
+	s.Description = ""
+
+	for _, sub := range s.Properties {
+		dropDocs(sub)
+	}
+	for _, sub := range s.PatternProperties {
+		dropDocs(sub)
+	}
+	for _, sub := range s.PrefixItems {
+		dropDocs(sub)
+	}
+	for _, sub := range s.AnyOf {
+		dropDocs(sub)
+	}
+	for _, sub := range s.AllOf {
+		dropDocs(sub)
+	}
+	for _, sub := range s.OneOf {
+		dropDocs(sub)
+	}
+	for _, sub := range []*config.Schema{
+		s.Items, s.Contains, s.UnevaluatedItems, s.Not, s.If, s.Then, s.Else,
+		s.AdditionalProperties, s.UnevaluatedProperties,
+	} {
+		dropDocs(sub)
+	}
+}
+
+// sharedRoot returns the schema of a tool input or output to share: its
+// reference when it refers to a component schema, for the tools using the
+// same component to share its variable, or its loaded schema otherwise.
+func sharedRoot(schema, loaded *config.Schema) *config.Schema {
+	if _, ok := componentRef(schema); ok {
+		return schema
+	}
+	return loaded
+}
@@ -0,0 +1,88 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+func TestGenerateMinimalRuntime(t *testing.T) {
+	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Tools: []config.Tool{
+			{
+				Name:         "create_user",
+				Description:  "Create a user",
+				InputSchema:  &config.Schema{Ref: "#/components/schemas/User"},
+				OutputSchema: &config.Schema{Ref: "#/components/schemas/User"},
+			},
+			{
+				Name:        "ship",
+				Description: "Ship a parcel",
+				InputSchema: &config.Schema{
+					Type: "object",
+					Properties: map[string]*config.Schema{
+						"to":   {Ref: "#/components/schemas/Address", Description: "Destination"},
+						"from": {AllOf: []*config.Schema{{Ref: "#/components/schemas/Address"}}},
+					},
+				},
+			},
+		},
+		Components: config.Components{
+			Schemas: map[string]*config.Schema{
+				"Address": {
+					Type:        "object",
+					Description: "A postal address",
+					Properties:  map[string]*config.Schema{"city": {Type: "string", Description: "City"}},
+				},
+				"User": {
+					Type:       "object",
+					Properties: map[string]*config.Schema{"address": {Ref: "#/components/schemas/Address"}},
+				},
+			},
+		},
+	}
+
+	generate := func(t *testing.T, cfg config.Config) (string, string) {
+		t.Helper()
+		cfg.Output = t.TempDir()
+		cfg.Exec = config.ExecConfig{Package: "server", Filename: "server/server.go", MinimalRuntime: true, SchemaRegistry: true}
+		cfg.Model.Package = "models"
+		cfg.Model.Filename = "models/models.go"
+		cfg.Resolver = config.ResolverConfig{Package: "resolver", Filename: "resolver/resolver.go", Type: "Resolver"}
+		require.NoError(t, New(&cfg, spec).Generate(StageModels, StageServer))
+
+		models, err := os.ReadFile(filepath.Join(cfg.Output, "models", "models.go"))
+		require.NoError(t, err)
+		server, err := os.ReadFile(filepath.Join(cfg.Output, "server", "server.go"))
+		require.NoError(t, err)
+		return string(models), string(server)
+	}
+
+	t.Run("shared components", func(t *testing.T) {
+		models, server := generate(t, config.Config{})
+
+		assert.Regexp(t, "AddressComponentSchema +"+regexp.QuoteMeta("= mcp.MustUnmarshalSchema(`{\"properties\":{\"city\":{\"type\":\"string\"}},\"type\":\"object\"}`)"), models, "descriptions are dropped")
+		assert.Regexp(t, "UserComponentSchema +"+regexp.QuoteMeta("= mcp.MustUnmarshalSchemaRefs(`{\"properties\":{\"address\":{\"$ref\":\"#/components/schemas/Address\"}},\"type\":\"object\"}`, mcp.SchemaRefs{\"Address\": AddressComponentSchema})"), models)
+		assert.Regexp(t, `CreateUserToolInputSchema += UserComponentSchema\n`, models)
+		assert.Regexp(t, `CreateUserToolOutputSchema += UserComponentSchema\n`, models)
+		assert.Regexp(t, "ShipToolInputSchema +"+regexp.QuoteMeta("= mcp.MustUnmarshalSchemaRefs(`{\"properties\":{\"from\":{\"allOf\":[{\"$ref\":\"#/components/schemas/Address\"}]},\"to\":{\"$ref\":\"#/components/schemas/Address\"}},\"type\":\"object\"}`, mcp.SchemaRefs{\"Address\": AddressComponentSchema})"), models)
+
+		assert.Contains(t, server, `"Address": models.AddressComponentSchema,`)
+		assert.Contains(t, server, `"User":    models.UserComponentSchema,`)
+	})
+
+	t.Run("strict inputs", func(t *testing.T) {
+		models, _ := generate(t, config.Config{Model: config.ModelConfig{StrictInputs: true}})
+
+		assert.Regexp(t, `CreateUserToolInputSchema += UserClosedComponentSchema\n`, models)
+		assert.Regexp(t, `CreateUserToolOutputSchema += UserComponentSchema\n`, models)
+		assert.Contains(t, models, "mcp.SchemaRefs{\"Address\": AddressClosedComponentSchema}")
+		assert.Regexp(t, "ShipToolInputSchema +"+regexp.QuoteMeta("= mcp.MustUnmarshalSchema(`{\"additionalProperties\":false,\"properties\":{\"from\":{\"allOf\":[{\"properties\":{\"city\":{\"type\":\"string\"}},\"type\":\"object\"}]}"), models, "schemas referring to components left open are inlined")
+	})
+}
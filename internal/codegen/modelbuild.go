@@ -0,0 +1,287 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ModelBuild holds the model definitions computed from the schemas, before
+// they are rendered, for the ModelMutator plugins to change.
+type ModelBuild struct {
+	// PackageName is the package of the models.
+	PackageName string
+	// Imports are the import paths of the models file. Plugins using types
+	// of other packages in fields add their import path.
+	Imports []string
+	// Models are the struct types, in the order of the file. Plugins can
+	// change them and append new ones, but not remove them.
+	Models []*Model
+}
+
+// Model is a generated struct type.
+type Model struct {
+	Name string
+	// Doc is the doc comment, without the comment markers.
+	Doc    string
+	Fields []*Field
+	// Implements lists the interfaces the model is marked as implementing:
+	// each gets an Is<Interface>() method, like gqlgen models.
+	Implements []string
+}
+
+// Field is a field of a Model.
+type Field struct {
+	// Name is the Go name of the field, empty for an embedded field.
+	Name string
+	// Type is the Go type expression, such as *time.Time or uuid.UUID.
+	Type string
+	// Tag is the struct tag, without the backquotes, such as
+	// json:"id,omitempty".
+	Tag string
+	// Doc is the doc comment, without the comment markers.
+	Doc string
+}
+
+// ModelMutator is a Plugin changing the model definitions before they are
+// rendered, such as adding fields, changing tags or marking models as
+// implementing interfaces. With the per-schema model layout, it is called
+// once per models file.
+type ModelMutator interface {
+	MutateModels(build *ModelBuild) error
+}
+
+// mutateModels runs the ModelMutator plugins on the generated models source
+// and returns it with their changes. Models the plugins leave untouched keep
+// their generated source.
+func (g *Generator) mutateModels(src []byte) ([]byte, error) {
+	var mutators []Plugin
+	for _, p := range g.plugins {
+		if _, ok := p.(ModelMutator); ok {
+			mutators = append(mutators, p)
+		}
+	}
+	if len(mutators) == 0 {
+		return src, nil
+	}
+
+	file, err := parseModels(src)
+	if err != nil {
+		return nil, err
+	}
+	build := file.build()
+	original := file.build()
+
+	for _, p := range mutators {
+		if err := p.(ModelMutator).MutateModels(build); err != nil {
+			return nil, fmt.Errorf("plugin %s: %w", p.Name(), err)
+		}
+	}
+
+	return file.render(original, build)
+}
+
+// modelsFile is a parsed models source.
+type modelsFile struct {
+	src     []byte
+	fset    *token.FileSet
+	file    *ast.File
+	structs []*ast.GenDecl
+}
+
+func parseModels(src []byte) (*modelsFile, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "models.go", src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse models: %w", err)
+	}
+
+	m := &modelsFile{src: src, fset: fset, file: file}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE || len(gen.Specs) != 1 {
+			continue
+		}
+		if _, ok := gen.Specs[0].(*ast.TypeSpec).Type.(*ast.StructType); ok {
+			m.structs = append(m.structs, gen)
+		}
+	}
+	return m, nil
+}
+
+// build returns the model definitions of the file.
+func (m *modelsFile) build() *ModelBuild {
+	build := &ModelBuild{PackageName: m.file.Name.Name}
+	for _, imp := range m.file.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		build.Imports = append(build.Imports, path)
+	}
+
+	for _, decl := range m.structs {
+		spec := decl.Specs[0].(*ast.TypeSpec)
+		model := &Model{Name: spec.Name.Name, Doc: commentText(decl.Doc)}
+		for _, f := range spec.Type.(*ast.StructType).Fields.List {
+			field := Field{Type: m.text(f.Type), Doc: commentText(f.Doc)}
+			if f.Tag != nil {
+				field.Tag, _ = strconv.Unquote(f.Tag.Value)
+			}
+			if len(f.Names) == 0 {
+				model.Fields = append(model.Fields, &field)
+			}
+			for _, name := range f.Names {
+				named := field
+				named.Name = name.Name
+				model.Fields = append(model.Fields, &named)
+			}
+		}
+		build.Models = append(build.Models, model)
+	}
+	return build
+}
+
+// render returns the source of the file with the models changed from
+// original to build.
+func (m *modelsFile) render(original, build *ModelBuild) ([]byte, error) {
+	models := make(map[string]*Model, len(build.Models))
+	for _, model := range build.Models {
+		if model.Name == "" {
+			return nil, fmt.Errorf("models: a model has no name")
+		}
+		models[model.Name] = model
+	}
+
+	var buf bytes.Buffer
+	offset := 0
+	replace := func(start, end token.Pos, text string) {
+		buf.Write(m.src[offset:m.offset(start)])
+		buf.WriteString(text)
+		offset = m.offset(end)
+	}
+
+	if !slices.Equal(original.Imports, build.Imports) {
+		imports := renderImports(build.Imports)
+		switch {
+		case len(m.file.Imports) > 0:
+			replace(m.importDecl().Pos(), m.importDecl().End(), imports)
+		default:
+			replace(m.file.Name.End(), m.file.Name.End(), "\n\n"+imports)
+		}
+	}
+
+	for i, decl := range m.structs {
+		before := original.Models[i]
+		after, ok := models[before.Name]
+		if !ok {
+			return nil, fmt.Errorf("models: %s cannot be removed, map its schema to another type instead", before.Name)
+		}
+		delete(models, before.Name)
+		if reflect.DeepEqual(before, after) {
+			continue
+		}
+		start := decl.Pos()
+		if decl.Doc != nil {
+			start = decl.Doc.Pos()
+		}
+		replace(start, decl.End(), renderModel(after))
+	}
+	buf.Write(m.src[offset:])
+
+	for _, model := range build.Models {
+		if _, ok := models[model.Name]; ok {
+			buf.WriteString("\n" + renderModel(model) + "\n")
+		}
+	}
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format models changed by plugins: %w\n%s", err, buf.String())
+	}
+	return formatted, nil
+}
+
+func (m *modelsFile) importDecl() *ast.GenDecl {
+	for _, decl := range m.file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			return gen
+		}
+	}
+	return nil
+}
+
+func (m *modelsFile) offset(pos token.Pos) int {
+	return m.fset.Position(pos).Offset
+}
+
+func (m *modelsFile) text(node ast.Node) string {
+	return string(m.src[m.offset(node.Pos()):m.offset(node.End())])
+}
+
+func commentText(group *ast.CommentGroup) string {
+	return strings.TrimSuffix(group.Text(), "\n")
+}
+
+func renderImports(imports []string) string {
+	if len(imports) == 0 {
+		return ""
+	}
+	sorted := slices.Clone(imports)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+
+	var buf strings.Builder
+	buf.WriteString("import (\n")
+	for _, imp := range sorted {
+		fmt.Fprintf(&buf, "\t%q\n", imp)
+	}
+	buf.WriteString(")")
+	return buf.String()
+}
+
+func renderModel(model *Model) string {
+	var buf strings.Builder
+	if model.Doc != "" {
+		buf.WriteString(docComment(model.Doc, ""))
+	}
+	fmt.Fprintf(&buf, "type %s struct {\n", model.Name)
+	for _, field := range model.Fields {
+		if field.Doc != "" {
+			buf.WriteString(docComment(field.Doc, "\t"))
+		}
+		buf.WriteString("\t")
+		if field.Name != "" {
+			buf.WriteString(field.Name + " ")
+		}
+		buf.WriteString(field.Type)
+		if field.Tag != "" {
+			buf.WriteString(" `" + field.Tag + "`")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString("}")
+
+	for _, iface := range model.Implements {
+		fmt.Fprintf(&buf, "\n\nfunc (%s) Is%s() {}", model.Name, iface)
+	}
+	return buf.String()
+}
+
+// docComment renders text as a comment, keeping its blank lines, which
+// formatComment does not.
+func docComment(text, prefix string) string {
+	var buf strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			buf.WriteString(prefix + "//\n")
+			continue
+		}
+		buf.WriteString(prefix + "// " + line + "\n")
+	}
+	return buf.String()
+}
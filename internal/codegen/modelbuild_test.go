@@ -0,0 +1,106 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+type modelsPlugin struct {
+	mutate func(build *ModelBuild)
+}
+
+func (p modelsPlugin) Name() string { return "models" }
+
+func (p modelsPlugin) MutateModels(build *ModelBuild) error {
+	p.mutate(build)
+	return nil
+}
+
+func TestMutateModels(t *testing.T) {
+	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Components: config.Components{
+			Schemas: map[string]*config.Schema{
+				"Task": {
+					Type:        "object",
+					Description: "A task to do",
+					Properties: map[string]*config.Schema{
+						"title": {Type: "string", Description: "Title of the task"},
+					},
+				},
+				"User": {Type: "object", Properties: map[string]*config.Schema{"name": {Type: "string"}}},
+			},
+		},
+	}
+
+	generate := func(t *testing.T, mutate func(build *ModelBuild)) (string, error) {
+		t.Helper()
+		outputDir := t.TempDir()
+		gen := New(&config.Config{
+			Output: outputDir,
+			Model:  config.ModelConfig{Package: "models", Filename: "models.go"},
+		}, spec)
+		gen.AddPlugin(modelsPlugin{mutate: mutate})
+		if err := gen.Generate(StageModels); err != nil {
+			return "", err
+		}
+
+		content, err := os.ReadFile(filepath.Join(outputDir, "models.go"))
+		require.NoError(t, err, "Failed to read models.go")
+		return string(content), nil
+	}
+
+	unchanged, err := generate(t, func(*ModelBuild) {})
+	require.NoError(t, err)
+
+	t.Run("mutations", func(t *testing.T) {
+		models, err := generate(t, func(build *ModelBuild) {
+			assert.Equal(t, "models", build.PackageName)
+			require.Len(t, build.Models, 2)
+			task := build.Models[0]
+			assert.Equal(t, "Task", task.Name)
+			assert.Equal(t, "A task to do", task.Doc)
+			assert.Equal(t, &Field{Name: "Title", Type: "*string", Tag: `json:"title,omitempty"`, Doc: "Title of the task"}, task.Fields[0])
+
+			task.Fields[0].Tag += ` db:"title"`
+			task.Fields = append(task.Fields, &Field{Name: "ID", Type: "uuid.UUID", Tag: `json:"-"`, Doc: "Database key"})
+			task.Implements = []string{"Node"}
+			build.Imports = append(build.Imports, "github.com/google/uuid")
+			build.Models = append(build.Models, &Model{
+				Name:   "Page",
+				Doc:    "A page of results",
+				Fields: []*Field{{Name: "Cursor", Type: "string", Tag: `json:"cursor"`}},
+			})
+		})
+		require.NoError(t, err)
+
+		assert.Contains(t, models, `"github.com/google/uuid"`)
+		assert.Contains(t, models, "// A task to do\ntype Task struct {\n\t// Title of the task\n\tTitle *string `json:\"title,omitempty\" db:\"title\"`\n\t// Database key\n\tID uuid.UUID `json:\"-\"`\n}")
+		assert.Contains(t, models, "func (Task) IsNode() {}")
+		assert.Contains(t, models, "// A page of results\ntype Page struct {\n\tCursor string `json:\"cursor\"`\n}")
+		assert.Contains(t, models, "type User struct {\n\tName *string `json:\"name,omitempty\"`\n}")
+	})
+
+	t.Run("untouched models keep their source", func(t *testing.T) {
+		models, err := generate(t, func(build *ModelBuild) {
+			build.Models[1].Doc = "A user"
+		})
+		require.NoError(t, err)
+		assert.Contains(t, models, "// A user\ntype User struct")
+
+		assert.Equal(t, unchanged, strings.Replace(models, "// A user", "// User represents the schema", 1), "only the doc comment of User differs")
+	})
+
+	t.Run("removal", func(t *testing.T) {
+		_, err := generate(t, func(build *ModelBuild) {
+			build.Models = build.Models[1:]
+		})
+		assert.ErrorContains(t, err, "models: Task cannot be removed, map its schema to another type instead")
+	})
+}
@@ -0,0 +1,109 @@
+package codegen
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// ModelsManifest is the generation manifest written next to the models. It
+// records the hash of the code of each type of the models files, so that the
+// next generation only formats the types whose schema changed and leaves the
+// files of unchanged schemas untouched. It is a cache: removing it only
+// makes the next generation slower.
+const ModelsManifest = ".mcpgen-models.json"
+
+type modelsManifest struct {
+	// Version and GoVersion are the versions of mcpgen and of gofmt which
+	// formatted the models
+	Version   string                        `json:"version"`
+	GoVersion string                        `json:"go_version"`
+	Files     map[string]modelsManifestFile `json:"files"`
+}
+
+type modelsManifestFile struct {
+	// SHA256 is the hash of the file as written, which tells whether it
+	// was changed since
+	SHA256 string      `json:"sha256"`
+	Decls  []modelDecl `json:"decls"`
+}
+
+// modelDecl is a top-level declaration of a models file: the code of a type
+// or enum, or the schema variables, which have no Type.
+type modelDecl struct {
+	Type string `json:"type,omitempty"`
+	// SHA256 is the hash of the code of the declaration before formatting
+	SHA256 string `json:"sha256"`
+	// Size is the length of the formatted declaration, which files end with
+	Size int `json:"size"`
+}
+
+// loadModelsCache returns the formatted declarations of the models files of
+// dir by the hash of their code, as recorded by the manifest of the previous
+// generation. Files changed since, or formatted by another version of mcpgen
+// or gofmt, are left out.
+func loadModelsCache(dir string) map[string][]byte {
+	data, err := os.ReadFile(filepath.Join(dir, ModelsManifest))
+	if err != nil {
+		return nil
+	}
+	var manifest modelsManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil
+	}
+	if manifest.Version != Version || manifest.GoVersion != runtime.Version() {
+		return nil
+	}
+
+	cache := map[string][]byte{}
+	for name, file := range manifest.Files {
+		code, err := os.ReadFile(filepath.Join(dir, filepath.Base(name)))
+		if err != nil || fileHash(code) != file.SHA256 {
+			continue
+		}
+
+		// The declarations end the file, each after an empty line
+		end := len(code)
+		for i := len(file.Decls) - 1; i >= 0; i-- {
+			start := end - file.Decls[i].Size
+			if start < 1 || code[start-1] != '\n' {
+				break
+			}
+			cache[file.Decls[i].SHA256] = code[start:end]
+			end = start - 1
+		}
+	}
+	return cache
+}
+
+// writeModelsManifest writes the manifest of the models files of dir, by
+// name, holding the declarations of decls.
+func writeModelsManifest(dir string, files map[string][]byte, decls map[string][]modelDecl) error {
+	manifest := modelsManifest{
+		Version:   Version,
+		GoVersion: runtime.Version(),
+		Files:     make(map[string]modelsManifestFile, len(files)),
+	}
+	for name, code := range files {
+		manifest.Files[name] = modelsManifestFile{SHA256: fileHash(code), Decls: decls[name]}
+	}
+
+	data, err := marshalJSON(manifest, "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, ModelsManifest)
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+		return nil
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func fileHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,74 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+func TestGenerateModelsManifest(t *testing.T) {
+	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Components: config.Components{
+			Schemas: map[string]*config.Schema{
+				"Task": {
+					Type:       "object",
+					Properties: map[string]*config.Schema{"status": {Type: "string", Enum: []any{"todo", "done"}}},
+				},
+				"Project": {Type: "object", Properties: map[string]*config.Schema{"name": {Type: "string"}}},
+			},
+		},
+	}
+	generate := func(t *testing.T, outputDir string) {
+		t.Helper()
+		gen := New(&config.Config{
+			Output: outputDir,
+			Model:  config.ModelConfig{Package: "models", Filename: "models.go", Layout: config.ModelLayoutPerSchema},
+		}, spec)
+		require.NoError(t, gen.Generate(StageModels))
+	}
+
+	outputDir := t.TempDir()
+	generate(t, outputDir)
+
+	cache := loadModelsCache(outputDir)
+	assert.Len(t, cache, 3, "Task, TaskStatus and Project are cached")
+
+	// The file of an unchanged schema is not rewritten
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	for _, name := range []string{"task.go", "project.go"} {
+		require.NoError(t, os.Chtimes(filepath.Join(outputDir, name), old, old))
+	}
+	spec.Components.Schemas["Project"].Properties["owner"] = &config.Schema{Type: "string"}
+	generate(t, outputDir)
+
+	info, err := os.Stat(filepath.Join(outputDir, "task.go"))
+	require.NoError(t, err)
+	assert.Equal(t, old, info.ModTime())
+	info, err = os.Stat(filepath.Join(outputDir, "project.go"))
+	require.NoError(t, err)
+	assert.NotEqual(t, old, info.ModTime())
+
+	// Reusing the formatted types gives the files of a full generation
+	freshDir := t.TempDir()
+	generate(t, freshDir)
+	for _, name := range []string{"models.go", "task.go", "project.go"} {
+		got, err := os.ReadFile(filepath.Join(outputDir, name))
+		require.NoError(t, err)
+		want, err := os.ReadFile(filepath.Join(freshDir, name))
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(got), name)
+	}
+
+	// Edited files are not trusted
+	require.NoError(t, os.WriteFile(filepath.Join(outputDir, "task.go"), []byte("package models\n"), 0o644))
+	assert.Len(t, loadModelsCache(outputDir), 1, "only Project is cached")
+
+	require.NoError(t, os.WriteFile(filepath.Join(outputDir, ModelsManifest), []byte("{}"), 0o644))
+	assert.Empty(t, loadModelsCache(outputDir), "manifests of other versions are ignored")
+}
@@ -0,0 +1,90 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+)
+
+// generateNames writes names.go next to the server, with constants of the
+// names of the tools, resources and prompts of the spec, such as
+// ToolCreateTask, and a Registry function returning the metadata of the
+// tools, so that the code around the server does not repeat them.
+func (g *Generator) generateNames() error {
+	tmpl, err := g.parseTemplate("names.gotpl")
+	if err != nil {
+		return fmt.Errorf("failed to parse names template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := g.execute(tmpl, &buf, g.buildNamesTemplateData()); err != nil {
+		return fmt.Errorf("failed to execute names template: %w", err)
+	}
+
+	serverFile := "server.go"
+	if g.config.Exec.Filename != "" {
+		serverFile = g.config.Exec.Filename
+	}
+	namesPath := filepath.Join(g.config.Output, filepath.Dir(serverFile), "names.go")
+
+	formatted, err := g.formatSource("names", namesPath, buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format names code: %w\n%s", err, buf.String())
+	}
+
+	if err := g.writeFile(namesPath, g.withHeader(formatted)); err != nil {
+		return fmt.Errorf("failed to write names file: %w", err)
+	}
+
+	g.logf("Generated names: %s\n", namesPath)
+	return nil
+}
+
+// buildNamesTemplateData returns the data of the names template: the
+// constant and name of each tool, resource and prompt, and the description
+// and hints of the tools.
+func (g *Generator) buildNamesTemplateData() map[string]interface{} {
+	tools := make([]map[string]interface{}, 0, len(g.spec.Tools))
+	for _, tool := range g.spec.Tools {
+		toolData := map[string]interface{}{
+			"Const": "Tool" + toHandlerName(tool.Name),
+			"Name":  tool.Name,
+		}
+		if tool.Title != "" {
+			toolData["Title"] = quoteText(tool.Title)
+		}
+		if tool.Description != "" {
+			toolData["Description"] = quoteText(tool.Description)
+		}
+		if tool.Hints != nil {
+			toolData["Readonly"] = tool.Hints.Readonly
+			toolData["Destructive"] = tool.Hints.Destructive
+			toolData["Idempotent"] = tool.Hints.Idempotent
+			toolData["OpenWorld"] = tool.Hints.OpenWorld
+		}
+		tools = append(tools, toolData)
+	}
+
+	resources := make([]map[string]interface{}, 0, len(g.spec.Resources))
+	for _, resource := range g.spec.Resources {
+		resources = append(resources, map[string]interface{}{
+			"Const": "Resource" + toHandlerName(resource.Name),
+			"Name":  resource.Name,
+		})
+	}
+
+	prompts := make([]map[string]interface{}, 0, len(g.spec.Prompts))
+	for _, prompt := range g.spec.Prompts {
+		prompts = append(prompts, map[string]interface{}{
+			"Const": "Prompt" + toHandlerName(prompt.Name),
+			"Name":  prompt.Name,
+		})
+	}
+
+	return map[string]interface{}{
+		"Package":   g.config.Exec.Package,
+		"Tools":     tools,
+		"Resources": resources,
+		"Prompts":   prompts,
+	}
+}
@@ -0,0 +1,53 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+func TestGenerateNames(t *testing.T) {
+	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Tools: []config.Tool{
+			{Name: "create_task", Title: "Create task", Description: "Creates a task.", NoInput: true, Hints: &config.ToolHints{Destructive: true, OpenWorld: true}},
+			{Name: "list_tasks", NoInput: true, Hints: &config.ToolHints{Readonly: true, Idempotent: true}},
+		},
+		Resources: []config.Resource{{Name: "task-list", URI: "tasks://all"}},
+		Prompts:   []config.Prompt{{Name: "summarize"}},
+	}
+	require.NoError(t, spec.Validate())
+
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Output:   outputDir,
+		Exec:     config.ExecConfig{Package: "test", Filename: "server/server.go"},
+		Model:    config.ModelConfig{Package: "test", Filename: "models.go"},
+		Resolver: config.ResolverConfig{Package: "test", Filename: "resolver.go", Type: "Resolver"},
+	}
+	require.NoError(t, New(cfg, spec).Generate(StageServer))
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "server", "names.go"))
+	require.NoError(t, err)
+	code := string(content)
+
+	assert.Contains(t, code, "const (\n\tToolCreateTask = \"create_task\"\n\tToolListTasks  = \"list_tasks\"\n)")
+	assert.Contains(t, code, "ResourceTaskList = \"task-list\"")
+	assert.Contains(t, code, "PromptSummarize = \"summarize\"")
+	assert.Contains(t, code, `		{
+			Name:        ToolCreateTask,
+			Title:       "Create task",
+			Description: "Creates a task.",
+			Destructive: true,
+			OpenWorld:   true,
+		},
+		{
+			Name:       ToolListTasks,
+			ReadOnly:   true,
+			Idempotent: true,
+		},`)
+}
@@ -0,0 +1,197 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// npmPlatform and npmArch are the process.platform and process.arch of
+// Node.js of the GOOS and GOARCH of the artifacts.
+var (
+	npmPlatform = map[string]string{
+		"darwin":  "darwin",
+		"freebsd": "freebsd",
+		"linux":   "linux",
+		"windows": "win32",
+	}
+	npmArch = map[string]string{
+		"386":   "ia32",
+		"amd64": "x64",
+		"arm":   "arm",
+		"arm64": "arm64",
+	}
+)
+
+// npmLauncher is the script the npm package runs with npx. It downloads the
+// archive of the platform on its first run, checks its checksum, extracts
+// the binary with tar, which unpacks zip archives on Windows too, and runs
+// it with the standard streams of the script. It writes nothing to stdout,
+// which carries the MCP messages.
+var npmLauncher = template.Must(template.New("launcher").Funcs(template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := marshalJSON(v, "")
+		return strings.TrimSuffix(string(data), "\n"), err
+	},
+}).Parse(`#!/usr/bin/env node
+// Launcher of the {{.Name}} MCP server {{.Version}}, generated by mcpgen release.
+"use strict";
+
+const { execFileSync, spawn } = require("node:child_process");
+const crypto = require("node:crypto");
+const fs = require("node:fs");
+const path = require("node:path");
+
+const name = {{json .Name}};
+const version = {{json .Version}};
+const downloadURL = {{json .DownloadURL}};
+const archives = {
+{{- range .Archives}}
+  {{json .Platform}}: { file: {{json .File}}, sha256: {{json .SHA256}} },
+{{- end}}
+};
+
+async function install(binary) {
+  const archive = archives[process.platform + "-" + process.arch];
+  if (!archive) {
+    throw new Error(name + " " + version + " is not built for " + process.platform + "/" + process.arch);
+  }
+
+  process.stderr.write("Downloading " + name + " " + version + " for " + process.platform + "/" + process.arch + "...\n");
+  const res = await fetch(downloadURL + "/" + archive.file);
+  if (!res.ok) {
+    throw new Error("cannot download " + archive.file + ": HTTP " + res.status);
+  }
+  const data = Buffer.from(await res.arrayBuffer());
+  const sum = crypto.createHash("sha256").update(data).digest("hex");
+  if (sum !== archive.sha256) {
+    throw new Error("checksum mismatch for " + archive.file + ": got " + sum + ", want " + archive.sha256);
+  }
+
+  const dir = path.dirname(binary);
+  fs.mkdirSync(dir, { recursive: true });
+  const tmp = fs.mkdtempSync(path.join(dir, ".download-"));
+  try {
+    fs.writeFileSync(path.join(tmp, archive.file), data);
+    execFileSync("tar", ["-xf", archive.file], { cwd: tmp, stdio: ["ignore", "ignore", "inherit"] });
+    fs.renameSync(path.join(tmp, path.basename(binary)), binary);
+  } finally {
+    fs.rmSync(tmp, { recursive: true, force: true });
+  }
+}
+
+async function main() {
+  const executable = process.platform === "win32" ? name + ".exe" : name;
+  const binary = path.join(__dirname, "..", "vendor", version, process.platform + "-" + process.arch, executable);
+  if (!fs.existsSync(binary)) {
+    await install(binary);
+  }
+
+  const child = spawn(binary, process.argv.slice(2), { stdio: "inherit" });
+  for (const signal of ["SIGINT", "SIGTERM"]) {
+    process.on(signal, () => child.kill(signal));
+  }
+  child.on("exit", (code, signal) => {
+    if (signal) {
+      process.kill(process.pid, signal);
+    } else {
+      process.exit(code ?? 1);
+    }
+  });
+}
+
+main().catch((err) => {
+  process.stderr.write(name + ": " + err.message + "\n");
+  process.exit(1);
+});
+`))
+
+// writeNPMPackage writes to dir the npm package opts.NPMPackage launching
+// the binaries of the artifacts, downloaded from opts.DownloadURL, which
+// clients run with npx.
+func writeNPMPackage(dir string, opts ReleaseOptions, description string, artifacts []Artifact) error {
+	type archive struct {
+		Platform string
+		File     string
+		SHA256   string
+	}
+	data := struct {
+		Name        string
+		Version     string
+		DownloadURL string
+		Archives    []archive
+	}{
+		Name:        opts.Name,
+		Version:     opts.Version,
+		DownloadURL: strings.TrimSuffix(opts.DownloadURL, "/"),
+	}
+	for _, artifact := range artifacts {
+		platform, okPlatform := npmPlatform[artifact.OS]
+		arch, okArch := npmArch[artifact.Arch]
+		if okPlatform && okArch {
+			data.Archives = append(data.Archives, archive{
+				Platform: platform + "-" + arch,
+				File:     filepath.Base(artifact.Path),
+				SHA256:   artifact.SHA256,
+			})
+		}
+	}
+	if len(data.Archives) == 0 {
+		return fmt.Errorf("no target supported by Node.js for the npm package")
+	}
+
+	var launcher bytes.Buffer
+	if err := npmLauncher.Execute(&launcher, data); err != nil {
+		return err
+	}
+
+	launcherPath := "bin/" + opts.Name + ".js"
+	pkg := struct {
+		Name        string            `json:"name"`
+		Version     string            `json:"version"`
+		Description string            `json:"description,omitempty"`
+		Homepage    string            `json:"homepage,omitempty"`
+		Bin         map[string]string `json:"bin"`
+		Files       []string          `json:"files"`
+		Engines     map[string]string `json:"engines"`
+	}{
+		Name:        opts.NPMPackage,
+		Version:     strings.TrimPrefix(opts.Version, "v"),
+		Description: description,
+		Homepage:    opts.Homepage,
+		Bin:         map[string]string{opts.Name: launcherPath},
+		Files:       []string{"bin"},
+		// For fetch
+		Engines: map[string]string{"node": ">=18"},
+	}
+	manifest, err := marshalJSON(pkg, "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "bin"), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), manifest, 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, filepath.FromSlash(launcherPath)), launcher.Bytes(), 0o755)
+}
+
+// marshalJSON encodes v as JSON indented with indent, followed by a newline,
+// leaving the characters significant in HTML, such as the > of version
+// ranges, unescaped.
+func marshalJSON(v any, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", indent)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,55 @@
+package codegen
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteNPMPackage(t *testing.T) {
+	opts := ReleaseOptions{
+		Name:        "tasks",
+		Version:     "v1.2.0",
+		DownloadURL: "https://github.com/acme/tasks/releases/download/v1.2.0/",
+		NPMPackage:  "@acme/tasks",
+	}
+	dir := filepath.Join(t.TempDir(), "npm")
+	require.NoError(t, writeNPMPackage(dir, opts, "Manage tasks", manifestArtifacts))
+
+	pkg, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "@acme/tasks",
+		"version": "1.2.0",
+		"description": "Manage tasks",
+		"bin": {"tasks": "bin/tasks.js"},
+		"files": ["bin"],
+		"engines": {"node": ">=18"}
+	}`, string(pkg))
+	assert.Contains(t, string(pkg), `">=18"`, "version ranges are not escaped")
+
+	launcherPath := filepath.Join(dir, "bin", "tasks.js")
+	launcher, err := os.ReadFile(launcherPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(launcher), `const downloadURL = "https://github.com/acme/tasks/releases/download/v1.2.0";`)
+	assert.Contains(t, string(launcher), `"darwin-arm64": { file: "tasks_1.2.0_darwin_arm64.tar.gz", sha256: "aa" },`)
+	assert.Contains(t, string(launcher), `"linux-x64": { file: "tasks_1.2.0_linux_amd64.tar.gz", sha256: "bb" },`)
+	assert.Contains(t, string(launcher), `"win32-x64": { file: "tasks_1.2.0_windows_amd64.zip", sha256: "dd" },`)
+	assert.NotContains(t, string(launcher), "riscv64", "only the platforms of npmPlatform and npmArch are launched")
+
+	info, err := os.Stat(launcherPath)
+	require.NoError(t, err)
+	assert.NotZero(t, info.Mode()&0o100, "the launcher is executable")
+
+	if node, err := exec.LookPath("node"); err == nil {
+		out, err := exec.Command(node, "--check", launcherPath).CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+
+	err = writeNPMPackage(dir, opts, "", manifestArtifacts[2:3])
+	assert.EqualError(t, err, "no target supported by Node.js for the npm package")
+}
@@ -0,0 +1,448 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"slices"
+
+	"go.probo.inc/mcpgen/internal/config"
+	"go.probo.inc/mcpgen/internal/schema"
+	mcputil "go.probo.inc/mcpgen/mcp"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	jsonRPCVersion  = "2.0"
+	sessionIDHeader = "Mcp-Session-Id"
+	toolsCallMethod = "tools/call"
+	openAPIVersion  = "3.1.0"
+)
+
+type openAPIDocument struct {
+	OpenAPI    string                                 `json:"openapi"`
+	Info       openAPIInfo                            `json:"info"`
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components openAPIComponents                      `json:"components"`
+}
+
+type openAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type openAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	Summary     string                     `json:"summary"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name        string         `json:"name"`
+	In          string         `json:"in"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      *config.Schema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                      `json:"description"`
+	Headers     map[string]openAPIHeader    `json:"headers,omitempty"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIHeader struct {
+	Description string         `json:"description,omitempty"`
+	Schema      *config.Schema `json:"schema"`
+}
+
+type openAPIMediaType struct {
+	Schema *config.Schema `json:"schema"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]*config.Schema `json:"schemas"`
+}
+
+// generateOpenAPI writes an OpenAPI document describing the streamable HTTP
+// transport served by the generated Run function. Every tool gets a request
+// schema for its tools/call message, so gateways can validate tool arguments
+// and clients can be generated in other languages.
+func (g *Generator) generateOpenAPI() error {
+	doc, err := g.buildOpenAPIDocument()
+	if err != nil {
+		return err
+	}
+
+	code, err := encodeOpenAPIDocument(doc, filepath.Ext(g.config.Exec.OpenAPI.Filename))
+	if err != nil {
+		return err
+	}
+
+	openAPIPath := filepath.Join(g.config.Output, g.config.Exec.OpenAPI.Filename)
+
+	if err := g.writeFile(openAPIPath, code); err != nil {
+		return fmt.Errorf("failed to write OpenAPI document: %w", err)
+	}
+
+	g.logf("Generated OpenAPI document: %s\n", openAPIPath)
+	return nil
+}
+
+func (g *Generator) buildOpenAPIDocument() (*openAPIDocument, error) {
+	schemas := map[string]*config.Schema{
+		"JSONRPCMessage": {
+			Type:        "object",
+			Description: "Any JSON-RPC message other than a tool call: initialization, list and read requests, notifications and responses.",
+			Properties: map[string]*config.Schema{
+				"jsonrpc": constSchema(jsonRPCVersion),
+				"id":      {Types: []string{"string", "integer"}},
+				"method":  {Type: "string"},
+				"params":  {Type: "object"},
+				"result":  {Type: "object"},
+				"error":   {Ref: "#/components/schemas/JSONRPCError"},
+			},
+			Required: []string{"jsonrpc"},
+			Not: &config.Schema{
+				Properties: map[string]*config.Schema{"method": constSchema(toolsCallMethod)},
+				Required:   []string{"method"},
+			},
+		},
+		"JSONRPCResponse": {
+			Type: "object",
+			Properties: map[string]*config.Schema{
+				"jsonrpc": constSchema(jsonRPCVersion),
+				"id":      {Types: []string{"string", "integer"}},
+				"result":  {Type: "object"},
+				"error":   {Ref: "#/components/schemas/JSONRPCError"},
+			},
+			Required: []string{"jsonrpc", "id"},
+		},
+		"JSONRPCError": {
+			Type: "object",
+			Properties: map[string]*config.Schema{
+				"code":    {Type: "integer"},
+				"message": {Type: "string"},
+				"data":    {},
+			},
+			Required: []string{"code", "message"},
+		},
+	}
+
+	messages := make([]*config.Schema, 0, len(g.spec.Tools)+1)
+	for _, tool := range g.spec.Tools {
+		inputSchema := &config.Schema{Type: "object"}
+		if tool.InputSchema != nil && !tool.TakesNoInput() {
+			resolved, err := g.resolveRefs(tool.InputSchema)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve input schema for tool %s: %w", tool.Name, err)
+			}
+			inputSchema = resolved
+		}
+
+		title := tool.Title
+		if title == "" {
+			title = tool.Name
+		}
+
+		callName := toPascalCase(tool.Name) + "ToolCall"
+		schemas[callName] = &config.Schema{
+			Type:        "object",
+			Title:       title,
+			Description: tool.Description,
+			Properties: map[string]*config.Schema{
+				"jsonrpc": constSchema(jsonRPCVersion),
+				"id":      {Types: []string{"string", "integer"}},
+				"method":  constSchema(toolsCallMethod),
+				"params": {
+					Type: "object",
+					Properties: map[string]*config.Schema{
+						"name":      constSchema(tool.Name),
+						"arguments": inputSchema,
+					},
+					Required: []string{"name", "arguments"},
+				},
+			},
+			Required: []string{"jsonrpc", "id", "method", "params"},
+		}
+		if tool.Icon != "" {
+			schemas[callName].Extra = map[string]any{"x-icon": tool.Icon}
+		}
+		messages = append(messages, &config.Schema{Ref: "#/components/schemas/" + callName})
+
+		if tool.OutputSchema != nil {
+			outputSchema, err := g.resolveRefs(tool.OutputSchema)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve output schema for tool %s: %w", tool.Name, err)
+			}
+
+			schemas[toPascalCase(tool.Name)+"ToolResult"] = &config.Schema{
+				Type:        "object",
+				Title:       title,
+				Description: "Result of the " + tool.Name + " tool.",
+				Properties: map[string]*config.Schema{
+					"content":           {Type: "array", Items: &config.Schema{Type: "object"}},
+					"structuredContent": outputSchema,
+					"isError":           {Type: "boolean"},
+				},
+				Required: []string{"content"},
+			}
+		}
+	}
+	messages = append(messages, &config.Schema{Ref: "#/components/schemas/JSONRPCMessage"})
+	schemas["Message"] = &config.Schema{OneOf: messages}
+
+	sessionHeader := openAPIParameter{
+		Name:        sessionIDHeader,
+		In:          "header",
+		Description: "Session identifier returned by the initialize response.",
+		Schema:      &config.Schema{Type: "string"},
+	}
+	eventStream := map[string]openAPIMediaType{
+		"text/event-stream": {Schema: &config.Schema{Type: "string"}},
+	}
+	notFound := openAPIResponse{Description: "Session not found"}
+
+	path := g.config.Exec.OpenAPI.Path
+	if path == "" {
+		path = mcputil.DefaultHTTPPath
+	}
+
+	return &openAPIDocument{
+		OpenAPI: openAPIVersion,
+		Info: openAPIInfo{
+			Title:       g.spec.Info.Title,
+			Version:     g.spec.Info.Version,
+			Description: g.spec.Info.Description,
+		},
+		Paths: map[string]map[string]openAPIOperation{
+			path: {
+				"post": {
+					OperationID: "postMessage",
+					Summary:     "Send a JSON-RPC message to the MCP server",
+					Parameters:  []openAPIParameter{sessionHeader},
+					RequestBody: &openAPIRequestBody{
+						Required: true,
+						Content: map[string]openAPIMediaType{
+							"application/json": {Schema: &config.Schema{Ref: "#/components/schemas/Message"}},
+						},
+					},
+					Responses: map[string]openAPIResponse{
+						"200": {
+							Description: "JSON-RPC response, or a stream of messages ending with the response",
+							Headers: map[string]openAPIHeader{
+								sessionIDHeader: {Description: "Session identifier, set on the initialize response.", Schema: &config.Schema{Type: "string"}},
+							},
+							Content: map[string]openAPIMediaType{
+								"application/json":  {Schema: &config.Schema{Ref: "#/components/schemas/JSONRPCResponse"}},
+								"text/event-stream": eventStream["text/event-stream"],
+							},
+						},
+						"202": {Description: "Notification or response accepted"},
+						"400": {Description: "Malformed message"},
+						"404": notFound,
+					},
+				},
+				"get": {
+					OperationID: "openEventStream",
+					Summary:     "Open a stream of server-initiated messages",
+					Parameters:  []openAPIParameter{withRequired(sessionHeader)},
+					Responses: map[string]openAPIResponse{
+						"200": {Description: "Server-sent events stream", Content: eventStream},
+						"404": notFound,
+						"405": {Description: "No active session"},
+					},
+				},
+				"delete": {
+					OperationID: "terminateSession",
+					Summary:     "Terminate the session",
+					Parameters:  []openAPIParameter{withRequired(sessionHeader)},
+					Responses: map[string]openAPIResponse{
+						"204": {Description: "Session terminated"},
+						"404": notFound,
+					},
+				},
+			},
+		},
+		Components: openAPIComponents{Schemas: schemas},
+	}, nil
+}
+
+// encodeOpenAPIDocument writes the document as YAML or JSON depending on ext.
+// Going through JSON keeps the field order of the document types and the
+// JSON Schema marshalling of the schemas.
+func encodeOpenAPIDocument(doc *openAPIDocument, ext string) ([]byte, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal OpenAPI document: %w", err)
+	}
+
+	// JSON is valid YAML: decoding into a node keeps the key order, which
+	// the properties of the schemas recording their spec order then follow
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("failed to convert OpenAPI document: %w", err)
+	}
+	orderProperties(&node)
+
+	switch ext {
+	case ".json":
+		var compact bytes.Buffer
+		if err := writeNodeJSON(&compact, node.Content[0]); err != nil {
+			return nil, fmt.Errorf("failed to encode OpenAPI document: %w", err)
+		}
+		var out bytes.Buffer
+		if err := json.Indent(&out, compact.Bytes(), "", "  "); err != nil {
+			return nil, fmt.Errorf("failed to indent OpenAPI document: %w", err)
+		}
+		out.WriteByte('\n')
+		return out.Bytes(), nil
+	case ".yaml", ".yml":
+		clearStyle(&node)
+
+		var out bytes.Buffer
+		enc := yaml.NewEncoder(&out)
+		enc.SetIndent(2)
+		if err := enc.Encode(&node); err != nil {
+			return nil, fmt.Errorf("failed to encode OpenAPI document: %w", err)
+		}
+		if err := enc.Close(); err != nil {
+			return nil, fmt.Errorf("failed to encode OpenAPI document: %w", err)
+		}
+		return out.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unsupported OpenAPI file format: %s (use .yaml, .yml, or .json)", ext)
+	}
+}
+
+// clearStyle switches nodes decoded from JSON to block style, keeping quotes
+// only where a string would otherwise be read as another type.
+func clearStyle(n *yaml.Node) {
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!str" {
+		n.Style = 0
+	} else {
+		n.Style &^= yaml.FlowStyle | yaml.DoubleQuotedStyle
+	}
+	for _, c := range n.Content {
+		clearStyle(c)
+	}
+}
+
+// orderProperties moves the properties of the schemas of n recording their
+// spec order into that order, and removes the annotation.
+func orderProperties(n *yaml.Node) {
+	if n.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value != schema.PropertyOrderKey {
+				continue
+			}
+			order := make(map[string]int, len(n.Content[i+1].Content))
+			for j, name := range n.Content[i+1].Content {
+				order[name.Value] = j
+			}
+			n.Content = slices.Delete(n.Content, i, i+2)
+			if properties := mappingValue(n, "properties"); properties != nil {
+				sortPairs(properties, order)
+			}
+			break
+		}
+	}
+	for _, c := range n.Content {
+		orderProperties(c)
+	}
+}
+
+// mappingValue returns the mapping at key in the mapping n, or nil.
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key && n.Content[i+1].Kind == yaml.MappingNode {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// sortPairs stably sorts the pairs of the mapping n by the index of their
+// key in order, keys missing from it last.
+func sortPairs(n *yaml.Node, order map[string]int) {
+	type pair struct{ key, value *yaml.Node }
+	pairs := make([]pair, 0, len(n.Content)/2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		pairs = append(pairs, pair{n.Content[i], n.Content[i+1]})
+	}
+	index := func(p pair) int {
+		if j, ok := order[p.key.Value]; ok {
+			return j
+		}
+		return len(order)
+	}
+	slices.SortStableFunc(pairs, func(a, b pair) int { return index(a) - index(b) })
+	n.Content = n.Content[:0]
+	for _, p := range pairs {
+		n.Content = append(n.Content, p.key, p.value)
+	}
+}
+
+// writeNodeJSON writes n, decoded from JSON, back as compact JSON.
+func writeNodeJSON(buf *bytes.Buffer, n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeNodeJSON(buf, n.Content[i]); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := writeNodeJSON(buf, n.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, c := range n.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeNodeJSON(buf, c); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case yaml.ScalarNode:
+		if n.Tag != "!!str" {
+			// Numbers, booleans and null keep their JSON text
+			buf.WriteString(n.Value)
+			return nil
+		}
+		data, err := json.Marshal(n.Value)
+		if err != nil {
+			return err
+		}
+		buf.Write(data)
+	default:
+		return fmt.Errorf("unexpected YAML node of kind %d", n.Kind)
+	}
+	return nil
+}
+
+func constSchema(v any) *config.Schema {
+	return &config.Schema{Const: &v}
+}
+
+func withRequired(p openAPIParameter) openAPIParameter {
+	p.Required = true
+	return p
+}
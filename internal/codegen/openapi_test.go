@@ -0,0 +1,169 @@
+package codegen
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.probo.inc/mcpgen/internal/config"
+	"go.probo.inc/mcpgen/internal/schema"
+)
+
+func TestGenerateOpenAPI(t *testing.T) {
+	specPath := filepath.Join("testdata", "config_based_types.yaml")
+	spec, err := config.LoadMCPSpec(specPath)
+	require.NoError(t, err, "Failed to load spec")
+
+	spec.Tools[0].OutputSchema = &config.Schema{Ref: "#/components/schemas/User"}
+	spec.Tools[0].Title = "Create an event"
+	spec.Tools[0].Icon = "https://example.com/event.svg"
+
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Spec:   specPath,
+		Output: outputDir,
+		Exec: config.ExecConfig{
+			Package:  "test",
+			Filename: "server.go",
+			OpenAPI: config.OpenAPIConfig{
+				Filename: "openapi.json",
+				Path:     "/rpc",
+			},
+		},
+		Model: config.ModelConfig{
+			Package:  "test",
+			Filename: "models.go",
+		},
+		Resolver: config.ResolverConfig{
+			Package:  "test",
+			Filename: "resolver.go",
+			Type:     "Resolver",
+		},
+	}
+
+	require.NoError(t, New(cfg, spec).Generate())
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "openapi.json"))
+	require.NoError(t, err, "Failed to read openapi.json")
+
+	var doc struct {
+		OpenAPI string `json:"openapi"`
+		Info    struct {
+			Title string `json:"title"`
+		} `json:"info"`
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]*config.Schema `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(content, &doc))
+
+	assert.Equal(t, "3.1.0", doc.OpenAPI)
+	assert.Equal(t, "config-based-test", doc.Info.Title)
+	require.Contains(t, doc.Paths, "/rpc")
+	assert.Len(t, doc.Paths["/rpc"], 3)
+
+	schemas := doc.Components.Schemas
+	require.Contains(t, schemas, "Message")
+	require.Len(t, schemas["Message"].OneOf, 2)
+	assert.Equal(t, "#/components/schemas/CreateEventToolCall", schemas["Message"].OneOf[0].Ref)
+	assert.Equal(t, "#/components/schemas/JSONRPCMessage", schemas["Message"].OneOf[1].Ref)
+
+	call := schemas["CreateEventToolCall"]
+	require.NotNil(t, call)
+	assert.Equal(t, "Create an event", call.Title)
+	assert.Equal(t, "https://example.com/event.svg", call.Extra["x-icon"])
+	params := call.Properties["params"]
+	require.NotNil(t, params)
+	assert.Equal(t, "create_event", *params.Properties["name"].Const)
+
+	// References are resolved so the document is self-contained
+	arguments := params.Properties["arguments"]
+	assert.Equal(t, "object", arguments.Type)
+	assert.Equal(t, "date-time", arguments.Properties["createdAt"].Format)
+	assert.Equal(t, "object", arguments.Properties["owner"].Type)
+
+	result := schemas["CreateEventToolResult"]
+	require.NotNil(t, result)
+	assert.Equal(t, []string{"id", "name"}, result.Properties["structuredContent"].Required)
+}
+
+func TestEncodeOpenAPIDocumentYAML(t *testing.T) {
+	doc := &openAPIDocument{
+		OpenAPI: openAPIVersion,
+		Info:    openAPIInfo{Title: "test", Version: "1.0.0"},
+		Paths:   map[string]map[string]openAPIOperation{},
+		Components: openAPIComponents{
+			Schemas: map[string]*config.Schema{"Version": constSchema(jsonRPCVersion)},
+		},
+	}
+
+	got, err := encodeOpenAPIDocument(doc, ".yaml")
+	require.NoError(t, err)
+
+	want := `openapi: 3.1.0
+info:
+  title: test
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    Version:
+      const: "2.0"
+`
+	assert.Equal(t, want, string(got))
+
+	_, err = encodeOpenAPIDocument(doc, ".txt")
+	assert.Error(t, err)
+}
+
+func TestEncodeOpenAPIDocumentPropertyOrder(t *testing.T) {
+	doc := &openAPIDocument{
+		OpenAPI: openAPIVersion,
+		Info:    openAPIInfo{Title: "test", Version: "1.0.0"},
+		Paths:   map[string]map[string]openAPIOperation{},
+		Components: openAPIComponents{
+			Schemas: map[string]*config.Schema{"Task": {
+				Type: "object",
+				Properties: map[string]*config.Schema{
+					"title": {Type: "string", Description: "<b>Title</b>"},
+					"id":    {Type: "integer", Minimum: jsonschema.Ptr(1.5)},
+				},
+				Extra: map[string]any{schema.PropertyOrderKey: []any{"title", "id"}},
+			}},
+		},
+	}
+
+	got, err := encodeOpenAPIDocument(doc, ".yaml")
+	require.NoError(t, err)
+	assert.Contains(t, string(got), `    Task:
+      type: object
+      properties:
+        title:
+          type: string
+          description: <b>Title</b>
+        id:
+          type: integer
+          minimum: 1.5
+`)
+	assert.NotContains(t, string(got), schema.PropertyOrderKey)
+
+	got, err = encodeOpenAPIDocument(doc, ".json")
+	require.NoError(t, err)
+	assert.Contains(t, string(got), `"properties": {
+          "title": {
+            "type": "string",
+            "description": "\u003cb\u003eTitle\u003c/b\u003e"
+          },
+          "id": {
+            "type": "integer",
+            "minimum": 1.5
+          }
+        }`)
+	assert.NotContains(t, string(got), schema.PropertyOrderKey)
+}
@@ -0,0 +1,41 @@
+package codegen
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// parallel calls fn with every index below n on as many goroutines as there
+// are CPUs, for the schemas and files of large specs to be generated
+// concurrently. fn must only write the state of its index, such as the i-th
+// element of a slice, for the output to be in the order of the indexes. The
+// error of the lowest failing index is returned, whatever the scheduling.
+func parallel(trace *Trace, n int, fn func(i int) error) error {
+	defer trace.Concurrent()()
+
+	errs := make([]error, n)
+	var (
+		next atomic.Int64
+		wg   sync.WaitGroup
+	)
+	for range min(runtime.GOMAXPROCS(0), n) {
+		wg.Go(func() {
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= n {
+					return
+				}
+				errs[i] = fn(i)
+			}
+		})
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
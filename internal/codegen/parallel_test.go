@@ -0,0 +1,30 @@
+package codegen
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParallel(t *testing.T) {
+	squares := make([]int, 100)
+	require.NoError(t, parallel(nil, len(squares), func(i int) error {
+		squares[i] = i * i
+		return nil
+	}))
+	for i, square := range squares {
+		assert.Equal(t, i*i, square)
+	}
+
+	err := parallel(nil, 100, func(i int) error {
+		if i%10 == 7 {
+			return fmt.Errorf("task %d failed", i)
+		}
+		return nil
+	})
+	assert.EqualError(t, err, "task 7 failed", "the error of the lowest failing index is returned")
+
+	assert.NoError(t, parallel(nil, 0, func(int) error { return nil }))
+}
@@ -15,12 +15,20 @@ type HandlerInfo struct {
 	RecvType   string
 	SourceCode string
 	IsOrphaned bool
+
+	// Body is the source of the function body, braces included, and Start
+	// and End the byte offsets of the declaration in the file, without its
+	// doc comment.
+	Body  string
+	Start int
+	End   int
 }
 
 type ResolverParser struct {
 	filePath string
 	fset     *token.FileSet
 	file     *ast.File
+	src      []byte
 }
 
 func NewResolverParser(filePath string) (*ResolverParser, error) {
@@ -30,7 +38,12 @@ func NewResolverParser(filePath string) (*ResolverParser, error) {
 		return nil, fmt.Errorf("resolver file not found: %s", filePath)
 	}
 
-	file, err := parser.ParseFile(fset, filePath, nil, parser.ParseComments)
+	src, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resolver file: %w", err)
+	}
+
+	file, err := parser.ParseFile(fset, filePath, src, parser.ParseComments)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse resolver file: %w", err)
 	}
@@ -39,6 +52,7 @@ func NewResolverParser(filePath string) (*ResolverParser, error) {
 		filePath: filePath,
 		fset:     fset,
 		file:     file,
+		src:      src,
 	}, nil
 }
 
@@ -83,12 +97,18 @@ func (p *ResolverParser) ExtractHandlers(resolverType string) (map[string]*Handl
 		// Transform receiver type from old wrapper types to main Resolver type
 		sourceCode = TransformReceiverType(sourceCode, resolverType)
 
-		handlers[methodName] = &HandlerInfo{
+		handler := &HandlerInfo{
 			Name:       methodName,
 			RecvType:   "*" + resolverType, // Always use main Resolver type
 			SourceCode: sourceCode,
 			IsOrphaned: false,
+			Start:      p.fset.Position(funcDecl.Pos()).Offset,
+			End:        p.fset.Position(funcDecl.End()).Offset,
+		}
+		if funcDecl.Body != nil {
+			handler.Body = string(p.src[p.fset.Position(funcDecl.Body.Lbrace).Offset:handler.End])
 		}
+		handlers[methodName] = handler
 	}
 
 	return handlers, nil
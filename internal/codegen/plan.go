@@ -0,0 +1,154 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileAction is what generation does to a file.
+type FileAction string
+
+const (
+	FileCreate    FileAction = "create"
+	FileUpdate    FileAction = "update"
+	FileUnchanged FileAction = "unchanged"
+	FileDelete    FileAction = "delete"
+)
+
+// PlannedFile is a file generation would write, with the resolver handlers
+// it would add, orphan, restore from the orphaned section or rename.
+type PlannedFile struct {
+	Path             string
+	Action           FileAction
+	AddedHandlers    []string
+	OrphanedHandlers []string
+	RestoredHandlers []string
+	RenamedHandlers  []string
+}
+
+// Plan lists the files generation would touch, in generation order.
+type Plan struct {
+	Files []*PlannedFile
+}
+
+// Changed returns the files that would be created, updated or deleted.
+func (p *Plan) Changed() []*PlannedFile {
+	var changed []*PlannedFile
+	for _, f := range p.Files {
+		if f.Action != FileUnchanged {
+			changed = append(changed, f)
+		}
+	}
+	return changed
+}
+
+func (p *Plan) file(path string) *PlannedFile {
+	for _, f := range p.Files {
+		if f.Path == path {
+			return f
+		}
+	}
+	return nil
+}
+
+// Plan runs the generation without writing any file and returns what would be
+// created, updated, deleted or left untouched. Stages can be selected as with
+// Generate.
+func (g *Generator) Plan(only ...string) (*Plan, error) {
+	g.dryRun = true
+	g.plan = &Plan{}
+	defer func() { g.dryRun = false }()
+
+	if err := g.Generate(only...); err != nil {
+		return nil, err
+	}
+
+	return g.plan, nil
+}
+
+// Check runs the generation without writing any file and returns the paths
+// of the generated files that are missing or differ from the ones on disk.
+// Stages can be selected as with Generate.
+func (g *Generator) Check(only ...string) ([]string, error) {
+	plan, err := g.Plan(only...)
+	if err != nil {
+		return nil, err
+	}
+
+	var stale []string
+	for _, f := range plan.Changed() {
+		stale = append(stale, f.Path)
+	}
+
+	return stale, nil
+}
+
+// writeFile writes a generated file, creating its directory if needed. In
+// dry-run mode the file is only compared with the one on disk.
+func (g *Generator) writeFile(path string, data []byte) error {
+	defer g.trace.Start("write " + path)()
+
+	if g.dryRun {
+		existing, err := os.ReadFile(path)
+		switch {
+		case os.IsNotExist(err):
+			g.plan.Files = append(g.plan.Files, &PlannedFile{Path: path, Action: FileCreate})
+		case err != nil:
+			return err
+		case bytes.Equal(existing, data):
+			g.plan.Files = append(g.plan.Files, &PlannedFile{Path: path, Action: FileUnchanged})
+		default:
+			g.plan.Files = append(g.plan.Files, &PlannedFile{Path: path, Action: FileUpdate})
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
+
+// removeFile removes a file generation no longer produces. In dry-run mode
+// the removal is only recorded in the plan.
+func (g *Generator) removeFile(path string) error {
+	if g.dryRun {
+		g.plan.Files = append(g.plan.Files, &PlannedFile{Path: path, Action: FileDelete})
+		return nil
+	}
+	if err := os.Remove(path); err != nil {
+		return err
+	}
+	g.logf("Removed stale file: %s\n", path)
+	return nil
+}
+
+// keepFile records in the plan a file that generation leaves untouched.
+func (g *Generator) keepFile(path string) {
+	if g.dryRun {
+		g.plan.Files = append(g.plan.Files, &PlannedFile{Path: path, Action: FileUnchanged})
+	}
+}
+
+// planHandlers records the resolver handler changes of a planned file.
+func (g *Generator) planHandlers(path string, added, orphaned, restored, renamed []string) {
+	if !g.dryRun {
+		return
+	}
+	if f := g.plan.file(path); f != nil {
+		f.AddedHandlers = added
+		f.OrphanedHandlers = orphaned
+		f.RestoredHandlers = restored
+		f.RenamedHandlers = renamed
+	}
+}
+
+// logf prints a progress message, except in dry-run mode.
+func (g *Generator) logf(format string, args ...interface{}) {
+	if !g.dryRun {
+		fmt.Printf(format, args...)
+	}
+}
@@ -0,0 +1,77 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+func TestPlan(t *testing.T) {
+	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Tools: []config.Tool{
+			{Name: "ping", InputSchema: &config.Schema{Type: "object"}},
+			{Name: "echo", InputSchema: &config.Schema{Type: "object"}},
+		},
+	}
+
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Output: outputDir,
+		Exec: config.ExecConfig{
+			Package:  "test",
+			Filename: "server.go",
+		},
+		Model: config.ModelConfig{
+			Package:  "test",
+			Filename: "models.go",
+		},
+		Resolver: config.ResolverConfig{
+			Package:  "test",
+			Filename: "resolver.go",
+			Type:     "Resolver",
+			Preserve: true,
+		},
+	}
+
+	resolversPath := filepath.Join(outputDir, "schema.resolvers.go")
+
+	plan, err := New(cfg, spec).Plan()
+	require.NoError(t, err)
+	require.Len(t, plan.Files, 5)
+	for _, f := range plan.Files {
+		assert.Equal(t, FileCreate, f.Action, f.Path)
+	}
+	assert.Equal(t, []string{"PingTool", "EchoTool"}, plan.file(resolversPath).AddedHandlers)
+
+	entries, err := os.ReadDir(outputDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "Plan should not write files")
+
+	require.NoError(t, New(cfg, spec).Generate())
+
+	plan, err = New(cfg, spec).Plan()
+	require.NoError(t, err)
+	assert.Empty(t, plan.Changed())
+
+	spec.Tools = []config.Tool{
+		{Name: "ping", InputSchema: &config.Schema{Type: "object"}},
+		{Name: "search", InputSchema: &config.Schema{Type: "object"}},
+	}
+
+	plan, err = New(cfg, spec).Plan(StageResolver)
+	require.NoError(t, err)
+	require.Len(t, plan.Files, 2)
+
+	assert.Equal(t, FileUnchanged, plan.Files[0].Action)
+	resolvers := plan.file(resolversPath)
+	require.NotNil(t, resolvers)
+	assert.Equal(t, FileUpdate, resolvers.Action)
+	assert.Equal(t, []string{"SearchTool"}, resolvers.AddedHandlers)
+	assert.Equal(t, []string{"EchoTool"}, resolvers.OrphanedHandlers)
+}
@@ -0,0 +1,105 @@
+package codegen
+
+import (
+	"fmt"
+	"text/template"
+
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+// Plugin extends the generation pipeline without patching mcpgen, like
+// gqlgen plugins. A plugin implements any of SpecMutator, ModelMutator,
+// TemplateDataMutator and PostGenerator; the generator calls the hooks of
+// its plugins in the order they were added.
+type Plugin interface {
+	// Name identifies the plugin in errors.
+	Name() string
+}
+
+// SpecMutator is a Plugin changing the spec before its schemas are loaded,
+// such as adding the tools every server of a company exposes. The spec is
+// validated again once every plugin has changed it.
+type SpecMutator interface {
+	MutateSpec(spec *config.MCPSpec) error
+}
+
+// TemplateDataMutator is a Plugin changing the data of a code template
+// before it is executed. name is the template, such as server.gotpl, and
+// data the values documented at the top of the template.
+type TemplateDataMutator interface {
+	MutateTemplateData(name string, data map[string]any) error
+}
+
+// PostGenerator is a Plugin running once the generated files are written,
+// such as to generate files of its own. It is not called when the
+// generation only plans or checks the files.
+type PostGenerator interface {
+	PostGenerate(cfg *config.Config, spec *config.MCPSpec) error
+}
+
+// AddPlugin registers a plugin, whose hooks run after the ones of the
+// plugins added before.
+func (g *Generator) AddPlugin(p Plugin) {
+	g.plugins = append(g.plugins, p)
+}
+
+// mutateSpec runs the SpecMutator plugins, once per generator.
+func (g *Generator) mutateSpec() error {
+	if g.specMutated {
+		return nil
+	}
+	g.specMutated = true
+
+	mutated := false
+	for _, p := range g.plugins {
+		m, ok := p.(SpecMutator)
+		if !ok {
+			continue
+		}
+		if err := m.MutateSpec(g.spec); err != nil {
+			return fmt.Errorf("plugin %s: %w", p.Name(), err)
+		}
+		mutated = true
+	}
+	if !mutated {
+		return nil
+	}
+
+	if err := g.spec.Validate(); err != nil {
+		return fmt.Errorf("invalid spec after plugins: %w", err)
+	}
+	return nil
+}
+
+// mutateTemplateData runs the TemplateDataMutator plugins on the data of
+// tmpl.
+func (g *Generator) mutateTemplateData(tmpl *template.Template, data any) error {
+	values, ok := data.(map[string]any)
+	if !ok {
+		return nil
+	}
+	for _, p := range g.plugins {
+		m, ok := p.(TemplateDataMutator)
+		if !ok {
+			continue
+		}
+		if err := m.MutateTemplateData(tmpl.Name(), values); err != nil {
+			return fmt.Errorf("plugin %s: %w", p.Name(), err)
+		}
+	}
+	return nil
+}
+
+// postGenerate runs the PostGenerator plugins.
+func (g *Generator) postGenerate() error {
+	for _, p := range g.plugins {
+		m, ok := p.(PostGenerator)
+		if !ok {
+			continue
+		}
+		if err := m.PostGenerate(g.config, g.spec); err != nil {
+			return fmt.Errorf("plugin %s: %w", p.Name(), err)
+		}
+	}
+	return nil
+}
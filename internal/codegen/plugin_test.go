@@ -0,0 +1,84 @@
+package codegen
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+type recordingPlugin struct {
+	calls   []string
+	specErr error
+}
+
+func (p *recordingPlugin) Name() string { return "recording" }
+
+func (p *recordingPlugin) MutateSpec(spec *config.MCPSpec) error {
+	p.calls = append(p.calls, "MutateSpec")
+	spec.Tools = append(spec.Tools, config.Tool{Name: "audit_log", Description: "List the audit log", NoInput: true})
+	return p.specErr
+}
+
+func (p *recordingPlugin) MutateTemplateData(name string, data map[string]any) error {
+	p.calls = append(p.calls, "MutateTemplateData "+name)
+	if name == "server.gotpl" {
+		data["ServerName"] = "acme-" + data["ServerName"].(string)
+	}
+	return nil
+}
+
+func (p *recordingPlugin) PostGenerate(cfg *config.Config, spec *config.MCPSpec) error {
+	p.calls = append(p.calls, "PostGenerate")
+	return nil
+}
+
+func TestGeneratePlugins(t *testing.T) {
+	newGenerator := func(t *testing.T, plugin Plugin) (*Generator, string) {
+		t.Helper()
+		specPath := filepath.Join("testdata", "config_based_types.yaml")
+		spec, err := config.LoadMCPSpec(specPath)
+		require.NoError(t, err, "Failed to load spec")
+
+		outputDir := t.TempDir()
+		gen := New(&config.Config{
+			Spec:     specPath,
+			Output:   outputDir,
+			Exec:     config.ExecConfig{Package: "test", Filename: "server.go"},
+			Model:    config.ModelConfig{Package: "test", Filename: "models.go"},
+			Resolver: config.ResolverConfig{Package: "test", Filename: "resolver.go", Type: "Resolver"},
+		}, spec)
+		gen.AddPlugin(plugin)
+		return gen, outputDir
+	}
+
+	t.Run("hooks", func(t *testing.T) {
+		plugin := &recordingPlugin{}
+		gen, outputDir := newGenerator(t, plugin)
+		require.NoError(t, gen.Generate(StageServer))
+		assert.Equal(t, []string{"MutateSpec", "MutateTemplateData server.gotpl", "MutateTemplateData names.gotpl", "PostGenerate"}, plugin.calls)
+
+		content, err := os.ReadFile(filepath.Join(outputDir, "server.go"))
+		require.NoError(t, err, "Failed to read server.go")
+		server := string(content)
+		assert.Contains(t, server, `Name:    "acme-`)
+		assert.Contains(t, server, `Name:        "audit_log"`)
+	})
+
+	t.Run("check", func(t *testing.T) {
+		plugin := &recordingPlugin{}
+		gen, _ := newGenerator(t, plugin)
+		_, err := gen.Check(StageServer)
+		require.NoError(t, err)
+		assert.NotContains(t, plugin.calls, "PostGenerate")
+	})
+
+	t.Run("error", func(t *testing.T) {
+		gen, _ := newGenerator(t, &recordingPlugin{specErr: errors.New("missing audit scope")})
+		assert.EqualError(t, gen.Generate(StageServer), "plugin recording: missing audit scope")
+	})
+}
@@ -0,0 +1,159 @@
+package codegen
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+// RegistryManifest is the name of the manifest Release writes for MCP server
+// registries.
+const RegistryManifest = "server.json"
+
+// registrySchema is the version of the server.json schema of the MCP
+// registry the manifest follows.
+const registrySchema = "https://static.modelcontextprotocol.io/schemas/2025-09-29/server.schema.json"
+
+// registryPublisherKey is the _meta key under which publishers describe
+// their server beyond the fields of the registry schema.
+const registryPublisherKey = "io.modelcontextprotocol.registry/publisher-provided"
+
+type registryServer struct {
+	Schema      string            `json:"$schema"`
+	Name        string            `json:"name"`
+	Title       string            `json:"title,omitempty"`
+	Description string            `json:"description"`
+	Version     string            `json:"version"`
+	WebsiteURL  string            `json:"websiteUrl,omitempty"`
+	Packages    []registryPackage `json:"packages,omitempty"`
+	Remotes     []registryRemote  `json:"remotes,omitempty"`
+	Meta        map[string]any    `json:"_meta,omitempty"`
+}
+
+type registryPackage struct {
+	RegistryType         string           `json:"registryType"`
+	RegistryBaseURL      string           `json:"registryBaseUrl"`
+	Identifier           string           `json:"identifier"`
+	Version              string           `json:"version"`
+	Transport            registryRemote   `json:"transport"`
+	EnvironmentVariables []registryEnvVar `json:"environmentVariables,omitempty"`
+}
+
+type registryRemote struct {
+	Type string `json:"type"`
+	URL  string `json:"url,omitempty"`
+}
+
+type registryEnvVar struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	IsRequired  bool   `json:"isRequired,omitempty"`
+	Default     string `json:"default,omitempty"`
+}
+
+type registryTool struct {
+	Name        string          `json:"name"`
+	Title       string          `json:"title,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Annotations map[string]bool `json:"annotations,omitempty"`
+}
+
+// checkRegistryOptions reports the options of Release the registry manifest
+// cannot be written with, before the binaries are built.
+func checkRegistryOptions(opts ReleaseOptions) error {
+	namespace, name, ok := strings.Cut(opts.RegistryName, "/")
+	if !ok || namespace == "" || name == "" || strings.Contains(name, "/") {
+		return fmt.Errorf("invalid registry name %q, expected a namespace and a name such as io.github.acme/tasks", opts.RegistryName)
+	}
+	if !opts.NPM && opts.RemoteURL == "" {
+		return fmt.Errorf("the registry manifest requires the npm package or the remote URL of the server")
+	}
+	return nil
+}
+
+// writeRegistryManifest writes the server.json manifest publishing the
+// server to MCP registries as opts.RegistryName: the npm package, run over
+// stdio, when opts.NPM is set, and the server deployed at opts.RemoteURL,
+// over streamable HTTP. The capabilities and the tools of the spec, except
+// those gated by an experiment, are listed in the publisher-provided
+// metadata, for registries and clients to show what the server offers.
+func writeRegistryManifest(path string, opts ReleaseOptions, description string, spec *config.MCPSpec) error {
+	version := strings.TrimPrefix(opts.Version, "v")
+	server := registryServer{
+		Schema:      registrySchema,
+		Name:        opts.RegistryName,
+		Title:       spec.Info.Title,
+		Description: description,
+		Version:     version,
+		WebsiteURL:  opts.Homepage,
+	}
+
+	if opts.NPM {
+		pkg := registryPackage{
+			RegistryType:    "npm",
+			RegistryBaseURL: "https://registry.npmjs.org",
+			Identifier:      opts.NPMPackage,
+			Version:         version,
+			Transport:       registryRemote{Type: "stdio"},
+		}
+		for _, v := range spec.Env {
+			pkg.EnvironmentVariables = append(pkg.EnvironmentVariables, registryEnvVar{
+				Name:        v.Name,
+				Description: v.Description,
+				IsRequired:  v.Required,
+				Default:     v.Default,
+			})
+		}
+		server.Packages = append(server.Packages, pkg)
+	}
+	if opts.RemoteURL != "" {
+		server.Remotes = append(server.Remotes, registryRemote{Type: "streamable-http", URL: opts.RemoteURL})
+	}
+
+	capabilities := map[string]any{}
+	if len(spec.Tools) > 0 {
+		capabilities["tools"] = map[string]any{}
+	}
+	if len(spec.Resources) > 0 {
+		capabilities["resources"] = map[string]any{}
+	}
+	if len(spec.Prompts) > 0 {
+		capabilities["prompts"] = map[string]any{}
+	}
+	tools := []registryTool{}
+	for _, tool := range spec.Tools {
+		if tool.Experiment != "" {
+			continue
+		}
+		t := registryTool{Name: tool.Name, Title: tool.Title, Description: tool.Description}
+		// As registered by the server, only the hints set are advertised
+		if h := tool.Hints; h != nil {
+			t.Annotations = map[string]bool{}
+			for hint, set := range map[string]bool{
+				"readOnlyHint":    h.Readonly,
+				"destructiveHint": h.Destructive,
+				"idempotentHint":  h.Idempotent,
+				"openWorldHint":   h.OpenWorld,
+			} {
+				if set {
+					t.Annotations[hint] = true
+				}
+			}
+		}
+		tools = append(tools, t)
+	}
+	server.Meta = map[string]any{
+		registryPublisherKey: map[string]any{
+			"capabilities": capabilities,
+			"tools":        tools,
+		},
+	}
+
+	data, err := marshalJSON(server, "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,67 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+func TestWriteRegistryManifest(t *testing.T) {
+	opts := ReleaseOptions{
+		Name:         "tasks",
+		Version:      "v1.2.0",
+		Homepage:     "https://github.com/acme/tasks",
+		NPM:          true,
+		NPMPackage:   "@acme/tasks",
+		RegistryName: "io.github.acme/tasks",
+		RemoteURL:    "https://tasks.acme.com/mcp",
+	}
+	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "tasks", Version: "1.2.0"},
+		Tools: []config.Tool{
+			{Name: "list_tasks", Title: "List tasks", Hints: &config.ToolHints{Readonly: true, Idempotent: true}},
+			{Name: "purge_tasks", Experiment: "purge"},
+		},
+		Prompts: []config.Prompt{{Name: "summarize"}},
+		Env:     []config.EnvVar{{Name: "TASKS_TOKEN", Description: "Token of the tasks API", Required: true}},
+	}
+	require.NoError(t, checkRegistryOptions(opts))
+
+	path := filepath.Join(t.TempDir(), RegistryManifest)
+	require.NoError(t, writeRegistryManifest(path, opts, "Manage tasks", spec))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"$schema": "https://static.modelcontextprotocol.io/schemas/2025-09-29/server.schema.json",
+		"name": "io.github.acme/tasks",
+		"title": "tasks",
+		"description": "Manage tasks",
+		"version": "1.2.0",
+		"websiteUrl": "https://github.com/acme/tasks",
+		"packages": [{
+			"registryType": "npm",
+			"registryBaseUrl": "https://registry.npmjs.org",
+			"identifier": "@acme/tasks",
+			"version": "1.2.0",
+			"transport": {"type": "stdio"},
+			"environmentVariables": [{"name": "TASKS_TOKEN", "description": "Token of the tasks API", "isRequired": true}]
+		}],
+		"remotes": [{"type": "streamable-http", "url": "https://tasks.acme.com/mcp"}],
+		"_meta": {
+			"io.modelcontextprotocol.registry/publisher-provided": {
+				"capabilities": {"tools": {}, "prompts": {}},
+				"tools": [{"name": "list_tasks", "title": "List tasks", "annotations": {"readOnlyHint": true, "idempotentHint": true}}]
+			}
+		}
+	}`, string(data))
+
+	err = checkRegistryOptions(ReleaseOptions{RegistryName: "tasks", NPM: true})
+	assert.EqualError(t, err, `invalid registry name "tasks", expected a namespace and a name such as io.github.acme/tasks`)
+	err = checkRegistryOptions(ReleaseOptions{RegistryName: "io.github.acme/tasks"})
+	assert.EqualError(t, err, "the registry manifest requires the npm package or the remote URL of the server")
+}
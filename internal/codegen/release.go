@@ -0,0 +1,381 @@
+package codegen
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+// DefaultReleaseTargets are the platforms Release builds when none is given.
+var DefaultReleaseTargets = []string{
+	"darwin/amd64",
+	"darwin/arm64",
+	"linux/amd64",
+	"linux/arm64",
+	"windows/amd64",
+}
+
+// ReleaseChecksums is the name of the checksums file Release writes next to
+// the archives, in the format of sha256sum.
+const ReleaseChecksums = "checksums.txt"
+
+// ReleaseOptions configures Release.
+type ReleaseOptions struct {
+	// Main is the main package of the server, as given to go build.
+	// Defaults to ".".
+	Main string
+	// Name is the name of the binary and of the server in the client
+	// configurations. Defaults to the name of the directory of Main.
+	Name string
+	// Version is embedded in the binaries and names the archives. Defaults
+	// to info.version of the spec.
+	Version string
+	// Targets are the GOOS/GOARCH pairs to build, DefaultReleaseTargets
+	// when empty.
+	Targets []string
+	// Output is the directory of the archives. Defaults to dist.
+	Output string
+
+	// DownloadURL is the URL the archives are published under, such as
+	// https://github.com/acme/tasks/releases/download/v1.2.0, from which
+	// the Homebrew formula and the Scoop manifest download them.
+	DownloadURL string
+	// Homepage is the homepage of the server in the Homebrew formula and
+	// the Scoop manifest.
+	Homepage string
+	// Homebrew writes a Homebrew formula installing the darwin and linux
+	// binaries, named after the binary with a .rb extension.
+	Homebrew bool
+	// Scoop writes a Scoop manifest installing the windows binaries, named
+	// after the binary with a .json extension.
+	Scoop bool
+	// NPM writes an npm package in the npm directory of Output, whose
+	// launcher downloads and runs the binary of the platform, for the
+	// clients launching servers with npx.
+	NPM bool
+	// NPMPackage is the name of the npm package, such as @acme/tasks.
+	// Defaults to Name.
+	NPMPackage string
+	// RegistryName writes the RegistryManifest publishing the server to
+	// MCP registries under this name, such as io.github.acme/tasks.
+	RegistryName string
+	// RemoteURL is the URL of a deployment of the server over streamable
+	// HTTP, listed by the registry manifest.
+	RemoteURL string
+}
+
+// ReleaseResult lists the files written by Release.
+type ReleaseResult struct {
+	Artifacts []Artifact
+	// HomebrewFormula is the path of the Homebrew formula, empty unless
+	// ReleaseOptions.Homebrew is set.
+	HomebrewFormula string
+	// ScoopManifest is the path of the Scoop manifest, empty unless
+	// ReleaseOptions.Scoop is set.
+	ScoopManifest string
+	// NPMPackage is the directory of the npm package, and NPXClientConfig
+	// the path of the snippet configuring MCP clients to run it with npx,
+	// empty unless ReleaseOptions.NPM is set.
+	NPMPackage      string
+	NPXClientConfig string
+	// RegistryManifest is the path of the manifest for MCP registries,
+	// empty unless ReleaseOptions.RegistryName is set.
+	RegistryManifest string
+}
+
+// Artifact is an archive of a server binary built by Release.
+type Artifact struct {
+	OS   string
+	Arch string
+	// Path is the path of the archive: a .zip for Windows, a .tar.gz
+	// otherwise.
+	Path string
+	// SHA256 is the hexadecimal SHA-256 of the archive.
+	SHA256 string
+	// ClientConfig is the path of the snippet configuring MCP clients to
+	// run the binary of the archive over stdio.
+	ClientConfig string
+}
+
+// Release builds the server for every target of opts without cgo, with its
+// version and the hash of the spec set in the generated ServerInfo, and
+// archives each binary in opts.Output along with a client configuration
+// snippet. The checksums of the archives are written to ReleaseChecksums,
+// and the Homebrew formula and Scoop manifest installing the binaries, the
+// npm package launching them and the MCP registry manifest when requested.
+func (g *Generator) Release(opts ReleaseOptions) (*ReleaseResult, error) {
+	if opts.Main == "" {
+		opts.Main = "."
+	}
+	if opts.Name == "" {
+		absMain, err := filepath.Abs(opts.Main)
+		if err != nil {
+			return nil, err
+		}
+		opts.Name = filepath.Base(absMain)
+	}
+	if opts.Version == "" {
+		opts.Version = g.spec.Info.Version
+	}
+	if opts.Version == "" || strings.ContainsFunc(opts.Version, invalidVersionRune) {
+		return nil, fmt.Errorf("invalid release version %q", opts.Version)
+	}
+	if len(opts.Targets) == 0 {
+		opts.Targets = DefaultReleaseTargets
+	}
+	if opts.Output == "" {
+		opts.Output = "dist"
+	}
+	if opts.NPMPackage == "" {
+		opts.NPMPackage = opts.Name
+	}
+	if (opts.Homebrew || opts.Scoop || opts.NPM) && opts.DownloadURL == "" {
+		return nil, fmt.Errorf("the download URL of the archives is required for the Homebrew formula, the Scoop manifest and the npm package")
+	}
+	if opts.RegistryName != "" {
+		if err := checkRegistryOptions(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.MkdirAll(opts.Output, 0o755); err != nil {
+		return nil, err
+	}
+	buildDir, err := os.MkdirTemp("", "mcpgen-release-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(buildDir) }()
+
+	serverPkg := g.computeImportPath(g.config.Exec.Package, g.config.Exec.Filename)
+	ldflags := fmt.Sprintf(
+		"-s -w -X %s.serverVersion=%s -X %s.serverSpecHash=%s",
+		serverPkg, opts.Version, serverPkg, g.specHash(),
+	)
+
+	result := &ReleaseResult{Artifacts: make([]Artifact, 0, len(opts.Targets))}
+	var checksums strings.Builder
+	for _, target := range opts.Targets {
+		goos, goarch, ok := strings.Cut(target, "/")
+		if !ok || goos == "" || goarch == "" {
+			return nil, fmt.Errorf("invalid release target %q, expected GOOS/GOARCH", target)
+		}
+
+		binary := opts.Name
+		if goos == "windows" {
+			binary += ".exe"
+		}
+		binPath := filepath.Join(buildDir, target, binary)
+
+		cmd := exec.Command("go", "build", "-trimpath", "-ldflags", ldflags, "-o", binPath, opts.Main)
+		cmd.Env = append(os.Environ(), "GOOS="+goos, "GOARCH="+goarch, "CGO_ENABLED=0")
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("failed to build %s: %w\n%s", target, err, stderr.Bytes())
+		}
+
+		base := fmt.Sprintf("%s_%s_%s_%s", opts.Name, opts.Version, goos, goarch)
+		artifact := Artifact{OS: goos, Arch: goarch}
+		if goos == "windows" {
+			artifact.Path = filepath.Join(opts.Output, base+".zip")
+			err = writeZipArchive(artifact.Path, binPath)
+		} else {
+			artifact.Path = filepath.Join(opts.Output, base+".tar.gz")
+			err = writeTarArchive(artifact.Path, binPath)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to archive %s: %w", target, err)
+		}
+
+		if artifact.SHA256, err = fileSHA256(artifact.Path); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&checksums, "%s  %s\n", artifact.SHA256, filepath.Base(artifact.Path))
+
+		artifact.ClientConfig = filepath.Join(opts.Output, base+".mcp.json")
+		if err := writeClientConfig(artifact.ClientConfig, opts.Name, g.spec.Env, binary); err != nil {
+			return nil, err
+		}
+
+		result.Artifacts = append(result.Artifacts, artifact)
+	}
+
+	if err := os.WriteFile(filepath.Join(opts.Output, ReleaseChecksums), []byte(checksums.String()), 0o644); err != nil {
+		return nil, err
+	}
+
+	description := g.spec.Info.Description
+	if description == "" {
+		description = g.spec.Info.Title
+	}
+	notes := envNotes(opts.Name, g.spec.Env)
+	if opts.Homebrew {
+		result.HomebrewFormula = filepath.Join(opts.Output, opts.Name+".rb")
+		if err := writeHomebrewFormula(result.HomebrewFormula, opts, description, notes, result.Artifacts); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Scoop {
+		result.ScoopManifest = filepath.Join(opts.Output, opts.Name+".json")
+		if err := writeScoopManifest(result.ScoopManifest, opts, description, notes, result.Artifacts); err != nil {
+			return nil, err
+		}
+	}
+	if opts.NPM {
+		result.NPMPackage = filepath.Join(opts.Output, "npm")
+		if err := writeNPMPackage(result.NPMPackage, opts, description, result.Artifacts); err != nil {
+			return nil, err
+		}
+		result.NPXClientConfig = filepath.Join(opts.Output, opts.Name+".npx.mcp.json")
+		if err := writeClientConfig(result.NPXClientConfig, opts.Name, g.spec.Env, "npx", "-y", opts.NPMPackage); err != nil {
+			return nil, err
+		}
+	}
+	if opts.RegistryName != "" {
+		result.RegistryManifest = filepath.Join(opts.Output, RegistryManifest)
+		if err := writeRegistryManifest(result.RegistryManifest, opts, description, g.spec); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// invalidVersionRune reports whether r cannot appear in the version of
+// a release, which names files and is passed to the linker.
+func invalidVersionRune(r rune) bool {
+	return r == '/' || r == '\\' || r == '\'' || r == '"' || r == ' ' || r == '\t' || r == '\n'
+}
+
+// writeTarArchive writes the gzipped tar archive path holding the
+// executable binPath.
+func writeTarArchive(path, binPath string) error {
+	info, err := os.Stat(binPath)
+	if err != nil {
+		return err
+	}
+	bin, err := os.Open(binPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = bin.Close() }()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    filepath.Base(binPath),
+		Mode:    0o755,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}); err != nil {
+		return err
+	}
+	if _, err := io.Copy(tw, bin); err != nil {
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// writeZipArchive writes the zip archive path holding the executable
+// binPath.
+func writeZipArchive(path, binPath string) error {
+	info, err := os.Stat(binPath)
+	if err != nil {
+		return err
+	}
+	bin, err := os.Open(binPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = bin.Close() }()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	zw := zip.NewWriter(f)
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return err
+	}
+	header.Method = zip.Deflate
+	header.SetMode(0o755)
+	w, err := zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, bin); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// writeClientConfig writes the snippet adding the server name, run by
+// command with args over stdio, to the mcpServers of an MCP client
+// configuration. Users replace a binary command with the path they installed
+// the binary at, unless it is on their PATH. The environment variables of
+// the server are set to their default, for users to fill in.
+func writeClientConfig(path, name string, env []config.EnvVar, command string, args ...string) error {
+	type server struct {
+		Command string            `json:"command"`
+		Args    []string          `json:"args"`
+		Env     map[string]string `json:"env,omitempty"`
+	}
+	s := server{Command: command, Args: append([]string{}, args...)}
+	if len(env) > 0 {
+		s.Env = make(map[string]string, len(env))
+		for _, v := range env {
+			s.Env[v.Name] = v.Default
+		}
+	}
+	data, err := json.MarshalIndent(map[string]any{
+		"mcpServers": map[string]server{name: s},
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = f.Close() }()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
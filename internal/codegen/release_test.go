@@ -0,0 +1,140 @@
+package codegen
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+func TestRelease(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the archive of the host platform is a zip")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/tasks\n\ngo 1.25\n",
+		"generated/server/server.go": `package server
+
+var (
+	serverVersion  = "1.0.0"
+	serverSpecHash = "stale"
+)
+
+func Info() string { return serverVersion + " " + serverSpecHash }
+`,
+		"cmd/tasks/main.go": `package main
+
+import (
+	"fmt"
+
+	"example.com/tasks/generated/server"
+)
+
+func main() { fmt.Print(server.Info()) }
+`,
+	}
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	t.Chdir(dir)
+
+	gen := New(
+		&config.Config{Output: "generated", Exec: config.ExecConfig{Package: "server", Filename: "server/server.go"}},
+		&config.MCPSpec{Info: config.ServerInfo{Title: "tasks", Version: "1.0.0"}},
+	)
+	target := runtime.GOOS + "/" + runtime.GOARCH
+	result, err := gen.Release(ReleaseOptions{Main: "./cmd/tasks", Version: "1.2.0", Targets: []string{target}})
+	require.NoError(t, err)
+	require.Len(t, result.Artifacts, 1)
+	assert.Empty(t, result.HomebrewFormula)
+	assert.Empty(t, result.ScoopManifest)
+
+	artifact := result.Artifacts[0]
+	base := filepath.Join("dist", "tasks_1.2.0_"+runtime.GOOS+"_"+runtime.GOARCH)
+	assert.Equal(t, base+".tar.gz", artifact.Path)
+	assert.Equal(t, base+".mcp.json", artifact.ClientConfig)
+
+	checksums, err := os.ReadFile(filepath.Join("dist", ReleaseChecksums))
+	require.NoError(t, err)
+	assert.Equal(t, artifact.SHA256+"  "+filepath.Base(artifact.Path)+"\n", string(checksums))
+
+	clientConfig, err := os.ReadFile(artifact.ClientConfig)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"mcpServers": {"tasks": {"command": "tasks", "args": []}}}`, string(clientConfig))
+
+	// The archive holds the binary, linked with the version and spec hash
+	f, err := os.Open(artifact.Path)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	gz, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+	header, err := tr.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "tasks", header.Name)
+	bin, err := os.OpenFile(filepath.Join(dir, "tasks"), os.O_CREATE|os.O_WRONLY, 0o755)
+	require.NoError(t, err)
+	_, err = io.Copy(bin, tr)
+	require.NoError(t, err)
+	require.NoError(t, bin.Close())
+
+	out, err := exec.Command(filepath.Join(dir, "tasks")).Output()
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.0 "+gen.specHash(), string(out))
+
+	_, err = gen.Release(ReleaseOptions{Main: "./cmd/tasks", Targets: []string{"linux"}})
+	assert.EqualError(t, err, `invalid release target "linux", expected GOOS/GOARCH`)
+	_, err = gen.Release(ReleaseOptions{Main: "./cmd/tasks", Version: "1.2.0 beta"})
+	assert.EqualError(t, err, `invalid release version "1.2.0 beta"`)
+	_, err = gen.Release(ReleaseOptions{Main: "./cmd/tasks", Homebrew: true})
+	assert.EqualError(t, err, "the download URL of the archives is required for the Homebrew formula, the Scoop manifest and the npm package")
+}
+
+func TestWriteZipArchive(t *testing.T) {
+	dir := t.TempDir()
+	binPath := filepath.Join(dir, "tasks.exe")
+	require.NoError(t, os.WriteFile(binPath, []byte("MZ"), 0o755))
+	path := filepath.Join(dir, "tasks.zip")
+	require.NoError(t, writeZipArchive(path, binPath))
+
+	zr, err := zip.OpenReader(path)
+	require.NoError(t, err)
+	defer func() { _ = zr.Close() }()
+	require.Len(t, zr.File, 1)
+	assert.Equal(t, "tasks.exe", zr.File[0].Name)
+	r, err := zr.File[0].Open()
+	require.NoError(t, err)
+	defer func() { _ = r.Close() }()
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "MZ", string(data))
+}
+
+func TestWriteClientConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.mcp.json")
+	env := []config.EnvVar{{Name: "TASKS_TOKEN", Required: true}, {Name: "TASKS_URL", Default: "https://tasks.example.com"}}
+	require.NoError(t, writeClientConfig(path, "tasks", env, "npx", "-y", "@acme/tasks"))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"mcpServers": {"tasks": {
+		"command": "npx",
+		"args": ["-y", "@acme/tasks"],
+		"env": {"TASKS_TOKEN": "", "TASKS_URL": "https://tasks.example.com"}
+	}}}`, string(data))
+}
@@ -0,0 +1,185 @@
+package codegen
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strings"
+)
+
+// renameSimilarity is the minimum name similarity, between 0 and 1, for an
+// orphaned handler and a new handler to be offered as a rename.
+const renameSimilarity = 0.6
+
+// RenameFunc confirms that the handler oldHandler, which no longer matches
+// the spec, was renamed to newHandler. A confirmed rename keeps the body of
+// oldHandler under the signature of newHandler instead of orphaning it.
+type RenameFunc func(oldHandler, newHandler string) bool
+
+// SetRenameFunc sets how incremental resolver updates confirm likely
+// renames. Without one, handlers are never treated as renamed.
+func (g *Generator) SetRenameFunc(fn RenameFunc) {
+	g.confirmRename = fn
+}
+
+type handlerRename struct {
+	Old string
+	New string
+}
+
+func (r handlerRename) String() string {
+	return r.Old + " -> " + r.New
+}
+
+// findRenames pairs orphaned handlers with new handlers of the same kind
+// (tool, resource or prompt) whose names are similar, most similar first,
+// and keeps the pairs confirmed by confirm.
+func findRenames(orphaned, added []string, confirm RenameFunc) []handlerRename {
+	if confirm == nil {
+		return nil
+	}
+
+	type candidate struct {
+		handlerRename
+		score float64
+	}
+
+	var candidates []candidate
+	for _, oldName := range orphaned {
+		for _, newName := range added {
+			oldBase, oldKind := splitHandlerKind(oldName)
+			newBase, newKind := splitHandlerKind(newName)
+			if oldKind == "" || oldKind != newKind {
+				continue
+			}
+			if score := nameSimilarity(oldBase, newBase); score >= renameSimilarity {
+				candidates = append(candidates, candidate{handlerRename{oldName, newName}, score})
+			}
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].String() < candidates[j].String()
+	})
+
+	var renames []handlerRename
+	taken := make(map[string]bool)
+	for _, c := range candidates {
+		if taken[c.Old] || taken[c.New] {
+			continue
+		}
+		if !confirm(c.Old, c.New) {
+			continue
+		}
+		taken[c.Old] = true
+		taken[c.New] = true
+		renames = append(renames, c.handlerRename)
+	}
+
+	return renames
+}
+
+func splitHandlerKind(name string) (string, string) {
+	for _, kind := range []string{"Tool", "Resource", "Prompt"} {
+		if base, ok := strings.CutSuffix(name, kind); ok && base != "" {
+			return base, kind
+		}
+	}
+	return name, ""
+}
+
+// nameSimilarity returns 1 minus the edit distance between a and b, ignoring
+// case, relative to the length of the longest.
+func nameSimilarity(a, b string) float64 {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	longest := max(len(a), len(b))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(a, b))/float64(longest)
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+// applyRenames replaces, in the resolver source, the declaration of every
+// renamed handler with the signature generated for its new name followed by
+// its existing body.
+func (g *Generator) applyRenames(src string, handlers map[string]*HandlerInfo, renames []handlerRename) (string, error) {
+	type replacement struct {
+		start, end int
+		code       string
+	}
+
+	replacements := make([]replacement, 0, len(renames))
+	for _, rename := range renames {
+		old := handlers[rename.Old]
+
+		stub, err := g.generateNewHandlersCode([]string{rename.New})
+		if err != nil {
+			return "", err
+		}
+		signature, err := handlerSignature(stub)
+		if err != nil {
+			return "", fmt.Errorf("failed to read signature of %s: %w", rename.New, err)
+		}
+
+		replacements = append(replacements, replacement{old.Start, old.End, signature + old.Body})
+	}
+
+	// Replace from the end of the file so earlier offsets stay valid
+	sort.Slice(replacements, func(i, j int) bool {
+		return replacements[i].start > replacements[j].start
+	})
+	for _, r := range replacements {
+		src = src[:r.start] + r.code + src[r.end:]
+	}
+
+	return src, nil
+}
+
+// handlerSignature returns the source of the generated handler declaration
+// up to its body, trailing space included.
+func handlerSignature(code string) (string, error) {
+	const header = "package p\n"
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", header+code, 0)
+	if err != nil {
+		return "", err
+	}
+
+	for _, decl := range file.Decls {
+		if funcDecl, ok := decl.(*ast.FuncDecl); ok && funcDecl.Body != nil {
+			start := fset.Position(funcDecl.Pos()).Offset
+			end := fset.Position(funcDecl.Body.Lbrace).Offset
+			return (header + code)[start:end], nil
+		}
+	}
+
+	return "", fmt.Errorf("no function declaration found")
+}
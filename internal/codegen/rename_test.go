@@ -0,0 +1,137 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+func TestFindRenames(t *testing.T) {
+	always := func(string, string) bool { return true }
+
+	tests := []struct {
+		name     string
+		orphaned []string
+		added    []string
+		confirm  RenameFunc
+		want     []handlerRename
+	}{
+		{
+			name:     "similar names",
+			orphaned: []string{"CreateEvntTool"},
+			added:    []string{"CreateEventTool"},
+			confirm:  always,
+			want:     []handlerRename{{Old: "CreateEvntTool", New: "CreateEventTool"}},
+		},
+		{
+			name:     "most similar first",
+			orphaned: []string{"GetUserTool", "GetUsersTool"},
+			added:    []string{"GetUsersListTool", "GetUserByIDTool"},
+			confirm:  always,
+			want: []handlerRename{
+				{Old: "GetUsersTool", New: "GetUsersListTool"},
+				{Old: "GetUserTool", New: "GetUserByIDTool"},
+			},
+		},
+		{
+			name:     "different kinds",
+			orphaned: []string{"ReadmeResource"},
+			added:    []string{"ReadmeTool"},
+			confirm:  always,
+			want:     nil,
+		},
+		{
+			name:     "unrelated names",
+			orphaned: []string{"DeleteTaskTool"},
+			added:    []string{"SearchTool"},
+			confirm:  always,
+			want:     nil,
+		},
+		{
+			name:     "declined",
+			orphaned: []string{"CreateEvntTool"},
+			added:    []string{"CreateEventTool"},
+			confirm:  func(string, string) bool { return false },
+			want:     nil,
+		},
+		{
+			name:     "no confirmation",
+			orphaned: []string{"CreateEvntTool"},
+			added:    []string{"CreateEventTool"},
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, findRenames(tt.orphaned, tt.added, tt.confirm))
+		})
+	}
+}
+
+func TestUpdateResolverIncrementalRename(t *testing.T) {
+	specPath := filepath.Join("testdata", "config_based_types.yaml")
+	spec, err := config.LoadMCPSpec(specPath)
+	require.NoError(t, err, "Failed to load spec")
+
+	tmpDir := t.TempDir()
+	resolverFile := filepath.Join(tmpDir, "schema.resolvers.go")
+	existing := `package test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type Resolver struct{}
+
+// CreateEvntTool stores the event.
+func (r *Resolver) CreateEvntTool(ctx context.Context, req *mcp.CallToolRequest, input *CreateEvntInput) (*mcp.CallToolResult, map[string]any, error) {
+	return nil, nil, fmt.Errorf("kept body")
+}
+`
+	require.NoError(t, os.WriteFile(resolverFile, []byte(existing), 0644))
+
+	cfg := &config.Config{
+		Spec:   specPath,
+		Output: tmpDir,
+		Model: config.ModelConfig{
+			Package:  "test",
+			Filename: "models.go",
+		},
+		Resolver: config.ResolverConfig{
+			Package:  "test",
+			Filename: "schema.resolvers.go",
+			Type:     "Resolver",
+			Preserve: true,
+		},
+	}
+
+	var asked []string
+	gen := New(cfg, spec)
+	gen.SetRenameFunc(func(oldHandler, newHandler string) bool {
+		asked = append(asked, oldHandler+" -> "+newHandler)
+		return true
+	})
+	require.NoError(t, gen.loadSchemas())
+	require.NoError(t, gen.updateResolverIncremental(resolverFile))
+
+	assert.Equal(t, []string{"CreateEvntTool -> CreateEventTool"}, asked)
+
+	content, err := os.ReadFile(resolverFile)
+	require.NoError(t, err)
+	code := string(content)
+
+	assert.Contains(t, code, "// CreateEvntTool stores the event.\nfunc (r *Resolver) CreateEventTool(ctx context.Context, req *mcp.CallToolRequest, input *CreateEventInput)")
+	assert.Contains(t, code, `fmt.Errorf("kept body")`)
+	assert.NotContains(t, code, "func (r *Resolver) CreateEvntTool")
+	assert.NotContains(t, code, "Orphaned Handlers")
+	assert.NotContains(t, code, "not implemented")
+}
@@ -0,0 +1,150 @@
+package codegen
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+// schemaCache caches the resolution of the schemas of the spec, which is
+// final once loaded: shared components, such as the input types of many
+// tools, are resolved and encoded once instead of once per schema using
+// them.
+//
+// The resolved schemas it holds are shared by every schema referring to
+// them and must not be modified; clone them with CloneSchemas first. A nil
+// cache caches nothing.
+type schemaCache struct {
+	mu sync.Mutex
+	// refs holds the resolved schemas of the local references, by
+	// reference.
+	refs map[string]*config.Schema
+	// encoded holds the canonical JSON of the resolved schemas, by hash of
+	// the content of the schema before resolution.
+	encoded map[schemaKey][]byte
+}
+
+// schemaKey identifies the resolution of a schema by its content.
+type schemaKey struct {
+	hash   [sha256.Size]byte
+	closed bool
+}
+
+func newSchemaCache() *schemaCache {
+	return &schemaCache{
+		refs:    make(map[string]*config.Schema),
+		encoded: make(map[schemaKey][]byte),
+	}
+}
+
+func (c *schemaCache) ref(ref string) (*config.Schema, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.refs[ref]
+	return s, ok
+}
+
+func (c *schemaCache) setRef(ref string, s *config.Schema) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refs[ref] = s
+}
+
+func (c *schemaCache) encodedJSON(key schemaKey) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, ok := c.encoded[key]
+	return data, ok
+}
+
+func (c *schemaCache) setEncodedJSON(key schemaKey, data []byte) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.encoded[key] = data
+}
+
+// resolveRef resolves the local reference ref, once per generation unless
+// the references to components are kept. A reference to a schema being
+// resolved is reported as a cycle, such as A → B → A, which would never
+// finish resolving.
+func (g *Generator) resolveRef(ref string) (*config.Schema, error) {
+	if g.sharedRefs == nil {
+		if resolved, ok := g.schemas.ref(ref); ok {
+			return resolved, nil
+		}
+	}
+	if i := slices.Index(g.resolving, ref); i >= 0 {
+		return nil, fmt.Errorf("circular schema reference: %s", refCycle(slices.Concat(g.resolving[i:], []string{ref})))
+	}
+
+	target, err := g.spec.ResolveSchemaRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	g.resolving = append(g.resolving, ref)
+	resolved, err := g.resolveAllRefs(target)
+	g.resolving = g.resolving[:len(g.resolving)-1]
+	if err != nil {
+		return nil, err
+	}
+	if g.sharedRefs == nil {
+		g.schemas.setRef(ref, resolved)
+	}
+	return resolved, nil
+}
+
+// refCycle returns the chain of references refs, by component name.
+func refCycle(refs []string) string {
+	names := make([]string, len(refs))
+	for i, ref := range refs {
+		names[i] = strings.TrimPrefix(ref, componentRefPrefix)
+	}
+	return strings.Join(names, " → ")
+}
+
+// resolvedJSON returns the canonical JSON of s with its references
+// resolved, with its objects closed as with model.strict_inputs when closed.
+// Schemas of the same content are resolved and encoded once.
+func (g *Generator) resolvedJSON(s *config.Schema, closed bool) ([]byte, error) {
+	content, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	key := schemaKey{hash: sha256.Sum256(content), closed: closed}
+
+	if data, ok := g.schemas.encodedJSON(key); ok {
+		return data, nil
+	}
+
+	resolved, err := g.resolveRefs(s)
+	if err != nil {
+		return nil, err
+	}
+	if closed {
+		resolved = resolved.CloneSchemas()
+		closeObjects(resolved)
+	}
+	data, err := canonicalJSON(resolved)
+	if err != nil {
+		return nil, err
+	}
+	g.schemas.setEncodedJSON(key, data)
+	return data, nil
+}
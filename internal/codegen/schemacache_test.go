@@ -0,0 +1,83 @@
+package codegen
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+func TestSchemaCache(t *testing.T) {
+	var spec config.MCPSpec
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"components": {"schemas": {
+			"Address": {
+				"type": "object",
+				"properties": {"city": {"type": "string"}}
+			},
+			"Input": {
+				"type": "object",
+				"properties": {
+					"home": {"$ref": "#/components/schemas/Address"},
+					"work": {"$ref": "#/components/schemas/Address"}
+				}
+			}
+		}}
+	}`), &spec))
+	gen := &Generator{spec: &spec, schemas: newSchemaCache()}
+
+	t.Run("shared references", func(t *testing.T) {
+		input := spec.Components.Schemas["Input"]
+		resolved, err := gen.resolveRefs(input)
+		require.NoError(t, err)
+		assert.Same(t, resolved.Properties["home"], resolved.Properties["work"], "a reference is resolved once")
+
+		again, err := gen.resolveRefs(&config.Schema{Ref: "#/components/schemas/Input"})
+		require.NoError(t, err)
+		assert.Same(t, resolved.Properties["home"], again.Properties["home"])
+	})
+
+	t.Run("JSON by content", func(t *testing.T) {
+		first, err := gen.resolvedJSON(spec.Components.Schemas["Input"], false)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"type": "object",
+			"properties": {
+				"home": {"type": "object", "properties": {"city": {"type": "string"}}},
+				"work": {"type": "object", "properties": {"city": {"type": "string"}}}
+			}
+		}`, string(first))
+
+		// A copy of the schema has the same content
+		var copied config.Schema
+		data, err := json.Marshal(spec.Components.Schemas["Input"])
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, &copied))
+		second, err := gen.resolvedJSON(&copied, false)
+		require.NoError(t, err)
+		assert.Same(t, &first[0], &second[0], "the cached JSON is returned")
+	})
+
+	t.Run("closed objects", func(t *testing.T) {
+		closed, err := gen.resolvedJSON(spec.Components.Schemas["Input"], true)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{
+			"type": "object",
+			"properties": {
+				"home": {"type": "object", "properties": {"city": {"type": "string"}}, "additionalProperties": false},
+				"work": {"type": "object", "properties": {"city": {"type": "string"}}, "additionalProperties": false}
+			},
+			"additionalProperties": false
+		}`, string(closed))
+
+		// Closing the objects leaves the shared resolved schemas open
+		resolved, err := gen.resolveRefs(spec.Components.Schemas["Address"])
+		require.NoError(t, err)
+		assert.Nil(t, resolved.AdditionalProperties)
+		open, err := gen.resolvedJSON(&config.Schema{Ref: "#/components/schemas/Address"}, false)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"type": "object", "properties": {"city": {"type": "string"}}}`, string(open))
+	})
+}
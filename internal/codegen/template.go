@@ -0,0 +1,67 @@
+package codegen
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// parseTemplate parses the code template name, such as server.gotpl, from
+// the templates directory of the configuration when it holds one, and from
+// the templates embedded in mcpgen otherwise.
+func (g *Generator) parseTemplate(name string) (*template.Template, error) {
+	if g.config.Templates != "" {
+		path := filepath.Join(g.config.Templates, name)
+		if _, err := os.Stat(path); err == nil {
+			return template.ParseFiles(path)
+		}
+	}
+	return template.ParseFS(templates, "templates/"+name)
+}
+
+// templateNames returns the names of the embedded code templates, which a
+// templates directory can override.
+func templateNames() []string {
+	entries, err := fs.ReadDir(templates, "templates")
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// templateWarnings reports the templates of the templates directory that
+// override no embedded template, such as misspelled ones, which would
+// otherwise be silently ignored.
+func (g *Generator) templateWarnings() []string {
+	if g.config.Templates == "" {
+		return nil
+	}
+	entries, err := os.ReadDir(g.config.Templates)
+	if err != nil {
+		return nil
+	}
+
+	names := templateNames()
+	var warnings []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".gotpl" || slices.Contains(names, name) {
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf(
+			"templates: %s overrides no template, use one of %s",
+			name, strings.Join(names, ", "),
+		))
+	}
+	return warnings
+}
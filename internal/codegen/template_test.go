@@ -0,0 +1,85 @@
+package codegen
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+func TestGenerateWithTemplateOverrides(t *testing.T) {
+	templatesDir := t.TempDir()
+	writeFiles(t, templatesDir, map[string]string{
+		"resolver_struct.gotpl": "package {{.Package}}\n\nimport \"log/slog\"\n\n// {{.ResolverType}} logs with the house logger\ntype {{.ResolverType}} struct {\n\tLogger *slog.Logger\n}\n",
+		"sever.gotpl":           "package {{.Package}}\n",
+		"README.md":             "Our code templates",
+	})
+
+	spec := &config.MCPSpec{
+		Info:  config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Tools: []config.Tool{{Name: "ping", NoInput: true}},
+	}
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Output:    outputDir,
+		Templates: templatesDir,
+		Exec: config.ExecConfig{
+			Package:  "test",
+			Filename: "server.go",
+		},
+		Model: config.ModelConfig{
+			Package:  "test",
+			Filename: "models.go",
+		},
+		Resolver: config.ResolverConfig{
+			Package:  "test",
+			Filename: "resolver.go",
+			Type:     "Resolver",
+		},
+	}
+	g := New(cfg, spec)
+	require.NoError(t, g.Generate(StageServer, StageResolver))
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "resolver.go"))
+	require.NoError(t, err, "Failed to read resolver.go")
+	assert.Contains(t, string(content), "// Resolver logs with the house logger\ntype Resolver struct {\n\tLogger *slog.Logger\n}")
+
+	content, err = os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	assert.Contains(t, string(content), "func New(resolver ResolverInterface", "templates not overridden are the embedded ones")
+
+	assert.Contains(t, g.Warnings(), "templates: sever.gotpl overrides no template, use one of fake.gotpl, names.gotpl, resolver.gotpl, resolver_struct.gotpl, server.gotpl")
+	assert.Len(t, g.templateWarnings(), 1)
+}
+
+func TestGenerateFixesImports(t *testing.T) {
+	templatesDir := t.TempDir()
+	writeFiles(t, templatesDir, map[string]string{
+		"resolver_struct.gotpl": "package {{.Package}}\n\nimport \"fmt\"\n\ntype {{.ResolverType}} struct {\n\tLogger *slog.Logger\n\tmu     sync.Mutex\n}\n",
+	})
+
+	spec := &config.MCPSpec{
+		Info:  config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Tools: []config.Tool{{Name: "ping", NoInput: true}},
+	}
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Output:    outputDir,
+		Templates: templatesDir,
+		Exec:      config.ExecConfig{Package: "test", Filename: "server.go"},
+		Model:     config.ModelConfig{Package: "test", Filename: "models.go"},
+		Resolver:  config.ResolverConfig{Package: "test", Filename: "resolver.go", Type: "Resolver"},
+	}
+	require.NoError(t, New(cfg, spec).Generate(StageServer, StageResolver))
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "resolver.go"))
+	require.NoError(t, err, "Failed to read resolver.go")
+	assert.Contains(t, string(content), "import (\n\t\"log/slog\"\n\t\"sync\"\n)\n", "missing imports are added and unused ones removed")
+
+	content, err = os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err, "Failed to read server.go")
+	assert.NotContains(t, string(content), `"sync/atomic"`, "the embedded templates import more than they use")
+}
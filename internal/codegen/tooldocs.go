@@ -0,0 +1,175 @@
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+// toolDoc returns the markdown documentation of tool served, with
+// exec.tool_docs, by the docs://tools/<name> resource: its description and
+// behavior, the steps of a composite tool, and the properties of its input
+// and output.
+func (g *Generator) toolDoc(tool config.Tool) string {
+	var b strings.Builder
+
+	if tool.Title != "" {
+		fmt.Fprintf(&b, "# %s\n\nTool: `%s`\n\n", tool.Title, tool.Name)
+	} else {
+		fmt.Fprintf(&b, "# %s\n\n", tool.Name)
+	}
+	if tool.Description != "" {
+		b.WriteString(strings.TrimSpace(tool.Description) + "\n\n")
+	}
+	if tool.Deprecated {
+		b.WriteString("**Deprecated.**")
+		if tool.ReplacedBy != "" {
+			fmt.Fprintf(&b, " Use `%s` instead.", tool.ReplacedBy)
+		}
+		b.WriteString("\n\n")
+	}
+	if behavior := toolBehavior(tool.Hints); len(behavior) > 0 {
+		fmt.Fprintf(&b, "Behavior: %s.\n\n", strings.Join(behavior, ", "))
+	}
+
+	if tool.IsComposite() {
+		b.WriteString("## Steps\n\n")
+		for i, step := range tool.Steps {
+			fmt.Fprintf(&b, "%d. `%s`\n", i+1, step.Tool)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Input\n\n")
+	if tool.TakesNoInput() || tool.InputSchema == nil {
+		b.WriteString("This tool takes no arguments.\n")
+	} else {
+		g.writeSchemaDoc(&b, tool.InputSchema)
+	}
+
+	if tool.OutputSchema != nil {
+		b.WriteString("\n## Output\n\n")
+		g.writeSchemaDoc(&b, tool.OutputSchema)
+	}
+
+	return b.String()
+}
+
+// toolBehavior returns the behaviors the hints of a tool declare.
+func toolBehavior(hints *config.ToolHints) []string {
+	if hints == nil {
+		return nil
+	}
+	var behavior []string
+	if hints.Readonly {
+		behavior = append(behavior, "read-only")
+	}
+	if hints.Destructive {
+		behavior = append(behavior, "destructive")
+	}
+	if hints.Idempotent {
+		behavior = append(behavior, "idempotent")
+	}
+	if hints.OpenWorld {
+		behavior = append(behavior, "interacts with external systems")
+	}
+	return behavior
+}
+
+// writeSchemaDoc writes the table of the properties of the object schema s,
+// or its type when it is not an object.
+func (g *Generator) writeSchemaDoc(b *strings.Builder, s *config.Schema) {
+	if name, ok := componentRef(s); ok {
+		if resolved, err := g.spec.ResolveSchemaRef(s.Ref); err == nil {
+			if resolved.Description != "" {
+				b.WriteString(strings.TrimSpace(resolved.Description) + "\n\n")
+			}
+			s = resolved
+		} else {
+			fmt.Fprintf(b, "A `%s`.\n", name)
+			return
+		}
+	}
+	if len(s.Properties) == 0 {
+		fmt.Fprintf(b, "A `%s`.\n", schemaTypeLabel(s))
+		return
+	}
+
+	required := make(map[string]bool, len(s.Required))
+	for _, name := range s.Required {
+		required[name] = true
+	}
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	b.WriteString("| Name | Type | Required | Description |\n")
+	b.WriteString("| --- | --- | --- | --- |\n")
+	for _, name := range names {
+		property := s.Properties[name]
+		requiredLabel := "no"
+		if required[name] {
+			requiredLabel = "yes"
+		}
+		fmt.Fprintf(b, "| `%s` | %s | %s | %s |\n", name, schemaTypeLabel(property), requiredLabel, propertyDoc(property))
+	}
+}
+
+// schemaTypeLabel returns the type of s for the documentation, such as
+// string (date-time), array of Task or integer or null.
+func schemaTypeLabel(s *config.Schema) string {
+	if s == nil {
+		return "any"
+	}
+	if name, ok := componentRef(s); ok {
+		return name
+	}
+
+	types := s.Types
+	if s.Type != "" {
+		types = []string{s.Type}
+	}
+	labels := make([]string, 0, len(types))
+	for _, t := range types {
+		switch {
+		case t == "array" && s.Items != nil:
+			labels = append(labels, "array of "+schemaTypeLabel(s.Items))
+		case t == "string" && s.Format != "":
+			labels = append(labels, "string ("+s.Format+")")
+		default:
+			labels = append(labels, t)
+		}
+	}
+	if len(labels) == 0 {
+		return "any"
+	}
+	return strings.Join(labels, " or ")
+}
+
+// propertyDoc returns the description of a property for a table cell, with
+// its allowed values and default.
+func propertyDoc(s *config.Schema) string {
+	var parts []string
+	if s.Description != "" {
+		parts = append(parts, strings.Join(strings.Fields(s.Description), " "))
+	}
+	if len(s.Enum) > 0 {
+		values := make([]string, len(s.Enum))
+		for i, v := range s.Enum {
+			values[i] = "`" + jsonValue(v) + "`"
+		}
+		parts = append(parts, "One of "+strings.Join(values, ", ")+".")
+	}
+	if s.Default != nil {
+		parts = append(parts, "Defaults to `"+string(s.Default)+"`.")
+	}
+	// A description followed by the values ends with a period
+	if len(parts) > 1 && s.Description != "" && !strings.HasSuffix(parts[0], ".") {
+		parts[0] += "."
+	}
+	return strings.ReplaceAll(strings.Join(parts, " "), "|", `\|`)
+}
@@ -0,0 +1,84 @@
+package codegen
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+func TestGenerateToolDocs(t *testing.T) {
+	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Tools: []config.Tool{
+			{
+				Name:        "create_task",
+				Title:       "Create task",
+				Description: "Creates a task in a project.",
+				InputSchema: &config.Schema{Ref: "#/components/schemas/CreateTaskInput"},
+				OutputSchema: &config.Schema{
+					Type:       "object",
+					Properties: map[string]*config.Schema{"id": {Type: "string", Format: "uuid"}},
+				},
+				Hints: &config.ToolHints{Idempotent: true},
+			},
+			{Name: "ping", NoInput: true, Deprecated: true, ReplacedBy: "create_task", RequiresClientCapability: []string{"sampling"}},
+		},
+		Components: config.Components{
+			Schemas: map[string]*config.Schema{
+				"CreateTaskInput": {
+					Type:        "object",
+					Description: "The task to create.",
+					Required:    []string{"title"},
+					Properties: map[string]*config.Schema{
+						"title":    {Type: "string", Description: "Title of the task,\nshown | everywhere."},
+						"priority": {Type: "string", Description: "Priority", Enum: []any{"low", "high"}, Default: json.RawMessage(`"low"`)},
+						"labels":   {Type: "array", Items: &config.Schema{Ref: "#/components/schemas/Label"}},
+						"due":      {Types: []string{"string", "null"}},
+					},
+				},
+				"Label": {Type: "string"},
+			},
+		},
+	}
+	require.NoError(t, spec.Validate())
+
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Output:   outputDir,
+		Exec:     config.ExecConfig{Package: "test", Filename: "server.go", ToolDocs: true},
+		Model:    config.ModelConfig{Package: "test", Filename: "models.go"},
+		Resolver: config.ResolverConfig{Package: "test", Filename: "resolver.go", Type: "Resolver"},
+	}
+	g := New(cfg, spec)
+
+	assert.Equal(t, "# Create task\n\nTool: `create_task`\n\nCreates a task in a project.\n\nBehavior: idempotent.\n\n"+
+		"## Input\n\nThe task to create.\n\n"+
+		"| Name | Type | Required | Description |\n"+
+		"| --- | --- | --- | --- |\n"+
+		"| `due` | string or null | no |  |\n"+
+		"| `labels` | array of Label | no |  |\n"+
+		"| `priority` | string | no | Priority. One of `\"low\"`, `\"high\"`. Defaults to `\"low\"`. |\n"+
+		"| `title` | string | yes | Title of the task, shown \\| everywhere. |\n"+
+		"\n## Output\n\n"+
+		"| Name | Type | Required | Description |\n"+
+		"| --- | --- | --- | --- |\n"+
+		"| `id` | string (uuid) | no |  |\n", g.toolDoc(spec.Tools[0]))
+	assert.Equal(t, "# ping\n\n**Deprecated.** Use `create_task` instead.\n\n## Input\n\nThis tool takes no arguments.\n", g.toolDoc(spec.Tools[1]))
+
+	require.NoError(t, g.Generate(StageModels, StageServer))
+
+	server, err := os.ReadFile(filepath.Join(outputDir, "server.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(server), "\tmcputil.AddToolDoc(server, \"create_task\", \"# Create task\\n\\nTool: `create_task`")
+	assert.Contains(t, string(server), "\tmcputil.AddToolDoc(server, \"ping\", \"# ping\\n\\n**Deprecated.**")
+	assert.Contains(t, string(server), `
+	Resources: map[string][]string{
+		"docs://tools/ping": {"sampling"},
+	},
+`, "the docs of a tool are hidden with the tool")
+}
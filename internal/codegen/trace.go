@@ -0,0 +1,158 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"golang.org/x/tools/imports"
+)
+
+// Trace records how long the steps of a generation take, to find what makes
+// the generation of a large spec slow. Steps with the same name, such as the
+// resolution of the references of every tool, add up. A nil *Trace records
+// nothing.
+//
+// Steps started by concurrent tasks, within Concurrent, are nested in the
+// step open as the tasks started and not in each other; their durations add
+// up, and can exceed the one of their parent.
+type Trace struct {
+	mu    sync.Mutex
+	start time.Time
+	steps []*traceStep
+	index map[string]*traceStep
+	// open holds the steps started and not ended yet, innermost last
+	open []*traceStep
+	// concurrent counts the sections of concurrent tasks open
+	concurrent int
+}
+
+type traceStep struct {
+	key      string
+	name     string
+	depth    int
+	count    int
+	duration time.Duration
+}
+
+// NewTrace returns a trace whose total duration starts now.
+func NewTrace() *Trace {
+	return &Trace{
+		start: time.Now(),
+		index: map[string]*traceStep{},
+	}
+}
+
+// Start starts timing a step and returns the function ending it. Steps
+// started before the end of another step are nested in it.
+//
+// Example:
+//
+//	defer g.trace.Start("load schemas")()
+func (t *Trace) Start(name string) func() {
+	if t == nil {
+		return func() {}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := name
+	if len(t.open) > 0 {
+		key = t.open[len(t.open)-1].key + "/" + name
+	}
+	step, ok := t.index[key]
+	if !ok {
+		step = &traceStep{key: key, name: name, depth: len(t.open)}
+		t.index[key] = step
+		t.steps = append(t.steps, step)
+	}
+
+	nested := t.concurrent == 0
+	if nested {
+		t.open = append(t.open, step)
+	}
+	start := time.Now()
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if nested {
+			t.open = t.open[:len(t.open)-1]
+		}
+		step.count++
+		step.duration += time.Since(start)
+	}
+}
+
+// Concurrent starts a section of concurrent tasks and returns the function
+// ending it: the steps they start are not nested in each other.
+func (t *Trace) Concurrent() func() {
+	if t == nil {
+		return func() {}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.concurrent++
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.concurrent--
+	}
+}
+
+// Write prints the steps in the order they first started, nested steps
+// indented under their parent, with their duration and share of the total.
+func (t *Trace) Write(w io.Writer) error {
+	total := time.Since(t.start)
+
+	width := len("total")
+	for _, step := range t.steps {
+		width = max(width, len(t.label(step)))
+	}
+
+	for _, step := range t.steps {
+		share := float64(step.duration) / float64(total) * 100
+		if _, err := fmt.Fprintf(w, "%-*s %10s %5.1f%%\n", width, t.label(step), step.duration.Round(time.Microsecond), share); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%-*s %10s\n", width, "total", total.Round(time.Microsecond))
+	return err
+}
+
+func (t *Trace) label(step *traceStep) string {
+	label := strings.Repeat("  ", step.depth) + step.name
+	if step.count > 1 {
+		label += fmt.Sprintf(" (x%d)", step.count)
+	}
+	return label
+}
+
+// SetTrace records the duration of the generation steps in t.
+func (g *Generator) SetTrace(t *Trace) {
+	g.trace = t
+	g.typeGen.trace = t
+}
+
+// execute executes a template into buf, once the plugins have changed its
+// data.
+func (g *Generator) execute(tmpl *template.Template, buf *bytes.Buffer, data any) error {
+	if err := g.mutateTemplateData(tmpl, data); err != nil {
+		return err
+	}
+	defer g.trace.Start("execute " + tmpl.Name())()
+	return tmpl.Execute(buf, data)
+}
+
+// formatSource formats generated Go code, name saying which, and fixes its
+// imports as goimports does: unused imports are removed and the missing ones
+// added, resolved from the module of path, the file the code is written to.
+// Templates then need not import exactly the packages the code they render
+// uses.
+func (g *Generator) formatSource(name, path string, src []byte) ([]byte, error) {
+	defer g.trace.Start("format " + name)()
+	return imports.Process(path, src, &imports.Options{Comments: true, TabIndent: true, TabWidth: 8})
+}
@@ -0,0 +1,100 @@
+package codegen
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+func TestTrace(t *testing.T) {
+	trace := NewTrace()
+
+	endStage := trace.Start("stage models")
+	for range 3 {
+		trace.Start("resolve refs")()
+	}
+	endStage()
+	endStage = trace.Start("stage openapi")
+	trace.Start("resolve refs")()
+	endStage()
+
+	var buf strings.Builder
+	require.NoError(t, trace.Write(&buf))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 5)
+	assert.Regexp(t, `^stage models +\S+ +\d+\.\d%$`, lines[0])
+	assert.Regexp(t, `^  resolve refs \(x3\) +\S+ +\d+\.\d%$`, lines[1])
+	assert.Regexp(t, `^stage openapi `, lines[2])
+	assert.Regexp(t, `^  resolve refs +\S+ +\d+\.\d%$`, lines[3])
+	assert.Regexp(t, `^total +\S+$`, lines[4])
+
+	// A nil trace records nothing
+	var none *Trace
+	none.Start("load schemas")()
+}
+
+func TestGenerateTrace(t *testing.T) {
+	specPath := filepath.Join("testdata", "config_based_types.yaml")
+	spec, err := config.LoadMCPSpec(specPath)
+	require.NoError(t, err, "Failed to load spec")
+
+	outputDir := t.TempDir()
+	cfg := &config.Config{
+		Spec:   specPath,
+		Output: outputDir,
+		Exec: config.ExecConfig{
+			Package:  "test",
+			Filename: "server.go",
+		},
+		Model: config.ModelConfig{
+			Package:  "test",
+			Filename: "models.go",
+		},
+		Resolver: config.ResolverConfig{
+			Package:  "test",
+			Filename: "resolver.go",
+			Type:     "Resolver",
+		},
+	}
+
+	trace := NewTrace()
+	gen := New(cfg, spec)
+	gen.SetTrace(trace)
+	require.NoError(t, gen.Generate(StageModels, StageServer))
+
+	var buf strings.Builder
+	require.NoError(t, trace.Write(&buf))
+	out := buf.String()
+	assert.Contains(t, out, "load schemas")
+	assert.Contains(t, out, "\n  resolve refs")
+	assert.Contains(t, out, "\n  format models")
+	assert.Contains(t, out, "\n  execute server.gotpl")
+	assert.Contains(t, out, "\n  write "+filepath.Join(outputDir, "server.go"))
+	assert.NotContains(t, out, "stage resolver")
+}
+
+func TestTraceConcurrent(t *testing.T) {
+	trace := NewTrace()
+
+	endStage := trace.Start("stage models")
+	require.NoError(t, parallel(trace, 8, func(int) error {
+		defer trace.Start("format models")()
+		trace.Start("resolve refs")()
+		return nil
+	}))
+	endStage()
+
+	var buf strings.Builder
+	require.NoError(t, trace.Write(&buf))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 4, "the steps of concurrent tasks are not nested in each other")
+	assert.Regexp(t, `^stage models `, lines[0])
+	assert.Regexp(t, `^  format models \(x8\) `, lines[1])
+	assert.Regexp(t, `^  resolve refs \(x8\) `, lines[2])
+}
@@ -1,11 +1,21 @@
 package codegen
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"go/ast"
 	"go/format"
+	"go/parser"
+	"go/token"
+	"path"
+	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 
+	"go.probo.inc/mcpgen/internal/config"
 	"go.probo.inc/mcpgen/internal/schema"
 )
 
@@ -21,7 +31,47 @@ type TypeGenerator struct {
 	enums          map[string]string
 	imports        map[string]bool
 	schemaVars     map[string]string
+	schemaVarRefs  map[string]map[string]string
+	schemaAliases  map[string]string
 	customMappings map[string]*CustomTypeMapping
+	formatMappings map[string]*CustomTypeMapping
+	strictTypes    map[string]bool
+	directions     map[string]direction
+
+	// optionalStyle is the default style of optional and nullable fields,
+	// see optionalFieldStyle
+	optionalStyle string
+	// enumStringer generates a String method on enum types
+	enumStringer bool
+
+	// splitComponents caches whether components are split into an Input
+	// and an Output variant, see isSplitComponent
+	splitComponents map[string]bool
+
+	// strict is set while generating a strict type and the inline types
+	// nested in it
+	strict bool
+	// direction is set while generating an input or output type and the
+	// types nested in it
+	direction direction
+	// variant and variantOf are the names of the Input or Output variant
+	// being generated and of its component, which names the enums nested
+	// in both variants, see enumTypeName
+	variant   string
+	variantOf string
+	// enumNames holds the names of the enums generated by schema, so that
+	// the variants of a split component and the tool types copying a
+	// component share the enums nested in it
+	enumNames map[*schema.Schema]string
+
+	// formatted holds the formatted declarations of the previous
+	// generation by the SHA-256 of their code, see loadModelsCache
+	formatted map[string][]byte
+	// decls records the declarations of the models files rendered by
+	// GenerateFiles, by file
+	decls map[string][]modelDecl
+
+	trace *Trace
 }
 
 func NewTypeGenerator() *TypeGenerator {
@@ -31,7 +81,15 @@ func NewTypeGenerator() *TypeGenerator {
 		enums:          make(map[string]string),
 		imports:        make(map[string]bool),
 		schemaVars:     make(map[string]string),
+		schemaVarRefs:  make(map[string]map[string]string),
+		schemaAliases:  make(map[string]string),
 		customMappings: make(map[string]*CustomTypeMapping),
+		formatMappings: make(map[string]*CustomTypeMapping),
+		strictTypes:    make(map[string]bool),
+		directions:     make(map[string]direction),
+
+		splitComponents: make(map[string]bool),
+		enumNames:       make(map[*schema.Schema]string),
 	}
 }
 
@@ -39,21 +97,89 @@ func (g *TypeGenerator) AddCustomMapping(schemaName string, mapping *CustomTypeM
 	g.customMappings[schemaName] = mapping
 }
 
+// AddFormatMapping maps every string schema with a format, other than enums
+// and consts, to a custom Go type.
+func (g *TypeGenerator) AddFormatMapping(format string, mapping *CustomTypeMapping) {
+	g.formatMappings[format] = mapping
+}
+
+// formatMapping returns the custom Go type of a string schema mapped by its
+// format, if any.
+func (g *TypeGenerator) formatMapping(s *schema.Schema) *CustomTypeMapping {
+	if s == nil || s.Format == "" || schema.GetType(s) != "string" || len(s.Enum) > 0 || s.Const != nil {
+		return nil
+	}
+	return g.formatMappings[s.Format]
+}
+
+// SetOptionalStyle sets how optional and nullable properties are generated
+// when their schema does not say: config.OptionalStylePointer, the default,
+// config.OptionalStyleOmittable or config.OptionalStyleValue.
+func (g *TypeGenerator) SetOptionalStyle(style string) {
+	g.optionalStyle = style
+}
+
+// SetEnumStringer makes enum types implement fmt.Stringer, returning the
+// name of their constants as the stringer tool would.
+func (g *TypeGenerator) SetEnumStringer(stringer bool) {
+	g.enumStringer = stringer
+}
+
 func (g *TypeGenerator) AddSchema(name string, s *schema.Schema) {
 	g.schemas[name] = s
 }
 
+// AddStrictSchema adds an input schema, as AddInputSchema does, whose
+// generated struct and the inline structs nested in it reject unknown fields.
+func (g *TypeGenerator) AddStrictSchema(name string, s *schema.Schema) {
+	g.AddInputSchema(name, s)
+	g.strictTypes[name] = true
+}
+
 func (g *TypeGenerator) AddSchemaVar(name string, schemaJSON string) {
 	g.schemaVars[name] = schemaJSON
 	g.imports["go.probo.inc/mcpgen/mcp"] = true
 }
 
-func (g *TypeGenerator) Generate(packageName string) ([]byte, error) {
-	var buf strings.Builder
+// AddSharedSchemaVar adds a schema variable like AddSchemaVar, whose
+// references to component schemas are replaced with the variables of refs,
+// by component name.
+func (g *TypeGenerator) AddSharedSchemaVar(name string, schemaJSON string, refs map[string]string) {
+	g.AddSchemaVar(name, schemaJSON)
+	if len(refs) > 0 {
+		g.schemaVarRefs[name] = refs
+	}
+}
+
+// AddSchemaAlias adds a schema variable holding the schema of the variable
+// target.
+func (g *TypeGenerator) AddSchemaAlias(name, target string) {
+	g.schemaVars[name] = ""
+	g.schemaAliases[name] = target
+}
 
-	buf.WriteString("// Code generated by mcpgen. DO NOT EDIT.\n\n")
-	buf.WriteString(fmt.Sprintf("package %s\n\n", packageName))
+// HasSchemaVar reports whether the schema variable name was added.
+func (g *TypeGenerator) HasSchemaVar(name string) bool {
+	_, ok := g.schemaVars[name]
+	return ok
+}
 
+func (g *TypeGenerator) Generate(packageName string) ([]byte, error) {
+	files, err := g.GenerateFiles(packageName, nil)
+	if err != nil {
+		return nil, err
+	}
+	return files[""], nil
+}
+
+// GenerateFiles generates the models like Generate, moving the types of the
+// schemas of files, which maps schema names to file names, to their own
+// file. A type belongs to the schema whose Go type name is the longest
+// prefix of its name, so the inline types nested in a schema, such as
+// TaskStatus, follow it; the types of the other schemas, the schema
+// variables and the types of no schema stay in the main file, under the ""
+// key.
+func (g *TypeGenerator) GenerateFiles(packageName string, files map[string]string) (map[string][]byte, error) {
 	// Sort schema names for deterministic output
 	schemaNames := make([]string, 0, len(g.schemas))
 	for name := range g.schemas {
@@ -61,7 +187,27 @@ func (g *TypeGenerator) Generate(packageName string) ([]byte, error) {
 	}
 	sort.Strings(schemaNames)
 
+	goTypeNames := make(map[string]string, len(schemaNames))
+	for _, name := range schemaNames {
+		goTypeNames[toGoTypeName(name)] = name
+	}
+
+	// Components come first, so that the tool types copying them use the
+	// names of the components for the enums nested in them
+	generateOrder := make([]string, 0, len(schemaNames))
+	for _, name := range schemaNames {
+		if g.directions[name] == anyDirection {
+			generateOrder = append(generateOrder, name)
+		}
+	}
 	for _, name := range schemaNames {
+		if g.directions[name] != anyDirection {
+			generateOrder = append(generateOrder, name)
+		}
+	}
+
+	endTypes := g.trace.Start("generate types")
+	for _, name := range generateOrder {
 		s := g.schemas[name]
 		typeName := toGoTypeName(name)
 
@@ -69,44 +215,56 @@ func (g *TypeGenerator) Generate(packageName string) ([]byte, error) {
 			continue
 		}
 
-		typeCode, err := g.generateType(typeName, s, 0)
-		if err != nil {
-			return nil, fmt.Errorf("failed to generate type for %s: %w", name, err)
+		// A schema used both ways with readOnly or writeOnly properties
+		// generates an Input and an Output variant
+		directions := []direction{g.directions[name]}
+		if directions[0] == anyDirection && g.isSplitComponent(name) {
+			directions = []direction{inputDirection, outputDirection}
 		}
 
-		if typeCode != "" && g.types[typeName] == "" {
-			g.types[typeName] = typeCode
-		}
-	}
+		for _, dir := range directions {
+			variantName := typeName
+			if len(directions) > 1 {
+				variantName += dir.suffix()
+				if other, ok := goTypeNames[variantName]; ok {
+					return nil, fmt.Errorf("schema %s has readOnly or writeOnly properties and generates %s, which is already the type of %s", name, variantName, other)
+				}
+			}
 
-	if len(g.imports) > 0 {
-		buf.WriteString("import (\n")
-		// Sort imports for deterministic output
-		imports := make([]string, 0, len(g.imports))
-		for imp := range g.imports {
-			imports = append(imports, imp)
-		}
-		sort.Strings(imports)
-		for _, imp := range imports {
-			buf.WriteString(fmt.Sprintf("\t\"%s\"\n", imp))
-		}
-		buf.WriteString(")\n\n")
-	}
+			g.strict = g.strictTypes[name]
+			g.direction = dir
+			if variantName != typeName {
+				g.variant, g.variantOf = variantName, typeName
+			}
+			typeCode, err := g.generateType(variantName, s, 0)
+			g.strict = false
+			g.direction = anyDirection
+			g.variant, g.variantOf = "", ""
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate type for %s: %w", name, err)
+			}
 
-	if len(g.schemaVars) > 0 {
-		buf.WriteString("// Tool input schemas\n")
-		buf.WriteString("var (\n")
-		// Sort schema var names for deterministic output
-		varNames := make([]string, 0, len(g.schemaVars))
-		for varName := range g.schemaVars {
-			varNames = append(varNames, varName)
+			if typeCode != "" && g.types[variantName] == "" {
+				g.types[variantName] = typeCode
+			}
 		}
-		sort.Strings(varNames)
-		for _, varName := range varNames {
-			schemaJSON := g.schemaVars[varName]
-			buf.WriteString(fmt.Sprintf("\t%s = mcp.MustUnmarshalSchema(`%s`)\n", varName, schemaJSON))
+	}
+	endTypes()
+
+	// Group the type and enum code by file, in the order of the single file
+	var (
+		order      = []string{""}
+		chunks     = map[string][]string{}
+		chunkTypes = map[string][]string{}
+	)
+	owners := g.typeOwners(schemaNames)
+	add := func(typeName, code string) {
+		file := files[owners[typeName]]
+		if _, ok := chunks[file]; !ok && file != "" {
+			order = append(order, file)
 		}
-		buf.WriteString(")\n\n")
+		chunks[file] = append(chunks[file], code)
+		chunkTypes[file] = append(chunkTypes[file], typeName)
 	}
 
 	// Sort enum names for deterministic output
@@ -116,9 +274,7 @@ func (g *TypeGenerator) Generate(packageName string) ([]byte, error) {
 	}
 	sort.Strings(enumNames)
 	for _, enumName := range enumNames {
-		enumCode := g.enums[enumName]
-		buf.WriteString(enumCode)
-		buf.WriteString("\n\n")
+		add(enumName, g.enums[enumName])
 	}
 
 	written := make(map[string]bool)
@@ -127,8 +283,7 @@ func (g *TypeGenerator) Generate(packageName string) ([]byte, error) {
 	for _, name := range schemaNames {
 		typeName := toGoTypeName(name)
 		if typeCode := g.types[typeName]; typeCode != "" {
-			buf.WriteString(typeCode)
-			buf.WriteString("\n\n")
+			add(typeName, typeCode)
 			written[typeName] = true
 		}
 	}
@@ -142,28 +297,214 @@ func (g *TypeGenerator) Generate(packageName string) ([]byte, error) {
 	for _, typeName := range typeNames {
 		typeCode := g.types[typeName]
 		if !written[typeName] && typeCode != "" {
-			buf.WriteString(typeCode)
-			buf.WriteString("\n\n")
+			add(typeName, typeCode)
 		}
 	}
 
-	formatted, err := format.Source([]byte(buf.String()))
+	sourceNames := make(map[string]string, len(files))
+	for name, file := range files {
+		sourceNames[file] = name
+	}
+
+	endFormat := g.trace.Start("format models")
+	defer endFormat()
+	codes := make([][]byte, len(order))
+	decls := make([][]modelDecl, len(order))
+	err := parallel(g.trace, len(order), func(i int) error {
+		file := order[i]
+		code, fileDecls, err := g.renderModels(packageName, sourceNames[file], file == "", len(files) > 0, chunkTypes[file], chunks[file])
+		codes[i] = code
+		decls[i] = fileDecls
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to format generated code: %w\n%s", err, buf.String())
+		return nil, err
 	}
 
-	return formatted, nil
+	result := make(map[string][]byte, len(order))
+	g.decls = make(map[string][]modelDecl, len(order))
+	for i, file := range order {
+		result[file] = codes[i]
+		g.decls[file] = decls[i]
+	}
+	return result, nil
+}
+
+// renderModels renders a models file holding chunks, the code of the types
+// and enums named by types. The main file holds the schema variables; the
+// file of a schema source says so in its header. When the models are split,
+// only the imports the file uses are kept. The declarations of the file are
+// returned along with it.
+func (g *TypeGenerator) renderModels(packageName, source string, main, split bool, types, chunks []string) ([]byte, []modelDecl, error) {
+	var buf strings.Builder
+
+	if source != "" {
+		buf.WriteString(fmt.Sprintf("%s%s schema. DO NOT EDIT.\n\n", schemaFileHeader, source))
+	} else {
+		buf.WriteString("// Code generated by mcpgen. DO NOT EDIT.\n\n")
+	}
+	buf.WriteString(fmt.Sprintf("package %s\n", packageName))
+
+	decls := make([]string, 0, len(chunks)+1)
+	declTypes := make([]string, 0, len(chunks)+1)
+	if main && len(g.schemaVars) > 0 {
+		var vars strings.Builder
+		vars.WriteString("// Tool input schemas\n")
+		vars.WriteString("var (\n")
+		// Sort schema var names for deterministic output
+		varNames := make([]string, 0, len(g.schemaVars))
+		for varName := range g.schemaVars {
+			varNames = append(varNames, varName)
+		}
+		sort.Strings(varNames)
+		for _, varName := range varNames {
+			schemaJSON := g.schemaVars[varName]
+			if target := g.schemaAliases[varName]; target != "" {
+				vars.WriteString(fmt.Sprintf("\t%s = %s\n", varName, target))
+				continue
+			}
+			if refs := g.schemaVarRefs[varName]; len(refs) > 0 {
+				vars.WriteString(fmt.Sprintf("\t%s = mcp.MustUnmarshalSchemaRefs(`%s`, mcp.SchemaRefs{%s})\n", varName, schemaJSON, schemaRefsLiteral(refs)))
+				continue
+			}
+			vars.WriteString(fmt.Sprintf("\t%s = mcp.MustUnmarshalSchema(`%s`)\n", varName, schemaJSON))
+		}
+		vars.WriteString(")")
+		decls = append(decls, vars.String())
+		declTypes = append(declTypes, "")
+	}
+	decls = append(decls, chunks...)
+	declTypes = append(declTypes, types...)
+
+	// Sort imports for deterministic output
+	imports := make([]string, 0, len(g.imports))
+	for imp := range g.imports {
+		imports = append(imports, imp)
+	}
+	sort.Strings(imports)
+	if split {
+		imports = usedImports(strings.Join(decls, "\n\n"), imports)
+	}
+	if len(imports) > 0 {
+		// Group the standard library imports first, as goimports does
+		sort.SliceStable(imports, func(i, j int) bool {
+			return isStdImport(imports[i]) && !isStdImport(imports[j])
+		})
+		buf.WriteString("\nimport (\n")
+		for i, imp := range imports {
+			if i > 0 && isStdImport(imports[i-1]) && !isStdImport(imp) {
+				buf.WriteString("\n")
+			}
+			buf.WriteString(fmt.Sprintf("\t\"%s\"\n", imp))
+		}
+		buf.WriteString(")\n")
+	}
+
+	// Format the declarations concurrently rather than the whole file at
+	// once, the longest step of the generation of large specs. gofmt
+	// formats top-level declarations independently of each other, so the
+	// declarations unchanged since the previous generation keep their
+	// formatting.
+	formatted, hashes, err := g.formatCachedDecls(decls)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The package clause, the imports and the declarations are separated
+	// by an empty line
+	fileDecls := make([]modelDecl, len(decls))
+	for i, decl := range formatted {
+		buf.WriteString("\n")
+		buf.Write(decl)
+		fileDecls[i] = modelDecl{Type: declTypes[i], SHA256: hashes[i], Size: len(decl)}
+	}
+
+	return []byte(buf.String()), fileDecls, nil
+}
+
+// formatCachedDecls formats decls as formatDecls does, taking the
+// declarations whose code is unchanged since the previous generation from
+// formatted. It also returns the SHA-256 of the code of each declaration.
+func (g *TypeGenerator) formatCachedDecls(decls []string) ([][]byte, []string, error) {
+	formatted := make([][]byte, len(decls))
+	hashes := make([]string, len(decls))
+	var changed []string
+	var changedIndexes []int
+	for i, decl := range decls {
+		sum := sha256.Sum256([]byte(decl))
+		hashes[i] = hex.EncodeToString(sum[:])
+		if cached, ok := g.formatted[hashes[i]]; ok {
+			formatted[i] = cached
+			continue
+		}
+		changed = append(changed, decl)
+		changedIndexes = append(changedIndexes, i)
+	}
+
+	changedFormatted, err := formatDecls(g.trace, changed)
+	if err != nil {
+		return nil, nil, err
+	}
+	for j, i := range changedIndexes {
+		formatted[i] = changedFormatted[j]
+	}
+	return formatted, hashes, nil
+}
+
+// isStdImport reports whether path is a package of the standard library,
+// whose first element has no dot.
+func isStdImport(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return !strings.Contains(first, ".")
+}
+
+// formatDecls formats Go top-level declarations with gofmt, each ending
+// with a newline.
+func formatDecls(trace *Trace, decls []string) ([][]byte, error) {
+	const header = "package p\n\n"
+
+	formatted := make([][]byte, len(decls))
+	err := parallel(trace, len(decls), func(i int) error {
+		src, err := format.Source([]byte(header + decls[i]))
+		if err != nil {
+			return fmt.Errorf("failed to format generated code: %w\n%s", err, decls[i])
+		}
+		formatted[i] = src[len(header):]
+		return nil
+	})
+	return formatted, err
 }
 
 func (g *TypeGenerator) generateType(name string, s *schema.Schema, depth int) (string, error) {
+	if flat := g.flattenAllOf(s); flat != nil {
+		s = flat
+	}
+
 	schemaType := schema.GetType(s)
 
 	if schemaType == "" && s.Properties != nil && len(s.Properties) > 0 {
 		return g.generateStruct(name, s, depth)
 	}
 
+	if schema.IsFalse(s) {
+		return "", fmt.Errorf("schema %s is false and accepts no value", name)
+	}
+
+	if union := g.discriminatedUnion(name, s); union != nil {
+		return g.generateUnion(name, s, union)
+	}
+
+	if s.Const != nil {
+		if value, ok := (*s.Const).(string); ok {
+			return g.generateConst(name, s, value)
+		}
+	}
+
+	// true, {} and schemas without a type accept any value
 	if schemaType == "" && s.Properties == nil {
-		return "", fmt.Errorf("unsupported schema type: %q (no type and no properties for %s)", schemaType, name)
+		if depth == 0 {
+			return g.generatePrimitiveTypeAlias(name, s, "any")
+		}
+		return "", nil
 	}
 
 	if len(s.Enum) > 0 {
@@ -172,6 +513,13 @@ func (g *TypeGenerator) generateType(name string, s *schema.Schema, depth int) (
 
 	switch schemaType {
 	case "object":
+		if value := mapValueSchema(s); value != nil {
+			valueType, err := g.elemType(value, name+"Value")
+			if err != nil {
+				return "", err
+			}
+			return g.generatePrimitiveTypeAlias(name, s, "map[string]"+valueType)
+		}
 		return g.generateStruct(name, s, depth)
 	case "array":
 		return g.generateArrayType(name, s, depth)
@@ -213,30 +561,91 @@ func (g *TypeGenerator) generateStruct(name string, s *schema.Schema, depth int)
 	} else {
 		buf.WriteString(fmt.Sprintf("// %s represents the schema\n", name))
 	}
+	if g.isDeprecatedType(name, s) {
+		buf.WriteString(deprecatedComment(name, "", true))
+	}
 
 	buf.WriteString(fmt.Sprintf("type %s struct {\n", name))
 
-	// Sort property names for deterministic output
-	propNames := make([]string, 0, len(s.Properties))
-	for propName := range s.Properties {
-		propNames = append(propNames, propName)
+	// Properties declared by the then and else branches of an if/then/else
+	// get a field too, unless the object declares them with a type
+	conditional := conditionalProperties(s)
+	for propName, propSchema := range s.Properties {
+		if schema.GetType(propSchema) != "" || propSchema.Ref != "" || len(propSchema.Properties) > 0 {
+			delete(conditional, propName)
+		}
 	}
-	sort.Strings(propNames)
 
+	// Fields follow the spec order of the properties when the schema records
+	// it, conditional properties last, and are sorted by name otherwise, for
+	// deterministic output
+	propNames := schema.PropertyNames(s)
+	var conditionalNames []string
+	for propName := range conditional {
+		if _, ok := s.Properties[propName]; !ok {
+			conditionalNames = append(conditionalNames, propName)
+		}
+	}
+	sort.Strings(conditionalNames)
+	propNames = append(propNames, conditionalNames...)
+	if _, ordered := s.Extra[schema.PropertyOrderKey]; !ordered {
+		sort.Strings(propNames)
+	}
+
+	var fieldNames []string
+	var defaults []fieldDefault
+	goFieldNames := make(map[string]string, len(propNames))
 	for _, propName := range propNames {
 		propSchema := s.Properties[propName]
-		fieldName := toGoFieldName(propName)
+
+		// A false property can never be set, so it gets no field
+		if propSchema != nil && schema.IsFalse(propSchema) {
+			continue
+		}
+		if g.skipsProperty(propSchema) {
+			continue
+		}
+		fieldNames = append(fieldNames, propName)
+
+		fieldName, err := goFieldName(propName, propSchema)
+		if err != nil {
+			return "", fmt.Errorf("field %s.%s: %w", name, propName, err)
+		}
+		if other, ok := goFieldNames[fieldName]; ok {
+			return "", fmt.Errorf("properties %s and %s of %s are both named %s, name one with x-go-name", other, propName, name, fieldName)
+		}
+		goFieldNames[fieldName] = propName
 		hint := name + fieldName
 
+		if branches, ok := conditional[propName]; ok {
+			field, err := g.conditionalField(name, fieldName, s.If, branches)
+			if err != nil {
+				return "", fmt.Errorf("failed to generate field %s: %w", propName, err)
+			}
+			tag, err := fieldTag(propName+",omitempty", propSchema)
+			if err != nil {
+				return "", fmt.Errorf("field %s.%s: %w", name, propName, err)
+			}
+			buf.WriteString(field)
+			buf.WriteString(" " + tag + "\n")
+			continue
+		}
+
 		isRequired := schema.IsRequired(s, propName)
 		isOmittable := schema.IsOmittable(propSchema)
+		isNullable, baseSchema := isNullableType(propSchema)
 
 		// Validate that omittable is only used on nullable fields
-		if isOmittable {
-			isNullable, _ := isNullableType(propSchema)
-			if !isNullable {
-				return "", fmt.Errorf("field %s.%s has omittable annotation but is not nullable (omittable only works with nullable fields)", name, propName)
-			}
+		if isOmittable && !isNullable {
+			return "", fmt.Errorf("field %s.%s has omittable annotation but is not nullable (omittable only works with nullable fields)", name, propName)
+		}
+
+		style, err := optionalFieldStyle(g.optionalStyle, s, propSchema)
+		if err != nil {
+			return "", fmt.Errorf("field %s.%s: %w", name, propName, err)
+		}
+		if style == config.OptionalStyleOmittable && (!isRequired || isNullable) {
+			isOmittable = true
 		}
 
 		fieldType, err := g.goType(propSchema, hint)
@@ -244,36 +653,366 @@ func (g *TypeGenerator) generateStruct(name string, s *schema.Schema, depth int)
 			return "", fmt.Errorf("failed to generate field %s: %w", propName, err)
 		}
 
-		if isOmittable {
+		valueType := fieldType
+		switch {
+		case isOmittable:
 			fieldType = fmt.Sprintf("mcp.Omittable[%s]", fieldType)
 			g.imports["go.probo.inc/mcpgen/mcp"] = true
-		} else if !isRequired && !isPointerType(fieldType) {
+		case style == config.OptionalStyleValue:
+			// Null decodes as the zero value of the type, references to
+			// components stay pointers as when they are required
+			if isNullable {
+				baseType, err := g.goType(baseSchema, hint)
+				if err != nil {
+					return "", fmt.Errorf("failed to generate field %s: %w", propName, err)
+				}
+				fieldType = baseType
+			}
+		case !isRequired && !isPointerType(fieldType):
 			fieldType = "*" + fieldType
 		}
 
+		// Defaults are applied to the fields left nil or unset
+		switch {
+		case isRequired || propSchema.Default == nil:
+		case isOmittable:
+			defaults = append(defaults, fieldDefault{name: fieldName, typ: "*" + valueType, value: propSchema.Default, omittable: true})
+		case isPointerType(fieldType):
+			defaults = append(defaults, fieldDefault{name: fieldName, typ: fieldType, value: propSchema.Default})
+		}
+
 		if propSchema.Description != "" {
 			buf.WriteString(formatComment(propSchema.Description, "\t"))
 		}
+		if propSchema.Deprecated {
+			buf.WriteString(deprecatedComment(fieldName, "\t", propSchema.Description != ""))
+		}
 
 		buf.WriteString(fmt.Sprintf("\t%s %s", fieldName, fieldType))
 
+		// An unset Omittable is a zero struct, which omitempty keeps
 		jsonTag := propName
-		if !isRequired {
+		switch {
+		case isRequired:
+		case isOmittable:
+			jsonTag += ",omitzero"
+		default:
 			jsonTag += ",omitempty"
 		}
-		buf.WriteString(fmt.Sprintf(" `json:\"%s\"`", jsonTag))
+		tag, err := fieldTag(jsonTag, propSchema)
+		if err != nil {
+			return "", fmt.Errorf("field %s.%s: %w", name, propName, err)
+		}
+		buf.WriteString(" " + tag)
 
 		buf.WriteString("\n")
 	}
 
 	buf.WriteString("}")
 
+	if g.strict || schema.IsClosed(s) {
+		buf.WriteString("\n\n")
+		buf.WriteString(g.generateClosedUnmarshal(name, fieldNames))
+	}
+
+	if len(defaults) > 0 {
+		buf.WriteString("\n\n")
+		buf.WriteString(g.generateApplyDefaults(name, defaults))
+	}
+
 	return buf.String(), nil
 }
 
-// isPointerType checks if the given type string is already a pointer or slice type
+// fieldDefault is an optional field whose schema declares a default. The
+// typ of an Omittable field is a pointer to the type of its value.
+type fieldDefault struct {
+	name      string
+	typ       string
+	value     json.RawMessage
+	omittable bool
+}
+
+// generateApplyDefaults generates the ApplyDefaults method, setting the
+// optional fields left nil, or Omittable fields left unset, to their schema
+// default. Scalar defaults are assigned as literals, others are decoded
+// from their JSON.
+func (g *TypeGenerator) generateApplyDefaults(name string, defaults []fieldDefault) string {
+	var buf strings.Builder
+
+	buf.WriteString("// ApplyDefaults sets the optional fields left unset to their schema default\n")
+	buf.WriteString(fmt.Sprintf("func (v *%s) ApplyDefaults() {\n", name))
+	for _, d := range defaults {
+		if d.omittable {
+			buf.WriteString(fmt.Sprintf("\tif !v.%s.IsSet() {\n", d.name))
+		} else {
+			buf.WriteString(fmt.Sprintf("\tif v.%s == nil {\n", d.name))
+		}
+		literal, ok := g.defaultLiteral(d.typ, d.value)
+		switch {
+		case ok && d.omittable:
+			buf.WriteString(fmt.Sprintf("\t\tv.%s = mcp.NewOmittable(%s(%s))\n", d.name, strings.TrimPrefix(d.typ, "*"), literal))
+		case ok:
+			buf.WriteString(fmt.Sprintf("\t\tvalue := %s(%s)\n", strings.TrimPrefix(d.typ, "*"), literal))
+			buf.WriteString(fmt.Sprintf("\t\tv.%s = &value\n", d.name))
+		default:
+			// Omittable decodes the default as set
+			buf.WriteString(fmt.Sprintf("\t\t_ = json.Unmarshal([]byte(%q), &v.%s)\n", string(d.value), d.name))
+			g.imports["encoding/json"] = true
+		}
+		buf.WriteString("\t}\n")
+	}
+	buf.WriteString("}")
+
+	return buf.String()
+}
+
+// defaultLiteral returns the Go literal of a string, number or boolean
+// default, when the field points to a builtin scalar of the same kind or to
+// an enum type.
+func (g *TypeGenerator) defaultLiteral(fieldType string, value json.RawMessage) (string, bool) {
+	elem, ok := strings.CutPrefix(fieldType, "*")
+	if !ok {
+		return "", false
+	}
+	_, isEnum := g.enums[elem]
+	if named, ok := g.schemas[elem]; ok && len(named.Enum) > 0 {
+		isEnum = true
+	}
+
+	var v any
+	if err := json.Unmarshal(value, &v); err != nil {
+		return "", false
+	}
+	switch v := v.(type) {
+	case string:
+		if elem == "string" || isEnum {
+			return strconv.Quote(v), true
+		}
+	case float64:
+		if elem == "float64" || isEnum || (elem == "int" && v == float64(int64(v))) {
+			return strconv.FormatFloat(v, 'f', -1, 64), true
+		}
+	case bool:
+		if elem == "bool" {
+			return strconv.FormatBool(v), true
+		}
+	}
+	return "", false
+}
+
+// generateClosedUnmarshal generates an UnmarshalJSON method rejecting the
+// fields a struct does not declare, for schemas with additionalProperties: false.
+func (g *TypeGenerator) generateClosedUnmarshal(name string, fieldNames []string) string {
+	var buf strings.Builder
+
+	quoted := make([]string, len(fieldNames))
+	for i, fieldName := range fieldNames {
+		quoted[i] = fmt.Sprintf("%q", fieldName)
+	}
+
+	buf.WriteString("// UnmarshalJSON implements json.Unmarshaler, rejecting unknown fields\n")
+	buf.WriteString(fmt.Sprintf("func (v *%s) UnmarshalJSON(data []byte) error {\n", name))
+	buf.WriteString("\tvar fields map[string]json.RawMessage\n")
+	buf.WriteString("\tif err := json.Unmarshal(data, &fields); err != nil {\n")
+	buf.WriteString("\t\treturn err\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\tfor field := range fields {\n")
+	buf.WriteString("\t\tswitch field {\n")
+	if len(quoted) > 0 {
+		buf.WriteString(fmt.Sprintf("\t\tcase %s:\n", strings.Join(quoted, ", ")))
+	}
+	buf.WriteString("\t\tdefault:\n")
+	buf.WriteString(fmt.Sprintf("\t\t\treturn mcp.NewError(mcp.MessageUnknownField, field, %q)\n", name))
+	buf.WriteString("\t\t}\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString(fmt.Sprintf("\ttype plain %s\n", name))
+	buf.WriteString("\treturn json.Unmarshal(data, (*plain)(v))\n")
+	buf.WriteString("}")
+
+	g.imports["encoding/json"] = true
+	g.imports["go.probo.inc/mcpgen/mcp"] = true
+
+	return buf.String()
+}
+
+// conditionalProperty is a property declared by the then or else branch of
+// an if/then/else object schema.
+type conditionalProperty struct {
+	then, els *schema.Schema
+}
+
+// conditionalProperties returns the properties declared by the then and else
+// branches of s, when it has an if/then/else.
+func conditionalProperties(s *schema.Schema) map[string]*conditionalProperty {
+	if s.If == nil {
+		return nil
+	}
+
+	props := map[string]*conditionalProperty{}
+	for _, branch := range []*schema.Schema{s.Then, s.Else} {
+		if branch == nil {
+			continue
+		}
+		for propName, propSchema := range branch.Properties {
+			if schema.IsFalse(propSchema) {
+				continue
+			}
+			prop, ok := props[propName]
+			if !ok {
+				prop = &conditionalProperty{}
+				props[propName] = prop
+			}
+			if branch == s.Then {
+				prop.then = propSchema
+			} else {
+				prop.els = propSchema
+			}
+		}
+	}
+	return props
+}
+
+// conditionalField returns the field declaration, without its tag, of a
+// property declared by the then or else branch of the if/then/else of the
+// struct name. The field is optional and documented with the condition it
+// applies under. When the branches declare it with different types, it gets
+// a named any type documenting each of them.
+func (g *TypeGenerator) conditionalField(name, fieldName string, ifSchema *schema.Schema, prop *conditionalProperty) (string, error) {
+	hint := name + fieldName
+	thenHint, elseHint := hint, hint
+	if prop.then != nil && prop.els != nil {
+		thenHint, elseHint = hint+"Then", hint+"Else"
+	}
+
+	var thenType, elseType string
+	var err error
+	if prop.then != nil {
+		if thenType, err = g.goType(prop.then, thenHint); err != nil {
+			return "", err
+		}
+	}
+	if prop.els != nil {
+		if elseType, err = g.goType(prop.els, elseHint); err != nil {
+			return "", err
+		}
+	}
+
+	condition := describeCondition(ifSchema)
+	var buf strings.Builder
+	for _, branch := range []*schema.Schema{prop.then, prop.els} {
+		if branch != nil && branch.Description != "" {
+			buf.WriteString(formatComment(branch.Description, "\t"))
+			buf.WriteString("\t//\n")
+			break
+		}
+	}
+
+	var fieldType string
+	switch {
+	case prop.els == nil:
+		fieldType = thenType
+		buf.WriteString(formatComment(fmt.Sprintf("Only used when %s.", condition), "\t"))
+	case prop.then == nil:
+		fieldType = elseType
+		buf.WriteString(formatComment(fmt.Sprintf("Only used unless %s.", condition), "\t"))
+	case thenType == elseType:
+		fieldType = thenType
+	default:
+		var doc strings.Builder
+		doc.WriteString(fmt.Sprintf("// %s holds the %s field of %s, whose type depends on its if/then/else:\n", hint, fieldName, name))
+		doc.WriteString("//\n")
+		doc.WriteString(fmt.Sprintf("//   - %s when %s\n", thenType, condition))
+		doc.WriteString(fmt.Sprintf("//   - %s otherwise\n", elseType))
+		g.types[hint] = doc.String() + fmt.Sprintf("type %s any", hint)
+		return buf.String() + fmt.Sprintf("\t%s %s", fieldName, hint), nil
+	}
+
+	if !isPointerType(fieldType) {
+		fieldType = "*" + fieldType
+	}
+	return buf.String() + fmt.Sprintf("\t%s %s", fieldName, fieldType), nil
+}
+
+// describeCondition describes the values an if schema matches, for doc
+// comments: the const and enum values of its properties and its required
+// properties.
+func describeCondition(s *schema.Schema) string {
+	propNames := make([]string, 0, len(s.Properties))
+	for propName := range s.Properties {
+		propNames = append(propNames, propName)
+	}
+	sort.Strings(propNames)
+
+	var parts []string
+	described := map[string]bool{}
+	for _, propName := range propNames {
+		prop := s.Properties[propName]
+		var values []string
+		if prop.Const != nil {
+			values = append(values, jsonValue(*prop.Const))
+		}
+		for _, v := range prop.Enum {
+			values = append(values, jsonValue(v))
+		}
+		switch {
+		case len(values) == 1:
+			parts = append(parts, fmt.Sprintf("%s is %s", propName, values[0]))
+		case len(values) > 1:
+			parts = append(parts, fmt.Sprintf("%s is one of %s", propName, strings.Join(values, ", ")))
+		default:
+			continue
+		}
+		described[propName] = true
+	}
+	for _, propName := range s.Required {
+		if !described[propName] {
+			parts = append(parts, propName+" is set")
+		}
+	}
+
+	if len(parts) == 0 {
+		return "the if schema matches"
+	}
+	return strings.Join(parts, " and ")
+}
+
+func jsonValue(v any) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// optionalStyleKey is the annotation overriding the optional field style of
+// the properties of an object schema, or of a single property.
+const optionalStyleKey = "go.probo.inc/mcpgen/optional-style"
+
+// optionalFieldStyle returns the style of the field of a property: the
+// style annotated on the property, else on its object, else defaultStyle.
+func optionalFieldStyle(defaultStyle string, object, property *schema.Schema) (string, error) {
+	style := defaultStyle
+	for _, s := range []*schema.Schema{object, property} {
+		value, ok := s.Extra[optionalStyleKey]
+		if !ok {
+			continue
+		}
+		annotated, _ := value.(string)
+		if !config.IsOptionalStyle(annotated) {
+			return "", fmt.Errorf("%s must be pointer, omittable or value, got %v", optionalStyleKey, value)
+		}
+		style = annotated
+	}
+	if style == "" {
+		style = config.OptionalStylePointer
+	}
+	return style, nil
+}
+
+// isPointerType checks if the given type string is already a pointer, slice or
+// map type, which are nil when unset
 func isPointerType(t string) bool {
-	return len(t) > 0 && (t[0] == '*' || t[0] == '[')
+	return strings.HasPrefix(t, "*") || strings.HasPrefix(t, "[]") || strings.HasPrefix(t, "map[")
 }
 
 func isNullableType(s *schema.Schema) (bool, *schema.Schema) {
@@ -307,11 +1046,12 @@ func isNullableType(s *schema.Schema) (bool, *schema.Schema) {
 		}
 
 		if hasNull && otherType != "" && len(s.Types) == 2 {
-			syntheticSchema := &schema.Schema{
-				Type:   otherType,
-				Format: s.Format,
-			}
-			return true, syntheticSchema
+			// The schema itself with the other type, keeping its enum,
+			// format, items or properties
+			baseSchema := *s
+			baseSchema.Type = otherType
+			baseSchema.Types = nil
+			return true, &baseSchema
 		}
 	}
 
@@ -319,20 +1059,11 @@ func isNullableType(s *schema.Schema) (bool, *schema.Schema) {
 }
 
 func (g *TypeGenerator) generateArrayType(name string, s *schema.Schema, depth int) (string, error) {
-	if s.Items == nil {
-		if depth == 0 {
-			return g.generatePrimitiveTypeAlias(name, s, "[]any")
-		}
-		return "[]any", nil
-	}
-
-	itemType, err := g.goType(s.Items, name+"Item")
+	arrayType, err := g.arrayType(s, name)
 	if err != nil {
 		return "", err
 	}
 
-	arrayType := fmt.Sprintf("[]%s", itemType)
-
 	if depth == 0 {
 		return g.generatePrimitiveTypeAlias(name, s, arrayType)
 	}
@@ -340,6 +1071,84 @@ func (g *TypeGenerator) generateArrayType(name string, s *schema.Schema, depth i
 	return arrayType, nil
 }
 
+// elemType returns the Go type of the items of an array or the values of a
+// map. References to enum components are not pointers there, so that arrays
+// and maps of enums hold the enum values, each checked when decoded.
+func (g *TypeGenerator) elemType(s *schema.Schema, hint string) (string, error) {
+	goType, err := g.goType(s, hint)
+	if err != nil {
+		return "", err
+	}
+	if g.isEnumRef(s) {
+		return strings.TrimPrefix(goType, "*"), nil
+	}
+	return goType, nil
+}
+
+// isEnumRef reports whether s references a string enum component generated
+// as an enum type.
+func (g *TypeGenerator) isEnumRef(s *schema.Schema) bool {
+	const prefix = "#/components/schemas/"
+	name, ok := strings.CutPrefix(s.Ref, prefix)
+	if !ok || g.customMappings[name] != nil {
+		return false
+	}
+	component, ok := g.schemas[name]
+	if !ok || len(component.Enum) == 0 || component.Const != nil || schema.GetType(component) != "string" {
+		return false
+	}
+	nullable, _ := isNullableType(component)
+	return !nullable
+}
+
+// arrayType returns the Go type of an array schema. The elements of a tuple,
+// declared with prefixItems, get the type they all share, or any. A tuple of
+// a fixed length, closed by items: false and requiring every element, is a
+// Go array.
+func (g *TypeGenerator) arrayType(s *schema.Schema, hint string) (string, error) {
+	if len(s.PrefixItems) == 0 {
+		if s.Items == nil {
+			return "[]any", nil
+		}
+		itemType, err := g.elemType(s.Items, hint+"Item")
+		if err != nil {
+			return "", err
+		}
+		return "[]" + itemType, nil
+	}
+
+	var elemTypes []string
+	for i, item := range s.PrefixItems {
+		itemType, err := g.elemType(item, fmt.Sprintf("%sItem%d", hint, i))
+		if err != nil {
+			return "", err
+		}
+		elemTypes = append(elemTypes, itemType)
+	}
+
+	closed := schema.IsFalse(s.Items) || (s.Items == nil && schema.IsFalse(s.UnevaluatedItems))
+	if s.Items != nil && !closed {
+		itemType, err := g.elemType(s.Items, hint+"Item")
+		if err != nil {
+			return "", err
+		}
+		elemTypes = append(elemTypes, itemType)
+	}
+
+	elemType := elemTypes[0]
+	for _, t := range elemTypes[1:] {
+		if t != elemType {
+			elemType = "any"
+			break
+		}
+	}
+
+	if closed && s.MinItems != nil && *s.MinItems == len(s.PrefixItems) {
+		return fmt.Sprintf("[%d]%s", len(s.PrefixItems), elemType), nil
+	}
+	return "[]" + elemType, nil
+}
+
 func (g *TypeGenerator) goType(s *schema.Schema, hint string) (string, error) {
 	if s.Ref != "" {
 		const prefix = "#/components/schemas/"
@@ -356,10 +1165,18 @@ func (g *TypeGenerator) goType(s *schema.Schema, hint string) (string, error) {
 				return customMapping.GoType, nil
 			}
 
-			return "*" + toGoTypeName(schemaName), nil
+			return "*" + g.componentTypeName(schemaName), nil
 		}
 	}
 
+	// An allOf wrapping a single reference, to document it, is that reference
+	if len(s.AllOf) == 1 && s.AllOf[0].Ref != "" && len(s.Properties) == 0 {
+		return g.goType(s.AllOf[0], hint)
+	}
+	if flat := g.flattenAllOf(s); flat != nil {
+		s = flat
+	}
+
 	if nullable, baseType := isNullableType(s); nullable {
 		goType, err := g.goType(baseType, hint)
 		if err != nil {
@@ -371,12 +1188,26 @@ func (g *TypeGenerator) goType(s *schema.Schema, hint string) (string, error) {
 		return "*" + goType, nil
 	}
 
+	if union := g.discriminatedUnion(hint, s); union != nil {
+		if g.types[hint] == "" {
+			typeCode, err := g.generateUnion(hint, s, union)
+			if err != nil {
+				return "", err
+			}
+			g.types[hint] = typeCode
+		}
+		return hint, nil
+	}
+
 	schemaType := schema.GetType(s)
 
 	switch schemaType {
 	case "string":
+		if s.Const != nil {
+			return g.constType(s, hint)
+		}
 		if len(s.Enum) > 0 {
-			enumTypeName := toGoTypeName(hint)
+			enumTypeName := g.enumTypeName(s, hint)
 			if g.enums[enumTypeName] == "" {
 				enumCode, err := g.generateEnum(enumTypeName, s)
 				if err != nil {
@@ -394,19 +1225,15 @@ func (g *TypeGenerator) goType(s *schema.Schema, hint string) (string, error) {
 	case "boolean":
 		return "bool", nil
 	case "array":
-		if s.Items == nil {
-			return "[]any", nil
-		}
-		itemType, err := g.goType(s.Items, hint+"Item")
-		if err != nil {
-			return "", err
-		}
-		return fmt.Sprintf("[]%s", itemType), nil
+		return g.arrayType(s, hint)
 	case "object":
 		if s.Title != "" {
 			typeName := toGoTypeName(s.Title)
+			if g.direction != anyDirection && g.isSplit(s, map[string]bool{}) {
+				typeName += g.direction.suffix()
+			}
 			if g.types[typeName] == "" {
-				typeCode, err := g.generateStruct(typeName, s, 0)
+				typeCode, err := g.generateType(typeName, s, 0)
 				if err != nil {
 					return "", err
 				}
@@ -414,7 +1241,7 @@ func (g *TypeGenerator) goType(s *schema.Schema, hint string) (string, error) {
 			}
 			return typeName, nil
 		}
-		if len(s.Properties) > 0 {
+		if len(s.Properties) > 0 || len(conditionalProperties(s)) > 0 {
 			typeName := hint
 			if g.types[typeName] == "" {
 				typeCode, err := g.generateStruct(typeName, s, 0)
@@ -425,6 +1252,13 @@ func (g *TypeGenerator) goType(s *schema.Schema, hint string) (string, error) {
 			}
 			return typeName, nil
 		}
+		if value := mapValueSchema(s); value != nil {
+			valueType, err := g.elemType(value, hint+"Value")
+			if err != nil {
+				return "", err
+			}
+			return "map[string]" + valueType, nil
+		}
 		return "map[string]any", nil
 	case "null":
 		return "any", nil
@@ -440,10 +1274,30 @@ func (g *TypeGenerator) goType(s *schema.Schema, hint string) (string, error) {
 			}
 			return typeName, nil
 		}
+		if s.Const != nil {
+			return g.constType(s, hint)
+		}
+		// A single-value enum, such as the discriminator of a oneOf variant
+		if _, ok := constString(s); ok {
+			return "string", nil
+		}
 		return "any", nil
 	}
 }
 
+// mapValueSchema returns the schema of the values of an object schema
+// declaring no property but a typed additionalProperties, which is a map of
+// these values, or nil.
+func mapValueSchema(s *schema.Schema) *schema.Schema {
+	if len(s.Properties) > 0 || len(s.PatternProperties) > 0 || len(conditionalProperties(s)) > 0 {
+		return nil
+	}
+	if !isTyped(s.AdditionalProperties) {
+		return nil
+	}
+	return s.AdditionalProperties
+}
+
 func (g *TypeGenerator) generatePrimitiveTypeAlias(name string, s *schema.Schema, goType string) (string, error) {
 	var buf strings.Builder
 
@@ -452,6 +1306,9 @@ func (g *TypeGenerator) generatePrimitiveTypeAlias(name string, s *schema.Schema
 	} else {
 		buf.WriteString(fmt.Sprintf("// %s represents a %s schema\n", name, goType))
 	}
+	if g.isDeprecatedType(name, s) {
+		buf.WriteString(deprecatedComment(name, "", true))
+	}
 
 	buf.WriteString(fmt.Sprintf("type %s %s", name, goType))
 	return buf.String(), nil
@@ -469,21 +1326,20 @@ func (g *TypeGenerator) generateEnum(enumTypeName string, s *schema.Schema) (str
 	} else {
 		buf.WriteString(fmt.Sprintf("// %s represents an enumeration\n", enumTypeName))
 	}
+	if g.isDeprecatedType(enumTypeName, s) {
+		buf.WriteString(deprecatedComment(enumTypeName, "", true))
+	}
+
+	constNames, err := enumConstNames(enumTypeName, s)
+	if err != nil {
+		return "", err
+	}
 
 	buf.WriteString(fmt.Sprintf("type %s string\n\n", enumTypeName))
 
 	buf.WriteString("const (\n")
-	var enumValues []string
 	for i, enumValue := range s.Enum {
-		strValue := fmt.Sprintf("%v", enumValue)
-		enumValues = append(enumValues, strValue)
-		constName := toEnumConstName(enumTypeName, strValue)
-
-		if i == 0 {
-			buf.WriteString(fmt.Sprintf("\t%s %s = %q\n", constName, enumTypeName, strValue))
-		} else {
-			buf.WriteString(fmt.Sprintf("\t%s %s = %q\n", constName, enumTypeName, strValue))
-		}
+		buf.WriteString(fmt.Sprintf("\t%s %s = %q\n", constNames[i], enumTypeName, fmt.Sprintf("%v", enumValue)))
 	}
 	buf.WriteString(")\n\n")
 
@@ -491,14 +1347,80 @@ func (g *TypeGenerator) generateEnum(enumTypeName string, s *schema.Schema) (str
 	buf.WriteString(fmt.Sprintf("// IsValid returns true if the %s value is valid\n", enumTypeName))
 	buf.WriteString(fmt.Sprintf("func (e %s) IsValid() bool {\n", enumTypeName))
 	buf.WriteString("\tswitch e {\n")
-	for _, strValue := range enumValues {
-		constName := toEnumConstName(enumTypeName, strValue)
+	for _, constName := range constNames {
 		buf.WriteString(fmt.Sprintf("\tcase %s:\n\t\treturn true\n", constName))
 	}
 	buf.WriteString("\t}\n")
 	buf.WriteString("\treturn false\n")
 	buf.WriteString("}\n\n")
 
+	// Generate the accessors listing the values, for exhaustive handling and
+	// for UIs offering a choice
+	buf.WriteString(fmt.Sprintf("// All%sValues returns the values of %s, in the order of the schema\n", enumTypeName, enumTypeName))
+	buf.WriteString(fmt.Sprintf("func All%sValues() []%s {\n", enumTypeName, enumTypeName))
+	buf.WriteString(fmt.Sprintf("\treturn []%s{\n", enumTypeName))
+	for _, constName := range constNames {
+		buf.WriteString(fmt.Sprintf("\t\t%s,\n", constName))
+	}
+	buf.WriteString("\t}\n")
+	buf.WriteString("}\n\n")
+
+	buf.WriteString(fmt.Sprintf("// Values returns the values of %s as strings, in the order of the schema\n", enumTypeName))
+	buf.WriteString(fmt.Sprintf("func (%s) Values() []string {\n", enumTypeName))
+	buf.WriteString("\treturn []string{\n")
+	for _, enumValue := range s.Enum {
+		buf.WriteString(fmt.Sprintf("\t\t%q,\n", fmt.Sprintf("%v", enumValue)))
+	}
+	buf.WriteString("\t}\n")
+	buf.WriteString("}\n\n")
+
+	// Generate the maps between the values and the names of the constants,
+	// trimmed of the type name, and the parsing of user strings
+	constPrefix := strings.TrimSuffix(enumTypeName, "Type")
+	buf.WriteString(fmt.Sprintf("// %sNames maps the values of %s to the names of their constants\n", enumTypeName, enumTypeName))
+	buf.WriteString(fmt.Sprintf("var %sNames = map[%s]string{\n", enumTypeName, enumTypeName))
+	for _, constName := range constNames {
+		buf.WriteString(fmt.Sprintf("\t%s: %q,\n", constName, strings.TrimPrefix(constName, constPrefix)))
+	}
+	buf.WriteString("}\n\n")
+
+	buf.WriteString(fmt.Sprintf("// %sByName maps the names of the constants of %s to their values\n", enumTypeName, enumTypeName))
+	buf.WriteString(fmt.Sprintf("var %sByName = map[string]%s{\n", enumTypeName, enumTypeName))
+	for _, constName := range constNames {
+		buf.WriteString(fmt.Sprintf("\t%q: %s,\n", strings.TrimPrefix(constName, constPrefix), constName))
+	}
+	buf.WriteString("}\n\n")
+
+	buf.WriteString(fmt.Sprintf("// Parse%s converts a value of %s, or the name of its constant, to a %s\n", enumTypeName, enumTypeName, enumTypeName))
+	buf.WriteString(fmt.Sprintf("func Parse%s(s string) (%s, error) {\n", enumTypeName, enumTypeName))
+	buf.WriteString(fmt.Sprintf("\tif e := %s(s); e.IsValid() {\n", enumTypeName))
+	buf.WriteString("\t\treturn e, nil\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString(fmt.Sprintf("\tif e, ok := %sByName[s]; ok {\n", enumTypeName))
+	buf.WriteString("\t\treturn e, nil\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString(fmt.Sprintf("\treturn \"\", mcp.NewError(mcp.MessageInvalidEnumValue, %q, s)\n", enumTypeName))
+	buf.WriteString("}\n\n")
+
+	if g.enumStringer {
+		buf.WriteString("// String returns the name of the constant of e, as stringer does\n")
+		buf.WriteString(fmt.Sprintf("func (e %s) String() string {\n", enumTypeName))
+		buf.WriteString(fmt.Sprintf("\tif name, ok := %sNames[e]; ok {\n", enumTypeName))
+		buf.WriteString("\t\treturn name\n")
+		buf.WriteString("\t}\n")
+		buf.WriteString(fmt.Sprintf("\treturn \"%s(\" + string(e) + \")\"\n", enumTypeName))
+		buf.WriteString("}\n\n")
+	}
+
+	buf.WriteString(fmt.Sprintf("// Must%s converts v to a %s, and panics if v is not one of its values\n", enumTypeName, enumTypeName))
+	buf.WriteString(fmt.Sprintf("func Must%s(v string) %s {\n", enumTypeName, enumTypeName))
+	buf.WriteString(fmt.Sprintf("\te := %s(v)\n", enumTypeName))
+	buf.WriteString("\tif !e.IsValid() {\n")
+	buf.WriteString(fmt.Sprintf("\t\tpanic(mcp.NewError(mcp.MessageInvalidEnumValue, %q, v))\n", enumTypeName))
+	buf.WriteString("\t}\n")
+	buf.WriteString("\treturn e\n")
+	buf.WriteString("}\n\n")
+
 	// Generate UnmarshalJSON method
 	buf.WriteString("// UnmarshalJSON implements json.Unmarshaler\n")
 	buf.WriteString(fmt.Sprintf("func (e *%s) UnmarshalJSON(data []byte) error {\n", enumTypeName))
@@ -508,7 +1430,7 @@ func (g *TypeGenerator) generateEnum(enumTypeName string, s *schema.Schema) (str
 	buf.WriteString("\t}\n")
 	buf.WriteString(fmt.Sprintf("\t*e = %s(s)\n", enumTypeName))
 	buf.WriteString("\tif !e.IsValid() {\n")
-	buf.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"invalid %s value: %%q\", s)\n", enumTypeName))
+	buf.WriteString(fmt.Sprintf("\t\treturn mcp.NewError(mcp.MessageInvalidEnumValue, %q, s)\n", enumTypeName))
 	buf.WriteString("\t}\n")
 	buf.WriteString("\treturn nil\n")
 	buf.WriteString("}\n\n")
@@ -517,18 +1439,96 @@ func (g *TypeGenerator) generateEnum(enumTypeName string, s *schema.Schema) (str
 	buf.WriteString("// MarshalJSON implements json.Marshaler\n")
 	buf.WriteString(fmt.Sprintf("func (e %s) MarshalJSON() ([]byte, error) {\n", enumTypeName))
 	buf.WriteString("\tif !e.IsValid() {\n")
-	buf.WriteString(fmt.Sprintf("\t\treturn nil, fmt.Errorf(\"invalid %s value: %%q\", string(e))\n", enumTypeName))
+	buf.WriteString(fmt.Sprintf("\t\treturn nil, mcp.NewError(mcp.MessageInvalidEnumValue, %q, string(e))\n", enumTypeName))
 	buf.WriteString("\t}\n")
 	buf.WriteString("\treturn json.Marshal(string(e))\n")
 	buf.WriteString("}")
 
 	g.imports["encoding/json"] = true
-	g.imports["fmt"] = true
+	g.imports["go.probo.inc/mcpgen/mcp"] = true
+
+	return buf.String(), nil
+}
+
+// constType returns the Go type of a const schema: a single-value type for
+// a string, generated as hint, or the Go type of other values.
+func (g *TypeGenerator) constType(s *schema.Schema, hint string) (string, error) {
+	switch value := (*s.Const).(type) {
+	case string:
+		typeName := g.enumTypeName(s, hint)
+		if g.enums[typeName] == "" {
+			code, err := g.generateConst(typeName, s, value)
+			if err != nil {
+				return "", err
+			}
+			g.enums[typeName] = code
+		}
+		return typeName, nil
+	case bool:
+		return "bool", nil
+	case float64:
+		if schema.GetType(s) == "integer" {
+			return "int", nil
+		}
+		return "float64", nil
+	default:
+		return "any", nil
+	}
+}
+
+// generateConst generates a type with a single value, for a string const.
+// Decoding rejects other values, and encoding always writes the value, so
+// that the field cannot be left wrong in results.
+func (g *TypeGenerator) generateConst(typeName string, s *schema.Schema, value string) (string, error) {
+	constName := toEnumConstName(typeName, value)
+
+	var buf strings.Builder
+
+	if s.Description != "" {
+		buf.WriteString(formatComment(s.Description, ""))
+		buf.WriteString("//\n")
+	}
+	buf.WriteString(fmt.Sprintf("// %s is always %q.\n", typeName, value))
+	buf.WriteString(fmt.Sprintf("type %s string\n\n", typeName))
+
+	buf.WriteString(fmt.Sprintf("// %s is the only value of %s.\n", constName, typeName))
+	buf.WriteString(fmt.Sprintf("const %s %s = %q\n\n", constName, typeName, value))
+
+	buf.WriteString(fmt.Sprintf("// UnmarshalJSON implements json.Unmarshaler, rejecting any other value than %s\n", constName))
+	buf.WriteString(fmt.Sprintf("func (c *%s) UnmarshalJSON(data []byte) error {\n", typeName))
+	buf.WriteString("\tvar s string\n")
+	buf.WriteString("\tif err := json.Unmarshal(data, &s); err != nil {\n")
+	buf.WriteString("\t\treturn err\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString(fmt.Sprintf("\tif s != string(%s) {\n", constName))
+	buf.WriteString(fmt.Sprintf("\t\treturn mcp.NewError(mcp.MessageInvalidConstValue, %q, s, string(%s))\n", typeName, constName))
+	buf.WriteString("\t}\n")
+	buf.WriteString(fmt.Sprintf("\t*c = %s\n", constName))
+	buf.WriteString("\treturn nil\n")
+	buf.WriteString("}\n\n")
+
+	buf.WriteString(fmt.Sprintf("// MarshalJSON implements json.Marshaler, always encoding %s\n", constName))
+	buf.WriteString(fmt.Sprintf("func (%s) MarshalJSON() ([]byte, error) {\n", typeName))
+	buf.WriteString(fmt.Sprintf("\treturn json.Marshal(string(%s))\n", constName))
+	buf.WriteString("}")
+
+	g.imports["encoding/json"] = true
+	g.imports["go.probo.inc/mcpgen/mcp"] = true
 
 	return buf.String(), nil
 }
 
 func (g *TypeGenerator) goStringType(s *schema.Schema) string {
+	if mapping := g.formatMapping(s); mapping != nil {
+		if mapping.ImportPath != "" {
+			g.imports[mapping.ImportPath] = true
+		}
+		if mapping.IsPointer {
+			return "*" + mapping.GoType
+		}
+		return mapping.GoType
+	}
+
 	switch s.Format {
 	case "date-time":
 		g.imports["time"] = true
@@ -602,6 +1602,59 @@ var goSpecialCase = map[string]string{
 	"oauth": "OAuth",
 }
 
+// fieldNameKeys are the annotations forcing the Go field name of a property.
+var fieldNameKeys = []string{"x-go-name", "go.probo.inc/mcpgen/name"}
+
+// goFieldName returns the Go field name of a property, forced by its field
+// name annotation or derived from the property name.
+func goFieldName(propName string, propSchema *schema.Schema) (string, error) {
+	if propSchema != nil {
+		for _, key := range fieldNameKeys {
+			if value, ok := propSchema.Extra[key]; ok {
+				name, ok := value.(string)
+				if !ok || !token.IsIdentifier(name) || !token.IsExported(name) {
+					return "", fmt.Errorf("%s must be an exported Go identifier, got %v", key, value)
+				}
+				return name, nil
+			}
+		}
+	}
+	return toGoFieldName(propName), nil
+}
+
+// fieldTagKeys are the annotations adding struct tags to the field of a
+// property, such as validate:"required,email" for a validator.
+var fieldTagKeys = []string{"x-go-tag", "go.probo.inc/mcpgen/tag"}
+
+// fieldTag returns the struct tag of the field of a property: its json tag
+// followed by the tags of its struct tag annotation.
+func fieldTag(jsonTag string, propSchema *schema.Schema) (string, error) {
+	tag := fmt.Sprintf("json:%q", jsonTag)
+	if propSchema == nil {
+		return "`" + tag + "`", nil
+	}
+
+	for _, key := range fieldTagKeys {
+		value, ok := propSchema.Extra[key]
+		if !ok {
+			continue
+		}
+		extra, ok := value.(string)
+		if !ok || strings.Contains(extra, "`") {
+			return "", fmt.Errorf("%s must be a string of struct tags, got %v", key, value)
+		}
+		if _, ok := reflect.StructTag(extra).Lookup("json"); ok {
+			return "", fmt.Errorf("%s cannot set the json tag, which is generated", key)
+		}
+		if extra = strings.TrimSpace(extra); extra != "" {
+			tag += " " + extra
+		}
+		break
+	}
+
+	return "`" + tag + "`", nil
+}
+
 func toGoFieldName(name string) string {
 	parts := strings.FieldsFunc(name, func(r rune) bool {
 		return r == '_' || r == '-' || r == ' '
@@ -634,6 +1687,72 @@ func formatComment(text, prefix string) string {
 	return result.String()
 }
 
+// isDeprecatedType reports whether the type generated for s is deprecated.
+// The types of tool inputs and outputs and of resource contents are not,
+// even when they reuse a deprecated component: their tool is not deprecated
+// with it.
+func (g *TypeGenerator) isDeprecatedType(name string, s *schema.Schema) bool {
+	return s.Deprecated && g.directions[name] == anyDirection
+}
+
+// deprecatedComment returns the Deprecated paragraph of the doc comment of
+// a deprecated type or field, separated from the text above it when there is
+// one, so that linters and IDEs flag its uses.
+func deprecatedComment(name, prefix string, separate bool) string {
+	paragraph := fmt.Sprintf("%s// Deprecated: %s is deprecated in the schema.\n", prefix, name)
+	if separate {
+		return prefix + "//\n" + paragraph
+	}
+	return paragraph
+}
+
+// enumConstNameKeys are the annotations naming the constants of the values
+// of an enum, in order: the generated names are the names they list,
+// prefixed like the derived names.
+var enumConstNameKeys = []string{"x-enum-varnames", "go.probo.inc/mcpgen/enum-names"}
+
+// enumConstNames returns the constant names of the values of an enum, taken
+// from its enum names annotation or derived from the values. Values whose
+// names collide are an error, asking for an annotation.
+func enumConstNames(enumTypeName string, s *schema.Schema) ([]string, error) {
+	var names []any
+	for _, key := range enumConstNameKeys {
+		if value, ok := s.Extra[key]; ok {
+			list, ok := value.([]any)
+			if !ok || len(list) != len(s.Enum) {
+				return nil, fmt.Errorf("%s of %s must list a name for each of its %d values", key, enumTypeName, len(s.Enum))
+			}
+			names = list
+			break
+		}
+	}
+
+	constNames := make([]string, len(s.Enum))
+	seen := make(map[string]string, len(s.Enum))
+	for i, enumValue := range s.Enum {
+		value := fmt.Sprintf("%v", enumValue)
+		if names != nil {
+			name, ok := names[i].(string)
+			if !ok || !token.IsIdentifier(name) {
+				return nil, fmt.Errorf("invalid name %v for the %q value of %s", names[i], value, enumTypeName)
+			}
+			constNames[i] = strings.TrimSuffix(enumTypeName, "Type") + name
+		} else {
+			constNames[i] = toEnumConstName(enumTypeName, value)
+			if !token.IsIdentifier(constNames[i]) {
+				return nil, fmt.Errorf("cannot name the %q value of %s, name it with x-enum-varnames", value, enumTypeName)
+			}
+		}
+
+		if other, ok := seen[constNames[i]]; ok {
+			return nil, fmt.Errorf("values %q and %q of %s are both named %s, name them with x-enum-varnames", other, value, enumTypeName, constNames[i])
+		}
+		seen[constNames[i]] = value
+	}
+
+	return constNames, nil
+}
+
 func toEnumConstName(enumTypeName, value string) string {
 	parts := strings.FieldsFunc(value, func(r rune) bool {
 		return r == '_' || r == '-' || r == ' ' || r == '.'
@@ -650,3 +1769,72 @@ func toEnumConstName(enumTypeName, value string) string {
 
 	return baseName + constName
 }
+
+// typeOwners maps the generated types and enums to the schema whose Go
+// type name is the longest prefix of their name.
+func (g *TypeGenerator) typeOwners(schemaNames []string) map[string]string {
+	byGoName := make(map[string]string, len(schemaNames))
+	for _, name := range schemaNames {
+		byGoName[toGoTypeName(name)] = name
+	}
+
+	owners := make(map[string]string, len(g.types)+len(g.enums))
+	owner := func(typeName string) {
+		for prefix := typeName; prefix != ""; prefix = prefix[:len(prefix)-1] {
+			if name, ok := byGoName[prefix]; ok {
+				owners[typeName] = name
+				return
+			}
+		}
+	}
+	for typeName := range g.types {
+		owner(typeName)
+	}
+	for enumName := range g.enums {
+		owner(enumName)
+	}
+	return owners
+}
+
+// usedImports returns the imports of imports whose package, named after the
+// last element of its path, src refers to.
+func usedImports(src string, imports []string) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), "", "package p\n\n"+src, 0)
+	if err != nil {
+		return imports
+	}
+
+	used := map[string]bool{}
+	ast.Inspect(file, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if ident, ok := sel.X.(*ast.Ident); ok {
+				used[ident.Name] = true
+			}
+		}
+		return true
+	})
+
+	var kept []string
+	for _, imp := range imports {
+		if used[path.Base(imp)] {
+			kept = append(kept, imp)
+		}
+	}
+	return kept
+}
+
+// schemaRefsLiteral returns the entries of the mcp.SchemaRefs literal of
+// refs, sorted by component name.
+func schemaRefsLiteral(refs map[string]string) string {
+	names := make([]string, 0, len(refs))
+	for name := range refs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	entries := make([]string, 0, len(names))
+	for _, name := range names {
+		entries = append(entries, fmt.Sprintf("%q: %s", name, refs[name]))
+	}
+	return strings.Join(entries, ", ")
+}
@@ -1,6 +1,8 @@
 package codegen
 
 import (
+	"encoding/json"
+	"regexp"
 	"testing"
 
 	"github.com/google/jsonschema-go/jsonschema"
@@ -415,6 +417,16 @@ func TestEnumGeneration(t *testing.T) {
 	if !containsString(code, "func (e Status) MarshalJSON") {
 		t.Error("Generated enum should contain MarshalJSON method")
 	}
+
+	if !containsString(code, "func AllStatusValues() []Status {\n\treturn []Status{\n\t\tStatusPending,\n\t\tStatusInProgress,\n\t\tStatusCompleted,\n\t}\n}") {
+		t.Error("Generated enum should contain AllStatusValues function")
+	}
+	if !containsString(code, "func (Status) Values() []string {\n\treturn []string{\n\t\t\"pending\",\n\t\t\"in_progress\",\n\t\t\"completed\",\n\t}\n}") {
+		t.Error("Generated enum should contain Values method")
+	}
+	if !containsString(code, "func MustStatus(v string) Status {") {
+		t.Error("Generated enum should contain MustStatus function")
+	}
 }
 
 func containsTypeDefinition(code, typeDef string) bool {
@@ -936,6 +948,202 @@ func TestGenerateStruct(t *testing.T) {
 	}
 }
 
+func TestBooleanSchemas(t *testing.T) {
+	var closed, anything config.Schema
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"legacy": false,
+			"payload": true
+		},
+		"required": ["name"],
+		"additionalProperties": false
+	}`), &closed))
+	require.NoError(t, json.Unmarshal([]byte(`true`), &anything))
+
+	gen := NewTypeGenerator()
+	gen.AddSchema("Closed", &closed)
+	gen.AddSchema("Anything", &anything)
+
+	code, err := gen.Generate("test")
+	require.NoError(t, err)
+
+	codeStr := string(code)
+	assert.Contains(t, codeStr, "type Anything any")
+	assert.Contains(t, codeStr, "Payload *any")
+	assert.NotContains(t, codeStr, "Legacy")
+	assert.Contains(t, codeStr, "func (v *Closed) UnmarshalJSON(data []byte) error {")
+	assert.Contains(t, codeStr, `case "name", "payload":`)
+	assert.Contains(t, codeStr, `return mcp.NewError(mcp.MessageUnknownField, field, "Closed")`)
+
+	open := &config.Schema{
+		Type:                 "object",
+		Properties:           map[string]*config.Schema{"name": {Type: "string"}},
+		AdditionalProperties: &config.Schema{},
+	}
+	gen = NewTypeGenerator()
+	gen.AddSchema("Open", open)
+
+	code, err = gen.Generate("test")
+	require.NoError(t, err)
+	assert.NotContains(t, string(code), "UnmarshalJSON")
+
+	var falseSchema config.Schema
+	require.NoError(t, json.Unmarshal([]byte(`false`), &falseSchema))
+	gen = NewTypeGenerator()
+	gen.AddSchema("Nothing", &falseSchema)
+
+	_, err = gen.Generate("test")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "accepts no value")
+}
+
+func TestBooleanSchemasPublished(t *testing.T) {
+	var s config.Schema
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {"payload": true, "legacy": false},
+		"additionalProperties": false
+	}`), &s))
+
+	gen := &Generator{spec: &config.MCPSpec{}}
+	resolved, err := gen.resolveAllRefs(&s)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(resolved)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "object",
+		"properties": {"payload": true, "legacy": false},
+		"additionalProperties": false
+	}`, string(data))
+}
+
+func TestDraft2020Keywords(t *testing.T) {
+	var s config.Schema
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"point": {
+				"type": "array",
+				"prefixItems": [{"type": "number"}, {"type": "number"}],
+				"items": false,
+				"minItems": 2
+			},
+			"range": {
+				"type": "array",
+				"prefixItems": [{"type": "integer"}, {"type": "integer"}],
+				"items": false
+			},
+			"entry": {
+				"type": "array",
+				"prefixItems": [{"type": "string"}, {"type": "integer"}]
+			},
+			"tags": {
+				"type": "array",
+				"prefixItems": [{"type": "string"}],
+				"items": {"type": "string"},
+				"contains": {"const": "primary"}
+			}
+		},
+		"required": ["point"],
+		"unevaluatedProperties": false
+	}`), &s))
+
+	gen := NewTypeGenerator()
+	gen.AddSchema("Shape", &s)
+	code, err := gen.Generate("test")
+	require.NoError(t, err)
+
+	codeStr := string(code)
+	assert.Contains(t, codeStr, "Point [2]float64")
+	assert.Contains(t, codeStr, "Range []int")
+	assert.Contains(t, codeStr, "Entry []any")
+	assert.Contains(t, codeStr, "Tags  []string")
+	assert.Contains(t, codeStr, "func (v *Shape) UnmarshalJSON(data []byte) error {")
+
+	// The keywords are kept in the published schemas
+	resolved, err := (&Generator{spec: &config.MCPSpec{}}).resolveAllRefs(&s)
+	require.NoError(t, err)
+	data, err := json.Marshal(resolved)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "array",
+		"prefixItems": [{"type": "string"}],
+		"items": {"type": "string"},
+		"contains": {"const": "primary"}
+	}`, mustMarshal(t, resolved.Properties["tags"]))
+	assert.Contains(t, string(data), `"unevaluatedProperties":false`)
+}
+
+func TestConditionalSchemas(t *testing.T) {
+	var s config.Schema
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"mode": {"type": "string", "enum": ["text", "number"]},
+			"value": {}
+		},
+		"required": ["mode"],
+		"if": {"properties": {"mode": {"const": "text"}}},
+		"then": {
+			"properties": {
+				"value": {"type": "string"},
+				"language": {"type": "string", "description": "Language of the text"}
+			}
+		},
+		"else": {
+			"properties": {
+				"value": {"type": "number"},
+				"precision": {"type": "integer"}
+			}
+		}
+	}`), &s))
+
+	gen := NewTypeGenerator()
+	gen.AddSchema("Setting", &s)
+	code, err := gen.Generate("test")
+	require.NoError(t, err)
+
+	codeStr := string(code)
+	assert.Contains(t, codeStr, "\t// Language of the text\n\t//\n\t// Only used when mode is \"text\".\n\tLanguage *string ")
+	assert.Contains(t, codeStr, "\t// Only used unless mode is \"text\".\n\tPrecision *int ")
+	assert.Contains(t, codeStr, "Value     SettingValue `json:\"value,omitempty\"`")
+	assert.Contains(t, codeStr, `// SettingValue holds the Value field of Setting, whose type depends on its if/then/else:
+//
+//   - string when mode is "text"
+//   - float64 otherwise
+type SettingValue any`)
+
+	// The conditional keywords are kept in the published schemas
+	resolved, err := (&Generator{spec: &config.MCPSpec{}}).resolveAllRefs(&s)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"properties": {"mode": {"const": "text"}}}`, mustMarshal(t, resolved.If))
+	assert.JSONEq(t, `{"properties": {"value": {"type": "number"}, "precision": {"type": "integer"}}}`, mustMarshal(t, resolved.Else))
+	require.NotNil(t, resolved.Then)
+
+	closeObjects(resolved)
+	assert.Nil(t, resolved.AdditionalProperties)
+}
+
+func TestDescribeCondition(t *testing.T) {
+	var s config.Schema
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"properties": {"kind": {"enum": ["a", "b"]}, "count": {"const": 1}},
+		"required": ["kind", "owner"]
+	}`), &s))
+	assert.Equal(t, `count is 1 and kind is one of "a", "b" and owner is set`, describeCondition(&s))
+	assert.Equal(t, "the if schema matches", describeCondition(&config.Schema{}))
+}
+
+func mustMarshal(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(data)
+}
+
 func TestToGoTypeName(t *testing.T) {
 	tests := []struct {
 		input string
@@ -1046,3 +1254,506 @@ func TestToEnumConstName(t *testing.T) {
 		})
 	}
 }
+
+func TestConstSchemas(t *testing.T) {
+	var s config.Schema
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"jsonrpc": {"type": "string", "const": "2.0"},
+			"kind": {"const": "card", "description": "Payment kind"},
+			"live": {"const": true},
+			"version": {"type": "integer", "const": 2}
+		},
+		"required": ["jsonrpc", "kind"]
+	}`), &s))
+
+	gen := NewTypeGenerator()
+	gen.AddSchema("Payment", &s)
+	gen.AddSchema("Protocol", &config.Schema{Const: jsonschema.Ptr[any]("mcp")})
+	code, err := gen.Generate("test")
+	require.NoError(t, err)
+
+	codeStr := string(code)
+	assert.Contains(t, codeStr, "// Payment kind\n//\n// PaymentKind is always \"card\".\ntype PaymentKind string")
+	assert.Contains(t, codeStr, "const PaymentKindCard PaymentKind = \"card\"")
+	assert.Contains(t, codeStr, "const PaymentJsonrpc20 PaymentJsonrpc = \"2.0\"")
+	assert.Contains(t, codeStr, `return mcp.NewError(mcp.MessageInvalidConstValue, "PaymentKind", s, string(PaymentKindCard))`)
+	assert.Contains(t, codeStr, "func (PaymentKind) MarshalJSON() ([]byte, error) {\n\treturn json.Marshal(string(PaymentKindCard))\n}")
+	assert.Regexp(t, "Jsonrpc +PaymentJsonrpc +`json:\"jsonrpc\"`", codeStr)
+	assert.Regexp(t, "Kind +PaymentKind +`json:\"kind\"`", codeStr)
+	assert.Regexp(t, "Live +\\*bool +`json:\"live,omitempty\"`", codeStr)
+	assert.Regexp(t, "Version +\\*int +`json:\"version,omitempty\"`", codeStr)
+	assert.Contains(t, codeStr, "const ProtocolMcp Protocol = \"mcp\"")
+}
+
+func TestTypedAdditionalProperties(t *testing.T) {
+	schemas := map[string]string{
+		"Label": `{"type": "object", "properties": {"value": {"type": "string"}}}`,
+		"Labels": `{
+			"type": "object",
+			"description": "Labels by key.",
+			"additionalProperties": {"$ref": "#/components/schemas/Label"}
+		}`,
+		"Resource": `{
+			"type": "object",
+			"properties": {
+				"metadata": {"type": "object", "additionalProperties": {"type": "string"}},
+				"counts": {"type": "object", "additionalProperties": {"type": "object", "properties": {"total": {"type": "integer"}}}},
+				"created": {"type": "object", "additionalProperties": {"$ref": "#/components/schemas/Timestamp"}},
+				"extra": {"type": "object", "additionalProperties": true},
+				"closed": {"type": "object", "additionalProperties": false}
+			}
+		}`,
+		"Timestamp": `{"type": "string", "format": "date-time"}`,
+	}
+
+	gen := NewTypeGenerator()
+	gen.AddCustomMapping("Timestamp", &CustomTypeMapping{GoType: "time.Time", ImportPath: "time"})
+	for name, data := range schemas {
+		var s config.Schema
+		require.NoError(t, json.Unmarshal([]byte(data), &s))
+		gen.AddSchema(name, &s)
+	}
+	code, err := gen.Generate("test")
+	require.NoError(t, err)
+
+	codeStr := string(code)
+	assert.Contains(t, codeStr, "// Labels by key.\ntype Labels map[string]*Label")
+	assert.Regexp(t, "Metadata +map\\[string\\]string ", codeStr)
+	assert.Regexp(t, "Counts +map\\[string\\]ResourceCountsValue ", codeStr)
+	assert.Contains(t, codeStr, "type ResourceCountsValue struct {")
+	assert.Regexp(t, "Created +map\\[string\\]time.Time ", codeStr)
+	assert.Regexp(t, "Extra +map\\[string\\]any ", codeStr)
+	assert.Regexp(t, "Closed +map\\[string\\]any ", codeStr)
+}
+
+func TestEnumVarNames(t *testing.T) {
+	generate := func(t *testing.T, data string) (string, error) {
+		t.Helper()
+		var s config.Schema
+		require.NoError(t, json.Unmarshal([]byte(data), &s))
+		gen := NewTypeGenerator()
+		gen.AddSchema("Window", &s)
+		code, err := gen.Generate("test")
+		return string(code), err
+	}
+
+	t.Run("x-enum-varnames", func(t *testing.T) {
+		code, err := generate(t, `{"type": "string", "enum": ["1h", "24h"], "x-enum-varnames": ["Hour", "Day"]}`)
+		require.NoError(t, err)
+		assert.Regexp(t, "WindowHour +Window = \"1h\"", code)
+		assert.Regexp(t, "WindowDay +Window = \"24h\"", code)
+		assert.Contains(t, code, "case WindowDay:")
+	})
+
+	t.Run("mcpgen annotation", func(t *testing.T) {
+		code, err := generate(t, `{"type": "string", "enum": ["1h"], "go.probo.inc/mcpgen/enum-names": ["Hour"]}`)
+		require.NoError(t, err)
+		assert.Contains(t, code, `WindowHour Window = "1h"`)
+	})
+
+	t.Run("derived names", func(t *testing.T) {
+		code, err := generate(t, `{"type": "string", "enum": ["1h", "24h"]}`)
+		require.NoError(t, err)
+		assert.Regexp(t, "Window1h +Window = \"1h\"", code)
+	})
+
+	t.Run("colliding names", func(t *testing.T) {
+		_, err := generate(t, `{"type": "string", "enum": ["last-day", "last_day"]}`)
+		assert.ErrorContains(t, err, `values "last-day" and "last_day" of Window are both named WindowLastDay`)
+	})
+
+	t.Run("wrong number of names", func(t *testing.T) {
+		_, err := generate(t, `{"type": "string", "enum": ["1h", "24h"], "x-enum-varnames": ["Hour"]}`)
+		assert.ErrorContains(t, err, "x-enum-varnames of Window must list a name for each of its 2 values")
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		_, err := generate(t, `{"type": "string", "enum": ["1h"], "x-enum-varnames": ["one hour"]}`)
+		assert.ErrorContains(t, err, `invalid name one hour for the "1h" value of Window`)
+	})
+}
+
+func TestFieldNameAnnotation(t *testing.T) {
+	generate := func(t *testing.T, data string) (string, error) {
+		t.Helper()
+		var s config.Schema
+		require.NoError(t, json.Unmarshal([]byte(data), &s))
+		gen := NewTypeGenerator()
+		gen.AddSchema("Email", &s)
+		code, err := gen.Generate("test")
+		return string(code), err
+	}
+
+	t.Run("x-go-name", func(t *testing.T) {
+		code, err := generate(t, `{
+			"type": "object",
+			"properties": {
+				"html_body": {"type": "string", "x-go-name": "HTMLBody"},
+				"text_body": {"type": "string", "go.probo.inc/mcpgen/name": "PlainBody"},
+				"subject": {"type": "string"}
+			},
+			"required": ["html_body"]
+		}`)
+		require.NoError(t, err)
+		assert.Regexp(t, "HTMLBody +string +`json:\"html_body\"`", code)
+		assert.Regexp(t, "PlainBody +\\*string +`json:\"text_body,omitempty\"`", code)
+		assert.Regexp(t, "Subject +\\*string ", code)
+	})
+
+	t.Run("unexported name", func(t *testing.T) {
+		_, err := generate(t, `{"type": "object", "properties": {"body": {"type": "string", "x-go-name": "body"}}}`)
+		assert.ErrorContains(t, err, "field Email.body: x-go-name must be an exported Go identifier, got body")
+	})
+
+	t.Run("colliding names", func(t *testing.T) {
+		_, err := generate(t, `{"type": "object", "properties": {"body": {"type": "string"}, "text": {"type": "string", "x-go-name": "Body"}}}`)
+		assert.ErrorContains(t, err, "properties body and text of Email are both named Body")
+	})
+}
+
+func TestFieldTagAnnotation(t *testing.T) {
+	generate := func(t *testing.T, data string) (string, error) {
+		t.Helper()
+		var s config.Schema
+		require.NoError(t, json.Unmarshal([]byte(data), &s))
+		gen := NewTypeGenerator()
+		gen.AddSchema("User", &s)
+		code, err := gen.Generate("test")
+		return string(code), err
+	}
+
+	t.Run("extra tags", func(t *testing.T) {
+		code, err := generate(t, `{
+			"type": "object",
+			"properties": {
+				"email": {"type": "string", "x-go-tag": "validate:\"required,email\""},
+				"user_id": {"type": "string", "go.probo.inc/mcpgen/tag": "db:\"user_id\""}
+			},
+			"required": ["email"]
+		}`)
+		require.NoError(t, err)
+		assert.Regexp(t, "Email +string +`json:\"email\" validate:\"required,email\"`", code)
+		assert.Regexp(t, "UserID +\\*string +`json:\"user_id,omitempty\" db:\"user_id\"`", code)
+	})
+
+	t.Run("json tag", func(t *testing.T) {
+		_, err := generate(t, `{"type": "object", "properties": {"email": {"type": "string", "x-go-tag": "json:\"mail\""}}}`)
+		assert.ErrorContains(t, err, "field User.email: x-go-tag cannot set the json tag")
+	})
+
+	t.Run("not a string", func(t *testing.T) {
+		_, err := generate(t, `{"type": "object", "properties": {"email": {"type": "string", "x-go-tag": {"validate": "email"}}}}`)
+		assert.ErrorContains(t, err, "x-go-tag must be a string of struct tags")
+	})
+}
+
+func TestApplyDefaults(t *testing.T) {
+	var s config.Schema
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"query": {"type": "string"},
+			"limit": {"type": "integer", "default": 10},
+			"ratio": {"type": "number", "default": 0.5},
+			"exact": {"type": "boolean", "default": false},
+			"order": {"type": "string", "enum": ["asc", "desc"], "default": "desc"},
+			"tags": {"type": "array", "items": {"type": "string"}, "default": ["recent"]},
+			"name": {"type": "string", "default": "anonymous"}
+		},
+		"required": ["query", "name"]
+	}`), &s))
+
+	gen := NewTypeGenerator()
+	gen.AddSchema("SearchInput", &s)
+	code, err := gen.Generate("test")
+	require.NoError(t, err)
+	out := string(code)
+
+	assert.Contains(t, out, "func (v *SearchInput) ApplyDefaults() {")
+	assert.Contains(t, out, "value := int(10)")
+	assert.Contains(t, out, "value := float64(0.5)")
+	assert.Contains(t, out, "value := bool(false)")
+	assert.Contains(t, out, "value := SearchInputOrder(\"desc\")")
+	assert.Contains(t, out, "_ = json.Unmarshal([]byte(\"[\\\"recent\\\"]\"), &v.Tags)")
+	assert.NotContains(t, out, "anonymous", "required fields have no default applied")
+
+	t.Run("no defaults", func(t *testing.T) {
+		var s config.Schema
+		require.NoError(t, json.Unmarshal([]byte(`{"type": "object", "properties": {"query": {"type": "string"}}}`), &s))
+		gen := NewTypeGenerator()
+		gen.AddSchema("SearchInput", &s)
+		code, err := gen.Generate("test")
+		require.NoError(t, err)
+		assert.NotContains(t, string(code), "ApplyDefaults")
+	})
+}
+
+func TestReadOnlyWriteOnlySplit(t *testing.T) {
+	schemas := map[string]string{
+		"Task": `{
+			"type": "object",
+			"properties": {
+				"id": {"type": "string", "readOnly": true},
+				"title": {"type": "string"},
+				"secret": {"type": "string", "writeOnly": true}
+			},
+			"required": ["id", "title"]
+		}`,
+		"Project": `{
+			"type": "object",
+			"properties": {
+				"name": {"type": "string"},
+				"tasks": {"type": "array", "items": {"$ref": "#/components/schemas/Task"}}
+			}
+		}`,
+		"Label": `{"type": "object", "properties": {"name": {"type": "string"}}}`,
+	}
+
+	gen := NewTypeGenerator()
+	for name, data := range schemas {
+		var s config.Schema
+		require.NoError(t, json.Unmarshal([]byte(data), &s))
+		gen.AddSchema(name, &s)
+	}
+	var input, output config.Schema
+	require.NoError(t, json.Unmarshal([]byte(schemas["Project"]), &input))
+	require.NoError(t, json.Unmarshal([]byte(schemas["Project"]), &output))
+	gen.AddInputSchema("CreateProjectInput", &input)
+	gen.AddOutputSchema("CreateProjectOutput", &output)
+
+	code, err := gen.Generate("test")
+	require.NoError(t, err)
+	out := string(code)
+
+	assert.Regexp(t, `(?s)type TaskInput struct \{\n\tSecret \*string[^}]*\tTitle  string[^}]*\}`, out)
+	assert.Regexp(t, `(?s)type TaskOutput struct \{\n\tID    string[^}]*\tTitle string[^}]*\}`, out)
+	assert.Contains(t, out, "type ProjectInput struct")
+	assert.Contains(t, out, "type ProjectOutput struct")
+	assert.Regexp(t, `(?s)type CreateProjectInput struct \{[^}]*Tasks \[\]\*TaskInput`, out)
+	assert.Regexp(t, `(?s)type CreateProjectOutput struct \{[^}]*Tasks \[\]\*TaskOutput`, out)
+	assert.NotRegexp(t, `type (Task|Project) struct`, out, "split components have no single type")
+	assert.Contains(t, out, "type Label struct", "components without readOnly or writeOnly properties are not split")
+	assert.NotContains(t, out, "LabelInput")
+
+	t.Run("inline enum", func(t *testing.T) {
+		var ext config.Schema
+		require.NoError(t, json.Unmarshal([]byte(`{
+			"type": "object",
+			"properties": {
+				"id": {"type": "string", "readOnly": true},
+				"status": {"type": "string", "enum": ["active", "inactive"]}
+			}
+		}`), &ext))
+		gen := NewTypeGenerator()
+		gen.AddSchema("Ext", &ext)
+		gen.AddInputSchema("AdoptInput", &config.Schema{
+			Type:       "object",
+			Properties: map[string]*config.Schema{"ext": {Ref: "#/components/schemas/Ext"}},
+		})
+		gen.AddOutputSchema("AdoptOutput", &ext)
+
+		code, err := gen.Generate("test")
+		require.NoError(t, err)
+		out := string(code)
+
+		assert.Contains(t, out, "type ExtStatus string")
+		assert.Regexp(t, `(?s)type ExtInput struct \{[^}]*Status \*ExtStatus`, out)
+		assert.Regexp(t, `(?s)type ExtOutput struct \{[^}]*Status \*ExtStatus`, out)
+		assert.Regexp(t, `(?s)type AdoptOutput struct \{[^}]*Status \*ExtStatus`, out, "types copying a component share its enums")
+		assert.NotRegexp(t, `type (ExtInput|ExtOutput|AdoptOutput)Status`, out)
+	})
+
+	t.Run("clash", func(t *testing.T) {
+		var s config.Schema
+		require.NoError(t, json.Unmarshal([]byte(schemas["Task"]), &s))
+		gen := NewTypeGenerator()
+		gen.AddSchema("Task", &s)
+		gen.AddInputSchema("TaskInput", &config.Schema{Type: "object"})
+		_, err := gen.Generate("test")
+		assert.ErrorContains(t, err, "schema Task has readOnly or writeOnly properties and generates TaskInput, which is already the type of TaskInput")
+	})
+}
+
+func TestDeprecatedComments(t *testing.T) {
+	var s, priority config.Schema
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"description": "A task.",
+		"deprecated": true,
+		"properties": {
+			"title": {"type": "string", "description": "Title of the task.", "deprecated": true},
+			"name": {"type": "string", "deprecated": true},
+			"priority": {"$ref": "#/components/schemas/Priority"}
+		}
+	}`), &s))
+	require.NoError(t, json.Unmarshal([]byte(`{"type": "string", "enum": ["low", "high"], "deprecated": true}`), &priority))
+
+	gen := NewTypeGenerator()
+	gen.AddSchema("Task", &s)
+	gen.AddSchema("Priority", &priority)
+	gen.AddOutputSchema("GetTaskOutput", &s)
+	code, err := gen.Generate("test")
+	require.NoError(t, err)
+	out := string(code)
+
+	assert.Contains(t, out, "// A task.\n//\n// Deprecated: Task is deprecated in the schema.\ntype Task struct {")
+	assert.Contains(t, out, "// Priority represents an enumeration\n//\n// Deprecated: Priority is deprecated in the schema.\ntype Priority string")
+	assert.Contains(t, out, "\t// Title of the task.\n\t//\n\t// Deprecated: Title is deprecated in the schema.\n\tTitle *string")
+	assert.Contains(t, out, "\t// Deprecated: Name is deprecated in the schema.\n\tName     *string")
+	assert.NotContains(t, out, "GetTaskOutput is deprecated", "tool types are not deprecated with the schemas they reuse")
+}
+
+func TestOptionalStyle(t *testing.T) {
+	const task = `{
+		"type": "object",
+		"required": ["id", "note"],
+		"properties": {
+			"id": {"type": "string"},
+			"title": {"type": "string", "default": "untitled"},
+			"note": {"type": ["string", "null"]},
+			"owner": {"$ref": "#/components/schemas/User"},
+			"tags": {"type": "array", "items": {"type": "string"}}
+		}
+	}`
+
+	tests := []struct {
+		style string
+		want  []string
+	}{
+		{
+			style: "",
+			want:  []string{"ID string", "Title *string", "Note *string", "Owner *User", "Tags []string", "func (v *Task) ApplyDefaults()"},
+		},
+		{
+			style: config.OptionalStyleOmittable,
+			want: []string{
+				"ID string", "Title mcp.Omittable[string] `json:\"title,omitzero\"`", "Note mcp.Omittable[*string]", "Owner mcp.Omittable[*User]", "Tags mcp.Omittable[[]string]",
+				"if !v.Title.IsSet() { v.Title = mcp.NewOmittable(string(\"untitled\")) }",
+			},
+		},
+		{
+			style: config.OptionalStyleValue,
+			want:  []string{"ID string", "Title string", "Note string", "Owner *User", "Tags []string"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			var s, user config.Schema
+			require.NoError(t, json.Unmarshal([]byte(task), &s))
+			require.NoError(t, json.Unmarshal([]byte(`{"type": "object", "properties": {"name": {"type": "string"}}}`), &user))
+
+			gen := NewTypeGenerator()
+			gen.SetOptionalStyle(tt.style)
+			gen.AddSchema("Task", &s)
+			gen.AddSchema("User", &user)
+			code, err := gen.Generate("test")
+			require.NoError(t, err)
+
+			fields := regexp.MustCompile(`\s+`).ReplaceAllString(string(code), " ")
+			for _, want := range tt.want {
+				assert.Contains(t, fields, want)
+			}
+			if tt.style == config.OptionalStyleValue {
+				assert.NotContains(t, fields, "ApplyDefaults", "value fields cannot tell unset from zero")
+			}
+		})
+	}
+}
+
+func TestOptionalStyleAnnotations(t *testing.T) {
+	var s config.Schema
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"go.probo.inc/mcpgen/optional-style": "value",
+		"properties": {
+			"title": {"type": "string"},
+			"note": {"type": "string", "go.probo.inc/mcpgen/optional-style": "omittable"}
+		}
+	}`), &s))
+
+	gen := NewTypeGenerator()
+	gen.AddSchema("Task", &s)
+	code, err := gen.Generate("test")
+	require.NoError(t, err)
+	fields := regexp.MustCompile(`\s+`).ReplaceAllString(string(code), " ")
+	assert.Contains(t, fields, "Title string")
+	assert.Contains(t, fields, "Note mcp.Omittable[string]")
+
+	s.Properties["note"].Extra["go.probo.inc/mcpgen/optional-style"] = "reference"
+	gen = NewTypeGenerator()
+	gen.AddSchema("Task", &s)
+	_, err = gen.Generate("test")
+	assert.ErrorContains(t, err, "field Task.note: go.probo.inc/mcpgen/optional-style must be pointer, omittable or value, got reference")
+}
+
+func TestEnumNamesAndParse(t *testing.T) {
+	enumSchema := &config.Schema{
+		Type: "string",
+		Enum: []any{"pending", "in_progress"},
+	}
+
+	gen := NewTypeGenerator()
+	code, err := gen.generateEnum("Status", enumSchema)
+	require.NoError(t, err)
+	assert.Contains(t, code, "var StatusNames = map[Status]string{\n\tStatusPending: \"Pending\",")
+	assert.Contains(t, code, "var StatusByName = map[string]Status{\n\t\"Pending\": StatusPending,")
+	assert.Contains(t, code, "func ParseStatus(s string) (Status, error) {")
+	assert.NotContains(t, code, "String() string")
+
+	gen = NewTypeGenerator()
+	gen.SetEnumStringer(true)
+	code, err = gen.generateEnum("WindowType", &config.Schema{
+		Type:  "string",
+		Enum:  []any{"1h"},
+		Extra: map[string]any{"x-enum-varnames": []any{"Hour"}},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, code, "\tWindowHour: \"Hour\",")
+	assert.Contains(t, code, "func (e WindowType) String() string {\n\tif name, ok := WindowTypeNames[e]; ok {\n\t\treturn name\n\t}\n\treturn \"WindowType(\" + string(e) + \")\"\n}")
+}
+
+func TestEnumCollections(t *testing.T) {
+	gen := NewTypeGenerator()
+	gen.AddSchema("Status", &config.Schema{Type: "string", Enum: []any{"open", "done"}})
+	gen.AddSchema("Priority", &config.Schema{Types: []string{"string", "null"}, Enum: []any{"low", "high"}})
+	gen.AddSchema("Task", &config.Schema{
+		Type:     "object",
+		Required: []string{"statuses", "byUser", "history"},
+		Properties: map[string]*config.Schema{
+			"statuses":   {Type: "array", Items: &config.Schema{Ref: "#/components/schemas/Status"}},
+			"byUser":     {Type: "object", AdditionalProperties: &config.Schema{Ref: "#/components/schemas/Status"}},
+			"history":    {Type: "array", Items: &config.Schema{Type: "array", Items: &config.Schema{Ref: "#/components/schemas/Status"}}},
+			"priorities": {Type: "array", Items: &config.Schema{Ref: "#/components/schemas/Priority"}},
+			"levels":     {Type: "object", AdditionalProperties: &config.Schema{Type: "string", Enum: []any{"low", "high"}}},
+			"pair": {
+				Type:        "array",
+				PrefixItems: []*config.Schema{{Ref: "#/components/schemas/Status"}, {Ref: "#/components/schemas/Status"}},
+				Items:       &config.Schema{Ref: "#/components/schemas/Status"},
+			},
+		},
+	})
+	gen.AddSchema("Labels", &config.Schema{Type: "object", AdditionalProperties: &config.Schema{Ref: "#/components/schemas/Status"}})
+	var transition config.Schema
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "array",
+		"prefixItems": [{"$ref": "#/components/schemas/Status"}, {"$ref": "#/components/schemas/Status"}],
+		"items": false,
+		"minItems": 2
+	}`), &transition))
+	gen.AddSchema("Transition", &transition)
+
+	code, err := gen.Generate("test")
+	require.NoError(t, err)
+	models := string(code)
+	assert.Regexp(t, `Statuses +\[\]Status +`, models)
+	assert.Regexp(t, `ByUser +map\[string\]Status +`, models)
+	assert.Regexp(t, `History +\[\]\[\]Status +`, models)
+	assert.Regexp(t, `Priorities +\[\]\*Priority +`, models, "nullable enums keep their pointer")
+	assert.Regexp(t, `Levels +map\[string\]TaskLevelsValue +`, models)
+	assert.Regexp(t, `Pair +\[\]Status +`, models)
+	assert.Contains(t, models, "type Labels map[string]Status")
+	assert.Contains(t, models, "type Transition [2]Status")
+}
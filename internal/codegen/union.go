@@ -0,0 +1,232 @@
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.probo.inc/mcpgen/internal/schema"
+)
+
+// discriminatedUnion is a oneOf whose variants are objects told apart by the
+// value of a string property.
+type discriminatedUnion struct {
+	property string
+	variants []unionVariant
+}
+
+// unionVariant is a variant of a discriminated union, with the Go struct
+// type it decodes to.
+type unionVariant struct {
+	value    string
+	typeName string
+	// inline is the schema of a variant declared in the oneOf rather than
+	// referenced, generated as a struct named typeName.
+	inline *schema.Schema
+}
+
+// discriminatedUnion returns the discriminated union of a oneOf schema, or
+// nil when it is not one. The property is the propertyName of an OpenAPI
+// discriminator, or else the first property every variant declares with a
+// distinct string const. The value of each variant comes from the
+// discriminator mapping, the const of its property, or the name of its
+// component.
+func (g *TypeGenerator) discriminatedUnion(name string, s *schema.Schema) *discriminatedUnion {
+	if len(s.OneOf) < 2 {
+		return nil
+	}
+
+	// The object schema of each variant, resolved through component references
+	schemas := make([]*schema.Schema, len(s.OneOf))
+	components := make([]string, len(s.OneOf))
+	for i, variant := range s.OneOf {
+		if variant.Ref != "" {
+			component, ok := strings.CutPrefix(variant.Ref, "#/components/schemas/")
+			if !ok {
+				return nil
+			}
+			if _, ok := g.customMappings[component]; ok {
+				return nil
+			}
+			variant = g.schemas[component]
+			if variant == nil {
+				return nil
+			}
+			components[i] = component
+		}
+		if schema.GetType(variant) != "object" && len(variant.Properties) == 0 {
+			return nil
+		}
+		schemas[i] = variant
+	}
+
+	property, mapping := discriminator(s)
+	if property == "" {
+		property = sharedConstProperty(schemas)
+		if property == "" {
+			return nil
+		}
+	}
+
+	union := &discriminatedUnion{property: property}
+	seen := map[string]bool{}
+	for i, variant := range schemas {
+		value, ok := constString(variant.Properties[property])
+		if ref := s.OneOf[i].Ref; ref != "" {
+			if mapped, found := mapping[ref]; found {
+				value, ok = mapped, true
+			} else if !ok {
+				value, ok = components[i], true
+			}
+		}
+		if !ok || seen[value] {
+			return nil
+		}
+		seen[value] = true
+
+		v := unionVariant{value: value}
+		if components[i] != "" {
+			v.typeName = g.componentTypeName(components[i])
+		} else {
+			v.typeName = name + toGoTypeName(value)
+			v.inline = variant
+		}
+		union.variants = append(union.variants, v)
+	}
+
+	return union
+}
+
+// discriminator returns the property name of the OpenAPI discriminator of s,
+// and its mapping from variant references to values.
+func discriminator(s *schema.Schema) (string, map[string]string) {
+	d, ok := s.Extra["discriminator"].(map[string]any)
+	if !ok {
+		return "", nil
+	}
+	property, _ := d["propertyName"].(string)
+
+	mapping := map[string]string{}
+	values, _ := d["mapping"].(map[string]any)
+	for value, ref := range values {
+		if ref, ok := ref.(string); ok {
+			mapping[ref] = value
+		}
+	}
+	return property, mapping
+}
+
+// sharedConstProperty returns the first property, in name order, that every
+// variant declares with a distinct string const, or "".
+func sharedConstProperty(variants []*schema.Schema) string {
+	names := make([]string, 0, len(variants[0].Properties))
+	for name := range variants[0].Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		seen := map[string]bool{}
+		for _, variant := range variants {
+			value, ok := constString(variant.Properties[name])
+			if !ok || seen[value] {
+				break
+			}
+			seen[value] = true
+		}
+		if len(seen) == len(variants) {
+			return name
+		}
+	}
+	return ""
+}
+
+// constString returns the value of a property schema only accepting one
+// string, with const or a single enum value.
+func constString(s *schema.Schema) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	if s.Const != nil {
+		value, ok := (*s.Const).(string)
+		return value, ok
+	}
+	if len(s.Enum) == 1 {
+		value, ok := s.Enum[0].(string)
+		return value, ok
+	}
+	return "", false
+}
+
+// generateUnion generates the struct holding a variant of a discriminated
+// union, the interface its variants implement and an UnmarshalJSON method
+// decoding the variant selected by the discriminator property.
+func (g *TypeGenerator) generateUnion(name string, s *schema.Schema, union *discriminatedUnion) (string, error) {
+	variantInterface := name + "Variant"
+	marker := "is" + name
+
+	typeNames := make([]string, len(union.variants))
+	for i, variant := range union.variants {
+		typeNames[i] = variant.typeName
+		if variant.inline != nil && g.types[variant.typeName] == "" {
+			code, err := g.generateStruct(variant.typeName, variant.inline, 0)
+			if err != nil {
+				return "", fmt.Errorf("failed to generate variant %s: %w", variant.value, err)
+			}
+			g.types[variant.typeName] = code
+		}
+	}
+
+	var buf strings.Builder
+
+	buf.WriteString(fmt.Sprintf("// %s is implemented by the variants of %s.\n", variantInterface, name))
+	buf.WriteString(fmt.Sprintf("type %s interface {\n", variantInterface))
+	buf.WriteString(fmt.Sprintf("\t%s()\n", marker))
+	buf.WriteString("}\n\n")
+
+	for _, typeName := range typeNames {
+		buf.WriteString(fmt.Sprintf("func (*%s) %s() {}\n", typeName, marker))
+	}
+	buf.WriteString("\n")
+
+	if s.Description != "" {
+		buf.WriteString(formatComment(s.Description, ""))
+	} else {
+		buf.WriteString(fmt.Sprintf("// %s is one of %s, selected by its %s property\n", name, strings.Join(typeNames, ", "), union.property))
+	}
+	buf.WriteString(fmt.Sprintf("type %s struct {\n", name))
+	buf.WriteString(fmt.Sprintf("\tValue %s\n", variantInterface))
+	buf.WriteString("}\n\n")
+
+	buf.WriteString(fmt.Sprintf("// UnmarshalJSON implements json.Unmarshaler, decoding the variant selected by the %s property\n", union.property))
+	buf.WriteString(fmt.Sprintf("func (u *%s) UnmarshalJSON(data []byte) error {\n", name))
+	buf.WriteString("\tvar discriminator struct {\n")
+	buf.WriteString(fmt.Sprintf("\t\tValue *string `json:%q`\n", union.property))
+	buf.WriteString("\t}\n")
+	buf.WriteString("\tif err := json.Unmarshal(data, &discriminator); err != nil {\n")
+	buf.WriteString("\t\treturn err\n")
+	buf.WriteString("\t}\n")
+	buf.WriteString("\tif discriminator.Value == nil {\n")
+	buf.WriteString(fmt.Sprintf("\t\treturn mcp.NewError(mcp.MessageMissingDiscriminator, %q, %q)\n", union.property, name))
+	buf.WriteString("\t}\n")
+	buf.WriteString("\tswitch *discriminator.Value {\n")
+	for _, variant := range union.variants {
+		buf.WriteString(fmt.Sprintf("\tcase %q:\n", variant.value))
+		buf.WriteString(fmt.Sprintf("\t\tu.Value = &%s{}\n", variant.typeName))
+	}
+	buf.WriteString("\tdefault:\n")
+	buf.WriteString(fmt.Sprintf("\t\treturn mcp.NewError(mcp.MessageInvalidDiscriminator, %q, %q, *discriminator.Value)\n", union.property, name))
+	buf.WriteString("\t}\n")
+	buf.WriteString("\treturn json.Unmarshal(data, u.Value)\n")
+	buf.WriteString("}\n\n")
+
+	buf.WriteString("// MarshalJSON implements json.Marshaler\n")
+	buf.WriteString(fmt.Sprintf("func (u %s) MarshalJSON() ([]byte, error) {\n", name))
+	buf.WriteString("\treturn json.Marshal(u.Value)\n")
+	buf.WriteString("}")
+
+	g.imports["encoding/json"] = true
+	g.imports["go.probo.inc/mcpgen/mcp"] = true
+
+	return buf.String(), nil
+}
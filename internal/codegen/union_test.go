@@ -0,0 +1,67 @@
+package codegen
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+func TestDiscriminatedUnion(t *testing.T) {
+	schemas := map[string]string{
+		"Circle": `{"type": "object", "properties": {"type": {"const": "circle"}, "radius": {"type": "number"}}}`,
+		"Square": `{"type": "object", "properties": {"type": {"type": "string"}, "side": {"type": "number"}}}`,
+		"Shape": `{
+			"oneOf": [{"$ref": "#/components/schemas/Circle"}, {"$ref": "#/components/schemas/Square"}],
+			"discriminator": {"propertyName": "type", "mapping": {"sq": "#/components/schemas/Square"}}
+		}`,
+		"Command": `{
+			"type": "object",
+			"properties": {
+				"action": {
+					"oneOf": [
+						{"type": "object", "properties": {"kind": {"const": "move"}, "dx": {"type": "number"}}},
+						{"type": "object", "properties": {"kind": {"enum": ["delete"]}}}
+					]
+				},
+				"target": {"oneOf": [{"type": "string"}, {"type": "number"}]}
+			}
+		}`,
+	}
+
+	gen := NewTypeGenerator()
+	for name, data := range schemas {
+		var s config.Schema
+		require.NoError(t, json.Unmarshal([]byte(data), &s))
+		gen.AddSchema(name, &s)
+	}
+	code, err := gen.Generate("test")
+	require.NoError(t, err)
+	codeStr := string(code)
+
+	t.Run("discriminator with mapping", func(t *testing.T) {
+		assert.Contains(t, codeStr, "type ShapeVariant interface {\n\tisShape()\n}")
+		assert.Contains(t, codeStr, "func (*Circle) isShape() {}")
+		assert.Contains(t, codeStr, "func (*Square) isShape() {}")
+		assert.Contains(t, codeStr, "type Shape struct {\n\tValue ShapeVariant\n}")
+		assert.Contains(t, codeStr, "Value *string `json:\"type\"`")
+		assert.Contains(t, codeStr, "\tcase \"circle\":\n\t\tu.Value = &Circle{}\n\tcase \"sq\":\n\t\tu.Value = &Square{}\n")
+		assert.Contains(t, codeStr, `return mcp.NewError(mcp.MessageInvalidDiscriminator, "type", "Shape", *discriminator.Value)`)
+		assert.Contains(t, codeStr, "func (u Shape) MarshalJSON() ([]byte, error) {")
+	})
+
+	t.Run("shared const property", func(t *testing.T) {
+		assert.Contains(t, codeStr, "Action *CommandAction")
+		assert.Contains(t, codeStr, "// CommandAction is one of CommandActionMove, CommandActionDelete, selected by its kind property")
+		assert.Contains(t, codeStr, "type CommandActionMove struct {")
+		assert.Contains(t, codeStr, "Kind *string")
+		assert.Contains(t, codeStr, "\tcase \"delete\":\n\t\tu.Value = &CommandActionDelete{}\n")
+		assert.Contains(t, codeStr, `return mcp.NewError(mcp.MessageMissingDiscriminator, "kind", "CommandAction")`)
+	})
+
+	t.Run("oneOf without discriminator", func(t *testing.T) {
+		assert.Contains(t, codeStr, "Target *any")
+	})
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMCPSpecAnchors(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"schema.yaml": `info:
+  title: tasks
+  version: 1.0.0
+components:
+  schemas:
+    Base: &base
+      type: object
+      properties: &base-properties
+        id:
+          type: string
+      required: [id]
+    Task:
+      <<: *base
+      description: A task.
+      properties:
+        <<: *base-properties
+        title:
+          type: string
+tools:
+  - name: get_task
+    hints: &readonly
+      readonly: true
+      idempotent: true
+    inputSchema: &by-id
+      type: object
+      properties:
+        id:
+          $ref: '#/$defs/ID'
+      $defs:
+        ID:
+          type: string
+          format: uuid
+  - name: delete_task
+    hints:
+      <<: *readonly
+      readonly: false
+      destructive: true
+    inputSchema: *by-id
+`,
+	})
+
+	spec, err := LoadMCPSpec(filepath.Join(dir, "schema.yaml"))
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"type": "object",
+		"description": "A task.",
+		"properties": {"id": {"type": "string"}, "title": {"type": "string"}},
+		"required": ["id"]
+	}`, schemaJSON(t, spec.Components.Schemas["Task"]), "merge keys are applied, keys set next to them win")
+	assert.JSONEq(t, `{"type": "object", "properties": {"id": {"type": "string"}}, "required": ["id"]}`, schemaJSON(t, spec.Components.Schemas["Base"]), "merging leaves the anchored node alone")
+
+	assert.Equal(t, &ToolHints{Readonly: true, Idempotent: true}, spec.Tools[0].Hints)
+	assert.Equal(t, &ToolHints{Destructive: true, Idempotent: true}, spec.Tools[1].Hints)
+
+	assert.Equal(t, "#/components/schemas/ID", spec.Tools[0].InputSchema.Properties["id"].Ref)
+	assert.Equal(t, "#/components/schemas/ID", spec.Tools[1].InputSchema.Properties["id"].Ref, "aliased schemas share their hoisted definitions")
+	assert.NotContains(t, spec.Components.Schemas, "ID2")
+}
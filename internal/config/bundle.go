@@ -0,0 +1,395 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// refTarget identifies the schema a $ref to another file points to: the
+// absolute path of the file and a JSON pointer within it.
+type refTarget struct {
+	file    string
+	pointer string
+}
+
+func (t refTarget) String() string {
+	return t.file + "#" + t.pointer
+}
+
+// bundler replaces the $ref to schemas of other files by references to
+// component schemas, so the generator and the linter only deal with a single
+// document. Referenced schemas are copied into the components of the main
+// spec, named after the last segment of their pointer, or after their file
+// when they point to a whole file. A component schema consisting of such a
+// $ref becomes the schema it points to, and so do the input, output and
+// resource schemas, so that they keep being generated as before.
+type bundler struct {
+	root   map[string]interface{}
+	remote *remoteRefs
+	files  map[string]interface{}
+	names  map[refTarget]string
+	taken  map[string]bool
+}
+
+type specDocument struct {
+	path string
+	doc  map[string]interface{}
+}
+
+func newBundler(root map[string]interface{}, remote *remoteRefs) *bundler {
+	return &bundler{
+		root:   root,
+		remote: remote,
+		files:  map[string]interface{}{},
+		names:  map[refTarget]string{},
+		taken:  map[string]bool{},
+	}
+}
+
+// bundle resolves the $ref to other files of the spec documents, relative to
+// the document they appear in. A $ref starting with # refers to the spec
+// itself.
+func (b *bundler) bundle(docs []specDocument) error {
+	// Component schemas which are a $ref to another file keep their name, so
+	// register them before anything else references their target
+	for _, d := range docs {
+		schemas := componentSchemas(d.doc)
+		for _, name := range sortedKeys(schemas) {
+			b.taken[name] = true
+			if target, ok, err := b.rootRef(schemas[name], d.path); err == nil && ok {
+				if _, exists := b.names[target]; !exists {
+					b.names[target] = name
+				}
+			}
+		}
+	}
+
+	for _, d := range docs {
+		schemas := componentSchemas(d.doc)
+		for _, name := range sortedKeys(schemas) {
+			path := "components.schemas." + name
+			target, ok, err := b.rootRef(schemas[name], d.path)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			if ok && b.names[target] == name {
+				content, err := b.resolve(target, path)
+				if err != nil {
+					return err
+				}
+				schemas[name] = content
+				continue
+			}
+			if err := b.rewrite(schemas[name], d.path, path); err != nil {
+				return err
+			}
+		}
+
+		entries := func(section string, schemaKeys ...string) error {
+			items, _ := d.doc[section].([]interface{})
+			for i, item := range items {
+				entry, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				name, _ := entry["name"].(string)
+				for _, key := range schemaKeys {
+					s, ok := entry[key]
+					if !ok {
+						continue
+					}
+					inlined, err := b.inline(s, d.path, entryPath(section, i, name)+"."+key)
+					if err != nil {
+						return err
+					}
+					entry[key] = inlined
+				}
+			}
+			return nil
+		}
+		if err := entries("tools", "inputSchema", "outputSchema"); err != nil {
+			return err
+		}
+		if err := entries("resources", "schema"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// inline returns the schema a root schema consisting of a $ref to another
+// file points to, or a reference to its component when it has one. Other
+// schemas are returned with their references rewritten.
+func (b *bundler) inline(s interface{}, base, path string) (interface{}, error) {
+	target, ok, err := b.rootRef(s, base)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if !ok {
+		return s, b.rewrite(s, base, path)
+	}
+
+	if name, ok := b.names[target]; ok {
+		return map[string]interface{}{"$ref": "#/components/schemas/" + name}, nil
+	}
+	return b.resolve(target, path)
+}
+
+// rootRef reports the target of a schema consisting only of a $ref to
+// another file.
+func (b *bundler) rootRef(s interface{}, base string) (refTarget, bool, error) {
+	m, ok := s.(map[string]interface{})
+	if !ok || len(m) != 1 {
+		return refTarget{}, false, nil
+	}
+	ref, ok := m["$ref"].(string)
+	if !ok {
+		return refTarget{}, false, nil
+	}
+	return b.target(ref, base)
+}
+
+// target returns the target of a $ref found in the file base. References
+// within the spec are not external, but references within another file are.
+func (b *bundler) target(ref, base string) (refTarget, bool, error) {
+	file, pointer, _ := strings.Cut(ref, "#")
+	if file == "" {
+		if _, external := b.files[base]; !external {
+			return refTarget{}, false, nil
+		}
+		file = base
+	}
+	if pointer != "" && !strings.HasPrefix(pointer, "/") {
+		return refTarget{}, false, fmt.Errorf("$ref %s: fragment must be a JSON pointer, such as #/Task", ref)
+	}
+
+	u, err := url.Parse(file)
+	if err != nil {
+		return refTarget{}, false, fmt.Errorf("$ref %s: %w", ref, err)
+	}
+	switch {
+	case isRemote(base):
+		// References within a remote document are relative to its URL
+		baseURL, err := url.Parse(base)
+		if err != nil {
+			return refTarget{}, false, fmt.Errorf("$ref %s: %w", ref, err)
+		}
+		file = baseURL.ResolveReference(u).String()
+	case u.Scheme == "https":
+		if b.remote == nil {
+			return refTarget{}, false, fmt.Errorf("$ref %s: remote references are only supported when loading a spec through its config file", ref)
+		}
+	case u.Scheme != "" && len(u.Scheme) > 1:
+		return refTarget{}, false, fmt.Errorf("$ref %s: only references to local files and https URLs are supported", ref)
+	default:
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(filepath.Dir(base), file)
+		}
+		file = filepath.Clean(file)
+	}
+	if isRemote(file) && !strings.HasPrefix(file, "https://") {
+		return refTarget{}, false, fmt.Errorf("$ref %s: only references to local files and https URLs are supported", ref)
+	}
+
+	return refTarget{file: file, pointer: pointer}, true, nil
+}
+
+// isRemote reports whether a referenced document is a URL.
+func isRemote(file string) bool {
+	return strings.Contains(file, "://")
+}
+
+// rewrite replaces the $ref to other files found in s, a schema of the file
+// base, by references to component schemas.
+func (b *bundler) rewrite(s interface{}, base, path string) error {
+	switch v := s.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(v) {
+			switch key {
+			case "$ref":
+				ref, ok := v[key].(string)
+				if !ok {
+					continue
+				}
+				target, ok, err := b.target(ref, base)
+				if err != nil {
+					return fmt.Errorf("%s: %w", path, err)
+				}
+				if !ok {
+					continue
+				}
+				name, err := b.component(target, path)
+				if err != nil {
+					return err
+				}
+				v[key] = "#/components/schemas/" + name
+			case "enum", "const", "default", "examples":
+				// Values, not schemas
+			default:
+				if err := b.rewrite(v[key], base, path+"."+key); err != nil {
+					return err
+				}
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			if err := b.rewrite(item, base, path+"["+strconv.Itoa(i)+"]"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// component returns the name of the component schema holding target, adding
+// it to the main spec on first use.
+func (b *bundler) component(target refTarget, path string) (string, error) {
+	if name, ok := b.names[target]; ok {
+		return name, nil
+	}
+
+	base := documentName(target.file)
+	if i := strings.LastIndex(target.pointer, "/"); i >= 0 && i < len(target.pointer)-1 {
+		base = unescapePointer(target.pointer[i+1:])
+	}
+	name := base
+	for i := 2; b.taken[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	b.names[target] = name
+	b.taken[name] = true
+
+	content, err := b.resolve(target, path)
+	if err != nil {
+		return "", err
+	}
+
+	components, ok := b.root["components"].(map[string]interface{})
+	if !ok {
+		components = map[string]interface{}{}
+		b.root["components"] = components
+	}
+	schemas, ok := components["schemas"].(map[string]interface{})
+	if !ok {
+		schemas = map[string]interface{}{}
+		components["schemas"] = schemas
+	}
+	schemas[name] = content
+
+	return name, nil
+}
+
+// resolve returns a copy of the schema target points to, with its own
+// references rewritten.
+func (b *bundler) resolve(target refTarget, path string) (interface{}, error) {
+	doc, ok := b.files[target.file]
+	if !ok {
+		var file map[string]interface{}
+		var err error
+		if isRemote(target.file) {
+			file, err = b.remote.document(target.file)
+		} else {
+			file, err = readSpecDocument(target.file)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: $ref %s: %w", path, target, err)
+		}
+		doc = file
+		b.files[target.file] = doc
+	}
+
+	node := doc
+	if target.pointer != "" {
+		for _, token := range strings.Split(target.pointer[1:], "/") {
+			token = unescapePointer(token)
+			switch v := node.(type) {
+			case map[string]interface{}:
+				next, ok := v[token]
+				if !ok {
+					return nil, fmt.Errorf("%s: $ref %s: %s not found", path, target, token)
+				}
+				node = next
+			case []interface{}:
+				i, err := strconv.Atoi(token)
+				if err != nil || i < 0 || i >= len(v) {
+					return nil, fmt.Errorf("%s: $ref %s: index %s out of range", path, target, token)
+				}
+				node = v[i]
+			default:
+				return nil, fmt.Errorf("%s: $ref %s: %s not found", path, target, token)
+			}
+		}
+	}
+
+	switch node.(type) {
+	case map[string]interface{}, bool:
+	default:
+		return nil, fmt.Errorf("%s: $ref %s: not a schema", path, target)
+	}
+
+	content := deepCopy(node)
+	if m, ok := content.(map[string]interface{}); ok {
+		// The definitions referenced from the file are added to the
+		// components as well, the others are not needed
+		delete(m, "$defs")
+		delete(m, "definitions")
+	}
+	if err := b.rewrite(content, target.file, path); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// documentName returns the name of a referenced file or URL, without its
+// extension.
+func documentName(file string) string {
+	if u, err := url.Parse(file); err == nil && isRemote(file) {
+		file = u.Path
+	}
+	name := path.Base(filepath.ToSlash(file))
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+func componentSchemas(doc map[string]interface{}) map[string]interface{} {
+	components, _ := doc["components"].(map[string]interface{})
+	schemas, _ := components["schemas"].(map[string]interface{})
+	return schemas
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func unescapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
+
+func deepCopy(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			result[key] = deepCopy(value)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, value := range v {
+			result[i] = deepCopy(value)
+		}
+		return result
+	default:
+		return v
+	}
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func schemaJSON(t *testing.T, s *Schema) string {
+	t.Helper()
+	data, err := json.Marshal(s)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestLoadMCPSpecResolvesFileRefs(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"schema.yaml": `info:
+  title: tasks
+  version: 1.0.0
+components:
+  schemas:
+    Task:
+      $ref: ./schemas/task.yaml#/Task
+tools:
+  - name: create_task
+    inputSchema:
+      $ref: ./schemas/task.yaml#/CreateTask
+    outputSchema:
+      type: object
+      properties:
+        task:
+          $ref: ./schemas/task.yaml#/Task
+  - name: tag
+    inputSchema:
+      $ref: schemas/tag.json
+`,
+		"schemas/task.yaml": `Task:
+  type: object
+  properties:
+    status:
+      $ref: '#/Status'
+    tags:
+      type: array
+      items:
+        $ref: ../shared/tag.yaml#/Tag
+Status:
+  type: string
+  enum: [open, done]
+CreateTask:
+  type: object
+  properties:
+    status:
+      $ref: '#/Status'
+`,
+		"shared/tag.yaml": `Tag:
+  type: object
+  properties:
+    name:
+      type: string
+`,
+		"schemas/tag.json": `{"type": "object", "properties": {"name": {"type": "string"}}}`,
+	})
+
+	spec, err := LoadMCPSpec(filepath.Join(dir, "schema.yaml"))
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"status": {"$ref": "#/components/schemas/Status"},
+			"tags": {"type": "array", "items": {"$ref": "#/components/schemas/Tag"}}
+		}
+	}`, schemaJSON(t, spec.Components.Schemas["Task"]))
+	assert.JSONEq(t, `{"type": "string", "enum": ["open", "done"]}`, schemaJSON(t, spec.Components.Schemas["Status"]))
+	assert.JSONEq(t, `{"type": "object", "properties": {"name": {"type": "string"}}}`, schemaJSON(t, spec.Components.Schemas["Tag"]))
+	assert.Len(t, spec.Components.Schemas, 3)
+
+	assert.JSONEq(t, `{
+		"type": "object",
+		"properties": {"status": {"$ref": "#/components/schemas/Status"}}
+	}`, schemaJSON(t, spec.Tools[0].InputSchema))
+	assert.Equal(t, "#/components/schemas/Task", spec.Tools[0].OutputSchema.Properties["task"].Ref)
+	assert.JSONEq(t, `{"type": "object", "properties": {"name": {"type": "string"}}}`, schemaJSON(t, spec.Tools[1].InputSchema))
+}
+
+func TestLoadMCPSpecFileRefNameClash(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"schema.yaml": `info:
+  title: tasks
+  version: 1.0.0
+components:
+  schemas:
+    Tag:
+      type: string
+tools:
+  - name: tag
+    inputSchema:
+      type: object
+      properties:
+        tag:
+          $ref: tag.yaml#/Tag
+`,
+		"tag.yaml": "Tag:\n  type: object\n",
+	})
+
+	spec, err := LoadMCPSpec(filepath.Join(dir, "schema.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "#/components/schemas/Tag2", spec.Tools[0].InputSchema.Properties["tag"].Ref)
+	assert.Equal(t, "object", spec.Components.Schemas["Tag2"].Type)
+	assert.Equal(t, "string", spec.Components.Schemas["Tag"].Type)
+}
+
+func TestLoadMCPSpecFileRefErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		ref  string
+		err  string
+	}{
+		{name: "missing file", ref: "missing.yaml#/Task", err: "failed to read MCP spec file"},
+		{name: "missing pointer", ref: "task.yaml#/Missing", err: "Missing not found"},
+		{name: "not a pointer", ref: "task.yaml#Task", err: "fragment must be a JSON pointer"},
+		{name: "remote without config", ref: "https://example.com/task.yaml#/Task", err: "remote references are only supported when loading a spec through its config file"},
+		{name: "http", ref: "http://example.com/task.yaml#/Task", err: "only references to local files and https URLs are supported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{
+				"schema.yaml": `info:
+  title: tasks
+  version: 1.0.0
+tools:
+  - name: get_task
+    inputSchema:
+      $ref: ` + tt.ref + "\n",
+				"task.yaml": "Task:\n  type: object\n",
+			})
+
+			_, err := LoadMCPSpec(filepath.Join(dir, "schema.yaml"))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "tools[0] (get_task).inputSchema")
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+// validateSteps checks the steps of the composite tool at path and the data
+// mappings of its steps and output.
+func (s *MCPSpec) validateSteps(errs *ValidationError, path string, tool Tool) {
+	tools := make(map[string]Tool, len(s.Tools))
+	for _, t := range s.Tools {
+		tools[t.Name] = t
+	}
+
+	steps := make(map[string]bool, len(tool.Steps))
+	for i, step := range tool.Steps {
+		stepPath := entryPath(path+".steps", i, step.Tool)
+		called, ok := tools[step.Tool]
+		switch {
+		case step.Tool == "":
+			errs.add("%s.tool is required", stepPath)
+		case !ok:
+			errs.add("%s.tool: unknown tool %q", stepPath, step.Tool)
+		case called.IsComposite():
+			errs.add("%s.tool: %q is a composite tool, list its steps instead", stepPath, step.Tool)
+		case called.TakesNoInput() && step.Input != nil:
+			errs.add("%s.input: tool %q takes no input", stepPath, step.Tool)
+		}
+
+		validateMapping(errs, stepPath+".input", step.Input, steps)
+
+		id := step.StepID()
+		switch {
+		case strings.Contains(id, "."):
+			errs.add("%s.id %q must not contain '.', set an id without it", stepPath, id)
+		case steps[id]:
+			errs.add("%s.id %q is already used by a previous step", stepPath, id)
+		}
+		steps[id] = true
+	}
+
+	validateMapping(errs, path+".output", tool.Output, steps)
+}
+
+// validateMapping checks that the values mapping selects are the input or
+// the output of one of steps.
+func validateMapping(errs *ValidationError, path string, mapping any, steps map[string]bool) {
+	switch m := mapping.(type) {
+	case string:
+		if strings.HasPrefix(m, "$$") {
+			return
+		}
+		expr, ok := strings.CutPrefix(m, "$")
+		if !ok {
+			return
+		}
+		segments := strings.Split(expr, ".")
+		switch {
+		case segments[0] == "input":
+		case segments[0] == "steps" && len(segments) > 1:
+			if !steps[segments[1]] {
+				errs.add("%s: %q refers to step %q, which does not run before", path, m, segments[1])
+			}
+		default:
+			errs.add("%s: %q must select $input or $steps.<id>, or start with $$ to be a string", path, m)
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			validateMapping(errs, path+"."+key, m[key], steps)
+		}
+	case []any:
+		for _, sub := range m {
+			validateMapping(errs, path, sub, steps)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpecValidateCompositeTools(t *testing.T) {
+	object := &Schema{Type: "object"}
+	spec := &MCPSpec{
+		Info: ServerInfo{Title: "test", Version: "1.0.0"},
+		Tools: []Tool{
+			{Name: "create_user", InputSchema: object, OutputSchema: object},
+			{Name: "ping", NoInput: true},
+			{
+				Name:        "onboard",
+				InputSchema: object,
+				Steps: []ToolStep{
+					{Tool: "create_user", Input: map[string]any{"name": "$input.name", "price": "$$5"}},
+					{ID: "welcome", Tool: "create_user", Input: map[string]any{"referrer": "$steps.create_user.id"}},
+					{Tool: "ping"},
+				},
+				Output: map[string]any{"user": "$steps.create_user", "welcome": "$steps.welcome.id"},
+			},
+		},
+	}
+	assert.NoError(t, spec.Validate())
+
+	spec.Tools = append(spec.Tools,
+		Tool{
+			Name:        "broken",
+			InputSchema: object,
+			Steps: []ToolStep{
+				{Tool: "delete_user"},
+				{Tool: "onboard"},
+				{Tool: "ping", Input: map[string]any{}},
+				{Tool: "create_user", Input: map[string]any{"a": "$steps.later.id", "b": []any{"$output"}}},
+				{ID: "later", Tool: "create_user"},
+				{ID: "later", Tool: "create_user"},
+				{ID: "a.b", Tool: "create_user"},
+			},
+		},
+		Tool{Name: "plain", NoInput: true, Output: "$input"},
+		Tool{Name: "grouped", InputSchema: object, Group: "users", Steps: []ToolStep{{Tool: "create_user"}}},
+	)
+	assert.EqualError(t, spec.Validate(), `9 problems:
+  - tools[3] (broken).steps[0] (delete_user).tool: unknown tool "delete_user"
+  - tools[3] (broken).steps[1] (onboard).tool: "onboard" is a composite tool, list its steps instead
+  - tools[3] (broken).steps[2] (ping).input: tool "ping" takes no input
+  - tools[3] (broken).steps[3] (create_user).input.a: "$steps.later.id" refers to step "later", which does not run before
+  - tools[3] (broken).steps[3] (create_user).input.b: "$output" must select $input or $steps.<id>, or start with $$ to be a string
+  - tools[3] (broken).steps[5] (create_user).id "later" is already used by a previous step
+  - tools[3] (broken).steps[6] (create_user).id "a.b" must not contain '.', set an id without it
+  - tools[4] (plain).output requires steps
+  - tools[5] (grouped).group: composite tools have no resolver handler to group`)
+}
@@ -3,25 +3,118 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"go/build/constraint"
 	"os"
 	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/google/jsonschema-go/jsonschema"
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Spec     string         `yaml:"spec" json:"spec"`
+	Spec string `yaml:"spec" json:"spec"`
+	// Include merges the tools, resources, prompts and component schemas of
+	// the spec files matching these glob patterns, relative to the config
+	// file, into the spec.
+	// Example: tools/*.yaml
+	Include  []string       `yaml:"include,omitempty" json:"include,omitempty"`
 	Output   string         `yaml:"output" json:"output"`
 	Exec     ExecConfig     `yaml:"exec,omitempty" json:"exec,omitempty"`
 	Resolver ResolverConfig `yaml:"resolver" json:"resolver"`
 	Model    ModelConfig    `yaml:"model,omitempty" json:"model,omitempty"`
 	Models   ModelsConfig   `yaml:"models,omitempty" json:"models,omitempty"`
+	Lint     LintConfig     `yaml:"lint,omitempty" json:"lint,omitempty"`
+	Import   ImportConfig   `yaml:"import,omitempty" json:"import,omitempty"`
+	// Formats maps string formats to the Go type of every string schema
+	// with that format.
+	// Example: uuid: github.com/google/uuid.UUID
+	Formats map[string]string `yaml:"formats,omitempty" json:"formats,omitempty"`
+	// Autobind lists Go packages whose exported types are used for the
+	// component schemas of the same name instead of generated ones.
+	// Example: github.com/myorg/app/models
+	Autobind []string `yaml:"autobind,omitempty" json:"autobind,omitempty"`
+	// Templates is a directory of code templates, such as server.gotpl,
+	// used instead of the embedded templates of the same name.
+	// Example: templates
+	Templates string `yaml:"templates,omitempty" json:"templates,omitempty"`
+	// Header sets the comments heading every generated Go file.
+	Header HeaderConfig `yaml:"header,omitempty" json:"header,omitempty"`
+
+	// SpecPath is the resolved path of the spec file, set by Load
+	SpecPath string `yaml:"-" json:"-"`
+	// IncludePaths are the spec files matched by Include, sorted, set by Load
+	IncludePaths []string `yaml:"-" json:"-"`
+
+	// dir is the directory of the configuration file, which holds the lock
+	// and the cache of the remote $ref documents
+	dir string
 }
 
 type ExecConfig struct {
 	Package  string `yaml:"package,omitempty" json:"package,omitempty"`
 	Filename string `yaml:"filename,omitempty" json:"filename,omitempty"`
+	// LenientCoercion converts string-encoded numbers and booleans in tool
+	// arguments to the type declared by the input schema before validation.
+	LenientCoercion bool `yaml:"lenient_coercion,omitempty" json:"lenient_coercion,omitempty"`
+	// ValidateInput validates tool arguments against the input schema before
+	// they are decoded, answering invalid ones with an error result.
+	ValidateInput bool `yaml:"validate_input,omitempty" json:"validate_input,omitempty"`
+	// ValidateOutput validates tool outputs against their output schema
+	// before they are returned, see mcputil.WithOutputValidation.
+	ValidateOutput bool `yaml:"validate_output,omitempty" json:"validate_output,omitempty"`
+	// OpenAPI generates an OpenAPI document describing the HTTP transport.
+	OpenAPI OpenAPIConfig `yaml:"openapi,omitempty" json:"openapi,omitempty"`
+	// SlowCallThreshold adds a middleware logging the goroutine stack of
+	// handlers still running after this duration, e.g. "2s".
+	SlowCallThreshold string `yaml:"slow_call_threshold,omitempty" json:"slow_call_threshold,omitempty"`
+	// SanitizeResults strips control characters and invalid UTF-8 from the
+	// text of tool, prompt and resource results, for clients on stdio.
+	SanitizeResults bool `yaml:"sanitize_results,omitempty" json:"sanitize_results,omitempty"`
+	// SwappableResolver generates a SwappableResolver forwarding every
+	// handler to a resolver that can be replaced at runtime with SetResolver.
+	SwappableResolver bool `yaml:"swappable_resolver,omitempty" json:"swappable_resolver,omitempty"`
+	// SchemaRegistry generates a Schemas registry of the resolved component
+	// schemas, to validate JSON fragments against them at runtime.
+	SchemaRegistry bool `yaml:"schema_registry,omitempty" json:"schema_registry,omitempty"`
+	// LazyTools resolves the schemas of each tool on its first call rather
+	// than when the server is created, and generates a Prewarm function
+	// resolving them ahead of time, see mcputil.WithLazyTools.
+	LazyTools bool `yaml:"lazy_tools,omitempty" json:"lazy_tools,omitempty"`
+	// MinimalRuntime shrinks the generated code: the component schemas are
+	// embedded once, shared by the tool schemas using them, and the schemas
+	// lose their titles and descriptions.
+	MinimalRuntime bool `yaml:"minimal_runtime,omitempty" json:"minimal_runtime,omitempty"`
+	// ToolDocs serves a markdown document per tool, generated from the
+	// spec, as the docs://tools/<name> resource.
+	ToolDocs bool `yaml:"tool_docs,omitempty" json:"tool_docs,omitempty"`
+	// Admin generates the metadata of the tools served by the admin
+	// endpoints that Run listens on with RunConfig.AdminAddr, and a tool
+	// switch to disable tools at runtime, see mcputil.AdminHandler.
+	Admin bool `yaml:"admin,omitempty" json:"admin,omitempty"`
+	// Fake generates an in-memory fake of the server, to test its clients.
+	Fake FakeConfig `yaml:"fake,omitempty" json:"fake,omitempty"`
+}
+
+type FakeConfig struct {
+	// Filename of the fake, relative to the output directory. The fake is
+	// only generated when set.
+	// Example: servertest/fake.go
+	Filename string `yaml:"filename,omitempty" json:"filename,omitempty"`
+	// Package of the fake. Defaults to the name of the directory of Filename.
+	Package string `yaml:"package,omitempty" json:"package,omitempty"`
+}
+
+type OpenAPIConfig struct {
+	// Filename of the document, relative to the output directory. The
+	// document is only generated when set.
+	// Example: openapi.yaml
+	Filename string `yaml:"filename,omitempty" json:"filename,omitempty"`
+	// Path the HTTP transport is mounted on. Defaults to /mcp.
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
 }
 
 type ResolverConfig struct {
@@ -34,6 +127,50 @@ type ResolverConfig struct {
 type ModelConfig struct {
 	Package  string `yaml:"package,omitempty" json:"package,omitempty"`
 	Filename string `yaml:"filename,omitempty" json:"filename,omitempty"`
+	// StrictInputs rejects tool arguments with properties the input schema
+	// does not declare, as if every input object had additionalProperties: false.
+	StrictInputs bool `yaml:"strict_inputs,omitempty" json:"strict_inputs,omitempty"`
+	// OptionalStyle sets how optional and nullable properties are generated:
+	// pointer (the default), omittable or value. Schemas and properties
+	// override it with the go.probo.inc/mcpgen/optional-style annotation.
+	OptionalStyle string `yaml:"optional_style,omitempty" json:"optional_style,omitempty"`
+	// EnumStringer generates a String method returning the name of the
+	// constant of enum values, as the stringer tool does.
+	EnumStringer bool `yaml:"enum_stringer,omitempty" json:"enum_stringer,omitempty"`
+	// Layout sets how the models are split into files: single (the
+	// default) writes them to Filename, per-schema, or its alias per-type,
+	// writes the types of each component schema to a file of its own next
+	// to it.
+	Layout string `yaml:"layout,omitempty" json:"layout,omitempty"`
+	// PropertyOrder sets the order of the fields of the generated structs
+	// and of the properties of the schema JSON: alphabetical (the default)
+	// or spec, the order of the properties in the spec file.
+	PropertyOrder string `yaml:"property_order,omitempty" json:"property_order,omitempty"`
+}
+
+// Model layouts of ModelConfig.Layout.
+const (
+	ModelLayoutSingle    = "single"
+	ModelLayoutPerSchema = "per-schema"
+	ModelLayoutPerType   = "per-type"
+)
+
+// Property orders of ModelConfig.PropertyOrder.
+const (
+	PropertyOrderAlphabetical = "alphabetical"
+	PropertyOrderSpec         = "spec"
+)
+
+// Optional field styles of ModelConfig.OptionalStyle.
+const (
+	OptionalStylePointer   = "pointer"
+	OptionalStyleOmittable = "omittable"
+	OptionalStyleValue     = "value"
+)
+
+// IsOptionalStyle reports whether style is a valid optional field style.
+func IsOptionalStyle(style string) bool {
+	return style == OptionalStylePointer || style == OptionalStyleOmittable || style == OptionalStyleValue
 }
 
 type ModelsConfig struct {
@@ -48,6 +185,45 @@ type TypeMapping struct {
 	Model string `yaml:"model" json:"model"`
 }
 
+type LintConfig struct {
+	// Enable or disable lint rules by name. Rules not listed are enabled.
+	// Example: tool-output-schema: false
+	Rules map[string]bool `yaml:"rules,omitempty" json:"rules,omitempty"`
+}
+
+type HeaderConfig struct {
+	// Comment heads every generated Go file, such as the license of the
+	// project. Each of its lines becomes a // comment line.
+	Comment string `yaml:"comment,omitempty" json:"comment,omitempty"`
+	// BuildTags is the build constraint of the generated Go files.
+	// Example: linux && !tinygo
+	BuildTags string `yaml:"build_tags,omitempty" json:"build_tags,omitempty"`
+	// Version records the version of mcpgen in the generated code comment
+	// of every file, such as "Code generated by mcpgen v1.4.0".
+	Version bool `yaml:"version,omitempty" json:"version,omitempty"`
+}
+
+type ImportConfig struct {
+	OpenAPI OpenAPIImportConfig `yaml:"openapi,omitempty" json:"openapi,omitempty"`
+}
+
+type OpenAPIImportConfig struct {
+	// Document imported by mcpgen import openapi when none is given on the
+	// command line, relative to the config file.
+	Document string `yaml:"document,omitempty" json:"document,omitempty"`
+	// Import only operations with one of these tags.
+	Tags []string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	// Import only operations whose path matches one of these patterns.
+	// Example: /pets/*
+	Paths []string `yaml:"paths,omitempty" json:"paths,omitempty"`
+}
+
+// RuleEnabled reports whether the named lint rule is enabled.
+func (c LintConfig) RuleEnabled(name string) bool {
+	enabled, ok := c.Rules[name]
+	return !ok || enabled
+}
+
 type ServerInfo struct {
 	Title       string `yaml:"title" json:"title"`
 	Version     string `yaml:"version" json:"version"`
@@ -69,17 +245,88 @@ type ToolHints struct {
 
 type Tool struct {
 	Name         string            `yaml:"name" json:"name"`
+	Title        string            `yaml:"title,omitempty" json:"title,omitempty"`
+	Icon         string            `yaml:"icon,omitempty" json:"icon,omitempty"`
 	Description  string            `yaml:"description,omitempty" json:"description,omitempty"`
-	InputSchema  *Schema           `yaml:"inputSchema" json:"inputSchema"`
+	InputSchema  *Schema           `yaml:"inputSchema,omitempty" json:"inputSchema,omitempty"`
+	NoInput      bool              `yaml:"noInput,omitempty" json:"noInput,omitempty"`
 	OutputSchema *Schema           `yaml:"outputSchema,omitempty" json:"outputSchema,omitempty"`
 	Hints        *ToolHints        `yaml:"hints,omitempty" json:"hints,omitempty"`
 	Annotations  map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
 	Handler      string            `yaml:"handler,omitempty" json:"handler,omitempty"`
+	// Group names the sub-resolver implementing the handler of the tool,
+	// generated in <group>.resolvers.go, to split the handlers of large
+	// servers by domain.
+	Group string `yaml:"group,omitempty" json:"group,omitempty"`
+	// RequiresClientCapability hides the tool from the clients not declaring
+	// these capabilities: sampling, elicitation or experimental.<name>.
+	RequiresClientCapability []string `yaml:"requiresClientCapability,omitempty" json:"requiresClientCapability,omitempty"`
+	// Experiment only registers the tool when the named experiment of the
+	// spec is enabled.
+	Experiment string `yaml:"experiment,omitempty" json:"experiment,omitempty"`
+	// Deprecated marks the handler of the tool as deprecated and advertises
+	// the deprecation in the metadata of the tool.
+	Deprecated bool `yaml:"deprecated,omitempty" json:"deprecated,omitempty"`
+	// ReplacedBy names the tool replacing a deprecated tool. The results of
+	// the deprecated tool then point its clients at the replacement.
+	ReplacedBy string `yaml:"replacedBy,omitempty" json:"replacedBy,omitempty"`
+	// DevFixture is the result of the tool served, in development, while its
+	// handler is not implemented.
+	DevFixture any `yaml:"devFixture,omitempty" json:"devFixture,omitempty"`
+	// Steps makes the tool a composite tool, whose generated handler calls
+	// the handlers of these tools in order instead of a handler of the
+	// resolver.
+	Steps []ToolStep `yaml:"steps,omitempty" json:"steps,omitempty"`
+	// Output maps the input and the step outputs of a composite tool to its
+	// output, like the input of a step. It defaults to the output of the
+	// last step.
+	Output any `yaml:"output,omitempty" json:"output,omitempty"`
+	// Extensions holds the x- fields of the tool.
+	Extensions Extensions `yaml:"-" json:"-"`
+}
+
+// IsComposite reports whether the tool is a composite tool, made of steps.
+func (t Tool) IsComposite() bool {
+	return len(t.Steps) > 0
+}
+
+// ToolStep is a step of a composite tool, calling another tool of the spec.
+type ToolStep struct {
+	// ID names the output of the step for the following steps. It defaults
+	// to the name of the tool.
+	ID   string `yaml:"id,omitempty" json:"id,omitempty"`
+	Tool string `yaml:"tool" json:"tool"`
+	// Input maps the input of the composite tool and the outputs of the
+	// previous steps to the input of the tool: its strings $input and
+	// $steps.<id>, followed by a property path such as $input.user.name,
+	// select a value, and its other values are copied. It defaults to
+	// $input.
+	Input any `yaml:"input,omitempty" json:"input,omitempty"`
+}
+
+// StepID returns the ID of the step, defaulting to its tool name.
+func (s ToolStep) StepID() string {
+	if s.ID != "" {
+		return s.ID
+	}
+	return s.Tool
+}
+
+// TakesNoInput reports whether the tool takes no arguments, either with
+// noInput or with an empty input schema.
+func (t Tool) TakesNoInput() bool {
+	return t.NoInput || isEmptySchema(t.InputSchema)
+}
+
+func isEmptySchema(s *Schema) bool {
+	return s != nil && reflect.ValueOf(*s).IsZero()
 }
 
 type Resource struct {
 	URI         string            `yaml:"uri,omitempty" json:"uri,omitempty"`
 	Name        string            `yaml:"name" json:"name"`
+	Title       string            `yaml:"title,omitempty" json:"title,omitempty"`
+	Icon        string            `yaml:"icon,omitempty" json:"icon,omitempty"`
 	Description string            `yaml:"description,omitempty" json:"description,omitempty"`
 	MimeType    string            `yaml:"mimeType,omitempty" json:"mimeType,omitempty"`
 	URITemplate string            `yaml:"uriTemplate,omitempty" json:"uriTemplate,omitempty"`
@@ -87,14 +334,58 @@ type Resource struct {
 	Readonly    bool              `yaml:"readonly,omitempty" json:"readonly,omitempty"`
 	Annotations map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
 	Handler     string            `yaml:"handler,omitempty" json:"handler,omitempty"`
+	// Group names the sub-resolver implementing the handler of the resource,
+	// generated in <group>.resolvers.go, to split the handlers of large
+	// servers by domain.
+	Group string `yaml:"group,omitempty" json:"group,omitempty"`
+	// Encoding names the serializer encoding the contents of the resource,
+	// such as cbor, registered with mcputil.RegisterSerializer.
+	Encoding string `yaml:"encoding,omitempty" json:"encoding,omitempty"`
+	// RequiresClientCapability hides the resource from the clients not
+	// declaring these capabilities.
+	RequiresClientCapability []string `yaml:"requiresClientCapability,omitempty" json:"requiresClientCapability,omitempty"`
+	// DevFixture is the content of the resource served, in development,
+	// while its handler is not implemented.
+	DevFixture any `yaml:"devFixture,omitempty" json:"devFixture,omitempty"`
+	// Extensions holds the x- fields of the resource.
+	Extensions Extensions `yaml:"-" json:"-"`
 }
 
 type Prompt struct {
 	Name        string            `yaml:"name" json:"name"`
+	Title       string            `yaml:"title,omitempty" json:"title,omitempty"`
+	Icon        string            `yaml:"icon,omitempty" json:"icon,omitempty"`
 	Description string            `yaml:"description,omitempty" json:"description,omitempty"`
 	Arguments   []PromptArgument  `yaml:"arguments,omitempty" json:"arguments,omitempty"`
 	Annotations map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
 	Handler     string            `yaml:"handler,omitempty" json:"handler,omitempty"`
+	// Group names the sub-resolver implementing the handler of the prompt,
+	// generated in <group>.resolvers.go, to split the handlers of large
+	// servers by domain.
+	Group string `yaml:"group,omitempty" json:"group,omitempty"`
+	// RequiresClientCapability hides the prompt from the clients not
+	// declaring these capabilities.
+	RequiresClientCapability []string `yaml:"requiresClientCapability,omitempty" json:"requiresClientCapability,omitempty"`
+	// Extensions holds the x- fields of the prompt.
+	Extensions Extensions `yaml:"-" json:"-"`
+}
+
+// Experiment is a named flag gating tools, enabled per deployment with the
+// generated server options.
+type Experiment struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+}
+
+// EnvVar is an environment variable the server reads, which the generated
+// Run function checks before serving and lists with -print-env.
+type EnvVar struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Required makes the server refuse to start while the variable is unset.
+	Required bool `yaml:"required,omitempty" json:"required,omitempty"`
+	// Default is the value of the variable while it is unset.
+	Default string `yaml:"default,omitempty" json:"default,omitempty"`
 }
 
 type PromptArgument struct {
@@ -103,10 +394,81 @@ type PromptArgument struct {
 	Required    bool   `yaml:"required,omitempty" json:"required,omitempty"`
 }
 
-func Load(path string) (*Config, *MCPSpec, error) {
+// LoadOption configures Load.
+type LoadOption func(*loadOptions)
+
+type loadOptions struct {
+	frozen   bool
+	readOnly bool
+}
+
+// WithFrozen forbids network access when resolving the remote $ref of the
+// spec: their documents must be recorded in mcpgen.lock and cached, as they
+// are by a previous load. Meant for CI.
+func WithFrozen(frozen bool) LoadOption {
+	return func(o *loadOptions) {
+		o.frozen = frozen
+	}
+}
+
+// WithReadOnly loads the spec without writing mcpgen.lock or the cache of
+// the remote $ref documents: documents missing from the cache are fetched
+// but not kept, and a lockfile missing some of them is reported as out of
+// date instead of being updated. Meant for commands checking the spec or
+// the generated code.
+func WithReadOnly(readOnly bool) LoadOption {
+	return func(o *loadOptions) {
+		o.readOnly = readOnly
+	}
+}
+
+// Load reads the configuration file at path and the spec it points to.
+// Remote $ref documents are pinned in mcpgen.lock and cached in .mcpgen/refs,
+// next to the configuration file.
+func Load(path string, opts ...LoadOption) (*Config, *MCPSpec, error) {
+	config, err := LoadConfig(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var o loadOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	remote := newRemoteRefs(config.dir, o.frozen, o.readOnly)
+	spec, err := loadMCPSpec(config.SpecPath, config.IncludePaths, remote, config.Model.PropertyOrder == PropertyOrderSpec)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load MCP spec from %s: %w", config.SpecPath, err)
+	}
+
+	return config, spec, nil
+}
+
+// LoadSpec loads the spec at path as Load loads the spec of config: with the
+// files config includes, its remote $ref documents and its property order.
+// It loads another version of the spec, such as the one of a previous
+// release, so that it compares with the spec of config. Its remote $ref
+// documents are read from the cache when it holds them, and fetched
+// otherwise, but neither mcpgen.lock nor the cache is written: they pin the
+// documents of the spec of config only.
+func LoadSpec(config *Config, path string, opts ...LoadOption) (*MCPSpec, error) {
+	var o loadOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	remote := newRemoteRefs(config.dir, o.frozen, o.readOnly)
+	remote.discard = true
+	return loadMCPSpec(path, config.IncludePaths, remote, config.Model.PropertyOrder == PropertyOrderSpec)
+}
+
+// LoadConfig reads the configuration file at path without loading the spec
+// it points to.
+func LoadConfig(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
 	config := &Config{
@@ -132,25 +494,34 @@ func Load(path string) (*Config, *MCPSpec, error) {
 	switch ext {
 	case ".yaml", ".yml":
 		if err := yaml.Unmarshal(data, config); err != nil {
-			return nil, nil, fmt.Errorf("failed to parse YAML config: %w", err)
+			return nil, fmt.Errorf("failed to parse YAML config: %w", err)
 		}
 	case ".json":
 		if err := json.Unmarshal(data, config); err != nil {
-			return nil, nil, fmt.Errorf("failed to parse JSON config: %w", err)
+			return nil, fmt.Errorf("failed to parse JSON config: %w", err)
 		}
 	default:
-		return nil, nil, fmt.Errorf("unsupported config file format: %s (use .yaml, .yml, or .json)", ext)
+		return nil, fmt.Errorf("unsupported config file format: %s (use .yaml, .yml, or .json)", ext)
 	}
 
 	if err := config.Validate(); err != nil {
-		return nil, nil, fmt.Errorf("invalid configuration: %w", err)
+		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
 
 	// Make output path absolute relative to config file directory
 	configDir := filepath.Dir(path)
+	config.dir = configDir
 	if !filepath.IsAbs(config.Output) {
 		config.Output = filepath.Join(configDir, config.Output)
 	}
+	if config.Templates != "" {
+		if !filepath.IsAbs(config.Templates) {
+			config.Templates = filepath.Join(configDir, config.Templates)
+		}
+		if info, err := os.Stat(config.Templates); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("invalid configuration: templates: %s is not a directory", config.Templates)
+		}
+	}
 
 	specPath := config.Spec
 	if !filepath.IsAbs(specPath) {
@@ -174,32 +545,102 @@ func Load(path string) (*Config, *MCPSpec, error) {
 		}
 	}
 
-	spec, err := LoadMCPSpec(specPath)
+	config.SpecPath = specPath
+
+	includePaths, err := globIncludes(configDir, config.Include, specPath)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to load MCP spec from %s: %w", specPath, err)
+		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
+	config.IncludePaths = includePaths
 
-	return config, spec, nil
+	return config, nil
+}
+
+// SpecFiles returns the paths of the spec file and of the files of Include.
+// The fragments the spec files include themselves are listed by the Files of
+// the loaded spec.
+func (c *Config) SpecFiles() []string {
+	return append([]string{c.SpecPath}, c.IncludePaths...)
+}
+
+// globIncludes returns the files matching the include patterns, relative to
+// dir, sorted and without duplicates or the spec file itself.
+func globIncludes(dir string, patterns []string, specPath string) ([]string, error) {
+	seen := map[string]bool{filepath.Clean(specPath): true}
+	var paths []string
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(dir, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("include pattern %s: %w", pattern, err)
+		}
+		for _, match := range matches {
+			if !seen[match] {
+				seen[match] = true
+				paths = append(paths, match)
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
 }
 
 func (c *Config) Validate() error {
+	errs := &ValidationError{}
+
 	if c.Spec == "" {
-		return fmt.Errorf("spec path is required")
+		errs.add("spec path is required")
 	}
 	if c.Output == "" {
-		return fmt.Errorf("output is required")
+		errs.add("output is required")
 	}
 	if c.Exec.Package == "" {
-		return fmt.Errorf("exec.package is required")
+		errs.add("exec.package is required")
 	}
 	if c.Resolver.Package == "" {
-		return fmt.Errorf("resolver.package is required")
+		errs.add("resolver.package is required")
 	}
 	if c.Model.Package == "" {
-		return fmt.Errorf("model.package is required")
+		errs.add("model.package is required")
+	}
+	if c.Exec.SlowCallThreshold != "" {
+		if d, err := time.ParseDuration(c.Exec.SlowCallThreshold); err != nil || d <= 0 {
+			errs.add("exec.slow_call_threshold must be a positive duration, such as 2s")
+		}
+	}
+	if c.Model.OptionalStyle != "" && !IsOptionalStyle(c.Model.OptionalStyle) {
+		errs.add("model.optional_style must be pointer, omittable or value")
+	}
+	if c.Model.Layout != "" && c.Model.Layout != ModelLayoutSingle && c.Model.Layout != ModelLayoutPerSchema && c.Model.Layout != ModelLayoutPerType {
+		errs.add("model.layout must be single, per-schema or per-type")
+	}
+	if c.Model.PropertyOrder != "" && c.Model.PropertyOrder != PropertyOrderAlphabetical && c.Model.PropertyOrder != PropertyOrderSpec {
+		errs.add("model.property_order must be alphabetical or spec")
+	}
+	formats := make([]string, 0, len(c.Formats))
+	for format := range c.Formats {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	for _, format := range formats {
+		if c.Formats[format] == "" {
+			errs.add("formats.%s must name a Go type, such as github.com/google/uuid.UUID", format)
+		}
+	}
+	for i, pkg := range c.Autobind {
+		if pkg == "" || strings.ContainsAny(pkg, " \t") {
+			errs.add("autobind[%d] must be a Go import path, such as github.com/myorg/app/models", i)
+		}
+	}
+	if c.Header.BuildTags != "" {
+		if _, err := constraint.Parse("//go:build " + c.Header.BuildTags); err != nil {
+			errs.add("header.build_tags must be a build constraint, such as linux && !tinygo")
+		}
 	}
 
-	return nil
+	return errs.err()
 }
 
 func IsSchemaRef(s *Schema) bool {
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// hoistDefs moves the $defs of the component, tool and resource schemas of
+// a normalized spec document to the component schemas, and points the
+// #/$defs/ references within each schema to them, so that they generate
+// named types. Definitions keep their name, with a number appended when it
+// is already used. Schemas declaring the same definitions, as the copies of
+// a schema made by YAML aliases do, share the hoisted component schemas.
+func hoistDefs(doc map[string]interface{}) {
+	schemas := componentSchemas(doc)
+	taken := map[string]bool{}
+	for name := range schemas {
+		taken[name] = true
+	}
+
+	type hoisted struct {
+		defs    interface{}
+		renames map[string]string
+	}
+	var seen []hoisted
+
+	hoist := func(s interface{}) {
+		root, ok := s.(map[string]interface{})
+		if !ok {
+			return
+		}
+		defs, ok := root["$defs"].(map[string]interface{})
+		if !ok {
+			return
+		}
+		delete(root, "$defs")
+
+		for _, h := range seen {
+			if reflect.DeepEqual(h.defs, defs) {
+				rewriteDefRefs(root, h.renames)
+				return
+			}
+		}
+		original := deepCopy(defs)
+
+		if schemas == nil {
+			components, ok := doc["components"].(map[string]interface{})
+			if !ok {
+				components = map[string]interface{}{}
+				doc["components"] = components
+			}
+			schemas = map[string]interface{}{}
+			components["schemas"] = schemas
+		}
+
+		renames := map[string]string{}
+		for _, name := range sortedKeys(defs) {
+			unique := name
+			for i := 2; taken[unique]; i++ {
+				unique = name + strconv.Itoa(i)
+			}
+			taken[unique] = true
+			renames[name] = unique
+			schemas[unique] = defs[name]
+		}
+
+		rewriteDefRefs(root, renames)
+		for _, name := range sortedKeys(defs) {
+			rewriteDefRefs(defs[name], renames)
+		}
+		seen = append(seen, hoisted{defs: original, renames: renames})
+	}
+
+	for _, name := range sortedKeys(schemas) {
+		hoist(schemas[name])
+	}
+	for _, section := range []string{"tools", "resources"} {
+		items, _ := doc[section].([]interface{})
+		for _, item := range items {
+			entry, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			for _, key := range []string{"inputSchema", "outputSchema", "schema"} {
+				hoist(entry[key])
+			}
+		}
+	}
+}
+
+// rewriteDefRefs points the #/$defs/ references of s to the component
+// schemas the definitions were moved to.
+func rewriteDefRefs(s interface{}, renames map[string]string) {
+	switch v := s.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			switch key {
+			case "$ref":
+				ref, _ := value.(string)
+				rest, ok := strings.CutPrefix(ref, "#/$defs/")
+				if !ok {
+					continue
+				}
+				name, tail, _ := strings.Cut(rest, "/")
+				name = unescapePointer(name)
+				renamed, ok := renames[name]
+				if !ok {
+					continue
+				}
+				target := "#/components/schemas/" + renamed
+				if tail != "" {
+					target += "/" + tail
+				}
+				v[key] = target
+			case "enum", "const", "default", "examples":
+				// Values, not schemas
+			default:
+				rewriteDefRefs(value, renames)
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			rewriteDefRefs(item, renames)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMCPSpecHoistsDefs(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"schema.yaml": `info:
+  title: tasks
+  version: 1.0.0
+components:
+  schemas:
+    Status:
+      type: string
+tools:
+  - name: create_task
+    inputSchema:
+      type: object
+      properties:
+        status:
+          $ref: '#/$defs/Status'
+        owner:
+          $ref: '#/$defs/User/properties/name'
+      $defs:
+        Status:
+          type: string
+          enum: [open, done]
+        User:
+          type: object
+          properties:
+            name:
+              type: string
+            status:
+              $ref: '#/$defs/Status'
+`,
+	})
+
+	spec, err := LoadMCPSpec(filepath.Join(dir, "schema.yaml"))
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"type": "object",
+		"properties": {
+			"status": {"$ref": "#/components/schemas/Status2"},
+			"owner": {"$ref": "#/components/schemas/User/properties/name"}
+		}
+	}`, schemaJSON(t, spec.Tools[0].InputSchema))
+	assert.JSONEq(t, `{"type": "string", "enum": ["open", "done"]}`, schemaJSON(t, spec.Components.Schemas["Status2"]))
+	assert.Equal(t, "#/components/schemas/Status2", spec.Components.Schemas["User"].Properties["status"].Ref)
+	assert.Equal(t, "string", spec.Components.Schemas["Status"].Type)
+}
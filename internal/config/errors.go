@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValidationError holds every problem found while validating a configuration
+// or spec, so they can all be fixed in one pass.
+type ValidationError struct {
+	Errors []error
+}
+
+func (e *ValidationError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d problems:", len(e.Errors))
+	for _, err := range e.Errors {
+		b.WriteString("\n  - ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+func (e *ValidationError) Unwrap() []error {
+	return e.Errors
+}
+
+func (e *ValidationError) add(format string, args ...any) {
+	e.Errors = append(e.Errors, fmt.Errorf(format, args...))
+}
+
+// err returns e when problems were found and nil otherwise.
+func (e *ValidationError) err() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+// entryPath names the i-th entry of a spec section, with its name when set,
+// as in tools[2] (create_event).
+func entryPath(section string, i int, name string) string {
+	if name == "" {
+		return fmt.Sprintf("%s[%d]", section, i)
+	}
+	return fmt.Sprintf("%s[%d] (%s)", section, i, name)
+}
@@ -0,0 +1,132 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpecValidateReportsAllProblems(t *testing.T) {
+	spec := &MCPSpec{
+		Info: ServerInfo{Title: "test"},
+		Tools: []Tool{
+			{Name: "ok", InputSchema: &Schema{Type: "object"}},
+			{Name: "broken"},
+			{InputSchema: &Schema{Type: "object"}},
+			{Name: "ping", NoInput: true},
+			{Name: "status", InputSchema: &Schema{}},
+			{Name: "conflict", NoInput: true, InputSchema: &Schema{Type: "object"}},
+			{Name: "summarize", NoInput: true, RequiresClientCapability: []string{"sampling", "experimental.drafts", "roots"}},
+			{Name: "export", NoInput: true, Experiment: "bulk_export"},
+			{Name: "archive", NoInput: true, Experiment: "archiving"},
+			{Name: "find", NoInput: true, ReplacedBy: "search"},
+			{Name: "lookup", NoInput: true, Deprecated: true, ReplacedBy: "search"},
+			{Name: "query", NoInput: true, Deprecated: true, ReplacedBy: "ping"},
+			{Name: "get", NoInput: true, OutputSchema: &Schema{Type: "object"}, DevFixture: []any{"a"}},
+			{Name: "echo", NoInput: true, DevFixture: "hello"},
+			{Name: "bill", NoInput: true, Group: "billing"},
+			{Name: "refund", NoInput: true, Group: "billing.refunds"},
+		},
+		Resources: []Resource{
+			{Name: "both", URI: "file:///a", URITemplate: "file:///{id}"},
+			{Name: "blob", URI: "file:///b", Encoding: "application/cbor"},
+		},
+		Prompts:     []Prompt{{}},
+		Experiments: []Experiment{{Name: "bulk_export"}, {Name: "bulk_export"}, {Name: "2fa"}},
+		Env:         []EnvVar{{Name: "API_TOKEN", Required: true}, {Name: "API_TOKEN"}, {Name: "API-URL"}, {}, {Name: "REGION", Required: true, Default: "eu"}},
+	}
+
+	err := spec.Validate()
+	require.Error(t, err)
+
+	var validationErr *ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, `19 problems:
+  - info.version is required
+  - experiments[1] (bulk_export) is already declared
+  - experiments[2] (2fa).name must start with a letter and contain only letters, digits, - and _
+  - env[1] (API_TOKEN) is already declared
+  - env[2] (API-URL).name must start with a letter or _ and contain only letters, digits and _
+  - env[3].name is required
+  - env[4] (REGION) cannot be both required and have a default
+  - tools[1] (broken).inputSchema is required
+  - tools[2].name is required
+  - tools[5] (conflict) cannot have both noInput and inputSchema
+  - tools[6] (summarize).requiresClientCapability: unknown capability "roots", want sampling, elicitation or experimental.<name>
+  - tools[8] (archive).experiment: unknown experiment "archiving", declare it in experiments
+  - tools[9] (find).replacedBy requires deprecated: true
+  - tools[10] (lookup).replacedBy: unknown tool "search"
+  - tools[12] (get).devFixture must be an object, as the tool has an outputSchema
+  - tools[15] (refund).group must start with a letter and contain only letters, digits, - and _
+  - resources[0] (both) cannot have both uri and uriTemplate
+  - resources[1] (blob).encoding must start with a letter and contain only letters, digits, - and _
+  - prompts[0].name is required`, err.Error())
+}
+
+func TestToolTakesNoInput(t *testing.T) {
+	assert.True(t, Tool{NoInput: true}.TakesNoInput())
+	assert.True(t, Tool{InputSchema: &Schema{}}.TakesNoInput())
+	assert.False(t, Tool{InputSchema: &Schema{Type: "object"}}.TakesNoInput())
+	assert.False(t, Tool{}.TakesNoInput())
+}
+
+func TestConfigValidate(t *testing.T) {
+	err := (&Config{Spec: "mcp.yaml", Output: "generated"}).Validate()
+	require.Error(t, err)
+	assert.Equal(t, `3 problems:
+  - exec.package is required
+  - resolver.package is required
+  - model.package is required`, err.Error())
+
+	err = (&Config{Spec: "mcp.yaml", Output: "generated", Exec: ExecConfig{Package: "server"}, Resolver: ResolverConfig{Package: "server"}}).Validate()
+	require.Error(t, err)
+	assert.Equal(t, "model.package is required", err.Error())
+
+	valid := &Config{
+		Spec:     "mcp.yaml",
+		Output:   "generated",
+		Exec:     ExecConfig{Package: "server"},
+		Resolver: ResolverConfig{Package: "server"},
+		Model:    ModelConfig{Package: "server"},
+	}
+	assert.NoError(t, valid.Validate())
+
+	valid.Exec.SlowCallThreshold = "2s"
+	assert.NoError(t, valid.Validate())
+
+	valid.Exec.SlowCallThreshold = "2"
+	assert.EqualError(t, valid.Validate(), "exec.slow_call_threshold must be a positive duration, such as 2s")
+	valid.Exec.SlowCallThreshold = ""
+
+	valid.Model.OptionalStyle = OptionalStyleOmittable
+	assert.NoError(t, valid.Validate())
+
+	valid.Model.OptionalStyle = "nullable"
+	assert.EqualError(t, valid.Validate(), "model.optional_style must be pointer, omittable or value")
+	valid.Model.OptionalStyle = ""
+
+	valid.Model.Layout = ModelLayoutPerSchema
+	assert.NoError(t, valid.Validate())
+	valid.Model.Layout = ModelLayoutPerType
+	assert.NoError(t, valid.Validate())
+
+	valid.Model.Layout = "per-file"
+	assert.EqualError(t, valid.Validate(), "model.layout must be single, per-schema or per-type")
+	valid.Model.Layout = ""
+
+	valid.Formats = map[string]string{"uuid": "github.com/google/uuid.UUID", "date": ""}
+	assert.EqualError(t, valid.Validate(), "formats.date must name a Go type, such as github.com/google/uuid.UUID")
+	valid.Formats = nil
+
+	valid.Autobind = []string{"github.com/myorg/app/models", ""}
+	assert.EqualError(t, valid.Validate(), "autobind[1] must be a Go import path, such as github.com/myorg/app/models")
+	valid.Autobind = nil
+
+	valid.Header.BuildTags = "linux && !tinygo"
+	assert.NoError(t, valid.Validate())
+
+	valid.Header.BuildTags = "linux &&"
+	assert.EqualError(t, valid.Validate(), "header.build_tags must be a build constraint, such as linux && !tinygo")
+}
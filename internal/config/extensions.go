@@ -0,0 +1,110 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// Extensions holds the x- fields of a tool, resource or prompt, such as
+// x-owner: billing. mcpgen does not interpret them but keeps them when
+// loading and writing specs, and passes them to the templates, so that
+// custom generators can act on organization-specific annotations.
+type Extensions map[string]any
+
+func (t *Tool) UnmarshalJSON(data []byte) error {
+	type plain Tool
+	return unmarshalWithExtensions(data, (*plain)(t), &t.Extensions)
+}
+
+func (t Tool) MarshalJSON() ([]byte, error) {
+	type plain Tool
+	return marshalWithExtensions(plain(t), t.Extensions)
+}
+
+func (r *Resource) UnmarshalJSON(data []byte) error {
+	type plain Resource
+	return unmarshalWithExtensions(data, (*plain)(r), &r.Extensions)
+}
+
+func (r Resource) MarshalJSON() ([]byte, error) {
+	type plain Resource
+	return marshalWithExtensions(plain(r), r.Extensions)
+}
+
+func (p *Prompt) UnmarshalJSON(data []byte) error {
+	type plain Prompt
+	return unmarshalWithExtensions(data, (*plain)(p), &p.Extensions)
+}
+
+func (p Prompt) MarshalJSON() ([]byte, error) {
+	type plain Prompt
+	return marshalWithExtensions(plain(p), p.Extensions)
+}
+
+// unmarshalWithExtensions decodes data into v and its x- fields into
+// extensions. Numbers are kept as json.Number so that they are written back
+// unchanged.
+func unmarshalWithExtensions(data []byte, v any, extensions *Extensions) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	*extensions = nil
+	for key, raw := range fields {
+		if !strings.HasPrefix(key, "x-") {
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		var value any
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+		if *extensions == nil {
+			*extensions = Extensions{}
+		}
+		(*extensions)[key] = value
+	}
+	return nil
+}
+
+// marshalWithExtensions encodes v followed by its extensions, sorted by key.
+func marshalWithExtensions(v any, extensions Extensions) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extensions) == 0 {
+		return data, err
+	}
+
+	keys := make([]string, 0, len(extensions))
+	for key := range extensions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	buf := bytes.NewBuffer(data[:len(data)-1])
+	for _, key := range keys {
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(extensions[key])
+		if err != nil {
+			return nil, err
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMCPSpecExtensions(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"schema.yaml": `info:
+  title: billing
+  version: 1.0.0
+tools:
+  - name: refund
+    x-owner: payments
+    x-rate-limit:
+      requests: 10
+      per: minute
+    inputSchema:
+      type: object
+resources:
+  - name: invoices
+    uri: billing://invoices
+    x-cache-ttl: 300
+prompts:
+  - name: dispute
+    x-audience: [support]
+`,
+	})
+
+	spec, err := LoadMCPSpec(filepath.Join(dir, "schema.yaml"))
+	require.NoError(t, err)
+
+	assert.Equal(t, Extensions{
+		"x-owner":      "payments",
+		"x-rate-limit": map[string]any{"requests": json.Number("10"), "per": "minute"},
+	}, spec.Tools[0].Extensions)
+	assert.Equal(t, Extensions{"x-cache-ttl": json.Number("300")}, spec.Resources[0].Extensions)
+	assert.Equal(t, Extensions{"x-audience": []any{"support"}}, spec.Prompts[0].Extensions)
+
+	data, err := EncodeSpec(spec, ".yaml")
+	require.NoError(t, err)
+	assert.Equal(t, `info:
+  title: billing
+  version: 1.0.0
+components: {}
+tools:
+  - name: refund
+    inputSchema:
+      type: object
+    x-owner: payments
+    x-rate-limit:
+      per: minute
+      requests: 10
+resources:
+  - name: invoices
+    uri: billing://invoices
+    x-cache-ttl: 300
+prompts:
+  - name: dispute
+    x-audience:
+      - support
+`, string(data))
+}
+
+func TestExtensionsOmittedWhenEmpty(t *testing.T) {
+	data, err := json.Marshal(Tool{Name: "ping", NoInput: true})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name": "ping", "noInput": true}`, string(data))
+
+	var tool Tool
+	require.NoError(t, json.Unmarshal([]byte(`{"name": "ping", "handler": "Ping"}`), &tool))
+	assert.Nil(t, tool.Extensions)
+	assert.Equal(t, "Ping", tool.Handler)
+}
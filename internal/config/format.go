@@ -0,0 +1,376 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Canonical key order of the spec sections. Keys not listed keep their
+// relative order after the listed ones.
+var (
+	specKeyOrder           = []string{"include", "info", "env", "components", "experiments", "tools", "resources", "prompts"}
+	infoKeyOrder           = []string{"title", "version", "description"}
+	toolKeyOrder           = []string{"name", "title", "icon", "description", "hints", "annotations", "requiresClientCapability", "experiment", "deprecated", "replacedBy", "group", "handler", "inputSchema", "outputSchema", "steps", "output", "devFixture"}
+	resourceKeyOrder       = []string{"name", "title", "icon", "description", "uri", "uriTemplate", "mimeType", "encoding", "readonly", "annotations", "requiresClientCapability", "group", "handler", "schema", "devFixture"}
+	promptKeyOrder         = []string{"name", "title", "icon", "description", "annotations", "requiresClientCapability", "group", "handler", "arguments"}
+	promptArgumentKeyOrder = []string{"name", "description", "required"}
+	experimentKeyOrder     = []string{"name", "description"}
+	envKeyOrder            = []string{"name", "description", "required", "default"}
+)
+
+// FormatSpec normalizes the layout of an MCP spec file: top-level sections
+// and entries use a canonical key order, component schemas are sorted by
+// name, and the document is re-indented with two spaces. YAML comments are
+// preserved, and so are the documents of multi-document files. ext selects
+// the output format (".yaml", ".yml" or ".json").
+func FormatSpec(data []byte, ext string) ([]byte, error) {
+	docs, err := parseSpecNodes(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse spec: %w", err)
+	}
+
+	if len(docs) == 0 {
+		return data, nil
+	}
+
+	for _, doc := range docs {
+		root := specRoot(doc)
+		if root == nil {
+			continue
+		}
+		if root.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("spec must be a mapping")
+		}
+
+		normalizeSpec(root)
+		reanchor(root, map[string]bool{})
+	}
+
+	return encodeSpec(docs, ext)
+}
+
+// parseSpecNodes parses the documents of a spec file, which YAML files may
+// separate with ---.
+func parseSpecNodes(data []byte) ([]*yaml.Node, error) {
+	var docs []*yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		doc := &yaml.Node{}
+		if err := dec.Decode(doc); err == io.EOF {
+			return docs, nil
+		} else if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+}
+
+// specRoot returns the root node of a parsed spec document, or nil when the
+// document is empty.
+func specRoot(doc *yaml.Node) *yaml.Node {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+	root := doc.Content[0]
+	if root.Kind == yaml.ScalarNode && root.Tag == "!!null" {
+		return nil
+	}
+	return root
+}
+
+// EncodeSpec writes spec in the format selected by ext (".yaml", ".yml" or
+// ".json"), laid out as FormatSpec would.
+func EncodeSpec(spec *MCPSpec, ext string) ([]byte, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal spec: %w", err)
+	}
+
+	// JSON is valid YAML: decoding into a node keeps the key order
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to convert spec: %w", err)
+	}
+	blockStyle(&doc)
+	normalizeSpec(doc.Content[0])
+
+	return encodeSpec([]*yaml.Node{&doc}, ext)
+}
+
+// blockStyle switches nodes decoded from JSON to block style, keeping quotes
+// only where a string would otherwise be read as another type.
+func blockStyle(n *yaml.Node) {
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!str" {
+		n.Style = 0
+	} else {
+		n.Style &^= yaml.FlowStyle | yaml.DoubleQuotedStyle
+	}
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
+
+// reanchor keeps the YAML anchors of a reordered document ahead of their
+// aliases: when an alias comes first, it takes the anchored node and the
+// node is replaced by an alias. The tag of merge keys is cleared, since the
+// encoder would otherwise write them as !!merge <<.
+func reanchor(n *yaml.Node, anchors map[string]bool) {
+	switch {
+	case n.Kind == yaml.AliasNode && !anchors[n.Value] && n.Alias != nil:
+		target := n.Alias
+		*n = *target
+		*target = yaml.Node{Kind: yaml.AliasNode, Value: n.Anchor, Alias: n}
+	case n.Kind == yaml.ScalarNode && n.Tag == "!!merge":
+		n.Tag = ""
+	}
+	if n.Anchor != "" {
+		anchors[n.Anchor] = true
+	}
+	for _, c := range n.Content {
+		reanchor(c, anchors)
+	}
+}
+
+// encodeSpec writes parsed spec documents back in the format selected by
+// ext. JSON files hold a single document.
+func encodeSpec(docs []*yaml.Node, ext string) ([]byte, error) {
+	switch ext {
+	case ".yaml", ".yml":
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		for _, doc := range docs {
+			if err := enc.Encode(doc); err != nil {
+				return nil, fmt.Errorf("failed to encode YAML spec: %w", err)
+			}
+		}
+		if err := enc.Close(); err != nil {
+			return nil, fmt.Errorf("failed to encode YAML spec: %w", err)
+		}
+		return buf.Bytes(), nil
+	case ".json":
+		if len(docs) != 1 {
+			return nil, fmt.Errorf("a JSON spec holds a single document, found %d", len(docs))
+		}
+		var compact bytes.Buffer
+		if err := writeJSON(&compact, docs[0]); err != nil {
+			return nil, fmt.Errorf("failed to encode JSON spec: %w", err)
+		}
+		var out bytes.Buffer
+		if err := json.Indent(&out, compact.Bytes(), "", "  "); err != nil {
+			return nil, fmt.Errorf("failed to indent JSON spec: %w", err)
+		}
+		out.WriteByte('\n')
+		return out.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("unsupported spec file format: %s (use .yaml, .yml, or .json)", ext)
+	}
+}
+
+func normalizeSpec(root *yaml.Node) {
+	orderMapping(root, specKeyOrder)
+
+	if info := mappingValue(root, "info"); info != nil {
+		orderMapping(info, infoKeyOrder)
+	}
+
+	if components := mappingValue(root, "components"); components != nil {
+		if schemas := mappingValue(components, "schemas"); schemas != nil {
+			sortMapping(schemas)
+		}
+	}
+
+	orderEntries(mappingValue(root, "tools"), toolKeyOrder)
+	orderEntries(mappingValue(root, "resources"), resourceKeyOrder)
+	orderEntries(mappingValue(root, "prompts"), promptKeyOrder)
+	orderEntries(mappingValue(root, "experiments"), experimentKeyOrder)
+	orderEntries(mappingValue(root, "env"), envKeyOrder)
+
+	if prompts := mappingValue(root, "prompts"); prompts != nil && prompts.Kind == yaml.SequenceNode {
+		for _, prompt := range prompts.Content {
+			orderEntries(mappingValue(prompt, "arguments"), promptArgumentKeyOrder)
+		}
+	}
+}
+
+// orderEntries applies orderMapping to every mapping of a sequence node.
+func orderEntries(seq *yaml.Node, order []string) {
+	if seq == nil || seq.Kind != yaml.SequenceNode {
+		return
+	}
+	for _, entry := range seq.Content {
+		orderMapping(entry, order)
+	}
+}
+
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+type mappingPair struct {
+	key   *yaml.Node
+	value *yaml.Node
+}
+
+func mappingPairs(n *yaml.Node) []mappingPair {
+	pairs := make([]mappingPair, 0, len(n.Content)/2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		pairs = append(pairs, mappingPair{key: n.Content[i], value: n.Content[i+1]})
+	}
+	return pairs
+}
+
+// mergedPairs returns the pairs of a mapping with its << merge keys replaced
+// by the pairs of the merged mappings. Keys set by the mapping itself win,
+// then the first merged mapping setting a key.
+func mergedPairs(n *yaml.Node) []mappingPair {
+	var own, merged []mappingPair
+	for _, p := range mappingPairs(n) {
+		if !isMergeKey(p.key) {
+			own = append(own, p)
+			continue
+		}
+		sources := []*yaml.Node{p.value}
+		if p.value.Kind == yaml.SequenceNode {
+			sources = p.value.Content
+		}
+		for _, source := range sources {
+			for source.Kind == yaml.AliasNode && source.Alias != nil {
+				source = source.Alias
+			}
+			if source.Kind == yaml.MappingNode {
+				merged = append(merged, mergedPairs(source)...)
+			}
+		}
+	}
+
+	set := make(map[string]bool, len(own))
+	for _, p := range own {
+		set[p.key.Value] = true
+	}
+	pairs := own
+	for _, p := range merged {
+		if !set[p.key.Value] {
+			set[p.key.Value] = true
+			pairs = append(pairs, p)
+		}
+	}
+	return pairs
+}
+
+// isMergeKey reports whether the key of a mapping pair is a << merge key,
+// whose pairs are those of the merged mappings.
+func isMergeKey(key *yaml.Node) bool {
+	return key.Tag == "!!merge" || key.Tag == "" && key.Value == "<<"
+}
+
+func setMappingPairs(n *yaml.Node, pairs []mappingPair) {
+	n.Content = n.Content[:0]
+	for _, p := range pairs {
+		n.Content = append(n.Content, p.key, p.value)
+	}
+}
+
+// orderMapping puts the keys listed in order first, keeping the remaining
+// keys in their original relative order.
+func orderMapping(n *yaml.Node, order []string) {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return
+	}
+
+	rank := make(map[string]int, len(order))
+	for i, key := range order {
+		rank[key] = i
+	}
+
+	pairs := mappingPairs(n)
+	sort.SliceStable(pairs, func(i, j int) bool {
+		ri, iKnown := rank[pairs[i].key.Value]
+		rj, jKnown := rank[pairs[j].key.Value]
+		switch {
+		case iKnown && jKnown:
+			return ri < rj
+		default:
+			return iKnown && !jKnown
+		}
+	})
+	setMappingPairs(n, pairs)
+}
+
+func sortMapping(n *yaml.Node) {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return
+	}
+
+	pairs := mappingPairs(n)
+	sort.SliceStable(pairs, func(i, j int) bool {
+		return pairs[i].key.Value < pairs[j].key.Value
+	})
+	setMappingPairs(n, pairs)
+}
+
+// writeJSON encodes a YAML node as compact JSON, keeping mapping key order.
+func writeJSON(buf *bytes.Buffer, n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			buf.WriteString("null")
+			return nil
+		}
+		return writeJSON(buf, n.Content[0])
+	case yaml.AliasNode:
+		return writeJSON(buf, n.Alias)
+	case yaml.MappingNode:
+		buf.WriteByte('{')
+		for i, p := range mergedPairs(n) {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			key, err := json.Marshal(p.key.Value)
+			if err != nil {
+				return err
+			}
+			buf.Write(key)
+			buf.WriteByte(':')
+			if err := writeJSON(buf, p.value); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case yaml.SequenceNode:
+		buf.WriteByte('[')
+		for i, item := range n.Content {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSON(buf, item); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case yaml.ScalarNode:
+		var value interface{}
+		if err := n.Decode(&value); err != nil {
+			return err
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		buf.Write(encoded)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatSpecYAML(t *testing.T) {
+	input := `prompts: []
+tools:
+    - inputSchema:
+        type: object
+      description: Say hello
+      name: hello # greeting tool
+components:
+    schemas:
+        Zebra:
+            type: string
+        # Apple comes first
+        Apple:
+            type: string
+info:
+    version: 1.0.0
+    title: test
+x-extra: true
+`
+
+	want := `info:
+  title: test
+  version: 1.0.0
+components:
+  schemas:
+    # Apple comes first
+    Apple:
+      type: string
+    Zebra:
+      type: string
+tools:
+  - name: hello # greeting tool
+    description: Say hello
+    inputSchema:
+      type: object
+prompts: []
+x-extra: true
+`
+
+	got, err := FormatSpec([]byte(input), ".yaml")
+	require.NoError(t, err)
+	assert.Equal(t, want, string(got))
+
+	again, err := FormatSpec(got, ".yaml")
+	require.NoError(t, err)
+	assert.Equal(t, string(got), string(again), "formatting should be idempotent")
+}
+
+func TestFormatSpecJSON(t *testing.T) {
+	input := `{"tools":[{"inputSchema":{"type":"object","required":["a"]},"name":"t"}],"info":{"version":"1.0.0","title":"test"}}`
+
+	want := `{
+  "info": {
+    "title": "test",
+    "version": "1.0.0"
+  },
+  "tools": [
+    {
+      "name": "t",
+      "inputSchema": {
+        "type": "object",
+        "required": [
+          "a"
+        ]
+      }
+    }
+  ]
+}
+`
+
+	got, err := FormatSpec([]byte(input), ".json")
+	require.NoError(t, err)
+	assert.Equal(t, want, string(got))
+}
+
+func TestFormatSpecAnchors(t *testing.T) {
+	input := `components:
+  schemas:
+    Zebra: &animal
+      type: object
+    Apple:
+      <<: *animal
+      description: Not an animal
+tools:
+  - outputSchema: &out
+      type: object
+    inputSchema: *out
+    name: t
+`
+
+	want := `components:
+  schemas:
+    Apple:
+      <<: &animal
+        type: object
+      description: Not an animal
+    Zebra: *animal
+tools:
+  - name: t
+    inputSchema: &out
+      type: object
+    outputSchema: *out
+`
+
+	got, err := FormatSpec([]byte(input), ".yaml")
+	require.NoError(t, err)
+	assert.Equal(t, want, string(got), "anchors stay ahead of their aliases")
+
+	again, err := FormatSpec(got, ".yaml")
+	require.NoError(t, err)
+	assert.Equal(t, string(got), string(again), "formatting should be idempotent")
+
+	got, err = FormatSpec([]byte(input), ".json")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"components": {"schemas": {
+			"Apple": {"description": "Not an animal", "type": "object"},
+			"Zebra": {"type": "object"}
+		}},
+		"tools": [{"name": "t", "inputSchema": {"type": "object"}, "outputSchema": {"type": "object"}}]
+	}`, string(got), "merge keys are applied in JSON")
+}
+
+func TestFormatSpecErrors(t *testing.T) {
+	_, err := FormatSpec([]byte("- a\n- b\n"), ".yaml")
+	assert.Error(t, err)
+
+	_, err = FormatSpec([]byte("info: {}\n"), ".toml")
+	assert.Error(t, err)
+
+	_, err = FormatSpec([]byte("info: [\n"), ".yaml")
+	assert.Error(t, err)
+}
+
+func TestFormatSpecMultiDocument(t *testing.T) {
+	input := `info:
+    version: 1.0.0
+    title: test
+include: [fragments/*.yaml]
+---
+# Task tools
+tools:
+    - inputSchema: {type: object}
+      name: create_task
+`
+
+	want := `include: [fragments/*.yaml]
+info:
+  title: test
+  version: 1.0.0
+---
+# Task tools
+tools:
+  - name: create_task
+    inputSchema: {type: object}
+`
+
+	got, err := FormatSpec([]byte(input), ".yaml")
+	require.NoError(t, err)
+	assert.Equal(t, want, string(got))
+}
@@ -0,0 +1,249 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func TestLoadWithIncludes(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"mcpgen.yaml": `spec: schema.yaml
+include:
+  - tools/*.yaml
+  - schemas/*.yaml
+`,
+		"schema.yaml": `info:
+  title: test
+  version: 1.0.0
+tools:
+  - name: ping
+    inputSchema:
+      type: object
+`,
+		"tools/users.yaml": `tools:
+  - name: get_user
+    inputSchema:
+      $ref: '#/components/schemas/UserQuery'
+prompts:
+  - name: summarize_user
+`,
+		"tools/accounts.json": `{"tools": [{"name": "ignored", "inputSchema": {"type": "object"}}]}`,
+		"schemas/user.yaml": `components:
+  schemas:
+    UserQuery:
+      type: object
+      properties:
+        id:
+          type: string
+`,
+	})
+
+	cfg, spec, err := Load(filepath.Join(dir, "mcpgen.yaml"))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		filepath.Join(dir, "schemas/user.yaml"),
+		filepath.Join(dir, "tools/users.yaml"),
+	}, cfg.IncludePaths)
+	assert.Equal(t, append([]string{filepath.Join(dir, "schema.yaml")}, cfg.IncludePaths...), cfg.SpecFiles())
+
+	assert.Equal(t, "test", spec.Info.Title)
+	require.Len(t, spec.Tools, 2)
+	assert.Equal(t, "ping", spec.Tools[0].Name)
+	assert.Equal(t, "get_user", spec.Tools[1].Name)
+	require.Len(t, spec.Prompts, 1)
+	assert.Contains(t, spec.Components.Schemas, "UserQuery")
+
+	_, err = spec.ResolveSchemaRef(spec.Tools[1].InputSchema.Ref)
+	assert.NoError(t, err)
+
+	// Another version of the spec is loaded with the same includes
+	writeFiles(t, dir, map[string]string{
+		"old.yaml": "info:\n  title: test\n  version: 0.9.0\n",
+	})
+	old, err := LoadSpec(cfg, filepath.Join(dir, "old.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "0.9.0", old.Info.Version)
+	require.Len(t, old.Tools, 1)
+	assert.Equal(t, "get_user", old.Tools[0].Name)
+	assert.Contains(t, old.Components.Schemas, "UserQuery")
+}
+
+func TestLoadWithIncludesErrors(t *testing.T) {
+	main := `info:
+  title: test
+  version: 1.0.0
+components:
+  schemas:
+    User:
+      type: object
+`
+
+	tests := []struct {
+		name    string
+		include string
+		err     string
+	}{
+		{
+			name:    "info",
+			include: "info:\n  title: other\n",
+			err:     "info can only be set in the main spec",
+		},
+		{
+			name:    "duplicate schema",
+			include: "components:\n  schemas:\n    User:\n      type: string\n",
+			err:     "components.schemas.User is already defined",
+		},
+		{
+			name:    "tools not a list",
+			include: "tools:\n  name: ping\n",
+			err:     "tools must be a list",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{
+				"schema.yaml":     main,
+				"extra/part.yaml": tt.include,
+			})
+
+			_, err := LoadMCPSpec(filepath.Join(dir, "schema.yaml"), filepath.Join(dir, "extra/part.yaml"))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "failed to include "+filepath.Join(dir, "extra/part.yaml"))
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestLoadMultiDocumentSpec(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"schema.yaml": `components:
+  schemas:
+    Task:
+      type: object
+---
+info:
+  title: test
+  version: 1.0.0
+include:
+  - fragments/*.yaml
+---
+tools:
+  - name: create_task
+    inputSchema:
+      $ref: '#/components/schemas/Task'
+`,
+		"fragments/pagination.yaml": `include:
+  - ../shared/page.yaml
+tools:
+  - name: list_tasks
+    inputSchema:
+      $ref: '#/components/schemas/Page'
+`,
+		"fragments/prompts.yaml": `include:
+  - ../shared/page.yaml
+prompts:
+  - name: summarize
+`,
+		"shared/page.yaml": `components:
+  schemas:
+    Page:
+      type: object
+      properties:
+        cursor:
+          type: string
+`,
+	})
+
+	spec, err := LoadMCPSpec(filepath.Join(dir, "schema.yaml"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "test", spec.Info.Title)
+	require.Len(t, spec.Tools, 2)
+	assert.Equal(t, "create_task", spec.Tools[0].Name)
+	assert.Equal(t, "list_tasks", spec.Tools[1].Name)
+	require.Len(t, spec.Prompts, 1)
+	assert.Contains(t, spec.Components.Schemas, "Task")
+	assert.Contains(t, spec.Components.Schemas, "Page")
+
+	// Fragments are merged depth first, and shared ones only once
+	assert.Equal(t, []string{
+		filepath.Join(dir, "schema.yaml"),
+		filepath.Join(dir, "fragments/pagination.yaml"),
+		filepath.Join(dir, "shared/page.yaml"),
+		filepath.Join(dir, "fragments/prompts.yaml"),
+	}, spec.Files)
+}
+
+func TestLoadMultiDocumentSpecErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		err   string
+	}{
+		{
+			name: "info twice",
+			files: map[string]string{
+				"schema.yaml": "info:\n  title: test\n---\ninfo:\n  title: other\n",
+			},
+			err: "document 2: info is already set by another document",
+		},
+		{
+			name: "info in a fragment",
+			files: map[string]string{
+				"schema.yaml": "info:\n  title: test\ninclude:\n  - part.yaml\n",
+				"part.yaml":   "info:\n  title: other\n",
+			},
+			err: "part.yaml: info can only be set in the main spec",
+		},
+		{
+			name: "include not a list",
+			files: map[string]string{
+				"schema.yaml": "info:\n  title: test\ninclude: part.yaml\n",
+			},
+			err: "include must be a list of file patterns",
+		},
+		{
+			name: "missing fragment",
+			files: map[string]string{
+				"schema.yaml": "info:\n  title: test\ninclude:\n  - missing/*.yaml\n",
+			},
+			err: "include pattern missing/*.yaml matches no file",
+		},
+		{
+			name: "document not a mapping",
+			files: map[string]string{
+				"schema.yaml": "info:\n  title: test\n---\n- tools\n",
+			},
+			err: "spec must be a mapping",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			_, err := LoadMCPSpec(filepath.Join(dir, "schema.yaml"))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
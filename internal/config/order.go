@@ -0,0 +1,113 @@
+package config
+
+import (
+	"go.probo.inc/mcpgen/internal/schema"
+	"gopkg.in/yaml.v3"
+)
+
+// nameKeywords are the keywords whose mappings are keyed by names, so that a
+// property or schema named properties is not taken for the keyword.
+var nameKeywords = map[string]bool{
+	"properties":        true,
+	"patternProperties": true,
+	"dependentSchemas":  true,
+	"$defs":             true,
+	"definitions":       true,
+	"schemas":           true,
+}
+
+// dataKeywords are the keywords whose values are instances rather than
+// schemas, which are left as written.
+var dataKeywords = map[string]bool{
+	"const":    true,
+	"default":  true,
+	"enum":     true,
+	"example":  true,
+	"examples": true,
+}
+
+// recordPropertyOrder sets the go.probo.inc/mcpgen/property-order
+// annotation of the schemas of v, decoded from node, to the names of their
+// properties in the order of the document. The values of the mappings in
+// names are walked as such rather than as schemas.
+func recordPropertyOrder(node *yaml.Node, v interface{}, names bool) {
+	for node.Kind == yaml.DocumentNode || node.Kind == yaml.AliasNode {
+		if node.Kind == yaml.DocumentNode {
+			if len(node.Content) == 0 {
+				return
+			}
+			node = node.Content[0]
+		} else {
+			node = node.Alias
+		}
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for _, p := range mergedPairs(node) {
+			key := p.key.Value
+			if !names && dataKeywords[key] {
+				continue
+			}
+			recordPropertyOrder(p.value, v[key], !names && nameKeywords[key])
+			if !names && key == "properties" {
+				if order := propertyOrder(p.value, v[key]); order != nil {
+					v[schema.PropertyOrderKey] = order
+				}
+			}
+		}
+	case []interface{}:
+		if node.Kind != yaml.SequenceNode || len(node.Content) != len(v) {
+			return
+		}
+		for i, item := range v {
+			recordPropertyOrder(node.Content[i], item, false)
+		}
+	}
+}
+
+// propertyOrder returns the names of the properties mapping v, decoded from
+// node, in the order of the document, or nil when v is not a mapping of
+// schemas.
+func propertyOrder(node *yaml.Node, v interface{}) []interface{} {
+	properties, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	var order []interface{}
+	for _, p := range mergedPairs(node) {
+		switch properties[p.key.Value].(type) {
+		case map[string]interface{}, bool:
+			order = append(order, p.key.Value)
+		default:
+			return nil
+		}
+	}
+	return order
+}
+
+// dropPropertyOrder removes the go.probo.inc/mcpgen/property-order
+// annotations recorded by recordPropertyOrder from v.
+func dropPropertyOrder(v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		delete(v, schema.PropertyOrderKey)
+		for _, value := range v {
+			dropPropertyOrder(value)
+		}
+	case []interface{}:
+		for _, item := range v {
+			dropPropertyOrder(item)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.probo.inc/mcpgen/internal/schema"
+)
+
+func TestLoadPropertyOrder(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"mcpgen.yaml": `spec: schema.yaml
+model:
+  package: types
+  property_order: spec
+`,
+		"schema.yaml": `info:
+  title: tasks
+  version: 1.0.0
+components:
+  schemas:
+    Base:
+      type: object
+      properties: &base-properties
+        id:
+          type: string
+        created_at:
+          type: string
+    Task:
+      type: object
+      properties:
+        title:
+          type: string
+        properties:
+          type: object
+          properties:
+            size: {type: integer}
+            color: {type: string}
+        <<: *base-properties
+      default:
+        properties: {b: 1, a: 2}
+    User:
+      $ref: ./user.json#/User
+`,
+		"user.json": `{"User": {"type": "object", "properties": {"name": {"type": "string"}, "email": {"type": "string"}}}}`,
+	})
+
+	_, spec, err := Load(filepath.Join(dir, "mcpgen.yaml"))
+	require.NoError(t, err)
+
+	task := spec.Components.Schemas["Task"]
+	assert.Equal(t, []any{"title", "properties", "id", "created_at"}, task.Extra[schema.PropertyOrderKey])
+	assert.Equal(t, []any{"size", "color"}, task.Properties["properties"].Extra[schema.PropertyOrderKey])
+	assert.JSONEq(t, `{"properties": {"b": 1, "a": 2}}`, string(task.Default), "instances are left as written")
+	assert.Equal(t, []any{"name", "email"}, spec.Components.Schemas["User"].Extra[schema.PropertyOrderKey])
+	assert.Equal(t, []string{"title", "properties", "id", "created_at"}, schema.PropertyNames(task))
+
+	spec, err = LoadMCPSpec(filepath.Join(dir, "schema.yaml"))
+	require.NoError(t, err)
+	assert.NotContains(t, spec.Components.Schemas["Task"].Extra, schema.PropertyOrderKey)
+	assert.Equal(t, []string{"created_at", "id", "properties", "title"}, schema.PropertyNames(spec.Components.Schemas["Task"]))
+
+	writeFiles(t, dir, map[string]string{"mcpgen.yaml": "spec: schema.yaml\nmodel:\n  package: types\n  property_order: random\n"})
+	_, _, err = Load(filepath.Join(dir, "mcpgen.yaml"))
+	assert.ErrorContains(t, err, "model.property_order must be alphabetical or spec")
+}
@@ -0,0 +1,174 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DescriptionPlaceholderPrefix starts every description inserted by
+// AddDescriptionPlaceholders, so remaining placeholders can be tracked.
+const DescriptionPlaceholderPrefix = "TODO"
+
+// IsDescriptionPlaceholder reports whether a description is a placeholder
+// that still needs to be written.
+func IsDescriptionPlaceholder(description string) bool {
+	return strings.HasPrefix(strings.TrimSpace(description), DescriptionPlaceholderPrefix)
+}
+
+// AddDescriptionPlaceholders inserts TODO descriptions into the tools and
+// schema properties of a spec file that lack one. It returns the updated file
+// and the paths of the inserted placeholders. Properties using $ref are
+// skipped: their description belongs to the referenced schema, and so are
+// the properties merged from an alias with a << key, described where they
+// are anchored.
+func AddDescriptionPlaceholders(data []byte, ext string) ([]byte, []string, error) {
+	docs, err := parseSpecNodes(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse spec: %w", err)
+	}
+
+	var added []string
+
+	for _, doc := range docs {
+		root := specRoot(doc)
+		if root == nil {
+			continue
+		}
+		if root.Kind != yaml.MappingNode {
+			return nil, nil, fmt.Errorf("spec must be a mapping")
+		}
+
+		if components := mappingValue(root, "components"); components != nil {
+			if schemas := mappingValue(components, "schemas"); schemas != nil && schemas.Kind == yaml.MappingNode {
+				for _, p := range mappingPairs(schemas) {
+					added = addPropertyPlaceholders("components.schemas."+p.key.Value, p.value, added)
+				}
+			}
+		}
+
+		if tools := mappingValue(root, "tools"); tools != nil && tools.Kind == yaml.SequenceNode {
+			for _, tool := range tools.Content {
+				name := scalarValue(mappingValue(tool, "name"))
+				path := "tools." + name
+				if needsDescription(tool) {
+					insertDescription(tool, "name", placeholderText(name))
+					added = append(added, path)
+				}
+				added = addPropertyPlaceholders(path+".inputSchema", mappingValue(tool, "inputSchema"), added)
+				added = addPropertyPlaceholders(path+".outputSchema", mappingValue(tool, "outputSchema"), added)
+			}
+		}
+
+		if resources := mappingValue(root, "resources"); resources != nil && resources.Kind == yaml.SequenceNode {
+			for _, resource := range resources.Content {
+				path := "resources." + scalarValue(mappingValue(resource, "name")) + ".schema"
+				added = addPropertyPlaceholders(path, mappingValue(resource, "schema"), added)
+			}
+		}
+
+		reanchor(root, map[string]bool{})
+	}
+
+	if len(added) == 0 {
+		return data, nil, nil
+	}
+
+	out, err := encodeSpec(docs, ext)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return out, added, nil
+}
+
+func addPropertyPlaceholders(path string, s *yaml.Node, added []string) []string {
+	if s == nil || s.Kind != yaml.MappingNode || mappingValue(s, "$ref") != nil {
+		return added
+	}
+
+	if props := mappingValue(s, "properties"); props != nil {
+		added = addPropertiesPlaceholders(path, props, added)
+	}
+
+	added = addPropertyPlaceholders(path+"[]", mappingValue(s, "items"), added)
+
+	return added
+}
+
+// addPropertiesPlaceholders adds the placeholders of the properties mapping
+// of a schema. The properties a << key merges from an alias are left to the
+// mapping it refers to, while those of a mapping anchored in place are
+// walked where they are.
+func addPropertiesPlaceholders(path string, props *yaml.Node, added []string) []string {
+	if props.Kind != yaml.MappingNode {
+		return added
+	}
+
+	for _, p := range mappingPairs(props) {
+		if isMergeKey(p.key) {
+			sources := []*yaml.Node{p.value}
+			if p.value.Kind == yaml.SequenceNode {
+				sources = p.value.Content
+			}
+			for _, source := range sources {
+				added = addPropertiesPlaceholders(path, source, added)
+			}
+			continue
+		}
+
+		propPath := path + "." + p.key.Value
+		if p.value.Kind == yaml.MappingNode && mappingValue(p.value, "$ref") == nil && needsDescription(p.value) {
+			insertDescription(p.value, "type", placeholderText(p.key.Value))
+			added = append(added, propPath)
+		}
+		added = addPropertyPlaceholders(propPath, p.value, added)
+	}
+
+	return added
+}
+
+func needsDescription(n *yaml.Node) bool {
+	return strings.TrimSpace(scalarValue(mappingValue(n, "description"))) == ""
+}
+
+// insertDescription sets the description of a mapping, placing a new key
+// right after afterKey when present.
+func insertDescription(n *yaml.Node, afterKey, text string) {
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: text}
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == "description" {
+			n.Content[i+1] = value
+			return
+		}
+	}
+
+	key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "description"}
+
+	pos := 0
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == afterKey {
+			pos = i + 2
+			break
+		}
+	}
+
+	content := make([]*yaml.Node, 0, len(n.Content)+2)
+	content = append(content, n.Content[:pos]...)
+	content = append(content, key, value)
+	content = append(content, n.Content[pos:]...)
+	n.Content = content
+}
+
+func placeholderText(name string) string {
+	return fmt.Sprintf("%s: describe %s", DescriptionPlaceholderPrefix, name)
+}
+
+func scalarValue(n *yaml.Node) string {
+	if n == nil || n.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return n.Value
+}
@@ -0,0 +1,186 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddDescriptionPlaceholders(t *testing.T) {
+	input := `info:
+  title: test
+  version: 1.0.0
+components:
+  schemas:
+    Task:
+      type: object
+      properties:
+        id:
+          type: string
+          description: Task ID
+        tags:
+          type: array
+          items:
+            type: object
+            properties:
+              label:
+                type: string
+tools:
+  - name: get_task # fetch one task
+    inputSchema:
+      type: object
+      properties:
+        id:
+          type: string
+        task:
+          $ref: "#/components/schemas/Task"
+  - name: ping
+    description: Check liveness
+`
+
+	want := `info:
+  title: test
+  version: 1.0.0
+components:
+  schemas:
+    Task:
+      type: object
+      properties:
+        id:
+          type: string
+          description: Task ID
+        tags:
+          type: array
+          description: 'TODO: describe tags'
+          items:
+            type: object
+            properties:
+              label:
+                type: string
+                description: 'TODO: describe label'
+tools:
+  - name: get_task # fetch one task
+    description: 'TODO: describe get_task'
+    inputSchema:
+      type: object
+      properties:
+        id:
+          type: string
+          description: 'TODO: describe id'
+        task:
+          $ref: "#/components/schemas/Task"
+  - name: ping
+    description: Check liveness
+`
+
+	got, added, err := AddDescriptionPlaceholders([]byte(input), ".yaml")
+	require.NoError(t, err)
+	assert.Equal(t, want, string(got))
+	assert.Equal(
+		t,
+		[]string{
+			"components.schemas.Task.tags",
+			"components.schemas.Task.tags[].label",
+			"tools.get_task",
+			"tools.get_task.inputSchema.id",
+		},
+		added,
+	)
+
+	again, added, err := AddDescriptionPlaceholders(got, ".yaml")
+	require.NoError(t, err)
+	assert.Empty(t, added)
+	assert.Equal(t, string(got), string(again))
+}
+
+func TestAddDescriptionPlaceholdersJSON(t *testing.T) {
+	input := `{"info":{"title":"test","version":"1.0.0"},"tools":[{"name":"ping","inputSchema":{"type":"object"}}]}`
+
+	got, added, err := AddDescriptionPlaceholders([]byte(input), ".json")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tools.ping"}, added)
+	assert.Contains(t, string(got), `"description": "TODO: describe ping"`)
+}
+
+func TestAddDescriptionPlaceholdersMultiDocument(t *testing.T) {
+	input := "info:\n  title: test\n---\ntools:\n  - name: ping\n"
+
+	got, added, err := AddDescriptionPlaceholders([]byte(input), ".yaml")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tools.ping"}, added)
+	assert.Equal(t, "info:\n  title: test\n---\ntools:\n  - name: ping\n    description: 'TODO: describe ping'\n", string(got))
+}
+
+func TestAddDescriptionPlaceholdersMergeKeys(t *testing.T) {
+	input := `components:
+  schemas:
+    Base: &base
+      type: object
+      properties: &props
+        id:
+          type: string
+    Ext:
+      <<: *base
+      description: Extended
+    Named:
+      type: object
+      properties:
+        <<: *props
+        name:
+          type: string
+    Owned:
+      type: object
+      properties:
+        <<: &owner
+          owner:
+            type: string
+`
+
+	want := `components:
+  schemas:
+    Base: &base
+      type: object
+      properties: &props
+        id:
+          type: string
+          description: 'TODO: describe id'
+    Ext:
+      <<: *base
+      description: Extended
+    Named:
+      type: object
+      properties:
+        <<: *props
+        name:
+          type: string
+          description: 'TODO: describe name'
+    Owned:
+      type: object
+      properties:
+        <<: &owner
+          owner:
+            type: string
+            description: 'TODO: describe owner'
+`
+
+	got, added, err := AddDescriptionPlaceholders([]byte(input), ".yaml")
+	require.NoError(t, err)
+	assert.Equal(t, want, string(got))
+	assert.Equal(
+		t,
+		[]string{
+			"components.schemas.Base.id",
+			"components.schemas.Named.name",
+			"components.schemas.Owned.owner",
+		},
+		added,
+	)
+}
+
+func TestIsDescriptionPlaceholder(t *testing.T) {
+	assert.True(t, IsDescriptionPlaceholder("TODO: describe id"))
+	assert.True(t, IsDescriptionPlaceholder("  TODO"))
+	assert.False(t, IsDescriptionPlaceholder("Task ID"))
+	assert.False(t, IsDescriptionPlaceholder(""))
+}
@@ -0,0 +1,199 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// LockFilename is the name of the file, next to the config file,
+	// recording the content hash of the documents of remote references.
+	LockFilename = "mcpgen.lock"
+	// RefCacheDir is the directory, next to the config file, holding the
+	// documents of remote references, so that they load without network
+	// access.
+	RefCacheDir = ".mcpgen/refs"
+)
+
+// remoteRefs fetches the documents of https references. Each document is
+// pinned by the hash of its content recorded in the lockfile, and kept in a
+// cache directory. A frozen remoteRefs never accesses the network: every
+// document must be locked and cached. A read-only remoteRefs writes neither
+// the lockfile nor the cache. A discarding one doesn't either, and doesn't
+// report the documents missing from the lockfile: it loads other versions of
+// the spec, whose documents are not pinned.
+type remoteRefs struct {
+	lockPath string
+	cacheDir string
+	frozen   bool
+	readOnly bool
+	discard  bool
+	client   *http.Client
+
+	lock    map[string]string
+	changed bool
+}
+
+func newRemoteRefs(dir string, frozen, readOnly bool) *remoteRefs {
+	return &remoteRefs{
+		lockPath: filepath.Join(dir, LockFilename),
+		cacheDir: filepath.Join(dir, filepath.FromSlash(RefCacheDir)),
+		frozen:   frozen,
+		readOnly: readOnly,
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// document returns the decoded document at rawURL.
+func (r *remoteRefs) document(rawURL string) (map[string]interface{}, error) {
+	data, err := r.fetch(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := ".json"
+	if u, err := url.Parse(rawURL); err == nil {
+		switch e := path.Ext(u.Path); e {
+		case ".yaml", ".yml", ".json":
+			ext = e
+		}
+	}
+	return decodeSpecDocument(data, ext)
+}
+
+// fetch returns the content of rawURL, from the cache when it holds the
+// locked content, and records the hash of newly fetched documents.
+func (r *remoteRefs) fetch(rawURL string) ([]byte, error) {
+	if err := r.readLock(); err != nil {
+		return nil, err
+	}
+
+	locked, ok := r.lock[rawURL]
+	if ok {
+		if data, err := os.ReadFile(r.cachePath(locked)); err == nil && contentHash(data) == locked {
+			return data, nil
+		}
+	}
+
+	if r.frozen {
+		if !ok {
+			return nil, fmt.Errorf("not recorded in %s, run without --frozen to fetch it", LockFilename)
+		}
+		return nil, fmt.Errorf("not found in %s, run without --frozen to fetch it", RefCacheDir)
+	}
+
+	resp, err := r.client.Get(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch: %s", resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch: %w", err)
+	}
+
+	hash := contentHash(data)
+	if ok && hash != locked {
+		return nil, fmt.Errorf("content changed: %s records %s but %s was fetched, remove its line from %s to accept the change", LockFilename, locked, hash, LockFilename)
+	}
+
+	if !r.readOnly && !r.discard {
+		if err := os.MkdirAll(r.cacheDir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create cache directory: %w", err)
+		}
+		if err := os.WriteFile(r.cachePath(hash), data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write cache: %w", err)
+		}
+	}
+	if !ok {
+		r.lock[rawURL] = hash
+		r.changed = true
+	}
+
+	return data, nil
+}
+
+func (r *remoteRefs) cachePath(hash string) string {
+	return filepath.Join(r.cacheDir, strings.TrimPrefix(hash, "sha256:"))
+}
+
+// readLock reads the lockfile on first use. Each line holds a URL and the
+// hash of its content; a missing lockfile is empty.
+func (r *remoteRefs) readLock() error {
+	if r.lock != nil {
+		return nil
+	}
+	r.lock = map[string]string{}
+
+	data, err := os.ReadFile(r.lockPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", LockFilename, err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 || !strings.HasPrefix(fields[1], "sha256:") {
+			return fmt.Errorf("%s:%d: expected a URL and its sha256 hash", LockFilename, line)
+		}
+		r.lock[fields[0]] = fields[1]
+	}
+	return nil
+}
+
+// saveLock writes the lockfile when documents were fetched for the first
+// time, or reports it out of date when r is read-only. It does nothing on a
+// nil or discarding remoteRefs.
+func (r *remoteRefs) saveLock() error {
+	if r == nil || r.discard || !r.changed {
+		return nil
+	}
+	if r.readOnly {
+		return fmt.Errorf("%s is out of date, run mcpgen generate to record the remote $ref documents", LockFilename)
+	}
+
+	urls := make([]string, 0, len(r.lock))
+	for u := range r.lock {
+		urls = append(urls, u)
+	}
+	sort.Strings(urls)
+
+	var buf bytes.Buffer
+	buf.WriteString("# Generated by mcpgen. Content hashes of the remote $ref documents.\n")
+	for _, u := range urls {
+		fmt.Fprintf(&buf, "%s %s\n", u, r.lock[u])
+	}
+
+	if err := os.WriteFile(r.lockPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", LockFilename, err)
+	}
+	r.changed = false
+	return nil
+}
+
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
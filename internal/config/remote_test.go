@@ -0,0 +1,157 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMCPSpecRemoteRefs(t *testing.T) {
+	documents := map[string]string{
+		"/schemas/task.json":   `{"Task": {"type": "object", "properties": {"owner": {"$ref": "common.yaml#/User"}}}}`,
+		"/schemas/common.yaml": "User:\n  type: object\n",
+	}
+	requests := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		doc, ok := documents[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(doc))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"schema.yaml": `info:
+  title: tasks
+  version: 1.0.0
+tools:
+  - name: get_task
+    inputSchema:
+      type: object
+    outputSchema:
+      $ref: ` + server.URL + `/schemas/task.json#/Task
+`,
+	})
+	specPath := filepath.Join(dir, "schema.yaml")
+
+	load := func(frozen bool) (*MCPSpec, error) {
+		remote := newRemoteRefs(dir, frozen, false)
+		remote.client = server.Client()
+		return loadMCPSpec(specPath, nil, remote, false)
+	}
+
+	spec, err := load(false)
+	require.NoError(t, err)
+	assert.Equal(t, "#/components/schemas/User", spec.Tools[0].OutputSchema.Properties["owner"].Ref)
+	assert.Equal(t, "object", spec.Components.Schemas["User"].Type)
+	assert.Equal(t, 2, requests)
+
+	lock, err := os.ReadFile(filepath.Join(dir, LockFilename))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(lock)), "\n")
+	require.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[1], server.URL+"/schemas/common.yaml sha256:"), lines[1])
+	assert.True(t, strings.HasPrefix(lines[2], server.URL+"/schemas/task.json sha256:"), lines[2])
+
+	t.Run("frozen loads from the cache", func(t *testing.T) {
+		requests = 0
+		_, err := load(true)
+		require.NoError(t, err)
+		assert.Equal(t, 0, requests)
+	})
+
+	t.Run("changed content is rejected", func(t *testing.T) {
+		require.NoError(t, os.RemoveAll(filepath.Join(dir, RefCacheDir)))
+		documents["/schemas/common.yaml"] = "User:\n  type: string\n"
+
+		_, err := load(true)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "run without --frozen to fetch it")
+
+		_, err = load(false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "content changed")
+	})
+
+	t.Run("frozen rejects unlocked documents", func(t *testing.T) {
+		require.NoError(t, os.Remove(filepath.Join(dir, LockFilename)))
+
+		_, err := load(true)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not recorded in mcpgen.lock")
+	})
+
+	t.Run("read-only reports an out-of-date lock", func(t *testing.T) {
+		cached, err := os.ReadDir(filepath.Join(dir, RefCacheDir))
+		require.NoError(t, err)
+
+		remote := newRemoteRefs(dir, false, true)
+		remote.client = server.Client()
+		_, err = loadMCPSpec(specPath, nil, remote, false)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "mcpgen.lock is out of date")
+
+		assert.NoFileExists(t, filepath.Join(dir, LockFilename))
+		after, err := os.ReadDir(filepath.Join(dir, RefCacheDir))
+		require.NoError(t, err)
+		assert.Len(t, after, len(cached), "fetched documents are not cached")
+	})
+}
+
+func TestLoadSpecLeavesLockUnchanged(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("User:\n  type: object\n"))
+	}))
+	defer server.Close()
+
+	// LoadSpec fetches with the default transport, which must trust the
+	// certificate of the server
+	transport := http.DefaultTransport
+	http.DefaultTransport = server.Client().Transport
+	defer func() { http.DefaultTransport = transport }()
+
+	dir := t.TempDir()
+	lock := "# Generated by mcpgen. Content hashes of the remote $ref documents.\n"
+	writeFiles(t, dir, map[string]string{
+		"mcpgen.yaml": "spec: schema.yaml\n",
+		"schema.yaml": "info:\n  title: tasks\n  version: 1.0.0\n",
+		"old.yaml": `info:
+  title: tasks
+  version: 0.9.0
+tools:
+  - name: get_user
+    inputSchema:
+      type: object
+    outputSchema:
+      $ref: ` + server.URL + `/schemas/common.yaml#/User
+`,
+		LockFilename: lock,
+	})
+
+	cfg, _, err := Load(filepath.Join(dir, "mcpgen.yaml"))
+	require.NoError(t, err)
+
+	old, err := LoadSpec(cfg, filepath.Join(dir, "old.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "object", old.Tools[0].OutputSchema.Type)
+
+	data, err := os.ReadFile(filepath.Join(dir, LockFilename))
+	require.NoError(t, err)
+	assert.Equal(t, lock, string(data))
+	assert.NoDirExists(t, filepath.Join(dir, RefCacheDir))
+
+	// Even when read-only, the documents of another version are not
+	// reported missing from the lockfile
+	_, err = LoadSpec(cfg, filepath.Join(dir, "old.yaml"), WithReadOnly(true))
+	require.NoError(t, err)
+}
@@ -1,11 +1,17 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 
+	"go.probo.inc/mcpgen/internal/schema"
 	"gopkg.in/yaml.v3"
 )
 
@@ -15,81 +21,525 @@ type MCPSpec struct {
 	Tools      []Tool     `yaml:"tools,omitempty" json:"tools,omitempty"`
 	Resources  []Resource `yaml:"resources,omitempty" json:"resources,omitempty"`
 	Prompts    []Prompt   `yaml:"prompts,omitempty" json:"prompts,omitempty"`
+	// Experiments declares the flags gating tools, see Tool.Experiment.
+	Experiments []Experiment `yaml:"experiments,omitempty" json:"experiments,omitempty"`
+	// Env declares the environment variables the server reads.
+	Env []EnvVar `yaml:"env,omitempty" json:"env,omitempty"`
+
+	// Files are the paths of the spec files loaded: the main spec, then the
+	// included files and the fragments they include, in merge order.
+	Files []string `yaml:"-" json:"-"`
+	// Warnings are non-fatal problems found while loading, such as draft-07
+	// schema constructs that could not be converted to 2020-12.
+	Warnings []string `yaml:"-" json:"-"`
+}
+
+// LoadMCPSpec loads the spec at path, merging the tools, resources, prompts
+// and component schemas of the included spec files into it. Schemas
+// referenced from other files, as in $ref: ./schemas/task.yaml#/Task, are
+// resolved relative to the referencing file and added to the components.
+func LoadMCPSpec(path string, includes ...string) (*MCPSpec, error) {
+	return loadMCPSpec(path, includes, nil, false)
 }
 
-func LoadMCPSpec(path string) (*MCPSpec, error) {
+// loadMCPSpec loads a spec as LoadMCPSpec does, fetching the documents of
+// remote references with remote. A nil remote rejects remote references.
+// With specOrder, the schemas keep the order of their properties in the
+// spec in their go.probo.inc/mcpgen/property-order annotation.
+func loadMCPSpec(path string, includes []string, remote *remoteRefs, specOrder bool) (*MCPSpec, error) {
+	var docs []specDocument
+	var files []string
+	seen := map[string]bool{}
+	for i, file := range append([]string{path}, includes...) {
+		read, err := readSpecFiles(file, seen)
+		if err != nil {
+			if i == 0 {
+				return nil, err
+			}
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		for _, d := range read {
+			files = append(files, d.path)
+			absPath, err := filepath.Abs(d.path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get absolute path: %w", err)
+			}
+			docs = append(docs, specDocument{path: absPath, doc: d.doc})
+		}
+	}
+
+	doc := docs[0].doc
+	if err := newBundler(doc, remote).bundle(docs); err != nil {
+		return nil, fmt.Errorf("failed to resolve references: %w", err)
+	}
+	if err := remote.saveLock(); err != nil {
+		return nil, err
+	}
+
+	for i, part := range docs[1:] {
+		if err := mergeSpecDocument(doc, part.doc); err != nil {
+			return nil, fmt.Errorf("failed to include %s: %w", files[i+1], err)
+		}
+	}
+
+	if !specOrder {
+		dropPropertyOrder(doc)
+	}
+
+	spec := &MCPSpec{Files: files}
+	spec.Warnings = normalizeSpecSchemas(doc)
+	hoistDefs(doc)
+
+	jsonData, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert spec to JSON: %w", err)
+	}
+	if err := json.Unmarshal(jsonData, spec); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal spec: %w", err)
+	}
+
+	if err := spec.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid MCP specification: %w", err)
+	}
+
+	return spec, nil
+}
+
+// readSpecFiles reads the spec file at path, then, depth first, the
+// fragment files listed by the include directives of its documents, which
+// are glob patterns relative to it. Files in seen, such as fragments shared
+// by several files, are only read once.
+func readSpecFiles(path string, seen map[string]bool) ([]specDocument, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	if seen[absPath] {
+		return nil, nil
+	}
+	seen[absPath] = true
+
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read MCP spec file: %w", err)
 	}
+	parts, err := decodeSpecDocuments(data, filepath.Ext(path))
+	if err != nil {
+		return nil, err
+	}
 
-	spec := &MCPSpec{}
-
-	ext := filepath.Ext(path)
-	switch ext {
-	case ".yaml", ".yml":
-		var intermediate interface{}
-		if err := yaml.Unmarshal(data, &intermediate); err != nil {
-			return nil, fmt.Errorf("failed to parse YAML spec: %w", err)
+	var patterns []string
+	for _, part := range parts {
+		include, err := includeDirective(part)
+		if err != nil {
+			return nil, err
 		}
-		jsonData, err := json.Marshal(intermediate)
+		patterns = append(patterns, include...)
+	}
+	doc, err := mergeSpecDocuments(parts)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := []specDocument{{path: path, doc: doc}}
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(filepath.Dir(path), pattern))
 		if err != nil {
-			return nil, fmt.Errorf("failed to convert YAML to JSON: %w", err)
+			return nil, fmt.Errorf("include pattern %s: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("include pattern %s matches no file", pattern)
 		}
-		if err := json.Unmarshal(jsonData, spec); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal spec: %w", err)
+		for _, match := range matches {
+			fragments, err := readSpecFiles(match, seen)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", match, err)
+			}
+			docs = append(docs, fragments...)
+		}
+	}
+	return docs, nil
+}
+
+// includeDirective removes the include directive from a spec document and
+// returns its patterns.
+func includeDirective(doc map[string]interface{}) ([]string, error) {
+	value, ok := doc["include"]
+	if !ok {
+		return nil, nil
+	}
+	delete(doc, "include")
+
+	items, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("include must be a list of file patterns")
+	}
+	patterns := make([]string, 0, len(items))
+	for _, item := range items {
+		pattern, ok := item.(string)
+		if !ok || pattern == "" {
+			return nil, fmt.Errorf("include must be a list of file patterns")
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// readSpecDocument decodes a YAML or JSON file holding a single document,
+// such as the target of a $ref. An empty file decodes to an empty document.
+func readSpecDocument(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read MCP spec file: %w", err)
+	}
+
+	return decodeSpecDocument(data, filepath.Ext(path))
+}
+
+// decodeSpecDocument decodes a document in the format selected by ext,
+// rejecting YAML streams of several documents.
+func decodeSpecDocument(data []byte, ext string) (map[string]interface{}, error) {
+	docs, err := decodeSpecDocuments(data, ext)
+	if err != nil {
+		return nil, err
+	}
+	switch len(docs) {
+	case 0:
+		return map[string]interface{}{}, nil
+	case 1:
+		return docs[0], nil
+	default:
+		return nil, fmt.Errorf("expected a single YAML document, found %d", len(docs))
+	}
+}
+
+// decodeSpecDocuments decodes the documents of a spec file in the format
+// selected by ext: YAML files may hold several, separated by ---. The
+// schemas of the documents record the order of their properties, see
+// recordPropertyOrder.
+func decodeSpecDocuments(data []byte, ext string) ([]map[string]interface{}, error) {
+	var docs []map[string]interface{}
+
+	switch ext {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		for {
+			var node yaml.Node
+			if err := dec.Decode(&node); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("failed to parse YAML spec: %w", err)
+			}
+			var intermediate interface{}
+			if err := node.Decode(&intermediate); err != nil {
+				return nil, fmt.Errorf("failed to parse YAML spec: %w", err)
+			}
+			recordPropertyOrder(&node, intermediate, false)
+			doc, err := specMapping(intermediate)
+			if err != nil {
+				return nil, err
+			}
+			docs = append(docs, doc)
 		}
 	case ".json":
-		if err := json.Unmarshal(data, spec); err != nil {
+		var intermediate interface{}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&intermediate); err != nil {
 			return nil, fmt.Errorf("failed to parse JSON spec: %w", err)
 		}
+		// JSON is YAML, whose nodes keep the order of the keys
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			node = yaml.Node{}
+		}
+		recordPropertyOrder(&node, intermediate, false)
+		doc, err := specMapping(intermediate)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
 	default:
 		return nil, fmt.Errorf("unsupported spec file format: %s (use .yaml, .yml, or .json)", ext)
 	}
 
-	if err := spec.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid MCP specification: %w", err)
+	return docs, nil
+}
+
+// specMapping returns a decoded document as a mapping. An empty document is
+// an empty mapping.
+func specMapping(intermediate interface{}) (map[string]interface{}, error) {
+	if intermediate == nil {
+		return map[string]interface{}{}, nil
+	}
+	doc, ok := intermediate.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("spec must be a mapping")
 	}
+	return doc, nil
+}
 
-	return spec, nil
+// mergeSpecDocuments merges the documents of a spec file into the first one,
+// in order, as mergeSpecDocument merges included files, except that the
+// server info may be set by any one of them.
+func mergeSpecDocuments(docs []map[string]interface{}) (map[string]interface{}, error) {
+	if len(docs) == 0 {
+		return map[string]interface{}{}, nil
+	}
+
+	doc := docs[0]
+	for i, part := range docs[1:] {
+		if info, ok := part["info"]; ok {
+			if _, ok := doc["info"]; ok {
+				return nil, fmt.Errorf("document %d: info is already set by another document", i+2)
+			}
+			doc["info"] = info
+			delete(part, "info")
+		}
+		if err := mergeSpecDocument(doc, part); err != nil {
+			return nil, fmt.Errorf("document %d: %w", i+2, err)
+		}
+	}
+	return doc, nil
+}
+
+// mergeSpecDocument adds the tools, resources, prompts, experiments, environment
+// variables and component schemas of an included spec file to doc. Server info is only read from the main
+// spec, and a component schema cannot be defined twice.
+func mergeSpecDocument(doc, part map[string]interface{}) error {
+	if _, ok := part["info"]; ok {
+		return fmt.Errorf("info can only be set in the main spec")
+	}
+
+	for _, section := range []string{"tools", "resources", "prompts", "experiments", "env"} {
+		items, ok := part[section].([]interface{})
+		if !ok {
+			if part[section] != nil {
+				return fmt.Errorf("%s must be a list", section)
+			}
+			continue
+		}
+		existing, _ := doc[section].([]interface{})
+		doc[section] = append(existing, items...)
+	}
+
+	components, _ := part["components"].(map[string]interface{})
+	schemas, _ := components["schemas"].(map[string]interface{})
+	if len(schemas) == 0 {
+		return nil
+	}
+
+	docComponents, ok := doc["components"].(map[string]interface{})
+	if !ok {
+		docComponents = map[string]interface{}{}
+		doc["components"] = docComponents
+	}
+	docSchemas, ok := docComponents["schemas"].(map[string]interface{})
+	if !ok {
+		docSchemas = map[string]interface{}{}
+		docComponents["schemas"] = docSchemas
+	}
+
+	names := make([]string, 0, len(schemas))
+	for name := range schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := docSchemas[name]; ok {
+			return fmt.Errorf("components.schemas.%s is already defined", name)
+		}
+		docSchemas[name] = schemas[name]
+	}
+
+	return nil
+}
+
+// normalizeSpecSchemas converts the inline schemas of a decoded spec to JSON
+// Schema 2020-12, see schema.Normalize.
+func normalizeSpecSchemas(doc interface{}) []string {
+	root, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var warnings []string
+
+	if components, ok := root["components"].(map[string]interface{}); ok {
+		if schemas, ok := components["schemas"].(map[string]interface{}); ok {
+			names := make([]string, 0, len(schemas))
+			for name := range schemas {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				warnings = append(warnings, schema.Normalize("components.schemas."+name, schemas[name])...)
+			}
+		}
+	}
+
+	entries := func(section, nameKey string, schemaKeys ...string) {
+		items, _ := root[section].([]interface{})
+		for _, item := range items {
+			entry, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := entry[nameKey].(string)
+			for _, key := range schemaKeys {
+				if s, ok := entry[key]; ok {
+					warnings = append(warnings, schema.Normalize(section+"."+name+"."+key, s)...)
+				}
+			}
+		}
+	}
+	entries("tools", "name", "inputSchema", "outputSchema")
+	entries("resources", "name", "schema")
+
+	return warnings
 }
 
+// Validate checks the required fields of the spec. All problems are
+// reported, as a *ValidationError.
 func (s *MCPSpec) Validate() error {
+	errs := &ValidationError{}
+
 	if s.Info.Title == "" {
-		return fmt.Errorf("info.title is required")
+		errs.add("info.title is required")
 	}
 	if s.Info.Version == "" {
-		return fmt.Errorf("info.version is required")
+		errs.add("info.version is required")
+	}
+
+	experiments := make(map[string]bool, len(s.Experiments))
+	for i, experiment := range s.Experiments {
+		path := entryPath("experiments", i, experiment.Name)
+		switch {
+		case experiment.Name == "":
+			errs.add("%s.name is required", path)
+		case !experimentNamePattern.MatchString(experiment.Name):
+			errs.add("%s.name must start with a letter and contain only letters, digits, - and _", path)
+		case experiments[experiment.Name]:
+			errs.add("%s is already declared", path)
+		}
+		experiments[experiment.Name] = true
+	}
+
+	env := make(map[string]bool, len(s.Env))
+	for i, v := range s.Env {
+		path := entryPath("env", i, v.Name)
+		switch {
+		case v.Name == "":
+			errs.add("%s.name is required", path)
+		case !envNamePattern.MatchString(v.Name):
+			errs.add("%s.name must start with a letter or _ and contain only letters, digits and _", path)
+		case env[v.Name]:
+			errs.add("%s is already declared", path)
+		}
+		env[v.Name] = true
+		if v.Required && v.Default != "" {
+			errs.add("%s cannot be both required and have a default", path)
+		}
+	}
+
+	tools := make(map[string]bool, len(s.Tools))
+	for _, tool := range s.Tools {
+		tools[tool.Name] = true
 	}
 
 	for i, tool := range s.Tools {
+		path := entryPath("tools", i, tool.Name)
 		if tool.Name == "" {
-			return fmt.Errorf("tools[%d].name is required", i)
+			errs.add("%s.name is required", path)
 		}
-		if tool.InputSchema == nil {
-			return fmt.Errorf("tools[%d].inputSchema is required", i)
+		if tool.NoInput {
+			if tool.InputSchema != nil && !isEmptySchema(tool.InputSchema) {
+				errs.add("%s cannot have both noInput and inputSchema", path)
+			}
+		} else if tool.InputSchema == nil {
+			errs.add("%s.inputSchema is required", path)
+		}
+		validateCapabilities(errs, path, tool.RequiresClientCapability)
+		if tool.Experiment != "" && !experiments[tool.Experiment] {
+			errs.add("%s.experiment: unknown experiment %q, declare it in experiments", path, tool.Experiment)
+		}
+		if tool.ReplacedBy != "" {
+			switch {
+			case !tool.Deprecated:
+				errs.add("%s.replacedBy requires deprecated: true", path)
+			case tool.ReplacedBy == tool.Name || !tools[tool.ReplacedBy]:
+				errs.add("%s.replacedBy: unknown tool %q", path, tool.ReplacedBy)
+			}
+		}
+		if tool.DevFixture != nil && tool.OutputSchema != nil {
+			if _, ok := tool.DevFixture.(map[string]any); !ok {
+				errs.add("%s.devFixture must be an object, as the tool has an outputSchema", path)
+			}
+		}
+		if tool.IsComposite() {
+			s.validateSteps(errs, path, tool)
+			if tool.Group != "" {
+				errs.add("%s.group: composite tools have no resolver handler to group", path)
+			}
+		} else if tool.Output != nil {
+			errs.add("%s.output requires steps", path)
 		}
+		validateGroup(errs, path, tool.Group)
 	}
 
 	for i, resource := range s.Resources {
+		path := entryPath("resources", i, resource.Name)
 		if resource.Name == "" {
-			return fmt.Errorf("resources[%d].name is required", i)
+			errs.add("%s.name is required", path)
 		}
 		if resource.URI == "" && resource.URITemplate == "" {
-			return fmt.Errorf("resources[%d] must have either uri or uriTemplate", i)
+			errs.add("%s must have either uri or uriTemplate", path)
 		}
 		if resource.URI != "" && resource.URITemplate != "" {
-			return fmt.Errorf("resources[%d] cannot have both uri and uriTemplate", i)
+			errs.add("%s cannot have both uri and uriTemplate", path)
 		}
+		if resource.Encoding != "" && !experimentNamePattern.MatchString(resource.Encoding) {
+			errs.add("%s.encoding must start with a letter and contain only letters, digits, - and _", path)
+		}
+		validateCapabilities(errs, path, resource.RequiresClientCapability)
+		validateGroup(errs, path, resource.Group)
 	}
 
 	for i, prompt := range s.Prompts {
 		if prompt.Name == "" {
-			return fmt.Errorf("prompts[%d].name is required", i)
+			errs.add("%s.name is required", entryPath("prompts", i, ""))
 		}
+		validateCapabilities(errs, entryPath("prompts", i, prompt.Name), prompt.RequiresClientCapability)
+		validateGroup(errs, entryPath("prompts", i, prompt.Name), prompt.Group)
 	}
 
-	return nil
+	return errs.err()
+}
+
+// experimentNamePattern matches the experiment names, which name the fields
+// of the generated Flags struct, the encoding names and the group names.
+var experimentNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// envNamePattern matches the names of the environment variables, portable
+// across shells.
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// validateGroup checks the group of an entry, which names a sub-resolver and
+// its file.
+func validateGroup(errs *ValidationError, path, group string) {
+	if group != "" && !experimentNamePattern.MatchString(group) {
+		errs.add("%s.group must start with a letter and contain only letters, digits, - and _", path)
+	}
+}
+
+// validateCapabilities checks the client capabilities an entry requires.
+func validateCapabilities(errs *ValidationError, path string, capabilities []string) {
+	for _, capability := range capabilities {
+		name, experimental := strings.CutPrefix(capability, "experimental.")
+		if capability != "sampling" && capability != "elicitation" && (!experimental || name == "") {
+			errs.add("%s.requiresClientCapability: unknown capability %q, want sampling, elicitation or experimental.<name>", path, capability)
+		}
+	}
 }
 
 func (s *MCPSpec) ResolveSchemaRef(ref string) (*Schema, error) {
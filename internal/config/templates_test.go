@@ -0,0 +1,30 @@
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfigTemplates(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"mcpgen.yaml":          "spec: schema.yaml\ntemplates: codegen\n",
+		"codegen/server.gotpl": "package {{.Package}}\n",
+		"missing/mcpgen.yaml":  "spec: schema.yaml\ntemplates: codegen\n",
+		"file/mcpgen.yaml":     "spec: schema.yaml\ntemplates: codegen\n",
+		"file/codegen":         "not a directory",
+	})
+
+	cfg, err := LoadConfig(filepath.Join(dir, "mcpgen.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "codegen"), cfg.Templates, "templates are relative to the config file")
+
+	_, err = LoadConfig(filepath.Join(dir, "missing", "mcpgen.yaml"))
+	assert.EqualError(t, err, "invalid configuration: templates: "+filepath.Join(dir, "missing", "codegen")+" is not a directory")
+
+	_, err = LoadConfig(filepath.Join(dir, "file", "mcpgen.yaml"))
+	assert.ErrorContains(t, err, "is not a directory")
+}
@@ -0,0 +1,386 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.probo.inc/mcpgen/internal/config"
+	"go.probo.inc/mcpgen/internal/schema"
+)
+
+type ChangeKind string
+
+const (
+	Added   ChangeKind = "added"
+	Removed ChangeKind = "removed"
+	Changed ChangeKind = "changed"
+)
+
+// Change describes a single difference between two specifications.
+type Change struct {
+	Kind     ChangeKind
+	Path     string
+	Message  string
+	Breaking bool
+}
+
+func (c Change) String() string {
+	var marker string
+	switch c.Kind {
+	case Added:
+		marker = "+"
+	case Removed:
+		marker = "-"
+	default:
+		marker = "~"
+	}
+
+	line := fmt.Sprintf("%s %s", marker, c.Path)
+	if c.Message != "" {
+		line += ": " + c.Message
+	}
+	if c.Breaking {
+		line += " [breaking]"
+	}
+	return line
+}
+
+type Report struct {
+	Changes []Change
+}
+
+func (r *Report) HasBreaking() bool {
+	return r.BreakingCount() > 0
+}
+
+func (r *Report) BreakingCount() int {
+	n := 0
+	for _, c := range r.Changes {
+		if c.Breaking {
+			n++
+		}
+	}
+	return n
+}
+
+// direction tells whether a schema describes data sent by the client (input)
+// or returned by the server (output), which decides what counts as breaking.
+type direction int
+
+const (
+	input direction = iota
+	output
+)
+
+type differ struct {
+	oldSpec *config.MCPSpec
+	newSpec *config.MCPSpec
+	report  *Report
+}
+
+// Compare reports the differences between two specifications. Changes are
+// flagged as breaking when existing clients may stop working: removed tools,
+// resources or prompts, new required inputs, removed outputs, and changed
+// types.
+func Compare(oldSpec, newSpec *config.MCPSpec) *Report {
+	d := &differ{
+		oldSpec: oldSpec,
+		newSpec: newSpec,
+		report:  &Report{},
+	}
+
+	d.compareTools()
+	d.compareResources()
+	d.comparePrompts()
+
+	return d.report
+}
+
+func (d *differ) add(kind ChangeKind, path, message string, breaking bool) {
+	d.report.Changes = append(d.report.Changes, Change{
+		Kind:     kind,
+		Path:     path,
+		Message:  message,
+		Breaking: breaking,
+	})
+}
+
+func (d *differ) compareTools() {
+	oldTools := make(map[string]config.Tool)
+	for _, tool := range d.oldSpec.Tools {
+		oldTools[tool.Name] = tool
+	}
+	newTools := make(map[string]config.Tool)
+	for _, tool := range d.newSpec.Tools {
+		newTools[tool.Name] = tool
+	}
+
+	for _, name := range unionKeys(oldTools, newTools) {
+		path := "tools." + name
+		oldTool, inOld := oldTools[name]
+		newTool, inNew := newTools[name]
+
+		switch {
+		case !inNew:
+			d.add(Removed, path, "", true)
+		case !inOld:
+			d.add(Added, path, "", false)
+		default:
+			if oldTool.Description != newTool.Description {
+				d.add(Changed, path, "description changed", false)
+			}
+			d.compareSchema(path+".inputSchema", oldTool.InputSchema, newTool.InputSchema, input)
+			d.compareSchema(path+".outputSchema", oldTool.OutputSchema, newTool.OutputSchema, output)
+		}
+	}
+}
+
+func (d *differ) compareResources() {
+	oldResources := make(map[string]config.Resource)
+	for _, resource := range d.oldSpec.Resources {
+		oldResources[resource.Name] = resource
+	}
+	newResources := make(map[string]config.Resource)
+	for _, resource := range d.newSpec.Resources {
+		newResources[resource.Name] = resource
+	}
+
+	for _, name := range unionKeys(oldResources, newResources) {
+		path := "resources." + name
+		oldResource, inOld := oldResources[name]
+		newResource, inNew := newResources[name]
+
+		switch {
+		case !inNew:
+			d.add(Removed, path, "", true)
+		case !inOld:
+			d.add(Added, path, "", false)
+		default:
+			if oldResource.URI != newResource.URI {
+				d.add(Changed, path+".uri", fmt.Sprintf("changed from %q to %q", oldResource.URI, newResource.URI), true)
+			}
+			if oldResource.URITemplate != newResource.URITemplate {
+				d.add(Changed, path+".uriTemplate", fmt.Sprintf("changed from %q to %q", oldResource.URITemplate, newResource.URITemplate), true)
+			}
+			if oldResource.MimeType != newResource.MimeType {
+				d.add(Changed, path+".mimeType", fmt.Sprintf("changed from %q to %q", oldResource.MimeType, newResource.MimeType), true)
+			}
+			d.compareSchema(path+".schema", oldResource.Schema, newResource.Schema, output)
+		}
+	}
+}
+
+func (d *differ) comparePrompts() {
+	oldPrompts := make(map[string]config.Prompt)
+	for _, prompt := range d.oldSpec.Prompts {
+		oldPrompts[prompt.Name] = prompt
+	}
+	newPrompts := make(map[string]config.Prompt)
+	for _, prompt := range d.newSpec.Prompts {
+		newPrompts[prompt.Name] = prompt
+	}
+
+	for _, name := range unionKeys(oldPrompts, newPrompts) {
+		path := "prompts." + name
+		oldPrompt, inOld := oldPrompts[name]
+		newPrompt, inNew := newPrompts[name]
+
+		switch {
+		case !inNew:
+			d.add(Removed, path, "", true)
+		case !inOld:
+			d.add(Added, path, "", false)
+		default:
+			d.comparePromptArguments(path, oldPrompt.Arguments, newPrompt.Arguments)
+		}
+	}
+}
+
+func (d *differ) comparePromptArguments(path string, oldArgs, newArgs []config.PromptArgument) {
+	oldByName := make(map[string]config.PromptArgument)
+	for _, arg := range oldArgs {
+		oldByName[arg.Name] = arg
+	}
+	newByName := make(map[string]config.PromptArgument)
+	for _, arg := range newArgs {
+		newByName[arg.Name] = arg
+	}
+
+	for _, name := range unionKeys(oldByName, newByName) {
+		argPath := path + ".arguments." + name
+		oldArg, inOld := oldByName[name]
+		newArg, inNew := newByName[name]
+
+		switch {
+		case !inNew:
+			d.add(Removed, argPath, "", true)
+		case !inOld:
+			d.add(Added, argPath, requiredMessage(newArg.Required), newArg.Required)
+		case !oldArg.Required && newArg.Required:
+			d.add(Changed, argPath, "became required", true)
+		case oldArg.Required && !newArg.Required:
+			d.add(Changed, argPath, "became optional", false)
+		}
+	}
+}
+
+func (d *differ) compareSchema(path string, oldSchema, newSchema *config.Schema, dir direction) {
+	d.compareSchemaVisited(path, oldSchema, newSchema, dir, make(map[[2]*config.Schema]bool))
+}
+
+func (d *differ) compareSchemaVisited(path string, oldSchema, newSchema *config.Schema, dir direction, visited map[[2]*config.Schema]bool) {
+	oldSchema = deref(d.oldSpec, oldSchema)
+	newSchema = deref(d.newSpec, newSchema)
+
+	switch {
+	case oldSchema == nil && newSchema == nil:
+		return
+	case oldSchema == nil:
+		// A new output schema constrains nothing clients relied on; a new
+		// input schema on an existing tool does.
+		d.add(Added, path, "", dir == input)
+		return
+	case newSchema == nil:
+		d.add(Removed, path, "", dir == output)
+		return
+	}
+
+	// Guard against recursive schemas
+	key := [2]*config.Schema{oldSchema, newSchema}
+	if visited[key] {
+		return
+	}
+	visited[key] = true
+
+	oldType := typeString(oldSchema)
+	newType := typeString(newSchema)
+	if oldType != newType {
+		d.add(Changed, path, fmt.Sprintf("type changed from %s to %s", oldType, newType), true)
+		return
+	}
+
+	d.compareEnum(path, oldSchema, newSchema, dir)
+
+	for _, name := range unionKeys(oldSchema.Properties, newSchema.Properties) {
+		propPath := path + "." + name
+		oldProp, inOld := oldSchema.Properties[name]
+		newProp, inNew := newSchema.Properties[name]
+		oldRequired := schema.IsRequired(oldSchema, name)
+		newRequired := schema.IsRequired(newSchema, name)
+
+		switch {
+		case !inNew:
+			// Removing an output breaks clients reading it; removing a
+			// required input breaks clients built around it.
+			d.add(Removed, propPath, requiredMessage(oldRequired), dir == output || oldRequired)
+		case !inOld:
+			d.add(Added, propPath, requiredMessage(newRequired), dir == input && newRequired)
+		default:
+			if oldRequired != newRequired {
+				if newRequired {
+					d.add(Changed, propPath, "became required", dir == input)
+				} else {
+					d.add(Changed, propPath, "became optional", dir == output)
+				}
+			}
+			d.compareSchemaVisited(propPath, oldProp, newProp, dir, visited)
+		}
+	}
+
+	if oldSchema.Items != nil || newSchema.Items != nil {
+		d.compareSchemaVisited(path+"[]", oldSchema.Items, newSchema.Items, dir, visited)
+	}
+}
+
+func (d *differ) compareEnum(path string, oldSchema, newSchema *config.Schema, dir direction) {
+	switch {
+	case len(oldSchema.Enum) == 0 && len(newSchema.Enum) == 0:
+		return
+	case len(newSchema.Enum) == 0:
+		// Dropping the constraint accepts more inputs but may return
+		// outputs clients do not expect.
+		d.add(Removed, path, "enum constraint removed", dir == output)
+		return
+	case len(oldSchema.Enum) == 0:
+		d.add(Added, path, "enum constraint added", dir == input)
+		return
+	}
+
+	oldValues := enumValues(oldSchema)
+	newValues := enumValues(newSchema)
+
+	for _, value := range unionKeys(oldValues, newValues) {
+		switch {
+		case !newValues[value]:
+			d.add(Removed, path, fmt.Sprintf("enum value %q removed", value), dir == input)
+		case !oldValues[value]:
+			d.add(Added, path, fmt.Sprintf("enum value %q added", value), dir == output)
+		}
+	}
+}
+
+// deref follows local component references until a concrete schema is found.
+func deref(spec *config.MCPSpec, s *config.Schema) *config.Schema {
+	seen := make(map[string]bool)
+	for config.IsSchemaRef(s) && strings.HasPrefix(s.Ref, "#") && !seen[s.Ref] {
+		seen[s.Ref] = true
+		resolved, err := spec.ResolveSchemaRef(s.Ref)
+		if err != nil || resolved == nil {
+			return s
+		}
+		s = resolved
+	}
+	return s
+}
+
+func typeString(s *config.Schema) string {
+	if s.Ref != "" {
+		return s.Ref
+	}
+	if s.Type != "" {
+		return s.Type
+	}
+	if len(s.Types) > 0 {
+		types := append([]string(nil), s.Types...)
+		sort.Strings(types)
+		return strings.Join(types, "|")
+	}
+	if len(s.Properties) > 0 {
+		return "object"
+	}
+	return "any"
+}
+
+func enumValues(s *config.Schema) map[string]bool {
+	values := make(map[string]bool, len(s.Enum))
+	for _, v := range s.Enum {
+		values[fmt.Sprintf("%v", v)] = true
+	}
+	return values
+}
+
+func requiredMessage(required bool) string {
+	if required {
+		return "required"
+	}
+	return "optional"
+}
+
+// unionKeys returns the sorted keys present in either map.
+func unionKeys[V, W any](a map[string]V, b map[string]W) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		seen[k] = true
+	}
+	for k := range b {
+		seen[k] = true
+	}
+
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,267 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+func baseSpec() *config.MCPSpec {
+	return &config.MCPSpec{
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Components: config.Components{
+			Schemas: map[string]*config.Schema{
+				"TaskInput": {
+					Type: "object",
+					Properties: map[string]*config.Schema{
+						"title":    {Type: "string"},
+						"priority": {Type: "string", Enum: []any{"low", "high"}},
+						"tags":     {Type: "array", Items: &config.Schema{Type: "string"}},
+					},
+					Required: []string{"title"},
+				},
+			},
+		},
+		Tools: []config.Tool{
+			{
+				Name:        "create_task",
+				InputSchema: &config.Schema{Ref: "#/components/schemas/TaskInput"},
+				OutputSchema: &config.Schema{
+					Type: "object",
+					Properties: map[string]*config.Schema{
+						"id": {Type: "string"},
+					},
+					Required: []string{"id"},
+				},
+			},
+		},
+		Resources: []config.Resource{
+			{Name: "readme", URI: "docs://readme"},
+		},
+		Prompts: []config.Prompt{
+			{
+				Name:      "help",
+				Arguments: []config.PromptArgument{{Name: "topic"}},
+			},
+		},
+	}
+}
+
+func findChange(t *testing.T, report *Report, path string) Change {
+	t.Helper()
+	for _, c := range report.Changes {
+		if c.Path == path {
+			return c
+		}
+	}
+	require.Failf(t, "change not found", "no change for path %s in %v", path, report.Changes)
+	return Change{}
+}
+
+func TestCompareIdentical(t *testing.T) {
+	report := Compare(baseSpec(), baseSpec())
+	assert.Empty(t, report.Changes)
+	assert.False(t, report.HasBreaking())
+}
+
+func TestCompareTools(t *testing.T) {
+	t.Run("removed tool is breaking", func(t *testing.T) {
+		newSpec := baseSpec()
+		newSpec.Tools = nil
+
+		report := Compare(baseSpec(), newSpec)
+		c := findChange(t, report, "tools.create_task")
+		assert.Equal(t, Removed, c.Kind)
+		assert.True(t, c.Breaking)
+	})
+
+	t.Run("added tool is not breaking", func(t *testing.T) {
+		newSpec := baseSpec()
+		newSpec.Tools = append(newSpec.Tools, config.Tool{Name: "search", InputSchema: &config.Schema{Type: "object"}})
+
+		report := Compare(baseSpec(), newSpec)
+		c := findChange(t, report, "tools.search")
+		assert.Equal(t, Added, c.Kind)
+		assert.False(t, report.HasBreaking())
+	})
+}
+
+func TestCompareSchemas(t *testing.T) {
+	tests := []struct {
+		name     string
+		mutate   func(spec *config.MCPSpec)
+		path     string
+		kind     ChangeKind
+		breaking bool
+	}{
+		{
+			name: "removed required input",
+			mutate: func(spec *config.MCPSpec) {
+				delete(spec.Components.Schemas["TaskInput"].Properties, "title")
+				spec.Components.Schemas["TaskInput"].Required = nil
+			},
+			path:     "tools.create_task.inputSchema.title",
+			kind:     Removed,
+			breaking: true,
+		},
+		{
+			name: "removed optional input",
+			mutate: func(spec *config.MCPSpec) {
+				delete(spec.Components.Schemas["TaskInput"].Properties, "tags")
+			},
+			path:     "tools.create_task.inputSchema.tags",
+			kind:     Removed,
+			breaking: false,
+		},
+		{
+			name: "new required input",
+			mutate: func(spec *config.MCPSpec) {
+				s := spec.Components.Schemas["TaskInput"]
+				s.Properties["owner"] = &config.Schema{Type: "string"}
+				s.Required = append(s.Required, "owner")
+			},
+			path:     "tools.create_task.inputSchema.owner",
+			kind:     Added,
+			breaking: true,
+		},
+		{
+			name: "input became required",
+			mutate: func(spec *config.MCPSpec) {
+				s := spec.Components.Schemas["TaskInput"]
+				s.Required = append(s.Required, "priority")
+			},
+			path:     "tools.create_task.inputSchema.priority",
+			kind:     Changed,
+			breaking: true,
+		},
+		{
+			name: "changed type",
+			mutate: func(spec *config.MCPSpec) {
+				spec.Components.Schemas["TaskInput"].Properties["title"] = &config.Schema{Type: "integer"}
+			},
+			path:     "tools.create_task.inputSchema.title",
+			kind:     Changed,
+			breaking: true,
+		},
+		{
+			name: "changed array item type",
+			mutate: func(spec *config.MCPSpec) {
+				spec.Components.Schemas["TaskInput"].Properties["tags"].Items = &config.Schema{Type: "integer"}
+			},
+			path:     "tools.create_task.inputSchema.tags[]",
+			kind:     Changed,
+			breaking: true,
+		},
+		{
+			name: "removed input enum value",
+			mutate: func(spec *config.MCPSpec) {
+				spec.Components.Schemas["TaskInput"].Properties["priority"].Enum = []any{"low"}
+			},
+			path:     "tools.create_task.inputSchema.priority",
+			kind:     Removed,
+			breaking: true,
+		},
+		{
+			name: "added input enum value",
+			mutate: func(spec *config.MCPSpec) {
+				spec.Components.Schemas["TaskInput"].Properties["priority"].Enum = []any{"low", "high", "urgent"}
+			},
+			path:     "tools.create_task.inputSchema.priority",
+			kind:     Added,
+			breaking: false,
+		},
+		{
+			name: "removed output property",
+			mutate: func(spec *config.MCPSpec) {
+				spec.Tools[0].OutputSchema.Properties = nil
+				spec.Tools[0].OutputSchema.Required = nil
+			},
+			path:     "tools.create_task.outputSchema.id",
+			kind:     Removed,
+			breaking: true,
+		},
+		{
+			name: "output became optional",
+			mutate: func(spec *config.MCPSpec) {
+				spec.Tools[0].OutputSchema.Required = nil
+			},
+			path:     "tools.create_task.outputSchema.id",
+			kind:     Changed,
+			breaking: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newSpec := baseSpec()
+			tt.mutate(newSpec)
+
+			report := Compare(baseSpec(), newSpec)
+			c := findChange(t, report, tt.path)
+			assert.Equal(t, tt.kind, c.Kind)
+			assert.Equal(t, tt.breaking, c.Breaking)
+		})
+	}
+}
+
+func TestCompareResourcesAndPrompts(t *testing.T) {
+	t.Run("changed resource uri", func(t *testing.T) {
+		newSpec := baseSpec()
+		newSpec.Resources[0].URI = "docs://index"
+
+		c := findChange(t, Compare(baseSpec(), newSpec), "resources.readme.uri")
+		assert.True(t, c.Breaking)
+	})
+
+	t.Run("prompt argument became required", func(t *testing.T) {
+		newSpec := baseSpec()
+		newSpec.Prompts[0].Arguments[0].Required = true
+
+		c := findChange(t, Compare(baseSpec(), newSpec), "prompts.help.arguments.topic")
+		assert.Equal(t, Changed, c.Kind)
+		assert.True(t, c.Breaking)
+	})
+
+	t.Run("new optional prompt argument", func(t *testing.T) {
+		newSpec := baseSpec()
+		newSpec.Prompts[0].Arguments = append(newSpec.Prompts[0].Arguments, config.PromptArgument{Name: "detail"})
+
+		c := findChange(t, Compare(baseSpec(), newSpec), "prompts.help.arguments.detail")
+		assert.False(t, c.Breaking)
+	})
+}
+
+func TestCompareRecursiveSchema(t *testing.T) {
+	spec := func() *config.MCPSpec {
+		return &config.MCPSpec{
+			Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+			Components: config.Components{
+				Schemas: map[string]*config.Schema{
+					"Node": {
+						Type: "object",
+						Properties: map[string]*config.Schema{
+							"next": {Ref: "#/components/schemas/Node"},
+						},
+					},
+				},
+			},
+			Tools: []config.Tool{
+				{Name: "walk", InputSchema: &config.Schema{Ref: "#/components/schemas/Node"}},
+			},
+		}
+	}
+
+	report := Compare(spec(), spec())
+	assert.Empty(t, report.Changes)
+}
+
+func TestChangeString(t *testing.T) {
+	c := Change{Kind: Removed, Path: "tools.x", Message: "gone", Breaking: true}
+	assert.Equal(t, "- tools.x: gone [breaking]", c.String())
+
+	c = Change{Kind: Added, Path: "tools.y"}
+	assert.Equal(t, "+ tools.y", c.String())
+}
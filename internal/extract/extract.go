@@ -0,0 +1,626 @@
+// Package extract derives an MCP spec from annotated Go code.
+//
+// Handler functions are marked with a directive comment in their doc comment:
+//
+//	// Creates an event in the calendar.
+//	//mcp:tool name=create_event idempotent
+//	func (r *Resolver) CreateEventTool(ctx context.Context, req *mcp.CallToolRequest, input *CreateEventInput) (*mcp.CallToolResult, Event, error)
+//
+// The input and output schemas of tools and the arguments of prompts are
+// derived from the structs of their signature, using the json struct tags for
+// property names and the jsonschema struct tags, or field comments, for
+// descriptions.
+package extract
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.probo.inc/mcpgen/internal/config"
+	"go.probo.inc/mcpgen/internal/importer"
+)
+
+// Directives marking declarations.
+const (
+	DirectiveTool     = "mcp:tool"
+	DirectiveResource = "mcp:resource"
+	DirectivePrompt   = "mcp:prompt"
+	DirectiveSchema   = "mcp:schema"
+)
+
+// Options sets the server info of the extracted spec.
+type Options struct {
+	// Title defaults to the package name.
+	Title string
+	// Version defaults to 1.0.0.
+	Version string
+}
+
+// Extract scans the Go files of the package in dir, test files excluded, and
+// returns the spec of its annotated tools, resources and prompts. Structs
+// used by their signatures, and structs marked with //mcp:schema, are added
+// as component schemas.
+//
+// Declarations that cannot be converted are skipped or left unconstrained and
+// reported in the returned warnings.
+func Extract(dir string, opts Options) (*config.MCPSpec, []string, error) {
+	files, pkgName, err := parseDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	e := &extractor{
+		fset:       files.fset,
+		types:      map[string]*ast.TypeSpec{},
+		docs:       map[string]string{},
+		enums:      map[string][]any{},
+		components: map[string]any{},
+		converting: map[string]bool{},
+		names:      importer.Names{},
+	}
+	for _, file := range files.files {
+		e.collectTypes(file)
+	}
+
+	tools := []any{}
+	resources := []any{}
+	prompts := []any{}
+	for _, file := range files.files {
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				directive, args, doc := readDirective(decl.Doc)
+				switch directive {
+				case DirectiveTool:
+					if tool := e.tool(decl, args, doc); tool != nil {
+						tools = append(tools, tool)
+					}
+				case DirectiveResource:
+					if resource := e.resource(decl, args, doc); resource != nil {
+						resources = append(resources, resource)
+					}
+				case DirectivePrompt:
+					prompts = append(prompts, e.prompt(decl, args, doc))
+				case DirectiveSchema:
+					e.warnf(decl.Pos(), "%s only applies to types, ignored", DirectiveSchema)
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					typeSpec, ok := spec.(*ast.TypeSpec)
+					if !ok {
+						continue
+					}
+					doc := typeSpec.Doc
+					if doc == nil && len(decl.Specs) == 1 {
+						doc = decl.Doc
+					}
+					if directive, _, _ := readDirective(doc); directive == DirectiveSchema {
+						e.typeSchema(typeSpec.Name, typeSpec.Name.Name)
+					}
+				}
+			}
+		}
+	}
+
+	if len(tools) == 0 && len(resources) == 0 && len(prompts) == 0 {
+		return nil, nil, fmt.Errorf("no //%s, //%s or //%s directive found in %s", DirectiveTool, DirectiveResource, DirectivePrompt, dir)
+	}
+
+	info := map[string]any{"title": opts.Title, "version": opts.Version}
+	if opts.Title == "" {
+		info["title"] = pkgName
+	}
+	if opts.Version == "" {
+		info["version"] = "1.0.0"
+	}
+
+	spec := map[string]any{"info": info, "tools": tools}
+	if len(resources) > 0 {
+		spec["resources"] = resources
+	}
+	if len(prompts) > 0 {
+		spec["prompts"] = prompts
+	}
+	if len(e.components) > 0 {
+		spec["components"] = map[string]any{"schemas": e.components}
+	}
+
+	result, err := importer.DecodeSpec(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, e.warnings, nil
+}
+
+type parsedFiles struct {
+	fset  *token.FileSet
+	files []*ast.File
+}
+
+func parseDir(dir string) (*parsedFiles, string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	parsed := &parsedFiles{fset: token.NewFileSet()}
+	pkgName := ""
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(parsed.fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, "", err
+		}
+		if pkgName != "" && file.Name.Name != pkgName {
+			return nil, "", fmt.Errorf("%s: found packages %s and %s", dir, pkgName, file.Name.Name)
+		}
+		pkgName = file.Name.Name
+		parsed.files = append(parsed.files, file)
+	}
+
+	if len(parsed.files) == 0 {
+		return nil, "", fmt.Errorf("no Go files in %s", dir)
+	}
+	return parsed, pkgName, nil
+}
+
+// readDirective returns the mcp: directive of a doc comment with its
+// arguments, and the rest of the comment.
+func readDirective(doc *ast.CommentGroup) (string, map[string]string, string) {
+	if doc == nil {
+		return "", nil, ""
+	}
+
+	directive := ""
+	args := map[string]string{}
+	for _, comment := range doc.List {
+		text, ok := strings.CutPrefix(comment.Text, "//mcp:")
+		if !ok {
+			continue
+		}
+		fields := splitArgs(text)
+		if len(fields) == 0 {
+			continue
+		}
+		directive = "mcp:" + fields[0]
+		for _, field := range fields[1:] {
+			key, value, _ := strings.Cut(field, "=")
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				value = unquoted
+			}
+			args[key] = value
+		}
+	}
+
+	// Directive comments are not part of the doc text
+	return directive, args, strings.TrimSpace(doc.Text())
+}
+
+// splitArgs splits directive arguments on spaces, keeping quoted values
+// together.
+func splitArgs(s string) []string {
+	var fields []string
+	var current strings.Builder
+	quoted := false
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			current.WriteRune(r)
+		case r == ' ' && !quoted:
+			if current.Len() > 0 {
+				fields = append(fields, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		fields = append(fields, current.String())
+	}
+	return fields
+}
+
+type extractor struct {
+	fset       *token.FileSet
+	types      map[string]*ast.TypeSpec
+	docs       map[string]string
+	enums      map[string][]any
+	components map[string]any
+	converting map[string]bool
+	names      importer.Names
+	warnings   []string
+}
+
+func (e *extractor) warnf(pos token.Pos, format string, args ...any) {
+	position := e.fset.Position(pos)
+	location := fmt.Sprintf("%s:%d", filepath.Base(position.Filename), position.Line)
+	e.warnings = append(e.warnings, location+": "+fmt.Sprintf(format, args...))
+}
+
+// collectTypes registers the type declarations of a file, their doc
+// comments, and the values of typed constants, used as enums.
+func (e *extractor) collectTypes(file *ast.File) {
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+
+		switch genDecl.Tok {
+		case token.TYPE:
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				e.types[typeSpec.Name.Name] = typeSpec
+				doc := typeSpec.Doc
+				if doc == nil && len(genDecl.Specs) == 1 {
+					doc = genDecl.Doc
+				}
+				_, _, text := readDirective(doc)
+				e.docs[typeSpec.Name.Name] = text
+			}
+		case token.CONST:
+			for _, spec := range genDecl.Specs {
+				valueSpec := spec.(*ast.ValueSpec)
+				ident, ok := valueSpec.Type.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				for _, value := range valueSpec.Values {
+					if lit, ok := value.(*ast.BasicLit); ok {
+						if v, ok := literalValue(lit); ok {
+							e.enums[ident.Name] = append(e.enums[ident.Name], v)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func literalValue(lit *ast.BasicLit) (any, bool) {
+	switch lit.Kind {
+	case token.STRING:
+		s, err := strconv.Unquote(lit.Value)
+		return s, err == nil
+	case token.INT:
+		n, err := strconv.ParseInt(lit.Value, 0, 64)
+		return n, err == nil
+	}
+	return nil, false
+}
+
+func (e *extractor) tool(decl *ast.FuncDecl, args map[string]string, doc string) map[string]any {
+	base := args["name"]
+	if base == "" {
+		base = importer.SnakeCase(strings.TrimSuffix(decl.Name.Name, "Tool"))
+	}
+	name := e.names.Unique(base)
+	if name != base {
+		e.warnf(decl.Pos(), "tool name %s already used, renamed to %s", base, name)
+	}
+
+	tool := map[string]any{"name": name, "inputSchema": map[string]any{"type": "object"}}
+	if doc != "" {
+		tool["description"] = doc
+	}
+
+	hints := map[string]any{}
+	for _, hint := range []string{"readonly", "destructive", "idempotent", "openWorld"} {
+		if _, ok := args[strings.ToLower(hint)]; ok {
+			hints[hint] = true
+		}
+	}
+	if len(hints) > 0 {
+		tool["hints"] = hints
+	}
+
+	// func(ctx, req, input) (result, output, error)
+	if params := decl.Type.Params.List; len(params) > 0 {
+		if input := e.typeSchema(params[len(params)-1].Type, decl.Name.Name+" input"); e.isObject(input) {
+			tool["inputSchema"] = input
+		}
+	}
+	if results := decl.Type.Results; results != nil && len(results.List) == 3 {
+		if output := e.typeSchema(results.List[1].Type, decl.Name.Name+" output"); e.isObject(output) && output["$ref"] != nil {
+			tool["outputSchema"] = output
+		}
+	}
+
+	return tool
+}
+
+func (e *extractor) resource(decl *ast.FuncDecl, args map[string]string, doc string) map[string]any {
+	uri := args["uri"]
+	if uri == "" {
+		e.warnf(decl.Pos(), "%s needs a uri argument, skipped", DirectiveResource)
+		return nil
+	}
+
+	name := args["name"]
+	if name == "" {
+		name = importer.SnakeCase(strings.TrimSuffix(decl.Name.Name, "Resource"))
+	}
+
+	resource := map[string]any{"name": name}
+	if strings.Contains(uri, "{") {
+		resource["uriTemplate"] = uri
+	} else {
+		resource["uri"] = uri
+	}
+	if doc != "" {
+		resource["description"] = doc
+	}
+	if mime := args["mime"]; mime != "" {
+		resource["mimeType"] = mime
+	}
+
+	return resource
+}
+
+func (e *extractor) prompt(decl *ast.FuncDecl, args map[string]string, doc string) map[string]any {
+	name := args["name"]
+	if name == "" {
+		name = importer.SnakeCase(strings.TrimSuffix(decl.Name.Name, "Prompt"))
+	}
+
+	prompt := map[string]any{"name": name}
+	if doc != "" {
+		prompt["description"] = doc
+	}
+
+	// func(ctx, req, args)
+	params := decl.Type.Params.List
+	if len(params) == 0 {
+		return prompt
+	}
+	structType := e.localStruct(params[len(params)-1].Type)
+	if structType == nil {
+		return prompt
+	}
+
+	var arguments []any
+	for _, field := range e.structFields(structType) {
+		argument := map[string]any{"name": field.name}
+		if field.description != "" {
+			argument["description"] = field.description
+		}
+		if field.required {
+			argument["required"] = true
+		}
+		arguments = append(arguments, argument)
+	}
+	if len(arguments) > 0 {
+		prompt["arguments"] = arguments
+	}
+
+	return prompt
+}
+
+// localStruct returns the struct type a type expression refers to, when it
+// is declared in the package.
+func (e *extractor) localStruct(expr ast.Expr) *ast.StructType {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+	if spec := e.types[ident.Name]; spec != nil {
+		structType, _ := spec.Type.(*ast.StructType)
+		return structType
+	}
+	return nil
+}
+
+type structField struct {
+	name        string
+	description string
+	required    bool
+	expr        ast.Expr
+}
+
+// structFields returns the JSON fields of a struct, embedded structs of the
+// package flattened, following the encoding/json rules for names.
+func (e *extractor) structFields(structType *ast.StructType) []structField {
+	var fields []structField
+	for _, field := range structType.Fields.List {
+		tag := reflect.StructTag("")
+		if field.Tag != nil {
+			if s, err := strconv.Unquote(field.Tag.Value); err == nil {
+				tag = reflect.StructTag(s)
+			}
+		}
+		jsonName, jsonOpts, _ := strings.Cut(tag.Get("json"), ",")
+		if jsonName == "-" && jsonOpts == "" {
+			continue
+		}
+
+		if len(field.Names) == 0 {
+			if embedded := e.localStruct(field.Type); embedded != nil && jsonName == "" {
+				fields = append(fields, e.structFields(embedded)...)
+				continue
+			}
+			e.warnf(field.Pos(), "embedded field %s is not a struct of the package, skipped", exprString(field.Type))
+			continue
+		}
+
+		description := tag.Get("jsonschema")
+		if description == "" && field.Doc != nil {
+			description = strings.TrimSpace(field.Doc.Text())
+		}
+		_, pointer := field.Type.(*ast.StarExpr)
+		optional := pointer || slices.Contains(strings.Split(jsonOpts, ","), "omitempty") || slices.Contains(strings.Split(jsonOpts, ","), "omitzero")
+
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			propName := jsonName
+			if propName == "" {
+				propName = name.Name
+			}
+			fields = append(fields, structField{
+				name:        propName,
+				description: description,
+				required:    !optional,
+				expr:        field.Type,
+			})
+		}
+	}
+	return fields
+}
+
+// typeSchema returns the schema of a Go type expression, adding the
+// component schemas of the package types it references.
+func (e *extractor) typeSchema(expr ast.Expr, context string) map[string]any {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return e.typeSchema(t.X, context)
+	case *ast.ArrayType:
+		if ident, ok := t.Elt.(*ast.Ident); ok && ident.Name == "byte" {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]any{"type": "array", "items": e.typeSchema(t.Elt, context)}
+	case *ast.MapType:
+		return map[string]any{"type": "object", "additionalProperties": e.typeSchema(t.Value, context)}
+	case *ast.InterfaceType:
+		return map[string]any{}
+	case *ast.StructType:
+		return e.structSchema(t, context)
+	case *ast.SelectorExpr:
+		switch exprString(t) {
+		case "time.Time":
+			return map[string]any{"type": "string", "format": "date-time"}
+		case "time.Duration":
+			return map[string]any{"type": "integer"}
+		case "json.RawMessage":
+			return map[string]any{}
+		}
+		e.warnf(t.Pos(), "%s: type %s of another package left unconstrained", context, exprString(t))
+		return map[string]any{}
+	case *ast.Ident:
+		if s := basicSchema(t.Name); s != nil {
+			return s
+		}
+		spec := e.types[t.Name]
+		if spec == nil {
+			e.warnf(t.Pos(), "%s: unknown type %s left unconstrained", context, t.Name)
+			return map[string]any{}
+		}
+
+		// The generator cannot expand recursive schemas, so a reference back
+		// to a type being converted is left as a plain object
+		if e.converting[t.Name] {
+			e.warnf(t.Pos(), "%s: recursive reference to %s, left as a plain object", context, t.Name)
+			return map[string]any{"type": "object"}
+		}
+		e.addComponent(spec)
+		return map[string]any{"$ref": "#/components/schemas/" + t.Name}
+	}
+
+	e.warnf(expr.Pos(), "%s: type %s left unconstrained", context, exprString(expr))
+	return map[string]any{}
+}
+
+func (e *extractor) addComponent(spec *ast.TypeSpec) {
+	name := spec.Name.Name
+	if _, ok := e.components[name]; ok {
+		return
+	}
+
+	e.converting[name] = true
+	defer delete(e.converting, name)
+
+	s := e.typeSchema(spec.Type, name)
+	if values := e.enums[name]; len(values) > 0 {
+		s["enum"] = values
+	}
+	if doc := e.docs[name]; doc != "" {
+		s["description"] = doc
+	}
+	e.components[name] = s
+}
+
+func (e *extractor) structSchema(structType *ast.StructType, context string) map[string]any {
+	s := map[string]any{"type": "object"}
+
+	properties := map[string]any{}
+	var required []any
+	for _, field := range e.structFields(structType) {
+		prop := e.typeSchema(field.expr, context+"."+field.name)
+		if field.description != "" {
+			prop["description"] = field.description
+		}
+		properties[field.name] = prop
+		if field.required {
+			required = append(required, field.name)
+		}
+	}
+
+	if len(properties) > 0 {
+		s["properties"] = properties
+	}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+func basicSchema(name string) map[string]any {
+	switch name {
+	case "string":
+		return map[string]any{"type": "string"}
+	case "bool":
+		return map[string]any{"type": "boolean"}
+	case "int", "int8", "int16", "int32", "int64", "rune":
+		return map[string]any{"type": "integer"}
+	case "uint", "uint8", "uint16", "uint32", "uint64", "byte", "uintptr":
+		return map[string]any{"type": "integer", "minimum": 0}
+	case "float32", "float64":
+		return map[string]any{"type": "number"}
+	case "any":
+		return map[string]any{}
+	}
+	return nil
+}
+
+// isObject reports whether s is an object schema or a reference to one.
+func (e *extractor) isObject(s map[string]any) bool {
+	if ref, ok := s["$ref"].(string); ok {
+		component, _ := e.components[strings.TrimPrefix(ref, "#/components/schemas/")].(map[string]any)
+		return component["type"] == "object"
+	}
+	return s["type"] == "object"
+}
+
+// exprString returns the source of a type expression, for messages.
+func exprString(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return exprString(t.X) + "." + t.Sel.Name
+	case *ast.StarExpr:
+		return "*" + exprString(t.X)
+	case *ast.ArrayType:
+		return "[]" + exprString(t.Elt)
+	case *ast.MapType:
+		return "map[" + exprString(t.Key) + "]" + exprString(t.Value)
+	}
+	return fmt.Sprintf("%T", expr)
+}
@@ -0,0 +1,79 @@
+package extract
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtract(t *testing.T) {
+	spec, warnings, err := Extract(filepath.Join("testdata", "calendar"), Options{Version: "0.1.0"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "calendar", spec.Info.Title)
+	assert.Equal(t, "0.1.0", spec.Info.Version)
+
+	require.Len(t, spec.Tools, 2)
+	create := spec.Tools[0]
+	assert.Equal(t, "create_event", create.Name)
+	assert.Equal(t, "Creates an event in the calendar.", create.Description)
+	require.NotNil(t, create.Hints)
+	assert.True(t, create.Hints.Idempotent)
+	assert.Equal(t, "#/components/schemas/CreateEventInput", create.InputSchema.Ref)
+	require.NotNil(t, create.OutputSchema)
+	assert.Equal(t, "#/components/schemas/Event", create.OutputSchema.Ref)
+
+	list := spec.Tools[1]
+	assert.Equal(t, "list_upcoming", list.Name)
+	assert.True(t, list.Hints.Readonly)
+	assert.Equal(t, "object", list.InputSchema.Type)
+	assert.Nil(t, list.OutputSchema)
+
+	input := spec.Components.Schemas["CreateEventInput"]
+	assert.Equal(t, []string{"title", "start"}, input.Required)
+	assert.Equal(t, "The title of the event", input.Properties["title"].Description)
+	assert.Equal(t, "Start of the event.", input.Properties["start"].Description)
+	assert.Equal(t, "date-time", input.Properties["start"].Format)
+	assert.Contains(t, input.Properties, "duration_minutes")
+	assert.NotContains(t, input.Properties, "internal")
+	assert.Equal(t, "#/components/schemas/Attendee", input.Properties["attendees"].Items.Ref)
+
+	visibility := spec.Components.Schemas["Visibility"]
+	assert.Equal(t, "string", visibility.Type)
+	assert.Equal(t, []any{"public", "private"}, visibility.Enum)
+	assert.Equal(t, "Visibility of an event to the other attendees.", visibility.Description)
+
+	event := spec.Components.Schemas["Event"]
+	assert.Equal(t, "An event of the calendar.", event.Description)
+	assert.Equal(t, []string{"id", "title"}, event.Required)
+	assert.Equal(t, "object", event.Properties["parent"].Type)
+
+	assert.Contains(t, spec.Components.Schemas, "Location")
+	assert.Equal(t, "A location, exported even though no handler uses it.", spec.Components.Schemas["Location"].Description)
+
+	require.Len(t, spec.Resources, 1)
+	assert.Equal(t, "day", spec.Resources[0].Name)
+	assert.Equal(t, "calendar://days/{date}", spec.Resources[0].URITemplate)
+	assert.Equal(t, "application/json", spec.Resources[0].MimeType)
+
+	require.Len(t, spec.Prompts, 1)
+	prompt := spec.Prompts[0]
+	assert.Equal(t, "plan_meeting", prompt.Name)
+	require.Len(t, prompt.Arguments, 2)
+	assert.Equal(t, "topic", prompt.Arguments[0].Name)
+	assert.Equal(t, "What the meeting is about", prompt.Arguments[0].Description)
+	assert.True(t, prompt.Arguments[0].Required)
+	assert.False(t, prompt.Arguments[1].Required)
+
+	assert.Equal(t, []string{
+		"types.go:23: CreateEventInput.link: type url.URL of another package left unconstrained",
+		"types.go:36: Event.parent: recursive reference to Event, left as a plain object",
+	}, warnings)
+}
+
+func TestExtractWithoutDirectives(t *testing.T) {
+	_, _, err := Extract(t.TempDir(), Options{})
+	assert.ErrorContains(t, err, "no Go files")
+}
@@ -0,0 +1,40 @@
+package calendar
+
+import (
+	"context"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type Resolver struct{}
+
+// Creates an event in the calendar.
+//
+//mcp:tool idempotent
+func (r *Resolver) CreateEventTool(ctx context.Context, req *mcp.CallToolRequest, input *CreateEventInput) (*mcp.CallToolResult, Event, error) {
+	return nil, Event{}, nil
+}
+
+// Lists the upcoming events.
+//
+//mcp:tool name=list_upcoming readonly
+func (r *Resolver) ListEvents(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, map[string]any, error) {
+	return nil, nil, nil
+}
+
+// The events of a day.
+//
+//mcp:resource uri=calendar://days/{date} mime=application/json
+func (r *Resolver) DayResource(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	return nil, nil
+}
+
+// Plans a meeting.
+//
+//mcp:prompt
+func (r *Resolver) PlanMeetingPrompt(ctx context.Context, req *mcp.GetPromptRequest, args PlanMeetingArgs) (*mcp.GetPromptResult, error) {
+	return nil, nil
+}
+
+func (r *Resolver) helper(start time.Time) {}
@@ -0,0 +1,53 @@
+package calendar
+
+import (
+	"net/url"
+	"time"
+)
+
+// Visibility of an event to the other attendees.
+type Visibility string
+
+const (
+	VisibilityPublic  Visibility = "public"
+	VisibilityPrivate Visibility = "private"
+)
+
+type CreateEventInput struct {
+	Title string `json:"title" jsonschema:"The title of the event"`
+	// Start of the event.
+	Start      time.Time  `json:"start"`
+	Duration   int        `json:"duration_minutes,omitempty"`
+	Visibility Visibility `json:"visibility,omitempty"`
+	Attendees  []Attendee `json:"attendees,omitempty"`
+	Link       *url.URL   `json:"link,omitempty"`
+	internal   string
+}
+
+type Attendee struct {
+	Email    string `json:"email"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// An event of the calendar.
+type Event struct {
+	Base
+	Title  string `json:"title"`
+	Parent *Event `json:"parent,omitempty"`
+}
+
+type Base struct {
+	ID string `json:"id"`
+}
+
+type PlanMeetingArgs struct {
+	Topic string `json:"topic" jsonschema:"What the meeting is about"`
+	Notes string `json:"notes,omitempty"`
+}
+
+// A location, exported even though no handler uses it.
+//
+//mcp:schema
+type Location struct {
+	Name string `json:"name"`
+}
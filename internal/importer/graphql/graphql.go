@@ -0,0 +1,342 @@
+package graphql
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"go.probo.inc/mcpgen/internal/config"
+	"go.probo.inc/mcpgen/internal/importer"
+)
+
+// Options selects the root fields to import. An empty filter matches every
+// query and mutation.
+type Options struct {
+	// Operations keeps these query and mutation fields, as field or
+	// Type.field.
+	Operations []string
+}
+
+// scalarSchemas maps the built-in GraphQL scalars, and common custom scalar
+// names, to JSON Schema. Other custom scalars are left unconstrained.
+var scalarSchemas = map[string]map[string]any{
+	"Int":      {"type": "integer", "format": "int32"},
+	"Float":    {"type": "number"},
+	"String":   {"type": "string"},
+	"Boolean":  {"type": "boolean"},
+	"ID":       {"type": "string"},
+	"Time":     {"type": "string", "format": "date-time"},
+	"DateTime": {"type": "string", "format": "date-time"},
+	"Date":     {"type": "string", "format": "date"},
+	"UUID":     {"type": "string", "format": "uuid"},
+	"URL":      {"type": "string", "format": "uri"},
+	"URI":      {"type": "string", "format": "uri"},
+	"Map":      {"type": "object"},
+}
+
+// Import converts GraphQL SDL documents into an MCP spec. Every field of the
+// query and mutation types becomes a tool taking its arguments as input and
+// returning its type, when that is an object. Input, object and enum types
+// are added as component schemas, with descriptions copied. Type extensions
+// are merged, across documents too.
+//
+// Subscriptions, unions and recursive references are approximated or
+// skipped and reported in the returned warnings.
+func Import(docs []*Document, opts Options) (*config.MCPSpec, []string, error) {
+	c := &converter{
+		definitions: map[string]*Definition{},
+		components:  map[string]any{},
+		converting:  map[string]bool{},
+		reserved:    map[string]bool{},
+		names:       importer.Names{},
+	}
+	if err := c.merge(docs); err != nil {
+		return nil, nil, err
+	}
+
+	query, mutation, subscription := "Query", "Mutation", "Subscription"
+	description := ""
+	for _, doc := range docs {
+		if doc.Query != "" {
+			query = doc.Query
+		}
+		if doc.Mutation != "" {
+			mutation = doc.Mutation
+		}
+		if doc.Subscription != "" {
+			subscription = doc.Subscription
+		}
+		if doc.Description != "" {
+			description = doc.Description
+		}
+	}
+
+	if c.definitions[query] == nil && c.definitions[mutation] == nil {
+		return nil, nil, fmt.Errorf("no %s or %s type found", query, mutation)
+	}
+	if def := c.definitions[subscription]; def != nil && len(def.Fields) > 0 {
+		c.warnf(subscription, "subscriptions are not supported, skipped")
+	}
+
+	type operation struct {
+		root  string
+		field *Field
+		name  string
+	}
+	var operations []operation
+	for _, root := range []string{query, mutation} {
+		def := c.definitions[root]
+		if def == nil {
+			continue
+		}
+		for _, field := range def.Fields {
+			if len(opts.Operations) > 0 && !slices.Contains(opts.Operations, field.Name) && !slices.Contains(opts.Operations, root+"."+field.Name) {
+				continue
+			}
+
+			base := importer.SnakeCase(field.Name)
+			name := c.names.Unique(base)
+			if name != base {
+				c.warnf(root+"."+field.Name, "tool name %s already used, renamed to %s", base, name)
+			}
+			operations = append(operations, operation{root, field, name})
+
+			// The generator names the input and output types of a tool
+			// after it, which GraphQL input types commonly clash with
+			c.reserved[pascalCase(name)+"Input"] = true
+			c.reserved[pascalCase(name)+"Output"] = true
+		}
+	}
+
+	tools := []any{}
+	for _, op := range operations {
+		tools = append(tools, c.tool(op.root, op.field, op.name, op.root == query))
+	}
+
+	title := "API"
+	if len(docs) > 0 && docs[0].Name != "" {
+		title = strings.TrimSuffix(filepath.Base(docs[0].Name), filepath.Ext(docs[0].Name))
+	}
+	info := map[string]any{"title": title, "version": "1.0.0"}
+	if description != "" {
+		info["description"] = description
+	}
+
+	spec := map[string]any{"info": info, "tools": tools}
+	if len(c.components) > 0 {
+		spec["components"] = map[string]any{"schemas": c.components}
+	}
+
+	result, err := importer.DecodeSpec(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, c.warnings, nil
+}
+
+type converter struct {
+	definitions map[string]*Definition
+	components  map[string]any
+	converting  map[string]bool
+	reserved    map[string]bool
+	names       importer.Names
+	warnings    []string
+}
+
+func (c *converter) warnf(path, format string, args ...any) {
+	c.warnings = append(c.warnings, path+": "+fmt.Sprintf(format, args...))
+}
+
+// merge registers the type definitions of every document, then applies the
+// extensions. An extension of an undefined type, as federated subgraphs
+// declare their root types, defines it.
+func (c *converter) merge(docs []*Document) error {
+	var extensions []*Definition
+	for _, doc := range docs {
+		for _, def := range doc.Definitions {
+			if def.Extend {
+				extensions = append(extensions, def)
+				continue
+			}
+			if c.definitions[def.Name] != nil {
+				return fmt.Errorf("%s: type %s is defined more than once", doc.Name, def.Name)
+			}
+			copied := *def
+			c.definitions[def.Name] = &copied
+		}
+	}
+
+	for _, ext := range extensions {
+		def := c.definitions[ext.Name]
+		if def == nil {
+			copied := *ext
+			c.definitions[ext.Name] = &copied
+			continue
+		}
+		if def.Kind != ext.Kind {
+			return fmt.Errorf("extend %s %s: %s is a %s", ext.Kind, ext.Name, ext.Name, def.Kind)
+		}
+		def.Fields = append(slices.Clip(def.Fields), ext.Fields...)
+		def.Values = append(slices.Clip(def.Values), ext.Values...)
+		def.Types = append(slices.Clip(def.Types), ext.Types...)
+	}
+
+	return nil
+}
+
+func (c *converter) tool(root string, field *Field, name string, query bool) map[string]any {
+	path := root + "." + field.Name
+
+	tool := map[string]any{"name": name, "inputSchema": c.objectSchema(path, field.Arguments)}
+	if field.Description != "" {
+		tool["description"] = field.Description
+	}
+	// Query fields must not have side effects
+	if query {
+		tool["hints"] = map[string]any{"readonly": true, "idempotent": true}
+	}
+
+	output := c.typeSchema(path, field.Type)
+	if c.isObject(output) {
+		tool["outputSchema"] = output
+	} else {
+		c.warnf(path, "type %s is not an object, output schema omitted", field.Type)
+	}
+
+	return tool
+}
+
+// objectSchema returns the object schema of the fields of a type, or of
+// arguments. Non-null fields without default are required.
+func (c *converter) objectSchema(path string, fields []*Field) map[string]any {
+	s := map[string]any{"type": "object"}
+
+	properties := map[string]any{}
+	var required []any
+	for _, field := range fields {
+		prop := c.typeSchema(path+"."+field.Name, field.Type)
+		if field.Description != "" {
+			prop["description"] = field.Description
+		}
+		if field.HasDefault {
+			prop["default"] = field.Default
+		}
+		if field.Deprecated {
+			prop["deprecated"] = true
+		}
+
+		properties[field.Name] = prop
+		if field.Type.NonNull && !field.HasDefault {
+			required = append(required, field.Name)
+		}
+	}
+
+	if len(properties) > 0 {
+		s["properties"] = properties
+	}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+// typeSchema returns the schema of a type reference, adding the component
+// schemas it references.
+func (c *converter) typeSchema(path string, t *Type) map[string]any {
+	if t.Elem != nil {
+		return map[string]any{"type": "array", "items": c.typeSchema(path, t.Elem)}
+	}
+
+	if s, ok := scalarSchemas[t.Name]; ok {
+		return copySchema(s)
+	}
+
+	def := c.definitions[t.Name]
+	if def == nil {
+		c.warnf(path, "unknown type %s, left unconstrained", t.Name)
+		return map[string]any{}
+	}
+	if def.Kind == KindScalar {
+		s := map[string]any{}
+		if def.Description != "" {
+			s["description"] = def.Description
+		}
+		return s
+	}
+
+	// The generator cannot expand recursive schemas, so a reference back to
+	// a type being converted is left as a plain object
+	if c.converting[def.Name] {
+		c.warnf(path, "recursive reference to %s, left as a plain object", def.Name)
+		return map[string]any{"type": "object"}
+	}
+
+	c.addComponent(def)
+	return map[string]any{"$ref": "#/components/schemas/" + c.componentName(def.Name)}
+}
+
+// componentName returns the component schema name of a type: its name, with
+// a Type suffix when that clashes with the generated type of a tool.
+func (c *converter) componentName(name string) string {
+	if c.reserved[name] {
+		return name + "Type"
+	}
+	return name
+}
+
+func (c *converter) addComponent(def *Definition) {
+	if _, ok := c.components[c.componentName(def.Name)]; ok {
+		return
+	}
+
+	var s map[string]any
+	switch def.Kind {
+	case KindEnum:
+		values := make([]any, 0, len(def.Values))
+		for _, v := range def.Values {
+			values = append(values, v.Name)
+		}
+		s = map[string]any{"type": "string", "enum": values}
+	case KindUnion:
+		c.warnf(def.Name, "unions are not supported, imported as a plain object")
+		s = map[string]any{"type": "object"}
+	default:
+		c.converting[def.Name] = true
+		defer delete(c.converting, def.Name)
+		s = c.objectSchema(def.Name, def.Fields)
+	}
+
+	if def.Description != "" {
+		s["description"] = def.Description
+	}
+	c.components[c.componentName(def.Name)] = s
+}
+
+// isObject reports whether s is a reference to an object type or an object
+// schema.
+func (c *converter) isObject(s map[string]any) bool {
+	if ref, ok := s["$ref"].(string); ok {
+		component, _ := c.components[strings.TrimPrefix(ref, "#/components/schemas/")].(map[string]any)
+		return component["type"] == "object"
+	}
+	return s["type"] == "object"
+}
+
+func pascalCase(name string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part != "" {
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
+
+func copySchema(s map[string]any) map[string]any {
+	result := make(map[string]any, len(s))
+	for k, v := range s {
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,119 @@
+package graphql
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseTestdata(t *testing.T, names ...string) []*Document {
+	t.Helper()
+
+	docs := make([]*Document, 0, len(names))
+	for _, name := range names {
+		src, err := os.ReadFile(filepath.Join("testdata", name))
+		require.NoError(t, err)
+
+		doc, err := Parse(name, src)
+		require.NoError(t, err)
+		docs = append(docs, doc)
+	}
+
+	return docs
+}
+
+func TestImport(t *testing.T) {
+	docs := parseTestdata(t, "blog.graphql")
+
+	spec, warnings, err := Import(docs, Options{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "blog", spec.Info.Title)
+	assert.Equal(t, "A blogging platform.", spec.Info.Description)
+
+	names := make([]string, 0, len(spec.Tools))
+	for _, tool := range spec.Tools {
+		names = append(names, tool.Name)
+	}
+	assert.Equal(t, []string{"post", "posts", "search", "create_post"}, names)
+
+	post := spec.Tools[0]
+	assert.Equal(t, "Get a post by its ID.", post.Description)
+	require.NotNil(t, post.Hints)
+	assert.True(t, post.Hints.Readonly)
+	assert.Equal(t, []string{"id"}, post.InputSchema.Required)
+	assert.Equal(t, "string", post.InputSchema.Properties["id"].Type)
+	require.NotNil(t, post.OutputSchema)
+	assert.Equal(t, "#/components/schemas/Post", post.OutputSchema.Ref)
+
+	posts := spec.Tools[1]
+	assert.Equal(t, "List the posts of an author.", posts.Description)
+	assert.Equal(t, []string{"authorId"}, posts.InputSchema.Required)
+	first := posts.InputSchema.Properties["first"]
+	assert.Equal(t, "integer", first.Type)
+	assert.JSONEq(t, "10", string(first.Default))
+	assert.Equal(t, "A scalar without well-known format.", posts.InputSchema.Properties["after"].Description)
+	// Lists cannot be structured content
+	assert.Nil(t, posts.OutputSchema)
+
+	create := spec.Tools[3]
+	assert.Nil(t, create.Hints)
+	// Renamed not to clash with the generated input type of create_post
+	assert.Equal(t, "#/components/schemas/CreatePostInputType", create.InputSchema.Properties["input"].Ref)
+
+	input := spec.Components.Schemas["CreatePostInputType"]
+	assert.Equal(t, []string{"title", "body"}, input.Required)
+	assert.Equal(t, "The title of the post.", input.Properties["title"].Description)
+	assert.Equal(t, "array", input.Properties["tags"].Type)
+
+	postSchema := spec.Components.Schemas["Post"]
+	assert.Equal(t, "A blog post.", postSchema.Description)
+	assert.Equal(t, []string{"id", "title", "status", "author"}, postSchema.Required)
+	assert.Equal(t, "date-time", postSchema.Properties["publishedAt"].Format)
+	assert.True(t, postSchema.Properties["legacyId"].Deprecated)
+	assert.Equal(t, "#/components/schemas/User", postSchema.Properties["author"].Ref)
+	assert.Equal(t, []any{"DRAFT", "PUBLISHED", "ARCHIVED"}, spec.Components.Schemas["PostStatus"].Enum)
+
+	assert.Equal(t, []string{
+		"Subscription: subscriptions are not supported, skipped",
+		"User.posts: recursive reference to Post, left as a plain object",
+		"Query.posts: type [Post!]! is not an object, output schema omitted",
+		"SearchResult: unions are not supported, imported as a plain object",
+	}, warnings)
+}
+
+func TestImportExtensions(t *testing.T) {
+	docs := parseTestdata(t, "blog.graphql", "extensions.graphql")
+
+	spec, _, err := Import(docs, Options{Operations: []string{"Query.me", "createPost"}})
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(spec.Tools))
+	for _, tool := range spec.Tools {
+		names = append(names, tool.Name)
+	}
+	assert.Equal(t, []string{"me", "create_post"}, names)
+	assert.Equal(t, []any{"DRAFT", "PUBLISHED", "ARCHIVED", "SCHEDULED"}, spec.Components.Schemas["PostStatus"].Enum)
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{name: "unterminated type", src: "type Query {\n  a: String\n", want: `a.graphql: unexpected end of file: expected "}"`},
+		{name: "missing type", src: "type Query {\n  a:\n}\n", want: `a.graphql: line 3: expected a name, found "}"`},
+		{name: "operation", src: "query {\n  a\n}\n", want: "a.graphql: line 1: executable definitions are not supported, pass a schema"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse("a.graphql", []byte(tt.src))
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
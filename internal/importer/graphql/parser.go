@@ -0,0 +1,680 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Kind is the kind of a GraphQL type definition.
+type Kind string
+
+const (
+	KindObject    Kind = "type"
+	KindInterface Kind = "interface"
+	KindUnion     Kind = "union"
+	KindEnum      Kind = "enum"
+	KindInput     Kind = "input"
+	KindScalar    Kind = "scalar"
+)
+
+// Document is the subset of a parsed GraphQL SDL document the importer uses.
+type Document struct {
+	Name string
+	// Description is the description of the schema definition.
+	Description string
+	Definitions []*Definition
+	// Query, Mutation and Subscription name the root operation types set
+	// by a schema definition, if any.
+	Query        string
+	Mutation     string
+	Subscription string
+}
+
+// Definition is a type definition, or a type extension when Extend is set.
+type Definition struct {
+	Kind        Kind
+	Name        string
+	Description string
+	Extend      bool
+	// Fields holds the fields of object, interface and input types.
+	Fields []*Field
+	// Values holds the values of enums.
+	Values []*EnumValue
+	// Types holds the members of unions.
+	Types []string
+}
+
+// Field is a field of an object, interface or input type, or an argument.
+type Field struct {
+	Name        string
+	Description string
+	Type        *Type
+	Arguments   []*Field
+	// Default is the default value of input fields and arguments, when
+	// HasDefault is set.
+	Default    any
+	HasDefault bool
+	Deprecated bool
+}
+
+type EnumValue struct {
+	Name        string
+	Description string
+	Deprecated  bool
+}
+
+// Type is a type reference: a named type, or a list of Elem, possibly
+// non-null.
+type Type struct {
+	Name    string
+	Elem    *Type
+	NonNull bool
+}
+
+func (t *Type) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenName
+	tokenPunct
+	tokenString
+	tokenNumber
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	line int
+}
+
+// Parse reads a GraphQL SDL document. Directive definitions are skipped and
+// directives ignored, except @deprecated; executable definitions are not
+// supported.
+func Parse(name string, src []byte) (*Document, error) {
+	tokens, err := tokenize(string(src))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	p := &parser{tokens: tokens}
+	doc, err := p.document()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	doc.Name = name
+	return doc, nil
+}
+
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	line := 1
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case strings.HasPrefix(src[i:], "\ufeff"):
+			i += len("\ufeff")
+		case c == ',' || unicode.IsSpace(rune(c)):
+			i++
+		case c == '#':
+			end := strings.IndexByte(src[i:], '\n')
+			if end == -1 {
+				end = len(src) - i
+			}
+			i += end
+		case strings.HasPrefix(src[i:], `"""`):
+			end := strings.Index(src[i+3:], `"""`)
+			for end != -1 && src[i+3+end-1] == '\\' {
+				next := strings.Index(src[i+3+end+3:], `"""`)
+				if next == -1 {
+					end = -1
+					break
+				}
+				end += 3 + next
+			}
+			if end == -1 {
+				return nil, fmt.Errorf("line %d: unterminated block string", line)
+			}
+			raw := src[i+3 : i+3+end]
+			tokens = append(tokens, token{kind: tokenString, text: blockString(raw), line: line})
+			line += strings.Count(raw, "\n")
+			i += end + 6
+		case c == '"':
+			j := i + 1
+			for ; j < len(src) && src[j] != '"'; j++ {
+				if src[j] == '\\' {
+					j++
+				}
+				if j < len(src) && src[j] == '\n' {
+					return nil, fmt.Errorf("line %d: unterminated string", line)
+				}
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			text, err := strconv.Unquote(src[i : j+1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid string %s", line, src[i:j+1])
+			}
+			tokens = append(tokens, token{kind: tokenString, text: text, line: line})
+			i = j + 1
+		case c == '-' || ('0' <= c && c <= '9'):
+			j := i + 1
+			for j < len(src) && strings.IndexByte("0123456789.eE+-", src[j]) >= 0 {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: src[i:j], line: line})
+			i = j
+		case isNameChar(c):
+			j := i
+			for j < len(src) && (isNameChar(src[j]) || ('0' <= src[j] && src[j] <= '9')) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenName, text: src[i:j], line: line})
+			i = j
+		case strings.HasPrefix(src[i:], "..."):
+			tokens = append(tokens, token{kind: tokenPunct, text: "...", line: line})
+			i += 3
+		case strings.IndexByte("!$&()=:@[]{}|", c) >= 0:
+			tokens = append(tokens, token{kind: tokenPunct, text: string(c), line: line})
+			i++
+		default:
+			return nil, fmt.Errorf("line %d: unexpected character %q", line, c)
+		}
+	}
+
+	return tokens, nil
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// blockString returns the value of a block string: its common indentation
+// and leading and trailing blank lines removed.
+func blockString(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, `\"""`, `"""`), "\n")
+
+	indent := -1
+	for _, l := range lines[1:] {
+		trimmed := strings.TrimLeft(l, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(l) - len(trimmed); indent == -1 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return token{}
+}
+
+func (p *parser) next() token {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+// is reports whether the next token is the punctuator text.
+func (p *parser) is(text string) bool {
+	tok := p.peek()
+	return tok.kind == tokenPunct && tok.text == text
+}
+
+func (p *parser) expect(text string) error {
+	tok := p.next()
+	if tok.kind != tokenPunct || tok.text != text {
+		return p.errorf(tok, "expected %q, found %q", text, tok.text)
+	}
+	return nil
+}
+
+func (p *parser) name() (string, error) {
+	tok := p.next()
+	if tok.kind != tokenName {
+		return "", p.errorf(tok, "expected a name, found %q", tok.text)
+	}
+	return tok.text, nil
+}
+
+func (p *parser) errorf(tok token, format string, args ...any) error {
+	if tok.line == 0 {
+		return fmt.Errorf("unexpected end of file: "+format, args...)
+	}
+	return fmt.Errorf("line %d: "+format, append([]any{tok.line}, args...)...)
+}
+
+// description reads the optional description before a definition.
+func (p *parser) description() string {
+	if p.peek().kind == tokenString {
+		return p.next().text
+	}
+	return ""
+}
+
+func (p *parser) document() (*Document, error) {
+	doc := &Document{}
+	for !p.done() {
+		description := p.description()
+
+		tok := p.next()
+		if tok.kind != tokenName {
+			return nil, p.errorf(tok, "expected a definition, found %q", tok.text)
+		}
+
+		extend := tok.text == "extend"
+		if extend {
+			tok = p.next()
+		}
+
+		switch tok.text {
+		case "schema":
+			if err := p.schema(doc); err != nil {
+				return nil, err
+			}
+			if description != "" {
+				doc.Description = description
+			}
+		case "directive":
+			if err := p.skipDirectiveDefinition(); err != nil {
+				return nil, err
+			}
+		case "type", "interface", "union", "enum", "input", "scalar":
+			def, err := p.definition(Kind(tok.text))
+			if err != nil {
+				return nil, err
+			}
+			def.Description = description
+			def.Extend = extend
+			doc.Definitions = append(doc.Definitions, def)
+		case "query", "mutation", "subscription", "fragment":
+			return nil, p.errorf(tok, "executable definitions are not supported, pass a schema")
+		default:
+			return nil, p.errorf(tok, "unexpected %q", tok.text)
+		}
+	}
+
+	return doc, nil
+}
+
+func (p *parser) schema(doc *Document) error {
+	if _, err := p.directives(); err != nil {
+		return err
+	}
+	if !p.is("{") {
+		return nil
+	}
+	p.next()
+
+	for !p.is("}") {
+		if p.done() {
+			return p.errorf(p.peek(), "expected \"}\"")
+		}
+		operation := p.next()
+		if err := p.expect(":"); err != nil {
+			return err
+		}
+		name, err := p.name()
+		if err != nil {
+			return err
+		}
+		switch operation.text {
+		case "query":
+			doc.Query = name
+		case "mutation":
+			doc.Mutation = name
+		case "subscription":
+			doc.Subscription = name
+		default:
+			return p.errorf(operation, "unknown operation type %q", operation.text)
+		}
+	}
+	p.next()
+
+	return nil
+}
+
+func (p *parser) skipDirectiveDefinition() error {
+	if err := p.expect("@"); err != nil {
+		return err
+	}
+	if _, err := p.name(); err != nil {
+		return err
+	}
+	if p.is("(") {
+		if _, err := p.fields("(", ")"); err != nil {
+			return err
+		}
+	}
+	if p.peek().text == "repeatable" {
+		p.next()
+	}
+	if tok := p.next(); tok.text != "on" {
+		return p.errorf(tok, "expected \"on\", found %q", tok.text)
+	}
+	if p.is("|") {
+		p.next()
+	}
+	for {
+		if _, err := p.name(); err != nil {
+			return err
+		}
+		if !p.is("|") {
+			return nil
+		}
+		p.next()
+	}
+}
+
+func (p *parser) definition(kind Kind) (*Definition, error) {
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	def := &Definition{Kind: kind, Name: name}
+
+	if p.peek().text == "implements" {
+		p.next()
+		if p.is("&") {
+			p.next()
+		}
+		for {
+			if _, err := p.name(); err != nil {
+				return nil, err
+			}
+			if !p.is("&") {
+				break
+			}
+			p.next()
+		}
+	}
+
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case KindObject, KindInterface, KindInput:
+		if p.is("{") {
+			if def.Fields, err = p.fields("{", "}"); err != nil {
+				return nil, err
+			}
+		}
+	case KindEnum:
+		if p.is("{") {
+			if def.Values, err = p.enumValues(); err != nil {
+				return nil, err
+			}
+		}
+	case KindUnion:
+		if p.is("=") {
+			p.next()
+			if p.is("|") {
+				p.next()
+			}
+			for {
+				member, err := p.name()
+				if err != nil {
+					return nil, err
+				}
+				def.Types = append(def.Types, member)
+				if !p.is("|") {
+					break
+				}
+				p.next()
+			}
+		}
+	}
+
+	return def, nil
+}
+
+// fields reads the fields of a type, or arguments, between open and close.
+func (p *parser) fields(open, close string) ([]*Field, error) {
+	if err := p.expect(open); err != nil {
+		return nil, err
+	}
+
+	var fields []*Field
+	for !p.is(close) {
+		if p.done() {
+			return nil, p.errorf(p.peek(), "expected %q", close)
+		}
+
+		field := &Field{Description: p.description()}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		field.Name = name
+
+		if p.is("(") {
+			if field.Arguments, err = p.fields("(", ")"); err != nil {
+				return nil, err
+			}
+		}
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+		if field.Type, err = p.typeRef(); err != nil {
+			return nil, err
+		}
+		if p.is("=") {
+			p.next()
+			if field.Default, err = p.value(); err != nil {
+				return nil, err
+			}
+			field.HasDefault = true
+		}
+		if field.Deprecated, err = p.directives(); err != nil {
+			return nil, err
+		}
+
+		fields = append(fields, field)
+	}
+	p.next()
+
+	return fields, nil
+}
+
+func (p *parser) enumValues() ([]*EnumValue, error) {
+	p.next()
+
+	var values []*EnumValue
+	for !p.is("}") {
+		if p.done() {
+			return nil, p.errorf(p.peek(), "expected \"}\"")
+		}
+
+		value := &EnumValue{Description: p.description()}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		value.Name = name
+		if value.Deprecated, err = p.directives(); err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
+	}
+	p.next()
+
+	return values, nil
+}
+
+func (p *parser) typeRef() (*Type, error) {
+	var t *Type
+	if p.is("[") {
+		p.next()
+		elem, err := p.typeRef()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		t = &Type{Elem: elem}
+	} else {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		t = &Type{Name: name}
+	}
+
+	if p.is("!") {
+		p.next()
+		t.NonNull = true
+	}
+	return t, nil
+}
+
+// directives skips the directives applied to a definition and reports
+// whether one is @deprecated.
+func (p *parser) directives() (bool, error) {
+	deprecated := false
+	for p.is("@") {
+		p.next()
+		name, err := p.name()
+		if err != nil {
+			return false, err
+		}
+		if name == "deprecated" {
+			deprecated = true
+		}
+		if p.is("(") {
+			p.next()
+			for !p.is(")") {
+				if p.done() {
+					return false, p.errorf(p.peek(), "expected \")\"")
+				}
+				if _, err := p.name(); err != nil {
+					return false, err
+				}
+				if err := p.expect(":"); err != nil {
+					return false, err
+				}
+				if _, err := p.value(); err != nil {
+					return false, err
+				}
+			}
+			p.next()
+		}
+	}
+	return deprecated, nil
+}
+
+// value reads a constant value, enum values being returned as strings.
+func (p *parser) value() (any, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenString:
+		return tok.text, nil
+	case tokenNumber:
+		if n, err := strconv.ParseInt(tok.text, 10, 64); err == nil {
+			return n, nil
+		}
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, p.errorf(tok, "invalid number %q", tok.text)
+		}
+		return n, nil
+	case tokenName:
+		switch tok.text {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return tok.text, nil
+	}
+
+	switch tok.text {
+	case "[":
+		list := []any{}
+		for !p.is("]") {
+			if p.done() {
+				return nil, p.errorf(p.peek(), "expected \"]\"")
+			}
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		p.next()
+		return list, nil
+	case "{":
+		object := map[string]any{}
+		for !p.is("}") {
+			if p.done() {
+				return nil, p.errorf(p.peek(), "expected \"}\"")
+			}
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			if object[name], err = p.value(); err != nil {
+				return nil, err
+			}
+		}
+		p.next()
+		return object, nil
+	}
+
+	return nil, p.errorf(tok, "expected a value, found %q", tok.text)
+}
@@ -0,0 +1,69 @@
+// Package importer holds the helpers shared by the importers converting
+// other API descriptions, such as OpenAPI documents, into MCP specs.
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+// DecodeSpec converts an MCP spec built as generic values, with the keys of
+// the spec file, into a validated config.MCPSpec.
+func DecodeSpec(spec map[string]any) (*config.MCPSpec, error) {
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode spec: %w", err)
+	}
+
+	result := &config.MCPSpec{}
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, fmt.Errorf("failed to decode spec: %w", err)
+	}
+	if err := result.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid MCP specification: %w", err)
+	}
+
+	return result, nil
+}
+
+// SnakeCase converts an identifier such as listUsers, list-users or
+// "get /users/{id}" to snake_case.
+func SnakeCase(s string) string {
+	var b strings.Builder
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case unicode.IsUpper(r):
+			if i > 0 && (unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1]) ||
+				(i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+
+	parts := strings.FieldsFunc(b.String(), func(r rune) bool { return r == '_' })
+	return strings.Join(parts, "_")
+}
+
+// Names hands out unique tool names.
+type Names map[string]bool
+
+// Unique returns base, or base with a numeric suffix when it is already
+// taken, and reserves it.
+func (n Names) Unique(base string) string {
+	name := base
+	for i := 2; n[name]; i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	n[name] = true
+	return name
+}
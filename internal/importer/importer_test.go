@@ -0,0 +1,27 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"listPets":          "list_pets",
+		"delete-pet":        "delete_pet",
+		"getHTTPResponse":   "get_http_response",
+		"get /pets/{petId}": "get_pets_pet_id",
+		"Users.Create":      "users_create",
+	}
+	for input, want := range tests {
+		assert.Equal(t, want, SnakeCase(input), input)
+	}
+}
+
+func TestNamesUnique(t *testing.T) {
+	names := Names{}
+	assert.Equal(t, "list", names.Unique("list"))
+	assert.Equal(t, "list_2", names.Unique("list"))
+	assert.Equal(t, "list_3", names.Unique("list"))
+}
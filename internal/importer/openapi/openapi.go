@@ -0,0 +1,544 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.probo.inc/mcpgen/internal/config"
+	"go.probo.inc/mcpgen/internal/importer"
+	"go.probo.inc/mcpgen/internal/schema"
+	"gopkg.in/yaml.v3"
+)
+
+// HTTP methods of a path item, in the order operations are converted.
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Options selects the operations to import. Operations must match both
+// filters; an empty filter matches every operation.
+type Options struct {
+	// Tags keeps the operations with at least one of these tags.
+	Tags []string
+	// Paths keeps the operations whose path matches one of these patterns,
+	// using the syntax of path.Match.
+	Paths []string
+}
+
+// Import converts an OpenAPI 3.0 or 3.1 document into an MCP spec. Every
+// operation becomes a tool: its parameters and JSON request body (as the
+// body property) form the input schema, and the JSON schema of its first
+// successful response becomes the output schema. Component schemas are
+// copied, converting the OpenAPI 3.0 keywords to JSON Schema 2020-12.
+//
+// Parts of the document that have no MCP equivalent are skipped and
+// reported in the returned warnings. ext selects the input format (".yaml",
+// ".yml" or ".json").
+func Import(data []byte, ext string, opts Options) (*config.MCPSpec, []string, error) {
+	for _, pattern := range opts.Paths {
+		if _, err := path.Match(pattern, "/"); err != nil {
+			return nil, nil, fmt.Errorf("invalid path pattern %q: %w", pattern, err)
+		}
+	}
+
+	var doc map[string]any
+	switch ext {
+	case ".yaml", ".yml":
+		var v any
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse YAML OpenAPI document: %w", err)
+		}
+		// Response codes are decoded as integer keys
+		doc, _ = stringKeys(v).(map[string]any)
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		if err := dec.Decode(&doc); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse JSON OpenAPI document: %w", err)
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported OpenAPI file format: %s (use .yaml, .yml, or .json)", ext)
+	}
+
+	version, _ := doc["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		if swagger, ok := doc["swagger"]; ok {
+			return nil, nil, fmt.Errorf("unsupported OpenAPI version %v: only OpenAPI 3.x is supported", swagger)
+		}
+		return nil, nil, fmt.Errorf("unsupported OpenAPI version %q: only OpenAPI 3.x is supported", version)
+	}
+
+	imp := &converter{doc: doc, opts: opts}
+	spec, err := imp.spec()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := importer.DecodeSpec(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return result, imp.warnings, nil
+}
+
+type converter struct {
+	doc      map[string]any
+	opts     Options
+	warnings []string
+	names    importer.Names
+}
+
+func (imp *converter) warnf(path, format string, args ...any) {
+	imp.warnings = append(imp.warnings, path+": "+fmt.Sprintf(format, args...))
+}
+
+// spec builds the MCP spec as generic values, decoded into config.MCPSpec
+// by Import.
+func (imp *converter) spec() (map[string]any, error) {
+	info, _ := imp.doc["info"].(map[string]any)
+	title, _ := info["title"].(string)
+	if title == "" {
+		return nil, fmt.Errorf("info.title is required")
+	}
+	// An unquoted YAML version such as 1.0 is decoded as a number
+	version := "1.0.0"
+	if v, ok := info["version"]; ok && v != nil {
+		version = fmt.Sprint(v)
+	}
+	specInfo := map[string]any{"title": title, "version": version}
+	if description, _ := info["description"].(string); description != "" {
+		specInfo["description"] = description
+	}
+
+	schemas := map[string]any{}
+	components, _ := imp.doc["components"].(map[string]any)
+	componentSchemas, _ := components["schemas"].(map[string]any)
+	for _, name := range sortedNames(componentSchemas) {
+		schemas[name] = imp.schema("components.schemas."+name, componentSchemas[name])
+	}
+
+	imp.names = importer.Names{}
+	tools := []any{}
+	paths, _ := imp.doc["paths"].(map[string]any)
+	for _, opPath := range sortedNames(paths) {
+		if !imp.opts.matchesPath(opPath) {
+			continue
+		}
+		item, _ := paths[opPath].(map[string]any)
+		for _, method := range methods {
+			op, ok := item[method].(map[string]any)
+			if !ok || !imp.opts.matchesTags(op) {
+				continue
+			}
+			tool, err := imp.tool(opPath, method, item, op)
+			if err != nil {
+				return nil, err
+			}
+			tools = append(tools, tool)
+		}
+	}
+
+	spec := map[string]any{"info": specInfo, "tools": tools}
+	if len(schemas) > 0 {
+		spec["components"] = map[string]any{"schemas": schemas}
+	}
+	return spec, nil
+}
+
+func (o Options) matchesPath(opPath string) bool {
+	if len(o.Paths) == 0 {
+		return true
+	}
+	for _, pattern := range o.Paths {
+		if ok, _ := path.Match(pattern, opPath); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (o Options) matchesTags(op map[string]any) bool {
+	if len(o.Tags) == 0 {
+		return true
+	}
+	tags, _ := op["tags"].([]any)
+	for _, tag := range tags {
+		if slices.Contains(o.Tags, fmt.Sprint(tag)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (imp *converter) tool(path, method string, item, op map[string]any) (map[string]any, error) {
+	opPath := "paths." + path + "." + method
+	name := imp.toolName(opPath, path, method, op)
+
+	tool := map[string]any{"name": name}
+	if description := operationDescription(op); description != "" {
+		tool["description"] = description
+	}
+	if hints := methodHints(method); hints != nil {
+		tool["hints"] = hints
+	}
+
+	properties := map[string]any{}
+	var required []any
+
+	// Operation parameters override path item parameters with the same name
+	// and location
+	params := map[string]map[string]any{}
+	var order []string
+	for _, list := range []any{item["parameters"], op["parameters"]} {
+		entries, _ := list.([]any)
+		for _, entry := range entries {
+			param, err := imp.resolve(entry, "parameters")
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", opPath, err)
+			}
+			paramName, _ := param["name"].(string)
+			in, _ := param["in"].(string)
+			key := in + " " + paramName
+			if _, exists := params[key]; !exists {
+				order = append(order, key)
+			}
+			params[key] = param
+		}
+	}
+
+	for _, key := range order {
+		param := params[key]
+		paramName, _ := param["name"].(string)
+		in, _ := param["in"].(string)
+		paramPath := opPath + ".parameters." + paramName
+
+		if in == "cookie" {
+			imp.warnf(paramPath, "cookie parameters are not supported, skipped")
+			continue
+		}
+		if _, exists := properties[paramName]; exists || paramName == "body" {
+			imp.warnf(paramPath, "%s parameter conflicts with another input property, skipped", in)
+			continue
+		}
+
+		s, ok := param["schema"]
+		if !ok {
+			imp.warnf(paramPath, "parameter without schema, skipped")
+			continue
+		}
+		prop := imp.schema(paramPath, s)
+		if description, _ := param["description"].(string); description != "" {
+			if _, exists := prop["description"]; !exists {
+				prop["description"] = description
+			}
+		}
+		properties[paramName] = prop
+
+		if isRequired, _ := param["required"].(bool); isRequired || in == "path" {
+			required = append(required, paramName)
+		}
+	}
+
+	if entry, ok := op["requestBody"]; ok {
+		body, err := imp.resolve(entry, "requestBodies")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", opPath, err)
+		}
+		if s, ok := imp.jsonContent(opPath+".requestBody", body); ok {
+			prop := imp.schema(opPath+".requestBody", s)
+			if description, _ := body["description"].(string); description != "" {
+				if _, exists := prop["description"]; !exists {
+					prop["description"] = description
+				}
+			}
+			properties["body"] = prop
+			if isRequired, _ := body["required"].(bool); isRequired {
+				required = append(required, "body")
+			}
+		}
+	}
+
+	inputSchema := map[string]any{"type": "object"}
+	if len(properties) > 0 {
+		inputSchema["properties"] = properties
+	}
+	if len(required) > 0 {
+		inputSchema["required"] = required
+	}
+	tool["inputSchema"] = inputSchema
+
+	outputSchema, err := imp.outputSchema(opPath, op)
+	if err != nil {
+		return nil, err
+	}
+	if outputSchema != nil {
+		tool["outputSchema"] = outputSchema
+	}
+
+	return tool, nil
+}
+
+// outputSchema returns the schema of the first successful JSON response of
+// op, or nil when it has none or it does not describe an object, which MCP
+// requires of structured content.
+func (imp *converter) outputSchema(opPath string, op map[string]any) (map[string]any, error) {
+	responses, _ := op["responses"].(map[string]any)
+
+	var status string
+	for _, code := range sortedNames(responses) {
+		if n, err := strconv.Atoi(code); err == nil && n >= 200 && n < 300 {
+			status = code
+			break
+		}
+	}
+	if status == "" {
+		return nil, nil
+	}
+
+	response, err := imp.resolve(responses[status], "responses")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", opPath, err)
+	}
+	responsePath := opPath + ".responses." + status
+	s, ok := imp.jsonContent(responsePath, response)
+	if !ok {
+		return nil, nil
+	}
+
+	if !imp.isObject(s) {
+		imp.warnf(responsePath, "response is not an object, output schema omitted")
+		return nil, nil
+	}
+	return imp.schema(responsePath, s), nil
+}
+
+// jsonContent returns the schema of the JSON media type of a request body or
+// response.
+func (imp *converter) jsonContent(path string, entry map[string]any) (any, bool) {
+	content, _ := entry["content"].(map[string]any)
+	if len(content) == 0 {
+		return nil, false
+	}
+
+	for _, mediaType := range sortedNames(content) {
+		if !isJSONMediaType(mediaType) {
+			continue
+		}
+		media, _ := content[mediaType].(map[string]any)
+		s, ok := media["schema"]
+		if !ok {
+			imp.warnf(path, "%s content without schema, skipped", mediaType)
+			return nil, false
+		}
+		return s, true
+	}
+
+	imp.warnf(path, "no JSON content (%s), skipped", strings.Join(sortedNames(content), ", "))
+	return nil, false
+}
+
+// resolve follows a reference to a component of the given section, such as
+// #/components/parameters/Limit.
+func (imp *converter) resolve(v any, section string) (map[string]any, error) {
+	m, _ := v.(map[string]any)
+	ref, ok := m["$ref"].(string)
+	if !ok {
+		return m, nil
+	}
+
+	prefix := "#/components/" + section + "/"
+	if !strings.HasPrefix(ref, prefix) {
+		return nil, fmt.Errorf("unsupported reference: %s", ref)
+	}
+
+	components, _ := imp.doc["components"].(map[string]any)
+	entries, _ := components[section].(map[string]any)
+	resolved, ok := entries[strings.TrimPrefix(ref, prefix)].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("reference not found: %s", ref)
+	}
+	return imp.resolve(resolved, section)
+}
+
+// isObject reports whether s, or the component schema it references,
+// describes an object.
+func (imp *converter) isObject(s any) bool {
+	m, ok := s.(map[string]any)
+	if !ok {
+		return false
+	}
+
+	if _, ok := m["$ref"]; ok {
+		resolved, err := imp.resolve(m, "schemas")
+		return err == nil && imp.isObject(resolved)
+	}
+
+	if _, ok := m["properties"]; ok || m["type"] == "object" {
+		return true
+	}
+	allOf, _ := m["allOf"].([]any)
+	for _, sub := range allOf {
+		if imp.isObject(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// schema returns a copy of an OpenAPI schema converted to JSON Schema
+// 2020-12: example becomes examples and the OpenAPI-only keywords are
+// dropped. nullable becomes a null type, as in specs, see schema.Normalize.
+func (imp *converter) schema(path string, v any) map[string]any {
+	m, ok := deepCopy(v).(map[string]any)
+	if !ok {
+		imp.warnf(path, "schema is not an object, replaced by an empty schema")
+		return map[string]any{}
+	}
+
+	imp.convert(path, m)
+	imp.warnings = append(imp.warnings, schema.Normalize(path, m)...)
+	return m
+}
+
+func (imp *converter) convert(path string, m map[string]any) {
+	if example, ok := m["example"]; ok {
+		delete(m, "example")
+		if _, exists := m["examples"]; !exists {
+			m["examples"] = []any{example}
+		}
+	}
+
+	// The discriminator of a oneOf generates a discriminated union
+	if _, ok := m["oneOf"]; !ok {
+		delete(m, "discriminator")
+	}
+	for _, keyword := range []string{"xml", "externalDocs"} {
+		delete(m, keyword)
+	}
+
+	for _, keyword := range []string{"items", "additionalProperties", "not"} {
+		if sub, ok := m[keyword].(map[string]any); ok {
+			imp.convert(path+"."+keyword, sub)
+		}
+	}
+	for _, keyword := range []string{"allOf", "anyOf", "oneOf", "prefixItems"} {
+		subs, _ := m[keyword].([]any)
+		for i, sub := range subs {
+			if sub, ok := sub.(map[string]any); ok {
+				imp.convert(fmt.Sprintf("%s.%s[%d]", path, keyword, i), sub)
+			}
+		}
+	}
+	if properties, ok := m["properties"].(map[string]any); ok {
+		for _, name := range sortedNames(properties) {
+			if sub, ok := properties[name].(map[string]any); ok {
+				imp.convert(path+"."+name, sub)
+			}
+		}
+	}
+}
+
+// toolName derives the tool name from the operation ID, or from the method
+// and path when the operation has none, made unique among the tools.
+func (imp *converter) toolName(opPath, path, method string, op map[string]any) string {
+	base := ""
+	if operationID, _ := op["operationId"].(string); operationID != "" {
+		base = importer.SnakeCase(operationID)
+	}
+	if base == "" {
+		base = importer.SnakeCase(method + " " + path)
+	}
+
+	name := imp.names.Unique(base)
+	if name != base {
+		imp.warnf(opPath, "tool name %s already used, renamed to %s", base, name)
+	}
+
+	return name
+}
+
+func operationDescription(op map[string]any) string {
+	description, _ := op["description"].(string)
+	if description = strings.TrimSpace(description); description != "" {
+		return description
+	}
+	summary, _ := op["summary"].(string)
+	return strings.TrimSpace(summary)
+}
+
+// methodHints returns the tool hints implied by the HTTP method semantics.
+func methodHints(method string) map[string]any {
+	switch method {
+	case "get", "head", "options", "trace":
+		return map[string]any{"readonly": true, "idempotent": true}
+	case "put":
+		return map[string]any{"idempotent": true}
+	case "delete":
+		return map[string]any{"destructive": true, "idempotent": true}
+	}
+	return nil
+}
+
+func isJSONMediaType(mediaType string) bool {
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// stringKeys converts the maps decoded from YAML with non-string keys to
+// maps with string keys.
+func stringKeys(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, item := range v {
+			v[k] = stringKeys(item)
+		}
+		return v
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, item := range v {
+			m[fmt.Sprint(k)] = stringKeys(item)
+		}
+		return m
+	case []any:
+		for i, item := range v {
+			v[i] = stringKeys(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func deepCopy(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, item := range v {
+			m[k] = deepCopy(item)
+		}
+		return m
+	case []any:
+		s := make([]any, len(v))
+		for i, item := range v {
+			s[i] = deepCopy(item)
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+func sortedNames(m map[string]any) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
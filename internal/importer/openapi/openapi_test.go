@@ -0,0 +1,110 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImport(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "petstore.yaml"))
+	require.NoError(t, err)
+
+	spec, warnings, err := Import(data, ".yaml", Options{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Petstore", spec.Info.Title)
+	assert.Equal(t, "A sample pet store", spec.Info.Description)
+
+	names := make([]string, 0, len(spec.Tools))
+	for _, tool := range spec.Tools {
+		names = append(names, tool.Name)
+	}
+	assert.Equal(t, []string{"list_pets", "create_pet", "get_pets_pet_id", "delete_pet"}, names)
+
+	list := spec.Tools[0]
+	assert.Equal(t, "List all pets", list.Description)
+	require.NotNil(t, list.Hints)
+	assert.True(t, list.Hints.Readonly)
+	limit := list.InputSchema.Properties["limit"]
+	require.NotNil(t, limit)
+	assert.Equal(t, "Maximum number of pets", limit.Description)
+	require.NotNil(t, limit.ExclusiveMaximum)
+	assert.Equal(t, 100.0, *limit.ExclusiveMaximum)
+	assert.NotContains(t, list.InputSchema.Properties, "session")
+	// Arrays cannot be structured content
+	assert.Nil(t, list.OutputSchema)
+
+	create := spec.Tools[1]
+	assert.Equal(t, "Create a pet", create.Description)
+	assert.Nil(t, create.Hints)
+	assert.Equal(t, []string{"body"}, create.InputSchema.Required)
+	assert.Equal(t, "#/components/schemas/NewPet", create.InputSchema.Properties["body"].Ref)
+	require.NotNil(t, create.OutputSchema)
+	assert.Equal(t, "#/components/schemas/Pet", create.OutputSchema.Ref)
+
+	get := spec.Tools[2]
+	assert.Equal(t, []string{"petId"}, get.InputSchema.Required)
+	assert.Equal(t, "The pet ID", get.InputSchema.Properties["petId"].Description)
+	require.NotNil(t, get.OutputSchema)
+
+	remove := spec.Tools[3]
+	assert.True(t, remove.Hints.Destructive)
+	assert.Nil(t, remove.OutputSchema)
+
+	newPet := spec.Components.Schemas["NewPet"]
+	assert.Equal(t, []string{"string", "null"}, newPet.Properties["tag"].Types)
+	assert.Equal(t, []any{"Rex"}, newPet.Properties["name"].Examples)
+
+	assert.Equal(t, []string{
+		"paths./pets.get.parameters.session: cookie parameters are not supported, skipped",
+		"paths./pets.get.responses.200: response is not an object, output schema omitted",
+	}, warnings)
+}
+
+func TestImportFilters(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "petstore.yaml"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{name: "tags", opts: Options{Tags: []string{"admin"}}, want: []string{"create_pet"}},
+		{name: "paths", opts: Options{Paths: []string{"/pets/*"}}, want: []string{"get_pets_pet_id", "delete_pet"}},
+		{name: "tags and paths", opts: Options{Tags: []string{"pets"}, Paths: []string{"/pets"}}, want: []string{"list_pets", "create_pet"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, _, err := Import(data, ".yaml", tt.opts)
+			require.NoError(t, err)
+
+			names := make([]string, 0, len(spec.Tools))
+			for _, tool := range spec.Tools {
+				names = append(names, tool.Name)
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+
+	_, _, err = Import(data, ".yaml", Options{Paths: []string{"["}})
+	assert.Error(t, err)
+}
+
+func TestImportErrors(t *testing.T) {
+	_, _, err := Import([]byte(`{"swagger": "2.0", "info": {"title": "x"}}`), ".json", Options{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "only OpenAPI 3.x is supported")
+
+	_, _, err = Import([]byte(`openapi: 3.1.0`), ".yaml", Options{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "info.title is required")
+
+	_, _, err = Import(nil, ".txt", Options{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,562 @@
+package proto
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// File is the subset of a parsed .proto file the importer uses.
+type File struct {
+	Name     string
+	Package  string
+	Imports  []string
+	Messages []*Message
+	Enums    []*Enum
+	Services []*Service
+}
+
+type Message struct {
+	Name     string
+	Comment  string
+	Fields   []*Field
+	Messages []*Message
+	Enums    []*Enum
+}
+
+type Field struct {
+	Name     string
+	JSONName string
+	Comment  string
+	Type     string
+	// KeyType is set for map fields, Type holding the value type.
+	KeyType  string
+	Repeated bool
+	Required bool
+	Oneof    string
+}
+
+type Enum struct {
+	Name    string
+	Comment string
+	Values  []string
+}
+
+type Service struct {
+	Name    string
+	Comment string
+	Methods []*Method
+}
+
+type Method struct {
+	Name            string
+	Comment         string
+	InputType       string
+	OutputType      string
+	ClientStreaming bool
+	ServerStreaming bool
+}
+
+type token struct {
+	text string
+	// comment holds the // or /* */ comments directly above the token
+	comment string
+	line    int
+	str     bool
+}
+
+// Parse reads a .proto file (proto2 or proto3). Options, extensions and
+// reserved declarations are skipped; groups are not supported.
+func Parse(name string, src []byte) (*File, error) {
+	tokens, err := tokenize(string(src))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	p := &parser{tokens: tokens}
+	file, err := p.file()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	file.Name = name
+	return file, nil
+}
+
+func tokenize(src string) ([]token, error) {
+	var tokens []token
+	var comment []string
+	line := 1
+	// lastLine is the line of the last token or comment, to only keep the
+	// comments directly above a declaration, and tokenLine the line of the
+	// last token, to drop trailing comments
+	lastLine, tokenLine := 0, 0
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case unicode.IsSpace(rune(c)):
+			i++
+		case strings.HasPrefix(src[i:], "//"):
+			end := strings.IndexByte(src[i:], '\n')
+			if end == -1 {
+				end = len(src) - i
+			}
+			if line == tokenLine {
+				i += end
+				continue
+			}
+			if lastLine < line-1 {
+				comment = nil
+			}
+			comment = append(comment, strings.TrimSpace(strings.TrimPrefix(src[i:i+end], "//")))
+			lastLine = line
+			i += end
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end == -1 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+			text := src[i+2 : i+2+end]
+			if line == tokenLine {
+				line += strings.Count(text, "\n")
+				i += end + 4
+				continue
+			}
+			if lastLine < line-1 {
+				comment = nil
+			}
+			for _, l := range strings.Split(text, "\n") {
+				if l = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(l), "*")); l != "" {
+					comment = append(comment, l)
+				}
+			}
+			line += strings.Count(text, "\n")
+			lastLine = line
+			i += end + 4
+		case c == '"' || c == '\'':
+			j := i + 1
+			var b strings.Builder
+			for ; j < len(src) && src[j] != c; j++ {
+				if src[j] == '\\' && j+1 < len(src) {
+					j++
+				}
+				if src[j] == '\n' {
+					return nil, fmt.Errorf("line %d: unterminated string", line)
+				}
+				b.WriteByte(src[j])
+			}
+			if j >= len(src) {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+			tokens = append(tokens, token{text: b.String(), str: true, line: line})
+			comment, lastLine, tokenLine = nil, line, line
+			i = j + 1
+		case isIdentChar(c):
+			j := i
+			for j < len(src) && (isIdentChar(src[j]) || src[j] == '.') {
+				j++
+			}
+			tok := token{text: src[i:j], line: line}
+			if lastLine >= line-1 {
+				tok.comment = strings.Join(comment, "\n")
+			}
+			tokens = append(tokens, tok)
+			comment, lastLine, tokenLine = nil, line, line
+			i = j
+		default:
+			text := string(c)
+			// A fully qualified type name starts with a dot
+			if c == '.' {
+				j := i + 1
+				for j < len(src) && (isIdentChar(src[j]) || src[j] == '.') {
+					j++
+				}
+				text = src[i:j]
+			}
+			tokens = append(tokens, token{text: text, line: line})
+			comment, lastLine, tokenLine = nil, line, line
+			i += len(text)
+		}
+	}
+
+	return tokens, nil
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '-' || c == '+' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return token{}
+}
+
+func (p *parser) next() token {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) expect(text string) error {
+	tok := p.next()
+	if tok.text != text || tok.str {
+		return p.errorf(tok, "expected %q, found %q", text, tok.text)
+	}
+	return nil
+}
+
+func (p *parser) errorf(tok token, format string, args ...any) error {
+	if tok.line == 0 {
+		return fmt.Errorf("unexpected end of file: "+format, args...)
+	}
+	return fmt.Errorf("line %d: "+format, append([]any{tok.line}, args...)...)
+}
+
+// skipStatement skips up to the next semicolon at the current nesting level,
+// or a balanced block when one starts first.
+func (p *parser) skipStatement() error {
+	depth := 0
+	for !p.done() {
+		tok := p.next()
+		if tok.str {
+			continue
+		}
+		switch tok.text {
+		case "{", "[", "(":
+			depth++
+		case "}", "]", ")":
+			depth--
+			if depth == 0 && tok.text == "}" {
+				return nil
+			}
+		case ";":
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("unexpected end of file")
+}
+
+func (p *parser) file() (*File, error) {
+	file := &File{}
+	for !p.done() {
+		tok := p.next()
+		switch tok.text {
+		case "syntax", "edition", "option":
+			if err := p.skipStatement(); err != nil {
+				return nil, err
+			}
+		case "package":
+			file.Package = p.next().text
+			if err := p.expect(";"); err != nil {
+				return nil, err
+			}
+		case "import":
+			path := p.next()
+			if path.text == "public" || path.text == "weak" {
+				path = p.next()
+			}
+			file.Imports = append(file.Imports, path.text)
+			if err := p.expect(";"); err != nil {
+				return nil, err
+			}
+		case "message":
+			msg, err := p.message(tok)
+			if err != nil {
+				return nil, err
+			}
+			file.Messages = append(file.Messages, msg)
+		case "enum":
+			enum, err := p.enum(tok)
+			if err != nil {
+				return nil, err
+			}
+			file.Enums = append(file.Enums, enum)
+		case "service":
+			svc, err := p.service(tok)
+			if err != nil {
+				return nil, err
+			}
+			file.Services = append(file.Services, svc)
+		case "extend":
+			if err := p.skipStatement(); err != nil {
+				return nil, err
+			}
+		case ";":
+		default:
+			return nil, p.errorf(tok, "unexpected %q", tok.text)
+		}
+	}
+	return file, nil
+}
+
+func (p *parser) message(start token) (*Message, error) {
+	msg := &Message{Name: p.next().text, Comment: start.comment}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+	if err := p.messageBody(msg, ""); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// messageBody parses declarations up to the closing brace of a message, or
+// of a oneof when oneof is set.
+func (p *parser) messageBody(msg *Message, oneof string) error {
+	for {
+		tok := p.peek()
+		if p.done() {
+			return p.errorf(tok, "expected \"}\"")
+		}
+
+		switch tok.text {
+		case "}":
+			p.next()
+			return nil
+		case ";":
+			p.next()
+		case "option", "reserved", "extensions", "extend":
+			p.next()
+			if err := p.skipStatement(); err != nil {
+				return err
+			}
+		case "message":
+			p.next()
+			nested, err := p.message(tok)
+			if err != nil {
+				return err
+			}
+			msg.Messages = append(msg.Messages, nested)
+		case "enum":
+			p.next()
+			enum, err := p.enum(tok)
+			if err != nil {
+				return err
+			}
+			msg.Enums = append(msg.Enums, enum)
+		case "oneof":
+			p.next()
+			name := p.next().text
+			if err := p.expect("{"); err != nil {
+				return err
+			}
+			if err := p.messageBody(msg, name); err != nil {
+				return err
+			}
+		case "group":
+			return p.errorf(tok, "groups are not supported")
+		default:
+			field, err := p.field()
+			if err != nil {
+				return err
+			}
+			field.Oneof = oneof
+			msg.Fields = append(msg.Fields, field)
+		}
+	}
+}
+
+func (p *parser) field() (*Field, error) {
+	start := p.peek()
+	field := &Field{Comment: start.comment}
+
+	switch start.text {
+	case "repeated":
+		field.Repeated = true
+		p.next()
+	case "required":
+		field.Required = true
+		p.next()
+	case "optional":
+		p.next()
+	}
+
+	tok := p.next()
+	if tok.text == "group" {
+		return nil, p.errorf(tok, "groups are not supported")
+	}
+	if tok.text == "map" {
+		if err := p.expect("<"); err != nil {
+			return nil, err
+		}
+		field.KeyType = p.next().text
+		if err := p.expect(","); err != nil {
+			return nil, err
+		}
+		field.Type = p.next().text
+		if err := p.expect(">"); err != nil {
+			return nil, err
+		}
+	} else {
+		field.Type = tok.text
+	}
+
+	field.Name = p.next().text
+	if err := p.expect("="); err != nil {
+		return nil, err
+	}
+	p.next() // field number
+
+	if p.peek().text == "[" {
+		if err := p.fieldOptions(field); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := p.expect(";"); err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+// fieldOptions reads json_name from a field option list and skips the rest.
+func (p *parser) fieldOptions(field *Field) error {
+	p.next()
+	depth := 1
+	for depth > 0 {
+		if p.done() {
+			return fmt.Errorf("unexpected end of file")
+		}
+		tok := p.next()
+		if tok.str {
+			continue
+		}
+		switch tok.text {
+		case "[", "{", "(":
+			depth++
+		case "]", "}", ")":
+			depth--
+		case "json_name":
+			if depth == 1 && p.peek().text == "=" {
+				p.next()
+				field.JSONName = p.next().text
+			}
+		}
+	}
+	return nil
+}
+
+func (p *parser) enum(start token) (*Enum, error) {
+	enum := &Enum{Name: p.next().text, Comment: start.comment}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.next()
+		switch {
+		case tok.line == 0:
+			return nil, p.errorf(tok, "expected \"}\"")
+		case tok.text == "}":
+			return enum, nil
+		case tok.text == ";":
+		case tok.text == "option" || tok.text == "reserved":
+			if err := p.skipStatement(); err != nil {
+				return nil, err
+			}
+		default:
+			enum.Values = append(enum.Values, tok.text)
+			if err := p.skipStatement(); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func (p *parser) service(start token) (*Service, error) {
+	svc := &Service{Name: p.next().text, Comment: start.comment}
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.next()
+		switch tok.text {
+		case "}":
+			return svc, nil
+		case ";":
+		case "option":
+			if err := p.skipStatement(); err != nil {
+				return nil, err
+			}
+		case "rpc":
+			method, err := p.method(tok)
+			if err != nil {
+				return nil, err
+			}
+			svc.Methods = append(svc.Methods, method)
+		default:
+			return nil, p.errorf(tok, "unexpected %q in service %s", tok.text, svc.Name)
+		}
+	}
+}
+
+func (p *parser) method(start token) (*Method, error) {
+	method := &Method{Name: p.next().text, Comment: start.comment}
+
+	messageType := func() (string, bool, error) {
+		if err := p.expect("("); err != nil {
+			return "", false, err
+		}
+		stream := false
+		if p.peek().text == "stream" {
+			p.next()
+			stream = true
+		}
+		name := p.next().text
+		if err := p.expect(")"); err != nil {
+			return "", false, err
+		}
+		return name, stream, nil
+	}
+
+	var err error
+	if method.InputType, method.ClientStreaming, err = messageType(); err != nil {
+		return nil, err
+	}
+	if err := p.expect("returns"); err != nil {
+		return nil, err
+	}
+	if method.OutputType, method.ServerStreaming, err = messageType(); err != nil {
+		return nil, err
+	}
+
+	// Either a semicolon or an options block
+	if p.peek().text == "{" {
+		p.next()
+		for p.peek().text != "}" {
+			if p.done() {
+				return nil, fmt.Errorf("unexpected end of file")
+			}
+			if err := p.skipStatement(); err != nil {
+				return nil, err
+			}
+		}
+		p.next()
+		if p.peek().text == ";" {
+			p.next()
+		}
+		return method, nil
+	}
+
+	if err := p.expect(";"); err != nil {
+		return nil, err
+	}
+	return method, nil
+}
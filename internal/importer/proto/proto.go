@@ -0,0 +1,389 @@
+package proto
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"go.probo.inc/mcpgen/internal/config"
+	"go.probo.inc/mcpgen/internal/importer"
+)
+
+// Options selects the RPCs to import. RPCs must match both filters; an empty
+// filter matches every RPC.
+type Options struct {
+	// Services keeps the RPCs of these services, by name or fully qualified
+	// name.
+	Services []string
+	// Methods keeps these RPCs, as Method or Service.Method.
+	Methods []string
+}
+
+// scalarSchemas maps the protobuf scalar types to the JSON Schema of their
+// proto3 JSON encoding.
+var scalarSchemas = map[string]map[string]any{
+	"double":   {"type": "number"},
+	"float":    {"type": "number"},
+	"int32":    {"type": "integer", "format": "int32"},
+	"sint32":   {"type": "integer", "format": "int32"},
+	"sfixed32": {"type": "integer", "format": "int32"},
+	"uint32":   {"type": "integer", "format": "int32", "minimum": 0},
+	"fixed32":  {"type": "integer", "format": "int32", "minimum": 0},
+	"int64":    {"type": "integer", "format": "int64"},
+	"sint64":   {"type": "integer", "format": "int64"},
+	"sfixed64": {"type": "integer", "format": "int64"},
+	"uint64":   {"type": "integer", "format": "int64", "minimum": 0},
+	"fixed64":  {"type": "integer", "format": "int64", "minimum": 0},
+	"bool":     {"type": "boolean"},
+	"string":   {"type": "string"},
+	"bytes":    {"type": "string", "contentEncoding": "base64"},
+}
+
+// wellKnownSchemas maps the google.protobuf well-known types to the JSON
+// Schema of their JSON encoding.
+var wellKnownSchemas = map[string]map[string]any{
+	"google.protobuf.Empty":       {"type": "object"},
+	"google.protobuf.Struct":      {"type": "object"},
+	"google.protobuf.Any":         {"type": "object", "properties": map[string]any{"@type": map[string]any{"type": "string"}}},
+	"google.protobuf.Value":       {},
+	"google.protobuf.ListValue":   {"type": "array"},
+	"google.protobuf.Timestamp":   {"type": "string", "format": "date-time"},
+	"google.protobuf.Duration":    {"type": "string", "pattern": `^-?[0-9]+(\.[0-9]+)?s$`},
+	"google.protobuf.FieldMask":   {"type": "string"},
+	"google.protobuf.StringValue": {"type": []any{"string", "null"}},
+	"google.protobuf.BytesValue":  {"type": []any{"string", "null"}, "contentEncoding": "base64"},
+	"google.protobuf.BoolValue":   {"type": []any{"boolean", "null"}},
+	"google.protobuf.DoubleValue": {"type": []any{"number", "null"}},
+	"google.protobuf.FloatValue":  {"type": []any{"number", "null"}},
+	"google.protobuf.Int32Value":  {"type": []any{"integer", "null"}, "format": "int32"},
+	"google.protobuf.UInt32Value": {"type": []any{"integer", "null"}, "format": "int32", "minimum": 0},
+	"google.protobuf.Int64Value":  {"type": []any{"integer", "null"}, "format": "int64"},
+	"google.protobuf.UInt64Value": {"type": []any{"integer", "null"}, "format": "int64", "minimum": 0},
+}
+
+// Import converts parsed .proto files into an MCP spec. Every unary RPC of
+// the selected services becomes a tool taking its request message as input
+// and returning its response message. The messages and enums they use are
+// added as component schemas, following the proto3 JSON mapping, with the
+// comments above declarations as descriptions.
+//
+// Streaming RPCs and types missing from files are skipped or left
+// unconstrained and reported in the returned warnings.
+func Import(files []*File, opts Options) (*config.MCPSpec, []string, error) {
+	c := &converter{
+		types:      map[string]*typeInfo{},
+		components: map[string]any{},
+		converting: map[string]bool{},
+		names:      importer.Names{},
+	}
+	for _, file := range files {
+		c.register(file)
+	}
+	c.assignComponentNames()
+
+	var services []*Service
+	var packages []string
+	tools := []any{}
+	for _, file := range files {
+		for _, svc := range file.Services {
+			fullName := qualify(file.Package, svc.Name)
+			if len(opts.Services) > 0 && !slices.Contains(opts.Services, svc.Name) && !slices.Contains(opts.Services, fullName) {
+				continue
+			}
+			services = append(services, svc)
+			packages = append(packages, file.Package)
+
+			for _, method := range svc.Methods {
+				if len(opts.Methods) > 0 && !slices.Contains(opts.Methods, method.Name) && !slices.Contains(opts.Methods, svc.Name+"."+method.Name) {
+					continue
+				}
+				if tool := c.tool(fullName, file.Package, method); tool != nil {
+					tools = append(tools, tool)
+				}
+			}
+		}
+	}
+
+	if len(services) == 0 {
+		return nil, nil, fmt.Errorf("no service found")
+	}
+
+	info := map[string]any{"title": services[0].Name, "version": "1.0.0"}
+	if len(services) == 1 {
+		if services[0].Comment != "" {
+			info["description"] = services[0].Comment
+		}
+	} else if packages[0] != "" {
+		info["title"] = packages[0]
+	}
+
+	spec := map[string]any{"info": info, "tools": tools}
+	if len(c.components) > 0 {
+		spec["components"] = map[string]any{"schemas": c.components}
+	}
+
+	result, err := importer.DecodeSpec(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, c.warnings, nil
+}
+
+type typeInfo struct {
+	fullName  string
+	pkg       string
+	message   *Message
+	enum      *Enum
+	component string
+}
+
+type converter struct {
+	types      map[string]*typeInfo
+	order      []string
+	components map[string]any
+	converting map[string]bool
+	names      importer.Names
+	warnings   []string
+}
+
+func (c *converter) warnf(path, format string, args ...any) {
+	c.warnings = append(c.warnings, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (c *converter) register(file *File) {
+	var addMessages func(scope string, messages []*Message)
+	addEnums := func(scope string, enums []*Enum) {
+		for _, enum := range enums {
+			fullName := qualify(scope, enum.Name)
+			c.types[fullName] = &typeInfo{fullName: fullName, pkg: file.Package, enum: enum}
+			c.order = append(c.order, fullName)
+		}
+	}
+	addMessages = func(scope string, messages []*Message) {
+		for _, msg := range messages {
+			fullName := qualify(scope, msg.Name)
+			c.types[fullName] = &typeInfo{fullName: fullName, pkg: file.Package, message: msg}
+			c.order = append(c.order, fullName)
+			addMessages(fullName, msg.Messages)
+			addEnums(fullName, msg.Enums)
+		}
+	}
+
+	addMessages(file.Package, file.Messages)
+	addEnums(file.Package, file.Enums)
+}
+
+// assignComponentNames names the component schema of every type after its
+// name within its package, nested names joined, or after its fully
+// qualified name when that clashes with a type of another package.
+func (c *converter) assignComponentNames() {
+	count := map[string]int{}
+	for _, fullName := range c.order {
+		info := c.types[fullName]
+		info.component = pascalCase(strings.TrimPrefix(strings.TrimPrefix(fullName, info.pkg), "."))
+		count[info.component]++
+	}
+	for _, fullName := range c.order {
+		if info := c.types[fullName]; count[info.component] > 1 {
+			info.component = pascalCase(fullName)
+		}
+	}
+}
+
+// resolve finds the type a name refers to from scope, searching the
+// enclosing scopes from the innermost as protoc does.
+func (c *converter) resolve(name, scope string) *typeInfo {
+	if fullName, ok := strings.CutPrefix(name, "."); ok {
+		return c.types[fullName]
+	}
+
+	for {
+		if info, ok := c.types[qualify(scope, name)]; ok {
+			return info
+		}
+		if scope == "" {
+			return nil
+		}
+		if i := strings.LastIndex(scope, "."); i >= 0 {
+			scope = scope[:i]
+		} else {
+			scope = ""
+		}
+	}
+}
+
+// typeSchema returns the schema of a field or RPC type, adding the component
+// schemas it references.
+func (c *converter) typeSchema(path, name, scope string) map[string]any {
+	if s, ok := scalarSchemas[name]; ok {
+		return copySchema(s)
+	}
+	if s, ok := wellKnownSchemas[strings.TrimPrefix(name, ".")]; ok {
+		return copySchema(s)
+	}
+
+	info := c.resolve(name, scope)
+	if info == nil {
+		c.warnf(path, "unknown type %s, left unconstrained", name)
+		return map[string]any{}
+	}
+
+	// The generator cannot expand recursive schemas, so a reference back to
+	// a message being converted is left as a plain object
+	if c.converting[info.component] {
+		c.warnf(path, "recursive reference to %s, left as a plain object", info.fullName)
+		return map[string]any{"type": "object"}
+	}
+
+	c.addComponent(info)
+	return map[string]any{"$ref": "#/components/schemas/" + info.component}
+}
+
+func (c *converter) addComponent(info *typeInfo) {
+	if _, ok := c.components[info.component]; ok {
+		return
+	}
+
+	if info.enum != nil {
+		values := make([]any, len(info.enum.Values))
+		for i, v := range info.enum.Values {
+			values[i] = v
+		}
+		s := map[string]any{"type": "string", "enum": values}
+		if info.enum.Comment != "" {
+			s["description"] = info.enum.Comment
+		}
+		c.components[info.component] = s
+		return
+	}
+
+	s := map[string]any{"type": "object"}
+	c.components[info.component] = s
+	c.converting[info.component] = true
+	defer delete(c.converting, info.component)
+
+	msg := info.message
+	if msg.Comment != "" {
+		s["description"] = msg.Comment
+	}
+
+	properties := map[string]any{}
+	var required []any
+	for _, field := range msg.Fields {
+		path := info.fullName + "." + field.Name
+
+		prop := c.typeSchema(path, field.Type, info.fullName)
+		switch {
+		case field.KeyType != "":
+			prop = map[string]any{"type": "object", "additionalProperties": prop}
+		case field.Repeated:
+			prop = map[string]any{"type": "array", "items": prop}
+		}
+		if field.Comment != "" {
+			prop["description"] = field.Comment
+		}
+
+		name := field.JSONName
+		if name == "" {
+			name = jsonName(field.Name)
+		}
+		properties[name] = prop
+		if field.Required {
+			required = append(required, name)
+		}
+	}
+
+	if len(properties) > 0 {
+		s["properties"] = properties
+	}
+	if len(required) > 0 {
+		s["required"] = required
+	}
+}
+
+func (c *converter) tool(service, pkg string, method *Method) map[string]any {
+	path := service + "." + method.Name
+	if method.ClientStreaming || method.ServerStreaming {
+		c.warnf(path, "streaming RPCs are not supported, skipped")
+		return nil
+	}
+
+	input := c.typeSchema(path, method.InputType, pkg)
+	if !c.isObject(input) {
+		c.warnf(path, "request type %s is not a message, skipped", method.InputType)
+		return nil
+	}
+
+	base := importer.SnakeCase(method.Name)
+	name := c.names.Unique(base)
+	if name != base {
+		c.warnf(path, "tool name %s already used, renamed to %s", base, name)
+	}
+
+	tool := map[string]any{"name": name, "inputSchema": input}
+	if method.Comment != "" {
+		tool["description"] = method.Comment
+	}
+
+	if strings.TrimPrefix(method.OutputType, ".") != "google.protobuf.Empty" {
+		output := c.typeSchema(path, method.OutputType, pkg)
+		if c.isObject(output) {
+			tool["outputSchema"] = output
+		} else {
+			c.warnf(path, "response type %s is not a message, output schema omitted", method.OutputType)
+		}
+	}
+
+	return tool
+}
+
+// isObject reports whether s is a message reference or an object schema.
+func (c *converter) isObject(s map[string]any) bool {
+	if ref, ok := s["$ref"].(string); ok {
+		component, _ := c.components[strings.TrimPrefix(ref, "#/components/schemas/")].(map[string]any)
+		return component["type"] == "object"
+	}
+	return s["type"] == "object"
+}
+
+func qualify(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+// jsonName returns the default proto3 JSON name of a field: lowerCamelCase.
+func jsonName(name string) string {
+	var b strings.Builder
+	upper := false
+	for _, r := range name {
+		if r == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func pascalCase(name string) string {
+	var b strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '.' || r == '_' }) {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
+
+func copySchema(s map[string]any) map[string]any {
+	result := make(map[string]any, len(s))
+	for k, v := range s {
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,115 @@
+package proto
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseTestdata(t *testing.T, name string) *File {
+	t.Helper()
+
+	src, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+
+	file, err := Parse(name, src)
+	require.NoError(t, err)
+
+	return file
+}
+
+func TestImport(t *testing.T) {
+	file := parseTestdata(t, "library.proto")
+
+	spec, warnings, err := Import([]*File{file}, Options{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "LibraryService", spec.Info.Title)
+	assert.Equal(t, "Manages the books of a library.", spec.Info.Description)
+
+	names := make([]string, 0, len(spec.Tools))
+	for _, tool := range spec.Tools {
+		names = append(names, tool.Name)
+	}
+	assert.Equal(t, []string{"get_book", "list_books", "delete_book"}, names)
+
+	get := spec.Tools[0]
+	assert.Equal(t, "Get a book by its ID.", get.Description)
+	assert.Equal(t, "#/components/schemas/GetBookRequest", get.InputSchema.Ref)
+	require.NotNil(t, get.OutputSchema)
+	assert.Equal(t, "#/components/schemas/Book", get.OutputSchema.Ref)
+
+	assert.Nil(t, spec.Tools[2].OutputSchema)
+
+	request := spec.Components.Schemas["GetBookRequest"]
+	assert.Equal(t, "The book ID.", request.Properties["bookId"].Description)
+
+	list := spec.Components.Schemas["ListBooksRequest"]
+	assert.Contains(t, list.Properties, "limit")
+	assert.Contains(t, list.Properties, "pageToken")
+
+	book := spec.Components.Schemas["Book"]
+	assert.Equal(t, "A book.", book.Description)
+	assert.Equal(t, "#/components/schemas/BookFormat", book.Properties["format"].Ref)
+	assert.Equal(t, "int64", book.Properties["pages"].Format)
+	assert.Equal(t, "base64", book.Properties["cover"].ContentEncoding)
+	assert.Equal(t, "date-time", book.Properties["publishedAt"].Format)
+	assert.Equal(t, "string", book.Properties["labels"].AdditionalProperties.Type)
+	assert.Equal(t, "object", book.Properties["related"].Type)
+	assert.Equal(t, []any{"FORMAT_UNSPECIFIED", "HARDCOVER", "PAPERBACK"}, spec.Components.Schemas["BookFormat"].Enum)
+
+	assert.Equal(t, []string{
+		"example.library.v1.Book.related: recursive reference to example.library.v1.Book, left as a plain object",
+		"example.library.v1.LibraryService.WatchBooks: streaming RPCs are not supported, skipped",
+	}, warnings)
+}
+
+func TestImportFilters(t *testing.T) {
+	file := parseTestdata(t, "library.proto")
+
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{name: "services", opts: Options{Services: []string{"example.library.v1.LibraryService"}}, want: []string{"get_book", "list_books", "delete_book"}},
+		{name: "methods", opts: Options{Methods: []string{"GetBook", "LibraryService.DeleteBook"}}, want: []string{"get_book", "delete_book"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, _, err := Import([]*File{file}, tt.opts)
+			require.NoError(t, err)
+
+			names := make([]string, 0, len(spec.Tools))
+			for _, tool := range spec.Tools {
+				names = append(names, tool.Name)
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+
+	_, _, err := Import([]*File{file}, Options{Services: []string{"Unknown"}})
+	assert.EqualError(t, err, "no service found")
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{name: "unterminated message", src: "syntax = \"proto3\";\nmessage A {\n  string a = 1;\n", want: `a.proto: unexpected end of file: expected "}"`},
+		{name: "group", src: "syntax = \"proto2\";\nmessage A {\n  optional group G = 1 {}\n}\n", want: "a.proto: line 3: groups are not supported"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse("a.proto", []byte(tt.src))
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.probo.inc/mcpgen/internal/config"
+	"go.probo.inc/mcpgen/internal/schema"
+)
+
+// Issue is a style problem found in a spec.
+type Issue struct {
+	Rule    string
+	Path    string
+	Message string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s (%s)", i.Path, i.Message, i.Rule)
+}
+
+// Rule is a named check run against a spec.
+type Rule struct {
+	Name        string
+	Description string
+	check       func(spec *config.MCPSpec) []Issue
+}
+
+// Rules lists every available lint rule. All rules are enabled unless disabled
+// in the lint block of the configuration.
+var Rules = []Rule{
+	{
+		Name:        "tool-description",
+		Description: "tools must have a description",
+		check:       checkToolDescriptions,
+	},
+	{
+		Name:        "resource-description",
+		Description: "resources must have a description",
+		check:       checkResourceDescriptions,
+	},
+	{
+		Name:        "prompt-description",
+		Description: "prompts and prompt arguments must have a description",
+		check:       checkPromptDescriptions,
+	},
+	{
+		Name:        "property-description",
+		Description: "schema properties must have a description",
+		check:       checkPropertyDescriptions,
+	},
+	{
+		Name:        "enum-description",
+		Description: "enum schemas must have a description",
+		check:       checkEnumDescriptions,
+	},
+	{
+		Name:        "tool-naming",
+		Description: "tool names must consistently use snake_case or kebab-case",
+		check:       checkToolNaming,
+	},
+	{
+		Name:        "tool-output-schema",
+		Description: "tools should declare an output schema",
+		check:       checkToolOutputSchemas,
+	},
+	{
+		Name:        "description-placeholder",
+		Description: "descriptions must not be left as TODO placeholders",
+		check:       checkDescriptionPlaceholders,
+	},
+}
+
+// Lint runs the rules enabled by cfg against spec and returns the issues found.
+func Lint(spec *config.MCPSpec, cfg config.LintConfig) ([]Issue, error) {
+	known := make(map[string]bool, len(Rules))
+	for _, rule := range Rules {
+		known[rule.Name] = true
+	}
+
+	// Sort rule names for deterministic output
+	configured := make([]string, 0, len(cfg.Rules))
+	for name := range cfg.Rules {
+		configured = append(configured, name)
+	}
+	sort.Strings(configured)
+
+	for _, name := range configured {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown lint rule: %s", name)
+		}
+	}
+
+	var issues []Issue
+	for _, rule := range Rules {
+		if !cfg.RuleEnabled(rule.Name) {
+			continue
+		}
+		for _, issue := range rule.check(spec) {
+			issue.Rule = rule.Name
+			issues = append(issues, issue)
+		}
+	}
+
+	return issues, nil
+}
+
+func checkToolDescriptions(spec *config.MCPSpec) []Issue {
+	var issues []Issue
+	for _, tool := range spec.Tools {
+		if strings.TrimSpace(tool.Description) == "" {
+			issues = append(issues, Issue{Path: "tools." + tool.Name, Message: "missing description"})
+		}
+	}
+	return issues
+}
+
+func checkResourceDescriptions(spec *config.MCPSpec) []Issue {
+	var issues []Issue
+	for _, resource := range spec.Resources {
+		if strings.TrimSpace(resource.Description) == "" {
+			issues = append(issues, Issue{Path: "resources." + resource.Name, Message: "missing description"})
+		}
+	}
+	return issues
+}
+
+func checkPromptDescriptions(spec *config.MCPSpec) []Issue {
+	var issues []Issue
+	for _, prompt := range spec.Prompts {
+		path := "prompts." + prompt.Name
+		if strings.TrimSpace(prompt.Description) == "" {
+			issues = append(issues, Issue{Path: path, Message: "missing description"})
+		}
+		for _, arg := range prompt.Arguments {
+			if strings.TrimSpace(arg.Description) == "" {
+				issues = append(issues, Issue{Path: path + ".arguments." + arg.Name, Message: "missing description"})
+			}
+		}
+	}
+	return issues
+}
+
+func checkPropertyDescriptions(spec *config.MCPSpec) []Issue {
+	var issues []Issue
+	walkSpecSchemas(spec, func(path string, s *config.Schema) {
+		for _, name := range sortedKeys(s.Properties) {
+			prop := s.Properties[name]
+			// Referenced schemas are checked where they are defined and
+			// enums by the enum-description rule
+			if prop.Ref == "" && len(prop.Enum) == 0 && strings.TrimSpace(prop.Description) == "" {
+				issues = append(issues, Issue{Path: path + "." + name, Message: "missing description"})
+			}
+		}
+	})
+	return issues
+}
+
+func checkEnumDescriptions(spec *config.MCPSpec) []Issue {
+	var issues []Issue
+	walkSpecSchemas(spec, func(path string, s *config.Schema) {
+		if len(s.Enum) > 0 && strings.TrimSpace(s.Description) == "" {
+			issues = append(issues, Issue{Path: path, Message: "enum without description"})
+		}
+	})
+	return issues
+}
+
+func checkToolNaming(spec *config.MCPSpec) []Issue {
+	var issues []Issue
+
+	// The style of the first tool with a separator is the reference
+	expected := ""
+	for _, tool := range spec.Tools {
+		style := namingStyle(tool.Name)
+		switch {
+		case style == "mixed":
+			issues = append(issues, Issue{Path: "tools." + tool.Name, Message: "name mixes snake_case and kebab-case"})
+		case style == "":
+			continue
+		case expected == "":
+			expected = style
+		case style != expected:
+			issues = append(issues, Issue{Path: "tools." + tool.Name, Message: fmt.Sprintf("name uses %s while other tools use %s", style, expected)})
+		}
+	}
+
+	return issues
+}
+
+func checkToolOutputSchemas(spec *config.MCPSpec) []Issue {
+	var issues []Issue
+	for _, tool := range spec.Tools {
+		if tool.OutputSchema == nil {
+			issues = append(issues, Issue{Path: "tools." + tool.Name, Message: "missing output schema"})
+		}
+	}
+	return issues
+}
+
+func checkDescriptionPlaceholders(spec *config.MCPSpec) []Issue {
+	var issues []Issue
+	check := func(path, description string) {
+		if config.IsDescriptionPlaceholder(description) {
+			issues = append(issues, Issue{Path: path, Message: "placeholder description"})
+		}
+	}
+
+	for _, tool := range spec.Tools {
+		check("tools."+tool.Name, tool.Description)
+	}
+	for _, resource := range spec.Resources {
+		check("resources."+resource.Name, resource.Description)
+	}
+	for _, prompt := range spec.Prompts {
+		check("prompts."+prompt.Name, prompt.Description)
+		for _, arg := range prompt.Arguments {
+			check("prompts."+prompt.Name+".arguments."+arg.Name, arg.Description)
+		}
+	}
+	walkSpecSchemas(spec, func(path string, s *config.Schema) {
+		check(path, s.Description)
+	})
+
+	return issues
+}
+
+func namingStyle(name string) string {
+	hasUnderscore := strings.Contains(name, "_")
+	hasDash := strings.Contains(name, "-")
+	switch {
+	case hasUnderscore && hasDash:
+		return "mixed"
+	case hasUnderscore:
+		return "snake_case"
+	case hasDash:
+		return "kebab-case"
+	}
+	return ""
+}
+
+// walkSpecSchemas calls fn for every schema defined in the spec: components,
+// tool input and output schemas, and resource schemas, including nested
+// properties and array items. References are not followed.
+func walkSpecSchemas(spec *config.MCPSpec, fn func(path string, s *config.Schema)) {
+	for _, name := range sortedKeys(spec.Components.Schemas) {
+		walkSchema("components.schemas."+name, spec.Components.Schemas[name], fn)
+	}
+	for _, tool := range spec.Tools {
+		walkSchema("tools."+tool.Name+".inputSchema", tool.InputSchema, fn)
+		walkSchema("tools."+tool.Name+".outputSchema", tool.OutputSchema, fn)
+	}
+	for _, resource := range spec.Resources {
+		walkSchema("resources."+resource.Name+".schema", resource.Schema, fn)
+	}
+}
+
+func walkSchema(path string, s *config.Schema, fn func(path string, s *config.Schema)) {
+	if s == nil || s.Ref != "" {
+		return
+	}
+
+	fn(path, s)
+
+	for _, name := range sortedKeys(s.Properties) {
+		walkSchema(path+"."+name, s.Properties[name], fn)
+	}
+	if s.Items != nil {
+		walkSchema(path+"[]", s.Items, fn)
+	}
+	for i, sub := range s.AnyOf {
+		walkSchema(fmt.Sprintf("%s.anyOf[%d]", path, i), sub, fn)
+	}
+}
+
+func sortedKeys(m map[string]*schema.Schema) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,155 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.probo.inc/mcpgen/internal/config"
+)
+
+func issuesForRule(issues []Issue, rule string) []Issue {
+	var result []Issue
+	for _, issue := range issues {
+		if issue.Rule == rule {
+			result = append(result, issue)
+		}
+	}
+	return result
+}
+
+func TestLint(t *testing.T) {
+	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Components: config.Components{
+			Schemas: map[string]*config.Schema{
+				"Status": {Type: "string", Enum: []any{"open", "closed"}},
+			},
+		},
+		Tools: []config.Tool{
+			{
+				Name:        "create_task",
+				Description: "Create a task",
+				InputSchema: &config.Schema{
+					Type: "object",
+					Properties: map[string]*config.Schema{
+						"title":  {Type: "string", Description: "Task title"},
+						"notes":  {Type: "string"},
+						"status": {Ref: "#/components/schemas/Status"},
+					},
+				},
+				OutputSchema: &config.Schema{Type: "object"},
+			},
+			{
+				Name:        "list-tasks",
+				InputSchema: &config.Schema{Type: "object"},
+			},
+			{
+				Name:         "get_task-details",
+				Description:  "Get a task",
+				InputSchema:  &config.Schema{Type: "object"},
+				OutputSchema: &config.Schema{Type: "object"},
+			},
+		},
+		Resources: []config.Resource{
+			{Name: "readme", URI: "docs://readme"},
+		},
+		Prompts: []config.Prompt{
+			{
+				Name:        "help",
+				Description: "Get help",
+				Arguments:   []config.PromptArgument{{Name: "topic"}},
+			},
+		},
+	}
+
+	issues, err := Lint(spec, config.LintConfig{})
+	require.NoError(t, err)
+
+	tests := []struct {
+		rule  string
+		paths []string
+	}{
+		{"tool-description", []string{"tools.list-tasks"}},
+		{"resource-description", []string{"resources.readme"}},
+		{"prompt-description", []string{"prompts.help.arguments.topic"}},
+		{"property-description", []string{"tools.create_task.inputSchema.notes"}},
+		{"enum-description", []string{"components.schemas.Status"}},
+		{"tool-naming", []string{"tools.list-tasks", "tools.get_task-details"}},
+		{"tool-output-schema", []string{"tools.list-tasks"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			var paths []string
+			for _, issue := range issuesForRule(issues, tt.rule) {
+				paths = append(paths, issue.Path)
+			}
+			assert.Equal(t, tt.paths, paths)
+		})
+	}
+}
+
+func TestLintDisabledRules(t *testing.T) {
+	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Tools: []config.Tool{
+			{Name: "ping", InputSchema: &config.Schema{Type: "object"}},
+		},
+	}
+
+	issues, err := Lint(spec, config.LintConfig{
+		Rules: map[string]bool{
+			"tool-description":   false,
+			"tool-output-schema": false,
+			"tool-naming":        true,
+		},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+}
+
+func TestLintDescriptionPlaceholders(t *testing.T) {
+	spec := &config.MCPSpec{
+		Info: config.ServerInfo{Title: "test", Version: "1.0.0"},
+		Tools: []config.Tool{
+			{
+				Name:        "ping",
+				Description: "TODO: describe ping",
+				InputSchema: &config.Schema{
+					Type: "object",
+					Properties: map[string]*config.Schema{
+						"host":  {Type: "string", Description: "TODO: describe host"},
+						"count": {Type: "integer", Description: "Number of pings"},
+					},
+				},
+			},
+		},
+	}
+
+	issues, err := Lint(spec, config.LintConfig{})
+	require.NoError(t, err)
+
+	assert.Equal(
+		t,
+		[]Issue{
+			{Rule: "description-placeholder", Path: "tools.ping", Message: "placeholder description"},
+			{Rule: "description-placeholder", Path: "tools.ping.inputSchema.host", Message: "placeholder description"},
+		},
+		issuesForRule(issues, "description-placeholder"),
+	)
+}
+
+func TestLintUnknownRule(t *testing.T) {
+	spec := &config.MCPSpec{Info: config.ServerInfo{Title: "test", Version: "1.0.0"}}
+
+	_, err := Lint(spec, config.LintConfig{Rules: map[string]bool{"no-such-rule": false}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no-such-rule")
+}
+
+func TestIssueString(t *testing.T) {
+	issue := Issue{Rule: "tool-description", Path: "tools.ping", Message: "missing description"}
+	assert.Equal(t, "tools.ping: missing description (tool-description)", issue.String())
+}
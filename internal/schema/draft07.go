@@ -0,0 +1,258 @@
+package schema
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Draft202012 is the meta-schema URI of the JSON Schema version mcpgen
+// generates code from.
+const Draft202012 = "https://json-schema.org/draft/2020-12/schema"
+
+// Keywords whose value is a subschema, an array of subschemas or a map of
+// subschemas, walked when normalizing nested schemas.
+var (
+	subschemaKeywords = []string{
+		"items", "additionalItems", "additionalProperties", "not", "if", "then", "else",
+		"contains", "propertyNames", "unevaluatedItems", "unevaluatedProperties", "contentSchema",
+	}
+	subschemaArrayKeywords = []string{"allOf", "anyOf", "oneOf", "prefixItems"}
+	subschemaMapKeywords   = []string{"properties", "patternProperties", "$defs", "dependentSchemas"}
+)
+
+// Normalize rewrites, in place, the draft-07 (and older) keywords of a JSON
+// schema decoded into generic values to their 2020-12 equivalents:
+//
+//   - definitions becomes $defs, and #/definitions/ references point to $defs
+//   - dependencies is split into dependentRequired and dependentSchemas
+//   - boolean exclusiveMinimum/exclusiveMaximum become numeric bounds
+//   - array-form items becomes prefixItems, and additionalItems becomes items
+//   - the OpenAPI 3.0 nullable keyword becomes a null type, see nullable
+//
+// Constructs that cannot be represented are dropped or kept as-is, and
+// reported in the returned warnings, prefixed with path. When the document
+// declares an older draft in $schema, it is updated to 2020-12 and keywords
+// whose meaning changed (id, siblings of $ref) are reported too.
+func Normalize(path string, doc any) []string {
+	n := &normalizer{}
+
+	if m, ok := doc.(map[string]any); ok {
+		if uri, ok := m["$schema"].(string); ok && isLegacyDraft(uri) {
+			n.legacy = true
+			m["$schema"] = Draft202012
+		}
+	}
+
+	n.schema(path, doc)
+	return n.warnings
+}
+
+type normalizer struct {
+	legacy   bool
+	warnings []string
+}
+
+func (n *normalizer) warnf(path, format string, args ...any) {
+	n.warnings = append(n.warnings, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (n *normalizer) schema(path string, v any) {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return
+	}
+
+	if n.legacy {
+		if id, ok := m["id"].(string); ok {
+			if _, exists := m["$id"]; !exists {
+				m["$id"] = id
+				delete(m, "id")
+			}
+		}
+		if _, ok := m["$ref"]; ok && len(m) > 1 {
+			n.warnf(path, "keywords next to $ref are ignored by draft-07 but applied by 2020-12")
+		}
+	}
+
+	if ref, ok := m["$ref"].(string); ok && strings.Contains(ref, "#/definitions/") {
+		m["$ref"] = strings.Replace(ref, "#/definitions/", "#/$defs/", 1)
+	}
+
+	if defs, ok := m["definitions"]; ok {
+		if _, exists := m["$defs"]; exists {
+			n.warnf(path, "both definitions and $defs are set, definitions dropped")
+		} else {
+			m["$defs"] = defs
+		}
+		delete(m, "definitions")
+	}
+
+	n.exclusiveBound(path, m, "exclusiveMinimum", "minimum")
+	n.exclusiveBound(path, m, "exclusiveMaximum", "maximum")
+	n.dependencies(path, m)
+	n.nullable(m)
+
+	if items, ok := m["items"].([]any); ok {
+		if _, exists := m["prefixItems"]; exists {
+			n.warnf(path, "both array items and prefixItems are set, items dropped")
+		} else {
+			m["prefixItems"] = items
+		}
+		delete(m, "items")
+		if additional, ok := m["additionalItems"]; ok {
+			m["items"] = additional
+			delete(m, "additionalItems")
+		}
+	}
+
+	for _, kw := range subschemaKeywords {
+		if sub, ok := m[kw]; ok {
+			n.schema(path+"."+kw, sub)
+		}
+	}
+	for _, kw := range subschemaArrayKeywords {
+		if subs, ok := m[kw].([]any); ok {
+			for i, sub := range subs {
+				n.schema(fmt.Sprintf("%s.%s[%d]", path, kw, i), sub)
+			}
+		}
+	}
+	for _, kw := range subschemaMapKeywords {
+		if subs, ok := m[kw].(map[string]any); ok {
+			for _, name := range sortedNames(subs) {
+				n.schema(path+"."+kw+"."+name, subs[name])
+			}
+		}
+	}
+}
+
+// exclusiveBound converts the draft-04 boolean form of exclusiveMinimum or
+// exclusiveMaximum, which modifies bound, to the numeric form.
+func (n *normalizer) exclusiveBound(path string, m map[string]any, keyword, bound string) {
+	exclusive, ok := m[keyword].(bool)
+	if !ok {
+		return
+	}
+	delete(m, keyword)
+
+	if !exclusive {
+		return
+	}
+
+	value, ok := m[bound]
+	if !ok {
+		n.warnf(path, "%s: true without %s dropped", keyword, bound)
+		return
+	}
+	m[keyword] = value
+	delete(m, bound)
+}
+
+// nullable converts the OpenAPI 3.0 nullable keyword, which allows null on
+// top of the values of the schema: null is added to the type of the schema
+// or, for schemas without a type such as references, the schema becomes an
+// anyOf of its constraints and null, keeping its annotations.
+func (n *normalizer) nullable(m map[string]any) {
+	nullable, ok := m["nullable"].(bool)
+	if !ok {
+		return
+	}
+	delete(m, "nullable")
+	if !nullable {
+		return
+	}
+
+	switch t := m["type"].(type) {
+	case string:
+		m["type"] = []any{t, "null"}
+		return
+	case []any:
+		if !slices.Contains(t, any("null")) {
+			m["type"] = append(t, "null")
+		}
+		return
+	}
+
+	constraints := map[string]any{}
+	for key, value := range m {
+		if !isAnnotation(key) {
+			constraints[key] = value
+			delete(m, key)
+		}
+	}
+	if len(constraints) > 0 {
+		m["anyOf"] = []any{constraints, map[string]any{"type": "null"}}
+	}
+}
+
+// isAnnotation reports whether a keyword describes a schema rather than
+// constrains its values, extensions included.
+func isAnnotation(keyword string) bool {
+	switch keyword {
+	case "title", "description", "default", "examples", "deprecated", "readOnly", "writeOnly", "$comment":
+		return true
+	}
+	return strings.HasPrefix(keyword, "x-") || strings.Contains(keyword, "/")
+}
+
+// dependencies splits the draft-07 dependencies keyword: property lists become
+// dependentRequired and schemas become dependentSchemas.
+func (n *normalizer) dependencies(path string, m map[string]any) {
+	deps, ok := m["dependencies"].(map[string]any)
+	if !ok {
+		return
+	}
+	delete(m, "dependencies")
+
+	required := map[string]any{}
+	schemas := map[string]any{}
+	for _, name := range sortedNames(deps) {
+		switch dep := deps[name].(type) {
+		case []any:
+			required[name] = dep
+		case map[string]any, bool:
+			schemas[name] = dep
+		default:
+			n.warnf(path, "dependencies.%s has unsupported value %v, dropped", name, dep)
+		}
+	}
+
+	mergeKeyword(m, "dependentRequired", required)
+	mergeKeyword(m, "dependentSchemas", schemas)
+}
+
+func mergeKeyword(m map[string]any, keyword string, values map[string]any) {
+	if len(values) == 0 {
+		return
+	}
+	existing, ok := m[keyword].(map[string]any)
+	if !ok {
+		m[keyword] = values
+		return
+	}
+	for k, v := range values {
+		if _, exists := existing[k]; !exists {
+			existing[k] = v
+		}
+	}
+}
+
+func isLegacyDraft(uri string) bool {
+	for _, draft := range []string{"draft-04", "draft-06", "draft-07"} {
+		if strings.Contains(uri, "json-schema.org/"+draft+"/schema") {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedNames(m map[string]any) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
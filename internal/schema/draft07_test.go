@@ -0,0 +1,126 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decode(t *testing.T, s string) any {
+	t.Helper()
+	var doc any
+	require.NoError(t, json.Unmarshal([]byte(s), &doc))
+	return doc
+}
+
+func TestNormalize(t *testing.T) {
+	doc := decode(t, `{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"id": "task",
+		"type": "object",
+		"definitions": {
+			"Tag": {"type": "string"}
+		},
+		"properties": {
+			"tags": {"type": "array", "items": {"$ref": "#/definitions/Tag"}},
+			"count": {"type": "integer", "minimum": 0, "exclusiveMinimum": true},
+			"limit": {"type": "integer", "maximum": 10, "exclusiveMaximum": false},
+			"point": {"type": "array", "items": [{"type": "number"}, {"type": "number"}], "additionalItems": false},
+			"owner": {"$ref": "#/definitions/Tag", "description": "Owner"}
+		},
+		"dependencies": {
+			"count": ["limit"],
+			"limit": {"required": ["count"]}
+		}
+	}`)
+
+	warnings := Normalize("task.json", doc)
+
+	want := decode(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$id": "task",
+		"type": "object",
+		"$defs": {
+			"Tag": {"type": "string"}
+		},
+		"properties": {
+			"tags": {"type": "array", "items": {"$ref": "#/$defs/Tag"}},
+			"count": {"type": "integer", "exclusiveMinimum": 0},
+			"limit": {"type": "integer", "maximum": 10},
+			"point": {"type": "array", "prefixItems": [{"type": "number"}, {"type": "number"}], "items": false},
+			"owner": {"$ref": "#/$defs/Tag", "description": "Owner"}
+		},
+		"dependentRequired": {"count": ["limit"]},
+		"dependentSchemas": {"limit": {"required": ["count"]}}
+	}`)
+	assert.Equal(t, want, doc)
+
+	assert.Equal(t, []string{
+		"task.json.properties.owner: keywords next to $ref are ignored by draft-07 but applied by 2020-12",
+	}, warnings)
+
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+
+	var s Schema
+	require.NoError(t, json.Unmarshal(data, &s))
+	assert.Equal(t, 0.0, *s.Properties["count"].ExclusiveMinimum)
+	assert.Len(t, s.Properties["point"].PrefixItems, 2)
+}
+
+func TestNormalizeUnrepresentable(t *testing.T) {
+	doc := decode(t, `{
+		"type": "integer",
+		"exclusiveMaximum": true,
+		"dependencies": {"a": 1}
+	}`)
+
+	warnings := Normalize("x", doc)
+
+	assert.Equal(t, map[string]any{"type": "integer"}, doc)
+	assert.Equal(t, []string{
+		"x: exclusiveMaximum: true without maximum dropped",
+		"x: dependencies.a has unsupported value 1, dropped",
+	}, warnings)
+}
+
+func TestNormalizeKeeps202012(t *testing.T) {
+	input := `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"$ref": "#/$defs/A",
+		"description": "kept",
+		"exclusiveMinimum": 1,
+		"$defs": {"A": {"type": "string"}}
+	}`
+	doc := decode(t, input)
+
+	assert.Empty(t, Normalize("x", doc))
+	assert.Equal(t, decode(t, input), doc)
+}
+
+func TestNormalizeNullable(t *testing.T) {
+	doc := decode(t, `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "nullable": true},
+			"tags": {"type": ["array", "null"], "nullable": true, "items": {"type": "string"}},
+			"count": {"type": "integer", "nullable": false},
+			"owner": {"$ref": "#/$defs/User", "nullable": true, "description": "Owner", "x-order": 1},
+			"any": {"nullable": true, "description": "Anything"}
+		}
+	}`)
+
+	assert.Empty(t, Normalize("x", doc))
+	assert.Equal(t, decode(t, `{
+		"type": "object",
+		"properties": {
+			"name": {"type": ["string", "null"]},
+			"tags": {"type": ["array", "null"], "items": {"type": "string"}},
+			"count": {"type": "integer"},
+			"owner": {"anyOf": [{"$ref": "#/$defs/User"}, {"type": "null"}], "description": "Owner", "x-order": 1},
+			"any": {"description": "Anything"}
+		}
+	}`), doc)
+}
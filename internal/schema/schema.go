@@ -1,10 +1,12 @@
 package schema
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"github.com/google/jsonschema-go/jsonschema"
 )
@@ -12,8 +14,9 @@ import (
 type Schema = jsonschema.Schema
 
 type Loader struct {
-	schemas map[string]*Schema
-	baseDir string
+	schemas  map[string]*Schema
+	baseDir  string
+	warnings []string
 }
 
 func NewLoader(baseDir string) *Loader {
@@ -38,6 +41,12 @@ func (l *Loader) Load(path string) (*Schema, error) {
 		return nil, fmt.Errorf("failed to read schema file %s: %w", path, err)
 	}
 
+	data, warnings, err := normalizeDocument(path, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema file %s: %w", path, err)
+	}
+	l.warnings = append(l.warnings, warnings...)
+
 	var schema Schema
 	if err := json.Unmarshal(data, &schema); err != nil {
 		return nil, fmt.Errorf("failed to parse schema file %s: %w", path, err)
@@ -48,6 +57,31 @@ func (l *Loader) Load(path string) (*Schema, error) {
 	return &schema, nil
 }
 
+// Warnings returns the problems found while normalizing the loaded schema
+// files to JSON Schema 2020-12.
+func (l *Loader) Warnings() []string {
+	return l.warnings
+}
+
+func normalizeDocument(path string, data []byte) ([]byte, []string, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, nil, err
+	}
+
+	warnings := Normalize(path, doc)
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, warnings, nil
+}
+
 func GetType(s *Schema) string {
 	if s.Type != "" {
 		return s.Type
@@ -67,6 +101,31 @@ func IsRequired(s *Schema, propName string) bool {
 	return false
 }
 
+// IsFalse reports whether s is the false schema, which accepts no value.
+func IsFalse(s *Schema) bool {
+	if s == nil {
+		return false
+	}
+	data, err := json.Marshal(s)
+	return err == nil && string(data) == "false"
+}
+
+// IsClosed reports whether an object schema rejects the properties it does
+// not declare, that is additionalProperties is false and no patternProperties
+// allow other names. unevaluatedProperties: false closes the object the same
+// way when no subschema evaluates other properties.
+func IsClosed(s *Schema) bool {
+	if len(s.PatternProperties) > 0 {
+		return false
+	}
+	if IsFalse(s.AdditionalProperties) {
+		return true
+	}
+	return IsFalse(s.UnevaluatedProperties) && s.Ref == "" &&
+		len(s.AllOf) == 0 && len(s.AnyOf) == 0 && len(s.OneOf) == 0 &&
+		s.If == nil && len(s.DependentSchemas) == 0
+}
+
 // IsOmittable checks if a schema property has the go.probo.inc/mcpgen/omittable annotation set to true.
 // This is used to wrap fields in mcp.Omittable[T] to distinguish between
 // "not set", "set to null", and "set to value".
@@ -83,3 +142,34 @@ func IsOmittable(s *Schema) bool {
 
 	return false
 }
+
+// PropertyOrderKey is the annotation listing the properties of a schema in
+// the order of the spec, which the spec loader records when
+// model.property_order is spec.
+const PropertyOrderKey = "go.probo.inc/mcpgen/property-order"
+
+// PropertyNames returns the names of the properties of s in the order of
+// its go.probo.inc/mcpgen/property-order annotation, followed by those the
+// annotation does not list, sorted by name.
+func PropertyNames(s *Schema) []string {
+	names := make([]string, 0, len(s.Properties))
+	listed := make(map[string]bool, len(s.Properties))
+	if order, ok := s.Extra[PropertyOrderKey].([]any); ok {
+		for _, name := range order {
+			name, ok := name.(string)
+			if _, exists := s.Properties[name]; ok && exists && !listed[name] {
+				names = append(names, name)
+				listed[name] = true
+			}
+		}
+	}
+
+	rest := make([]string, 0, len(s.Properties)-len(names))
+	for name := range s.Properties {
+		if !listed[name] {
+			rest = append(rest, name)
+		}
+	}
+	slices.Sort(rest)
+	return append(names, rest...)
+}
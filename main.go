@@ -1,18 +1,33 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime/pprof"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"go.probo.inc/mcpgen/internal/codegen"
 	"go.probo.inc/mcpgen/internal/config"
+	"go.probo.inc/mcpgen/internal/diff"
+	"go.probo.inc/mcpgen/internal/extract"
+	"go.probo.inc/mcpgen/internal/importer/graphql"
+	"go.probo.inc/mcpgen/internal/importer/openapi"
+	"go.probo.inc/mcpgen/internal/importer/proto"
+	"go.probo.inc/mcpgen/internal/lint"
 )
 
 var version = "dev"
 
+// frozen forbids network access when loading remote $ref, see --frozen.
+var frozen bool
+
 func main() {
+	codegen.Version = version
+
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
@@ -40,47 +55,434 @@ var generateCmd = &cobra.Command{
 	Long: `Reads mcpgen.yaml (or mcpgen.yml) configuration file and generates:
   - Type-safe Go structs from JSON Schemas
   - MCP server boilerplate code
-  - Handler function stubs for tools, resources, and prompts`,
+  - Handler function stubs for tools, resources, and prompts
+
+With --only, only the listed stages are generated, e.g. --only models when only
+component schemas changed. Stages: models, server, openapi, fake, resolver. A kind
+of primitive can be given instead, tools, resources or prompts: its stages run
+and the resolver only gets the handlers of that kind added or orphaned.
+
+With --dry-run, no file is written: the files that would be created, updated
+or left untouched are printed, along with the resolver handlers that would be
+added, orphaned or restored.
+
+With --check, no file is written: the command exits with a non-zero status if
+any generated file is missing or out of date, for use as a CI gate.
+
+With --trace, the time spent loading the spec, resolving references, executing
+templates, formatting and writing each file is printed to stderr once done.
+With --cpuprofile, a CPU profile of the generation is written in pprof format,
+to inspect with go tool pprof.
+
+When a resolver handler disappears from the spec while one with a similar name
+appears, generate asks whether it is a rename: if so, the existing body is kept
+under the new handler signature instead of being orphaned. With
+--assume-rename, every such pair is treated as a rename without asking.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		configFile, _ := cmd.Flags().GetString("config")
-		return runGenerate(configFile)
+		only, _ := cmd.Flags().GetStringSlice("only")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		check, _ := cmd.Flags().GetBool("check")
+		assumeRename, _ := cmd.Flags().GetBool("assume-rename")
+		cpuProfile, _ := cmd.Flags().GetString("cpuprofile")
+
+		var trace *codegen.Trace
+		if traced, _ := cmd.Flags().GetBool("trace"); traced {
+			trace = codegen.NewTrace()
+			defer func() {
+				fmt.Fprintln(os.Stderr, "Generation trace:")
+				_ = trace.Write(os.Stderr)
+			}()
+		}
+
+		if cpuProfile != "" {
+			stop, err := startCPUProfile(cpuProfile)
+			if err != nil {
+				return err
+			}
+			defer stop()
+		}
+
+		if dryRun {
+			return runGeneratePlan(configFile, only, assumeRename, trace)
+		}
+		if check {
+			cmd.SilenceUsage = true
+			return runGenerateCheck(configFile, only, assumeRename, trace)
+		}
+		return runGenerate(configFile, only, assumeRename, trace)
+	},
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate mcpgen configuration and MCP specification",
+	Long: `Loads mcpgen.yaml (or mcpgen.yml) and the MCP specification, resolves all
+schema references and builds the models in memory, reporting any problem
+without writing files.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configFile, _ := cmd.Flags().GetString("config")
+		return runValidate(configFile)
+	},
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that the target module can build the generated code",
+	Long: `Checks the go.mod of the module the code is generated into against what the
+generated code requires: the go directive, the versions of the MCP SDK,
+jsonschema-go and the mcpgen runtime, and that the package paths of the
+configuration match the import paths computed from the module path.
+
+A fix is suggested for every failed check, and the command exits with a
+non-zero status when any check fails.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configFile, _ := cmd.Flags().GetString("config")
+		return runDoctor(configFile)
+	},
+}
+
+var releaseCmd = &cobra.Command{
+	Use:   "release [main-package]",
+	Short: "Build the server for distribution to end users",
+	Long: `Builds the main package of the server, . by default, for every --target
+with go build, without cgo, setting the version and the spec hash reported by
+the generated ServerInfo. Each binary is archived in the --output directory, a
+.zip for Windows and a .tar.gz otherwise, with a client configuration snippet
+running it over stdio. The SHA-256 checksums of the archives are written to
+checksums.txt.
+
+The version defaults to info.version of the spec, and the binary is named
+after the directory of the main package unless --name is set.
+
+With --homebrew and --scoop, a Homebrew formula installing the macOS and Linux
+binaries and a Scoop manifest installing the Windows ones are written too,
+downloading the archives from --download-url once published there. With --npm,
+an npm package is written in the npm directory of --output: its launcher
+downloads the binary of the platform from --download-url on first run, for the
+clients starting servers with npx. With --registry-name, a server.json manifest
+publishing the npm package, or the deployment at --remote-url, to MCP server
+registries is written too.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configFile, _ := cmd.Flags().GetString("config")
+		opts := codegen.ReleaseOptions{}
+		if len(args) > 0 {
+			opts.Main = args[0]
+		}
+		opts.Name, _ = cmd.Flags().GetString("name")
+		opts.Version, _ = cmd.Flags().GetString("version")
+		opts.Targets, _ = cmd.Flags().GetStringSlice("target")
+		opts.Output, _ = cmd.Flags().GetString("output")
+		opts.DownloadURL, _ = cmd.Flags().GetString("download-url")
+		opts.Homepage, _ = cmd.Flags().GetString("homepage")
+		opts.Homebrew, _ = cmd.Flags().GetBool("homebrew")
+		opts.Scoop, _ = cmd.Flags().GetBool("scoop")
+		opts.NPM, _ = cmd.Flags().GetBool("npm")
+		opts.NPMPackage, _ = cmd.Flags().GetString("npm-package")
+		opts.RegistryName, _ = cmd.Flags().GetString("registry-name")
+		opts.RemoteURL, _ = cmd.Flags().GetString("remote-url")
+		return runRelease(configFile, opts)
+	},
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <old-spec> [new-spec]",
+	Short: "Compare two MCP specifications and detect breaking changes",
+	Long: `Compares two MCP specification files and reports added, removed and changed
+tools, resources, prompts and schema fields. When new-spec is omitted, the spec
+referenced by the configuration file is used, and old-spec is loaded with the
+files the configuration includes.
+
+Exits with a non-zero status when breaking changes are found.`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configFile, _ := cmd.Flags().GetString("config")
+		newSpec := ""
+		if len(args) > 1 {
+			newSpec = args[1]
+		}
+		cmd.SilenceUsage = true
+		return runDiff(configFile, args[0], newSpec)
+	},
+}
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check the MCP specification for style issues",
+	Long: `Checks the MCP specification for style issues such as missing descriptions,
+inconsistent tool naming or tools without output schemas.
+
+Rules can be enabled or disabled in the lint block of mcpgen.yaml:
+
+  lint:
+    rules:
+      tool-output-schema: false
+
+With --fix, TODO description placeholders are inserted into the spec for
+tools and schema properties lacking a description. The placeholders are then
+reported by the description-placeholder rule until they are written.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configFile, _ := cmd.Flags().GetString("config")
+		listRules, _ := cmd.Flags().GetBool("list-rules")
+		fix, _ := cmd.Flags().GetBool("fix")
+		if listRules {
+			for _, rule := range lint.Rules {
+				fmt.Printf("%-22s %s\n", rule.Name, rule.Description)
+			}
+			return nil
+		}
+		return runLint(configFile, fix)
+	},
+}
+
+var fmtCmd = &cobra.Command{
+	Use:   "fmt [spec-files...]",
+	Short: "Format MCP specification files",
+	Long: `Normalizes the layout of MCP specification files: canonical key ordering
+(info, components, experiments, tools, resources, prompts), sorted schema names and two-space
+indentation. When no file is given, the spec referenced by the configuration
+file and the files it includes are formatted.
+
+With --check, files are not modified and the command exits with a non-zero
+status if any of them is not formatted.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configFile, _ := cmd.Flags().GetString("config")
+		check, _ := cmd.Flags().GetBool("check")
+		return runFmt(configFile, args, check)
+	},
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Convert other API descriptions into an MCP specification",
+}
+
+var importOpenAPICmd = &cobra.Command{
+	Use:   "openapi [document]",
+	Short: "Convert an OpenAPI 3.x document into an MCP specification",
+	Long: `Converts an OpenAPI 3.x document into an MCP specification. Every operation
+becomes a tool taking its parameters and request body as input and returning
+its successful JSON response. Component schemas are copied.
+
+Operations can be filtered by tag or path pattern, with flags or in the import
+block of mcpgen.yaml, which can also name the document:
+
+  import:
+    openapi:
+      document: api/openapi.yaml
+      tags: [pets]
+      paths: ["/pets/*"]
+
+Flags take precedence over the configuration. The spec is written to stdout
+unless --output is set.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		configFile, _ := cmd.Flags().GetString("config")
+		output, _ := cmd.Flags().GetString("output")
+		tags, _ := cmd.Flags().GetStringSlice("tag")
+		paths, _ := cmd.Flags().GetStringSlice("path")
+		document := ""
+		if len(args) > 0 {
+			document = args[0]
+		}
+		return runImportOpenAPI(configFile, cmd.Flags().Changed("config"), document, output, tags, paths)
+	},
+}
+
+var importGraphQLCmd = &cobra.Command{
+	Use:   "graphql <file>...",
+	Short: "Convert a GraphQL schema into an MCP specification",
+	Long: `Converts a GraphQL schema, in SDL, into an MCP specification. Every query and
+mutation field becomes a tool taking its arguments as input and returning its
+type when that is an object; queries are marked read-only. Input, object and
+enum types become component schemas and descriptions are kept. Subscriptions
+are skipped.
+
+A schema split across files is merged, type extensions included.
+
+The spec is written to stdout unless --output is set.`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		operations, _ := cmd.Flags().GetStringSlice("operation")
+		return runImportGraphQL(args, output, operations)
+	},
+}
+
+var importProtoCmd = &cobra.Command{
+	Use:   "proto <file>...",
+	Short: "Convert gRPC services of .proto files into an MCP specification",
+	Long: `Converts the gRPC services declared in .proto files into an MCP specification.
+Every unary RPC becomes a tool taking its request message as input and
+returning its response message, mapped to JSON Schema following the proto3
+JSON encoding. Comments become descriptions. Streaming RPCs are skipped.
+
+Pass every file declaring a type the services use; well-known google.protobuf
+types are built in. Compiled descriptor sets are not supported.
+
+The spec is written to stdout unless --output is set.`,
+	Args:         cobra.MinimumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		services, _ := cmd.Flags().GetStringSlice("service")
+		methods, _ := cmd.Flags().GetStringSlice("method")
+		return runImportProto(args, output, services, methods)
+	},
+}
+
+var extractCmd = &cobra.Command{
+	Use:   "extract [package-dir]",
+	Short: "Derive an MCP specification from annotated Go code",
+	Long: `Scans the Go package in package-dir (default: current directory) for handler
+functions marked with a directive comment and writes the MCP specification
+they describe, for projects keeping Go as the source of truth:
+
+  // Creates an event in the calendar.
+  //
+  //mcp:tool name=create_event idempotent
+  func (r *Resolver) CreateEventTool(ctx context.Context, req *mcp.CallToolRequest, input *CreateEventInput) (*mcp.CallToolResult, Event, error)
+
+Directives:
+  //mcp:tool [name=...] [readonly] [destructive] [idempotent] [openworld]
+  //mcp:resource uri=... [name=...] [mime=...]
+  //mcp:prompt [name=...]
+  //mcp:schema    on a type, to add it to the component schemas
+
+Doc comments become descriptions. Tool input and output schemas and prompt
+arguments are derived from the structs of the signature: json tags name the
+properties and jsonschema tags, or field comments, describe them. Names
+default to the function name in snake_case, without its Tool, Resource or
+Prompt suffix.
+
+The spec is written to stdout unless --output is set.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		title, _ := cmd.Flags().GetString("title")
+		version, _ := cmd.Flags().GetString("version")
+		dir := "."
+		if len(args) > 0 {
+			dir = args[0]
+		}
+		return runExtract(dir, output, extract.Options{Title: title, Version: version})
 	},
 }
 
 var initCmd = &cobra.Command{
 	Use:   "init [name]",
 	Short: "Initialize a new MCP server project",
-	Long:  `Creates a new MCP server project with example configuration and file structure.`,
-	Args:  cobra.MaximumNArgs(1),
+	Long: `Creates a new MCP server project with example configuration and file structure.
+
+With --from-openapi, the spec is generated from an OpenAPI 3.x document
+instead: every operation becomes a tool taking its parameters and request body
+as input and returning its successful JSON response.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		name := "my-mcp-server"
 		if len(args) > 0 {
 			name = args[0]
 		}
-		return runInit(name)
+		fromOpenAPI, _ := cmd.Flags().GetString("from-openapi")
+		return runInit(name, fromOpenAPI)
 	},
 }
 
 func init() {
+	rootCmd.PersistentFlags().BoolVar(&frozen, "frozen", false, "Fail instead of fetching remote $ref documents missing from mcpgen.lock or its cache")
 	generateCmd.Flags().StringP("config", "c", "mcpgen.yaml", "Path to config file")
+	generateCmd.Flags().Bool("check", false, "Report out-of-date generated files without modifying them")
+	generateCmd.Flags().Bool("dry-run", false, "Print the files generation would change without writing them")
+	generateCmd.Flags().StringSlice("only", nil, "Generate only these stages (models, server, openapi, fake, resolver) or kinds (tools, resources, prompts)")
+	generateCmd.Flags().Bool("assume-rename", false, "Treat removed handlers with a similar new handler as renamed without asking")
+	generateCmd.Flags().Bool("trace", false, "Print the time spent in each generation step")
+	generateCmd.Flags().String("cpuprofile", "", "Write a CPU profile of the generation to this file")
+	validateCmd.Flags().StringP("config", "c", "mcpgen.yaml", "Path to config file")
+	doctorCmd.Flags().StringP("config", "c", "mcpgen.yaml", "Path to config file")
+	lintCmd.Flags().StringP("config", "c", "mcpgen.yaml", "Path to config file")
+	lintCmd.Flags().Bool("list-rules", false, "List available lint rules")
+	lintCmd.Flags().Bool("fix", false, "Insert TODO placeholders for missing descriptions")
+	fmtCmd.Flags().StringP("config", "c", "mcpgen.yaml", "Path to config file (used when no spec file is given)")
+	fmtCmd.Flags().Bool("check", false, "Report unformatted files without modifying them")
+	diffCmd.Flags().StringP("config", "c", "mcpgen.yaml", "Path to config file (used when new-spec is omitted)")
+	releaseCmd.Flags().StringP("config", "c", "mcpgen.yaml", "Path to config file")
+	releaseCmd.Flags().String("name", "", "Name of the binary and of the server in client configurations")
+	releaseCmd.Flags().String("version", "", "Version of the release (defaults to info.version of the spec)")
+	releaseCmd.Flags().StringSlice("target", codegen.DefaultReleaseTargets, "Platforms to build, as GOOS/GOARCH")
+	releaseCmd.Flags().StringP("output", "o", "dist", "Directory of the archives")
+	releaseCmd.Flags().Bool("homebrew", false, "Write a Homebrew formula installing the macOS and Linux binaries")
+	releaseCmd.Flags().Bool("scoop", false, "Write a Scoop manifest installing the Windows binaries")
+	releaseCmd.Flags().Bool("npm", false, "Write an npm package launching the binaries, for clients using npx")
+	releaseCmd.Flags().String("npm-package", "", "Name of the npm package (defaults to the binary name)")
+	releaseCmd.Flags().String("registry-name", "", "Write a server.json manifest publishing the server to MCP registries under this name, such as io.github.acme/tasks")
+	releaseCmd.Flags().String("remote-url", "", "URL of a deployment of the server over streamable HTTP, for the registry manifest")
+	releaseCmd.Flags().String("download-url", "", "URL the archives are published under, for the Homebrew formula, Scoop manifest and npm package")
+	releaseCmd.Flags().String("homepage", "", "Homepage of the server in the Homebrew formula, Scoop manifest and npm package")
+	importOpenAPICmd.Flags().StringP("config", "c", "mcpgen.yaml", "Path to config file (optional unless set)")
+	importOpenAPICmd.Flags().StringP("output", "o", "", "Write the spec to this file instead of stdout")
+	importOpenAPICmd.Flags().StringSlice("tag", nil, "Import only operations with one of these tags")
+	importOpenAPICmd.Flags().StringSlice("path", nil, "Import only operations whose path matches one of these patterns")
+	importGraphQLCmd.Flags().StringP("output", "o", "", "Write the spec to this file instead of stdout")
+	importGraphQLCmd.Flags().StringSlice("operation", nil, "Import only these query or mutation fields, as field or Type.field")
+	importProtoCmd.Flags().StringP("output", "o", "", "Write the spec to this file instead of stdout")
+	importProtoCmd.Flags().StringSlice("service", nil, "Import only these services, by name or full name")
+	importProtoCmd.Flags().StringSlice("method", nil, "Import only these RPCs, as Method or Service.Method")
+	extractCmd.Flags().StringP("output", "o", "", "Write the spec to this file instead of stdout")
+	extractCmd.Flags().String("title", "", "Server title (defaults to the package name)")
+	extractCmd.Flags().String("version", "", "Server version (defaults to 1.0.0)")
+	initCmd.Flags().String("from-openapi", "", "Generate the spec from an OpenAPI 3.x document")
 
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(generateCmd)
+	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(releaseCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(lintCmd)
+	rootCmd.AddCommand(fmtCmd)
 	rootCmd.AddCommand(initCmd)
+	importCmd.AddCommand(importOpenAPICmd)
+	importCmd.AddCommand(importGraphQLCmd)
+	importCmd.AddCommand(importProtoCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(extractCmd)
 }
 
-func runGenerate(configFile string) error {
+// resolveConfigFile falls back to mcpgen.yml when the default mcpgen.yaml
+// does not exist.
+func resolveConfigFile(configFile string) string {
 	if _, err := os.Stat(configFile); os.IsNotExist(err) {
 		if configFile == "mcpgen.yaml" {
 			if _, err := os.Stat("mcpgen.yml"); err == nil {
-				configFile = "mcpgen.yml"
+				return "mcpgen.yml"
 			}
 		}
 	}
+	return configFile
+}
+
+// loadConfig loads the configuration file and its spec with opts, honoring
+// --frozen.
+func loadConfig(configFile string, opts ...config.LoadOption) (*config.Config, *config.MCPSpec, error) {
+	return config.Load(configFile, append(opts, config.WithFrozen(frozen))...)
+}
+
+func runGenerate(configFile string, only []string, assumeRename bool, trace *codegen.Trace) error {
+	configFile = resolveConfigFile(configFile)
 
 	fmt.Printf("Loading configuration from %s...\n", configFile)
 
-	cfg, spec, err := config.Load(configFile)
+	endLoad := trace.Start("load spec")
+	cfg, spec, err := loadConfig(configFile)
+	endLoad()
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
@@ -88,18 +490,514 @@ func runGenerate(configFile string) error {
 	fmt.Printf("Generating code for %s v%s...\n", spec.Info.Title, spec.Info.Version)
 
 	gen := codegen.New(cfg, spec)
+	gen.SetRenameFunc(renameFunc(assumeRename, true))
+	gen.SetTrace(trace)
 
-	if err := gen.Generate(); err != nil {
+	if err := gen.Generate(only...); err != nil {
 		return fmt.Errorf("code generation failed: %w", err)
 	}
 
+	printWarnings(gen.Warnings())
+
 	fmt.Println("✓ Code generation completed successfully!")
 	return nil
 }
 
-func runInit(name string) error {
+func runGeneratePlan(configFile string, only []string, assumeRename bool, trace *codegen.Trace) error {
+	endLoad := trace.Start("load spec")
+	cfg, spec, err := loadConfig(resolveConfigFile(configFile), config.WithReadOnly(true))
+	endLoad()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	gen := codegen.New(cfg, spec)
+	gen.SetRenameFunc(renameFunc(assumeRename, false))
+	gen.SetTrace(trace)
+
+	plan, err := gen.Plan(only...)
+	if err != nil {
+		return fmt.Errorf("code generation failed: %w", err)
+	}
+
+	for _, file := range plan.Files {
+		fmt.Printf("%-10s %s\n", file.Action, file.Path)
+		for _, name := range file.AddedHandlers {
+			fmt.Printf("             + %s\n", name)
+		}
+		for _, name := range file.OrphanedHandlers {
+			fmt.Printf("             - %s (orphaned)\n", name)
+		}
+		for _, name := range file.RestoredHandlers {
+			fmt.Printf("             ~ %s (restored)\n", name)
+		}
+		for _, rename := range file.RenamedHandlers {
+			fmt.Printf("             ~ %s (renamed)\n", rename)
+		}
+	}
+
+	fmt.Printf("\n%d file(s) would change\n", len(plan.Changed()))
+	return nil
+}
+
+func runGenerateCheck(configFile string, only []string, assumeRename bool, trace *codegen.Trace) error {
+	endLoad := trace.Start("load spec")
+	cfg, spec, err := loadConfig(resolveConfigFile(configFile), config.WithReadOnly(true))
+	endLoad()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	gen := codegen.New(cfg, spec)
+	gen.SetRenameFunc(renameFunc(assumeRename, false))
+	gen.SetTrace(trace)
+
+	stale, err := gen.Check(only...)
+	if err != nil {
+		return fmt.Errorf("code generation failed: %w", err)
+	}
+
+	if len(stale) == 0 {
+		fmt.Println("✓ Generated code is up to date")
+		return nil
+	}
+
+	for _, path := range stale {
+		fmt.Println(path)
+	}
+
+	return fmt.Errorf("%d generated file(s) out of date, run mcpgen generate", len(stale))
+}
+
+// renameFunc returns how generate confirms that a removed resolver handler
+// was renamed: always with --assume-rename, by asking when interactive and
+// stdin is a terminal, and never otherwise.
+func renameFunc(assumeRename, interactive bool) codegen.RenameFunc {
+	if assumeRename {
+		return func(string, string) bool { return true }
+	}
+	if !interactive {
+		return nil
+	}
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return nil
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	return func(oldHandler, newHandler string) bool {
+		fmt.Printf("%s was removed and %s added. Rename it and keep its body? [y/N] ", oldHandler, newHandler)
+		answer, err := reader.ReadString('\n')
+		if err != nil {
+			fmt.Println()
+		}
+		answer = strings.ToLower(strings.TrimSpace(answer))
+		return answer == "y" || answer == "yes"
+	}
+}
+
+// startCPUProfile starts writing a CPU profile to path and returns the
+// function stopping it.
+func startCPUProfile(path string) (func(), error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CPU profile: %w", err)
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to start CPU profile: %w", err)
+	}
+	return func() {
+		pprof.StopCPUProfile()
+		f.Close()
+	}, nil
+}
+
+func printWarnings(warnings []string) {
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+}
+
+func runValidate(configFile string) error {
+	configFile = resolveConfigFile(configFile)
+
+	fmt.Printf("Validating configuration from %s...\n", configFile)
+
+	cfg, spec, err := loadConfig(configFile, config.WithReadOnly(true))
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	gen := codegen.New(cfg, spec)
+
+	if err := gen.Validate(); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	printWarnings(gen.Warnings())
+
+	fmt.Printf("✓ %s v%s is valid\n", spec.Info.Title, spec.Info.Version)
+	return nil
+}
+
+func runDoctor(configFile string) error {
+	configFile = resolveConfigFile(configFile)
+
+	cfg, spec, err := loadConfig(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	failed := 0
+	for _, d := range codegen.New(cfg, spec).Doctor() {
+		fmt.Println(d)
+		if !d.OK {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	return nil
+}
+
+func runRelease(configFile string, opts codegen.ReleaseOptions) error {
+	configFile = resolveConfigFile(configFile)
+
+	cfg, spec, err := loadConfig(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	result, err := codegen.New(cfg, spec).Release(opts)
+	if err != nil {
+		return err
+	}
+	for _, artifact := range result.Artifacts {
+		fmt.Printf("✓ %s/%s: %s\n", artifact.OS, artifact.Arch, artifact.Path)
+		fmt.Printf("  client config: %s\n", artifact.ClientConfig)
+	}
+	if result.HomebrewFormula != "" {
+		fmt.Printf("✓ Homebrew formula: %s\n", result.HomebrewFormula)
+	}
+	if result.ScoopManifest != "" {
+		fmt.Printf("✓ Scoop manifest: %s\n", result.ScoopManifest)
+	}
+	if result.NPMPackage != "" {
+		fmt.Printf("✓ npm package: %s\n", result.NPMPackage)
+		fmt.Printf("  client config: %s\n", result.NPXClientConfig)
+	}
+	if result.RegistryManifest != "" {
+		fmt.Printf("✓ registry manifest: %s\n", result.RegistryManifest)
+	}
+	return nil
+}
+
+func runDiff(configFile, oldSpecPath, newSpecPath string) error {
+	var oldSpec, newSpec *config.MCPSpec
+	if newSpecPath != "" {
+		var err error
+		oldSpec, err = config.LoadMCPSpec(oldSpecPath)
+		if err != nil {
+			return fmt.Errorf("failed to load old spec: %w", err)
+		}
+		newSpec, err = config.LoadMCPSpec(newSpecPath)
+		if err != nil {
+			return fmt.Errorf("failed to load new spec: %w", err)
+		}
+	} else {
+		// The old spec is loaded as the spec of the configuration, with
+		// the same includes and remote references. Neither load writes
+		// mcpgen.lock or the cache
+		cfg, spec, err := loadConfig(resolveConfigFile(configFile), config.WithReadOnly(true))
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		newSpec = spec
+		oldSpec, err = config.LoadSpec(cfg, oldSpecPath, config.WithFrozen(frozen))
+		if err != nil {
+			return fmt.Errorf("failed to load old spec: %w", err)
+		}
+	}
+
+	report := diff.Compare(oldSpec, newSpec)
+	if len(report.Changes) == 0 {
+		fmt.Println("No changes")
+		return nil
+	}
+
+	for _, change := range report.Changes {
+		fmt.Println(change)
+	}
+
+	breaking := report.BreakingCount()
+	fmt.Printf("\n%d change(s), %d breaking\n", len(report.Changes), breaking)
+
+	if breaking > 0 {
+		return fmt.Errorf("%d breaking change(s) detected", breaking)
+	}
+
+	return nil
+}
+
+func runLint(configFile string, fix bool) error {
+	configFile = resolveConfigFile(configFile)
+
+	cfg, spec, err := loadConfig(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if fix {
+		fixed := false
+		for _, specFile := range spec.Files {
+			data, err := os.ReadFile(specFile)
+			if err != nil {
+				return fmt.Errorf("failed to read spec file: %w", err)
+			}
+
+			updated, added, err := config.AddDescriptionPlaceholders(data, filepath.Ext(specFile))
+			if err != nil {
+				return fmt.Errorf("failed to fix %s: %w", specFile, err)
+			}
+			if len(added) == 0 {
+				continue
+			}
+
+			if err := os.WriteFile(specFile, updated, 0644); err != nil {
+				return fmt.Errorf("failed to write spec file: %w", err)
+			}
+			fmt.Printf("Added %d description placeholder(s) to %s\n\n", len(added), specFile)
+			fixed = true
+		}
+
+		// Reload so the placeholders are reported below
+		if fixed {
+			if _, spec, err = loadConfig(configFile); err != nil {
+				return fmt.Errorf("failed to load configuration: %w", err)
+			}
+		}
+	}
+
+	issues, err := lint.Lint(spec, cfg.Lint)
+	if err != nil {
+		return fmt.Errorf("invalid lint configuration: %w", err)
+	}
+
+	if len(issues) == 0 {
+		fmt.Println("✓ No lint issues found")
+		return nil
+	}
+
+	for _, issue := range issues {
+		fmt.Println(issue)
+	}
+
+	return fmt.Errorf("%d lint issue(s) found", len(issues))
+}
+
+func runFmt(configFile string, specFiles []string, check bool) error {
+	if len(specFiles) == 0 {
+		_, spec, err := loadConfig(resolveConfigFile(configFile), config.WithReadOnly(check))
+		if err != nil {
+			return fmt.Errorf("failed to load configuration: %w", err)
+		}
+		specFiles = spec.Files
+	}
+
+	unformatted := 0
+	for _, specFile := range specFiles {
+		data, err := os.ReadFile(specFile)
+		if err != nil {
+			return fmt.Errorf("failed to read spec file: %w", err)
+		}
+
+		formatted, err := config.FormatSpec(data, filepath.Ext(specFile))
+		if err != nil {
+			return fmt.Errorf("failed to format %s: %w", specFile, err)
+		}
+
+		if bytes.Equal(data, formatted) {
+			continue
+		}
+
+		if check {
+			fmt.Println(specFile)
+			unformatted++
+			continue
+		}
+
+		if err := os.WriteFile(specFile, formatted, 0644); err != nil {
+			return fmt.Errorf("failed to write spec file: %w", err)
+		}
+		fmt.Printf("Formatted %s\n", specFile)
+	}
+
+	if unformatted > 0 {
+		return fmt.Errorf("%d spec file(s) not formatted", unformatted)
+	}
+
+	return nil
+}
+
+func runImportOpenAPI(configFile string, configRequired bool, document, output string, tags, paths []string) error {
+	var opts openapi.Options
+
+	configFile = resolveConfigFile(configFile)
+	if _, err := os.Stat(configFile); err == nil || configRequired {
+		cfg, err := config.LoadConfig(configFile)
+		if err != nil {
+			return err
+		}
+
+		importConfig := cfg.Import.OpenAPI
+		opts.Tags = importConfig.Tags
+		opts.Paths = importConfig.Paths
+		if document == "" && importConfig.Document != "" {
+			document = importConfig.Document
+			if !filepath.IsAbs(document) {
+				document = filepath.Join(filepath.Dir(configFile), document)
+			}
+		}
+	}
+
+	if len(tags) > 0 {
+		opts.Tags = tags
+	}
+	if len(paths) > 0 {
+		opts.Paths = paths
+	}
+
+	if document == "" {
+		return fmt.Errorf("no OpenAPI document given: pass it as an argument or set import.openapi.document")
+	}
+
+	data, err := os.ReadFile(document)
+	if err != nil {
+		return fmt.Errorf("failed to read OpenAPI document: %w", err)
+	}
+
+	spec, warnings, err := openapi.Import(data, filepath.Ext(document), opts)
+	if err != nil {
+		return fmt.Errorf("failed to import %s: %w", document, err)
+	}
+	printWarnings(warnings)
+
+	return writeImportedSpec(spec, document, output)
+}
+
+func runImportProto(files []string, output string, services, methods []string) error {
+	parsed := make([]*proto.File, 0, len(files))
+	for _, file := range files {
+		switch filepath.Ext(file) {
+		case ".pb", ".protoset", ".desc", ".binpb":
+			return fmt.Errorf("%s: descriptor sets are not supported, pass the .proto files", file)
+		}
+
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read proto file: %w", err)
+		}
+
+		f, err := proto.Parse(file, src)
+		if err != nil {
+			return err
+		}
+		parsed = append(parsed, f)
+	}
+
+	spec, warnings, err := proto.Import(parsed, proto.Options{Services: services, Methods: methods})
+	if err != nil {
+		return fmt.Errorf("failed to import %s: %w", strings.Join(files, ", "), err)
+	}
+	printWarnings(warnings)
+
+	return writeImportedSpec(spec, strings.Join(files, ", "), output)
+}
+
+func runImportGraphQL(files []string, output string, operations []string) error {
+	docs := make([]*graphql.Document, 0, len(files))
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read GraphQL schema: %w", err)
+		}
+
+		doc, err := graphql.Parse(file, src)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, doc)
+	}
+
+	spec, warnings, err := graphql.Import(docs, graphql.Options{Operations: operations})
+	if err != nil {
+		return fmt.Errorf("failed to import %s: %w", strings.Join(files, ", "), err)
+	}
+	printWarnings(warnings)
+
+	return writeImportedSpec(spec, strings.Join(files, ", "), output)
+}
+
+func runExtract(dir, output string, opts extract.Options) error {
+	spec, warnings, err := extract.Extract(dir, opts)
+	if err != nil {
+		return fmt.Errorf("failed to extract spec: %w", err)
+	}
+	printWarnings(warnings)
+
+	return writeImportedSpec(spec, dir, output)
+}
+
+// writeImportedSpec writes an imported spec to output, or to stdout when
+// output is empty.
+func writeImportedSpec(spec *config.MCPSpec, source, output string) error {
+	ext := ".yaml"
+	if output != "" {
+		ext = filepath.Ext(output)
+	}
+	content, err := config.EncodeSpec(spec, ext)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		_, err := os.Stdout.Write(content)
+		return err
+	}
+
+	if err := os.WriteFile(output, content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	fmt.Printf("Imported %d tool(s) from %s into %s\n", len(spec.Tools), source, output)
+
+	return nil
+}
+
+func runInit(name, fromOpenAPI string) error {
 	fmt.Printf("Initializing new MCP server project: %s\n", name)
 
+	var schemaContent []byte
+	if fromOpenAPI != "" {
+		data, err := os.ReadFile(fromOpenAPI)
+		if err != nil {
+			return fmt.Errorf("failed to read OpenAPI document: %w", err)
+		}
+
+		spec, warnings, err := openapi.Import(data, filepath.Ext(fromOpenAPI), openapi.Options{})
+		if err != nil {
+			return fmt.Errorf("failed to import %s: %w", fromOpenAPI, err)
+		}
+		printWarnings(warnings)
+
+		schemaContent, err = config.EncodeSpec(spec, ".yaml")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Imported %d tool(s) from %s\n", len(spec.Tools), fromOpenAPI)
+	}
+
 	if err := os.MkdirAll(name, 0755); err != nil {
 		return fmt.Errorf("failed to create project directory: %w", err)
 	}
@@ -129,7 +1027,8 @@ model:
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
-	schemaContent := fmt.Sprintf(`# MCP API Specification
+	if schemaContent == nil {
+		schemaContent = []byte(fmt.Sprintf(`# MCP API Specification
 # This file contains the pure MCP API definition
 
 info:
@@ -142,32 +1041,48 @@ components:
   schemas:
     ExampleInput:
       type: object
-      properties:
+      # YAML anchors (&name) and aliases (*name) reuse any part of the spec
+      properties: &example-properties
         message:
           type: string
           description: The message to process
       required: [message]
+    RepeatInput:
+      type: object
+      properties:
+        # A merge key copies the keys of an anchored mapping
+        <<: *example-properties
+        count:
+          type: integer
+          description: How many times to repeat the message
+      required: [message, count]
 
 # MCP Tools
 tools:
   - name: example_tool
     description: An example tool that processes messages
-    hints:
+    hints: &example-hints
       readonly: false
       destructive: false
       idempotent: true
     inputSchema:
       $ref: "#/components/schemas/ExampleInput"
+  - name: repeat_tool
+    description: An example tool that repeats messages
+    hints: *example-hints
+    inputSchema:
+      $ref: "#/components/schemas/RepeatInput"
 
 # MCP Resources
 resources: []
 
 # MCP Prompts
 prompts: []
-`, name)
+`, name))
+	}
 
 	schemaPath := filepath.Join(name, "schema.yaml")
-	if err := os.WriteFile(schemaPath, []byte(schemaContent), 0644); err != nil {
+	if err := os.WriteFile(schemaPath, schemaContent, 0644); err != nil {
 		return fmt.Errorf("failed to write schema file: %w", err)
 	}
 
@@ -0,0 +1,248 @@
+package mcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Authenticator authenticates the requests of the admin endpoints served by
+// AdminHandler. Authenticate returns an error to reject a request.
+type Authenticator interface {
+	Authenticate(r *http.Request) error
+}
+
+// AuthenticatorFunc is an Authenticator calling the function.
+type AuthenticatorFunc func(r *http.Request) error
+
+// Authenticate calls f(r).
+func (f AuthenticatorFunc) Authenticate(r *http.Request) error {
+	return f(r)
+}
+
+// BearerTokenAuthenticator returns an Authenticator accepting the requests
+// whose Authorization header is "Bearer <token>". An empty token rejects
+// every request.
+func BearerTokenAuthenticator(token string) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) error {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			return errors.New("invalid bearer token")
+		}
+		return nil
+	})
+}
+
+// ToolSwitch enables and disables tools at runtime, to take a misbehaving
+// tool out of service during an incident without redeploying. Tools are
+// enabled until disabled. Its middleware hides the disabled tools.
+type ToolSwitch struct {
+	mu       sync.RWMutex
+	disabled map[string]bool
+}
+
+// NewToolSwitch returns a ToolSwitch with every tool enabled.
+func NewToolSwitch() *ToolSwitch {
+	return &ToolSwitch{disabled: make(map[string]bool)}
+}
+
+// SetEnabled enables or disables the tool name.
+func (s *ToolSwitch) SetEnabled(name string, enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if enabled {
+		delete(s.disabled, name)
+	} else {
+		s.disabled[name] = true
+	}
+}
+
+// Enabled reports whether the tool name is enabled.
+func (s *ToolSwitch) Enabled(name string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return !s.disabled[name]
+}
+
+// ToolSwitchMiddleware returns a receiving middleware hiding the tools s
+// disables: they are left out of list results, and calling them returns an
+// error result.
+func ToolSwitchMiddleware(s *ToolSwitch) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if call, ok := req.(*mcp.CallToolRequest); ok && call.Params != nil && !s.Enabled(call.Params.Name) {
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: Message(MessageToolDisabled, call.Params.Name)}},
+				}, nil
+			}
+
+			result, err := next(ctx, method, req)
+			if list, ok := result.(*mcp.ListToolsResult); ok && err == nil {
+				list.Tools = filter(list.Tools, func(t *mcp.Tool) bool {
+					return s.Enabled(t.Name)
+				})
+			}
+			return result, err
+		}
+	}
+}
+
+// ToolInfo describes a tool of the spec for the admin endpoints. The
+// generated AdminTools function lists them.
+type ToolInfo struct {
+	Name       string `json:"name"`
+	Title      string `json:"title,omitempty"`
+	Experiment string `json:"experiment,omitempty"`
+	Deprecated bool   `json:"deprecated,omitempty"`
+	ReplacedBy string `json:"replaced_by,omitempty"`
+}
+
+// AdminConfig configures the admin endpoints of AdminHandler. The endpoints
+// of the features left nil answer 501 Not Implemented.
+type AdminConfig struct {
+	// Authenticator authenticates every request. Without it every request
+	// is rejected.
+	Authenticator Authenticator
+	// Info is the metadata of the server, as returned by the generated
+	// ServerInfo function.
+	Info BuildInfo
+	// Tools describes the tools of the server, as returned by the generated
+	// AdminTools function.
+	Tools []ToolInfo
+	// Switch enables and disables the tools of the server.
+	Switch *ToolSwitch
+	// LogLevel is the level of the logger of the server.
+	LogLevel *slog.LevelVar
+	// Reload reloads the configuration of the server.
+	Reload func(ctx context.Context) error
+}
+
+// AdminHandler returns an HTTP handler serving the admin endpoints of a
+// server, meant for operations teams and listening on a separate port:
+//
+//   - GET /registry returns the metadata of the server and of its tools
+//   - POST /tools/{name}/enable and /tools/{name}/disable switch a tool
+//   - GET /log-level returns the log level, PUT /log-level sets it from a
+//     {"level": "debug"} body
+//   - POST /reload reloads the configuration
+//
+// Requests the Authenticator of cfg rejects get 401 Unauthorized.
+func AdminHandler(cfg AdminConfig) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /registry", func(w http.ResponseWriter, r *http.Request) {
+		type tool struct {
+			ToolInfo
+			Enabled bool `json:"enabled"`
+		}
+		registry := struct {
+			Name          string `json:"name"`
+			Version       string `json:"version"`
+			SpecHash      string `json:"spec_hash"`
+			MCPGenVersion string `json:"mcpgen_version"`
+			Tools         []tool `json:"tools"`
+		}{
+			Name:          cfg.Info.Name,
+			Version:       cfg.Info.Version,
+			SpecHash:      cfg.Info.SpecHash,
+			MCPGenVersion: cfg.Info.MCPGenVersion,
+			Tools:         make([]tool, 0, len(cfg.Tools)),
+		}
+		for _, info := range cfg.Tools {
+			registry.Tools = append(registry.Tools, tool{ToolInfo: info, Enabled: cfg.Switch == nil || cfg.Switch.Enabled(info.Name)})
+		}
+		writeJSON(w, registry)
+	})
+
+	switchTool := func(enabled bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if cfg.Switch == nil {
+				http.Error(w, "tool switch not configured", http.StatusNotImplemented)
+				return
+			}
+			name := r.PathValue("name")
+			if !knownTool(cfg.Tools, name) {
+				http.Error(w, "unknown tool "+name, http.StatusNotFound)
+				return
+			}
+			cfg.Switch.SetEnabled(name, enabled)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+	mux.HandleFunc("POST /tools/{name}/enable", switchTool(true))
+	mux.HandleFunc("POST /tools/{name}/disable", switchTool(false))
+
+	mux.HandleFunc("/log-level", func(w http.ResponseWriter, r *http.Request) {
+		if cfg.LogLevel == nil {
+			http.Error(w, "log level not configured", http.StatusNotImplemented)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, map[string]string{"level": cfg.LogLevel.Level().String()})
+		case http.MethodPut:
+			var body struct {
+				Level string `json:"level"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				http.Error(w, "invalid body: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			if err := cfg.LogLevel.UnmarshalText([]byte(body.Level)); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc("POST /reload", func(w http.ResponseWriter, r *http.Request) {
+		if cfg.Reload == nil {
+			http.Error(w, "reload not configured", http.StatusNotImplemented)
+			return
+		}
+		if err := cfg.Reload(r.Context()); err != nil {
+			http.Error(w, "reload: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cfg.Authenticator == nil || cfg.Authenticator.Authenticate(r) != nil {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// knownTool reports whether tools describes the tool name, or whether any
+// name is accepted when tools is empty.
+func knownTool(tools []ToolInfo, name string) bool {
+	if len(tools) == 0 {
+		return true
+	}
+	for _, tool := range tools {
+		if tool.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
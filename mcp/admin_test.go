@@ -0,0 +1,155 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBearerTokenAuthenticator(t *testing.T) {
+	request := func(authorization string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/registry", nil)
+		if authorization != "" {
+			r.Header.Set("Authorization", authorization)
+		}
+		return r
+	}
+
+	auth := BearerTokenAuthenticator("s3cret")
+	assert.NoError(t, auth.Authenticate(request("Bearer s3cret")))
+	assert.Error(t, auth.Authenticate(request("Bearer wrong")))
+	assert.Error(t, auth.Authenticate(request("s3cret")))
+	assert.Error(t, auth.Authenticate(request("")))
+	assert.Error(t, BearerTokenAuthenticator("").Authenticate(request("Bearer ")), "an empty token rejects every request")
+}
+
+func TestAdminHandler(t *testing.T) {
+	level := &slog.LevelVar{}
+	tools := NewToolSwitch()
+	reloads := 0
+	handler := AdminHandler(AdminConfig{
+		Authenticator: BearerTokenAuthenticator("s3cret"),
+		Info:          BuildInfo{Name: "demo", Version: "1.0.0", SpecHash: "abc", MCPGenVersion: "v0.1.0"},
+		Tools: []ToolInfo{
+			{Name: "create_task", Title: "Create task"},
+			{Name: "search_tasks", Deprecated: true, ReplacedBy: "find_tasks"},
+		},
+		Switch:   tools,
+		LogLevel: level,
+		Reload: func(ctx context.Context) error {
+			reloads++
+			if reloads > 1 {
+				return errors.New("invalid config")
+			}
+			return nil
+		},
+	})
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set("Authorization", "Bearer s3cret")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/registry", nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("tools", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, serve(http.MethodPost, "/tools/create_task/disable", "").Code)
+		assert.False(t, tools.Enabled("create_task"))
+
+		w := serve(http.MethodGet, "/registry", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{
+			"name": "demo", "version": "1.0.0", "spec_hash": "abc", "mcpgen_version": "v0.1.0",
+			"tools": [
+				{"name": "create_task", "title": "Create task", "enabled": false},
+				{"name": "search_tasks", "deprecated": true, "replaced_by": "find_tasks", "enabled": true}
+			]
+		}`, w.Body.String())
+
+		assert.Equal(t, http.StatusNoContent, serve(http.MethodPost, "/tools/create_task/enable", "").Code)
+		assert.True(t, tools.Enabled("create_task"))
+		assert.Equal(t, http.StatusNotFound, serve(http.MethodPost, "/tools/unknown/disable", "").Code)
+		assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodGet, "/tools/create_task/disable", "").Code)
+	})
+
+	t.Run("log level", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, serve(http.MethodPut, "/log-level", `{"level": "debug"}`).Code)
+		assert.Equal(t, slog.LevelDebug, level.Level())
+
+		w := serve(http.MethodGet, "/log-level", "")
+		assert.JSONEq(t, `{"level": "DEBUG"}`, w.Body.String())
+
+		assert.Equal(t, http.StatusBadRequest, serve(http.MethodPut, "/log-level", `{"level": "loud"}`).Code)
+		assert.Equal(t, slog.LevelDebug, level.Level())
+	})
+
+	t.Run("reload", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, serve(http.MethodPost, "/reload", "").Code)
+		w := serve(http.MethodPost, "/reload", "")
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Contains(t, w.Body.String(), "reload: invalid config")
+	})
+
+	t.Run("features not configured", func(t *testing.T) {
+		handler := AdminHandler(AdminConfig{Authenticator: BearerTokenAuthenticator("s3cret")})
+		for _, path := range []string{"/tools/create_task/disable", "/reload"} {
+			r := httptest.NewRequest(http.MethodPost, path, nil)
+			r.Header.Set("Authorization", "Bearer s3cret")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			assert.Equal(t, http.StatusNotImplemented, w.Code, path)
+		}
+	})
+}
+
+func TestToolSwitchMiddleware(t *testing.T) {
+	ctx := context.Background()
+	tools := NewToolSwitch()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	for _, name := range []string{"create_task", "delete_task"} {
+		mcp.AddTool(server, &mcp.Tool{Name: name}, func(context.Context, *mcp.CallToolRequest, map[string]any) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, nil, nil
+		})
+	}
+	server.AddReceivingMiddleware(ToolSwitchMiddleware(tools))
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+
+	tools.SetEnabled("delete_task", false)
+
+	list, err := session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, list.Tools, 1)
+	assert.Equal(t, "create_task", list.Tools[0].Name)
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "delete_task", Arguments: map[string]any{}})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, "tool delete_task is disabled", result.Content[0].(*mcp.TextContent).Text)
+
+	tools.SetEnabled("delete_task", true)
+	result, err = session.CallTool(ctx, &mcp.CallToolParams{Name: "delete_task", Arguments: map[string]any{}})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+}
@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strings"
+)
+
+// BuildInfoMetricName is the name of the metric written by
+// BuildInfo.WritePrometheus, following the Prometheus *_build_info convention.
+const BuildInfoMetricName = "mcp_server_build_info"
+
+// BuildInfo holds the build and specification metadata of a generated server.
+// It is returned by the generated ServerInfo function.
+type BuildInfo struct {
+	// Name is the server name (info.title in the spec).
+	Name string
+	// Version is the server version (info.version in the spec).
+	Version string
+	// SpecHash is the SHA-256 of the specification the server was generated from.
+	SpecHash string
+	// MCPGenVersion is the version of mcpgen that generated the server.
+	MCPGenVersion string
+}
+
+// WritePrometheus writes the build info as a constant gauge in the Prometheus
+// text exposition format, so fleets of servers can be inventoried from
+// monitoring:
+//
+//	mcp_server_build_info{name="demo",version="1.0.0",spec_hash="...",mcpgen_version="v0.1.0",goversion="go1.25.3"} 1
+func (b BuildInfo) WritePrometheus(w io.Writer) error {
+	_, err := fmt.Fprintf(
+		w,
+		"# HELP %[1]s A metric with a constant '1' value labeled by MCP server name, version, spec hash and mcpgen version.\n"+
+			"# TYPE %[1]s gauge\n"+
+			"%[1]s{name=\"%s\",version=\"%s\",spec_hash=\"%s\",mcpgen_version=\"%s\",goversion=\"%s\"} 1\n",
+		BuildInfoMetricName,
+		escapeLabelValue(b.Name),
+		escapeLabelValue(b.Version),
+		escapeLabelValue(b.SpecHash),
+		escapeLabelValue(b.MCPGenVersion),
+		escapeLabelValue(runtime.Version()),
+	)
+	return err
+}
+
+// MetricsHandler returns an HTTP handler serving the build info metric. It can
+// be mounted on its own or its output appended to an existing metrics endpoint.
+func (b BuildInfo) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = b.WritePrometheus(w)
+	})
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(v string) string {
+	return labelValueEscaper.Replace(v)
+}
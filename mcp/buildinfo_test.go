@@ -0,0 +1,48 @@
+package mcp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildInfoWritePrometheus(t *testing.T) {
+	info := BuildInfo{
+		Name:          "demo",
+		Version:       "1.0.0",
+		SpecHash:      "abc123",
+		MCPGenVersion: "v0.1.0",
+	}
+
+	var buf strings.Builder
+	require.NoError(t, info.WritePrometheus(&buf))
+
+	out := buf.String()
+	assert.Contains(t, out, "# TYPE mcp_server_build_info gauge\n")
+	assert.Contains(t, out, `mcp_server_build_info{name="demo",version="1.0.0",spec_hash="abc123",mcpgen_version="v0.1.0",goversion="`+runtime.Version()+`"} 1`)
+}
+
+func TestBuildInfoEscapesLabelValues(t *testing.T) {
+	info := BuildInfo{Name: "a\"b\\c\nd"}
+
+	var buf strings.Builder
+	require.NoError(t, info.WritePrometheus(&buf))
+
+	assert.Contains(t, buf.String(), `name="a\"b\\c\nd"`)
+}
+
+func TestBuildInfoMetricsHandler(t *testing.T) {
+	info := BuildInfo{Name: "demo", Version: "1.0.0"}
+
+	rec := httptest.NewRecorder()
+	info.MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+	assert.Contains(t, rec.Body.String(), `mcp_server_build_info{name="demo",version="1.0.0"`)
+}
@@ -0,0 +1,137 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Client capabilities features can require.
+const (
+	CapabilitySampling    = "sampling"
+	CapabilityElicitation = "elicitation"
+	// CapabilityExperimental prefixes the name of an experimental capability,
+	// as in "experimental.streaming".
+	CapabilityExperimental = "experimental."
+)
+
+// CapabilityRequirements lists the client capabilities required by tools
+// and prompts, by name, and by resources, by URI or URI template.
+type CapabilityRequirements struct {
+	Tools     map[string][]string
+	Prompts   map[string][]string
+	Resources map[string][]string
+}
+
+// CapabilityMiddleware returns a receiving middleware hiding the tools,
+// prompts and resources requiring a client capability the client of the
+// session did not declare when it initialized. They are left out of list
+// results, and using them is an error, as if they were not registered.
+// Reads of resources matched by a URI template are not checked.
+//
+// Example:
+//
+//	server.AddReceivingMiddleware(mcputil.CapabilityMiddleware(mcputil.CapabilityRequirements{
+//	    Tools: map[string][]string{"summarize": {mcputil.CapabilitySampling}},
+//	}))
+func CapabilityMiddleware(requirements CapabilityRequirements) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			var capabilities *mcp.ClientCapabilities
+			if session, ok := req.GetSession().(*mcp.ServerSession); ok {
+				if params := session.InitializeParams(); params != nil {
+					capabilities = params.Capabilities
+				}
+			}
+			missing := func(required []string) string {
+				for _, capability := range required {
+					if !hasClientCapability(capabilities, capability) {
+						return capability
+					}
+				}
+				return ""
+			}
+
+			switch req := req.(type) {
+			case *mcp.CallToolRequest:
+				if req.Params != nil {
+					if capability := missing(requirements.Tools[req.Params.Name]); capability != "" {
+						return &mcp.CallToolResult{
+							IsError: true,
+							Content: []mcp.Content{&mcp.TextContent{Text: Message(MessageMissingClientCapability, "tool", req.Params.Name, capability)}},
+						}, nil
+					}
+				}
+			case *mcp.GetPromptRequest:
+				if req.Params != nil {
+					if capability := missing(requirements.Prompts[req.Params.Name]); capability != "" {
+						return nil, NewError(MessageMissingClientCapability, "prompt", req.Params.Name, capability)
+					}
+				}
+			case *mcp.ReadResourceRequest:
+				if req.Params != nil {
+					if capability := missing(requirements.Resources[req.Params.URI]); capability != "" {
+						return nil, NewError(MessageMissingClientCapability, "resource", req.Params.URI, capability)
+					}
+				}
+			}
+
+			result, err := next(ctx, method, req)
+			if err != nil {
+				return result, err
+			}
+
+			switch result := result.(type) {
+			case *mcp.ListToolsResult:
+				result.Tools = filter(result.Tools, func(t *mcp.Tool) bool {
+					return missing(requirements.Tools[t.Name]) == ""
+				})
+			case *mcp.ListPromptsResult:
+				result.Prompts = filter(result.Prompts, func(p *mcp.Prompt) bool {
+					return missing(requirements.Prompts[p.Name]) == ""
+				})
+			case *mcp.ListResourcesResult:
+				result.Resources = filter(result.Resources, func(r *mcp.Resource) bool {
+					return missing(requirements.Resources[r.URI]) == ""
+				})
+			case *mcp.ListResourceTemplatesResult:
+				result.ResourceTemplates = filter(result.ResourceTemplates, func(r *mcp.ResourceTemplate) bool {
+					return missing(requirements.Resources[r.URITemplate]) == ""
+				})
+			}
+			return result, nil
+		}
+	}
+}
+
+// hasClientCapability reports whether capabilities declare capability.
+func hasClientCapability(capabilities *mcp.ClientCapabilities, capability string) bool {
+	if capabilities == nil {
+		return false
+	}
+
+	switch capability {
+	case CapabilitySampling:
+		return capabilities.Sampling != nil
+	case CapabilityElicitation:
+		return capabilities.Elicitation != nil
+	}
+	if name, ok := strings.CutPrefix(capability, CapabilityExperimental); ok {
+		_, declared := capabilities.Experimental[name]
+		return declared
+	}
+	return false
+}
+
+// filter returns the items keep returns true for, in a new slice so that
+// the slices of the server are left alone.
+func filter[T any](items []T, keep func(T) bool) []T {
+	kept := make([]T, 0, len(items))
+	for _, item := range items {
+		if keep(item) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
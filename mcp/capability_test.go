@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilityMiddleware(t *testing.T) {
+	ctx := context.Background()
+
+	connect := func(t *testing.T, clientOpts *mcp.ClientOptions) *mcp.ClientSession {
+		t.Helper()
+		opts := ApplyOptions(nil)
+
+		server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+		server.AddReceivingMiddleware(CapabilityMiddleware(CapabilityRequirements{
+			Tools:     map[string][]string{"summarize": {CapabilitySampling}},
+			Prompts:   map[string][]string{"interview": {CapabilityElicitation}},
+			Resources: map[string][]string{"docs://draft": {"experimental.drafts"}},
+		}))
+		for _, name := range []string{"summarize", "ping"} {
+			AddToolWithoutInput(server, &mcp.Tool{Name: name}, func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, map[string]any, error) {
+				return nil, map[string]any{"ok": true}, nil
+			}, &opts)
+		}
+		server.AddPrompt(&mcp.Prompt{Name: "interview"}, func(context.Context, *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return &mcp.GetPromptResult{}, nil
+		})
+		for _, uri := range []string{"docs://draft", "docs://readme"} {
+			server.AddResource(&mcp.Resource{Name: uri, URI: uri}, func(_ context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+				return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{{URI: req.Params.URI, Text: "text"}}}, nil
+			})
+		}
+
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		serverSession, err := server.Connect(ctx, serverTransport, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = serverSession.Close() })
+
+		client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, clientOpts)
+		session, err := client.Connect(ctx, clientTransport, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = session.Close() })
+		return session
+	}
+
+	t.Run("missing capabilities", func(t *testing.T) {
+		session := connect(t, nil)
+
+		tools, err := session.ListTools(ctx, nil)
+		require.NoError(t, err)
+		require.Len(t, tools.Tools, 1)
+		assert.Equal(t, "ping", tools.Tools[0].Name)
+
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "summarize"})
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Equal(t, "tool summarize requires the sampling client capability", result.Content[0].(*mcp.TextContent).Text)
+
+		prompts, err := session.ListPrompts(ctx, nil)
+		require.NoError(t, err)
+		assert.Empty(t, prompts.Prompts)
+
+		_, err = session.GetPrompt(ctx, &mcp.GetPromptParams{Name: "interview"})
+		assert.ErrorContains(t, err, "prompt interview requires the elicitation client capability")
+
+		resources, err := session.ListResources(ctx, nil)
+		require.NoError(t, err)
+		require.Len(t, resources.Resources, 1)
+		assert.Equal(t, "docs://readme", resources.Resources[0].URI)
+
+		_, err = session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "docs://draft"})
+		assert.ErrorContains(t, err, "resource docs://draft requires the experimental.drafts client capability")
+	})
+
+	t.Run("declared capabilities", func(t *testing.T) {
+		session := connect(t, &mcp.ClientOptions{
+			CreateMessageHandler: func(context.Context, *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+				return &mcp.CreateMessageResult{}, nil
+			},
+			ElicitationHandler: func(context.Context, *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+				return &mcp.ElicitResult{}, nil
+			},
+		})
+
+		tools, err := session.ListTools(ctx, nil)
+		require.NoError(t, err)
+		assert.Len(t, tools.Tools, 2)
+
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "summarize"})
+		require.NoError(t, err)
+		assert.False(t, result.IsError)
+
+		_, err = session.GetPrompt(ctx, &mcp.GetPromptParams{Name: "interview"})
+		assert.NoError(t, err)
+	})
+}
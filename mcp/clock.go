@@ -0,0 +1,138 @@
+package mcp
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Clock tells the time to the generated middlewares, such as for the
+// timestamps of transcripts. Tests fix it with a FakeClock.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc is a Clock calling the function.
+type ClockFunc func() time.Time
+
+// Now calls f().
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the Clock of the system time, the default of the generated
+// server.
+var SystemClock Clock = ClockFunc(time.Now)
+
+// FakeClock is a Clock returning a time set by the test, which only moves
+// when the test moves it.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock returns a FakeClock set to now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set sets the time of the clock.
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+// Advance moves the clock forward by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// IDGenerator generates the IDs of the generated middlewares, such as the
+// request IDs of RequestIDMiddleware. Tests fix them with a
+// SequentialIDGenerator.
+type IDGenerator interface {
+	NewID() string
+}
+
+// IDGeneratorFunc is an IDGenerator calling the function.
+type IDGeneratorFunc func() string
+
+// NewID calls f().
+func (f IDGeneratorFunc) NewID() string {
+	return f()
+}
+
+// RandomIDGenerator generates random IDs of 32 hexadecimal digits, the
+// default of the generated server.
+var RandomIDGenerator IDGenerator = IDGeneratorFunc(func() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+})
+
+// SequentialIDGenerator returns an IDGenerator generating prefix followed by
+// 1, 2 and so on.
+func SequentialIDGenerator(prefix string) IDGenerator {
+	var n atomic.Uint64
+	return IDGeneratorFunc(func() string {
+		return prefix + strconv.FormatUint(n.Add(1), 10)
+	})
+}
+
+// WithClock sets the clock of the generated middlewares. Defaults to
+// SystemClock.
+func WithClock(clock Clock) Option {
+	return func(o *Options) {
+		o.Clock = clock
+	}
+}
+
+// WithIDGenerator sets the generator of the IDs of the generated
+// middlewares. Defaults to RandomIDGenerator.
+func WithIDGenerator(ids IDGenerator) Option {
+	return func(o *Options) {
+		o.IDGenerator = ids
+	}
+}
+
+// requestIDKey is the context key of the ID of a request.
+type requestIDKey struct{}
+
+// RequestID returns the ID RequestIDMiddleware gave the request handled with
+// ctx, or the empty string.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// RequestIDMiddleware returns a receiving middleware giving each request an
+// ID generated by ids, which handlers and the other middlewares read with
+// RequestID to correlate their logs. Messages the server sends while
+// handling a request, such as sampling requests, carry its ID too.
+//
+// Example:
+//
+//	server.AddReceivingMiddleware(mcputil.RequestIDMiddleware(mcputil.RandomIDGenerator))
+func RequestIDMiddleware(ids IDGenerator) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			return next(context.WithValue(ctx, requestIDKey{}, ids.NewID()), method, req)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeClock(t *testing.T) {
+	now := time.Date(2025, 3, 14, 15, 9, 26, 0, time.UTC)
+	clock := NewFakeClock(now)
+	assert.Equal(t, now, clock.Now())
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, now.Add(time.Minute), clock.Now())
+
+	clock.Set(now)
+	assert.Equal(t, now, clock.Now())
+}
+
+func TestIDGenerators(t *testing.T) {
+	ids := SequentialIDGenerator("req-")
+	assert.Equal(t, "req-1", ids.NewID())
+	assert.Equal(t, "req-2", ids.NewID())
+
+	id := RandomIDGenerator.NewID()
+	assert.Regexp(t, `^[0-9a-f]{32}$`, id)
+	assert.NotEqual(t, id, RandomIDGenerator.NewID())
+}
+
+func TestApplyOptionsClock(t *testing.T) {
+	o := ApplyOptions(nil)
+	assert.WithinDuration(t, time.Now(), o.Clock.Now(), time.Minute, "defaults to the system clock")
+	assert.Regexp(t, `^[0-9a-f]{32}$`, o.IDGenerator.NewID(), "defaults to random IDs")
+
+	clock := NewFakeClock(time.Time{})
+	o = ApplyOptions([]Option{WithClock(clock), WithIDGenerator(SequentialIDGenerator("id-"))})
+	assert.Same(t, clock, o.Clock)
+	assert.Equal(t, "id-1", o.IDGenerator.NewID())
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, RequestID(ctx))
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "whoami"}, func(ctx context.Context, _ *mcp.CallToolRequest, _ map[string]any) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: RequestID(ctx)}}}, nil, nil
+	})
+	server.AddReceivingMiddleware(RequestIDMiddleware(SequentialIDGenerator("req-")))
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "whoami", Arguments: map[string]any{}})
+	require.NoError(t, err)
+	// initialize and notifications/initialized got the first IDs
+	assert.Equal(t, "req-3", result.Content[0].(*mcp.TextContent).Text)
+}
@@ -0,0 +1,159 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// CoerceArguments rewrites string-encoded numbers and booleans in args to
+// the JSON type declared by s. Values that cannot be converted are left
+// untouched so that schema validation reports them as usual.
+//
+// Some clients send every argument as a string (e.g. "42" for an integer);
+// coercing them before validation avoids spurious failures.
+func CoerceArguments(s *jsonschema.Schema, args json.RawMessage) (json.RawMessage, error) {
+	if s == nil || len(args) == 0 {
+		return args, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(args))
+	dec.UseNumber()
+
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	coerced, changed := coerceValue(s, value)
+	if !changed {
+		return args, nil
+	}
+
+	return json.Marshal(coerced)
+}
+
+// CoercionMiddleware returns a receiving middleware that applies
+// CoerceArguments to the arguments of "tools/call" requests, using the input
+// schema registered for the called tool in schemas.
+//
+// Example:
+//
+//	server.AddReceivingMiddleware(mcputil.CoercionMiddleware(map[string]*jsonschema.Schema{
+//	    "add": AddToolInputSchema,
+//	}))
+func CoercionMiddleware(schemas map[string]*jsonschema.Schema) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" {
+				return next(ctx, method, req)
+			}
+
+			callReq, ok := req.(*mcp.CallToolRequest)
+			if !ok || callReq.Params == nil {
+				return next(ctx, method, req)
+			}
+
+			if s, ok := schemas[callReq.Params.Name]; ok {
+				args, err := CoerceArguments(s, callReq.Params.Arguments)
+				if err == nil {
+					callReq.Params.Arguments = args
+				}
+			}
+
+			return next(ctx, method, req)
+		}
+	}
+}
+
+func coerceValue(s *jsonschema.Schema, value any) (any, bool) {
+	if s == nil {
+		return value, false
+	}
+
+	switch v := value.(type) {
+	case string:
+		return coerceString(s, v)
+	case map[string]any:
+		changed := false
+		for name, prop := range v {
+			if propSchema, ok := s.Properties[name]; ok {
+				if coerced, ok := coerceValue(propSchema, prop); ok {
+					v[name] = coerced
+					changed = true
+				}
+			}
+		}
+		return v, changed
+	case []any:
+		if s.Items == nil {
+			return v, false
+		}
+		changed := false
+		for i, item := range v {
+			if coerced, ok := coerceValue(s.Items, item); ok {
+				v[i] = coerced
+				changed = true
+			}
+		}
+		return v, changed
+	}
+
+	return value, false
+}
+
+func coerceString(s *jsonschema.Schema, value string) (any, bool) {
+	types := schemaTypes(s)
+
+	// A string is already valid when the schema accepts strings.
+	for _, t := range types {
+		if t == "string" {
+			return value, false
+		}
+	}
+
+	for _, t := range types {
+		switch t {
+		case "integer":
+			if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+				return json.Number(strconv.FormatInt(n, 10)), true
+			}
+		case "number":
+			if f, err := strconv.ParseFloat(value, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+				return json.Number(strconv.FormatFloat(f, 'g', -1, 64)), true
+			}
+		case "boolean":
+			switch strings.ToLower(value) {
+			case "true":
+				return true, true
+			case "false":
+				return false, true
+			}
+		}
+	}
+
+	return value, false
+}
+
+// schemaTypes returns the types accepted by s, including the non-null
+// branches of a nullable anyOf.
+func schemaTypes(s *jsonschema.Schema) []string {
+	if s.Type != "" {
+		return []string{s.Type}
+	}
+	if len(s.Types) > 0 {
+		return s.Types
+	}
+
+	var types []string
+	for _, sub := range s.AnyOf {
+		types = append(types, schemaTypes(sub)...)
+	}
+	return types
+}
@@ -0,0 +1,140 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoerceArguments(t *testing.T) {
+	s := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"count":   {Type: "integer"},
+			"ratio":   {Type: "number"},
+			"enabled": {Type: "boolean"},
+			"name":    {Type: "string"},
+			"limit":   {Types: []string{"integer", "null"}},
+			"ids":     {Type: "array", Items: &jsonschema.Schema{Type: "integer"}},
+			"nested": {
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"flag": {AnyOf: []*jsonschema.Schema{{Type: "boolean"}, {Type: "null"}}},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		args string
+		want string
+	}{
+		{
+			name: "integer",
+			args: `{"count":"42"}`,
+			want: `{"count":42}`,
+		},
+		{
+			name: "number",
+			args: `{"ratio":"0.5"}`,
+			want: `{"ratio":0.5}`,
+		},
+		{
+			name: "boolean",
+			args: `{"enabled":"TRUE"}`,
+			want: `{"enabled":true}`,
+		},
+		{
+			name: "string stays string",
+			args: `{"name":"42"}`,
+			want: `{"name":"42"}`,
+		},
+		{
+			name: "nullable integer",
+			args: `{"limit":"10"}`,
+			want: `{"limit":10}`,
+		},
+		{
+			name: "array items",
+			args: `{"ids":["1",2,"3"]}`,
+			want: `{"ids":[1,2,3]}`,
+		},
+		{
+			name: "nested nullable boolean",
+			args: `{"nested":{"flag":"false"}}`,
+			want: `{"nested":{"flag":false}}`,
+		},
+		{
+			name: "invalid value left untouched",
+			args: `{"count":"forty-two","ratio":"NaN"}`,
+			want: `{"count":"forty-two","ratio":"NaN"}`,
+		},
+		{
+			name: "unknown property left untouched",
+			args: `{"other":"1"}`,
+			want: `{"other":"1"}`,
+		},
+		{
+			name: "large integer keeps precision",
+			args: `{"count":"9007199254740993"}`,
+			want: `{"count":9007199254740993}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CoerceArguments(s, json.RawMessage(tt.args))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got))
+		})
+	}
+
+	t.Run("empty arguments", func(t *testing.T) {
+		got, err := CoerceArguments(s, nil)
+		require.NoError(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		_, err := CoerceArguments(s, json.RawMessage(`{`))
+		assert.Error(t, err)
+	})
+}
+
+func TestCoercionMiddleware(t *testing.T) {
+	schemas := map[string]*jsonschema.Schema{
+		"add": {
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"a": {Type: "integer"},
+			},
+		},
+	}
+
+	var received json.RawMessage
+	next := func(_ context.Context, _ string, req mcp.Request) (mcp.Result, error) {
+		received = req.(*mcp.CallToolRequest).Params.Arguments
+		return nil, nil
+	}
+	handler := CoercionMiddleware(schemas)(next)
+
+	t.Run("coerces registered tool", func(t *testing.T) {
+		req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "add", Arguments: json.RawMessage(`{"a":"1"}`)}}
+		_, err := handler(context.Background(), "tools/call", req)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"a":1}`, string(received))
+	})
+
+	t.Run("ignores unknown tool", func(t *testing.T) {
+		req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "other", Arguments: json.RawMessage(`{"a":"1"}`)}}
+		_, err := handler(context.Background(), "tools/call", req)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"a":"1"}`, string(received))
+	})
+}
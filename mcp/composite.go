@@ -0,0 +1,185 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// CompositeCall holds the values the data mappings of a composite tool
+// select from: the input of the call and the outputs of the steps run so
+// far. The generated handlers of the composite tools of the spec use it.
+//
+// A mapping is a JSON value whose strings starting with $ select a value:
+// $input is the input of the call and $steps.<id> the output of the step id,
+// followed by the properties, or array indexes, of the selected value, such
+// as $steps.create_user.address.city. Selecting a missing value omits the
+// property holding it. The other values are copied, and $$ starts a string
+// with $.
+type CompositeCall struct {
+	input any
+	steps map[string]any
+}
+
+// NewCompositeCall returns the CompositeCall of a composite tool called with
+// input.
+func NewCompositeCall(input any) (*CompositeCall, error) {
+	value, err := jsonValue(input)
+	if err != nil {
+		return nil, fmt.Errorf("composite input: %w", err)
+	}
+	return &CompositeCall{input: value, steps: make(map[string]any)}, nil
+}
+
+// StepInput returns the input of the step id, mapped from the values of
+// call by mapping.
+func StepInput[T any](call *CompositeCall, id, mapping string) (T, error) {
+	var input T
+	if err := call.bind(mapping, &input); err != nil {
+		return input, fmt.Errorf("step %s: input: %w", id, err)
+	}
+	return input, nil
+}
+
+// Done records the output of the step id, and returns an error stopping the
+// composite tool when the step failed or returned an error result.
+func (c *CompositeCall) Done(id string, result *mcp.CallToolResult, output any, err error) error {
+	if err != nil {
+		return fmt.Errorf("step %s: %w", id, err)
+	}
+	if result != nil && result.IsError {
+		return fmt.Errorf("step %s: %s", id, resultText(result))
+	}
+
+	value, err := jsonValue(output)
+	if err != nil {
+		return fmt.Errorf("step %s: output: %w", id, err)
+	}
+	c.steps[id] = value
+	return nil
+}
+
+// resultText returns the text contents of result.
+func resultText(result *mcp.CallToolResult) string {
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	if len(texts) == 0 {
+		return "error result"
+	}
+	return strings.Join(texts, "\n")
+}
+
+// CompositeOutput returns the output of the composite tool, mapped from the
+// values of call by mapping.
+func CompositeOutput[T any](call *CompositeCall, mapping string) (T, error) {
+	var output T
+	if err := call.bind(mapping, &output); err != nil {
+		return output, fmt.Errorf("composite output: %w", err)
+	}
+	return output, nil
+}
+
+// bind decodes the value mapping selects into v.
+func (c *CompositeCall) bind(mapping string, v any) error {
+	var m any
+	if err := json.Unmarshal([]byte(mapping), &m); err != nil {
+		return fmt.Errorf("invalid mapping: %w", err)
+	}
+
+	value, ok := c.eval(m)
+	if !ok {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// eval returns the value m maps to, or false when it selects a missing value.
+func (c *CompositeCall) eval(m any) (any, bool) {
+	switch m := m.(type) {
+	case string:
+		if strings.HasPrefix(m, "$$") {
+			return m[1:], true
+		}
+		if path, ok := strings.CutPrefix(m, "$"); ok {
+			return c.lookup(path)
+		}
+	case map[string]any:
+		value := make(map[string]any, len(m))
+		for key, sub := range m {
+			if v, ok := c.eval(sub); ok {
+				value[key] = v
+			}
+		}
+		return value, true
+	case []any:
+		value := make([]any, len(m))
+		for i, sub := range m {
+			value[i], _ = c.eval(sub)
+		}
+		return value, true
+	}
+	return m, true
+}
+
+// lookup returns the value path selects, such as steps.create_user.id.
+func (c *CompositeCall) lookup(path string) (any, bool) {
+	segments := strings.Split(path, ".")
+
+	var value any
+	switch {
+	case segments[0] == "input":
+		value, segments = c.input, segments[1:]
+	case segments[0] == "steps" && len(segments) > 1:
+		output, ok := c.steps[segments[1]]
+		if !ok {
+			return nil, false
+		}
+		value, segments = output, segments[2:]
+	default:
+		return nil, false
+	}
+
+	for _, segment := range segments {
+		switch v := value.(type) {
+		case map[string]any:
+			sub, ok := v[segment]
+			if !ok {
+				return nil, false
+			}
+			value = sub
+		case []any:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, false
+			}
+			value = v[i]
+		default:
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// jsonValue returns the JSON value of v, as decoded into an any.
+func jsonValue(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompositeCall(t *testing.T) {
+	type user struct {
+		ID   string   `json:"id"`
+		Tags []string `json:"tags,omitempty"`
+	}
+	type taskInput struct {
+		OwnerID string  `json:"owner_id"`
+		Title   string  `json:"title"`
+		Tag     *string `json:"tag,omitempty"`
+		Price   string  `json:"price"`
+	}
+
+	call, err := NewCompositeCall(&struct {
+		Name  string `json:"name"`
+		Title string `json:"title"`
+	}{Name: "Ada", Title: "Write docs"})
+	require.NoError(t, err)
+
+	require.NoError(t, call.Done("create_user", nil, user{ID: "u1", Tags: []string{"admin"}}, nil))
+
+	t.Run("step input", func(t *testing.T) {
+		input, err := StepInput[taskInput](call, "create_task",
+			`{"owner_id":"$steps.create_user.id","title":"$input.title","tag":"$steps.create_user.tags.0","price":"$$5"}`)
+		require.NoError(t, err)
+		tag := "admin"
+		assert.Equal(t, taskInput{OwnerID: "u1", Title: "Write docs", Tag: &tag, Price: "$5"}, input)
+	})
+
+	t.Run("missing values are omitted", func(t *testing.T) {
+		input, err := StepInput[taskInput](call, "create_task", `{"title":"$input.missing","tag":"$steps.create_user.tags.3"}`)
+		require.NoError(t, err)
+		assert.Equal(t, taskInput{}, input)
+	})
+
+	t.Run("output", func(t *testing.T) {
+		output, err := CompositeOutput[user](call, `"$steps.create_user"`)
+		require.NoError(t, err)
+		assert.Equal(t, user{ID: "u1", Tags: []string{"admin"}}, output)
+
+		values, err := CompositeOutput[map[string]any](call, `{"user":"$steps.create_user.id","name":"$input.name"}`)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"user": "u1", "name": "Ada"}, values)
+	})
+
+	t.Run("mismatched types", func(t *testing.T) {
+		_, err := StepInput[taskInput](call, "create_task", `{"title":"$steps.create_user.tags"}`)
+		assert.ErrorContains(t, err, "step create_task: input: ")
+	})
+
+	t.Run("failed steps stop the call", func(t *testing.T) {
+		err := call.Done("notify", nil, nil, ErrNotImplemented)
+		assert.ErrorIs(t, err, ErrNotImplemented)
+		assert.EqualError(t, err, "step notify: "+ErrNotImplemented.Error())
+
+		err = call.Done("notify", &mcp.CallToolResult{IsError: true, Content: []mcp.Content{&mcp.TextContent{Text: "mailbox full"}}}, nil, nil)
+		assert.EqualError(t, err, "step notify: mailbox full")
+
+		input, err := StepInput[map[string]any](call, "next", `{"notified":"$steps.notify"}`)
+		require.NoError(t, err)
+		assert.Empty(t, input, "the outputs of stopped steps are not recorded")
+	})
+}
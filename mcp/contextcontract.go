@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ContextContractMiddleware returns a receiving middleware, meant for debug
+// builds, that reports the handlers reading context values under keys other
+// than the declared ones. Handlers relying on values set by a transport, such
+// as HTTP request values, break when the server runs over another transport;
+// this catches them while testing over one.
+//
+// Every read of an undeclared key is logged at warn level once per key and
+// call site, with the key type and the location of the read. Reads made by
+// the context package itself and by the MCP SDK are ignored.
+//
+// Example:
+//
+//	server.AddReceivingMiddleware(mcputil.ContextContractMiddleware(logger, userKey{}, tenantKey{}))
+func ContextContractMiddleware(logger *slog.Logger, keys ...any) mcp.Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	declared := make(map[any]bool, len(keys))
+	for _, key := range keys {
+		declared[key] = true
+	}
+	var reported sync.Map
+
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			ctx = &contractContext{
+				Context: ctx,
+				check: func(key any) {
+					if declared[key] {
+						return
+					}
+					caller, ok := valueCaller()
+					if !ok {
+						return
+					}
+
+					keyType := fmt.Sprintf("%T", key)
+					if _, loaded := reported.LoadOrStore(keyType+" "+caller, true); loaded {
+						return
+					}
+					logger.LogAttrs(ctx, slog.LevelWarn, "undeclared context value read",
+						append(callAttrs(method, req), slog.String("key", keyType), slog.String("caller", caller))...)
+				},
+			}
+
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// contractContext checks the keys of the values read from it, including
+// through the contexts derived from it.
+type contractContext struct {
+	context.Context
+	check func(key any)
+}
+
+func (c *contractContext) Value(key any) any {
+	c.check(key)
+	return c.Context.Value(key)
+}
+
+// valueCaller returns the function and location reading a context value,
+// skipping the context implementations. It reports false for the reads made
+// by the context package and the MCP SDK.
+func valueCaller() (string, bool) {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		switch {
+		case strings.HasPrefix(frame.Function, "context."),
+			strings.HasSuffix(frame.Function, ".(*contractContext).Value"):
+		case strings.HasPrefix(frame.Function, "github.com/modelcontextprotocol/go-sdk/"):
+			return "", false
+		default:
+			return fmt.Sprintf("%s (%s:%d)", frame.Function, frame.File, frame.Line), true
+		}
+		if !more {
+			return "", false
+		}
+	}
+}
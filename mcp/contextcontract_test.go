@@ -0,0 +1,52 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	declaredKey   struct{}
+	undeclaredKey struct{}
+)
+
+func TestContextContractMiddleware(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+
+	handler := func(ctx context.Context, _ string, _ mcp.Request) (mcp.Result, error) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		for range 2 {
+			_ = ctx.Value(declaredKey{})
+			_ = ctx.Value(undeclaredKey{})
+		}
+		return nil, nil
+	}
+
+	ctx := context.WithValue(context.Background(), undeclaredKey{}, "transport")
+	req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "whoami"}}
+	_, err := ContextContractMiddleware(logger, declaredKey{})(handler)(ctx, "tools/call", req)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	require.Len(t, lines, 1, logs.String())
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "undeclared context value read", entry["msg"])
+	assert.Equal(t, "WARN", entry["level"])
+	assert.Equal(t, "whoami", entry["tool"])
+	assert.Equal(t, "mcp.undeclaredKey", entry["key"])
+	assert.Contains(t, entry["caller"], "TestContextContractMiddleware")
+	assert.Contains(t, entry["caller"], "contextcontract_test.go")
+}
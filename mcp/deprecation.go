@@ -0,0 +1,141 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DeprecatedCallsMetricName is the name of the metric written by
+// DeprecatedCalls.WritePrometheus.
+const DeprecatedCallsMetricName = "mcp_deprecated_tool_calls_total"
+
+// DeprecationMetaKey is the key of the warning DeprecationMiddleware adds to
+// the _meta of the results of deprecated tools.
+const DeprecationMetaKey = "deprecation"
+
+// DeprecatedCalls counts the calls to deprecated tools, to follow how many
+// clients still have to move to their replacement before they are removed.
+// The zero value is ready to use.
+type DeprecatedCalls struct {
+	mu     sync.Mutex
+	counts map[string]uint64
+}
+
+// Count returns the number of calls to a deprecated tool.
+func (c *DeprecatedCalls) Count(tool string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.counts[tool]
+}
+
+func (c *DeprecatedCalls) inc(tool string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.counts == nil {
+		c.counts = map[string]uint64{}
+	}
+	c.counts[tool]++
+}
+
+// WritePrometheus writes the call counts as a counter in the Prometheus text
+// exposition format, labeled by tool and replacement:
+//
+//	mcp_deprecated_tool_calls_total{tool="search",replaced_by="search_v2"} 12
+func (c *DeprecatedCalls) WritePrometheus(w io.Writer, replacements map[string]string) error {
+	c.mu.Lock()
+	counts := make(map[string]uint64, len(c.counts))
+	for tool, count := range c.counts {
+		counts[tool] = count
+	}
+	c.mu.Unlock()
+
+	if _, err := fmt.Fprintf(
+		w,
+		"# HELP %[1]s The number of calls to deprecated tools, labeled by tool and replacement.\n"+
+			"# TYPE %[1]s counter\n",
+		DeprecatedCallsMetricName,
+	); err != nil {
+		return err
+	}
+
+	tools := make([]string, 0, len(counts))
+	for tool := range counts {
+		tools = append(tools, tool)
+	}
+	slices.Sort(tools)
+	for _, tool := range tools {
+		if _, err := fmt.Fprintf(
+			w,
+			"%s{tool=\"%s\",replaced_by=\"%s\"} %d\n",
+			DeprecatedCallsMetricName,
+			escapeLabelValue(tool),
+			escapeLabelValue(replacements[tool]),
+			counts[tool],
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MetricsHandler returns an HTTP handler serving the call counts. It can be
+// mounted on its own or its output appended to an existing metrics endpoint.
+func (c *DeprecatedCalls) MetricsHandler(replacements map[string]string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = c.WritePrometheus(w, replacements)
+	})
+}
+
+// WithDeprecatedCalls sets the counter of the calls to deprecated tools,
+// which the generated server otherwise keeps to itself.
+func WithDeprecatedCalls(calls *DeprecatedCalls) Option {
+	return func(o *Options) {
+		o.DeprecatedCalls = calls
+	}
+}
+
+// DeprecationMiddleware returns a receiving middleware warning the clients
+// calling a deprecated tool, a key of replacements, that they should call its
+// replacement instead. The warning is added to the _meta of the result under
+// DeprecationMetaKey, and the call is counted in calls unless it is nil.
+//
+// Example:
+//
+//	server.AddReceivingMiddleware(mcputil.DeprecationMiddleware(map[string]string{"search": "search_v2"}, calls))
+func DeprecationMiddleware(replacements map[string]string, calls *DeprecatedCalls) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			call, ok := req.(*mcp.CallToolRequest)
+			if !ok || call.Params == nil {
+				return next(ctx, method, req)
+			}
+			replacement, deprecated := replacements[call.Params.Name]
+			if !deprecated {
+				return next(ctx, method, req)
+			}
+
+			if calls != nil {
+				calls.inc(call.Params.Name)
+			}
+
+			result, err := next(ctx, method, req)
+			if toolResult, ok := result.(*mcp.CallToolResult); ok && toolResult != nil {
+				if toolResult.Meta == nil {
+					toolResult.Meta = mcp.Meta{}
+				}
+				toolResult.Meta[DeprecationMetaKey] = map[string]any{
+					"message":    fmt.Sprintf("the %s tool is deprecated, call %s instead", call.Params.Name, replacement),
+					"replacedBy": replacement,
+				}
+			}
+			return result, err
+		}
+	}
+}
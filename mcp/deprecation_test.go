@@ -0,0 +1,53 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeprecationMiddleware(t *testing.T) {
+	calls := &DeprecatedCalls{}
+	replacements := map[string]string{"search": "search_v2"}
+	handler := DeprecationMiddleware(replacements, calls)(func(context.Context, string, mcp.Request) (mcp.Result, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, nil
+	})
+	call := func(name string) *mcp.CallToolResult {
+		t.Helper()
+		result, err := handler(context.Background(), "tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: name}})
+		require.NoError(t, err)
+		return result.(*mcp.CallToolResult)
+	}
+
+	result := call("search")
+	assert.Equal(t, map[string]any{
+		"message":    "the search tool is deprecated, call search_v2 instead",
+		"replacedBy": "search_v2",
+	}, result.Meta[DeprecationMetaKey])
+	call("search")
+	assert.Equal(t, uint64(2), calls.Count("search"))
+
+	assert.Nil(t, call("search_v2").Meta, "other tools get no warning")
+	assert.Zero(t, calls.Count("search_v2"))
+
+	var buf bytes.Buffer
+	require.NoError(t, calls.WritePrometheus(&buf, replacements))
+	assert.Equal(t, "# HELP mcp_deprecated_tool_calls_total The number of calls to deprecated tools, labeled by tool and replacement.\n"+
+		"# TYPE mcp_deprecated_tool_calls_total counter\n"+
+		"mcp_deprecated_tool_calls_total{tool=\"search\",replaced_by=\"search_v2\"} 2\n", buf.String())
+}
+
+func TestDeprecationMiddlewareWithoutCounter(t *testing.T) {
+	handler := DeprecationMiddleware(map[string]string{"search": "search_v2"}, nil)(func(context.Context, string, mcp.Request) (mcp.Result, error) {
+		return &mcp.CallToolResult{Meta: mcp.Meta{"trace": "abc"}}, nil
+	})
+	result, err := handler(context.Background(), "tools/call", &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "search"}})
+	require.NoError(t, err)
+	meta := result.(*mcp.CallToolResult).Meta
+	assert.Equal(t, "abc", meta["trace"])
+	assert.Contains(t, meta, DeprecationMetaKey)
+}
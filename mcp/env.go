@@ -0,0 +1,67 @@
+package mcp
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// EnvVar is an environment variable declared by the spec of a server. The
+// generated Env function lists them.
+type EnvVar struct {
+	Name        string
+	Description string
+	// Required makes CheckEnv fail while the variable is unset.
+	Required bool
+	// Default is set by CheckEnv while the variable is unset.
+	Default string
+}
+
+// CheckEnv sets the unset variables of vars to their default and returns an
+// error naming the required ones that are unset, so that a misconfigured
+// server fails when it starts rather than on its first call. An empty
+// variable is unset.
+func CheckEnv(vars []EnvVar) error {
+	var missing []string
+	for _, v := range vars {
+		if os.Getenv(v.Name) != "" {
+			continue
+		}
+		switch {
+		case v.Required:
+			missing = append(missing, v.Name)
+		case v.Default != "":
+			if err := os.Setenv(v.Name, v.Default); err != nil {
+				return fmt.Errorf("cannot set %s to its default: %w", v.Name, err)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required environment variables: %s (see -print-env)", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// PrintEnv writes vars to w as a .env file: each variable is set to its
+// default, after a comment with its description and whether it is
+// required. The current values are not written, as they may be secrets.
+func PrintEnv(w io.Writer, vars []EnvVar) error {
+	var b strings.Builder
+	for i, v := range vars {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		for _, line := range strings.Split(strings.TrimSpace(v.Description), "\n") {
+			if line != "" {
+				fmt.Fprintf(&b, "# %s\n", line)
+			}
+		}
+		if v.Required {
+			b.WriteString("# Required.\n")
+		}
+		fmt.Fprintf(&b, "%s=%s\n", v.Name, v.Default)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,61 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testEnv = []EnvVar{
+	{Name: "MCPGEN_TEST_TOKEN", Description: "Token of the tasks API.", Required: true},
+	{Name: "MCPGEN_TEST_URL", Description: "Base URL of the tasks API.\nWithout a trailing slash.", Default: "https://tasks.example.com"},
+	{Name: "MCPGEN_TEST_REGION"},
+}
+
+func TestCheckEnv(t *testing.T) {
+	t.Setenv("MCPGEN_TEST_TOKEN", "")
+	t.Setenv("MCPGEN_TEST_URL", "")
+	t.Setenv("MCPGEN_TEST_REGION", "")
+
+	err := CheckEnv(testEnv)
+	assert.EqualError(t, err, "missing required environment variables: MCPGEN_TEST_TOKEN (see -print-env)")
+
+	t.Setenv("MCPGEN_TEST_TOKEN", "secret")
+	require.NoError(t, CheckEnv(testEnv))
+	assert.Equal(t, "https://tasks.example.com", os.Getenv("MCPGEN_TEST_URL"), "unset variables get their default")
+	assert.Empty(t, os.Getenv("MCPGEN_TEST_REGION"))
+
+	t.Setenv("MCPGEN_TEST_URL", "http://localhost:8080")
+	require.NoError(t, CheckEnv(testEnv))
+	assert.Equal(t, "http://localhost:8080", os.Getenv("MCPGEN_TEST_URL"), "set variables keep their value")
+}
+
+func TestPrintEnv(t *testing.T) {
+	t.Setenv("MCPGEN_TEST_TOKEN", "secret")
+
+	var buf bytes.Buffer
+	require.NoError(t, PrintEnv(&buf, testEnv))
+	assert.Equal(t, `# Token of the tasks API.
+# Required.
+MCPGEN_TEST_TOKEN=
+
+# Base URL of the tasks API.
+# Without a trailing slash.
+MCPGEN_TEST_URL=https://tasks.example.com
+
+MCPGEN_TEST_REGION=
+`, buf.String())
+}
+
+func TestRunChecksEnv(t *testing.T) {
+	t.Setenv("MCPGEN_TEST_TOKEN", "")
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	err := Run(context.Background(), server, RunConfig{Stdio: true, Env: testEnv})
+	assert.EqualError(t, err, "missing required environment variables: MCPGEN_TEST_TOKEN (see -print-env)")
+}
@@ -0,0 +1,222 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ErrorKindMetaKey is the key of the ErrorKind ErrorMiddleware sets in the
+// _meta of the tool results built from a classified error.
+const ErrorKindMetaKey = "errorKind"
+
+// ErrorKind classifies the errors of handlers, so that the generated server
+// reports them uniformly and that middlewares, such as metrics or retries,
+// can tell them apart.
+type ErrorKind string
+
+const (
+	// KindInvalidInput is the kind of the errors caused by arguments the
+	// handler cannot accept, beyond what the input schema checks.
+	KindInvalidInput ErrorKind = "invalid_input"
+	// KindNotFound is the kind of the errors caused by a missing entity.
+	KindNotFound ErrorKind = "not_found"
+	// KindPermissionDenied is the kind of the errors caused by a caller not
+	// allowed to perform the call.
+	KindPermissionDenied ErrorKind = "permission_denied"
+	// KindUnavailable is the kind of the errors caused by a dependency being
+	// temporarily unavailable. Calls failing with it can be retried.
+	KindUnavailable ErrorKind = "unavailable"
+	// KindInternal is the kind of the errors the caller can do nothing
+	// about. Their message is not sent to the client, which gets the
+	// MessageInternalError message instead.
+	KindInternal ErrorKind = "internal"
+)
+
+// Retryable reports whether the calls failing with an error of kind k can
+// be retried as is.
+func (k ErrorKind) Retryable() bool {
+	return k == KindUnavailable
+}
+
+// Error is an error classified with an ErrorKind. Handlers create them with
+// InvalidInput, NotFound, PermissionDenied, Unavailable and Internal.
+type Error struct {
+	Kind ErrorKind
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// InvalidInput returns an error of kind KindInvalidInput. The format and
+// args are those of fmt.Errorf, so %w wraps an error.
+//
+// Example:
+//
+//	if input.DueDate.Before(time.Now()) {
+//	    return nil, Task{}, mcputil.InvalidInput("due date %s is in the past", input.DueDate)
+//	}
+func InvalidInput(format string, args ...any) error {
+	return &Error{Kind: KindInvalidInput, Err: fmt.Errorf(format, args...)}
+}
+
+// NotFound returns an error of kind KindNotFound, formatted like
+// fmt.Errorf. Resource handlers returning it answer with the MCP resource
+// not found error.
+func NotFound(format string, args ...any) error {
+	return &Error{Kind: KindNotFound, Err: fmt.Errorf(format, args...)}
+}
+
+// PermissionDenied returns an error of kind KindPermissionDenied, formatted
+// like fmt.Errorf.
+func PermissionDenied(format string, args ...any) error {
+	return &Error{Kind: KindPermissionDenied, Err: fmt.Errorf(format, args...)}
+}
+
+// Unavailable returns an error of kind KindUnavailable, formatted like
+// fmt.Errorf.
+func Unavailable(format string, args ...any) error {
+	return &Error{Kind: KindUnavailable, Err: fmt.Errorf(format, args...)}
+}
+
+// Internal returns an error of kind KindInternal, formatted like
+// fmt.Errorf. Its message is meant for logs: clients get the
+// MessageInternalError message.
+//
+// Example:
+//
+//	task, err := r.db.LoadTask(ctx, input.ID)
+//	if err != nil {
+//	    return nil, Task{}, mcputil.Internal("cannot load task %s: %w", input.ID, err)
+//	}
+func Internal(format string, args ...any) error {
+	return &Error{Kind: KindInternal, Err: fmt.Errorf(format, args...)}
+}
+
+// KindOf returns the kind of the first *Error err wraps, or "" when err is
+// nil or not classified.
+func KindOf(err error) ErrorKind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return ""
+}
+
+// ResultKind returns the kind of the outcome of a request, as seen by a
+// receiving middleware: the kind of err, or the kind ErrorMiddleware set on
+// the result of a tool call, since tool errors are reported in results.
+//
+// Example:
+//
+//	server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+//	    return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+//	        result, err := next(ctx, method, req)
+//	        if kind := mcputil.ResultKind(result, err); kind != "" {
+//	            callErrors.WithLabelValues(method, string(kind)).Inc()
+//	        }
+//	        return result, err
+//	    }
+//	})
+func ResultKind(result mcp.Result, err error) ErrorKind {
+	if err != nil {
+		return KindOf(err)
+	}
+	if r, ok := result.(*mcp.CallToolResult); ok && r.IsError {
+		if kind, ok := r.Meta[ErrorKindMetaKey].(string); ok {
+			return ErrorKind(kind)
+		}
+	}
+	return ""
+}
+
+// ClientMessage returns the message of err to report to clients: the
+// MessageInternalError message for the errors of kind KindInternal, and the
+// message of err otherwise.
+func ClientMessage(err error) string {
+	if KindOf(err) == KindInternal {
+		return Message(MessageInternalError)
+	}
+	return err.Error()
+}
+
+// ErrorResult returns the tool result reporting the classified error err.
+// Its text content holds the client message of err, and its structured
+// content the same message and the kind of err.
+func ErrorResult(err error) *mcp.CallToolResult {
+	message := ClientMessage(err)
+	kind := KindOf(err)
+
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{&mcp.TextContent{Text: message}},
+		StructuredContent: map[string]any{
+			"error": message,
+			"kind":  kind,
+		},
+		Meta: mcp.Meta{ErrorKindMetaKey: string(kind)},
+	}
+}
+
+// errorKey is the context key of the *errorCall of a tools/call request.
+type errorKey struct{}
+
+// errorCall records the classified error a tool handler returned.
+type errorCall struct {
+	err error
+}
+
+// ErrorMiddleware returns a receiving middleware reporting the classified
+// errors of handlers uniformly:
+//
+//   - tool calls whose handler, registered with AddTool, returned an *Error
+//     get the ErrorResult of the error. The replacement happens here rather
+//     than in the handler so that the result is not validated against the
+//     tool's output schema.
+//   - resource reads failing with KindNotFound get the MCP resource not found
+//     error.
+//   - other requests failing with KindInternal get the MessageInternalError
+//     message, still classified as KindInternal.
+func ErrorMiddleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method == "tools/call" {
+				call := &errorCall{}
+				result, err := next(context.WithValue(ctx, errorKey{}, call), method, req)
+				if err != nil || call.err == nil {
+					return result, err
+				}
+				return ErrorResult(call.err), nil
+			}
+
+			result, err := next(ctx, method, req)
+			switch KindOf(err) {
+			case KindNotFound:
+				if r, ok := req.(*mcp.ReadResourceRequest); ok && r.Params != nil {
+					return nil, mcp.ResourceNotFoundError(r.Params.URI)
+				}
+			case KindInternal:
+				return nil, &Error{Kind: KindInternal, Err: NewError(MessageInternalError)}
+			}
+			return result, err
+		}
+	}
+}
+
+// recordError records err on the errorCall of ctx when it is classified.
+func recordError(ctx context.Context, err error) {
+	if KindOf(err) == "" {
+		return
+	}
+	if call, ok := ctx.Value(errorKey{}).(*errorCall); ok {
+		call.err = err
+	}
+}
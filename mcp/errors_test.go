@@ -0,0 +1,135 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorKinds(t *testing.T) {
+	cause := errors.New("connection refused")
+
+	for _, tt := range []struct {
+		err  error
+		kind ErrorKind
+	}{
+		{InvalidInput("due date is in the past"), KindInvalidInput},
+		{NotFound("task %s not found", "t1"), KindNotFound},
+		{PermissionDenied("cannot delete task t1"), KindPermissionDenied},
+		{Unavailable("database: %w", cause), KindUnavailable},
+		{Internal("load task: %w", cause), KindInternal},
+		{fmt.Errorf("get_task: %w", NotFound("task t1 not found")), KindNotFound},
+		{cause, ""},
+		{nil, ""},
+	} {
+		assert.Equal(t, tt.kind, KindOf(tt.err), "%v", tt.err)
+	}
+
+	err := Unavailable("database: %w", cause)
+	assert.EqualError(t, err, "database: connection refused")
+	assert.ErrorIs(t, err, cause)
+	assert.True(t, KindOf(err).Retryable())
+	assert.False(t, KindOf(NotFound("task t1 not found")).Retryable())
+}
+
+func TestErrorResult(t *testing.T) {
+	result := ErrorResult(fmt.Errorf("get_task: %w", NotFound("task t1 not found")))
+	assert.True(t, result.IsError)
+	assert.Equal(t, "get_task: task t1 not found", result.Content[0].(*mcp.TextContent).Text)
+	assert.Equal(t, map[string]any{"error": "get_task: task t1 not found", "kind": KindNotFound}, result.StructuredContent)
+	assert.Equal(t, KindNotFound, ResultKind(result, nil))
+
+	result = ErrorResult(Internal("load task: %w", errors.New("password authentication failed")))
+	assert.Equal(t, "internal system error", result.Content[0].(*mcp.TextContent).Text)
+	assert.Equal(t, KindInternal, ResultKind(result, nil))
+
+	assert.Equal(t, KindUnavailable, ResultKind(nil, Unavailable("database down")))
+	assert.Equal(t, ErrorKind(""), ResultKind(&mcp.CallToolResult{IsError: true}, nil))
+}
+
+func TestErrorMiddleware(t *testing.T) {
+	ctx := context.Background()
+	var handlerErr error
+
+	o := ApplyOptions(nil)
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+
+	var kinds []ErrorKind
+	server.AddReceivingMiddleware(ErrorMiddleware())
+	server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			result, err := next(ctx, method, req)
+			if kind := ResultKind(result, err); kind != "" {
+				kinds = append(kinds, kind)
+			}
+			return result, err
+		}
+	})
+	AddTool(server, &mcp.Tool{
+		Name:        "report",
+		InputSchema: &jsonschema.Schema{Type: "object"},
+		OutputSchema: &jsonschema.Schema{
+			Type:       "object",
+			Properties: map[string]*jsonschema.Schema{"total": {Type: "integer", Minimum: jsonschema.Ptr(1.0)}},
+			Required:   []string{"total"},
+		},
+	}, func(context.Context, *mcp.CallToolRequest, map[string]any) (*mcp.CallToolResult, reportOutput, error) {
+		return nil, reportOutput{}, handlerErr
+	}, &o)
+	server.AddResourceTemplate(&mcp.ResourceTemplate{Name: "task", URITemplate: "tasks://{id}"},
+		func(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return nil, handlerErr
+		})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+
+	t.Run("tool", func(t *testing.T) {
+		handlerErr = PermissionDenied("cannot read the reports of %s", "acme")
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "report", Arguments: map[string]any{}})
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Equal(t, "cannot read the reports of acme", result.Content[0].(*mcp.TextContent).Text)
+		assert.Equal(t, map[string]any{"error": "cannot read the reports of acme", "kind": "permission_denied"}, result.StructuredContent)
+		assert.Equal(t, "permission_denied", result.Meta[ErrorKindMetaKey])
+	})
+
+	t.Run("unclassified tool error", func(t *testing.T) {
+		handlerErr = errors.New("boom")
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "report", Arguments: map[string]any{}})
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Equal(t, "boom", result.Content[0].(*mcp.TextContent).Text)
+		assert.NotContains(t, result.Meta, ErrorKindMetaKey)
+	})
+
+	t.Run("resource not found", func(t *testing.T) {
+		handlerErr = NotFound("task t1 not found")
+		_, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "tasks://t1"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "Resource not found")
+	})
+
+	t.Run("internal resource error", func(t *testing.T) {
+		handlerErr = Internal("load task: password authentication failed")
+		_, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "tasks://t1"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "internal system error")
+		assert.NotContains(t, err.Error(), "password")
+	})
+
+	assert.Equal(t, []ErrorKind{KindPermissionDenied, KindInternal}, kinds)
+}
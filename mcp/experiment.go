@@ -0,0 +1,25 @@
+package mcp
+
+// WithExperiments enables the experiments of the spec set to true. The tools
+// gated by an experiment are only registered when it is enabled. The
+// generated WithFlags option sets them from the typed Flags struct.
+//
+// Example:
+//
+//	server.New(resolver, mcputil.WithExperiments(map[string]bool{"bulk_export": true}))
+func WithExperiments(experiments map[string]bool) Option {
+	return func(o *Options) {
+		if o.Experiments == nil {
+			o.Experiments = make(map[string]bool, len(experiments))
+		}
+		for name, enabled := range experiments {
+			o.Experiments[name] = enabled
+		}
+	}
+}
+
+// ExperimentEnabled reports whether the named experiment is enabled.
+// Experiments are disabled unless an option enables them.
+func (o *Options) ExperimentEnabled(name string) bool {
+	return o.Experiments[name]
+}
@@ -0,0 +1,23 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithExperiments(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		o := ApplyOptions(nil)
+		assert.False(t, o.ExperimentEnabled("bulk_export"))
+	})
+
+	t.Run("later options win", func(t *testing.T) {
+		o := ApplyOptions([]Option{
+			WithExperiments(map[string]bool{"bulk_export": true, "drafts": true}),
+			WithExperiments(map[string]bool{"drafts": false}),
+		})
+		assert.True(t, o.ExperimentEnabled("bulk_export"))
+		assert.False(t, o.ExperimentEnabled("drafts"))
+	})
+}
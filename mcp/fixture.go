@@ -0,0 +1,98 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// DevFixtureMetaKey is the key set in the _meta of the results served from a
+// fixture, so that clients can tell them from real ones.
+const DevFixtureMetaKey = "devFixture"
+
+// WithDevFixtures makes the tools and resources whose handler is not
+// implemented serve the devFixture of the spec instead, to develop clients
+// before the handlers exist. Tools answer with their fixture when their
+// handler returns ErrNotImplemented, as the generated stubs do, and so do
+// resources whose handler returns an error wrapping it.
+func WithDevFixtures(enabled bool) Option {
+	return func(o *Options) {
+		o.DevFixtures = enabled
+	}
+}
+
+// FixtureNotImplementedFunc returns a NotImplementedFunc answering the calls
+// to the tools of fixtures with their JSON fixture, and the calls to the
+// other tools with fallback. A nil fallback uses DefaultNotImplementedFunc.
+//
+// An object fixture is the structured content of the result and its JSON
+// text content; a string fixture is the text content.
+func FixtureNotImplementedFunc(fixtures map[string]string, fallback NotImplementedFunc) NotImplementedFunc {
+	if fallback == nil {
+		fallback = DefaultNotImplementedFunc
+	}
+
+	return func(ctx context.Context, tool *mcp.Tool) *mcp.CallToolResult {
+		fixture, ok := fixtures[tool.Name]
+		if !ok {
+			return fallback(ctx, tool)
+		}
+
+		text, value := fixtureText(fixture)
+		result := &mcp.CallToolResult{
+			Content: []mcp.Content{&mcp.TextContent{Text: text}},
+			Meta:    mcp.Meta{DevFixtureMetaKey: true},
+		}
+		if _, ok := value.(map[string]any); ok {
+			result.StructuredContent = json.RawMessage(fixture)
+		}
+		return result
+	}
+}
+
+// FixtureResourceHandler returns a resource handler serving the JSON fixture
+// as the text of the resource when h returns an error wrapping
+// ErrNotImplemented and opts enables WithDevFixtures. Otherwise it returns h.
+func FixtureResourceHandler(h mcp.ResourceHandler, fixture, mimeType string, opts *Options) mcp.ResourceHandler {
+	if !opts.DevFixtures {
+		return h
+	}
+
+	text, value := fixtureText(fixture)
+	if mimeType == "" {
+		mimeType = "text/plain"
+		if _, ok := value.(string); !ok {
+			mimeType = "application/json"
+		}
+	}
+
+	return func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		result, err := h(ctx, req)
+		if !errors.Is(err, ErrNotImplemented) {
+			return result, err
+		}
+		return &mcp.ReadResourceResult{
+			Contents: []*mcp.ResourceContents{{
+				URI:      req.Params.URI,
+				MIMEType: mimeType,
+				Text:     text,
+			}},
+			Meta: mcp.Meta{DevFixtureMetaKey: true},
+		}, nil
+	}
+}
+
+// fixtureText returns the text of a JSON fixture, which is the string itself
+// for string fixtures, and its decoded value.
+func fixtureText(fixture string) (string, any) {
+	var value any
+	if err := json.Unmarshal([]byte(fixture), &value); err != nil {
+		return fixture, nil
+	}
+	if s, ok := value.(string); ok {
+		return s, s
+	}
+	return fixture, value
+}
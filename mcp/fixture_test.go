@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixtureNotImplementedFunc(t *testing.T) {
+	ctx := context.Background()
+	fn := FixtureNotImplementedFunc(map[string]string{
+		"get_task": `{"id":"t1","title":"Write docs"}`,
+		"greet":    `"hello"`,
+	}, nil)
+
+	t.Run("object fixture", func(t *testing.T) {
+		result := fn(ctx, &mcp.Tool{Name: "get_task"})
+		assert.False(t, result.IsError)
+		assert.Equal(t, `{"id":"t1","title":"Write docs"}`, result.Content[0].(*mcp.TextContent).Text)
+		assert.Equal(t, json.RawMessage(`{"id":"t1","title":"Write docs"}`), result.StructuredContent)
+		assert.Equal(t, true, result.Meta[DevFixtureMetaKey])
+	})
+
+	t.Run("string fixture", func(t *testing.T) {
+		result := fn(ctx, &mcp.Tool{Name: "greet"})
+		assert.Equal(t, "hello", result.Content[0].(*mcp.TextContent).Text)
+		assert.Nil(t, result.StructuredContent)
+	})
+
+	t.Run("no fixture", func(t *testing.T) {
+		result := fn(ctx, &mcp.Tool{Name: "delete_task"})
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "delete_task")
+	})
+}
+
+func TestFixtureResourceHandler(t *testing.T) {
+	ctx := context.Background()
+	req := &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: "tasks://t1"}}
+	stub := func(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		return nil, fmt.Errorf("task: %w", ErrNotImplemented)
+	}
+	fixture := `{"id":"t1"}`
+
+	t.Run("disabled", func(t *testing.T) {
+		_, err := FixtureResourceHandler(stub, fixture, "", &Options{})(ctx, req)
+		assert.ErrorIs(t, err, ErrNotImplemented)
+	})
+
+	t.Run("not implemented", func(t *testing.T) {
+		result, err := FixtureResourceHandler(stub, fixture, "", &Options{DevFixtures: true})(ctx, req)
+		require.NoError(t, err)
+		require.Len(t, result.Contents, 1)
+		assert.Equal(t, "tasks://t1", result.Contents[0].URI)
+		assert.Equal(t, "application/json", result.Contents[0].MIMEType)
+		assert.Equal(t, fixture, result.Contents[0].Text)
+		assert.Equal(t, true, result.Meta[DevFixtureMetaKey])
+
+		result, err = FixtureResourceHandler(stub, `"# Task"`, "text/markdown", &Options{DevFixtures: true})(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, "text/markdown", result.Contents[0].MIMEType)
+		assert.Equal(t, "# Task", result.Contents[0].Text)
+	})
+
+	t.Run("implemented", func(t *testing.T) {
+		failing := func(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return nil, errors.New("database down")
+		}
+		_, err := FixtureResourceHandler(failing, fixture, "", &Options{DevFixtures: true})(ctx, req)
+		assert.EqualError(t, err, "database down")
+	})
+}
@@ -0,0 +1,241 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// WithLazyTools defers the resolution of the tool schemas registered with
+// AddTool to the first call of each tool, rather than resolving all of them
+// as the server is created. Servers with hundreds of tools then answer the
+// initialize request of stdio clients before they time out. Call Prewarm in
+// the background to resolve the schemas ahead of the first calls anyway.
+//
+// A lazily registered tool answers arguments not matching its input schema
+// with an error result, as ValidationMiddleware does, rather than with an
+// invalid params error.
+func WithLazyTools(enabled bool) Option {
+	return func(o *Options) {
+		o.LazyTools = enabled
+	}
+}
+
+// schemaResolutions caches the resolution of schemas, by schema, for the
+// servers of a process to share it.
+var schemaResolutions sync.Map
+
+// schemaResolution is the resolution of a schema, computed once.
+type schemaResolution struct {
+	once     sync.Once
+	resolved *jsonschema.Resolved
+	err      error
+}
+
+// resolveSchema resolves s like the MCP SDK resolves tool schemas, once per
+// process.
+func resolveSchema(s *jsonschema.Schema) (*jsonschema.Resolved, error) {
+	v, _ := schemaResolutions.LoadOrStore(s, &schemaResolution{})
+	r := v.(*schemaResolution)
+	r.once.Do(func() {
+		r.resolved, r.err = s.Resolve(&jsonschema.ResolveOptions{ValidateDefaults: true})
+	})
+	return r.resolved, r.err
+}
+
+// Prewarm resolves schemas ahead of the first calls of the tools registered
+// with WithLazyTools, and of ValidationMiddleware. It returns the errors of
+// the schemas that cannot be resolved, which the calls of their tool would
+// otherwise report.
+//
+// Example:
+//
+//	go func() {
+//	    if err := mcputil.Prewarm(AddToolInputSchema, AddToolOutputSchema); err != nil {
+//	        logger.Error("invalid tool schemas", "error", err)
+//	    }
+//	}()
+func Prewarm(schemas ...*jsonschema.Schema) error {
+	var errs []error
+	for _, s := range schemas {
+		if _, err := resolveSchema(s); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// lazyTool holds the resolved schemas of a lazily registered tool.
+type lazyTool struct {
+	input  *jsonschema.Resolved
+	output *jsonschema.Resolved
+}
+
+// addLazyTool registers h on s like mcp.AddTool, but resolves the schemas of
+// t on the first call of the tool. Schemas the SDK would infer from In or
+// Out are inferred now, since they are part of the tool metadata. It returns
+// false, leaving the registration to mcp.AddTool, when a schema of t is not
+// a *jsonschema.Schema.
+func addLazyTool[In, Out any](s *mcp.Server, t *mcp.Tool, h mcp.ToolHandlerFor[In, Out]) bool {
+	tt := *t
+	if reflect.TypeFor[In]() == reflect.TypeFor[any]() && tt.InputSchema == nil {
+		tt.InputSchema = &jsonschema.Schema{Type: "object"}
+	}
+	input, inferredInput, _, ok := toolSchema[In](tt.InputSchema)
+	if !ok {
+		return false
+	}
+	tt.InputSchema = input
+
+	var (
+		output         *jsonschema.Schema
+		inferredOutput bool
+		elemZero       any
+	)
+	if tt.OutputSchema != nil || reflect.TypeFor[Out]() != reflect.TypeFor[any]() {
+		output, inferredOutput, elemZero, ok = toolSchema[Out](tt.OutputSchema)
+		if !ok {
+			return false
+		}
+		tt.OutputSchema = output
+	}
+
+	resolve := sync.OnceValues(func() (*lazyTool, error) {
+		var (
+			tool lazyTool
+			err  error
+		)
+		if tool.input, err = resolveToolSchema(input, inferredInput); err != nil {
+			return nil, fmt.Errorf("tool %s: input schema: %w", tt.Name, err)
+		}
+		if output != nil {
+			if tool.output, err = resolveToolSchema(output, inferredOutput); err != nil {
+				return nil, fmt.Errorf("tool %s: output schema: %w", tt.Name, err)
+			}
+		}
+		return &tool, nil
+	})
+
+	s.AddTool(&tt, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		tool, err := resolve()
+		if err != nil {
+			return nil, err
+		}
+
+		args, err := applySchema(req.Params.Arguments, tool.input)
+		if err != nil {
+			return invalidArgumentsResult(req.Params.Name, err), nil
+		}
+		var in In
+		if args != nil {
+			if err := json.Unmarshal(args, &in); err != nil {
+				return invalidArgumentsResult(req.Params.Name, err), nil
+			}
+		}
+
+		res, out, err := h(ctx, req, in)
+		if err != nil {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+			}, nil
+		}
+		if res == nil {
+			res = &mcp.CallToolResult{}
+		}
+
+		var outval any = out
+		if elemZero != nil {
+			var zero Out
+			if any(out) == any(zero) {
+				outval = elemZero
+			}
+		}
+		if outval == nil {
+			return res, nil
+		}
+		data, err := json.Marshal(outval)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling output: %w", err)
+		}
+		structured, err := applySchema(data, tool.output)
+		if err != nil {
+			return nil, fmt.Errorf("validating tool output: %w", err)
+		}
+		res.StructuredContent = structured
+		if res.Content == nil {
+			res.Content = []mcp.Content{&mcp.TextContent{Text: string(structured)}}
+		}
+		return res, nil
+	})
+	return true
+}
+
+// toolSchema returns the schema of a tool input or output of type T: schema
+// itself, or the schema inferred from T when schema is nil, along with the
+// zero value of the element type of a pointer T whose schema is inferred. It
+// returns false when schema is not a *jsonschema.Schema or cannot be
+// inferred.
+func toolSchema[T any](schema any) (s *jsonschema.Schema, inferred bool, elemZero any, ok bool) {
+	if schema != nil {
+		s, ok = schema.(*jsonschema.Schema)
+		return s, false, nil, ok && s != nil
+	}
+
+	rt := reflect.TypeFor[T]()
+	if rt.Kind() == reflect.Pointer {
+		rt = rt.Elem()
+		elemZero = reflect.Zero(rt).Interface()
+	}
+	s, err := jsonschema.ForType(rt, &jsonschema.ForOptions{})
+	if err != nil {
+		return nil, false, nil, false
+	}
+	return s, true, elemZero, true
+}
+
+// resolveToolSchema resolves a tool schema. The schemas of the spec are
+// resolved once per process, and Prewarm resolves them ahead of time;
+// inferred schemas are resolved once per registration.
+func resolveToolSchema(s *jsonschema.Schema, inferred bool) (*jsonschema.Resolved, error) {
+	if inferred {
+		return s.Resolve(&jsonschema.ResolveOptions{ValidateDefaults: true})
+	}
+	return resolveSchema(s)
+}
+
+// applySchema applies the defaults of resolved to data and validates it, as
+// the MCP SDK does for the arguments and outputs of typed tool handlers.
+// Missing data is validated as an empty object.
+func applySchema(data json.RawMessage, resolved *jsonschema.Resolved) (json.RawMessage, error) {
+	if resolved == nil {
+		return data, nil
+	}
+
+	v := make(map[string]any)
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("unmarshaling arguments: %w", err)
+		}
+	}
+	if err := resolved.ApplyDefaults(&v); err != nil {
+		return nil, fmt.Errorf("applying schema defaults: %w", err)
+	}
+	if err := resolved.Validate(&v); err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+func invalidArgumentsResult(tool string, err error) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		IsError: true,
+		Content: []mcp.Content{&mcp.TextContent{Text: Message(MessageInvalidArguments, tool, err.Error())}},
+	}
+}
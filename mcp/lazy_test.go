@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLazyTools(t *testing.T) {
+	ctx := context.Background()
+
+	type addInput struct {
+		A float64 `json:"a"`
+		B float64 `json:"b"`
+	}
+	type addOutput struct {
+		Sum float64 `json:"sum"`
+	}
+
+	inputSchema := func() *jsonschema.Schema {
+		return &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"a": {Type: "number"},
+				"b": {Type: "number", Default: []byte("1")},
+			},
+			Required: []string{"a"},
+		}
+	}
+	outputSchema := &jsonschema.Schema{
+		Type:       "object",
+		Properties: map[string]*jsonschema.Schema{"sum": {Type: "number"}},
+	}
+
+	connect := func(t *testing.T, input *jsonschema.Schema, opts ...Option) *mcp.ClientSession {
+		t.Helper()
+		o := ApplyOptions(opts)
+
+		server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+		AddTool(server, &mcp.Tool{Name: "add", InputSchema: input, OutputSchema: outputSchema},
+			func(_ context.Context, _ *mcp.CallToolRequest, in addInput) (*mcp.CallToolResult, addOutput, error) {
+				return nil, addOutput{Sum: in.A + in.B}, nil
+			}, &o)
+		AddToolWithoutInput(server, &mcp.Tool{Name: "ping"},
+			func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, map[string]any, error) {
+				return nil, map[string]any{"pong": true}, nil
+			}, &o)
+
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		serverSession, err := server.Connect(ctx, serverTransport, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = serverSession.Close() })
+
+		client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil)
+		session, err := client.Connect(ctx, clientTransport, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = session.Close() })
+		return session
+	}
+
+	t.Run("same results as the eager registration", func(t *testing.T) {
+		eager := connect(t, inputSchema())
+		lazy := connect(t, inputSchema(), WithLazyTools(true))
+
+		eagerTools, err := eager.ListTools(ctx, nil)
+		require.NoError(t, err)
+		lazyTools, err := lazy.ListTools(ctx, nil)
+		require.NoError(t, err)
+		assert.Equal(t, eagerTools, lazyTools)
+
+		for _, params := range []*mcp.CallToolParams{
+			{Name: "add", Arguments: map[string]any{"a": 2}},
+			{Name: "add", Arguments: map[string]any{"a": 2, "b": 3}},
+			{Name: "ping"},
+		} {
+			want, err := eager.CallTool(ctx, params)
+			require.NoError(t, err)
+			got, err := lazy.CallTool(ctx, params)
+			require.NoError(t, err)
+			assert.Equal(t, want, got, params.Name)
+		}
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		session := connect(t, inputSchema(), WithLazyTools(true))
+
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "add", Arguments: map[string]any{"b": 3}})
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "invalid arguments for tool add: ")
+	})
+
+	t.Run("unresolvable schema", func(t *testing.T) {
+		input := inputSchema()
+		input.Properties["b"].Default = []byte(`"one"`)
+		session := connect(t, input, WithLazyTools(true))
+
+		_, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "add", Arguments: map[string]any{"a": 2}})
+		assert.ErrorContains(t, err, "tool add: input schema: ")
+		assert.Error(t, Prewarm(input, outputSchema))
+	})
+}
+
+func TestPrewarm(t *testing.T) {
+	schema := &jsonschema.Schema{Type: "object"}
+	require.NoError(t, Prewarm(schema))
+
+	first, err := resolveSchema(schema)
+	require.NoError(t, err)
+	second, err := resolveSchema(schema)
+	require.NoError(t, err)
+	assert.Same(t, first, second, "schemas are resolved once")
+}
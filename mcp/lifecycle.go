@@ -0,0 +1,55 @@
+package mcp
+
+import (
+	"context"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// InitializeHook is implemented by resolvers preparing per-client state,
+// such as warming caches, when a session initializes. params holds the
+// client information and capabilities.
+type InitializeHook interface {
+	OnInitialize(ctx context.Context, session *mcp.ServerSession, params *mcp.InitializeParams)
+}
+
+// ShutdownHook is implemented by resolvers releasing their resources when
+// the server shuts down.
+type ShutdownHook interface {
+	OnShutdown(ctx context.Context) error
+}
+
+// LifecycleOptions returns the server options calling the OnInitialize hook
+// of resolver once a session is initialized, or nil when resolver does not
+// implement InitializeHook.
+func LifecycleOptions(resolver any) *mcp.ServerOptions {
+	hook, ok := resolver.(InitializeHook)
+	if !ok {
+		return nil
+	}
+
+	return &mcp.ServerOptions{
+		InitializedHandler: func(ctx context.Context, req *mcp.InitializedRequest) {
+			hook.OnInitialize(ctx, req.Session, req.Session.InitializeParams())
+		},
+	}
+}
+
+// Shutdown calls the OnShutdown hook of resolver, when it implements
+// ShutdownHook. The hook runs even when ctx is cancelled, which is how Run
+// usually stops, bounded by timeout or DefaultShutdownTimeout when zero.
+func Shutdown(ctx context.Context, resolver any, timeout time.Duration) error {
+	hook, ok := resolver.(ShutdownHook)
+	if !ok {
+		return nil
+	}
+
+	if timeout == 0 {
+		timeout = DefaultShutdownTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+
+	return hook.OnShutdown(ctx)
+}
@@ -0,0 +1,73 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type lifecycleResolver struct {
+	clients  chan string
+	deadline bool
+}
+
+func (r *lifecycleResolver) OnInitialize(_ context.Context, _ *mcp.ServerSession, params *mcp.InitializeParams) {
+	r.clients <- params.ClientInfo.Name
+}
+
+func (r *lifecycleResolver) OnShutdown(ctx context.Context) error {
+	_, r.deadline = ctx.Deadline()
+	return ctx.Err()
+}
+
+func TestLifecycleOptions(t *testing.T) {
+	assert.Nil(t, LifecycleOptions(struct{}{}))
+
+	ctx := context.Background()
+	resolver := &lifecycleResolver{clients: make(chan string, 1)}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, LifecycleOptions(resolver))
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+
+	select {
+	case name := <-resolver.clients:
+		assert.Equal(t, "client", name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnInitialize was not called")
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	assert.NoError(t, Shutdown(context.Background(), struct{}{}, 0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	resolver := &lifecycleResolver{}
+	require.NoError(t, Shutdown(ctx, resolver, time.Second))
+	assert.True(t, resolver.deadline)
+
+	failing := &failingShutdown{err: errors.New("flush failed")}
+	assert.EqualError(t, Shutdown(ctx, failing, 0), "flush failed")
+}
+
+type failingShutdown struct {
+	err error
+}
+
+func (f *failingShutdown) OnShutdown(context.Context) error {
+	return f.err
+}
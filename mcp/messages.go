@@ -0,0 +1,123 @@
+package mcp
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// MessageID identifies an error message the generated code and this package
+// report to clients.
+type MessageID string
+
+const (
+	// MessageUnknownField is reported when a strict input object has a
+	// property its schema does not declare. Arguments: the property name and
+	// the Go type name.
+	MessageUnknownField MessageID = "unknown_field"
+	// MessageInvalidEnumValue is reported when a value is not one of the
+	// values of an enum. Arguments: the Go type name and the value.
+	MessageInvalidEnumValue MessageID = "invalid_enum_value"
+	// MessageInternalError is returned by DefaultRecoverFunc when a handler
+	// panics. No arguments.
+	MessageInternalError MessageID = "internal_error"
+	// MessageNotImplemented is reported by DefaultNotImplementedFunc when a
+	// tool handler returns ErrNotImplemented. Arguments: the tool name.
+	MessageNotImplemented MessageID = "not_implemented"
+	// MessageMissingDiscriminator is reported when a value of a oneOf lacks
+	// the property selecting its variant. Arguments: the property name and
+	// the Go type name.
+	MessageMissingDiscriminator MessageID = "missing_discriminator"
+	// MessageInvalidDiscriminator is reported when the property selecting
+	// the variant of a oneOf has an unknown value. Arguments: the property
+	// name, the Go type name and the value.
+	MessageInvalidDiscriminator MessageID = "invalid_discriminator"
+	// MessageInvalidConstValue is reported when a value differs from the
+	// const of its schema. Arguments: the Go type name, the value and the
+	// expected value.
+	MessageInvalidConstValue MessageID = "invalid_const_value"
+	// MessageMissingClientCapability is reported when a client uses a tool,
+	// prompt or resource requiring a capability it did not declare.
+	// Arguments: the kind and name of the feature and the capability.
+	MessageMissingClientCapability MessageID = "missing_client_capability"
+	// MessageInvalidArguments is reported when the arguments of a tool call
+	// do not match its input schema. Arguments: the tool name and the
+	// validation error.
+	MessageInvalidArguments MessageID = "invalid_arguments"
+	// MessageToolDisabled is reported when a client calls a tool disabled
+	// with a ToolSwitch. Arguments: the tool name.
+	MessageToolDisabled MessageID = "tool_disabled"
+)
+
+// DefaultMessages holds the English messages, as fmt format strings taking
+// the arguments documented on each MessageID.
+var DefaultMessages = map[MessageID]string{
+	MessageUnknownField:            "unknown field %q in %s",
+	MessageInvalidEnumValue:        "invalid %s value: %q",
+	MessageInternalError:           "internal system error",
+	MessageNotImplemented:          "tool %s is not implemented yet",
+	MessageMissingDiscriminator:    "missing %s property in %s",
+	MessageInvalidDiscriminator:    "invalid %s value for %s: %q",
+	MessageInvalidConstValue:       "invalid %s value: %q, want %q",
+	MessageMissingClientCapability: "%s %s requires the %s client capability",
+	MessageInvalidArguments:        "invalid arguments for tool %s: %s",
+	MessageToolDisabled:            "tool %s is disabled",
+}
+
+// MessageFunc returns the message for id formatted with args, or false to
+// use the default message.
+type MessageFunc func(id MessageID, args []any) (string, bool)
+
+var messageFunc atomic.Pointer[MessageFunc]
+
+// SetMessageFunc overrides the messages of the generated code, to localize
+// them without editing generated files. It is meant to be called once at
+// startup; a nil f restores the default messages.
+//
+// Example:
+//
+//	mcputil.SetMessageFunc(func(id mcputil.MessageID, args []any) (string, bool) {
+//	    format, ok := frenchMessages[id]
+//	    if !ok {
+//	        return "", false
+//	    }
+//	    return fmt.Sprintf(format, args...), true
+//	})
+func SetMessageFunc(f MessageFunc) {
+	if f == nil {
+		messageFunc.Store(nil)
+		return
+	}
+	messageFunc.Store(&f)
+}
+
+// Message returns the message for id formatted with args.
+func Message(id MessageID, args ...any) string {
+	if f := messageFunc.Load(); f != nil {
+		if msg, ok := (*f)(id, args); ok {
+			return msg
+		}
+	}
+
+	format, ok := DefaultMessages[id]
+	if !ok {
+		return fmt.Sprintf("%s %v", id, args)
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// MessageError is an error reported with a message of the catalog. Its
+// message is formatted when read, so overrides apply to errors created
+// before SetMessageFunc is called.
+type MessageError struct {
+	ID   MessageID
+	Args []any
+}
+
+// NewError returns a *MessageError for id and args.
+func NewError(id MessageID, args ...any) error {
+	return &MessageError{ID: id, Args: args}
+}
+
+func (e *MessageError) Error() string {
+	return Message(e.ID, e.Args...)
+}
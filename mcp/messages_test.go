@@ -0,0 +1,39 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessage(t *testing.T) {
+	assert.Equal(t, `unknown field "color" in Pen`, Message(MessageUnknownField, "color", "Pen"))
+	assert.Equal(t, `invalid Status value: "lost"`, Message(MessageInvalidEnumValue, "Status", "lost"))
+	assert.Equal(t, `invalid Kind value: "cash", want "card"`, Message(MessageInvalidConstValue, "Kind", "cash", "card"))
+	assert.Equal(t, "quota_exceeded [10]", Message("quota_exceeded", 10))
+}
+
+func TestSetMessageFunc(t *testing.T) {
+	err := NewError(MessageInvalidEnumValue, "Status", "lost")
+
+	SetMessageFunc(func(id MessageID, args []any) (string, bool) {
+		if id != MessageInvalidEnumValue {
+			return "", false
+		}
+		return fmt.Sprintf("valeur %q invalide pour %s", args[1], args[0]), true
+	})
+	t.Cleanup(func() { SetMessageFunc(nil) })
+
+	assert.Equal(t, `valeur "lost" invalide pour Status`, err.Error())
+	assert.Equal(t, `unknown field "color" in Pen`, Message(MessageUnknownField, "color", "Pen"))
+
+	var msgErr *MessageError
+	assert.True(t, errors.As(DefaultRecoverFunc(context.Background(), "boom"), &msgErr))
+	assert.Equal(t, MessageInternalError, msgErr.ID)
+
+	SetMessageFunc(nil)
+	assert.Equal(t, `invalid Status value: "lost"`, err.Error())
+}
@@ -0,0 +1,18 @@
+package mcp
+
+// ToolMetadata describes a tool of the spec to the code around the server,
+// such as authorization layers and the allowlists of metric labels, without
+// repeating its name. The generated Registry function lists them.
+type ToolMetadata struct {
+	// Name is the name of the tool, also a constant of the generated
+	// names.go, such as ToolCreateTask.
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	// ReadOnly, Destructive, Idempotent and OpenWorld are the hints of the
+	// tool in the spec.
+	ReadOnly    bool `json:"read_only,omitempty"`
+	Destructive bool `json:"destructive,omitempty"`
+	Idempotent  bool `json:"idempotent,omitempty"`
+	OpenWorld   bool `json:"open_world,omitempty"`
+}
@@ -0,0 +1,161 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ErrNotImplemented is returned by the generated resolver stubs of tools that
+// are not implemented yet. A tool handler registered with AddTool returning
+// it, possibly wrapped, gets the result of the server's NotImplementedFunc
+// instead of a bare error.
+var ErrNotImplemented = errors.New("not implemented")
+
+// NotImplementedFunc builds the result of a call to a tool whose handler
+// returned ErrNotImplemented.
+//
+// Example:
+//
+//	server.New(resolver, mcputil.WithNotImplementedFunc(func(ctx context.Context, tool *mcp.Tool) *mcp.CallToolResult {
+//	    return &mcp.CallToolResult{
+//	        IsError: true,
+//	        Content: []mcp.Content{&mcp.TextContent{Text: tool.Name + " is coming soon"}},
+//	    }
+//	}))
+type NotImplementedFunc func(ctx context.Context, tool *mcp.Tool) *mcp.CallToolResult
+
+// DefaultNotImplementedFunc returns an error result describing the tool, so
+// that clients probing a partially implemented server learn what the tool is
+// meant to do and what it expects. The text content holds the
+// MessageNotImplemented message, the tool description and its schemas; the
+// structured content holds the same information as a JSON object.
+func DefaultNotImplementedFunc(_ context.Context, tool *mcp.Tool) *mcp.CallToolResult {
+	message := Message(MessageNotImplemented, tool.Name)
+
+	structured := map[string]any{
+		"error": message,
+		"tool":  tool.Name,
+	}
+	text := []string{message}
+	if tool.Description != "" {
+		structured["description"] = tool.Description
+		text = append(text, tool.Description)
+	}
+	if tool.InputSchema != nil {
+		structured["inputSchema"] = tool.InputSchema
+		text = append(text, "Input schema: "+schemaText(tool.InputSchema))
+	}
+	if tool.OutputSchema != nil {
+		structured["outputSchema"] = tool.OutputSchema
+		text = append(text, "Output schema: "+schemaText(tool.OutputSchema))
+	}
+
+	return &mcp.CallToolResult{
+		IsError:           true,
+		Content:           []mcp.Content{&mcp.TextContent{Text: strings.Join(text, "\n\n")}},
+		StructuredContent: structured,
+	}
+}
+
+func schemaText(schema any) string {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
+
+// notImplementedKey is the context key of the *notImplementedCall of a
+// tools/call request.
+type notImplementedKey struct{}
+
+// notImplementedCall records the tool whose handler returned
+// ErrNotImplemented.
+type notImplementedCall struct {
+	tool *mcp.Tool
+}
+
+// AddTool registers a typed tool handler on s, like mcp.AddTool. Panics of
+// the handler are recovered with opts.RecoverFunc, and calls for which it
+// returns ErrNotImplemented are answered by NotImplementedMiddleware, and
+// calls for which it returns an *Error by ErrorMiddleware. With
+// WithOutputValidation, outputs not matching t.OutputSchema are an error.
+// With WithLazyTools, the schemas of t are resolved on its first call.
+func AddTool[In, Out any](s *mcp.Server, t *mcp.Tool, h mcp.ToolHandlerFor[In, Out], opts *Options) {
+	validate := outputValidator(t, opts)
+
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, input In) (result *mcp.CallToolResult, output Out, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = opts.RecoverFunc(ctx, r)
+			}
+			if errors.Is(err, ErrNotImplemented) {
+				if call, ok := ctx.Value(notImplementedKey{}).(*notImplementedCall); ok {
+					call.tool = t
+				}
+			}
+			recordError(ctx, err)
+		}()
+
+		result, output, err = h(ctx, req, input)
+		if err == nil && validate != nil {
+			if err := validate(ctx, output); err != nil {
+				var zero Out
+				return nil, zero, err
+			}
+		}
+		return result, output, err
+	}
+	if opts.LazyTools && addLazyTool(s, t, handler) {
+		return
+	}
+	mcp.AddTool(s, t, handler)
+}
+
+// NoInputToolHandlerFor is the handler of a tool taking no arguments.
+type NoInputToolHandlerFor[Out any] func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, Out, error)
+
+// AddToolWithoutInput registers the handler of a tool taking no arguments on
+// s, like AddTool. A tool without an input schema gets an empty object
+// schema, which the MCP specification requires.
+func AddToolWithoutInput[Out any](s *mcp.Server, t *mcp.Tool, h NoInputToolHandlerFor[Out], opts *Options) {
+	if t.InputSchema == nil {
+		t.InputSchema = &jsonschema.Schema{Type: "object"}
+	}
+	AddTool(s, t, func(ctx context.Context, req *mcp.CallToolRequest, _ map[string]any) (*mcp.CallToolResult, Out, error) {
+		return h(ctx, req)
+	}, opts)
+}
+
+// NotImplementedMiddleware returns a receiving middleware that replaces the
+// result of the tool calls whose handler, registered with AddTool, returned
+// ErrNotImplemented with the result of fn. The replacement happens here
+// rather than in the handler so that the placeholder result is not validated
+// against the tool's output schema.
+//
+// A nil fn uses DefaultNotImplementedFunc.
+func NotImplementedMiddleware(fn NotImplementedFunc) mcp.Middleware {
+	if fn == nil {
+		fn = DefaultNotImplementedFunc
+	}
+
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" {
+				return next(ctx, method, req)
+			}
+
+			call := &notImplementedCall{}
+			result, err := next(context.WithValue(ctx, notImplementedKey{}, call), method, req)
+			if err != nil || call.tool == nil {
+				return result, err
+			}
+			return fn(ctx, call.tool), nil
+		}
+	}
+}
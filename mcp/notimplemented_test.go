@@ -0,0 +1,137 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type reportOutput struct {
+	Total int `json:"total"`
+}
+
+func TestNotImplemented(t *testing.T) {
+	ctx := context.Background()
+
+	callReport := func(t *testing.T, handler mcp.ToolHandlerFor[map[string]any, reportOutput], opts ...Option) *mcp.CallToolResult {
+		t.Helper()
+		o := ApplyOptions(opts)
+
+		server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+		server.AddReceivingMiddleware(NotImplementedMiddleware(o.NotImplementedFunc))
+		AddTool(server, &mcp.Tool{
+			Name:        "report",
+			Description: "Sum the amounts of a period",
+			InputSchema: &jsonschema.Schema{Type: "object"},
+			OutputSchema: &jsonschema.Schema{
+				Type:       "object",
+				Properties: map[string]*jsonschema.Schema{"total": {Type: "integer", Minimum: jsonschema.Ptr(1.0)}},
+				Required:   []string{"total"},
+			},
+		}, handler, &o)
+
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		serverSession, err := server.Connect(ctx, serverTransport, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = serverSession.Close() })
+
+		client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil)
+		session, err := client.Connect(ctx, clientTransport, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = session.Close() })
+
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "report", Arguments: map[string]any{}})
+		require.NoError(t, err)
+		return result
+	}
+
+	notImplemented := func(context.Context, *mcp.CallToolRequest, map[string]any) (*mcp.CallToolResult, reportOutput, error) {
+		return nil, reportOutput{}, fmt.Errorf("report: %w", ErrNotImplemented)
+	}
+
+	t.Run("default result describes the tool", func(t *testing.T) {
+		result := callReport(t, notImplemented)
+
+		assert.True(t, result.IsError)
+		require.Len(t, result.Content, 1)
+		text := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, text, "tool report is not implemented yet")
+		assert.Contains(t, text, "Sum the amounts of a period")
+		assert.Contains(t, text, `Output schema: {"type":"object","required":["total"]`)
+
+		structured, ok := result.StructuredContent.(map[string]any)
+		require.True(t, ok, "%T", result.StructuredContent)
+		assert.Equal(t, "report", structured["tool"])
+		assert.Equal(t, "Sum the amounts of a period", structured["description"])
+		assert.Equal(t, map[string]any{"type": "object"}, structured["inputSchema"])
+		assert.Contains(t, structured, "outputSchema")
+	})
+
+	t.Run("custom result", func(t *testing.T) {
+		result := callReport(t, notImplemented, WithNotImplementedFunc(func(_ context.Context, tool *mcp.Tool) *mcp.CallToolResult {
+			return &mcp.CallToolResult{
+				IsError: true,
+				Content: []mcp.Content{&mcp.TextContent{Text: tool.Name + " is coming soon"}},
+			}
+		}))
+
+		assert.True(t, result.IsError)
+		assert.Equal(t, "report is coming soon", result.Content[0].(*mcp.TextContent).Text)
+		assert.Nil(t, result.StructuredContent)
+	})
+
+	t.Run("other errors are left alone", func(t *testing.T) {
+		result := callReport(t, func(context.Context, *mcp.CallToolRequest, map[string]any) (*mcp.CallToolResult, reportOutput, error) {
+			return nil, reportOutput{}, fmt.Errorf("database unavailable")
+		})
+
+		assert.True(t, result.IsError)
+		assert.Equal(t, "database unavailable", result.Content[0].(*mcp.TextContent).Text)
+	})
+
+	t.Run("panics are recovered", func(t *testing.T) {
+		result := callReport(t, func(context.Context, *mcp.CallToolRequest, map[string]any) (*mcp.CallToolResult, reportOutput, error) {
+			panic("boom")
+		}, WithRecoverFunc(func(context.Context, any) error {
+			return fmt.Errorf("recovered")
+		}))
+
+		assert.True(t, result.IsError)
+		assert.Equal(t, "recovered", result.Content[0].(*mcp.TextContent).Text)
+	})
+}
+
+func TestAddToolWithoutInput(t *testing.T) {
+	ctx := context.Background()
+	opts := ApplyOptions(nil)
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	AddToolWithoutInput(server, &mcp.Tool{Name: "ping"}, func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, map[string]any, error) {
+		return nil, map[string]any{"pong": true}, nil
+	}, &opts)
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+
+	tools, err := session.ListTools(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, tools.Tools, 1)
+	assert.Equal(t, map[string]any{"type": "object"}, tools.Tools[0].InputSchema)
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "ping"})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, map[string]any{"pong": true}, result.StructuredContent)
+}
@@ -16,8 +16,8 @@ import (
 // Example usage:
 //
 //	type UpdateUserInput struct {
-//	    Name  Omittable[string] `json:"name,omitempty"`
-//	    Email Omittable[string] `json:"email,omitempty"`
+//	    Name  Omittable[string] `json:"name,omitzero"`
+//	    Email Omittable[string] `json:"email,omitzero"`
 //	}
 //
 //	func (r *Resolver) UpdateUser(input UpdateUserInput) {
@@ -104,10 +104,8 @@ func (o *Omittable[T]) UnmarshalJSON(data []byte) error {
 
 // MarshalJSON implements json.Marshaler.
 func (o Omittable[T]) MarshalJSON() ([]byte, error) {
-	// Note: When marshaling structs with Omittable fields, use *Omittable[T]
-	// if you need omitempty to work correctly. With value types, omitempty
-	// doesn't work well with custom MarshalJSON.
-	// For MCP use cases (unmarshaling input), this is not typically an issue.
+	// Note: omitempty never omits a struct, tag the fields omitzero so that
+	// an unset Omittable is left out instead of written as null.
 
 	if !o.isSet || o.value == nil {
 		return []byte("null"), nil
@@ -0,0 +1,109 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// WithOutputValidation validates the outputs of the tool handlers registered
+// with AddTool against the output schema of their tool, to catch the Go types
+// drifting from the declared schemas. A mismatch is logged and reported to
+// the client as an error result naming the tool, or panics in development
+// mode. The generated server enables it when exec.validate_output is set.
+func WithOutputValidation(enabled bool) Option {
+	return func(o *Options) {
+		o.ValidateOutput = enabled
+	}
+}
+
+// WithDevelopment makes programming errors fail loudly, for development
+// servers: tool outputs not matching their output schema panic, and the
+// panic and its stack are reported by the RecoverFunc.
+func WithDevelopment(enabled bool) Option {
+	return func(o *Options) {
+		o.Development = enabled
+	}
+}
+
+// OutputMismatchError reports a tool output not matching the output schema
+// of the tool.
+type OutputMismatchError struct {
+	Tool   string
+	GoType string
+	Err    error
+}
+
+func (e *OutputMismatchError) Error() string {
+	return fmt.Sprintf("output of tool %s (%s) does not match its output schema: %v", e.Tool, e.GoType, e.Err)
+}
+
+func (e *OutputMismatchError) Unwrap() error {
+	return e.Err
+}
+
+// outputValidator returns the function validating the outputs of t, or nil
+// when output validation is disabled or t has no *jsonschema.Schema output
+// schema. Schemas that cannot be resolved are left to the validation of the
+// MCP SDK. With WithLazyTools, the schema is resolved on the first output.
+func outputValidator(t *mcp.Tool, opts *Options) func(ctx context.Context, out any) error {
+	if !opts.ValidateOutput || t.OutputSchema == nil {
+		return nil
+	}
+	schema, ok := t.OutputSchema.(*jsonschema.Schema)
+	if !ok {
+		return nil
+	}
+	resolve := func() (*jsonschema.Resolved, error) { return resolveSchema(schema) }
+	if !opts.LazyTools {
+		resolved, err := schema.Resolve(nil)
+		if err != nil {
+			return nil
+		}
+		resolve = func() (*jsonschema.Resolved, error) { return resolved, nil }
+	}
+
+	return func(ctx context.Context, out any) error {
+		resolved, err := resolve()
+		if err != nil {
+			return nil
+		}
+		err = validateOutput(resolved, out)
+		if err == nil {
+			return nil
+		}
+
+		mismatch := &OutputMismatchError{Tool: t.Name, GoType: fmt.Sprintf("%T", out), Err: err}
+		opts.Logger.LogAttrs(ctx, slog.LevelError, "tool output does not match its output schema",
+			slog.String("tool", t.Name),
+			slog.String("type", mismatch.GoType),
+			slog.String("error", err.Error()),
+		)
+		if opts.Development {
+			panic(mismatch)
+		}
+		return mismatch
+	}
+}
+
+// validateOutput validates the JSON encoding of out against resolved. Nil
+// outputs are left to the MCP SDK, which replaces them with zero values.
+func validateOutput(resolved *jsonschema.Resolved, out any) error {
+	data, err := json.Marshal(out)
+	if err != nil {
+		return err
+	}
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	if value == nil {
+		return nil
+	}
+	return resolved.Validate(value)
+}
@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputValidation(t *testing.T) {
+	ctx := context.Background()
+
+	type user struct {
+		Name string `json:"name,omitempty"`
+	}
+
+	connect := func(t *testing.T, opts ...Option) *mcp.ClientSession {
+		t.Helper()
+		o := ApplyOptions(opts)
+
+		server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+		AddToolWithoutInput(server, &mcp.Tool{
+			Name: "whoami",
+			OutputSchema: &jsonschema.Schema{
+				Type:       "object",
+				Properties: map[string]*jsonschema.Schema{"name": {Type: "string"}},
+				Required:   []string{"name"},
+			},
+		}, func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, user, error) {
+			return nil, user{}, nil
+		}, &o)
+
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		serverSession, err := server.Connect(ctx, serverTransport, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = serverSession.Close() })
+
+		client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil)
+		session, err := client.Connect(ctx, clientTransport, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = session.Close() })
+		return session
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		var logs bytes.Buffer
+		session := connect(t, WithOutputValidation(true), WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "whoami"})
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "output of tool whoami (mcp.user) does not match its output schema")
+		assert.Contains(t, logs.String(), "tool output does not match its output schema")
+		assert.Contains(t, logs.String(), "tool=whoami")
+	})
+
+	t.Run("development", func(t *testing.T) {
+		var recovered any
+		session := connect(t,
+			WithOutputValidation(true),
+			WithDevelopment(true),
+			WithLogger(slog.New(slog.DiscardHandler)),
+			WithRecoverFunc(func(_ context.Context, err any) error {
+				recovered = err
+				return errors.New("internal error")
+			}),
+		)
+
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "whoami"})
+		require.NoError(t, err)
+		assert.True(t, result.IsError)
+		var mismatch *OutputMismatchError
+		require.ErrorAs(t, recovered.(error), &mismatch)
+		assert.Equal(t, "whoami", mismatch.Tool)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		session := connect(t)
+
+		_, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "whoami"})
+		assert.ErrorContains(t, err, "validating tool output", "the MCP SDK still rejects the output")
+	})
+}
@@ -2,8 +2,9 @@ package mcp
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"runtime/debug"
 )
@@ -28,7 +29,7 @@ func DefaultRecoverFunc(_ context.Context, err any) error {
 	fmt.Fprintln(os.Stderr, err)
 	fmt.Fprintln(os.Stderr)
 	debug.PrintStack()
-	return errors.New("internal system error")
+	return NewError(MessageInternalError)
 }
 
 // Option configures the generated MCP server.
@@ -37,6 +38,42 @@ type Option func(*Options)
 // Options holds configuration for the generated MCP server.
 type Options struct {
 	RecoverFunc RecoverFunc
+	// NotImplementedFunc builds the result of the tool calls whose handler
+	// returned ErrNotImplemented.
+	NotImplementedFunc NotImplementedFunc
+	// Logger receives the diagnostics of the generated middlewares.
+	Logger *slog.Logger
+	// Experiments enables the experiments of the spec, by name.
+	Experiments map[string]bool
+	// ValidateOutput validates tool outputs against their output schema.
+	ValidateOutput bool
+	// Development makes programming errors panic rather than be reported.
+	Development bool
+	// DeprecatedCalls counts the calls to deprecated tools.
+	DeprecatedCalls *DeprecatedCalls
+	// DevFixtures answers the calls to unimplemented handlers with the
+	// fixtures of the spec.
+	DevFixtures bool
+	// ValidationFailures counts the tool arguments rejected by the input
+	// validation.
+	ValidationFailures *ValidationFailures
+	// ValidationFailureFunc receives the tool arguments rejected by the
+	// input validation.
+	ValidationFailureFunc ValidationFailureFunc
+	// LazyTools resolves the schemas of each tool on its first call.
+	LazyTools bool
+	// TranscriptWriter receives the transcript of every session.
+	TranscriptWriter io.Writer
+	// TranscriptDir receives the transcript of each session in its own
+	// file.
+	TranscriptDir string
+	// TranscriptRedactFunc redacts the transcript entries before they are
+	// written.
+	TranscriptRedactFunc TranscriptRedactFunc
+	// Clock tells the time to the generated middlewares.
+	Clock Clock
+	// IDGenerator generates the IDs of the generated middlewares.
+	IDGenerator IDGenerator
 }
 
 // WithRecoverFunc sets the panic recover function for tool handlers.
@@ -48,6 +85,24 @@ func WithRecoverFunc(fn RecoverFunc) Option {
 	}
 }
 
+// WithNotImplementedFunc sets the function building the result of the tool
+// calls whose handler returned ErrNotImplemented, as the generated resolver
+// stubs do. Defaults to DefaultNotImplementedFunc.
+func WithNotImplementedFunc(fn NotImplementedFunc) Option {
+	return func(o *Options) {
+		o.NotImplementedFunc = fn
+	}
+}
+
+// WithLogger sets the logger of the diagnostics middlewares, such as the
+// slow call logging enabled by exec.slow_call_threshold. Defaults to
+// slog.Default().
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *Options) {
+		o.Logger = logger
+	}
+}
+
 // ApplyOptions applies the given options to an Options struct.
 // If RecoverFunc is nil after applying options, it is set to DefaultRecoverFunc:
 // recovery is always enabled, matching gqlgen's behavior.
@@ -59,5 +114,23 @@ func ApplyOptions(opts []Option) Options {
 	if o.RecoverFunc == nil {
 		o.RecoverFunc = DefaultRecoverFunc
 	}
+	if o.NotImplementedFunc == nil {
+		o.NotImplementedFunc = DefaultNotImplementedFunc
+	}
+	if o.Logger == nil {
+		o.Logger = slog.Default()
+	}
+	if o.DeprecatedCalls == nil {
+		o.DeprecatedCalls = &DeprecatedCalls{}
+	}
+	if o.ValidationFailures == nil {
+		o.ValidationFailures = &ValidationFailures{}
+	}
+	if o.Clock == nil {
+		o.Clock = SystemClock
+	}
+	if o.IDGenerator == nil {
+		o.IDGenerator = RandomIDGenerator
+	}
 	return o
 }
@@ -3,6 +3,7 @@ package mcp
 import (
 	"context"
 	"errors"
+	"log/slog"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -14,6 +15,11 @@ func TestApplyOptions(t *testing.T) {
 		assert.NotNil(t, opts.RecoverFunc)
 	})
 
+	t.Run("no options uses default logger", func(t *testing.T) {
+		opts := ApplyOptions(nil)
+		assert.Equal(t, slog.Default(), opts.Logger)
+	})
+
 	t.Run("nil recover func falls back to default", func(t *testing.T) {
 		opts := ApplyOptions([]Option{WithRecoverFunc(nil)})
 		assert.NotNil(t, opts.RecoverFunc)
@@ -0,0 +1,98 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// SchemaRegistry holds the component schemas of a spec by name, so that
+// handlers and middleware can validate JSON fragments against them without
+// repeating the schemas. Schemas are resolved on their first lookup.
+type SchemaRegistry struct {
+	schemas map[string]*jsonschema.Schema
+
+	mu       sync.Mutex
+	resolved map[string]*jsonschema.Resolved
+}
+
+// NewSchemaRegistry returns a registry of schemas, keyed by component name.
+//
+// Example:
+//
+//	var Schemas = mcputil.NewSchemaRegistry(map[string]*jsonschema.Schema{
+//	    "Task": mcputil.MustUnmarshalSchema(`{"type":"object"}`),
+//	})
+func NewSchemaRegistry(schemas map[string]*jsonschema.Schema) *SchemaRegistry {
+	return &SchemaRegistry{
+		schemas:  schemas,
+		resolved: make(map[string]*jsonschema.Resolved, len(schemas)),
+	}
+}
+
+// Names returns the sorted names of the schemas of the registry.
+func (r *SchemaRegistry) Names() []string {
+	names := make([]string, 0, len(r.schemas))
+	for name := range r.schemas {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Schema returns the schema registered under name.
+func (r *SchemaRegistry) Schema(name string) (*jsonschema.Schema, bool) {
+	s, ok := r.schemas[name]
+	return s, ok
+}
+
+// Lookup returns the resolved schema registered under name, ready to
+// validate values. It returns false when no schema has this name or when the
+// schema cannot be resolved.
+func (r *SchemaRegistry) Lookup(name string) (*jsonschema.Resolved, bool) {
+	rs, err := r.resolve(name)
+	return rs, err == nil
+}
+
+// Validate validates a JSON fragment against the schema registered under
+// name.
+//
+// Example:
+//
+//	if err := generated.Schemas.Validate("Task", raw); err != nil {
+//	    return nil, fmt.Errorf("invalid task: %w", err)
+//	}
+func (r *SchemaRegistry) Validate(name string, data json.RawMessage) error {
+	rs, err := r.resolve(name)
+	if err != nil {
+		return err
+	}
+
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	return rs.Validate(value)
+}
+
+func (r *SchemaRegistry) resolve(name string) (*jsonschema.Resolved, error) {
+	s, ok := r.schemas[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown schema %q", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rs, ok := r.resolved[name]; ok {
+		return rs, nil
+	}
+	rs, err := s.Resolve(nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve schema %q: %w", name, err)
+	}
+	r.resolved[name] = rs
+	return rs, nil
+}
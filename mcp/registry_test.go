@@ -0,0 +1,45 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaRegistry(t *testing.T) {
+	registry := NewSchemaRegistry(map[string]*jsonschema.Schema{
+		"Task":   MustUnmarshalSchema(`{"type":"object","properties":{"title":{"type":"string"}},"required":["title"]}`),
+		"Status": {Type: "string", Enum: []any{"open", "done"}},
+	})
+
+	assert.Equal(t, []string{"Status", "Task"}, registry.Names())
+
+	t.Run("lookup", func(t *testing.T) {
+		rs, ok := registry.Lookup("Status")
+		require.True(t, ok)
+		assert.NoError(t, rs.Validate("open"))
+		assert.Error(t, rs.Validate("closed"))
+
+		_, ok = registry.Lookup("User")
+		assert.False(t, ok)
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		assert.NoError(t, registry.Validate("Task", json.RawMessage(`{"title":"write docs"}`)))
+		assert.ErrorContains(t, registry.Validate("Task", json.RawMessage(`{}`)), "title")
+		assert.Error(t, registry.Validate("Task", json.RawMessage(`{`)))
+		assert.EqualError(t, registry.Validate("User", json.RawMessage(`{}`)), `unknown schema "User"`)
+	})
+
+	t.Run("unresolvable schema", func(t *testing.T) {
+		registry := NewSchemaRegistry(map[string]*jsonschema.Schema{
+			"Broken": {Ref: "#/$defs/missing"},
+		})
+		_, ok := registry.Lookup("Broken")
+		assert.False(t, ok)
+		assert.ErrorContains(t, registry.Validate("Broken", json.RawMessage(`{}`)), `cannot resolve schema "Broken"`)
+	})
+}
@@ -0,0 +1,32 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Result returns the results of a tool without an output schema, whose
+// handler returns a map[string]any, with v as structured content. It lets
+// such handlers build their result with a typed struct rather than a map.
+// v must encode to a JSON object, as structured content is an object.
+//
+// Example:
+//
+//	func (r *Resolver) StatsTool(ctx context.Context, req *mcp.CallToolRequest, input *StatsInput) (*mcp.CallToolResult, map[string]any, error) {
+//	    return mcputil.Result(Stats{Users: 42, Active: 7})
+//	}
+func Result[T any](v T) (*mcp.CallToolResult, map[string]any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, nil, fmt.Errorf("cannot marshal structured content: %w", err)
+	}
+
+	var content map[string]any
+	if err := json.Unmarshal(data, &content); err != nil || content == nil {
+		return nil, nil, fmt.Errorf("structured content must be a JSON object, got %T", v)
+	}
+
+	return nil, content, nil
+}
@@ -0,0 +1,64 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stats struct {
+	Users  int    `json:"users"`
+	Region string `json:"region,omitempty"`
+}
+
+func TestResult(t *testing.T) {
+	t.Run("struct", func(t *testing.T) {
+		result, content, err := Result(stats{Users: 42})
+		require.NoError(t, err)
+		assert.Nil(t, result)
+		assert.Equal(t, map[string]any{"users": float64(42)}, content)
+	})
+
+	t.Run("pointer", func(t *testing.T) {
+		_, content, err := Result(&stats{Users: 1, Region: "eu"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]any{"users": float64(1), "region": "eu"}, content)
+	})
+
+	t.Run("not an object", func(t *testing.T) {
+		_, _, err := Result([]int{1, 2})
+		assert.EqualError(t, err, "structured content must be a JSON object, got []int")
+
+		_, _, err = Result[*stats](nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("structured content of a call", func(t *testing.T) {
+		ctx := context.Background()
+		opts := ApplyOptions(nil)
+
+		server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+		AddToolWithoutInput(server, &mcp.Tool{Name: "stats"}, func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, map[string]any, error) {
+			return Result(stats{Users: 42, Region: "eu"})
+		}, &opts)
+
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		serverSession, err := server.Connect(ctx, serverTransport, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = serverSession.Close() })
+
+		client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil)
+		session, err := client.Connect(ctx, clientTransport, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = session.Close() })
+
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "stats"})
+		require.NoError(t, err)
+		assert.False(t, result.IsError)
+		assert.Equal(t, map[string]any{"users": float64(42), "region": "eu"}, result.StructuredContent)
+		assert.Equal(t, `{"region":"eu","users":42}`, result.Content[0].(*mcp.TextContent).Text)
+	})
+}
@@ -0,0 +1,239 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// DefaultHTTPPath is the path the streamable HTTP handler is mounted on
+	// when RunConfig.HTTPPath is empty.
+	DefaultHTTPPath = "/mcp"
+
+	// DefaultHTTPAddr is the listen address used when the HTTP transport is
+	// selected through the environment without an explicit address.
+	DefaultHTTPAddr = ":8080"
+
+	// DefaultShutdownTimeout bounds the graceful shutdown of the HTTP
+	// transport when RunConfig.ShutdownTimeout is zero.
+	DefaultShutdownTimeout = 10 * time.Second
+)
+
+// RunConfig selects the transports served by Run. Stdio and HTTP can be
+// enabled together, in which case both are served by the same server.
+type RunConfig struct {
+	// Stdio serves the server over standard input and output.
+	Stdio bool
+	// HTTPAddr serves the streamable HTTP transport on this address when set.
+	HTTPAddr string
+	// HTTPPath is the path of the HTTP handler. Defaults to DefaultHTTPPath.
+	HTTPPath string
+	// ShutdownTimeout bounds the graceful HTTP shutdown. Defaults to
+	// DefaultShutdownTimeout.
+	ShutdownTimeout time.Duration
+	// DevFixtures serves the fixtures of the spec in place of the handlers
+	// that are not implemented, see WithDevFixtures.
+	DevFixtures bool
+	// AdminAddr serves the admin endpoints of AdminHandler on this address
+	// when set, guarded by the Authenticator of Admin.
+	AdminAddr string
+	// Admin configures the admin endpoints. The generated Run function sets
+	// its Info, Tools and Switch.
+	Admin AdminConfig
+	// TranscriptDir writes the transcript of each session to this directory
+	// when set, see WithTranscriptDir.
+	TranscriptDir string
+	// Env declares the environment variables of the server, which Run
+	// checks with CheckEnv before serving. The generated Run function sets
+	// it.
+	Env []EnvVar
+	// PrintEnv makes Run write Env to standard output with PrintEnv rather
+	// than serve.
+	PrintEnv bool
+}
+
+// RunConfigFromEnv builds a RunConfig from the environment:
+//
+//   - MCP_TRANSPORT: "stdio" (default), "http" or "both"
+//   - MCP_HTTP_ADDR: HTTP listen address, defaults to DefaultHTTPAddr
+//   - MCP_HTTP_PATH: HTTP handler path, defaults to DefaultHTTPPath
+//   - MCP_DEV_FIXTURES: "true" to serve the fixtures of the spec
+//   - MCP_ADMIN_ADDR: admin listen address, not served by default
+//   - MCP_TRANSCRIPT_DIR: directory of the session transcripts, not written
+//     by default
+func RunConfigFromEnv() RunConfig {
+	cfg := RunConfig{
+		HTTPPath:      os.Getenv("MCP_HTTP_PATH"),
+		AdminAddr:     os.Getenv("MCP_ADMIN_ADDR"),
+		TranscriptDir: os.Getenv("MCP_TRANSCRIPT_DIR"),
+	}
+	cfg.DevFixtures, _ = strconv.ParseBool(os.Getenv("MCP_DEV_FIXTURES"))
+
+	addr := os.Getenv("MCP_HTTP_ADDR")
+	if addr == "" {
+		addr = DefaultHTTPAddr
+	}
+
+	switch strings.ToLower(os.Getenv("MCP_TRANSPORT")) {
+	case "http":
+		cfg.HTTPAddr = addr
+	case "both":
+		cfg.Stdio = true
+		cfg.HTTPAddr = addr
+	default:
+		cfg.Stdio = true
+	}
+
+	return cfg
+}
+
+// RegisterFlags binds the RunConfig fields to command line flags, using the
+// current values as defaults. Call it after RunConfigFromEnv to let flags
+// override the environment.
+func (c *RunConfig) RegisterFlags(fs *flag.FlagSet) {
+	fs.BoolVar(&c.Stdio, "stdio", c.Stdio, "serve MCP over standard input/output")
+	fs.StringVar(&c.HTTPAddr, "http", c.HTTPAddr, "serve MCP over streamable HTTP on this address")
+	fs.StringVar(&c.HTTPPath, "http-path", c.HTTPPath, "path of the MCP HTTP handler")
+	fs.DurationVar(&c.ShutdownTimeout, "shutdown-timeout", c.ShutdownTimeout, "graceful shutdown timeout")
+	fs.BoolVar(&c.DevFixtures, "dev-fixtures", c.DevFixtures, "serve the fixtures of the spec in place of unimplemented handlers")
+	fs.StringVar(&c.AdminAddr, "admin", c.AdminAddr, "serve the admin endpoints on this address")
+	fs.StringVar(&c.TranscriptDir, "transcript-dir", c.TranscriptDir, "write the transcript of each session to this directory")
+	fs.BoolVar(&c.PrintEnv, "print-env", c.PrintEnv, "print the environment variables of the server and exit")
+}
+
+// Run serves server on the transports selected by cfg until ctx is cancelled,
+// an interrupt or termination signal is received, or one of the transports
+// stops. When one transport stops, the others are shut down gracefully. It
+// first checks the environment variables of cfg.Env, or only prints them with
+// cfg.PrintEnv.
+//
+// With cfg.AdminAddr, the admin endpoints of AdminHandler are served on
+// their own listener as long as the transports, and the tools disabled by
+// cfg.Admin.Switch are hidden from the clients.
+//
+// Example:
+//
+//	cfg := mcputil.RunConfigFromEnv()
+//	cfg.RegisterFlags(flag.CommandLine)
+//	flag.Parse()
+//
+//	if err := mcputil.Run(ctx, server.New(resolver), cfg); err != nil {
+//	    log.Fatal(err)
+//	}
+func Run(ctx context.Context, server *mcp.Server, cfg RunConfig) error {
+	if cfg.PrintEnv {
+		return PrintEnv(os.Stdout, cfg.Env)
+	}
+	if err := CheckEnv(cfg.Env); err != nil {
+		return err
+	}
+	if !cfg.Stdio && cfg.HTTPAddr == "" {
+		return errors.New("no transport configured: enable stdio or set an HTTP address")
+	}
+	if cfg.HTTPPath == "" {
+		cfg.HTTPPath = DefaultHTTPPath
+	}
+	if cfg.ShutdownTimeout == 0 {
+		cfg.ShutdownTimeout = DefaultShutdownTimeout
+	}
+	if cfg.AdminAddr != "" {
+		if cfg.Admin.Authenticator == nil {
+			return errors.New("admin endpoints require an authenticator")
+		}
+		if cfg.Admin.Switch != nil {
+			server.AddReceivingMiddleware(ToolSwitchMiddleware(cfg.Admin.Switch))
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make(chan error, 2)
+	running := 0
+
+	if cfg.Stdio {
+		running++
+		go func() {
+			errs <- runStdio(ctx, server)
+		}()
+	}
+
+	if cfg.HTTPAddr != "" {
+		running++
+		go func() {
+			errs <- runHTTP(ctx, server, cfg)
+		}()
+	}
+
+	if cfg.AdminAddr != "" {
+		running++
+		go func() {
+			errs <- serveHTTP(ctx, cfg.AdminAddr, AdminHandler(cfg.Admin), cfg.ShutdownTimeout)
+		}()
+	}
+
+	var firstErr error
+	for range running {
+		err := <-errs
+		cancel()
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+func runStdio(ctx context.Context, server *mcp.Server) error {
+	err := server.Run(ctx, &mcp.StdioTransport{})
+	if ctx.Err() != nil {
+		// Cancellation is the normal way to stop serving.
+		return nil
+	}
+	return err
+}
+
+func runHTTP(ctx context.Context, server *mcp.Server, cfg RunConfig) error {
+	mux := http.NewServeMux()
+	mux.Handle(cfg.HTTPPath, mcp.NewStreamableHTTPHandler(
+		func(*http.Request) *mcp.Server { return server },
+		nil,
+	))
+	return serveHTTP(ctx, cfg.HTTPAddr, mux, cfg.ShutdownTimeout)
+}
+
+// serveHTTP serves handler on addr until ctx is cancelled, then shuts the
+// server down gracefully within timeout.
+func serveHTTP(ctx context.Context, addr string, handler http.Handler, timeout time.Duration) error {
+	httpServer := &http.Server{
+		Addr:    addr,
+		Handler: handler,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		return httpServer.Shutdown(shutdownCtx)
+	}
+}
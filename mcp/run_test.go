@@ -0,0 +1,112 @@
+package mcp
+
+import (
+	"context"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunConfigFromEnv(t *testing.T) {
+	t.Run("defaults to stdio", func(t *testing.T) {
+		t.Setenv("MCP_TRANSPORT", "")
+		cfg := RunConfigFromEnv()
+		assert.True(t, cfg.Stdio)
+		assert.Empty(t, cfg.HTTPAddr)
+	})
+
+	t.Run("http with default address", func(t *testing.T) {
+		t.Setenv("MCP_TRANSPORT", "http")
+		t.Setenv("MCP_HTTP_ADDR", "")
+		cfg := RunConfigFromEnv()
+		assert.False(t, cfg.Stdio)
+		assert.Equal(t, DefaultHTTPAddr, cfg.HTTPAddr)
+	})
+
+	t.Run("both with custom address and path", func(t *testing.T) {
+		t.Setenv("MCP_TRANSPORT", "BOTH")
+		t.Setenv("MCP_HTTP_ADDR", "127.0.0.1:9000")
+		t.Setenv("MCP_HTTP_PATH", "/rpc")
+		cfg := RunConfigFromEnv()
+		assert.True(t, cfg.Stdio)
+		assert.Equal(t, "127.0.0.1:9000", cfg.HTTPAddr)
+		assert.Equal(t, "/rpc", cfg.HTTPPath)
+	})
+
+	t.Run("dev fixtures", func(t *testing.T) {
+		t.Setenv("MCP_DEV_FIXTURES", "true")
+		assert.True(t, RunConfigFromEnv().DevFixtures)
+		t.Setenv("MCP_DEV_FIXTURES", "")
+		assert.False(t, RunConfigFromEnv().DevFixtures)
+	})
+
+	t.Run("admin address", func(t *testing.T) {
+		t.Setenv("MCP_ADMIN_ADDR", "127.0.0.1:9090")
+		assert.Equal(t, "127.0.0.1:9090", RunConfigFromEnv().AdminAddr)
+		t.Setenv("MCP_ADMIN_ADDR", "")
+		assert.Empty(t, RunConfigFromEnv().AdminAddr)
+	})
+
+	t.Run("transcript dir", func(t *testing.T) {
+		t.Setenv("MCP_TRANSCRIPT_DIR", "/var/log/mcp")
+		assert.Equal(t, "/var/log/mcp", RunConfigFromEnv().TranscriptDir)
+	})
+}
+
+func TestRunConfigRegisterFlags(t *testing.T) {
+	cfg := RunConfig{Stdio: true}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	cfg.RegisterFlags(fs)
+
+	require.NoError(t, fs.Parse([]string{"-stdio=false", "-http", ":9090", "-shutdown-timeout", "2s", "-dev-fixtures", "-admin", ":9091", "-transcript-dir", "transcripts", "--print-env"}))
+	assert.False(t, cfg.Stdio)
+	assert.Equal(t, ":9090", cfg.HTTPAddr)
+	assert.Equal(t, 2*time.Second, cfg.ShutdownTimeout)
+	assert.True(t, cfg.DevFixtures)
+	assert.Equal(t, ":9091", cfg.AdminAddr)
+	assert.Equal(t, "transcripts", cfg.TranscriptDir)
+	assert.True(t, cfg.PrintEnv)
+}
+
+func TestRun(t *testing.T) {
+	newServer := func() *mcp.Server {
+		return mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	}
+
+	t.Run("no transport", func(t *testing.T) {
+		err := Run(context.Background(), newServer(), RunConfig{})
+		assert.Error(t, err)
+	})
+
+	t.Run("http stops on cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			done <- Run(ctx, newServer(), RunConfig{HTTPAddr: "127.0.0.1:0"})
+		}()
+
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+
+		select {
+		case err := <-done:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Run did not return after cancel")
+		}
+	})
+
+	t.Run("admin without authenticator", func(t *testing.T) {
+		err := Run(context.Background(), newServer(), RunConfig{HTTPAddr: "127.0.0.1:0", AdminAddr: "127.0.0.1:0"})
+		assert.EqualError(t, err, "admin endpoints require an authenticator")
+	})
+
+	t.Run("invalid http address", func(t *testing.T) {
+		err := Run(context.Background(), newServer(), RunConfig{HTTPAddr: "invalid-address"})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,83 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SanitizeText returns s as valid UTF-8 without control characters: invalid
+// bytes are replaced by U+FFFD and control characters other than tab, line
+// feed and carriage return are removed. Text already clean is returned as is.
+func SanitizeText(s string) string {
+	if utf8.ValidString(s) && strings.IndexFunc(s, isStrippedControl) < 0 {
+		return s
+	}
+
+	return strings.Map(func(r rune) rune {
+		if isStrippedControl(r) {
+			return -1
+		}
+		return r
+	}, strings.ToValidUTF8(s, string(utf8.RuneError)))
+}
+
+func isStrippedControl(r rune) bool {
+	return unicode.IsControl(r) && r != '\t' && r != '\n' && r != '\r'
+}
+
+// SanitizeMiddleware returns a receiving middleware passing the text of tool
+// call, prompt and resource results through SanitizeText, so that text built
+// from untrusted data cannot trip up clients reading the stdio transport.
+// Structured content and binary data are left alone.
+//
+// Example:
+//
+//	server.AddReceivingMiddleware(mcputil.SanitizeMiddleware())
+func SanitizeMiddleware() mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			result, err := next(ctx, method, req)
+			if err != nil {
+				return result, err
+			}
+
+			switch result := result.(type) {
+			case *mcp.CallToolResult:
+				for _, content := range result.Content {
+					sanitizeContent(content)
+				}
+			case *mcp.GetPromptResult:
+				result.Description = SanitizeText(result.Description)
+				for _, message := range result.Messages {
+					if message != nil {
+						sanitizeContent(message.Content)
+					}
+				}
+			case *mcp.ReadResourceResult:
+				for _, contents := range result.Contents {
+					sanitizeResourceContents(contents)
+				}
+			}
+			return result, nil
+		}
+	}
+}
+
+func sanitizeContent(content mcp.Content) {
+	switch content := content.(type) {
+	case *mcp.TextContent:
+		content.Text = SanitizeText(content.Text)
+	case *mcp.EmbeddedResource:
+		sanitizeResourceContents(content.Resource)
+	}
+}
+
+func sanitizeResourceContents(contents *mcp.ResourceContents) {
+	if contents != nil && contents.Text != "" {
+		contents.Text = SanitizeText(contents.Text)
+	}
+}
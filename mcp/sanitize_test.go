@@ -0,0 +1,62 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"clean", "Größe\tin cm\r\n", "Größe\tin cm\r\n"},
+		{"control characters", "bell\a escape\x1b[0m nul\x00 del\x7f c1\u0085", "bell escape[0m nul del c1"},
+		{"invalid UTF-8", "caf\xe9 \xff\xfe", "caf� �"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, SanitizeText(tt.in))
+		})
+	}
+}
+
+func TestSanitizeMiddleware(t *testing.T) {
+	handle := func(result mcp.Result) mcp.Result {
+		t.Helper()
+		handler := SanitizeMiddleware()(func(context.Context, string, mcp.Request) (mcp.Result, error) {
+			return result, nil
+		})
+		out, err := handler(context.Background(), "", nil)
+		require.NoError(t, err)
+		return out
+	}
+
+	toolResult := handle(&mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: "ok\x00"},
+			&mcp.EmbeddedResource{Resource: &mcp.ResourceContents{URI: "docs://a", Text: "\xffdoc"}},
+			&mcp.ImageContent{Data: []byte{0x00, 0xff}, MIMEType: "image/png"},
+		},
+	}).(*mcp.CallToolResult)
+	assert.Equal(t, "ok", toolResult.Content[0].(*mcp.TextContent).Text)
+	assert.Equal(t, "�doc", toolResult.Content[1].(*mcp.EmbeddedResource).Resource.Text)
+	assert.Equal(t, []byte{0x00, 0xff}, toolResult.Content[2].(*mcp.ImageContent).Data, "binary data is left alone")
+
+	promptResult := handle(&mcp.GetPromptResult{
+		Description: "intro\x1b",
+		Messages:    []*mcp.PromptMessage{{Role: "user", Content: &mcp.TextContent{Text: "hi\x07"}}},
+	}).(*mcp.GetPromptResult)
+	assert.Equal(t, "intro", promptResult.Description)
+	assert.Equal(t, "hi", promptResult.Messages[0].Content.(*mcp.TextContent).Text)
+
+	resourceResult := handle(&mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{{URI: "docs://a", Text: "line\x00\n"}},
+	}).(*mcp.ReadResourceResult)
+	assert.Equal(t, "line\n", resourceResult.Contents[0].Text)
+}
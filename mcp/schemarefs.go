@@ -0,0 +1,135 @@
+package mcp
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// componentRefPrefix prefixes the references to the component schemas of a
+// spec.
+const componentRefPrefix = "#/components/schemas/"
+
+// SchemaRefs holds the component schemas the schemas unmarshaled by
+// MustUnmarshalSchemaRefs refer to, by component name.
+type SchemaRefs map[string]*jsonschema.Schema
+
+// MustUnmarshalSchemaRefs unmarshals a JSON schema string like
+// MustUnmarshalSchema, replacing its {"$ref": "#/components/schemas/<name>"}
+// subschemas with the schemas of refs, so that the components several
+// schemas use are embedded in the binary once. It panics on references
+// missing from refs.
+//
+// A component used more than once in the schema is copied, since a schema
+// must be a tree to be resolved; the components are never modified.
+//
+// Example:
+//
+//	var (
+//	    TaskComponentSchema      = mcputil.MustUnmarshalSchema(`{"type":"object"}`)
+//	    CreateTaskToolInputSchema = mcputil.MustUnmarshalSchemaRefs(
+//	        `{"type":"object","properties":{"task":{"$ref":"#/components/schemas/Task"}}}`,
+//	        mcputil.SchemaRefs{"Task": TaskComponentSchema},
+//	    )
+//	)
+func MustUnmarshalSchemaRefs(schemaJSON string, refs SchemaRefs) *jsonschema.Schema {
+	schema := MustUnmarshalSchema(schemaJSON)
+	if schema.Ref != "" {
+		return refSchema(schema, refs)
+	}
+
+	seen := make(map[*jsonschema.Schema]bool)
+	var link func(s *jsonschema.Schema)
+	link = func(s *jsonschema.Schema) {
+		seen[s] = true
+		eachSubschema(s, func(sub *jsonschema.Schema, replace func(*jsonschema.Schema)) {
+			if sub.Ref == "" {
+				link(sub)
+				return
+			}
+
+			component := refSchema(sub, refs)
+			if shares(component, seen) {
+				component = component.CloneSchemas()
+			}
+			mark(component, seen)
+			replace(component)
+		})
+	}
+	link(schema)
+	return schema
+}
+
+// refSchema returns the component of refs s refers to.
+func refSchema(s *jsonschema.Schema, refs SchemaRefs) *jsonschema.Schema {
+	name, ok := strings.CutPrefix(s.Ref, componentRefPrefix)
+	if !ok || refs[name] == nil {
+		panic("invalid schema JSON: unknown reference " + s.Ref)
+	}
+	return refs[name]
+}
+
+// shares reports whether s or one of its subschemas is in seen.
+func shares(s *jsonschema.Schema, seen map[*jsonschema.Schema]bool) bool {
+	if seen[s] {
+		return true
+	}
+	found := false
+	eachSubschema(s, func(sub *jsonschema.Schema, _ func(*jsonschema.Schema)) {
+		found = found || shares(sub, seen)
+	})
+	return found
+}
+
+// mark adds s and its subschemas to seen.
+func mark(s *jsonschema.Schema, seen map[*jsonschema.Schema]bool) {
+	seen[s] = true
+	eachSubschema(s, func(sub *jsonschema.Schema, _ func(*jsonschema.Schema)) {
+		mark(sub, seen)
+	})
+}
+
+var (
+	schemaType      = reflect.TypeFor[*jsonschema.Schema]()
+	schemaSliceType = reflect.TypeFor[[]*jsonschema.Schema]()
+	schemaMapType   = reflect.TypeFor[map[string]*jsonschema.Schema]()
+)
+
+// eachSubschema calls f with the direct subschemas of s, along with the
+// function replacing the subschema in s.
+func eachSubschema(s *jsonschema.Schema, f func(sub *jsonschema.Schema, replace func(*jsonschema.Schema))) {
+	v := reflect.ValueOf(s).Elem()
+	for i := range v.NumField() {
+		if !v.Type().Field(i).IsExported() {
+			continue
+		}
+
+		field := v.Field(i)
+		switch field.Type() {
+		case schemaType:
+			if !field.IsNil() {
+				f(field.Interface().(*jsonschema.Schema), func(sub *jsonschema.Schema) {
+					field.Set(reflect.ValueOf(sub))
+				})
+			}
+		case schemaSliceType:
+			for j := range field.Len() {
+				elem := field.Index(j)
+				if !elem.IsNil() {
+					f(elem.Interface().(*jsonschema.Schema), func(sub *jsonschema.Schema) {
+						elem.Set(reflect.ValueOf(sub))
+					})
+				}
+			}
+		case schemaMapType:
+			for _, key := range field.MapKeys() {
+				if elem := field.MapIndex(key); !elem.IsNil() {
+					f(elem.Interface().(*jsonschema.Schema), func(sub *jsonschema.Schema) {
+						field.SetMapIndex(key, reflect.ValueOf(sub))
+					})
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package mcp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMustUnmarshalSchemaRefs(t *testing.T) {
+	address := MustUnmarshalSchema(`{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}`)
+	user := MustUnmarshalSchemaRefs(
+		`{"type":"object","properties":{"address":{"$ref":"#/components/schemas/Address"}}}`,
+		SchemaRefs{"Address": address},
+	)
+
+	t.Run("shared components", func(t *testing.T) {
+		assert.Same(t, address, user.Properties["address"])
+		assert.Same(t, user, MustUnmarshalSchemaRefs(`{"$ref":"#/components/schemas/User"}`, SchemaRefs{"User": user}))
+	})
+
+	t.Run("components used twice are copied", func(t *testing.T) {
+		input := MustUnmarshalSchemaRefs(
+			`{"type":"object","properties":{"billing":{"$ref":"#/components/schemas/Address"},"owner":{"$ref":"#/components/schemas/User"}}}`,
+			SchemaRefs{"Address": address, "User": user},
+		)
+		assert.Same(t, address, input.Properties["billing"])
+		assert.NotSame(t, user, input.Properties["owner"])
+		assert.Same(t, address, user.Properties["address"], "components are not modified")
+
+		data, err := json.Marshal(input)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"type":"object","properties":{
+			"billing":{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]},
+			"owner":{"type":"object","properties":{"address":{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}}}
+		}}`, string(data))
+
+		rs, err := input.Resolve(nil)
+		require.NoError(t, err)
+		assert.Error(t, rs.Validate(map[string]any{"owner": map[string]any{"address": map[string]any{}}}))
+	})
+
+	t.Run("unknown reference", func(t *testing.T) {
+		assert.PanicsWithValue(t, "invalid schema JSON: unknown reference #/components/schemas/Missing", func() {
+			MustUnmarshalSchemaRefs(`{"type":"array","items":{"$ref":"#/components/schemas/Missing"}}`, nil)
+		})
+	})
+}
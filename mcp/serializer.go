@@ -0,0 +1,126 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Serializer encodes resource contents in a format other than JSON, such as
+// CBOR or MessagePack, to shrink the payloads of binary-heavy servers.
+// Serializers are registered by encoding name with RegisterSerializer, and
+// resources choose theirs with the encoding option of the spec.
+type Serializer interface {
+	// MIMEType is the MIME type of the encoded contents, such as
+	// application/cbor.
+	MIMEType() string
+	// Marshal encodes v.
+	Marshal(v any) ([]byte, error)
+}
+
+// SerializerFunc adapts a marshal function, such as cbor.Marshal, to the
+// Serializer interface.
+type SerializerFunc struct {
+	ContentType string
+	MarshalFunc func(v any) ([]byte, error)
+}
+
+func (s SerializerFunc) MIMEType() string {
+	return s.ContentType
+}
+
+func (s SerializerFunc) Marshal(v any) ([]byte, error) {
+	return s.MarshalFunc(v)
+}
+
+var (
+	serializersMu sync.RWMutex
+	serializers   = map[string]Serializer{
+		"json": SerializerFunc{ContentType: "application/json", MarshalFunc: json.Marshal},
+	}
+)
+
+// RegisterSerializer makes a serializer available under an encoding name,
+// replacing the serializer registered under that name, if any. The json
+// encoding is registered by default. It is meant to be called before the
+// server is created, typically from main or an init function.
+//
+// Example:
+//
+//	mcputil.RegisterSerializer("cbor", mcputil.SerializerFunc{
+//	    ContentType: "application/cbor",
+//	    MarshalFunc: cbor.Marshal,
+//	})
+func RegisterSerializer(encoding string, s Serializer) {
+	serializersMu.Lock()
+	defer serializersMu.Unlock()
+	serializers[encoding] = s
+}
+
+// LookupSerializer returns the serializer registered under an encoding name
+// or, failing that, the one producing the given MIME type.
+func LookupSerializer(encoding string) (Serializer, bool) {
+	serializersMu.RLock()
+	defer serializersMu.RUnlock()
+
+	if s, ok := serializers[encoding]; ok {
+		return s, true
+	}
+	for _, s := range serializers {
+		if s.MIMEType() == encoding {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// SerializerMIMEType returns the MIME type of the serializer registered
+// under an encoding name, or "" when there is none.
+func SerializerMIMEType(encoding string) string {
+	s, ok := LookupSerializer(encoding)
+	if !ok {
+		return ""
+	}
+	return s.MIMEType()
+}
+
+// EncodeResource encodes v as the contents of the resource at uri with the
+// serializer of an encoding, named or given by its MIME type. Textual
+// contents, such as JSON, are sent as text and binary ones as a blob, which
+// the SDK encodes in base64. The contents can also be embedded in tool
+// results, for tools returning large binary outputs.
+func EncodeResource(uri, encoding string, v any) (*mcp.ResourceContents, error) {
+	s, ok := LookupSerializer(encoding)
+	if !ok {
+		return nil, fmt.Errorf("no serializer registered for encoding %q", encoding)
+	}
+
+	data, err := s.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode resource %s as %s: %w", uri, encoding, err)
+	}
+
+	contents := &mcp.ResourceContents{URI: uri, MIMEType: s.MIMEType()}
+	if isTextMIMEType(contents.MIMEType) {
+		contents.Text = string(data)
+	} else {
+		contents.Blob = data
+	}
+	return contents, nil
+}
+
+// isTextMIMEType reports whether contents of a MIME type are text, as
+// opposed to binary.
+func isTextMIMEType(mimeType string) bool {
+	mediaType, _, _ := strings.Cut(mimeType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+	return strings.HasPrefix(mediaType, "text/") ||
+		mediaType == "application/json" ||
+		strings.HasSuffix(mediaType, "+json") ||
+		mediaType == "application/xml" ||
+		strings.HasSuffix(mediaType, "+xml") ||
+		mediaType == "application/yaml"
+}
@@ -0,0 +1,70 @@
+package mcp
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeResource(t *testing.T) {
+	RegisterSerializer("hex", SerializerFunc{
+		ContentType: "application/x-hex",
+		MarshalFunc: func(v any) ([]byte, error) {
+			s, ok := v.(string)
+			if !ok {
+				return nil, errors.New("not a string")
+			}
+			return []byte(hex.EncodeToString([]byte(s))), nil
+		},
+	})
+
+	t.Run("json as text", func(t *testing.T) {
+		contents, err := EncodeResource("stats://current", "json", stats{Users: 42})
+		require.NoError(t, err)
+		assert.Equal(t, "stats://current", contents.URI)
+		assert.Equal(t, "application/json", contents.MIMEType)
+		assert.Equal(t, `{"users":42}`, contents.Text)
+		assert.Nil(t, contents.Blob)
+	})
+
+	t.Run("binary as blob", func(t *testing.T) {
+		contents, err := EncodeResource("files://a", "hex", "ab")
+		require.NoError(t, err)
+		assert.Equal(t, "application/x-hex", contents.MIMEType)
+		assert.Equal(t, []byte("6162"), contents.Blob)
+		assert.Empty(t, contents.Text)
+	})
+
+	t.Run("by mime type", func(t *testing.T) {
+		contents, err := EncodeResource("files://a", "application/x-hex", "ab")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("6162"), contents.Blob)
+		assert.Equal(t, "application/x-hex", SerializerMIMEType("hex"))
+	})
+
+	t.Run("unknown encoding", func(t *testing.T) {
+		_, err := EncodeResource("files://a", "cbor", "ab")
+		assert.EqualError(t, err, `no serializer registered for encoding "cbor"`)
+		assert.Empty(t, SerializerMIMEType("cbor"))
+	})
+
+	t.Run("marshal error", func(t *testing.T) {
+		_, err := EncodeResource("files://a", "hex", 1)
+		assert.EqualError(t, err, "cannot encode resource files://a as hex: not a string")
+	})
+}
+
+func TestIsTextMIMEType(t *testing.T) {
+	for mimeType, text := range map[string]bool{
+		"text/plain; charset=utf-8": true,
+		"application/json":          true,
+		"application/ld+json":       true,
+		"application/cbor":          false,
+		"application/msgpack":       false,
+	} {
+		assert.Equal(t, text, isTextMIMEType(mimeType), mimeType)
+	}
+}
@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// SlowCallMiddleware returns a receiving middleware that logs the requests
+// taking longer than threshold to handle. When the threshold is reached, the
+// stack of the goroutine handling the request is captured and logged at warn
+// level with the method and the tool, resource or prompt called, showing
+// where the handler is stuck. The total duration is logged once the request
+// completes. The logs carry the request ID of RequestIDMiddleware.
+//
+// A nil logger logs to slog.Default().
+//
+// Example:
+//
+//	server.AddReceivingMiddleware(mcputil.SlowCallMiddleware(2*time.Second, logger))
+func SlowCallMiddleware(threshold time.Duration, logger *slog.Logger) mcp.Middleware {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			attrs := callAttrs(method, req)
+			if id := RequestID(ctx); id != "" {
+				attrs = append(attrs, slog.String("request_id", id))
+			}
+			id := goroutineID()
+			start := time.Now()
+
+			timer := time.AfterFunc(threshold, func() {
+				logger.LogAttrs(ctx, slog.LevelWarn, "slow MCP call", slices.Concat(attrs, []slog.Attr{
+					slog.Duration("elapsed", time.Since(start)),
+					slog.String("stack", goroutineStack(id)),
+				})...)
+			})
+
+			result, err := next(ctx, method, req)
+
+			if !timer.Stop() {
+				logger.LogAttrs(ctx, slog.LevelWarn, "slow MCP call completed", append(attrs, slog.Duration("duration", time.Since(start)))...)
+			}
+
+			return result, err
+		}
+	}
+}
+
+// callAttrs describes a request for logging.
+func callAttrs(method string, req mcp.Request) []slog.Attr {
+	attrs := []slog.Attr{slog.String("method", method)}
+
+	switch r := req.(type) {
+	case *mcp.CallToolRequest:
+		if r.Params != nil {
+			attrs = append(attrs, slog.String("tool", r.Params.Name))
+		}
+	case *mcp.ReadResourceRequest:
+		if r.Params != nil {
+			attrs = append(attrs, slog.String("uri", r.Params.URI))
+		}
+	case *mcp.GetPromptRequest:
+		if r.Params != nil {
+			attrs = append(attrs, slog.String("prompt", r.Params.Name))
+		}
+	}
+
+	return attrs
+}
+
+// goroutineID returns the ID of the calling goroutine, read from the header
+// of its stack trace.
+func goroutineID() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if i := bytes.IndexByte(buf, ' '); i > 0 {
+		id, _ := strconv.ParseUint(string(buf[:i]), 10, 64)
+		return id
+	}
+	return 0
+}
+
+// goroutineStack returns the stack trace of the goroutine with the given ID,
+// or of every goroutine when it cannot be found.
+func goroutineStack(id uint64) string {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	header := []byte(fmt.Sprintf("goroutine %d [", id))
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		if bytes.HasPrefix(stack, header) {
+			return string(stack)
+		}
+	}
+
+	return string(buf)
+}
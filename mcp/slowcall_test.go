@@ -0,0 +1,58 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlowCallMiddleware(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+
+	t.Run("fast call is not logged", func(t *testing.T) {
+		fast := func(_ context.Context, _ string, _ mcp.Request) (mcp.Result, error) {
+			return nil, nil
+		}
+		handler := SlowCallMiddleware(time.Second, logger)(fast)
+		req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "add"}}
+		_, err := handler(context.Background(), "tools/call", req)
+		require.NoError(t, err)
+		assert.Empty(t, logs.String())
+	})
+
+	t.Run("slow call logs the handler stack", func(t *testing.T) {
+		slow := func(_ context.Context, _ string, _ mcp.Request) (mcp.Result, error) {
+			time.Sleep(50 * time.Millisecond)
+			return nil, nil
+		}
+		req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "add"}}
+		_, err := SlowCallMiddleware(10*time.Millisecond, logger)(slow)(context.Background(), "tools/call", req)
+		require.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+		require.Len(t, lines, 2)
+
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+		assert.Equal(t, "slow MCP call", entry["msg"])
+		assert.Equal(t, "WARN", entry["level"])
+		assert.Equal(t, "tools/call", entry["method"])
+		assert.Equal(t, "add", entry["tool"])
+		stack, _ := entry["stack"].(string)
+		assert.True(t, strings.HasPrefix(stack, "goroutine "), stack)
+		assert.Contains(t, stack, "time.Sleep")
+
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &entry))
+		assert.Equal(t, "slow MCP call completed", entry["msg"])
+		assert.Contains(t, entry, "duration")
+	})
+}
@@ -0,0 +1,32 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ToolDocURI returns the URI of the resource documenting the tool name.
+func ToolDocURI(name string) string {
+	return "docs://tools/" + name
+}
+
+// AddToolDoc registers doc, the markdown documentation of the tool name, as
+// the resource at ToolDocURI(name), so that agents read detailed usage
+// guidance on demand rather than with every tools/list result.
+func AddToolDoc(server *mcp.Server, name, doc string) {
+	uri := ToolDocURI(name)
+	server.AddResource(
+		&mcp.Resource{
+			URI:         uri,
+			Name:        name + "_docs",
+			Description: "Usage guidance of the " + name + " tool",
+			MIMEType:    "text/markdown",
+		},
+		func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return &mcp.ReadResourceResult{
+				Contents: []*mcp.ResourceContents{{URI: uri, MIMEType: "text/markdown", Text: doc}},
+			}, nil
+		},
+	)
+}
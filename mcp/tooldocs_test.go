@@ -0,0 +1,38 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddToolDoc(t *testing.T) {
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	AddToolDoc(server, "create_task", "# create_task\n\nCreates a task.\n")
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = session.Close() })
+
+	list, err := session.ListResources(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, list.Resources, 1)
+	assert.Equal(t, "docs://tools/create_task", list.Resources[0].URI)
+	assert.Equal(t, "text/markdown", list.Resources[0].MIMEType)
+
+	result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: ToolDocURI("create_task")})
+	require.NoError(t, err)
+	require.Len(t, result.Contents, 1)
+	assert.Equal(t, "# create_task\n\nCreates a task.\n", result.Contents[0].Text)
+	assert.Equal(t, "text/markdown", result.Contents[0].MIMEType)
+}
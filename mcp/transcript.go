@@ -0,0 +1,280 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	// TranscriptReceived is the direction of the messages the client sends,
+	// such as tool calls.
+	TranscriptReceived = "received"
+	// TranscriptSent is the direction of the messages the server sends,
+	// such as sampling requests and notifications.
+	TranscriptSent = "sent"
+
+	// RedactedValue replaces the values removed by RedactTranscriptFields.
+	RedactedValue = "[REDACTED]"
+)
+
+// TranscriptEntry is a message of a session transcript with its response,
+// written as a line of JSON.
+type TranscriptEntry struct {
+	// Time is when the message was handled.
+	Time time.Time `json:"time"`
+	// Session is the ID of the session, empty over stdio.
+	Session string `json:"session,omitempty"`
+	// RequestID is the ID RequestIDMiddleware gave the request, or the
+	// request of the client being handled as the server sent the message.
+	RequestID string `json:"request_id,omitempty"`
+	// Direction is TranscriptReceived or TranscriptSent.
+	Direction string          `json:"direction"`
+	Method    string          `json:"method"`
+	Params    json.RawMessage `json:"params,omitempty"`
+	// Result is the result of the message, empty for notifications and
+	// errors.
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// TranscriptRedactFunc removes the secrets and personal data of an entry
+// before it is written, such as with RedactTranscriptFields.
+type TranscriptRedactFunc func(entry *TranscriptEntry)
+
+// WithTranscript writes the transcript of every session to w, one
+// TranscriptEntry per line, to reproduce the bugs clients report from what
+// they exchanged with the server. The entries of concurrent sessions are
+// interleaved; their Session tells them apart.
+func WithTranscript(w io.Writer) Option {
+	return func(o *Options) {
+		o.TranscriptWriter = w
+	}
+}
+
+// WithTranscriptDir writes the transcript of each session to its own file of
+// dir, named after the time the session started and its ID, as
+// WithTranscript does, in place of writing to the writer of WithTranscript.
+// The directory is created when missing.
+func WithTranscriptDir(dir string) Option {
+	return func(o *Options) {
+		o.TranscriptDir = dir
+	}
+}
+
+// WithTranscriptRedactFunc sets the function redacting the transcript
+// entries before they are written. Transcripts otherwise hold the tool
+// arguments and results as they are.
+//
+// Example:
+//
+//	server.New(resolver,
+//	    mcputil.WithTranscriptDir("/var/log/mcp"),
+//	    mcputil.WithTranscriptRedactFunc(mcputil.RedactTranscriptFields("password", "token")),
+//	)
+func WithTranscriptRedactFunc(fn TranscriptRedactFunc) Option {
+	return func(o *Options) {
+		o.TranscriptRedactFunc = fn
+	}
+}
+
+// RedactTranscriptFields returns a TranscriptRedactFunc replacing the values
+// of the object fields with the given names, at any depth of the params and
+// result, with RedactedValue. Names are matched ignoring case. Secrets in
+// free text, such as the text content of results, are left as they are.
+func RedactTranscriptFields(names ...string) TranscriptRedactFunc {
+	redacted := make(map[string]bool, len(names))
+	for _, name := range names {
+		redacted[strings.ToLower(name)] = true
+	}
+
+	redact := func(raw json.RawMessage) json.RawMessage {
+		var v any
+		if len(raw) == 0 || json.Unmarshal(raw, &v) != nil {
+			return raw
+		}
+		out, err := json.Marshal(redactFields(v, redacted))
+		if err != nil {
+			return raw
+		}
+		return out
+	}
+
+	return func(entry *TranscriptEntry) {
+		entry.Params = redact(entry.Params)
+		entry.Result = redact(entry.Result)
+	}
+}
+
+// redactFields replaces the values of the fields of v named in redacted.
+func redactFields(v any, redacted map[string]bool) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if redacted[strings.ToLower(key)] {
+				v[key] = RedactedValue
+			} else {
+				v[key] = redactFields(value, redacted)
+			}
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = redactFields(value, redacted)
+		}
+	}
+	return v
+}
+
+// Transcript records the messages of the sessions of a server. Options
+// build it from the transcript options.
+type Transcript struct {
+	w      io.Writer
+	dir    string
+	redact TranscriptRedactFunc
+	logger *slog.Logger
+	clock  Clock
+
+	mu       sync.Mutex
+	sessions map[mcp.Session]*os.File
+	started  int
+}
+
+// Transcript returns the Transcript configured by WithTranscript or
+// WithTranscriptDir, or nil when neither is set. The generated server adds
+// its middlewares.
+func (o *Options) Transcript() *Transcript {
+	if o.TranscriptWriter == nil && o.TranscriptDir == "" {
+		return nil
+	}
+	return &Transcript{
+		w:        o.TranscriptWriter,
+		dir:      o.TranscriptDir,
+		redact:   o.TranscriptRedactFunc,
+		logger:   o.Logger,
+		clock:    o.Clock,
+		sessions: make(map[mcp.Session]*os.File),
+	}
+}
+
+// Middleware returns a middleware recording the messages handled in
+// direction: a receiving middleware for TranscriptReceived and a sending
+// middleware for TranscriptSent. Add it after the other middlewares, for the
+// transcript to hold the messages as the client sees them, but before
+// RequestIDMiddleware, for the entries to carry the request IDs.
+//
+// Example:
+//
+//	server.AddReceivingMiddleware(transcript.Middleware(mcputil.TranscriptReceived))
+//	server.AddSendingMiddleware(transcript.Middleware(mcputil.TranscriptSent))
+func (t *Transcript) Middleware(direction string) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			entry := TranscriptEntry{Time: t.clock.Now(), Direction: direction, Method: method}
+			if params := req.GetParams(); params != nil {
+				entry.Params = marshalTranscript(params)
+			}
+
+			result, err := next(ctx, method, req)
+
+			if err != nil {
+				entry.Error = err.Error()
+			} else if result != nil {
+				entry.Result = marshalTranscript(result)
+			}
+			entry.RequestID = RequestID(ctx)
+			t.write(ctx, req.GetSession(), entry)
+
+			return result, err
+		}
+	}
+}
+
+// marshalTranscript returns the JSON of v, or nothing when v is a nil
+// pointer or cannot be marshaled.
+func marshalTranscript(v any) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil || string(data) == "null" {
+		return nil
+	}
+	return data
+}
+
+func (t *Transcript) write(ctx context.Context, session mcp.Session, entry TranscriptEntry) {
+	if session != nil {
+		entry.Session = session.ID()
+	}
+	if t.redact != nil {
+		t.redact(&entry)
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		t.logger.WarnContext(ctx, "cannot marshal the session transcript entry", "method", entry.Method, "error", err)
+		return
+	}
+	line = append(line, '\n')
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	w := t.w
+	if t.dir != "" {
+		if w, err = t.sessionFile(session); err != nil {
+			t.logger.WarnContext(ctx, "cannot create the session transcript", "dir", t.dir, "error", err)
+			return
+		}
+	}
+	if _, err := w.Write(line); err != nil {
+		t.logger.WarnContext(ctx, "cannot write the session transcript", "error", err)
+	}
+}
+
+// sessionFile returns the transcript file of session, created on its first
+// message and closed as it ends. t.mu must be held.
+func (t *Transcript) sessionFile(session mcp.Session) (*os.File, error) {
+	if f, ok := t.sessions[session]; ok {
+		return f, nil
+	}
+
+	if err := os.MkdirAll(t.dir, 0o755); err != nil {
+		return nil, err
+	}
+	t.started++
+	name := fmt.Sprint(t.started)
+	if session != nil && session.ID() != "" {
+		name = strings.Map(func(r rune) rune {
+			if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+				return r
+			}
+			return -1
+		}, session.ID())
+	}
+	f, err := os.OpenFile(
+		filepath.Join(t.dir, t.clock.Now().UTC().Format("20060102T150405Z")+"-"+name+".jsonl"),
+		os.O_CREATE|os.O_WRONLY|os.O_APPEND,
+		0o600,
+	)
+	if err != nil {
+		return nil, err
+	}
+	t.sessions[session] = f
+
+	if ss, ok := session.(*mcp.ServerSession); ok {
+		go func() {
+			_ = ss.Wait()
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			delete(t.sessions, session)
+			_ = f.Close()
+		}()
+	}
+	return f, nil
+}
@@ -0,0 +1,116 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// transcriptServer returns a server recording transcripts with opts and
+// serving a login tool, and a function connecting clients to it.
+func transcriptServer(t *testing.T, opts ...Option) func() *mcp.ClientSession {
+	t.Helper()
+	o := ApplyOptions(opts)
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "login"}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		return nil, map[string]any{"user": args["user"], "token": "t0k3n"}, nil
+	})
+	transcript := o.Transcript()
+	require.NotNil(t, transcript)
+	server.AddReceivingMiddleware(transcript.Middleware(TranscriptReceived))
+	server.AddSendingMiddleware(transcript.Middleware(TranscriptSent))
+	server.AddReceivingMiddleware(RequestIDMiddleware(o.IDGenerator))
+
+	return func() *mcp.ClientSession {
+		ctx := context.Background()
+		serverTransport, clientTransport := mcp.NewInMemoryTransports()
+		serverSession, err := server.Connect(ctx, serverTransport, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = serverSession.Close() })
+		client := mcp.NewClient(&mcp.Implementation{Name: "client", Version: "1.0.0"}, nil)
+		session, err := client.Connect(ctx, clientTransport, nil)
+		require.NoError(t, err)
+		return session
+	}
+}
+
+func readTranscript(t *testing.T, data []byte) []TranscriptEntry {
+	t.Helper()
+	var entries []TranscriptEntry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var entry TranscriptEntry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestTranscript(t *testing.T) {
+	var buf bytes.Buffer
+	now := time.Date(2025, 3, 14, 15, 9, 26, 0, time.UTC)
+	connect := transcriptServer(t,
+		WithTranscript(&buf),
+		WithTranscriptRedactFunc(RedactTranscriptFields("Password", "token")),
+		WithClock(NewFakeClock(now)),
+		WithIDGenerator(SequentialIDGenerator("req-")),
+	)
+	session := connect()
+	_, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Name:      "login",
+		Arguments: map[string]any{"user": "ada", "password": "s3cret"},
+	})
+	require.NoError(t, err)
+	require.NoError(t, session.Close())
+
+	entries := readTranscript(t, buf.Bytes())
+	methods := make([]string, len(entries))
+	for i, entry := range entries {
+		methods[i] = entry.Method
+		assert.Equal(t, TranscriptReceived, entry.Direction)
+		assert.True(t, now.Equal(entry.Time), "entries are timestamped with the clock of the options")
+		assert.Equal(t, fmt.Sprintf("req-%d", i+1), entry.RequestID)
+	}
+	assert.Equal(t, []string{"initialize", "notifications/initialized", "tools/call"}, methods)
+
+	call := entries[2]
+	assert.JSONEq(t, `{"name": "login", "arguments": {"user": "ada", "password": "[REDACTED]"}}`, string(call.Params))
+	var result struct {
+		StructuredContent map[string]any `json:"structuredContent"`
+	}
+	require.NoError(t, json.Unmarshal(call.Result, &result))
+	assert.Equal(t, map[string]any{"user": "ada", "token": "[REDACTED]"}, result.StructuredContent)
+	assert.NotContains(t, buf.String(), "s3cret")
+}
+
+func TestTranscriptDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "transcripts")
+	connect := transcriptServer(t, WithTranscriptDir(dir))
+	for range 2 {
+		session := connect()
+		require.NoError(t, session.Ping(context.Background(), nil))
+		require.NoError(t, session.Close())
+	}
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 2, "each session has its own transcript")
+	for _, file := range files {
+		assert.Regexp(t, `^\d{8}T\d{6}Z-\d+\.jsonl$`, file.Name())
+		data, err := os.ReadFile(filepath.Join(dir, file.Name()))
+		require.NoError(t, err)
+		entries := readTranscript(t, data)
+		require.Len(t, entries, 3)
+		assert.Equal(t, "ping", entries[2].Method)
+	}
+}
@@ -0,0 +1,277 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ValidationMiddleware returns a receiving middleware validating the
+// arguments of "tools/call" requests against the input schema registered for
+// the called tool in schemas, before the handler decodes them. Invalid
+// arguments are answered with an error result naming the tool and the
+// failing constraint, rather than with the error of decoding them. Each
+// rejection is reported to report, unless it is nil.
+//
+// Schemas are resolved on the first call of their tool, or by Prewarm.
+// Schemas that cannot be resolved are skipped, leaving the arguments to the
+// validation of the MCP SDK.
+//
+// Example:
+//
+//	server.AddReceivingMiddleware(mcputil.ValidationMiddleware(map[string]*jsonschema.Schema{
+//	    "add": AddToolInputSchema,
+//	}, o.ReportValidationFailure))
+func ValidationMiddleware(schemas map[string]*jsonschema.Schema, report ValidationFailureFunc) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" {
+				return next(ctx, method, req)
+			}
+
+			callReq, ok := req.(*mcp.CallToolRequest)
+			if !ok || callReq.Params == nil {
+				return next(ctx, method, req)
+			}
+
+			s, ok := schemas[callReq.Params.Name]
+			if !ok {
+				return next(ctx, method, req)
+			}
+			rs, err := resolveSchema(s)
+			if err != nil {
+				return next(ctx, method, req)
+			}
+
+			if err := validateArguments(rs, callReq.Params.Arguments); err != nil {
+				if report != nil {
+					report(ctx, newValidationFailure(callReq, err))
+				}
+				return &mcp.CallToolResult{
+					IsError: true,
+					Content: []mcp.Content{&mcp.TextContent{Text: Message(MessageInvalidArguments, callReq.Params.Name, err.Error())}},
+				}, nil
+			}
+
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// validateArguments validates raw tool arguments against rs. Missing
+// arguments are validated as an empty object.
+func validateArguments(rs *jsonschema.Resolved, args json.RawMessage) error {
+	var value any = map[string]any{}
+	if len(args) > 0 && string(args) != "null" {
+		if err := json.Unmarshal(args, &value); err != nil {
+			return err
+		}
+	}
+	return rs.Validate(value)
+}
+
+// ValidationFailuresMetricName is the name of the metric written by
+// ValidationFailures.WritePrometheus.
+const ValidationFailuresMetricName = "mcp_tool_input_validation_failures_total"
+
+// ValidationFailure describes tool arguments ValidationMiddleware rejected,
+// to find out which clients keep sending malformed inputs.
+type ValidationFailure struct {
+	// Tool is the name of the called tool.
+	Tool string
+	// Client is the name the client gave in its initialize request, empty
+	// when unknown.
+	Client string
+	// Pointer is the JSON pointer of the failing schema in the input schema
+	// of the tool, such as /properties/due, empty for the input schema
+	// itself.
+	Pointer string
+	// Constraint is the failing keyword, such as required or minimum, empty
+	// when the arguments are not JSON.
+	Constraint string
+	// Err is the validation error.
+	Err error
+}
+
+// ValidationFailureFunc receives the tool arguments ValidationMiddleware
+// rejected, such as to audit them.
+//
+// Example:
+//
+//	server.New(resolver, mcputil.WithValidationFailureFunc(func(ctx context.Context, f mcputil.ValidationFailure) {
+//	    logger.InfoContext(ctx, "invalid tool arguments", "tool", f.Tool, "client", f.Client, "pointer", f.Pointer, "constraint", f.Constraint)
+//	}))
+type ValidationFailureFunc func(ctx context.Context, failure ValidationFailure)
+
+// WithValidationFailureFunc sets the function receiving the tool arguments
+// rejected by the input validation enabled by exec.validate_input.
+func WithValidationFailureFunc(fn ValidationFailureFunc) Option {
+	return func(o *Options) {
+		o.ValidationFailureFunc = fn
+	}
+}
+
+// WithValidationFailures sets the counter of the tool arguments rejected by
+// the input validation, which the generated server otherwise keeps to
+// itself.
+func WithValidationFailures(failures *ValidationFailures) Option {
+	return func(o *Options) {
+		o.ValidationFailures = failures
+	}
+}
+
+// ReportValidationFailure counts failure in o.ValidationFailures and passes
+// it to o.ValidationFailureFunc. It is the ValidationFailureFunc of the
+// generated server.
+func (o *Options) ReportValidationFailure(ctx context.Context, failure ValidationFailure) {
+	if o.ValidationFailures != nil {
+		o.ValidationFailures.inc(failure.Tool, failure.Constraint)
+	}
+	if o.ValidationFailureFunc != nil {
+		o.ValidationFailureFunc(ctx, failure)
+	}
+}
+
+// newValidationFailure describes the rejection of the arguments of req with
+// err.
+func newValidationFailure(req *mcp.CallToolRequest, err error) ValidationFailure {
+	failure := ValidationFailure{Tool: req.Params.Name, Err: err}
+	if req.Session != nil {
+		if params := req.Session.InitializeParams(); params != nil && params.ClientInfo != nil {
+			failure.Client = params.ClientInfo.Name
+		}
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+		return failure
+	}
+	failure.Pointer, failure.Constraint = failingConstraint(err)
+	return failure
+}
+
+// failingConstraint extracts the location of the failing schema and the
+// failing keyword from a jsonschema validation error, which reads like
+// "validating root: validating /properties/a: minimum: 0 is less than 1".
+func failingConstraint(err error) (pointer, constraint string) {
+	msg := err.Error()
+	for {
+		rest, ok := strings.CutPrefix(msg, "validating ")
+		if !ok {
+			break
+		}
+		location, tail, ok := strings.Cut(rest, ": ")
+		if !ok {
+			break
+		}
+		pointer, msg = location, tail
+	}
+	if pointer == "root" {
+		pointer = ""
+	}
+
+	if strings.HasPrefix(msg, "unexpected additional properties") {
+		return pointer, "additionalProperties"
+	}
+	keyword, _, _ := strings.Cut(msg, ":")
+	keyword, _, _ = strings.Cut(keyword, "[")
+	if strings.ContainsAny(keyword, " \"") {
+		return pointer, ""
+	}
+	return pointer, keyword
+}
+
+// validationFailureKey identifies a counter of ValidationFailures.
+type validationFailureKey struct {
+	tool, constraint string
+}
+
+// ValidationFailures counts the tool arguments rejected by the input
+// validation, by tool and failing keyword. The zero value is ready to use.
+type ValidationFailures struct {
+	mu     sync.Mutex
+	counts map[validationFailureKey]uint64
+}
+
+// Count returns the number of calls to tool rejected for failing
+// constraint.
+func (f *ValidationFailures) Count(tool, constraint string) uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.counts[validationFailureKey{tool, constraint}]
+}
+
+func (f *ValidationFailures) inc(tool, constraint string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.counts == nil {
+		f.counts = map[validationFailureKey]uint64{}
+	}
+	f.counts[validationFailureKey{tool, constraint}]++
+}
+
+// WritePrometheus writes the failure counts as a counter in the Prometheus
+// text exposition format, labeled by tool and constraint:
+//
+//	mcp_tool_input_validation_failures_total{tool="create_task",constraint="required"} 7
+func (f *ValidationFailures) WritePrometheus(w io.Writer) error {
+	f.mu.Lock()
+	counts := make(map[validationFailureKey]uint64, len(f.counts))
+	for key, count := range f.counts {
+		counts[key] = count
+	}
+	f.mu.Unlock()
+
+	if _, err := fmt.Fprintf(
+		w,
+		"# HELP %[1]s The number of tool calls rejected by input validation, labeled by tool and failing constraint.\n"+
+			"# TYPE %[1]s counter\n",
+		ValidationFailuresMetricName,
+	); err != nil {
+		return err
+	}
+
+	keys := make([]validationFailureKey, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b validationFailureKey) int {
+		if c := strings.Compare(a.tool, b.tool); c != 0 {
+			return c
+		}
+		return strings.Compare(a.constraint, b.constraint)
+	})
+	for _, key := range keys {
+		if _, err := fmt.Fprintf(
+			w,
+			"%s{tool=\"%s\",constraint=\"%s\"} %d\n",
+			ValidationFailuresMetricName,
+			escapeLabelValue(key.tool),
+			escapeLabelValue(key.constraint),
+			counts[key],
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MetricsHandler returns an HTTP handler serving the failure counts. It can
+// be mounted on its own or its output appended to an existing metrics
+// endpoint.
+func (f *ValidationFailures) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = f.WritePrometheus(w)
+	})
+}
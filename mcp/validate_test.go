@@ -0,0 +1,142 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidationMiddleware(t *testing.T) {
+	schemas := map[string]*jsonschema.Schema{
+		"add": {
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"a": {Type: "integer"},
+				"b": {Type: "integer"},
+			},
+			Required: []string{"a", "b"},
+		},
+	}
+
+	called := false
+	next := func(context.Context, string, mcp.Request) (mcp.Result, error) {
+		called = true
+		return &mcp.CallToolResult{}, nil
+	}
+	handler := ValidationMiddleware(schemas, nil)(next)
+
+	call := func(t *testing.T, name, args string) *mcp.CallToolResult {
+		t.Helper()
+		called = false
+		req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: name, Arguments: json.RawMessage(args)}}
+		result, err := handler(context.Background(), "tools/call", req)
+		require.NoError(t, err)
+		return result.(*mcp.CallToolResult)
+	}
+
+	t.Run("valid arguments", func(t *testing.T) {
+		result := call(t, "add", `{"a":1,"b":2}`)
+		assert.True(t, called)
+		assert.False(t, result.IsError)
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		result := call(t, "add", `{"a":"one","b":2}`)
+		assert.False(t, called, "the handler is not called")
+		assert.True(t, result.IsError)
+		text := result.Content[0].(*mcp.TextContent).Text
+		assert.Contains(t, text, "invalid arguments for tool add: ")
+		assert.Contains(t, text, "integer")
+	})
+
+	t.Run("missing arguments", func(t *testing.T) {
+		result := call(t, "add", ``)
+		assert.False(t, called)
+		assert.Contains(t, result.Content[0].(*mcp.TextContent).Text, "required")
+	})
+
+	t.Run("unknown tool", func(t *testing.T) {
+		call(t, "other", `{"a":"one"}`)
+		assert.True(t, called)
+	})
+}
+
+func TestValidationFailures(t *testing.T) {
+	schemas := map[string]*jsonschema.Schema{
+		"create_task": {
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"title":    {Type: "string"},
+				"priority": {Type: "integer", Minimum: jsonschema.Ptr(1.0)},
+				"labels":   {Type: "array", Items: &jsonschema.Schema{Type: "string"}},
+			},
+			Required: []string{"title"},
+		},
+	}
+
+	var reported []ValidationFailure
+	o := ApplyOptions([]Option{WithValidationFailureFunc(func(_ context.Context, failure ValidationFailure) {
+		reported = append(reported, failure)
+	})})
+	handler := ValidationMiddleware(schemas, o.ReportValidationFailure)(func(context.Context, string, mcp.Request) (mcp.Result, error) {
+		return &mcp.CallToolResult{}, nil
+	})
+
+	for _, args := range []string{
+		`{"title":"Write docs","priority":0}`,
+		`{"priority":2}`,
+		`{"title":"Write docs","labels":["docs",3]}`,
+		`{"title":"Write docs"}`,
+		`{"title":`,
+		`{}`,
+	} {
+		req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "create_task", Arguments: json.RawMessage(args)}}
+		_, err := handler(context.Background(), "tools/call", req)
+		require.NoError(t, err)
+	}
+
+	require.Len(t, reported, 5)
+	for i, want := range []struct{ pointer, constraint string }{
+		{"/properties/priority", "minimum"},
+		{"", "required"},
+		{"/properties/labels/items", "type"},
+		{"", ""},
+		{"", "required"},
+	} {
+		assert.Equal(t, "create_task", reported[i].Tool)
+		assert.Equal(t, want.pointer, reported[i].Pointer, "failure %d", i)
+		assert.Equal(t, want.constraint, reported[i].Constraint, "failure %d", i)
+		assert.Error(t, reported[i].Err)
+	}
+
+	assert.Equal(t, uint64(2), o.ValidationFailures.Count("create_task", "required"))
+
+	var buf bytes.Buffer
+	require.NoError(t, o.ValidationFailures.WritePrometheus(&buf))
+	assert.Equal(t, "# HELP mcp_tool_input_validation_failures_total The number of tool calls rejected by input validation, labeled by tool and failing constraint.\n"+
+		"# TYPE mcp_tool_input_validation_failures_total counter\n"+
+		"mcp_tool_input_validation_failures_total{tool=\"create_task\",constraint=\"\"} 1\n"+
+		"mcp_tool_input_validation_failures_total{tool=\"create_task\",constraint=\"minimum\"} 1\n"+
+		"mcp_tool_input_validation_failures_total{tool=\"create_task\",constraint=\"required\"} 2\n"+
+		"mcp_tool_input_validation_failures_total{tool=\"create_task\",constraint=\"type\"} 1\n", buf.String())
+}
+
+func TestFailingConstraint(t *testing.T) {
+	for _, tt := range []struct{ msg, pointer, constraint string }{
+		{`validating root: validating /properties/a: minimum: 0 is less than 1`, "/properties/a", "minimum"},
+		{`validating root: unexpected additional properties ["x"]`, "", "additionalProperties"},
+		{`validating root: dependentRequired["a"]: missing properties ["b"]`, "", "dependentRequired"},
+		{`unexpected end of JSON input`, "", ""},
+	} {
+		pointer, constraint := failingConstraint(errors.New(tt.msg))
+		assert.Equal(t, tt.pointer, pointer, tt.msg)
+		assert.Equal(t, tt.constraint, constraint, tt.msg)
+	}
+}